@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/installer"
+)
+
+const (
+	// adminUser is the user new kubeconfigs authenticate as by default.
+	adminUser = "system:admin"
+
+	// adminGroup is the group only adminUser is a member of by default,
+	// which grants cluster-admin.
+	adminGroup = "system:masters"
+)
+
+var (
+	authOpts struct {
+		ttl    time.Duration
+		user   string
+		groups []string
+		output string
+	}
+)
+
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage credentials for an OpenShift cluster",
+		Long:  "",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newAuthNewKubeconfigCmd())
+	return cmd
+}
+
+func newAuthNewKubeconfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new-kubeconfig",
+		Short: "Issue a short-lived kubeconfig for the cluster",
+		Long: `Issue a short-lived kubeconfig for the cluster.
+
+A fresh client certificate is signed by the admin kubeconfig signer kept in
+the installer state of the asset directory, so time-boxed credentials can be
+handed out without sharing auth/kubeconfig.  By default the certificate
+authenticates as system:admin, in the system:masters group; use --user to
+issue credentials which are scoped by the cluster's RBAC instead.  Other
+users are only in the groups given with --group.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			if !cmd.Flags().Changed("group") && authOpts.user == adminUser {
+				authOpts.groups = []string{adminGroup}
+			}
+			err := runAuthNewKubeconfigCmd(rootOpts.dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&authOpts.ttl, "ttl", 24*time.Hour, "how long the kubeconfig is valid for")
+	cmd.Flags().StringVar(&authOpts.user, "user", adminUser, "the user to authenticate as")
+	cmd.Flags().StringSliceVar(&authOpts.groups, "group", nil, fmt.Sprintf("the groups the user is a member of (%s for the default %s user)", adminGroup, adminUser))
+	cmd.Flags().StringVar(&authOpts.output, "output", "", "write the kubeconfig to this file instead of auth/kubeconfig-<expiry> (use - for stdout)")
+	return cmd
+}
+
+func runAuthNewKubeconfigCmd(directory string) error {
+	data, expires, err := installer.NewKubeconfig(rootCtx, installer.NewKubeconfigOptions{
+		Dir:    directory,
+		TTL:    authOpts.ttl,
+		User:   authOpts.user,
+		Groups: authOpts.groups,
+	})
+	if err != nil {
+		return err
+	}
+
+	if authOpts.output == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	output := authOpts.output
+	if output == "" {
+		output = filepath.Join(directory, "auth", fmt.Sprintf("kubeconfig-%s", expires.UTC().Format("20060102T150405Z")))
+	}
+	if err := fileaudit.WriteFile(output, data, 0600, "kubeconfig"); err != nil {
+		return errors.Wrap(err, "failed to write kubeconfig")
+	}
+	logrus.Infof("Wrote a kubeconfig for %s valid until %s to %s", authOpts.user, expires.Local().Format(time.RFC1123), output)
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+)
+
+// serveBootstrapContent serves the bootstrap content of the asset
+// directory dir on address, at /bootstrap-content/<sha512>, until the
+// installer exits.
+func serveBootstrapContent(address, dir string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for bootstrap content requests")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/bootstrap-content/", bootstrap.ContentHandler(dir))
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			logrus.Warnf("Bootstrap content endpoint stopped: %v", err)
+		}
+	}()
+	logrus.Infof("Serving bootstrap content on http://%s/bootstrap-content", listener.Addr())
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/cache"
+)
+
+var (
+	cacheCleanOpts struct {
+		olderThan time.Duration
+	}
+)
+
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "List or clean the installer's download cache",
+		Long: `List or clean the installer's download cache.
+
+Downloads such as RHCOS images are cached in $XDG_CACHE_HOME/kni-install
+(~/.cache/kni-install by default), or in the directory given by
+--cache-dir, and reused by later installs.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newCacheListCmd())
+	cmd.AddCommand(newCacheCleanCmd())
+	return cmd
+}
+
+func newCacheListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the cached downloads",
+		Args:  cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runCacheList(); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+}
+
+func runCacheList() error {
+	dir, err := cache.Dir()
+	if err != nil {
+		return err
+	}
+	items, err := cache.List(dir)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tSIZE\tCACHED\tPARTIAL")
+	for _, item := range items {
+		total += item.Size
+		fmt.Fprintf(w, "%s\t%d\t%s\t%t\n", item.Path, item.Size, item.ModTime.Format(time.RFC3339), item.Partial)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	logrus.Infof("%d items, %d bytes, cached in %s", len(items), total, dir)
+	return nil
+}
+
+func newCacheCleanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove cached downloads",
+		Args:  cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runCacheClean(); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&cacheCleanOpts.olderThan, "older-than", 0, "only remove downloads cached longer ago than this (e.g. \"720h\")")
+	return cmd
+}
+
+func runCacheClean() error {
+	dir, err := cache.Dir()
+	if err != nil {
+		return err
+	}
+	var cutoff time.Time
+	if cacheCleanOpts.olderThan > 0 {
+		cutoff = time.Now().Add(-cacheCleanOpts.olderThan)
+	}
+
+	removed, err := cache.Clean(dir, cutoff)
+	var total int64
+	for _, item := range removed {
+		total += item.Size
+		logrus.Debugf("Removed %s", item.Path)
+	}
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Removed %d items, %d bytes, from %s", len(removed), total, dir)
+	return nil
+}
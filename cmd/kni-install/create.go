@@ -1,43 +1,35 @@
 package main
 
 import (
-	"context"
-	"crypto/x509"
 	"fmt"
-	"io/ioutil"
+	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	corev1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/clientcmd"
-	clientwatch "k8s.io/client-go/tools/watch"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
-	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 	targetassets "github.com/metalkube/kni-installer/pkg/asset/targets"
-	destroybootstrap "github.com/metalkube/kni-installer/pkg/destroy/bootstrap"
-	configv1 "github.com/openshift/api/config/v1"
-	configclient "github.com/openshift/client-go/config/clientset/versioned"
-	routeclient "github.com/openshift/client-go/route/clientset/versioned"
-	cov1helpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/installer"
+	"github.com/metalkube/kni-installer/pkg/simulate"
+	"github.com/metalkube/kni-installer/pkg/status"
 )
 
 type target struct {
 	name    string
 	command *cobra.Command
 	assets  []asset.WritableAsset
+	// lint checks the generated files with manifests.Lint before they are
+	// written.
+	lint bool
 }
 
 // each target is a variable to preserve the order when creating subcommands and still
@@ -63,6 +55,7 @@ var (
 			// Long:  "",
 		},
 		assets: targetassets.Manifests,
+		lint:   true,
 	}
 
 	manifestTemplatesTarget = target{
@@ -80,8 +73,14 @@ var (
 		command: &cobra.Command{
 			Use:   "ignition-configs",
 			Short: "Generates the Ignition Config asset",
-			// FIXME: add longer descriptions for our commands with examples for better UX.
-			// Long:  "",
+			Long: `Generates the Ignition Config asset.
+
+With --role, only the Ignition config for that role (and the assets it
+depends on) is generated.  For example, to regenerate worker.ign for
+scale-out, copy .openshift_install_state.json from the original asset
+directory into an empty directory and run:
+
+  kni-install --dir scale-out create ignition-configs --role=worker`,
 		},
 		assets: targetassets.IgnitionConfigs,
 	}
@@ -93,361 +92,296 @@ var (
 			Short: "Create an OpenShift cluster",
 			// FIXME: add longer descriptions for our commands with examples for better UX.
 			// Long:  "",
-			PostRun: func(_ *cobra.Command, _ []string) {
-				ctx := context.Background()
-
-				cleanup := setupFileHook(rootOpts.dir)
-				defer cleanup()
-
-				config, err := clientcmd.BuildConfigFromFlags("", filepath.Join(rootOpts.dir, "auth", "kubeconfig"))
-				if err != nil {
-					logrus.Fatal(errors.Wrap(err, "loading kubeconfig"))
-				}
-
-				logrus.Warn("FIXME! Exiting after bootstrap cluster create for baremetal testing")
-				return
-
-				err = destroyBootstrap(ctx, config, rootOpts.dir)
-				if err != nil {
-					logrus.Fatal(err)
-				}
-
-				if err := waitForInitializedCluster(ctx, config); err != nil {
-					logrus.Fatal(err)
-				}
-
-				consoleURL, err := waitForConsole(ctx, config, rootOpts.dir)
-				if err != nil {
-					logrus.Fatal(err)
-				}
-
-				if err = addRouterCAToClusterCA(config, rootOpts.dir); err != nil {
-					logrus.Fatal(err)
-				}
-
-				err = logComplete(rootOpts.dir, consoleURL)
-				if err != nil {
-					logrus.Fatal(err)
-				}
-			},
 		},
 		assets: targetassets.Cluster,
 	}
 
 	targets = []target{installConfigTarget, manifestTemplatesTarget, manifestsTarget, ignitionConfigsTarget, clusterTarget}
+
+	installConfigOpts struct {
+		fromCluster string
+	}
+
+	ignitionConfigsOpts struct {
+		role string
+	}
+
+	clusterOpts struct {
+		skipConnectivityCheck   bool
+		skipConfigRecord        bool
+		statusAddress           string
+		statusLinger            time.Duration
+		bootstrapContentAddress string
+		gatherBootstrap         bool
+		bootstrapHost           string
+	}
+
+	createOpts struct {
+		ephemeral bool
+		ttl       time.Duration
+		keyPool   bool
+		simulate  bool
+	}
+
+	outputOpts struct {
+		authDir      string
+		manifestsDir string
+		tlsDir       string
+		tarball      string
+	}
+
+	bootMediaOpts struct {
+		host  string
+		image string
+	}
+
+	bootstrapPreviewOpts struct {
+		skipRender bool
+	}
+
+	// keyPool is the pool the create commands take RSA keys from, if
+	// --key-pool is set.
+	keyPool *tls.KeyPool
 )
 
 func newCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create part of an OpenShift cluster",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			runRootCmd(cmd, args)
+			if createOpts.ephemeral {
+				os.Setenv(installconfig.EphemeralTTLEnvVar, createOpts.ttl.String())
+			}
+			if createOpts.keyPool {
+				// Every certificate has a key, as does the service
+				// account key pair.
+				keys := len(cluster.CertKeys()) + 1
+				keyPool = tls.NewKeyPool(asset.SystemSources, runtime.NumCPU(), keys)
+				rootCtx = asset.NewSourcesContext(rootCtx, keyPool)
+			}
+			if createOpts.simulate {
+				rootCtx = simulate.NewContext(rootCtx)
+			}
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if keyPool != nil {
+				keyPool.Stop()
+			}
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		},
 	}
+	cmd.PersistentFlags().BoolVar(&createOpts.ephemeral, "ephemeral", false, "create a throwaway cluster whose certificates and credentials expire after --ttl")
+	cmd.PersistentFlags().DurationVar(&createOpts.ttl, "ttl", 6*time.Hour, "the lifetime of an ephemeral cluster")
+	cmd.PersistentFlags().BoolVar(&createOpts.simulate, "simulate", false, "rehearse the install without touching hardware: the libvirt and bare metal resources are applied on an in-memory backend instead of with Terraform, and no OS image is downloaded")
+	cmd.PersistentFlags().BoolVar(&createOpts.keyPool, "key-pool", true, "once the first certificate is generated, generate the rest of the cluster's RSA keys in the background on every CPU, rather than one at a time as certificates need them")
 
 	for _, t := range targets {
 		t.command.Args = cobra.ExactArgs(0)
-		t.command.Run = runTargetCmd(t.assets...)
+		t.command.Run = runTargetCmd(t.name, t.lint, t.assets...)
+		t.command.Flags().StringVar(&outputOpts.authDir, "auth-dir", "", "also copy the generated auth/ files (kubeconfig, kubeadmin-password) to this directory")
+		t.command.Flags().StringVar(&outputOpts.manifestsDir, "manifests-dir", "", "also copy the generated manifests/ and openshift/ files to this directory")
+		t.command.Flags().StringVar(&outputOpts.tlsDir, "tls-dir", "", "also copy the generated tls/ files to this directory")
+		t.command.Flags().StringVar(&outputOpts.tarball, "tarball", "", "also write all the generated files, laid out as in the asset directory, to this gzipped tarball")
 		cmd.AddCommand(t.command)
 	}
 
-	return cmd
-}
+	installConfigTarget.command.Flags().StringVar(&installConfigOpts.fromCluster, "from-cluster", "", "reconstruct a best-effort install-config from the running cluster with this admin kubeconfig, e.g. to rebuild it, instead of asking for it")
+	installConfigTarget.command.Run = func(cmd *cobra.Command, args []string) {
+		if installConfigOpts.fromCluster == "" {
+			runTargetCmd(installConfigTarget.name, false, installConfigTarget.assets...)(cmd, args)
+			return
+		}
+		cleanup := setupFileHook(rootOpts.dir)
+		defer cleanup()
 
-func runTargetCmd(targets ...asset.WritableAsset) func(cmd *cobra.Command, args []string) {
-	runner := func(directory string) error {
-		assetStore, err := assetstore.NewStore(directory)
+		path, err := installer.InstallConfigFromCluster(rootCtx, installer.InstallConfigFromClusterOptions{
+			Dir:        rootOpts.dir,
+			Kubeconfig: installConfigOpts.fromCluster,
+		})
 		if err != nil {
-			return errors.Wrap(err, "failed to create asset store")
+			logrus.Fatal(err)
 		}
+		logrus.Infof("Install config reconstructed in %s, review it before creating a cluster from it", path)
+	}
 
-		for _, a := range targets {
-			err := assetStore.Fetch(a)
-			if err != nil {
-				err = errors.Wrapf(err, "failed to fetch %s", a.Name())
+	roles := make([]string, 0, len(targetassets.IgnitionConfigsByRole))
+	for role := range targetassets.IgnitionConfigsByRole {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	ignitionConfigsTarget.command.Flags().StringVar(&ignitionConfigsOpts.role, "role", "", fmt.Sprintf("only generate the Ignition config for this role (%s)", strings.Join(roles, ", ")))
+	ignitionConfigsTarget.command.Run = func(cmd *cobra.Command, args []string) {
+		assets := ignitionConfigsTarget.assets
+		if ignitionConfigsOpts.role != "" {
+			var ok bool
+			assets, ok = targetassets.IgnitionConfigsByRole[ignitionConfigsOpts.role]
+			if !ok {
+				logrus.Fatalf("invalid role %q: must be one of %s", ignitionConfigsOpts.role, strings.Join(roles, ", "))
 			}
+		}
+		runTargetCmd(ignitionConfigsTarget.name, false, assets...)(cmd, args)
+	}
+	clusterTarget.command.Flags().BoolVar(&clusterOpts.skipConnectivityCheck, "skip-connectivity-check", false, "do not check that the installer host can reach the registry, RHCOS image, libvirt and API DNS names before provisioning")
+	clusterTarget.command.Flags().BoolVar(&clusterOpts.skipConfigRecord, "skip-config-record", false, "do not store a redacted copy of the install-config and the installer, release image and asset versions in the kube-system/kni-install-config configmap once the cluster is installed")
+	clusterTarget.command.Flags().BoolVar(&clusterOpts.gatherBootstrap, "gather-bootstrap", false, "before destroying the bootstrap machine, copy its journal, rendered assets and etcd discovery data over SSH into the bootstrap-artifacts directory")
+	clusterTarget.command.Flags().StringVar(&clusterOpts.bootstrapHost, "bootstrap-host", "", "the address of the bootstrap machine for --gather-bootstrap, if it is not in the Terraform variables or state")
+	clusterTarget.command.Flags().StringVar(&clusterOpts.bootstrapContentAddress, "bootstrap-content-address", "", "serve the bootstrap content of platform.baremetal.bootstrapContent on this address (e.g. \":8080\"), at /bootstrap-content, while the cluster is created")
+	clusterTarget.command.Flags().StringVar(&clusterOpts.statusAddress, "status-address", "", "serve the install's phase, last error and ETA as JSON on this address (e.g. \"localhost:9090\"), at /status and /healthz")
+	clusterTarget.command.Flags().DurationVar(&clusterOpts.statusLinger, "status-linger", time.Minute, "with --status-address, how long to keep serving the final status, succeeded or failed, before exiting")
+	clusterTarget.command.Run = runClusterCmd
+
+	cmd.AddCommand(newCreateBootMediaCmd())
+	cmd.AddCommand(newCreateBootstrapPreviewCmd())
+	return cmd
+}
 
-			if err2 := asset.PersistToFile(a, directory); err2 != nil {
-				err2 = errors.Wrapf(err2, "failed to write asset (%s) to disk", a.Name())
-				if err != nil {
-					logrus.Error(err2)
-					return err
-				}
-				return err2
+func newCreateBootMediaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "boot-media",
+		Short: "Generates a bootable image for a bare metal host",
+		Long: `Generates a bootable image for a bare metal host.
+
+The host's Ignition config and first-boot kernel arguments, including its
+static network configuration, are embedded into a copy of the RHCOS live ISO
+or disk image, which is written to boot-media/<host>.iso (or .qcow2 or .img
+for disk images) in the asset directory.
+Write it to a USB stick for hosts which can boot neither from PXE nor from
+virtual media:
+
+  kni-install create boot-media --host=master-0 --image=rhcos-live.iso
+  dd if=boot-media/master-0.iso of=/dev/sdX bs=4M`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if bootMediaOpts.host == "" {
+				logrus.Fatal("--host is required")
+			}
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			path, err := installer.CreateBootMedia(rootCtx, installer.BootMediaOptions{
+				Dir:   rootOpts.dir,
+				Host:  bootMediaOpts.host,
+				Image: bootMediaOpts.image,
+			})
+			if err != nil {
+				logrus.Fatal(err)
 			}
+			logrus.Infof("Boot media written to %s", path)
+		},
+	}
+	cmd.Flags().StringVar(&bootMediaOpts.host, "host", "", "the name of the host in platform.baremetal.hosts")
+	cmd.Flags().StringVar(&bootMediaOpts.image, "image", "", "the RHCOS live ISO or disk image to customize (defaults to the platform's RHCOS image)")
+	return cmd
+}
 
+func newCreateBootstrapPreviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bootstrap-preview",
+		Short: "Renders the bootstrap static pod manifests locally",
+		Long: `Renders the bootstrap static pod manifests locally.
+
+The bootstrap machine's /opt/openshift is laid out in bootstrap-preview/ in
+the asset directory, and the release image's render steps which bootkube.sh
+runs on the bootstrap machine are run there with podman.  The installer's
+manifests and the rendered static pods are then checked, so that a release
+image which does not match the installer's templates is caught before any
+hardware is touched.  Without podman, only the installer's manifests are
+checked.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			path, err := installer.PreviewBootstrap(rootCtx, installer.BootstrapPreviewOptions{
+				Dir:        rootOpts.dir,
+				SkipRender: bootstrapPreviewOpts.skipRender,
+			})
 			if err != nil {
-				return err
+				logrus.Fatal(err)
 			}
-		}
-		return nil
+			logrus.Infof("Bootstrap preview rendered in %s", path)
+		},
 	}
+	cmd.Flags().BoolVar(&bootstrapPreviewOpts.skipRender, "skip-render", false, "only check the installer's manifests, without running the release image's render steps")
+	return cmd
+}
 
+func runTargetCmd(name string, lint bool, targets ...asset.WritableAsset) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
 		cleanup := setupFileHook(rootOpts.dir)
 		defer cleanup()
 
-		err := runner(rootOpts.dir)
+		done := notifyPhase(name)
+		err := installer.GenerateAssets(rootCtx, installer.GenerateAssetsOptions{Dir: rootOpts.dir, Targets: targets, Lint: lint, Outputs: outputs()})
 		if err != nil {
 			logrus.Fatal(err)
 		}
+		done("")
 	}
 }
 
-// addRouterCAToClusterCA adds router CA to cluster CA in kubeconfig
-func addRouterCAToClusterCA(config *rest.Config, directory string) (err error) {
-	client, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return errors.Wrap(err, "creating a Kubernetes client")
-	}
+func runClusterCmd(cmd *cobra.Command, args []string) {
+	cleanup := setupFileHook(rootOpts.dir)
+	defer cleanup()
 
-	// Configmap may not exist. log and accept not-found errors with configmap.
-	caConfigMap, err := client.CoreV1().ConfigMaps("openshift-config-managed").Get("router-ca", metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logrus.Infof("router-ca resource not found in cluster, perhaps you are not using default router CA")
-			return nil
-		}
-		return errors.Wrap(err, "fetching router-ca configmap from openshift-config-managed namespace")
-	}
-
-	routerCrtBytes := []byte(caConfigMap.Data["ca-bundle.crt"])
-	kubeconfig := filepath.Join(directory, "auth", "kubeconfig")
-	kconfig, err := clientcmd.LoadFromFile(kubeconfig)
-	if err != nil {
-		return errors.Wrap(err, "loading kubeconfig")
-	}
-
-	if kconfig == nil || len(kconfig.Clusters) == 0 {
-		return errors.New("kubeconfig is missing expected data")
-	}
-
-	for _, c := range kconfig.Clusters {
-		clusterCABytes := c.CertificateAuthorityData
-		if len(clusterCABytes) == 0 {
-			return errors.New("kubeconfig CertificateAuthorityData not found")
-		}
-		certPool := x509.NewCertPool()
-		if !certPool.AppendCertsFromPEM(clusterCABytes) {
-			return errors.New("cluster CA found in kubeconfig not valid PEM format")
-		}
-		if !certPool.AppendCertsFromPEM(routerCrtBytes) {
-			return errors.New("ca-bundle.crt from router-ca configmap not valid PEM format")
+	if clusterOpts.bootstrapContentAddress != "" {
+		if err := serveBootstrapContent(clusterOpts.bootstrapContentAddress, rootOpts.dir); err != nil {
+			logrus.Fatal(err)
 		}
-
-		newCA := append(routerCrtBytes, clusterCABytes...)
-		c.CertificateAuthorityData = newCA
 	}
-	if err := clientcmd.WriteToFile(*kconfig, kubeconfig); err != nil {
-		return errors.Wrap(err, "writing kubeconfig")
-	}
-	return nil
-}
 
-// FIXME: pulling the kubeconfig and metadata out of the root
-// directory is a bit cludgy when we already have them in memory.
-func destroyBootstrap(ctx context.Context, config *rest.Config, directory string) (err error) {
-	client, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return errors.Wrap(err, "creating a Kubernetes client")
-	}
-
-	discovery := client.Discovery()
-
-	apiTimeout := 30 * time.Minute
-	logrus.Infof("Waiting up to %v for the Kubernetes API...", apiTimeout)
-	apiContext, cancel := context.WithTimeout(ctx, apiTimeout)
-	defer cancel()
-	// Poll quickly so we notice changes, but only log when the response
-	// changes (because that's interesting) or when we've seen 15 of the
-	// same errors in a row (to show we're still alive).
-	logDownsample := 15
-	silenceRemaining := logDownsample
-	previousErrorSuffix := ""
-	wait.Until(func() {
-		version, err := discovery.ServerVersion()
-		if err == nil {
-			logrus.Infof("API %s up", version)
-			cancel()
-		} else {
-			silenceRemaining--
-			chunks := strings.Split(err.Error(), ":")
-			errorSuffix := chunks[len(chunks)-1]
-			if previousErrorSuffix != errorSuffix {
-				logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
-				previousErrorSuffix = errorSuffix
-				silenceRemaining = logDownsample
-			} else if silenceRemaining == 0 {
-				logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
-				silenceRemaining = logDownsample
-			}
+	onPhase := installer.PhaseFunc(notifyPhase)
+	var tracker *status.Tracker
+	if clusterOpts.statusAddress != "" {
+		var err error
+		onPhase, tracker, err = serveStatus(clusterOpts.statusAddress, status.ClusterPhases(installer.ClusterPhases()), onPhase)
+		if err != nil {
+			logrus.Fatal(err)
 		}
-	}, 2*time.Second, apiContext.Done())
-	err = apiContext.Err()
-	if err != nil && err != context.Canceled {
-		return errors.Wrap(err, "waiting for Kubernetes API")
 	}
 
-	events := client.CoreV1().Events("kube-system")
-
-	eventTimeout := 30 * time.Minute
-	logrus.Infof("Waiting up to %v for the bootstrap-complete event...", eventTimeout)
-	eventContext, cancel := context.WithTimeout(ctx, eventTimeout)
-	defer cancel()
-	_, err = Until(
-		eventContext,
-		"",
-		func(sinceResourceVersion string) (watch.Interface, error) {
-			for {
-				watcher, err := events.Watch(metav1.ListOptions{
-					ResourceVersion: sinceResourceVersion,
-				})
-				if err == nil {
-					return watcher, nil
-				}
-				select {
-				case <-eventContext.Done():
-					return watcher, err
-				default:
-					logrus.Warningf("Failed to connect events watcher: %s", err)
-					time.Sleep(2 * time.Second)
-				}
-			}
+	info, err := installer.CreateCluster(rootCtx, installer.CreateClusterOptions{
+		Dir:                   rootOpts.dir,
+		OnPhase:               onPhase,
+		SkipConnectivityCheck: clusterOpts.skipConnectivityCheck,
+		SkipConfigRecord:      clusterOpts.skipConfigRecord,
+		GatherBootstrap: installer.GatherBootstrapOptions{
+			Enabled: clusterOpts.gatherBootstrap,
+			Host:    clusterOpts.bootstrapHost,
 		},
-		func(watchEvent watch.Event) (bool, error) {
-			event, ok := watchEvent.Object.(*corev1.Event)
-			if !ok {
-				return false, nil
-			}
-
-			if watchEvent.Type == watch.Error {
-				logrus.Debugf("error %s: %s", event.Name, event.Message)
-				return false, nil
-			}
-
-			if watchEvent.Type != watch.Added {
-				return false, nil
-			}
-
-			logrus.Debugf("added %s: %s", event.Name, event.Message)
-			return event.Name == "bootstrap-complete", nil
-		},
-	)
-	if err != nil {
-		return errors.Wrap(err, "waiting for bootstrap-complete")
+		Outputs: outputs(),
+	})
+	if tracker != nil {
+		finishStatus(rootCtx, tracker, err, clusterOpts.statusLinger)
 	}
-
-	logrus.Info("Destroying the bootstrap resources...")
-	return destroybootstrap.Destroy(rootOpts.dir)
-}
-
-// waitForInitializedCluster watches the ClusterVersion waiting for confirmation
-// that the cluster has been initialized.
-func waitForInitializedCluster(ctx context.Context, config *rest.Config) error {
-	timeout := 30 * time.Minute
-	logrus.Infof("Waiting up to %v for the cluster to initialize...", timeout)
-	cc, err := configclient.NewForConfig(config)
 	if err != nil {
-		return errors.Wrap(err, "failed to create a config client")
+		logrus.Fatal(err)
 	}
-	clusterVersionContext, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	var clusterVersion *configv1.ClusterVersion
-
-	_, err = clientwatch.UntilWithSync(
-		clusterVersionContext,
-		cache.NewListWatchFromClient(cc.ConfigV1().RESTClient(), "clusterversions", "", fields.OneTermEqualSelector("metadata.name", "version")),
-		&configv1.ClusterVersion{},
-		nil,
-		func(event watch.Event) (bool, error) {
-			switch event.Type {
-			case watch.Added, watch.Modified:
-				cv, ok := event.Object.(*configv1.ClusterVersion)
-				if !ok {
-					logrus.Warnf("Expected a ClusterVersion object but got a %q object instead", event.Object.GetObjectKind().GroupVersionKind())
-					return false, nil
-				}
-				clusterVersion = cv
-				if cov1helpers.IsStatusConditionTrue(cv.Status.Conditions, configv1.OperatorAvailable) {
-					logrus.Debug("Cluster is initialized")
-					return true, nil
-				}
-				if cov1helpers.IsStatusConditionTrue(cv.Status.Conditions, configv1.OperatorFailing) {
-					logrus.Debugf("Still waiting for the cluster to initialize: %v",
-						cov1helpers.FindStatusCondition(cv.Status.Conditions, configv1.OperatorFailing).Message)
-					return false, nil
-				}
-			}
-			logrus.Debug("Still waiting for the cluster to initialize...")
-			return false, nil
-		},
-	)
-
-	// If we timed out and the CVO failed, print out the failure message
-	if err != nil && clusterVersion != nil {
-		if cov1helpers.IsStatusConditionTrue(clusterVersion.Status.Conditions, configv1.OperatorFailing) {
-			err = errors.New(cov1helpers.FindStatusCondition(clusterVersion.Status.Conditions, configv1.OperatorFailing).Message)
-		}
+	if info.ConsoleURL == "" {
+		return
 	}
 
-	return errors.Wrap(err, "failed to initialize the cluster")
-}
-
-// waitForConsole returns the console URL from the route 'console' in namespace openshift-console
-func waitForConsole(ctx context.Context, config *rest.Config, directory string) (string, error) {
-	url := ""
-	// Need to keep these updated if they change
-	consoleNamespace := "openshift-console"
-	consoleRouteName := "console"
-	rc, err := routeclient.NewForConfig(config)
+	err = logComplete(rootOpts.dir, info.ConsoleURL)
 	if err != nil {
-		return "", errors.Wrap(err, "creating a route client")
+		logrus.Fatal(err)
 	}
+}
 
-	consoleRouteTimeout := 10 * time.Minute
-	logrus.Infof("Waiting up to %v for the openshift-console route to be created...", consoleRouteTimeout)
-	consoleRouteContext, cancel := context.WithTimeout(ctx, consoleRouteTimeout)
-	defer cancel()
-	// Poll quickly but only log when the response
-	// when we've seen 15 of the same errors or output of
-	// no route in a row (to show we're still alive).
-	logDownsample := 15
-	silenceRemaining := logDownsample
-	wait.Until(func() {
-		consoleRoutes, err := rc.RouteV1().Routes(consoleNamespace).List(metav1.ListOptions{})
-		if err == nil && len(consoleRoutes.Items) > 0 {
-			for _, route := range consoleRoutes.Items {
-				logrus.Debugf("Route found in openshift-console namespace: %s", route.Name)
-				if route.Name == consoleRouteName {
-					url = fmt.Sprintf("https://%s", route.Spec.Host)
-				}
-			}
-			logrus.Debug("OpenShift console route is created")
-			cancel()
-		} else if err != nil {
-			silenceRemaining--
-			if silenceRemaining == 0 {
-				logrus.Debugf("Still waiting for the console route: %v", err)
-				silenceRemaining = logDownsample
-			}
-		} else if len(consoleRoutes.Items) == 0 {
-			silenceRemaining--
-			if silenceRemaining == 0 {
-				logrus.Debug("Still waiting for the console route...")
-				silenceRemaining = logDownsample
-			}
-		}
-	}, 2*time.Second, consoleRouteContext.Done())
-	err = consoleRouteContext.Err()
-	if err != nil && err != context.Canceled {
-		return url, errors.Wrap(err, "waiting for openshift-console URL")
-	}
-	if url == "" {
-		return url, errors.New("could not get openshift-console URL")
+// outputs returns where the output flags place copies of the generated
+// files.
+func outputs() installer.OutputOptions {
+	return installer.OutputOptions{
+		Dirs: map[string]string{
+			"auth":      outputOpts.authDir,
+			"manifests": outputOpts.manifestsDir,
+			"openshift": outputOpts.manifestsDir,
+			"tls":       outputOpts.tlsDir,
+		},
+		Tarball: outputOpts.tarball,
 	}
-	return url, nil
 }
 
 // logComplete prints info upon completion
@@ -458,14 +392,16 @@ func logComplete(directory, consoleURL string) error {
 	}
 	kubeconfig := filepath.Join(absDir, "auth", "kubeconfig")
 	pwFile := filepath.Join(absDir, "auth", "kubeadmin-password")
-	pw, err := ioutil.ReadFile(pwFile)
+	pw, err := fileaudit.ReadFile(pwFile, "kubeadmin password")
 	if err != nil {
 		return err
 	}
 	logrus.Info("Install complete!")
 	logrus.Infof("Run 'export KUBECONFIG=%s' to manage the cluster with 'oc', the OpenShift CLI.", kubeconfig)
 	logrus.Infof("The cluster is ready when 'oc login -u kubeadmin -p %s' succeeds (wait a few minutes).", pw)
-	logrus.Infof("Access the OpenShift web-console here: %s", consoleURL)
-	logrus.Infof("Login to the console with user: kubeadmin, password: %s", pw)
+	if consoleURL != "" {
+		logrus.Infof("Access the OpenShift web-console here: %s", consoleURL)
+		logrus.Infof("Login to the console with user: kubeadmin, password: %s", pw)
+	}
 	return nil
 }
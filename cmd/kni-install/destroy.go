@@ -1,16 +1,33 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
-	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
 	"github.com/metalkube/kni-installer/pkg/destroy"
-	_ "github.com/metalkube/kni-installer/pkg/destroy/baremetal"
-	"github.com/metalkube/kni-installer/pkg/destroy/bootstrap"
-	_ "github.com/metalkube/kni-installer/pkg/destroy/libvirt"
-	_ "github.com/metalkube/kni-installer/pkg/destroy/openstack"
+	"github.com/metalkube/kni-installer/pkg/installer"
+)
+
+var (
+	destroyOpts struct {
+		dryRun  bool
+		output  string
+		filters []string
+		timeout time.Duration
+		force   bool
+	}
+
+	destroyBootstrapOpts struct {
+		gather        bool
+		bootstrapHost string
+	}
 )
 
 func newDestroyCmd() *cobra.Command {
@@ -28,7 +45,7 @@ func newDestroyCmd() *cobra.Command {
 }
 
 func newDestroyClusterCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "cluster",
 		Short: "Destroy an OpenShift cluster",
 		Args:  cobra.ExactArgs(0),
@@ -36,43 +53,73 @@ func newDestroyClusterCmd() *cobra.Command {
 			cleanup := setupFileHook(rootOpts.dir)
 			defer cleanup()
 
-			err := runDestroyCmd(rootOpts.dir)
+			filter, err := destroy.ParseFilter(destroyOpts.filters)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			opts := installer.DestroyClusterOptions{
+				Dir:     rootOpts.dir,
+				Filter:  filter,
+				Timeout: destroyOpts.timeout,
+				Force:   destroyOpts.force,
+			}
+
+			if destroyOpts.dryRun {
+				if err := runDestroyClusterDryRun(opts, destroyOpts.output); err != nil {
+					logrus.Fatal(err)
+				}
+				return
+			}
+
+			done := notifyPhase("Destroy cluster")
+			err = installer.DestroyCluster(rootCtx, opts)
 			if err != nil {
 				logrus.Fatal(err)
 			}
+			done("")
 		},
 	}
+	cmd.Flags().BoolVar(&destroyOpts.dryRun, "dry-run", false, "list the resources which would be destroyed, without destroying anything")
+	cmd.Flags().StringVar(&destroyOpts.output, "output", "table", "format of the --dry-run listing (table or json)")
+	cmd.Flags().StringSliceVar(&destroyOpts.filters, "filter", nil, "only destroy some resource groups, e.g. only=workers or keep=network (groups: bootstrap, masters, network, storage, workers); the assets are kept when filtering")
+	cmd.Flags().DurationVar(&destroyOpts.timeout, "timeout", 10*time.Minute, "how long to wait for a single resource to be deleted, or 0 to wait forever")
+	cmd.Flags().BoolVar(&destroyOpts.force, "force", false, "skip resources which cannot be reached or deleted, with a warning, instead of failing")
+	return cmd
 }
 
-func runDestroyCmd(directory string) error {
-	destroyer, err := destroy.New(logrus.StandardLogger(), directory)
-	if err != nil {
-		return errors.Wrap(err, "Failed while preparing to destroy cluster")
-	}
-	if err := destroyer.Run(); err != nil {
-		return errors.Wrap(err, "Failed to destroy cluster")
+func runDestroyClusterDryRun(opts installer.DestroyClusterOptions, output string) error {
+	if output != "table" && output != "json" {
+		return errors.Errorf("unsupported output format %q (must be table or json)", output)
 	}
 
-	store, err := assetstore.NewStore(directory)
+	resources, err := installer.ListClusterResources(rootCtx, opts)
 	if err != nil {
-		return errors.Wrap(err, "failed to create asset store")
+		return err
 	}
-	for _, asset := range clusterTarget.assets {
-		if err := store.Destroy(asset); err != nil {
-			return errors.Wrapf(err, "failed to destroy asset %q", asset.Name())
+
+	if output == "json" {
+		if resources == nil {
+			resources = []destroy.Resource{}
 		}
-	}
-	// delete the state file as well
-	err = store.DestroyState()
-	if err != nil {
-		return errors.Wrap(err, "failed to remove state file")
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resources)
 	}
 
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME")
+	for _, resource := range resources {
+		fmt.Fprintf(w, "%s\t%s\n", resource.Type, resource.Name)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	logrus.Infof("%d resources would be destroyed", len(resources))
 	return nil
 }
 
 func newDestroyBootstrapCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "bootstrap",
 		Short: "Destroy the bootstrap resources",
 		Args:  cobra.ExactArgs(0),
@@ -80,10 +127,16 @@ func newDestroyBootstrapCmd() *cobra.Command {
 			cleanup := setupFileHook(rootOpts.dir)
 			defer cleanup()
 
-			err := bootstrap.Destroy(rootOpts.dir)
+			err := installer.DestroyBootstrap(rootCtx, rootOpts.dir, installer.GatherBootstrapOptions{
+				Enabled: destroyBootstrapOpts.gather,
+				Host:    destroyBootstrapOpts.bootstrapHost,
+			})
 			if err != nil {
 				logrus.Fatal(err)
 			}
 		},
 	}
+	cmd.Flags().BoolVar(&destroyBootstrapOpts.gather, "gather-bootstrap", false, "before destroying the bootstrap machine, copy its journal, rendered assets and etcd discovery data over SSH into the bootstrap-artifacts directory")
+	cmd.Flags().StringVar(&destroyBootstrapOpts.bootstrapHost, "bootstrap-host", "", "the address of the bootstrap machine for --gather-bootstrap, if it is not in the Terraform variables or state")
+	return cmd
 }
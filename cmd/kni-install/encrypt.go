@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/installer"
+)
+
+func newEncryptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt the secret-bearing files of the asset directory",
+		Long: `Encrypt the secret-bearing files of the asset directory.
+
+The kubeconfigs, kubeadmin password, Ignition configs, private keys, Secret
+manifests, Terraform state and installer state are encrypted in place with
+sops or age, for the recipients in the install config's secretsEncryption,
+so the asset directory can be committed to git.  Boot media are not
+encrypted and must not be committed.  Decrypt the files with sops or age
+before running the installer against the directory again, e.g. to destroy
+the cluster.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			encrypted, err := installer.EncryptAssets(rootCtx, rootOpts.dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			logrus.Infof("Encrypted %s", strings.Join(encrypted, ", "))
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/metalkube/kni-installer/pkg/expiry"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+var (
+	checkExpiryOpts struct {
+		threshold  time.Duration
+		cluster    bool
+		kubeconfig string
+		output     string
+	}
+)
+
+func newCheckExpiryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-expiry",
+		Short: "List the cluster's expiring credentials",
+		Long: `List the cluster's expiring credentials.
+
+By default the asset directory is scanned for certificates, in files of
+their own, in kubeconfigs and in Ignition configs, and for the kubeadmin
+password.  With --cluster, the cluster behind auth/kubeconfig (or
+--kubeconfig) is scanned instead, for its TLS secrets, bootstrap tokens and
+kubeadmin secret.  A table of the artifacts, soonest to expire first, is
+written to stdout, or a JSON report to --output, and the command exits
+non-zero if any expires within --threshold.
+
+The asset directory holds certificates which are only used during the
+install and are valid for a day, so audit long-running clusters with
+--cluster.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			report, err := runCheckExpiryCmd(rootOpts.dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if checkExpiryOpts.output == "" {
+				err = report.WriteTable(os.Stdout)
+			} else {
+				err = writeExpiryReport(report, checkExpiryOpts.output)
+			}
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if report.Expiring > 0 {
+				logrus.Fatalf("%d artifacts expire within %s", report.Expiring, checkExpiryOpts.threshold)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&checkExpiryOpts.threshold, "threshold", 30*24*time.Hour, "fail if anything expires within this duration")
+	cmd.Flags().BoolVar(&checkExpiryOpts.cluster, "cluster", false, "scan the running cluster instead of the asset directory")
+	cmd.Flags().StringVar(&checkExpiryOpts.kubeconfig, "kubeconfig", "", "kubeconfig of the cluster to scan (implies --cluster; defaults to auth/kubeconfig in the asset directory)")
+	cmd.Flags().StringVar(&checkExpiryOpts.output, "output", "", "write a JSON report to this file instead of a table to stdout")
+	return cmd
+}
+
+func runCheckExpiryCmd(directory string) (*expiry.Report, error) {
+	if !checkExpiryOpts.cluster && checkExpiryOpts.kubeconfig == "" {
+		return expiry.ScanDir(directory, checkExpiryOpts.threshold)
+	}
+
+	kubeconfig := checkExpiryOpts.kubeconfig
+	if kubeconfig == "" {
+		kubeconfig = filepath.Join(directory, "auth", "kubeconfig")
+	}
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading kubeconfig")
+	}
+	fileaudit.RecordFile(fileaudit.Read, kubeconfig, "kubeconfig")
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating a Kubernetes client")
+	}
+	return expiry.ScanCluster(client, checkExpiryOpts.threshold)
+}
+
+// writeExpiryReport writes a JSON report to output.
+func writeExpiryReport(report *expiry.Report, output string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the expiry report")
+	}
+	data = append(data, '\n')
+	return errors.Wrap(fileaudit.WriteFile(output, data, 0644, "expiry report"), "failed to write the expiry report")
+}
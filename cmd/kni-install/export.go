@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/gitops"
+	"github.com/metalkube/kni-installer/pkg/installer"
+)
+
+var (
+	exportOpts struct {
+		repoLayout string
+		output     string
+	}
+)
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the generated assets for use outside the installer",
+		Long:  "",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newExportGitOpsCmd())
+	return cmd
+}
+
+func newExportGitOpsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gitops",
+		Short: "Arrange the generated manifests for management in git",
+		Long: `Arrange the generated manifests for management in git.
+
+The manifests the installer generated for the cluster are split into one
+file per object and arranged in a repository layout, by default a kustomize
+base with a kustomization.yaml at its root, which Argo CD or Flux can sync.
+Secrets are encrypted for the recipients of the install config's
+secretsEncryption, or left out if it is unset, so the result can be
+committed safely.  The manifests
+are kept in the installer state, so this works before or after the cluster
+is created.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			output, err := installer.ExportGitOps(rootCtx, installer.GitOpsOptions{
+				Dir:    rootOpts.dir,
+				Output: exportOpts.output,
+				Layout: exportOpts.repoLayout,
+			})
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			logrus.Infof("Manifests exported to %s", output)
+		},
+	}
+	cmd.Flags().StringVar(&exportOpts.repoLayout, "repo-layout", gitops.LayoutKustomize, fmt.Sprintf("the repository layout (%s)", strings.Join(gitops.Layouts, ", ")))
+	cmd.Flags().StringVar(&exportOpts.output, "output", "", "the directory to write the repository to (default <dir>/gitops)")
+	return cmd
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 )
 
 var (
@@ -69,6 +70,7 @@ func runGraphCmd(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		defer f.Close()
+		defer fileaudit.RecordFile(fileaudit.Write, graphOpts.outputFile, "asset graph")
 		out = f
 	}
 
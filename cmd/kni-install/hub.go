@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/hub"
+	"github.com/metalkube/kni-installer/pkg/installer"
+)
+
+// hubCheckpointInterval is how often "hub run" saves the asset directory
+// while a phase is running.
+const hubCheckpointInterval = 30 * time.Second
+
+var (
+	hubOpts struct {
+		name      string
+		namespace string
+		image     string
+		secret    string
+	}
+)
+
+func newHubCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hub",
+		Short: "Install spoke clusters from a management (hub) cluster",
+		Long: `Install spoke clusters from a management (hub) cluster.
+
+"hub manifests" prints the manifests of a Job which installs a spoke cluster
+from the install-config.yaml in --dir.  Apply them to the hub:
+
+  kni-install --dir=spoke-1 hub manifests --name=spoke-1 --namespace=spokes \
+    --image=registry.example.com/kni/installer:latest | oc apply -f -
+
+The Job's pod runs "hub run", which keeps the asset directory in a Secret,
+saving it after each phase and every 30 seconds during one, so the install
+continues where it stopped if the pod is restarted.  The spoke's admin kubeconfig is in that Secret's archive once
+the install completes.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&hubOpts.namespace, "namespace", "default", "the hub namespace to run the install in")
+
+	manifests := &cobra.Command{
+		Use:   "manifests",
+		Short: "Print the hub manifests installing a spoke cluster",
+		Args:  cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runHubManifestsCmd(rootOpts.dir); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	manifests.Flags().StringVar(&hubOpts.name, "name", "", "the name of the spoke cluster, which prefixes the names of the hub resources")
+	manifests.Flags().StringVar(&hubOpts.image, "image", "", "the installer image for the Job to run")
+	cmd.AddCommand(manifests)
+
+	run := &cobra.Command{
+		Use:   "run",
+		Short: "Create a cluster, keeping the asset directory in a Secret (runs in the Job)",
+		Args:  cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runHubRunCmd(rootOpts.dir); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	run.Flags().StringVar(&hubOpts.secret, "secret", "", "the Secret holding the asset directory")
+	cmd.AddCommand(run)
+
+	return cmd
+}
+
+func runHubManifestsCmd(directory string) error {
+	installConfig, err := fileaudit.ReadFile(filepath.Join(directory, hub.InstallConfigKey), "install config")
+	if err != nil {
+		return errors.Wrap(err, "failed to read the install config")
+	}
+
+	manifests, err := hub.Manifests(hub.Options{
+		Name:          hubOpts.name,
+		Namespace:     hubOpts.namespace,
+		Image:         hubOpts.image,
+		InstallConfig: installConfig,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(manifests)
+	return err
+}
+
+func runHubRunCmd(directory string) error {
+	if hubOpts.secret == "" {
+		return errors.New("--secret is required")
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to load the in-cluster config")
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a hub client")
+	}
+
+	store := &hub.StateStore{
+		Client:    client.CoreV1(),
+		Namespace: hubOpts.namespace,
+		Name:      hubOpts.secret,
+	}
+	if err := store.Restore(directory); err != nil {
+		return err
+	}
+
+	cleanup := setupFileHook(directory)
+	defer cleanup()
+
+	save := func() {
+		if err := store.Save(directory); err != nil {
+			logrus.Error(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(rootCtx)
+	defer cancel()
+	go store.Checkpoint(ctx, directory, hubCheckpointInterval)
+
+	_, err = installer.CreateCluster(ctx, installer.CreateClusterOptions{
+		Dir: directory,
+		OnPhase: func(phase string) func(message string) {
+			done := notifyPhase(phase)
+			return func(message string) {
+				done(message)
+				save()
+			}
+		},
+	})
+	save()
+	return err
+}
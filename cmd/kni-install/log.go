@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 	"github.com/metalkube/kni-installer/pkg/version"
 )
 
@@ -55,6 +56,7 @@ func setupFileHook(baseDir string) func() {
 	if err != nil {
 		logrus.Fatal(errors.Wrap(err, "failed to open log file"))
 	}
+	fileaudit.Record(fileaudit.Write, logfile.Name(), "install log", nil)
 
 	originalHooks := logrus.LevelHooks{}
 	for k, v := range logrus.StandardLogger().Hooks {
@@ -67,7 +69,7 @@ func setupFileHook(baseDir string) func() {
 		DisableLevelTruncation: false,
 	}))
 
-	logrus.Debugf(version.String)
+	logrus.Debug(version.String)
 
 	return func() {
 		logfile.Close()
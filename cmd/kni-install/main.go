@@ -12,13 +12,30 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
+	"github.com/metalkube/kni-installer/pkg/cache"
+	"github.com/metalkube/kni-installer/pkg/credentials"
+	"github.com/metalkube/kni-installer/pkg/download"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/offline"
 	"github.com/metalkube/kni-installer/pkg/terraform/exec/plugins"
+	"github.com/metalkube/kni-installer/pkg/terraform/state"
 )
 
 var (
 	rootOpts struct {
-		dir      string
-		logLevel string
+		dir                 string
+		logLevel            string
+		notifyURL           string
+		offline             bool
+		answers             string
+		auditFiles          string
+		cacheDir            string
+		credentialProviders []string
+		downloadRateLimit   string
+		rhcosMirrors        []string
+		tfstate             string
+		warningsFile        string
 	}
 )
 
@@ -41,11 +58,23 @@ func main() {
 }
 
 func installerMain() {
+	setupInterruptContext()
 	rootCmd := newRootCmd()
 
 	for _, subCmd := range []*cobra.Command{
 		newCreateCmd(),
 		newDestroyCmd(),
+		newWaitForCmd(),
+		newVerifyCmd(),
+		newAuthCmd(),
+		newServeCmd(),
+		newHubCmd(),
+		newExportCmd(),
+		newEncryptCmd(),
+		newSnapshotCmd(),
+		newCacheCmd(),
+		newCheckExpiryCmd(),
+		newPlanChangeCmd(),
 		newVersionCmd(),
 		newGraphCmd(),
 		newCompletionCmd(),
@@ -56,6 +85,7 @@ func installerMain() {
 	if err := rootCmd.Execute(); err != nil {
 		logrus.Fatalf("Error executing kni-install: %v", err)
 	}
+	reportWarnings()
 }
 
 func newRootCmd() *cobra.Command {
@@ -69,6 +99,16 @@ func newRootCmd() *cobra.Command {
 	}
 	cmd.PersistentFlags().StringVar(&rootOpts.dir, "dir", ".", "assets directory")
 	cmd.PersistentFlags().StringVar(&rootOpts.logLevel, "log-level", "info", "log level (e.g. \"debug | info | warn | error\")")
+	cmd.PersistentFlags().StringVar(&rootOpts.notifyURL, "notify-url", "", "webhook URL (e.g. a Slack incoming webhook) to post progress and the final result to")
+	cmd.PersistentFlags().BoolVar(&rootOpts.offline, "offline", false, "refuse all outbound network fetches, requiring local images and mirrors instead")
+	cmd.PersistentFlags().StringVar(&rootOpts.downloadRateLimit, "download-rate-limit", "", "maximum rate, in bytes per second, for downloading RHCOS metadata and images (e.g. \"10M\")")
+	cmd.PersistentFlags().StringSliceVar(&rootOpts.rhcosMirrors, "rhcos-mirror", nil, "RHCOS release mirror to fall back to, in order, if the primary location fails (may be repeated)")
+	cmd.PersistentFlags().StringVar(&rootOpts.answers, "answers-file", "", "YAML file to record interactive answers to, and replay them from on later runs")
+	cmd.PersistentFlags().StringVar(&rootOpts.auditFiles, "audit-files", "", "report to append every file the installer reads, writes or removes to, with its purpose and SHA-256, as JSON lines")
+	cmd.PersistentFlags().StringVar(&rootOpts.warningsFile, "warnings-file", "", "file to write the warnings collected during the run to, as JSON")
+	cmd.PersistentFlags().StringVar(&rootOpts.cacheDir, "cache-dir", "", "directory to cache downloads such as RHCOS images in (default $XDG_CACHE_HOME/kni-install)")
+	cmd.PersistentFlags().StringSliceVar(&rootOpts.credentialProviders, "credential-providers", nil, "the credentials providers, of file, exec and vault, which install-config references may use (e.g. \"file,vault\"); env is always enabled")
+	cmd.PersistentFlags().StringVar(&rootOpts.tfstate, "tfstate", "", "where to keep the cluster's Terraform state, instead of the assets directory (e.g. \"s3://bucket/key\" or \"secret://namespace/name\"); destroy finds it from the cluster's metadata")
 	return cmd
 }
 
@@ -92,7 +132,55 @@ func runRootCmd(cmd *cobra.Command, args []string) {
 		DisableLevelTruncation: true,
 	}))
 
+	setupWarnings()
+
 	if err != nil {
 		logrus.Fatal(errors.Wrap(err, "invalid log-level"))
 	}
+
+	if rootOpts.offline {
+		rootCtx = offline.NewContext(rootCtx)
+	}
+	if _, err := download.ParseRateLimit(rootOpts.downloadRateLimit); err != nil {
+		logrus.Fatal(err)
+	}
+	if rootOpts.downloadRateLimit != "" {
+		os.Setenv(download.RateLimitEnvVar, rootOpts.downloadRateLimit)
+	}
+	if len(rootOpts.rhcosMirrors) > 0 {
+		os.Setenv(download.MirrorsEnvVar, strings.Join(rootOpts.rhcosMirrors, ","))
+	}
+	if rootOpts.answers != "" {
+		os.Setenv(answers.EnvVar, rootOpts.answers)
+	}
+	if len(rootOpts.credentialProviders) > 0 {
+		os.Setenv(credentials.EnvVar, strings.Join(rootOpts.credentialProviders, ","))
+	}
+	if rootOpts.offline && credentials.Enabled("vault") {
+		logrus.Fatal("the vault credentials provider reads secrets over the network, which --offline refuses")
+	}
+	if rootOpts.auditFiles != "" {
+		report, err := filepath.Abs(rootOpts.auditFiles)
+		if err == nil {
+			err = fileaudit.Check(report)
+		}
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		os.Setenv(fileaudit.EnvVar, report)
+	}
+	if rootOpts.cacheDir != "" {
+		dir, err := filepath.Abs(rootOpts.cacheDir)
+		if err != nil {
+			logrus.Fatal(errors.Wrap(err, "failed to resolve the cache directory"))
+		}
+		os.Setenv(cache.EnvVar, dir)
+	}
+	if rootOpts.tfstate != "" {
+		if _, err := state.New(rootOpts.dir, rootOpts.tfstate); err != nil {
+			logrus.Fatal(err)
+		}
+		os.Setenv(state.EnvVar, rootOpts.tfstate)
+	}
+	setupNotifier(rootOpts.notifyURL, rootOpts.dir)
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/notify"
+)
+
+var (
+	// notifier is nil unless --notify-url is set, in which case it posts
+	// phase transitions to that webhook.
+	notifier *notify.Notifier
+
+	// failureHook reports fatal errors as failures of the current phase.
+	failureHook = &notify.FailureHook{}
+)
+
+func setupNotifier(url, directory string) {
+	if url == "" {
+		return
+	}
+	notifier = notify.New(rootCtx, url, directory)
+	failureHook.Notifier = notifier
+	logrus.AddHook(failureHook)
+}
+
+// notifyPhase reports the start of phase and returns a function reporting
+// its success.  Fatal errors logged in between are reported as failures of
+// phase.
+func notifyPhase(phase string) func(message string) {
+	failureHook.Phase = phase
+	notifier.Notify(phase, notify.Started, "")
+	return func(message string) {
+		notifier.Notify(phase, notify.Succeeded, message)
+	}
+}
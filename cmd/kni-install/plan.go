@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/plan"
+)
+
+var (
+	planChangeOpts struct {
+		config string
+		output string
+	}
+)
+
+func newPlanChangeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan-change",
+		Short: "Report the impact of changing the install config",
+		Long: `Report the impact of changing the install config.
+
+The install config in --config is compared with the one stored in the
+asset directory's state.  For each changed field, the generated files which
+it changes are listed, and whether the infrastructure changes with them.
+The assets are rendered from both install configs with the same keys, so
+certificates are only listed when a change affects them, although every key
+is regenerated on any change.  Rendering is simulated, so the platform is
+not contacted.
+
+The command exits non-zero if any change would be silently ignored: a field
+which changes no file, a field the installer does not know, or any change
+once the cluster has been created, which must be made with day-2
+operations instead.  Note that changing the install config after
+"create manifests" regenerates the manifests, discarding edits to them.
+
+A table is written to stdout, or a JSON report to --output.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			report, err := runPlanChangeCmd(rootOpts.dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if planChangeOpts.output == "" {
+				err = report.WriteTable(os.Stdout)
+			} else {
+				err = writePlanReport(report, planChangeOpts.output)
+			}
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if report.Unsupported > 0 || report.NoOps > 0 || len(report.Ignored) > 0 {
+				logrus.Fatalf("%d unsupported changes, %d changes without effect and %d unknown fields", report.Unsupported, report.NoOps, len(report.Ignored))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&planChangeOpts.config, "config", "", "the new install config (required)")
+	cmd.Flags().StringVar(&planChangeOpts.output, "output", "", "write a JSON report to this file instead of a table to stdout")
+	return cmd
+}
+
+func runPlanChangeCmd(directory string) (*plan.Report, error) {
+	if planChangeOpts.config == "" {
+		return nil, errors.New("--config is required")
+	}
+	installConfig, err := fileaudit.ReadFile(planChangeOpts.config, "install config")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the new install config")
+	}
+	return plan.Plan(rootCtx, directory, installConfig)
+}
+
+// writePlanReport writes a JSON report to output.
+func writePlanReport(report *plan.Report, output string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the plan report")
+	}
+	data = append(data, '\n')
+	return errors.Wrap(fileaudit.WriteFile(output, data, 0644, "plan report"), "failed to write the plan report")
+}
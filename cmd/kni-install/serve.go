@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+	"github.com/metalkube/kni-installer/pkg/server"
+)
+
+var (
+	serveOpts struct {
+		listen    string
+		tokenFile string
+	}
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a REST API for creating and destroying clusters",
+		Long: `Serve a REST API for creating and destroying clusters.
+
+Each cluster gets its own asset directory under --dir, which also keeps
+the cluster's status, so the clusters survive a restart of the server.
+The API is:
+
+  GET  /v1/clusters                        list clusters
+  POST /v1/clusters                        create a cluster from an install-config body
+  GET  /v1/clusters/{id}                   get the status of a cluster
+  GET  /v1/clusters/{id}/log               stream the install log until the operation finishes
+  GET  /v1/clusters/{id}/artifacts/{path}  fetch metadata.json, auth/kubeconfig or auth/kubeadmin-password
+  GET  /v1/clusters/{id}/bootstrap-content/{sha512}
+                                           fetch bootstrap content for the bootstrap node
+  POST /v1/clusters/{id}/destroy           destroy a cluster
+
+Clients must send "Authorization: Bearer <token>", with the token read from
+--token-file, or generated into serve-token in --dir if it is unset.  Only
+the bootstrap content is served without it.  The token travels in the
+clear, so terminate TLS in front of the server on untrusted networks.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			if err := runServeCmd(rootOpts.dir, serveOpts.listen); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&serveOpts.listen, "listen", "localhost:8080", "address to listen on")
+	cmd.Flags().StringVar(&serveOpts.tokenFile, "token-file", "", "file holding the bearer token clients must send (default: generate one into serve-token in --dir)")
+	return cmd
+}
+
+func runServeCmd(directory, listen string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "failed to find the kni-install executable")
+	}
+
+	token, err := serveToken(directory, serveOpts.tokenFile)
+	if err != nil {
+		return err
+	}
+
+	s, err := server.New(directory, executable, token)
+	if err != nil {
+		return err
+	}
+	if offline.FromContext(rootCtx) {
+		command := s.Command
+		s.Command = func(dir string, args ...string) *exec.Cmd {
+			return command(dir, append([]string{"--offline"}, args...)...)
+		}
+	}
+	logrus.Infof("Serving on %s", listen)
+	return http.ListenAndServe(listen, s)
+}
+
+// serveToken reads the token from tokenFile, or generates one into
+// serve-token in directory if tokenFile is empty.
+func serveToken(directory, tokenFile string) (string, error) {
+	if tokenFile != "" {
+		data, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read the token")
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", errors.Errorf("%s holds no token", tokenFile)
+		}
+		return token, nil
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.Wrap(err, "failed to generate a token")
+	}
+	token := hex.EncodeToString(random)
+	path := filepath.Join(directory, "serve-token")
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", errors.Wrap(err, "failed to write the token")
+	}
+	logrus.Infof("Clients must send the bearer token in %s", path)
+	return token, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rootCtx is cancelled on the first interrupt, so the running operation
+// can stop Terraform and its wait loops cleanly.  A second interrupt exits
+// immediately.
+var rootCtx = context.Background()
+
+func setupInterruptContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	rootCtx = ctx
+
+	signalCh := make(chan os.Signal, 2)
+	signal.Notify(signalCh, interruptSignals...)
+	go func() {
+		sig := <-signalCh
+		logrus.Warnf("Received %s, stopping gracefully; interrupt again to exit immediately", sig)
+		cancel()
+		<-signalCh
+		logrus.Fatal("Interrupted")
+	}()
+}
@@ -0,0 +1,11 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// interruptSignals cancel the running operation.
+var interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
@@ -0,0 +1,10 @@
+// +build windows
+
+package main
+
+import (
+	"os"
+)
+
+// interruptSignals cancel the running operation.
+var interruptSignals = []os.Signal{os.Interrupt}
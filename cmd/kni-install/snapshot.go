@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/installer"
+)
+
+func newSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Snapshot or restore the machines of a development cluster",
+		Long: `Snapshot or restore the machines of a development cluster.
+
+Snapshots are only supported on libvirt.  All of the cluster's domains are
+paused, then their disks and memory are snapshotted, so that the etcd
+members are checkpointed at the same moment.  Restoring reverts every
+domain before resuming them together.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newSnapshotCreateCmd())
+	cmd.AddCommand(newSnapshotRestoreCmd())
+	return cmd
+}
+
+func newSnapshotCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create NAME",
+		Short: "Snapshot all of the cluster's machines",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			if err := installer.CreateSnapshot(rootCtx, rootOpts.dir, args[0]); err != nil {
+				logrus.Fatal(err)
+			}
+			logrus.Infof("Created snapshot %s", args[0])
+		},
+	}
+}
+
+func newSnapshotRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore NAME",
+		Short: "Roll all of the cluster's machines back to a snapshot",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			if err := installer.RestoreSnapshot(rootCtx, rootOpts.dir, args[0]); err != nil {
+				logrus.Fatal(err)
+			}
+			logrus.Infof("Restored snapshot %s", args[0])
+		},
+	}
+}
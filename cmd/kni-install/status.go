@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/installer"
+	"github.com/metalkube/kni-installer/pkg/status"
+)
+
+// serveStatus serves the progress of phases on address, returning an
+// installer.PhaseFunc which records them alongside onPhase, and the
+// tracker to record the end of the command with.
+func serveStatus(address string, phases []status.ExpectedPhase, onPhase installer.PhaseFunc) (installer.PhaseFunc, *status.Tracker, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to listen for status requests")
+	}
+
+	tracker := status.New(phases)
+	logrus.AddHook(tracker)
+	go func() {
+		if err := http.Serve(listener, tracker); err != nil {
+			logrus.Warnf("Status endpoint stopped: %v", err)
+		}
+	}()
+	logrus.Infof("Serving install status on http://%s/status", listener.Addr())
+
+	return func(phase string) func(message string) {
+		tracked := tracker.Start(phase)
+		notified := onPhase(phase)
+		return func(message string) {
+			tracked(message)
+			notified(message)
+		}
+	}, tracker, nil
+}
+
+// finishStatus records the end of the command with err, and keeps serving
+// the final status for linger, so pollers see it before the installer
+// exits.
+func finishStatus(ctx context.Context, tracker *status.Tracker, err error, linger time.Duration) {
+	tracker.Finish(err)
+	if linger <= 0 {
+		return
+	}
+	logrus.Infof("Serving the final install status for %v", linger)
+	select {
+	case <-time.After(linger):
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/installer"
+	"github.com/metalkube/kni-installer/pkg/verify"
+)
+
+var (
+	verifyOpts struct {
+		output string
+	}
+)
+
+func newVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify an OpenShift cluster",
+		Long:  "",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newVerifyClusterCmd())
+	cmd.AddCommand(newVerifyConnectivityCmd())
+	return cmd
+}
+
+func newVerifyClusterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Run post-install smoke tests against an OpenShift cluster",
+		Long: `Run post-install smoke tests against an OpenShift cluster.
+
+The checks cover cluster operator and image registry availability, node
+readiness against the replicas declared in the install config, the default
+storage class on platforms that provide one, and DNS resolution and
+reachability of the API and ingress endpoints.  A JSON report is written to
+stdout (or --output) and the command exits non-zero if any check failed.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			passed, err := runVerifyClusterCmd(rootOpts.dir, verifyOpts.output)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if !passed {
+				logrus.Fatal("Cluster verification failed")
+			}
+			logrus.Info("Cluster verification passed")
+		},
+	}
+	cmd.Flags().StringVar(&verifyOpts.output, "output", "", "write the JSON report to this file instead of stdout")
+	return cmd
+}
+
+func newVerifyConnectivityCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "connectivity",
+		Short: "Check that the installer host can reach everything an install needs",
+		Long: `Check that the installer host can reach everything an install needs.
+
+The checks cover the release image registry, the RHCOS image source, the
+libvirt daemon on bare metal and libvirt, DNS resolution of the api and
+api-int names on platforms where the user provides DNS, and, on bare metal,
+that nothing already answers ARP for the VIPs or the hosts' static
+addresses, and that no host is still running a previous deployment.  They
+also run at the start of "create cluster".  A table of the results is written to stdout,
+or a JSON report to --output, and the command exits non-zero if anything is
+blocked.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			report, err := installer.CheckConnectivity(rootCtx, rootOpts.dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if verifyOpts.output == "" {
+				err = report.WriteTable(os.Stdout)
+			} else {
+				err = writeReport(report, verifyOpts.output)
+			}
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if !report.Passed {
+				logrus.Fatal("Connectivity check failed")
+			}
+			logrus.Info("Connectivity check passed")
+		},
+	}
+	cmd.Flags().StringVar(&verifyOpts.output, "output", "", "write a JSON report to this file instead of a table to stdout")
+	return cmd
+}
+
+func runVerifyClusterCmd(directory, output string) (bool, error) {
+	store, err := assetstore.NewStore(directory)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	if err := store.Fetch(rootCtx, installConfig); err != nil {
+		return false, errors.Wrap(err, "failed to fetch install config")
+	}
+
+	kubeconfig := filepath.Join(directory, "auth", "kubeconfig")
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return false, errors.Wrap(err, "loading kubeconfig")
+	}
+	fileaudit.RecordFile(fileaudit.Read, kubeconfig, "admin kubeconfig")
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return false, errors.Wrap(err, "creating a Kubernetes client")
+	}
+	cc, err := configclient.NewForConfig(config)
+	if err != nil {
+		return false, errors.Wrap(err, "creating a config client")
+	}
+
+	report := verify.Run(verify.ClusterChecks(installConfig.Config, client, cc))
+	if err := writeReport(report, output); err != nil {
+		return false, err
+	}
+	return report.Passed, nil
+}
+
+// writeReport writes a JSON report to output, or stdout if it is empty.
+func writeReport(report *verify.Report, output string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the verification report")
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = fileaudit.WriteFile(output, data, 0644, "verification report")
+	}
+	return errors.Wrap(err, "failed to write the verification report")
+}
@@ -1,25 +1,87 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/updatecheck"
 	"github.com/metalkube/kni-installer/pkg/version"
 )
 
+var (
+	checkOpts struct {
+		check    bool
+		endpoint string
+		release  string
+		channel  string
+	}
+)
+
 func newVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
 		Long:  "",
 		Args:  cobra.ExactArgs(0),
 		RunE:  runVersionCmd,
 	}
+	cmd.Flags().BoolVar(&checkOpts.check, "check", false, "also check the release endpoint for a newer installer build")
+	addCheckFlags(cmd)
+	return cmd
+}
+
+func addCheckFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&checkOpts.endpoint, "endpoint", os.Getenv(updatecheck.EndpointEnvVar), "the URL or local path of the release index")
+	cmd.Flags().StringVar(&checkOpts.release, "release", "", "the OpenShift release, as major.minor, the build must install (defaults to the release image's version)")
+	cmd.Flags().StringVar(&checkOpts.channel, "channel", "stable", "the release channel to pick builds from")
 }
 
 func runVersionCmd(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s %s\n", os.Args[0], version.Raw)
+	if !checkOpts.check {
+		return nil
+	}
+
+	build, err := latestBuild(rootCtx)
+	if err != nil {
+		return err
+	}
+	if build == nil {
+		fmt.Println("No newer installer build is available")
+		return nil
+	}
+	fmt.Printf("A newer installer build is available: %s (%s, SHA-256 %s)\n", build.Version, build.URL, build.SHA256)
 	return nil
 }
+
+// latestBuild returns the newest build on the release endpoint, if it is
+// newer than this installer, or nil.
+func latestBuild(ctx context.Context) (*updatecheck.Build, error) {
+	release := checkOpts.release
+	if release == "" {
+		release = updatecheck.ReleaseFromImage(bootstrap.ReleaseImage())
+	}
+	index, err := updatecheck.FetchIndex(ctx, checkOpts.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	build := updatecheck.Latest(index, release, checkOpts.channel)
+	if build == nil {
+		return nil, nil
+	}
+	newer, err := updatecheck.Newer(build.Version, version.Raw)
+	if err != nil {
+		// A development build, without a release tag, is never up to date.
+		logrus.Debugf("Cannot compare with this installer's version: %v", err)
+		return build, nil
+	}
+	if !newer {
+		return nil, nil
+	}
+	return build, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/metalkube/kni-installer/pkg/installer"
+)
+
+var (
+	waitForInstallCompleteOpts struct {
+		skipConfigRecord bool
+	}
+)
+
+func newWaitForCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait-for",
+		Short: "Wait for install-time events",
+		Long: `Wait for install-time events.
+
+'create cluster' stops once the bootstrap cluster is up.  Once the
+bootstrap resources are destroyed with 'destroy bootstrap', wait for the
+rest of the install here.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newWaitForInstallCompleteCmd())
+	return cmd
+}
+
+func newWaitForInstallCompleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install-complete",
+		Short: "Wait until the cluster is ready",
+		Long: `Wait until the cluster is ready.
+
+This waits for the cluster to initialize, rolls out the compute pools which
+are brought up in waves, waits for the console unless the install-config
+disables it, and stores a redacted copy of the install-config in the
+cluster.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := setupFileHook(rootOpts.dir)
+			defer cleanup()
+
+			info, err := installer.WaitForInstallComplete(rootCtx, installer.WaitForInstallCompleteOptions{
+				Dir:              rootOpts.dir,
+				OnPhase:          installer.PhaseFunc(notifyPhase),
+				SkipConfigRecord: waitForInstallCompleteOpts.skipConfigRecord,
+			})
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if info.ConsoleURL == "" {
+				return
+			}
+			if err := logComplete(rootOpts.dir, info.ConsoleURL); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&waitForInstallCompleteOpts.skipConfigRecord, "skip-config-record", false, "do not store a redacted copy of the install-config and the installer, release image and asset versions in the kube-system/kni-install-config configmap once the cluster is installed")
+	return cmd
+}
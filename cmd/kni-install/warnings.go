@@ -0,0 +1,35 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/warnings"
+)
+
+var (
+	warningsCollector = warnings.NewCollector()
+	reportOnce        sync.Once
+)
+
+// setupWarnings collects the warnings logged, and reports them when the
+// installer exits, even if it exits with a fatal error.
+func setupWarnings() {
+	logrus.AddHook(warningsCollector)
+	logrus.RegisterExitHandler(reportWarnings)
+}
+
+// reportWarnings logs a summary of the warnings collected, and writes them
+// to --warnings-file.
+func reportWarnings() {
+	reportOnce.Do(func() {
+		collected := warningsCollector.Warnings()
+		if rootOpts.warningsFile != "" {
+			if err := warnings.Write(rootOpts.warningsFile, collected); err != nil {
+				logrus.Errorf("Failed to write the warnings: %v", err)
+			}
+		}
+		warnings.Log(logrus.StandardLogger(), collected)
+	})
+}
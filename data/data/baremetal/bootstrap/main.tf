@@ -37,4 +37,65 @@ resource "libvirt_domain" "bootstrap" {
   network_interface {
     bridge = "${var.overcloud_bridge}"
   }
+
+  xml {
+    xslt = "${var.domain_xslt}"
+  }
+}
+
+resource "libvirt_volume" "registry_mirror_base" {
+  count = "${var.registry_mirror_ignition == "" ? 0 : 1}"
+
+  name   = "${var.cluster_id}-bootstrap-registry-mirror-base"
+  source = "${var.image}"
+}
+
+resource "libvirt_volume" "registry_mirror" {
+  count = "${var.registry_mirror_ignition == "" ? 0 : 1}"
+
+  name           = "${var.cluster_id}-bootstrap-registry-mirror"
+  base_volume_id = "${libvirt_volume.registry_mirror_base.id}"
+
+  # The mirrored release payload is around 5GiB; leave room for a few.
+  size = "${64 * 1024 * 1024 * 1024}"
+}
+
+resource "libvirt_ignition" "registry_mirror" {
+  count = "${var.registry_mirror_ignition == "" ? 0 : 1}"
+
+  name    = "${var.cluster_id}-bootstrap-registry-mirror.ign"
+  content = "${var.registry_mirror_ignition}"
+}
+
+resource "libvirt_domain" "registry_mirror" {
+  count = "${var.registry_mirror_ignition == "" ? 0 : 1}"
+
+  name = "${var.cluster_id}-bootstrap-registry-mirror"
+
+  memory = "2048"
+
+  vcpu = "2"
+
+  coreos_ignition = "${libvirt_ignition.registry_mirror.id}"
+
+  disk {
+    volume_id = "${libvirt_volume.registry_mirror.id}"
+  }
+
+  console {
+    type        = "pty"
+    target_port = 0
+  }
+
+  cpu {
+    mode = "host-passthrough"
+  }
+
+  network_interface {
+    bridge = "${var.baremetal_bridge}"
+  }
+
+  xml {
+    xslt = "${var.domain_xslt}"
+  }
 }
@@ -22,3 +22,14 @@ variable "overcloud_bridge" {
   type        = "string"
   description = "The name of the overcloud bridge"
 }
+
+variable "domain_xslt" {
+  type        = "string"
+  description = "XSLT applied to the bootstrap domain to tag it with the cluster infra ID and user tags"
+}
+
+variable "registry_mirror_ignition" {
+  type        = "string"
+  default     = ""
+  description = "The content of the registry mirror ignition file, or empty for no registry mirror."
+}
@@ -10,4 +10,23 @@ module "bootstrap" {
   ignition         = "${var.ignition_bootstrap}"
   baremetal_bridge = "${var.baremetal_bridge}"
   overcloud_bridge = "${var.overcloud_bridge}"
+  domain_xslt      = "${var.domain_xslt}"
+
+  registry_mirror_ignition = "${var.ignition_registry_mirror}"
+}
+
+module "masters" {
+  source = "./masters"
+
+  cluster_id       = "${var.cluster_id}"
+  master_count     = "${var.virtual_master_count}"
+  image            = "${var.os_image}"
+  ignition         = "${var.ignition_master}"
+  memory           = "${var.virtual_master_memory}"
+  vcpu             = "${var.virtual_master_vcpu}"
+  disk_size        = "${var.virtual_master_disk_size}"
+  names            = "${var.virtual_master_names}"
+  macs             = "${var.virtual_master_macs}"
+  baremetal_bridge = "${var.baremetal_bridge}"
+  domain_xslt      = "${var.domain_xslt}"
 }
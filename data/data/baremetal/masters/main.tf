@@ -0,0 +1,57 @@
+resource "libvirt_volume" "base" {
+  count = "${var.master_count > 0 ? 1 : 0}"
+
+  name   = "${var.cluster_id}-master-base"
+  source = "${var.image}"
+}
+
+resource "libvirt_volume" "master" {
+  count = "${var.master_count}"
+
+  name           = "${var.cluster_id}-master-${count.index}"
+  base_volume_id = "${libvirt_volume.base.id}"
+  size           = "${var.disk_size}"
+}
+
+resource "libvirt_ignition" "master" {
+  count = "${var.master_count > 0 ? 1 : 0}"
+
+  name    = "${var.cluster_id}-master.ign"
+  content = "${var.ignition}"
+}
+
+resource "libvirt_domain" "master" {
+  count = "${var.master_count}"
+
+  name = "${var.cluster_id}-master-${count.index}"
+
+  memory = "${var.memory}"
+  vcpu   = "${var.vcpu}"
+
+  coreos_ignition = "${libvirt_ignition.master.id}"
+
+  disk {
+    volume_id = "${element(libvirt_volume.master.*.id, count.index)}"
+  }
+
+  console {
+    type        = "pty"
+    target_port = 0
+  }
+
+  cpu {
+    mode = "host-passthrough"
+  }
+
+  # The machine network is on the baremetal bridge, shared with the
+  # physical workers, and DHCP hands out the address reserved for the MAC.
+  network_interface {
+    bridge   = "${var.baremetal_bridge}"
+    mac      = "${var.macs[count.index]}"
+    hostname = "${var.names[count.index]}"
+  }
+
+  xml {
+    xslt = "${var.domain_xslt}"
+  }
+}
@@ -0,0 +1,55 @@
+variable "cluster_id" {
+  type        = "string"
+  description = "The identifier for the cluster."
+}
+
+variable "master_count" {
+  type        = "string"
+  default     = "0"
+  description = "The number of control plane VMs, or 0 for a physical control plane."
+}
+
+variable "image" {
+  type        = "string"
+  description = "The URL of the OS disk image"
+}
+
+variable "ignition" {
+  type        = "string"
+  description = "The content of the master ignition file."
+}
+
+variable "memory" {
+  type        = "string"
+  description = "The memory of each VM in MiB."
+}
+
+variable "vcpu" {
+  type        = "string"
+  description = "The number of virtual CPUs of each VM."
+}
+
+variable "disk_size" {
+  type        = "string"
+  description = "The size of each VM's disk in bytes."
+}
+
+variable "names" {
+  type        = "list"
+  description = "The hostnames of the VMs."
+}
+
+variable "macs" {
+  type        = "list"
+  description = "The MAC addresses of the VMs on the baremetal bridge."
+}
+
+variable "baremetal_bridge" {
+  type        = "string"
+  description = "The name of the baremetal bridge"
+}
+
+variable "domain_xslt" {
+  type        = "string"
+  description = "XSLT applied to the VMs to tag them with the cluster infra ID and user tags"
+}
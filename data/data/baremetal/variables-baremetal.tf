@@ -17,3 +17,50 @@ variable "overcloud_bridge" {
   type        = "string"
   description = "The name of the overcloud bridge"
 }
+
+variable "domain_xslt" {
+  type        = "string"
+  description = "XSLT applied to libvirt domains to tag them with the cluster infra ID and user tags"
+}
+
+variable "ignition_registry_mirror" {
+  type        = "string"
+  default     = ""
+  description = "The content of the registry mirror ignition file, or empty for no registry mirror."
+}
+
+variable "virtual_master_count" {
+  type        = "string"
+  default     = "0"
+  description = "The number of control plane VMs on the provisioning host, or 0 for a physical control plane."
+}
+
+variable "virtual_master_memory" {
+  type        = "string"
+  default     = "16384"
+  description = "The memory of each control plane VM in MiB."
+}
+
+variable "virtual_master_vcpu" {
+  type        = "string"
+  default     = "4"
+  description = "The number of virtual CPUs of each control plane VM."
+}
+
+variable "virtual_master_disk_size" {
+  type        = "string"
+  default     = "128849018880"
+  description = "The size of each control plane VM's disk in bytes."
+}
+
+variable "virtual_master_names" {
+  type        = "list"
+  default     = []
+  description = "The hostnames of the control plane VMs."
+}
+
+variable "virtual_master_macs" {
+  type        = "list"
+  default     = []
+  description = "The MAC addresses of the control plane VMs on the baremetal bridge."
+}
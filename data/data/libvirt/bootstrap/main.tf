@@ -17,6 +17,17 @@ resource "libvirt_domain" "bootstrap" {
 
   coreos_ignition = "${libvirt_ignition.bootstrap.id}"
 
+  firmware = "${var.firmware}"
+
+  nvram {
+    file     = "/var/lib/libvirt/qemu/nvram/${var.cluster_id}-bootstrap_VARS.fd"
+    template = "${var.nvram_template}"
+  }
+
+  xml {
+    xslt = "${var.domain_xslt}"
+  }
+
   disk {
     volume_id = "${libvirt_volume.bootstrap.id}"
   }
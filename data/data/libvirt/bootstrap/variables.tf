@@ -23,3 +23,21 @@ variable "network_id" {
   type        = "string"
   description = "The ID of a network resource containing the bootstrap node's addresses."
 }
+
+variable "firmware" {
+  type        = "string"
+  default     = ""
+  description = "The path of the UEFI firmware for the bootstrap node, which boots with BIOS if it is empty."
+}
+
+variable "nvram_template" {
+  type        = "string"
+  default     = ""
+  description = "The path of the UEFI variable store template for the bootstrap node."
+}
+
+variable "domain_xslt" {
+  type        = "string"
+  default     = ""
+  description = "An XSLT stylesheet transforming the bootstrap node's domain XML, e.g. to enable secure boot."
+}
@@ -9,6 +9,10 @@ module "volume" {
   image      = "${var.os_image}"
 }
 
+locals {
+  domain_xslt = "${var.libvirt_secure_boot ? file("${path.module}/secure-boot.xsl") : ""}"
+}
+
 module "bootstrap" {
   source = "./bootstrap"
 
@@ -17,6 +21,9 @@ module "bootstrap" {
   cluster_id     = "${var.cluster_id}"
   ignition       = "${var.ignition_bootstrap}"
   network_id     = "${libvirt_network.net.id}"
+  firmware       = "${var.libvirt_firmware}"
+  nvram_template = "${var.libvirt_nvram_template}"
+  domain_xslt    = "${local.domain_xslt}"
 }
 
 resource "libvirt_volume" "master" {
@@ -52,6 +59,8 @@ resource "libvirt_network" "net" {
     hosts = ["${flatten(list(
       data.libvirt_network_dns_host_template.bootstrap.*.rendered,
       data.libvirt_network_dns_host_template.masters.*.rendered,
+      data.libvirt_network_dns_host_template.bootstrap_int.*.rendered,
+      data.libvirt_network_dns_host_template.masters_int.*.rendered,
       data.libvirt_network_dns_host_template.etcds.*.rendered,
     ))}"]
   }]
@@ -69,6 +78,17 @@ resource "libvirt_domain" "master" {
 
   coreos_ignition = "${libvirt_ignition.master.id}"
 
+  firmware = "${var.libvirt_firmware}"
+
+  nvram {
+    file     = "/var/lib/libvirt/qemu/nvram/${var.cluster_id}-master-${count.index}_VARS.fd"
+    template = "${var.libvirt_nvram_template}"
+  }
+
+  xml {
+    xslt = "${local.domain_xslt}"
+  }
+
   disk {
     volume_id = "${element(libvirt_volume.master.*.id, count.index)}"
   }
@@ -101,6 +121,18 @@ data "libvirt_network_dns_host_template" "masters" {
   hostname = "api.${var.cluster_domain}"
 }
 
+data "libvirt_network_dns_host_template" "bootstrap_int" {
+  count    = "${var.bootstrap_dns ? 1 : 0}"
+  ip       = "${var.libvirt_bootstrap_ip}"
+  hostname = "api-int.${var.cluster_domain}"
+}
+
+data "libvirt_network_dns_host_template" "masters_int" {
+  count    = "${var.master_count}"
+  ip       = "${var.libvirt_master_ips[count.index]}"
+  hostname = "api-int.${var.cluster_domain}"
+}
+
 data "libvirt_network_dns_host_template" "etcds" {
   count    = "${var.master_count}"
   ip       = "${var.libvirt_master_ips[count.index]}"
@@ -0,0 +1,27 @@
+<?xml version="1.0"?>
+<!-- Enables UEFI secure boot, which OVMF only supports on the q35 machine
+     type with System Management Mode. -->
+<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:output omit-xml-declaration="yes" indent="yes"/>
+
+  <xsl:template match="node()|@*">
+    <xsl:copy>
+      <xsl:apply-templates select="node()|@*"/>
+    </xsl:copy>
+  </xsl:template>
+
+  <xsl:template match="/domain/os/type/@machine">
+    <xsl:attribute name="machine">q35</xsl:attribute>
+  </xsl:template>
+
+  <xsl:template match="/domain/os/loader/@secure">
+    <xsl:attribute name="secure">yes</xsl:attribute>
+  </xsl:template>
+
+  <xsl:template match="/domain/features">
+    <xsl:copy>
+      <xsl:apply-templates select="node()|@*"/>
+      <smm state="on"/>
+    </xsl:copy>
+  </xsl:template>
+</xsl:stylesheet>
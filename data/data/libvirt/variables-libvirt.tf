@@ -43,3 +43,20 @@ variable "libvirt_master_vcpu" {
   description = "CPUs allocated to masters"
   default     = "4"
 }
+
+variable "libvirt_firmware" {
+  type        = "string"
+  description = "The path of the UEFI firmware (OVMF code) on the libvirt host. Domains boot with BIOS if it is empty."
+  default     = ""
+}
+
+variable "libvirt_nvram_template" {
+  type        = "string"
+  description = "The path of the UEFI variable store template on the libvirt host."
+  default     = ""
+}
+
+variable "libvirt_secure_boot" {
+  description = "Whether UEFI domains boot with secure boot."
+  default     = false
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 )
 
 // Unpack unpacks the assets from this package into a target directory.
@@ -45,5 +47,8 @@ func Unpack(base string, uri string) (err error) {
 	defer out.Close()
 
 	_, err = io.Copy(out, file)
+	if err == nil {
+		fileaudit.RecordFile(fileaudit.Write, base, "Terraform module "+uri)
+	}
 	return err
 }
@@ -33,7 +33,13 @@ fi
 MODE="${MODE:-release}"
 LDFLAGS="${LDFLAGS} -X github.com/metalkube/kni-installer/pkg/version.Raw=$(git describe --always --abbrev=40 --dirty)"
 TAGS="${TAGS:-}"
-OUTPUT="${OUTPUT:-bin/kni-install}"
+GOOS="$(go env GOOS)"
+if test "${GOOS}" = windows
+then
+	OUTPUT="${OUTPUT:-bin/kni-install.exe}"
+else
+	OUTPUT="${OUTPUT:-bin/kni-install}"
+fi
 export CGO_ENABLED=0
 
 case "${MODE}" in
@@ -42,7 +48,7 @@ release)
 	TAGS="${TAGS} release"
 	if test -n "${RELEASE_IMAGE}"
 	then
-		LDFLAGS="${LDFLAGS} -X github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap.defaultReleaseImage=${RELEASE_IMAGE}"
+		LDFLAGS="${LDFLAGS} -X github.com/metalkube/kni-installer/pkg/release.defaultImage=${RELEASE_IMAGE}"
 	fi
 	if test -n "${RHCOS_BUILD_NAME}"
 	then
@@ -62,6 +68,11 @@ esac
 
 if (echo "${TAGS}" | grep -q 'libvirt')
 then
+	if test "${GOOS}" != linux
+	then
+		echo "the libvirt tag is only supported when building for linux, not ${GOOS}" >&2
+		exit 1
+	fi
 	export CGO_ENABLED=1
 fi
 
@@ -0,0 +1,131 @@
+// Package answers records interactive survey answers to a file and replays
+// them on later runs, so an install explored interactively can be repeated
+// unattended.
+package answers
+
+import (
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	survey "gopkg.in/AlecAivazis/survey.v1"
+	"gopkg.in/AlecAivazis/survey.v1/core"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+// EnvVar holds the path of the answers file.  The --answers-file flag sets
+// it, so child processes record to and replay from the same file.
+const EnvVar = "OPENSHIFT_INSTALL_ANSWERS_FILE"
+
+// Ask is survey.Ask, except that questions already answered in the
+// answers file are not asked again, and new answers are recorded to it.
+// Answers to password prompts are never recorded.
+func Ask(qs []*survey.Question, response interface{}, opts ...survey.AskOpt) error {
+	path := os.Getenv(EnvVar)
+	for _, q := range qs {
+		if err := ask(path, q, response, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AskOne is survey.AskOne with the recording and replaying of Ask.
+func AskOne(p survey.Prompt, response interface{}, v survey.Validator, opts ...survey.AskOpt) error {
+	return Ask([]*survey.Question{{Prompt: p, Validate: v}}, response, opts...)
+}
+
+func ask(path string, q *survey.Question, response interface{}, opts ...survey.AskOpt) error {
+	if path == "" {
+		return survey.Ask([]*survey.Question{q}, response, opts...)
+	}
+
+	key := message(q.Prompt)
+	_, secret := q.Prompt.(*survey.Password)
+
+	recorded, err := load(path)
+	if err != nil {
+		return err
+	}
+
+	ans, ok := recorded[key]
+	if ok && !secret {
+		logrus.Debugf("Using the recorded answer for %q from %s", key, path)
+		if q.Validate != nil {
+			if err := q.Validate(ans); err != nil {
+				return errors.Wrapf(err, "invalid recorded answer for %q in %s", key, path)
+			}
+		}
+	} else {
+		// Ask without the transform, so the answer is recorded as the
+		// user gave it and replays through the same validation.
+		asked := map[string]interface{}{}
+		if err := survey.Ask([]*survey.Question{{Name: "answer", Prompt: q.Prompt, Validate: q.Validate}}, &asked, opts...); err != nil {
+			return err
+		}
+		ans = asked["answer"]
+		if !secret {
+			recorded[key] = ans
+			if err := save(path, recorded); err != nil {
+				return err
+			}
+		}
+	}
+
+	if q.Transform != nil {
+		if transformed := q.Transform(ans); transformed != nil {
+			ans = transformed
+		}
+	}
+	return core.WriteAnswer(response, q.Name, ans)
+}
+
+// message returns the message of p, which keys its answer in the file.
+func message(p survey.Prompt) string {
+	switch p := p.(type) {
+	case *survey.Input:
+		return p.Message
+	case *survey.Password:
+		return p.Message
+	case *survey.Select:
+		return p.Message
+	case *survey.MultiSelect:
+		return p.Message
+	case *survey.Confirm:
+		return p.Message
+	case *survey.Editor:
+		return p.Message
+	case *survey.Multiline:
+		return p.Message
+	default:
+		return ""
+	}
+}
+
+func load(path string) (map[string]interface{}, error) {
+	recorded := map[string]interface{}{}
+	data, err := fileaudit.ReadFile(path, "recorded answers")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return recorded, nil
+		}
+		return nil, errors.Wrap(err, "failed to read answers file")
+	}
+	if err := yaml.Unmarshal(data, &recorded); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", path)
+	}
+	if recorded == nil {
+		recorded = map[string]interface{}{}
+	}
+	return recorded, nil
+}
+
+func save(path string, recorded map[string]interface{}) error {
+	data, err := yaml.Marshal(recorded)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal answers")
+	}
+	return errors.Wrap(fileaudit.WriteFile(path, data, 0600, "recorded answers"), "failed to write answers file")
+}
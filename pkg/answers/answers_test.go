@@ -0,0 +1,57 @@
+package answers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	survey "gopkg.in/AlecAivazis/survey.v1"
+)
+
+func TestReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kni-install-answers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "answers.yaml")
+	if err := save(path, map[string]interface{}{
+		"Cluster Name": "test-cluster",
+		"Region":       "us-east-1 (N. Virginia)",
+		"Platform":     "bad",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(EnvVar, path)
+	defer os.Unsetenv(EnvVar)
+
+	var name string
+	assert.NoError(t, AskOne(&survey.Input{Message: "Cluster Name"}, &name, survey.Required))
+	assert.Equal(t, "test-cluster", name)
+
+	var region string
+	assert.NoError(t, Ask([]*survey.Question{{
+		Prompt: &survey.Select{Message: "Region"},
+		Transform: func(ans interface{}) interface{} {
+			return strings.SplitN(ans.(string), " ", 2)[0]
+		},
+	}}, &region))
+	assert.Equal(t, "us-east-1", region)
+
+	var platform string
+	err = AskOne(&survey.Select{Message: "Platform"}, &platform, func(ans interface{}) error {
+		return errors.Errorf("invalid platform %q", ans)
+	})
+	assert.EqualError(t, err, `invalid recorded answer for "Platform" in `+path+`: invalid platform "bad"`)
+}
+
+func TestLoadMissing(t *testing.T) {
+	recorded, err := load(filepath.Join(os.TempDir(), "kni-install-answers-missing.yaml"))
+	assert.NoError(t, err)
+	assert.Empty(t, recorded)
+}
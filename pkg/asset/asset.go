@@ -1,14 +1,16 @@
 package asset
 
 import (
+	"context"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 )
 
 // Asset used to install OpenShift.
@@ -17,7 +19,7 @@ type Asset interface {
 	Dependencies() []Asset
 
 	// Generate generates this asset given the states of its parent assets.
-	Generate(Parents) error
+	Generate(context.Context, Parents) error
 
 	// Name returns the human-friendly name of the asset.
 	Name() string
@@ -36,36 +38,60 @@ type WritableAsset interface {
 	Load(FileFetcher) (found bool, err error)
 }
 
+// AppendableAsset is a WritableAsset which is extended, rather than
+// replaced, when it is regenerated, e.g. a log.
+type AppendableAsset interface {
+	WritableAsset
+
+	// SetPrevious is called before Generate with the state the asset
+	// had, from the target directory or else the state file, if any.
+	SetPrevious(previous Asset)
+}
+
 // File is a file for an Asset.
 type File struct {
 	// Filename is the name of the file.
 	Filename string
 	// Data is the contents of the file.
 	Data []byte
+	// Mode is the permissions of the file, if they differ from those
+	// ModeFor gives its name.
+	Mode os.FileMode `json:",omitempty"`
 }
 
 // PersistToFile writes all of the files of the specified asset into the specified
-// directory.
+// directory, with the modes their FileMode gives, including over existing files.
 func PersistToFile(asset WritableAsset, directory string) error {
 	for _, f := range asset.Files() {
 		path := filepath.Join(directory, f.Filename)
 		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 			return errors.Wrap(err, "failed to create dir")
 		}
-		if err := ioutil.WriteFile(path, f.Data, 0644); err != nil {
+		if err := WriteFile(path, f.Data, f.FileMode(), asset.Name()); err != nil {
 			return errors.Wrap(err, "failed to write file")
 		}
 	}
 	return nil
 }
 
+// WriteFile writes data to path with the given mode, which, unlike
+// ioutil.WriteFile, is also applied if the file exists.
+func WriteFile(path string, data []byte, mode os.FileMode, purpose string) error {
+	if err := fileaudit.WriteFile(path, data, mode, purpose); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
 // DeleteAssetFromDisk removes all the files for asset from disk.
 // this is function is not safe for calling concurrently on the same directory.
 func DeleteAssetFromDisk(asset WritableAsset, directory string) error {
 	logrus.Debugf("Purging asset %q from disk", asset.Name())
 	for _, f := range asset.Files() {
 		path := filepath.Join(directory, f.Filename)
-		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		if err := os.Remove(path); err == nil {
+			fileaudit.Record(fileaudit.Remove, path, asset.Name(), nil)
+		} else if !os.IsNotExist(err) {
 			return errors.Wrap(err, "failed to remove file")
 		}
 
@@ -78,6 +104,7 @@ func DeleteAssetFromDisk(asset WritableAsset, directory string) error {
 			if err := os.Remove(dir); err != nil {
 				return errors.Wrap(err, "failed to remove directory")
 			}
+			fileaudit.Record(fileaudit.Remove, dir, asset.Name(), nil)
 		}
 	}
 	return nil
@@ -1,10 +1,12 @@
 package asset
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,7 +22,7 @@ func (a *persistAsset) Dependencies() []Asset {
 	return []Asset{}
 }
 
-func (a *persistAsset) Generate(Parents) error {
+func (a *persistAsset) Generate(context.Context, Parents) error {
 	return nil
 }
 
@@ -109,3 +111,114 @@ func verifyFilesCreated(t *testing.T, dir string, expectedFiles map[string][]byt
 		t.Errorf("Expected file %q not created", f)
 	}
 }
+
+func TestPersistToFileModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes only mark files read-only on Windows")
+	}
+
+	dir, err := ioutil.TempDir("", "TestPersistToFileModes")
+	if err != nil {
+		t.Skipf("could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// An existing file keeps its mode with ioutil.WriteFile.
+	if err := os.MkdirAll(filepath.Join(dir, "tls"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls", "admin.key"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	asset := &writablePersistAsset{
+		FileList: []*File{
+			{Filename: "tls/admin.key"},
+			{Filename: "auth/kubeconfig"},
+			{Filename: "master.ign"},
+			{Filename: "manifests/cluster-config.yaml"},
+			{Filename: "manifests/secret.yaml", Mode: PrivateMode},
+		},
+	}
+	if !assert.NoError(t, PersistToFile(asset, dir)) {
+		return
+	}
+	for filename, expected := range map[string]os.FileMode{
+		"tls/admin.key":                 0600,
+		"auth/kubeconfig":               0600,
+		"master.ign":                    0640,
+		"manifests/cluster-config.yaml": 0644,
+		"manifests/secret.yaml":         0600,
+	} {
+		info, err := os.Stat(filepath.Join(dir, filename))
+		if assert.NoError(t, err, filename) {
+			assert.Equal(t, expected, info.Mode().Perm(), filename)
+		}
+	}
+}
+
+func TestModeFor(t *testing.T) {
+	cases := []struct {
+		filename string
+		expected os.FileMode
+	}{
+		{filename: "install-config.yaml", expected: PrivateMode},
+		{filename: "auth/kubeadmin-password", expected: PrivateMode},
+		{filename: "tls/kube-apiserver-lb-server.key", expected: PrivateMode},
+		{filename: "terraform.tfvars", expected: PrivateMode},
+		{filename: "terraform.baremetal.auto.tfvars", expected: PrivateMode},
+		{filename: "terraform.tfstate", expected: PrivateMode},
+		{filename: "rhel-worker-user-data.yaml", expected: PrivateMode},
+		{filename: "bootstrap.ign", expected: IgnitionMode},
+		{filename: "tls/kube-apiserver-lb-server.crt", expected: PublicMode},
+		{filename: "manifests/cluster-config.yaml", expected: PublicMode},
+		{filename: "metadata.json", expected: PublicMode},
+	}
+	for _, tc := range cases {
+		t.Run(tc.filename, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ModeFor(tc.filename))
+		})
+	}
+}
+
+func TestSecretMode(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected os.FileMode
+	}{
+		{
+			name:     "secret",
+			data:     "apiVersion: v1\nkind: Secret\nmetadata:\n  name: pull\n",
+			expected: PrivateMode,
+		},
+		{
+			name:     "json secret",
+			data:     `{"apiVersion": "v1", "kind": "Secret"}`,
+			expected: PrivateMode,
+		},
+		{
+			name:     "list of secrets",
+			data:     "kind: List\napiVersion: v1\nitems:\n- apiVersion: v1\n  kind: Secret\n",
+			expected: PrivateMode,
+		},
+		{
+			name:     "secret in a stream",
+			data:     "apiVersion: cert-manager.io/v1\nkind: ClusterIssuer\n---\napiVersion: v1\nkind: Secret\n",
+			expected: PrivateMode,
+		},
+		{
+			name: "config map",
+			data: "apiVersion: v1\nkind: ConfigMap\n",
+		},
+		{
+			name: "certificate",
+			data: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, SecretMode([]byte(tc.data)))
+		})
+	}
+}
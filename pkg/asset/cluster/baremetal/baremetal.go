@@ -10,6 +10,7 @@ import (
 // Metadata converts an install configuration to bare metal metadata.
 func Metadata(infraID string, config *types.InstallConfig) *baremetal.Metadata {
 	return &baremetal.Metadata{
-		URI: config.Platform.BareMetal.URI,
+		URI:       config.Platform.BareMetal.URI,
+		Inventory: baremetal.Inventory(config.Platform.BareMetal.Hosts),
 	}
 }
@@ -0,0 +1,142 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/asset/kubeconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/manifests"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+)
+
+const certificateAuditFileName = "tls/certificate-audit.jsonl"
+
+// CertificateAuditLog is the asset that records every certificate
+// issued by the installer in a signed, hash-chained JSON Lines log.
+// When the certificates are regenerated, the new ones are appended to
+// the log.
+type CertificateAuditLog struct {
+	// Entries is the number of certificates recorded in the log.
+	Entries int
+	// Head is the hex-encoded SHA-256 digest of the last line of the
+	// log.
+	Head string
+
+	File *asset.File
+
+	// previous is the log which is extended.
+	previous []byte
+}
+
+var _ asset.AppendableAsset = (*CertificateAuditLog)(nil)
+
+// auditedOutputs are the assets the installer hands out whose
+// certificates are recorded.
+func auditedOutputs() []asset.Asset {
+	return []asset.Asset{
+		&TerraformVariables{},
+		&bootstrap.RegistryMirror{},
+		&kubeconfig.AdminClient{},
+		&manifests.WorkloadIssuer{},
+		&tls.KubeAPIServerLBFrontendCertKey{},
+	}
+}
+
+// CertKeys returns the certificate assets the installer's outputs depend
+// upon, with each CA ahead of the certificates it signs and the root CA
+// first.
+func CertKeys() []asset.Asset {
+	var certKeys []asset.Asset
+	seen := map[reflect.Type]bool{}
+	var walk func(a asset.Asset)
+	walk = func(a asset.Asset) {
+		if seen[reflect.TypeOf(a)] {
+			return
+		}
+		seen[reflect.TypeOf(a)] = true
+		for _, d := range a.Dependencies() {
+			walk(d)
+		}
+		switch a.(type) {
+		case *tls.UserRootCA:
+			// The user's root CA is not issued by the installer.
+		case *tls.RootCA:
+			certKeys = append([]asset.Asset{a}, certKeys...)
+		case tls.CertKeyInterface:
+			certKeys = append(certKeys, a)
+		}
+	}
+	for _, a := range auditedOutputs() {
+		walk(a)
+	}
+	return certKeys
+}
+
+// Dependencies returns the certificate assets recorded in the log.
+func (a *CertificateAuditLog) Dependencies() []asset.Asset {
+	return CertKeys()
+}
+
+// Generate records the dependent certificates which are not in the log
+// yet.
+func (a *CertificateAuditLog) Generate(ctx context.Context, dependencies asset.Parents) error {
+	assets := CertKeys()
+	dependencies.Get(assets...)
+
+	certKeys := make([]tls.CertInterface, 0, len(assets))
+	for _, certKey := range assets {
+		certKeys = append(certKeys, certKey.(tls.CertInterface))
+	}
+	data, head, err := tls.AppendAuditLog(a.previous, assets[0].(*tls.RootCA), certKeys)
+	if err != nil {
+		return err
+	}
+
+	a.Entries = bytes.Count(data, []byte("\n"))
+	a.Head = head
+	a.File = &asset.File{
+		Filename: certificateAuditFileName,
+		Data:     data,
+	}
+	return nil
+}
+
+// SetPrevious sets the log which Generate extends.
+func (a *CertificateAuditLog) SetPrevious(previous asset.Asset) {
+	if log, ok := previous.(*CertificateAuditLog); ok && log.File != nil {
+		a.previous = log.File.Data
+	}
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *CertificateAuditLog) Name() string {
+	return "Certificate Audit Log"
+}
+
+// Files returns the files generated by the asset.
+func (a *CertificateAuditLog) Files() []*asset.File {
+	if a.File != nil {
+		return []*asset.File{a.File}
+	}
+	return []*asset.File{}
+}
+
+// Load reads the log from the asset directory.
+func (a *CertificateAuditLog) Load(f asset.FileFetcher) (bool, error) {
+	file, err := f.FetchByName(certificateAuditFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	a.Entries = bytes.Count(file.Data, []byte("\n"))
+	a.Head = tls.AuditHead(file.Data)
+	a.File = file
+	return true, nil
+}
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -12,7 +13,9 @@ import (
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 	"github.com/metalkube/kni-installer/pkg/asset/password"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 	"github.com/metalkube/kni-installer/pkg/terraform"
+	"github.com/metalkube/kni-installer/pkg/terraform/state"
 )
 
 var (
@@ -48,8 +51,10 @@ func (c *Cluster) Dependencies() []asset.Asset {
 	}
 }
 
-// Generate launches the cluster and generates the terraform state file on disk.
-func (c *Cluster) Generate(parents asset.Parents) (err error) {
+// Generate launches the cluster and generates the terraform state file on
+// disk, or writes the state to the location set by state.EnvVar, holding
+// its lock.
+func (c *Cluster) Generate(ctx context.Context, parents asset.Parents) (err error) {
 	clusterID := &installconfig.ClusterID{}
 	installConfig := &installconfig.InstallConfig{}
 	terraformVariables := &TerraformVariables{}
@@ -60,16 +65,41 @@ func (c *Cluster) Generate(parents asset.Parents) (err error) {
 		return errors.New("cluster cannot be created with platform set to 'none'")
 	}
 
+	var store state.Store
+	if location := state.Location(); location != "" {
+		store, err = state.New("", location)
+		if err != nil {
+			return err
+		}
+		unlock, err := store.Lock(ctx, "create cluster")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err2 := unlock(); err2 != nil {
+				logrus.Errorf("Failed to unlock the Terraform state in %s: %v", store, err2)
+			}
+		}()
+		if data, err := store.Read(ctx); err != nil {
+			return err
+		} else if data != nil {
+			return errors.Errorf("%s already exists.  There may already be a running cluster", store)
+		}
+	}
+
 	// Copy the terraform.tfvars to a temp directory where the terraform will be invoked within.
 	tmpDir, err := ioutil.TempDir("", "kni-install-")
 	if err != nil {
 		return errors.Wrap(err, "failed to create temp dir for terraform execution")
 	}
-	defer os.RemoveAll(tmpDir)
+	defer func() {
+		os.RemoveAll(tmpDir)
+		fileaudit.Record(fileaudit.Remove, tmpDir, "Terraform working directory", nil)
+	}()
 
 	extraArgs := []string{}
 	for _, file := range terraformVariables.Files() {
-		if err := ioutil.WriteFile(filepath.Join(tmpDir, file.Filename), file.Data, 0600); err != nil {
+		if err := fileaudit.WriteFile(filepath.Join(tmpDir, file.Filename), file.Data, 0600, "Terraform variables"); err != nil {
 			return err
 		}
 		extraArgs = append(extraArgs, fmt.Sprintf("-var-file=%s", filepath.Join(tmpDir, file.Filename)))
@@ -83,27 +113,42 @@ func (c *Cluster) Generate(parents asset.Parents) (err error) {
 	}
 
 	logrus.Infof("Creating cluster...")
-	stateFile, err := terraform.Apply(tmpDir, installConfig.Config.Platform.Name(), extraArgs...)
+	stateFile, err := terraform.Apply(ctx, tmpDir, installConfig.Config.Platform.Name(), extraArgs...)
 	if err != nil {
-		err = errors.Wrap(err, "failed to create cluster")
 		if stateFile == "" {
-			return err
+			return errors.Wrap(err, "failed to create cluster")
+		}
+		debugDir := filepath.Join(terraform.DebugDir, "cluster")
+		for name, data := range terraform.DebugArtifacts(ctx, tmpDir, extraArgs...) {
+			c.FileList = append(c.FileList, &asset.File{
+				Filename: filepath.Join(debugDir, name),
+				Data:     data,
+			})
 		}
+		err = errors.Wrapf(err, "failed to create cluster (Terraform's plan, state and debug log are in %s)", debugDir)
 		// Store the error from the apply, but continue with the
 		// generation so that the Terraform state file is recovered from
 		// the temporary directory.
 	}
 
-	data, err2 := ioutil.ReadFile(stateFile)
+	data, err2 := fileaudit.ReadFile(stateFile, "Terraform state")
 	if err2 == nil {
-		c.FileList = append(c.FileList, &asset.File{
-			Filename: terraform.StateFileName,
-			Data:     data,
-		})
-	} else if err == nil {
-		err = err2
-	} else {
-		logrus.Errorf("Failed to read tfstate: %v", err2)
+		if store != nil {
+			// Save the state even if the apply was interrupted.
+			err2 = store.Write(context.Background(), data)
+		} else {
+			c.FileList = append(c.FileList, &asset.File{
+				Filename: terraform.StateFileName,
+				Data:     data,
+			})
+		}
+	}
+	if err2 != nil {
+		if err == nil {
+			err = err2
+		} else {
+			logrus.Errorf("Failed to save tfstate: %v", err2)
+		}
 	}
 
 	return err
@@ -0,0 +1,13 @@
+// Package fake extracts fake metadata from install configurations.
+package fake
+
+import (
+	"github.com/metalkube/kni-installer/pkg/types/fake"
+)
+
+// Metadata converts an install configuration to fake metadata.
+func Metadata(infraID string) *fake.Metadata {
+	return &fake.Metadata{
+		ClusterID: infraID,
+	}
+}
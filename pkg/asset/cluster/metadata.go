@@ -1,16 +1,20 @@
 package cluster
 
 import (
+	"context"
 	"encoding/json"
-	"io/ioutil"
 	"path/filepath"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/cluster/aws"
 	"github.com/metalkube/kni-installer/pkg/asset/cluster/baremetal"
+	"github.com/metalkube/kni-installer/pkg/asset/cluster/fake"
 	"github.com/metalkube/kni-installer/pkg/asset/cluster/libvirt"
 	"github.com/metalkube/kni-installer/pkg/asset/cluster/openstack"
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/simulate"
+	"github.com/metalkube/kni-installer/pkg/terraform/state"
 	"github.com/metalkube/kni-installer/pkg/types"
 	"github.com/pkg/errors"
 )
@@ -37,23 +41,34 @@ func (m *Metadata) Dependencies() []asset.Asset {
 	return []asset.Asset{
 		&installconfig.ClusterID{},
 		&installconfig.InstallConfig{},
+		&installconfig.Ephemeral{},
+		&CertificateAuditLog{},
 	}
 }
 
 // Generate generates the metadata asset.
-func (m *Metadata) Generate(parents asset.Parents) (err error) {
+func (m *Metadata) Generate(ctx context.Context, parents asset.Parents) (err error) {
 	clusterID := &installconfig.ClusterID{}
 	installConfig := &installconfig.InstallConfig{}
-	parents.Get(clusterID, installConfig)
+	ephemeral := &installconfig.Ephemeral{}
+	auditLog := &CertificateAuditLog{}
+	parents.Get(clusterID, installConfig, ephemeral, auditLog)
 
 	if installConfig.Config.Platform.None != nil {
 		return nil
 	}
 
 	metadata := &types.ClusterMetadata{
-		ClusterName: installConfig.Config.ObjectMeta.Name,
-		ClusterID:   clusterID.UUID,
-		InfraID:     clusterID.InfraID,
+		ClusterName:    installConfig.Config.ObjectMeta.Name,
+		ClusterID:      clusterID.UUID,
+		InfraID:        clusterID.InfraID,
+		ExpiresAt:      ephemeral.ExpiresAt,
+		Simulated:      simulate.FromContext(ctx),
+		TerraformState: state.Location(),
+		CertificateAudit: &types.CertificateAuditMetadata{
+			Entries: auditLog.Entries,
+			Head:    auditLog.Head,
+		},
 	}
 
 	switch {
@@ -65,6 +80,8 @@ func (m *Metadata) Generate(parents asset.Parents) (err error) {
 		metadata.ClusterPlatformMetadata.OpenStack = openstack.Metadata(clusterID.InfraID, installConfig.Config)
 	case installConfig.Config.Platform.BareMetal != nil:
 		metadata.ClusterPlatformMetadata.BareMetal = baremetal.Metadata(clusterID.InfraID, installConfig.Config)
+	case installConfig.Config.Platform.Fake != nil:
+		metadata.ClusterPlatformMetadata.Fake = fake.Metadata(clusterID.InfraID)
 	default:
 		return errors.Errorf("no known platform")
 	}
@@ -99,7 +116,7 @@ func (m *Metadata) Load(f asset.FileFetcher) (found bool, err error) {
 // LoadMetadata loads the cluster metadata from an asset directory.
 func LoadMetadata(dir string) (*types.ClusterMetadata, error) {
 	path := filepath.Join(dir, metadataFileName)
-	raw, err := ioutil.ReadFile(path)
+	raw, err := fileaudit.ReadFile(path, "cluster metadata")
 	if err != nil {
 		return nil, err
 	}
@@ -111,3 +128,13 @@ func LoadMetadata(dir string) (*types.ClusterMetadata, error) {
 
 	return metadata, err
 }
+
+// SaveMetadata replaces the cluster metadata in an asset directory, e.g.
+// to record how long the install took once it has completed.
+func SaveMetadata(dir string, metadata *types.ClusterMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.Wrap(err, "failed to Marshal ClusterMetadata")
+	}
+	return fileaudit.WriteFile(filepath.Join(dir, metadataFileName), data, 0644, "cluster metadata")
+}
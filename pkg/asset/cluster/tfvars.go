@@ -1,9 +1,11 @@
 package cluster
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/ghodss/yaml"
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
 	"github.com/metalkube/kni-installer/pkg/asset/ignition/machine"
@@ -13,16 +15,20 @@ import (
 	"github.com/metalkube/kni-installer/pkg/tfvars"
 	awstfvars "github.com/metalkube/kni-installer/pkg/tfvars/aws"
 	baremetaltfvars "github.com/metalkube/kni-installer/pkg/tfvars/baremetal"
+	faketfvars "github.com/metalkube/kni-installer/pkg/tfvars/fake"
 	libvirttfvars "github.com/metalkube/kni-installer/pkg/tfvars/libvirt"
 	openstacktfvars "github.com/metalkube/kni-installer/pkg/tfvars/openstack"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	"github.com/metalkube/kni-installer/pkg/types/none"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
 	libvirtprovider "github.com/openshift/cluster-api-provider-libvirt/pkg/apis/libvirtproviderconfig/v1alpha1"
+	machineapi "github.com/openshift/cluster-api/pkg/apis/machine/v1beta1"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
 	awsprovider "sigs.k8s.io/cluster-api-provider-aws/pkg/apis/awsproviderconfig/v1beta1"
 	openstackprovider "sigs.k8s.io/cluster-api-provider-openstack/pkg/apis/openstackproviderconfig/v1alpha1"
 )
@@ -60,13 +66,14 @@ func (t *TerraformVariables) Dependencies() []asset.Asset {
 		&installconfig.InstallConfig{},
 		new(rhcos.Image),
 		&bootstrap.Bootstrap{},
+		&bootstrap.RegistryMirror{},
 		&machine.Master{},
 		&machines.Master{},
 	}
 }
 
 // Generate generates the terraform.tfvars file.
-func (t *TerraformVariables) Generate(parents asset.Parents) error {
+func (t *TerraformVariables) Generate(ctx context.Context, parents asset.Parents) error {
 	clusterID := &installconfig.ClusterID{}
 	installConfig := &installconfig.InstallConfig{}
 	bootstrapIgnAsset := &bootstrap.Bootstrap{}
@@ -75,11 +82,25 @@ func (t *TerraformVariables) Generate(parents asset.Parents) error {
 	rhcosImage := new(rhcos.Image)
 	parents.Get(clusterID, installConfig, bootstrapIgnAsset, masterIgnAsset, mastersAsset, rhcosImage)
 
-	bootstrapIgn := string(bootstrapIgnAsset.Files()[0].Data)
-	masterIgn := string(masterIgnAsset.Files()[0].Data)
+	bootstrapIgn, err := ignitionConfig(bootstrapIgnAsset)
+	if err != nil {
+		return err
+	}
+	masterIgn, err := ignitionConfig(masterIgnAsset)
+	if err != nil {
+		return err
+	}
 
 	masters := mastersAsset.Machines()
 	masterCount := len(masters)
+	if masterCount == 0 {
+		return errors.Errorf("master slice cannot be empty")
+	}
+
+	if installConfig.Config.Networking == nil || installConfig.Config.Networking.MachineCIDR == nil {
+		return errors.New("no machine CIDR")
+	}
+
 	data, err := tfvars.TFVars(
 		clusterID.InfraID,
 		installConfig.Config.ClusterDomain(),
@@ -99,10 +120,6 @@ func (t *TerraformVariables) Generate(parents asset.Parents) error {
 		},
 	}
 
-	if masterCount == 0 {
-		return errors.Errorf("master slice cannot be empty")
-	}
-
 	switch platform := installConfig.Config.Platform.Name(); platform {
 	case aws.Name:
 		masters, err := mastersAsset.StructuredMachines()
@@ -111,7 +128,15 @@ func (t *TerraformVariables) Generate(parents asset.Parents) error {
 		}
 		masterConfigs := make([]*awsprovider.AWSMachineProviderConfig, len(masters))
 		for i, m := range masters {
-			masterConfigs[i] = m.Spec.ProviderSpec.Value.Object.(*awsprovider.AWSMachineProviderConfig)
+			object, err := providerSpec(m)
+			if err != nil {
+				return err
+			}
+			config, ok := object.(*awsprovider.AWSMachineProviderConfig)
+			if !ok || config == nil {
+				return errors.Errorf("master %s has a %T provider spec, not an AWS one", m.Name, object)
+			}
+			masterConfigs[i] = config
 		}
 		data, err := awstfvars.TFVars(masterConfigs)
 		if err != nil {
@@ -126,12 +151,25 @@ func (t *TerraformVariables) Generate(parents asset.Parents) error {
 		if err != nil {
 			return err
 		}
+		object, err := providerSpec(masters[0])
+		if err != nil {
+			return err
+		}
+		masterConfig, ok := object.(*libvirtprovider.LibvirtMachineProviderConfig)
+		if !ok || masterConfig == nil {
+			return errors.Errorf("master %s has a %T provider spec, not a libvirt one", masters[0].Name, object)
+		}
+		if installConfig.Config.Platform.Libvirt.Network == nil {
+			return errors.New("no libvirt network")
+		}
 		data, err = libvirttfvars.TFVars(
-			masters[0].Spec.ProviderSpec.Value.Object.(*libvirtprovider.LibvirtMachineProviderConfig),
+			ctx,
+			masterConfig,
 			string(*rhcosImage),
 			&installConfig.Config.Networking.MachineCIDR.IPNet,
 			installConfig.Config.Platform.Libvirt.Network.IfName,
 			masterCount,
+			installConfig.Config.Platform.Libvirt.Firmware,
 		)
 		if err != nil {
 			return errors.Wrapf(err, "failed to get %s Terraform variables", platform)
@@ -140,14 +178,37 @@ func (t *TerraformVariables) Generate(parents asset.Parents) error {
 			Filename: fmt.Sprintf(TfPlatformVarsFileName, platform),
 			Data:     data,
 		})
+	case fake.Name:
+		masters := make([]machineapi.Machine, len(mastersAsset.Machines()))
+		for i, data := range mastersAsset.Machines() {
+			if err := yaml.Unmarshal(data, &masters[i]); err != nil {
+				return errors.Wrapf(err, "unmarshal master %d", i)
+			}
+		}
+		data, err = faketfvars.TFVars(masters)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s Terraform variables", platform)
+		}
+		t.FileList = append(t.FileList, &asset.File{
+			Filename: fmt.Sprintf(TfPlatformVarsFileName, platform),
+			Data:     data,
+		})
 	case none.Name:
 	case openstack.Name:
 		masters, err := mastersAsset.StructuredMachines()
 		if err != nil {
 			return err
 		}
+		object, err := providerSpec(masters[0])
+		if err != nil {
+			return err
+		}
+		masterConfig, ok := object.(*openstackprovider.OpenstackProviderSpec)
+		if !ok || masterConfig == nil {
+			return errors.Errorf("master %s has a %T provider spec, not an OpenStack one", masters[0].Name, object)
+		}
 		data, err = openstacktfvars.TFVars(
-			masters[0].Spec.ProviderSpec.Value.Object.(*openstackprovider.OpenstackProviderSpec),
+			masterConfig,
 			installConfig.Config.Platform.OpenStack.Region,
 			installConfig.Config.Platform.OpenStack.ExternalNetwork,
 			installConfig.Config.Platform.OpenStack.LbFloatingIP,
@@ -161,12 +222,25 @@ func (t *TerraformVariables) Generate(parents asset.Parents) error {
 			Data:     data,
 		})
 	case baremetal.Name:
+		registryMirrorIgnAsset := &bootstrap.RegistryMirror{}
+		parents.Get(registryMirrorIgnAsset)
+		var registryMirrorIgn string
+		if files := registryMirrorIgnAsset.Files(); len(files) > 0 {
+			registryMirrorIgn = string(files[0].Data)
+		}
 		// FIXME:: baremetal
 		data, err = baremetaltfvars.TFVars(
+			ctx,
+			clusterID.InfraID,
 			installConfig.Config.Platform.BareMetal.URI,
 			string(*rhcosImage),
 			"baremetal",
-			"provisioning")
+			"provisioning",
+			installConfig.Config.Platform.BareMetal.UserTags,
+			registryMirrorIgn,
+			installConfig.Config.Platform.BareMetal.VirtualControlPlane,
+			installConfig.Config.Platform.BareMetal.Hosts,
+			masterCount)
 		if err != nil {
 			return errors.Wrapf(err, "failed to get %s Terraform variables", platform)
 		}
@@ -181,6 +255,23 @@ func (t *TerraformVariables) Generate(parents asset.Parents) error {
 	return nil
 }
 
+// ignitionConfig returns the Ignition config generated by the asset.
+func ignitionConfig(a asset.WritableAsset) (string, error) {
+	files := a.Files()
+	if len(files) == 0 {
+		return "", errors.Errorf("no %s", a.Name())
+	}
+	return string(files[0].Data), nil
+}
+
+// providerSpec returns the decoded provider spec of the machine.
+func providerSpec(machine machineapi.Machine) (runtime.Object, error) {
+	if machine.Spec.ProviderSpec.Value == nil || machine.Spec.ProviderSpec.Value.Object == nil {
+		return nil, errors.Errorf("master %s has no provider spec", machine.Name)
+	}
+	return machine.Spec.ProviderSpec.Value.Object, nil
+}
+
 // Files returns the files generated by the asset.
 func (t *TerraformVariables) Files() []*asset.File {
 	return t.FileList
@@ -0,0 +1,91 @@
+package asset
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+const (
+	// PrivateMode is the mode of files holding secrets: private keys,
+	// kubeconfigs, passwords, the pull secret in the install-config and the
+	// RHEL worker user data, and the Ignition configs passed to Terraform.
+	PrivateMode os.FileMode = 0600
+
+	// IgnitionMode is the mode of Ignition configs, which a web server in
+	// the owner's group may need to serve to booting machines.
+	IgnitionMode os.FileMode = 0640
+
+	// PublicMode is the mode of all other files, such as manifests,
+	// certificates and metadata.
+	PublicMode os.FileMode = 0644
+)
+
+// ModeFor returns the mode of the file with the given name, relative to
+// the asset directory.
+func ModeFor(filename string) os.FileMode {
+	filename = filepath.ToSlash(filename)
+	base := filepath.Base(filename)
+	switch {
+	case strings.HasSuffix(base, ".key"),
+		strings.HasPrefix(filename, "auth/"),
+		strings.HasPrefix(base, "terraform.") && (strings.Contains(base, ".tfvars") || strings.Contains(base, ".tfstate")),
+		base == "install-config.yaml",
+		strings.HasSuffix(base, "-user-data.yaml"):
+		return PrivateMode
+	case strings.HasSuffix(base, ".ign"):
+		return IgnitionMode
+	default:
+		return PublicMode
+	}
+}
+
+// SecretMode returns PrivateMode if data is a manifest holding a Secret,
+// either a Secret, a List with a Secret among its items or a multi-document
+// YAML stream with a Secret in it, and zero otherwise.
+func SecretMode(data []byte) os.FileMode {
+	for _, document := range bytes.Split(data, []byte("\n---")) {
+		var manifest struct {
+			Kind  string `json:"kind"`
+			Items []struct {
+				Kind string `json:"kind"`
+			} `json:"items"`
+		}
+		if err := yaml.Unmarshal(document, &manifest); err != nil {
+			continue
+		}
+		if manifest.Kind == "Secret" {
+			return PrivateMode
+		}
+		for _, item := range manifest.Items {
+			if item.Kind == "Secret" {
+				return PrivateMode
+			}
+		}
+	}
+	return 0
+}
+
+// FileMode returns the mode the file is written with: its Mode if that is
+// set, else the mode ModeFor gives its name.
+func (f *File) FileMode() os.FileMode {
+	if f.Mode != 0 {
+		return f.Mode.Perm()
+	}
+	return ModeFor(f.Filename)
+}
+
+// VerifyFile checks that the file at path, which is to be loaded, is
+// owned by the installer's user and is no more permissive than mode.
+// Permissions beyond mode, e.g. of an install-config copied into the
+// asset directory, are removed with a warning.
+func VerifyFile(path string, mode os.FileMode) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return verifyFile(path, info, mode)
+}
@@ -0,0 +1,30 @@
+// +build !windows
+
+package asset
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/warnings"
+)
+
+// verifyFile refuses files owned by another user, unless the installer
+// runs as root, and removes permissions beyond mode.
+func verifyFile(path string, info os.FileInfo, mode os.FileMode) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		uid := os.Getuid()
+		if uid != 0 && int(stat.Uid) != uid {
+			return errors.Errorf("%s is owned by uid %d, not the installer's uid %d", path, stat.Uid, uid)
+		}
+	}
+
+	perm := info.Mode().Perm()
+	if perm&^mode == 0 {
+		return nil
+	}
+	warnings.Warnf(warnings.Weak, "%s has mode %#o, more permissive than %#o; changing it to %#o", path, perm, mode, perm&mode)
+	return errors.Wrapf(os.Chmod(path, perm&mode), "failed to change the mode of %s", path)
+}
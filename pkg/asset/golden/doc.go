@@ -0,0 +1,11 @@
+// Package golden holds tests which render the asset tree from canned
+// install configs and compare the output against golden files, so
+// changes to the generated manifests and Ignition configs show up in
+// review as diffs of the golden files.
+//
+// Each case is a directory under a testdata directory holding an
+// install-config.yaml, with the expected output under its golden
+// directory.  After an intended change, regenerate the golden files with:
+//
+//	go test ./pkg/asset/golden -args -update-golden
+package golden
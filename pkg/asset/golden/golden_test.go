@@ -0,0 +1,149 @@
+package golden
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/data"
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/render"
+	"github.com/metalkube/kni-installer/pkg/asset/render/rendertest"
+	assettargets "github.com/metalkube/kni-installer/pkg/asset/targets"
+)
+
+const (
+	// installConfigFilename is the name of each case's install config.
+	installConfigFilename = "install-config.yaml"
+
+	// goldenDir is the directory of each case's expected output.
+	goldenDir = "golden"
+)
+
+var update = flag.Bool("update-golden", false, "rewrite the golden files from the generated assets")
+
+// targets are the assets rendered for each case.
+var targets = [][]asset.WritableAsset{
+	assettargets.Manifests,
+	assettargets.IgnitionConfigs,
+}
+
+func TestGolden(t *testing.T) {
+	data.Assets = http.Dir("../../../data/data")
+	check(t, "testdata")
+}
+
+// check renders every case under dir and compares the output with the
+// case's golden files, or rewrites them with -update-golden.
+func check(t *testing.T, dir string) {
+	cases, err := ioutil.ReadDir(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, c := range cases {
+		if !c.IsDir() {
+			continue
+		}
+		caseDir := filepath.Join(dir, c.Name())
+		t.Run(c.Name(), func(t *testing.T) {
+			installConfig, err := ioutil.ReadFile(filepath.Join(caseDir, installConfigFilename))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			rendered, err := render.Render(context.Background(), installConfig, targets, rendertest.Sources)
+			if !assert.NoError(t, err) {
+				return
+			}
+			files := make(map[string][]byte, len(rendered))
+			for name, file := range rendered {
+				files[name] = file.Data
+			}
+
+			if *update {
+				assert.NoError(t, write(filepath.Join(caseDir, goldenDir), files))
+				return
+			}
+			compare(t, filepath.Join(caseDir, goldenDir), files)
+		})
+	}
+}
+
+// compare reports every golden file which is missing from, differs from
+// or is not in files, in order of path.
+func compare(t *testing.T, dir string, files map[string][]byte) {
+	expected, err := read(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	for name := range expected {
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		want, wanted := expected[name]
+		got, generated := files[name]
+		switch {
+		case !wanted:
+			t.Errorf("%s was generated, but has no golden file", name)
+		case !generated:
+			t.Errorf("%s has a golden file, but was not generated", name)
+		default:
+			assert.Equalf(t, string(want), string(got), "%s differs from its golden file", name)
+		}
+	}
+}
+
+// read returns the files under dir keyed by their slash-separated path
+// relative to it.
+func read(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+// write replaces the contents of dir with files.
+func write(dir string, files map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -48,7 +48,14 @@ fi
 
 API_DNS=$(sudo awk -F[/:] '/apiServerURL/ {print $5}' /opt/openshift/manifests/cluster-infrastructure-02-config.yml)
 export MASTER_VIP=$(dig +noall +answer "$API_DNS" | awk '{print $NF}')
-env INTERFACE=$(get_iface_in_vip_subnet "$MASTER_VIP") envsubst < /etc/keepalived/keepalived.conf.tmpl > /etc/keepalived/keepalived.conf
+INTERFACE=$(get_iface_in_vip_subnet "$MASTER_VIP" || true)
+if [ -z "$INTERFACE" ]; then
+    # With routed machine networks, only the control plane's subnet can
+    # carry the API VIP.
+    echo "No interface is on the subnet of the API VIP $MASTER_VIP; attach the bootstrap host to the control plane's machine network" >&2
+    exit 1
+fi
+env INTERFACE="$INTERFACE" envsubst < /etc/keepalived/keepalived.conf.tmpl > /etc/keepalived/keepalived.conf
 
 podman run \
         --rm \
@@ -0,0 +1,278 @@
+#!/usr/bin/env bash
+set -e
+
+mkdir --parents /etc/kubernetes/{manifests,bootstrap-configs,bootstrap-manifests}
+
+if ! podman inspect registry.svc.ci.openshift.org/openshift/origin-release:v4.0 &>/dev/null; then
+    echo "Pulling release image..."
+    podman pull --quiet registry.svc.ci.openshift.org/openshift/origin-release:v4.0
+fi
+
+# convert the release image pull spec to an "absolute" form if a digest is available - this is
+# safe to resolve after the actions above because podman will not pull the image once it is
+# locally available
+if ! release=$( podman inspect registry.svc.ci.openshift.org/openshift/origin-release:v4.0 -f '{{ index .RepoDigests 0 }}' ) || [[ -z "${release}" ]]; then
+	echo "Warning: Could not resolve release image to pull by digest" 2>&1
+	release="registry.svc.ci.openshift.org/openshift/origin-release:v4.0"
+fi
+
+MACHINE_CONFIG_OPERATOR_IMAGE=$(podman run --quiet --rm ${release} image machine-config-operator)
+MACHINE_CONFIG_CONTROLLER_IMAGE=$(podman run --quiet --rm ${release} image machine-config-controller)
+MACHINE_CONFIG_SERVER_IMAGE=$(podman run --quiet --rm ${release} image machine-config-server)
+MACHINE_CONFIG_DAEMON_IMAGE=$(podman run --quiet --rm ${release} image machine-config-daemon)
+MACHINE_CONFIG_OSCONTENT=$(podman run --quiet --rm ${release} image machine-os-content)
+MACHINE_CONFIG_ETCD_IMAGE=$(podman run --quiet --rm ${release} image etcd)
+MACHINE_CONFIG_SETUP_ETCD_ENV_IMAGE=$(podman run --quiet --rm ${release} image setup-etcd-environment)
+MACHINE_CONFIG_INFRA_IMAGE=$(podman run --quiet --rm ${release} image pod)
+
+CONFIG_OPERATOR_IMAGE=$(podman run --quiet --rm ${release} image cluster-config-operator)
+KUBE_APISERVER_OPERATOR_IMAGE=$(podman run --quiet --rm ${release} image cluster-kube-apiserver-operator)
+KUBE_CONTROLLER_MANAGER_OPERATOR_IMAGE=$(podman run --quiet --rm ${release} image cluster-kube-controller-manager-operator)
+KUBE_SCHEDULER_OPERATOR_IMAGE=$(podman run --quiet --rm ${release} image cluster-kube-scheduler-operator)
+
+OPENSHIFT_HYPERSHIFT_IMAGE=$(podman run --quiet --rm ${release} image hypershift)
+OPENSHIFT_HYPERKUBE_IMAGE=$(podman run --quiet --rm ${release} image hyperkube)
+
+CLUSTER_BOOTSTRAP_IMAGE=$(podman run --quiet --rm ${release} image cluster-bootstrap)
+
+mkdir --parents ./{bootstrap-manifests,manifests}
+
+if [ ! -f cvo-bootstrap.done ]
+then
+	echo "Rendering Cluster Version Operator Manifests..."
+
+	rm -rf cvo-bootstrap
+
+	# shellcheck disable=SC2154
+	podman run \
+		--quiet \
+		--volume "$PWD:/assets:z" \
+		"${release}" \
+		render \
+			--output-dir=/assets/cvo-bootstrap \
+			--release-image="${release}"
+
+	cp cvo-bootstrap/bootstrap/* bootstrap-manifests/
+	cp cvo-bootstrap/manifests/* manifests/
+	## FIXME: CVO should use `/etc/kubernetes/bootstrap-secrets/kubeconfig` instead
+	cp auth/kubeconfig /etc/kubernetes/kubeconfig
+
+	touch cvo-bootstrap.done
+fi
+
+if [ ! -f config-bootstrap.done ]
+then
+	echo "Rendering cluster config manifests..."
+
+	rm -rf config-bootstrap
+
+	# shellcheck disable=SC2154
+	podman run \
+		--quiet \
+		--volume "$PWD:/assets:z" \
+		"${CONFIG_OPERATOR_IMAGE}" \
+		/usr/bin/cluster-config-operator render \
+		--config-output-file=/assets/config-bootstrap/config \
+		--asset-input-dir=/assets/tls \
+		--asset-output-dir=/assets/config-bootstrap
+
+	cp config-bootstrap/manifests/* manifests/
+
+	touch config-bootstrap.done
+fi
+
+if [ ! -f kube-apiserver-bootstrap.done ]
+then
+	echo "Rendering Kubernetes API server core manifests..."
+
+	rm -rf kube-apiserver-bootstrap
+
+	# shellcheck disable=SC2154
+	podman run \
+		--quiet \
+		--volume "$PWD:/assets:z" \
+		"${KUBE_APISERVER_OPERATOR_IMAGE}" \
+		/usr/bin/cluster-kube-apiserver-operator render \
+		--manifest-etcd-serving-ca=etcd-client-ca.crt \
+		--manifest-etcd-server-urls=https://etcd-0.single.example.com:2379 \
+		--manifest-image=${OPENSHIFT_HYPERSHIFT_IMAGE} \
+		--asset-input-dir=/assets/tls \
+		--asset-output-dir=/assets/kube-apiserver-bootstrap \
+		--config-output-file=/assets/kube-apiserver-bootstrap/config \
+		--config-override-files=/assets/kube-apiserver-config-overrides.yaml \
+		--cluster-config-file=/assets/openshift/99_openshift-cluster-api_cluster.yaml
+
+	cp kube-apiserver-bootstrap/config /etc/kubernetes/bootstrap-configs/kube-apiserver-config.yaml
+	cp kube-apiserver-bootstrap/bootstrap-manifests/* bootstrap-manifests/
+	cp kube-apiserver-bootstrap/manifests/* manifests/
+
+	touch kube-apiserver-bootstrap.done
+fi
+
+if [ ! -f kube-controller-manager-bootstrap.done ]
+then
+	echo "Rendering Kubernetes Controller Manager core manifests..."
+
+	rm -rf kube-controller-manager-bootstrap
+
+	# shellcheck disable=SC2154
+	podman run \
+		--quiet \
+		--volume "$PWD:/assets:z" \
+		"${KUBE_CONTROLLER_MANAGER_OPERATOR_IMAGE}" \
+		/usr/bin/cluster-kube-controller-manager-operator render \
+		--manifest-image=${OPENSHIFT_HYPERKUBE_IMAGE} \
+		--asset-input-dir=/assets/tls \
+		--asset-output-dir=/assets/kube-controller-manager-bootstrap \
+		--config-output-file=/assets/kube-controller-manager-bootstrap/config \
+		--cluster-config-file=/assets/openshift/99_openshift-cluster-api_cluster.yaml
+
+	cp kube-controller-manager-bootstrap/config /etc/kubernetes/bootstrap-configs/kube-controller-manager-config.yaml
+	cp kube-controller-manager-bootstrap/bootstrap-manifests/* bootstrap-manifests/
+	cp kube-controller-manager-bootstrap/manifests/* manifests/
+
+	touch kube-controller-manager-bootstrap.done
+fi
+
+if [ ! -f kube-scheduler-bootstrap.done ]
+then
+	echo "Rendering Kubernetes Scheduler core manifests..."
+
+	rm -rf kube-scheduler-bootstrap
+
+	# shellcheck disable=SC2154
+	podman run \
+		--quiet \
+		--volume "$PWD:/assets:z" \
+		"${KUBE_SCHEDULER_OPERATOR_IMAGE}" \
+		/usr/bin/cluster-kube-scheduler-operator render \
+		--manifest-image=${OPENSHIFT_HYPERKUBE_IMAGE} \
+		--asset-input-dir=/assets/tls \
+		--asset-output-dir=/assets/kube-scheduler-bootstrap \
+		--config-output-file=/assets/kube-scheduler-bootstrap/config
+
+	cp kube-scheduler-bootstrap/config /etc/kubernetes/bootstrap-configs/kube-scheduler-config.yaml
+	cp kube-scheduler-bootstrap/bootstrap-manifests/* bootstrap-manifests/
+	cp kube-scheduler-bootstrap/manifests/* manifests/
+
+	touch kube-scheduler-bootstrap.done
+fi
+
+if [ ! -f mco-bootstrap.done ]
+then
+	echo "Rendering MCO manifests..."
+
+	rm -rf mco-bootstrap
+
+	# shellcheck disable=SC2154
+	podman run \
+		--quiet \
+		--user 0 \
+		--volume "$PWD:/assets:z" \
+		"${MACHINE_CONFIG_OPERATOR_IMAGE}" \
+		bootstrap \
+			--etcd-ca=/assets/tls/etcd-client-ca.crt \
+			--root-ca=/assets/tls/root-ca.crt \
+			--kube-ca=/assets/tls/kube-ca.crt \
+			--config-file=/assets/manifests/cluster-config.yaml \
+			--dest-dir=/assets/mco-bootstrap \
+			--pull-secret=/assets/manifests/pull.json \
+			--etcd-image=${MACHINE_CONFIG_ETCD_IMAGE} \
+			--setup-etcd-env-image=${MACHINE_CONFIG_SETUP_ETCD_ENV_IMAGE} \
+			--machine-config-controller-image=${MACHINE_CONFIG_CONTROLLER_IMAGE} \
+			--machine-config-server-image=${MACHINE_CONFIG_SERVER_IMAGE} \
+			--machine-config-daemon-image=${MACHINE_CONFIG_DAEMON_IMAGE} \
+			--machine-config-oscontent-image=${MACHINE_CONFIG_OSCONTENT} \
+			--infra-image=${MACHINE_CONFIG_INFRA_IMAGE}
+
+	# Bootstrap MachineConfigController uses /etc/mcc/bootstrap/manifests/ dir to
+	# 1. read the controller config rendered by MachineConfigOperator
+	# 2. read the default MachineConfigPools rendered by MachineConfigOperator
+	# 3. read any additional MachineConfigs that are needed for the default MachineConfigPools.
+	mkdir --parents /etc/mcc/bootstrap /etc/mcs/bootstrap /etc/kubernetes/manifests
+	cp mco-bootstrap/bootstrap/manifests/* /etc/mcc/bootstrap/
+	cp openshift/* /etc/mcc/bootstrap/
+	cp auth/kubeconfig-kubelet /etc/mcs/kubeconfig
+	cp mco-bootstrap/bootstrap/machineconfigoperator-bootstrap-pod.yaml /etc/kubernetes/manifests/
+	cp mco-bootstrap/manifests/* manifests/
+
+	# /etc/ssl/mcs/tls.{crt, key} are locations for MachineConfigServer's tls assets.
+	mkdir --parents /etc/ssl/mcs/
+	cp tls/machine-config-server.crt /etc/ssl/mcs/tls.crt
+	cp tls/machine-config-server.key /etc/ssl/mcs/tls.key
+
+	touch mco-bootstrap.done
+fi
+
+# We originally wanted to run the etcd cert signer as
+# a static pod, but kubelet could't remove static pod
+# when API server is not up, so we have to run this as
+# podman container.
+# See https://github.com/kubernetes/kubernetes/issues/43292
+
+echo "Starting etcd certificate signer..."
+
+trap "podman rm --force etcd-signer" ERR
+
+# shellcheck disable=SC2154
+podman run \
+	--quiet \
+	--name etcd-signer \
+	--detach \
+	--volume /opt/openshift/tls:/opt/openshift/tls:ro,z \
+	--network host \
+	"quay.io/coreos/kube-etcd-signer-server:678cc8e6841e2121ebfdb6e2db568fce290b67d6" \
+	serve \
+	--cacrt=/opt/openshift/tls/etcd-client-ca.crt \
+	--cakey=/opt/openshift/tls/etcd-client-ca.key \
+	--servcrt=/opt/openshift/tls/apiserver.crt \
+	--servkey=/opt/openshift/tls/apiserver.key \
+	--address=0.0.0.0:6443 \
+	--csrdir=/tmp \
+	--peercertdur=26280h \
+	--servercertdur=26280h
+
+echo "Waiting for etcd cluster..."
+
+# Wait for the etcd cluster to come up.
+# shellcheck disable=SC2154,SC2086
+until podman run \
+		--quiet \
+		--rm \
+		--network host \
+		--name etcdctl \
+		--env ETCDCTL_API=3 \
+		--volume /opt/openshift/tls:/opt/openshift/tls:ro,z \
+		--entrypoint etcdctl \
+		"${MACHINE_CONFIG_ETCD_IMAGE}" \
+		--dial-timeout=10m \
+		--cacert=/opt/openshift/tls/etcd-client-ca.crt \
+		--cert=/opt/openshift/tls/etcd-client.crt \
+		--key=/opt/openshift/tls/etcd-client.key \
+		--endpoints=https://etcd-0.single.example.com:2379 \
+		endpoint health
+do
+	echo "etcdctl failed. Retrying in 5 seconds..."
+	sleep 5
+done
+
+echo "etcd cluster up. Killing etcd certificate signer..."
+
+podman rm --force etcd-signer
+rm --force /etc/kubernetes/manifests/machineconfigoperator-bootstrap-pod.yaml
+
+echo "Starting cluster-bootstrap..."
+
+# shellcheck disable=SC2154
+podman run \
+	--quiet \
+	--rm \
+	--volume "$PWD:/assets:z" \
+	--volume /etc/kubernetes:/etc/kubernetes:z \
+	--network=host \
+	"${CLUSTER_BOOTSTRAP_IMAGE}" \
+	start --asset-dir=/assets --required-pods="kube-apiserver:openshift-kube-apiserver/openshift-kube-apiserver|openshift-kube-apiserver/kube-apiserver,openshift-kube-scheduler/openshift-kube-scheduler,kube-controller-manager:openshift-kube-controller-manager/openshift-kube-controller-manager|openshift-kube-controller-manager/kube-controller-manager,openshift-cluster-version/cluster-version-operator"
+
+# Workaround for https://github.com/opencontainers/runc/pull/1807
+touch /opt/openshift/.bootkube.done
+echo "bootkube.service complete"
@@ -0,0 +1,38 @@
+#!/usr/bin/env bash
+# Pull the release images bootkube.sh needs in parallel, so slow links are
+# not paid for once per image.  Failures are not fatal: bootkube.sh pulls
+# anything still missing.
+
+if ! podman inspect registry.svc.ci.openshift.org/openshift/origin-release:v4.0 &>/dev/null; then
+	echo "Pulling release image..."
+	podman pull --quiet registry.svc.ci.openshift.org/openshift/origin-release:v4.0 >/dev/null || exit 0
+fi
+
+if ! release=$( podman inspect registry.svc.ci.openshift.org/openshift/origin-release:v4.0 -f '{{ index .RepoDigests 0 }}' ) || [[ -z "${release}" ]]; then
+	release="registry.svc.ci.openshift.org/openshift/origin-release:v4.0"
+fi
+
+prepull() {
+	local name="$1" image attempt
+
+	if ! image=$(podman run --quiet --rm "${release}" image "${name}"); then
+		echo "Could not resolve the ${name} image" >&2
+		return 1
+	fi
+	for attempt in 1 2 3; do
+		if podman pull --quiet "${image}" >/dev/null; then
+			echo "Pulled ${name} (${image})"
+			return 0
+		fi
+		sleep $((attempt * 5))
+	done
+	echo "Failed to pull ${name} (${image})" >&2
+	return 1
+}
+
+echo "Pre-pulling release images..."
+for name in cluster-bootstrap cluster-config-operator cluster-kube-apiserver-operator cluster-kube-controller-manager-operator cluster-kube-scheduler-operator etcd hyperkube hypershift machine-config-controller machine-config-operator machine-config-server pod setup-etcd-environment
+do
+	prepull "${name}" &
+done
+wait
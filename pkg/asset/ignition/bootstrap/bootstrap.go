@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,6 +26,7 @@ import (
 	"github.com/metalkube/kni-installer/pkg/asset/machines"
 	"github.com/metalkube/kni-installer/pkg/asset/manifests"
 	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/release"
 	"github.com/metalkube/kni-installer/pkg/types"
 )
 
@@ -36,7 +38,24 @@ const (
 )
 
 var (
-	defaultReleaseImage = "registry.svc.ci.openshift.org/openshift/origin-release:v4.0"
+	// prePullImages are the release payload components which bootkube.sh
+	// needs before the control plane is up.  prepull.sh pulls them in
+	// parallel, instead of bootkube.sh pulling them one at a time.
+	prePullImages = []string{
+		"cluster-bootstrap",
+		"cluster-config-operator",
+		"cluster-kube-apiserver-operator",
+		"cluster-kube-controller-manager-operator",
+		"cluster-kube-scheduler-operator",
+		"etcd",
+		"hyperkube",
+		"hypershift",
+		"machine-config-controller",
+		"machine-config-operator",
+		"machine-config-server",
+		"pod",
+		"setup-etcd-environment",
+	}
 )
 
 // bootstrapTemplateData is the data to use to replace values in bootstrap
@@ -44,14 +63,31 @@ var (
 type bootstrapTemplateData struct {
 	EtcdCertSignerImage string
 	EtcdCluster         string
+	PrePullImages       []string
 	PullSecret          string
 	ReleaseImage        string
+
+	// SchedulableMasters is true if the cluster has no compute nodes, e.g.
+	// with the compact profile, so openshift.sh makes the
+	// ControlPlaneReplicas control plane nodes schedulable.
+	SchedulableMasters   bool
+	ControlPlaneReplicas int64
+
+	// APIVIP is the bare metal API VIP, which keepalived.sh holds on the
+	// bootstrap machine, and APIVIPSubnet is the control plane's machine
+	// network it is on, which may be one of several routed subnets.
+	APIVIP       string
+	APIVIPSubnet string
 }
 
 // Bootstrap is an asset that generates the ignition config for bootstrap nodes.
 type Bootstrap struct {
 	Config *igntypes.Config
 	File   *asset.File
+
+	// Contents are the file contents the config references instead of
+	// embedding them, when the install config asks for them to be served.
+	Contents []*asset.File
 }
 
 var _ asset.WritableAsset = (*Bootstrap)(nil)
@@ -60,7 +96,7 @@ var _ asset.WritableAsset = (*Bootstrap)(nil)
 func (a *Bootstrap) Dependencies() []asset.Asset {
 	return []asset.Asset{
 		&installconfig.InstallConfig{},
-		&kubeconfig.AdminClient{},
+		&kubeconfig.AdminBootstrapClient{},
 		&kubeconfig.Kubelet{},
 		&kubeconfig.KubeletClient{},
 		&machines.Master{},
@@ -114,11 +150,11 @@ func (a *Bootstrap) Dependencies() []asset.Asset {
 }
 
 // Generate generates the ignition config for the Bootstrap asset.
-func (a *Bootstrap) Generate(dependencies asset.Parents) error {
+func (a *Bootstrap) Generate(ctx context.Context, dependencies asset.Parents) error {
 	installConfig := &installconfig.InstallConfig{}
 	dependencies.Get(installConfig)
 
-	templateData, err := a.getTemplateData(installConfig.Config)
+	templateData, err := a.getTemplateData(ctx, installConfig.Config)
 	if err != nil {
 		return errors.Wrap(err, "failed to get bootstrap templates")
 	}
@@ -139,11 +175,23 @@ func (a *Bootstrap) Generate(dependencies asset.Parents) error {
 	}
 	a.addParentFiles(dependencies)
 
+	if bm := installConfig.Config.Platform.BareMetal; bm != nil && bm.RegistryMirror != nil {
+		a.Config.Storage.Files = append(a.Config.Storage.Files, ignition.FileFromString(ignition.RegistriesConfPath, "root", 0644, ignition.RegistriesConf(bm.RegistryMirror)))
+	}
+
 	a.Config.Passwd.Users = append(
 		a.Config.Passwd.Users,
 		igntypes.PasswdUser{Name: "core", SSHAuthorizedKeys: []igntypes.SSHAuthorizedKey{igntypes.SSHAuthorizedKey(installConfig.Config.SSHKey)}},
 	)
 
+	a.Contents = nil
+	if bm := installConfig.Config.Platform.BareMetal; bm != nil && bm.BootstrapContent != nil {
+		a.Contents, err = serveContents(a.Config, bm.BootstrapContent)
+		if err != nil {
+			return errors.Wrap(err, "failed to move the bootstrap file contents out of the Ignition config")
+		}
+	}
+
 	data, err := json.Marshal(a.Config)
 	if err != nil {
 		return errors.Wrap(err, "failed to Marshal Ignition config")
@@ -164,29 +212,63 @@ func (a *Bootstrap) Name() string {
 // Files returns the files generated by the asset.
 func (a *Bootstrap) Files() []*asset.File {
 	if a.File != nil {
-		return []*asset.File{a.File}
+		return append([]*asset.File{a.File}, a.Contents...)
 	}
 	return []*asset.File{}
 }
 
-// getTemplateData returns the data to use to execute bootstrap templates.
-func (a *Bootstrap) getTemplateData(installConfig *types.InstallConfig) (*bootstrapTemplateData, error) {
+// ReleaseImage returns the release image the cluster is installed from.
+func ReleaseImage() string {
+	return release.Image()
+}
+
+// EtcdCluster returns the comma-separated client URLs of the etcd
+// members, which the bootstrap machine's API server connects to.
+func EtcdCluster(installConfig *types.InstallConfig) string {
 	etcdEndpoints := make([]string, *installConfig.ControlPlane.Replicas)
 	for i := range etcdEndpoints {
 		etcdEndpoints[i] = fmt.Sprintf("https://etcd-%d.%s:2379", i, installConfig.ClusterDomain())
 	}
+	return strings.Join(etcdEndpoints, ",")
+}
 
-	releaseImage := defaultReleaseImage
-	if ri, ok := os.LookupEnv("OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE"); ok && ri != "" {
-		logrus.Warn("Found override for ReleaseImage. Please be warned, this is not advised")
-		releaseImage = ri
+// getTemplateData returns the data to use to execute bootstrap templates.
+func (a *Bootstrap) getTemplateData(ctx context.Context, installConfig *types.InstallConfig) (*bootstrapTemplateData, error) {
+	pullSecret, err := installConfig.MergedPullSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge pull secrets")
+	}
+
+	// An autoscaled pool brings up compute nodes for pending workloads.
+	hasCompute := false
+	for _, pool := range installConfig.Compute {
+		if (pool.Replicas != nil && *pool.Replicas > 0) || pool.Autoscaling != nil {
+			hasCompute = true
+		}
+	}
+	controlPlaneReplicas := int64(0)
+	if installConfig.ControlPlane != nil && installConfig.ControlPlane.Replicas != nil {
+		controlPlaneReplicas = *installConfig.ControlPlane.Replicas
+	}
+
+	var apiVIP, apiVIPSubnet string
+	if bm := installConfig.Platform.BareMetal; bm != nil {
+		apiVIP = bm.APIVIP
+		if cidr := installConfig.ControlPlane.MachineNetwork(installConfig.Networking); cidr != nil {
+			apiVIPSubnet = cidr.String()
+		}
 	}
 
 	return &bootstrapTemplateData{
-		EtcdCertSignerImage: etcdCertSignerImage,
-		PullSecret:          installConfig.PullSecret,
-		ReleaseImage:        releaseImage,
-		EtcdCluster:         strings.Join(etcdEndpoints, ","),
+		EtcdCertSignerImage:  etcdCertSignerImage,
+		PrePullImages:        prePullImages,
+		PullSecret:           pullSecret,
+		ReleaseImage:         release.ImageFromEnv(asset.SourcesFromContext(ctx).LookupEnv),
+		EtcdCluster:          EtcdCluster(installConfig),
+		SchedulableMasters:   !hasCompute,
+		ControlPlaneReplicas: controlPlaneReplicas,
+		APIVIP:               apiVIP,
+		APIVIPSubnet:         apiVIPSubnet,
 	}, nil
 }
 
@@ -250,6 +332,7 @@ func (a *Bootstrap) addSystemdUnits(uri string, templateData *bootstrapTemplateD
 	enabled := map[string]struct{}{
 		"progress.service":                {},
 		"kubelet.service":                 {},
+		"prepull.service":                 {},
 		"keepalived.service":              {},
 		"systemd-journal-gatewayd.socket": {},
 	}
@@ -356,7 +439,10 @@ func readFile(name string, reader io.Reader, templateData interface{}) (finalNam
 		if err != nil {
 			return name, data, err
 		}
-		stringData := applyTemplateData(tmpl, templateData)
+		stringData, err := applyTemplateData(tmpl, templateData)
+		if err != nil {
+			return name, data, errors.Wrapf(err, "failed to render %s", name)
+		}
 		data = []byte(stringData)
 	}
 
@@ -374,7 +460,7 @@ func (a *Bootstrap) addParentFiles(dependencies asset.Parents) {
 	}
 
 	for _, asset := range []asset.WritableAsset{
-		&kubeconfig.AdminClient{},
+		&kubeconfig.AdminBootstrapClient{},
 		&kubeconfig.Kubelet{},
 		&kubeconfig.KubeletClient{},
 		&kubeconfig.KubeletClient{},
@@ -434,12 +520,12 @@ func (a *Bootstrap) addParentFiles(dependencies asset.Parents) {
 	a.Config.Storage.Files = append(a.Config.Storage.Files, ignition.FilesFromAsset(rootDir, "systemd-journal-gateway", 0600, journal)...)
 }
 
-func applyTemplateData(template *template.Template, templateData interface{}) string {
+func applyTemplateData(template *template.Template, templateData interface{}) (string, error) {
 	buf := &bytes.Buffer{}
 	if err := template.Execute(buf, templateData); err != nil {
-		panic(err)
+		return "", err
 	}
-	return buf.String()
+	return buf.String(), nil
 }
 
 // Load returns the bootstrap ignition from disk.
@@ -457,6 +543,14 @@ func (a *Bootstrap) Load(f asset.FileFetcher) (found bool, err error) {
 		return false, errors.Wrap(err, "failed to unmarshal")
 	}
 
+	contents, err := f.FetchByPattern(filepath.Join(bootstrapContentDir, "*"))
+	if err != nil {
+		return false, err
+	}
+
 	a.File, a.Config = file, config
+	if len(contents) > 0 {
+		a.Contents = contents
+	}
 	return true, nil
 }
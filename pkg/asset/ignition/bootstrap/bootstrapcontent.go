@@ -0,0 +1,102 @@
+package bootstrap
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/pkg/errors"
+	"github.com/vincent-petithory/dataurl"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// bootstrapContentDir is the directory of the asset directory holding the
+// contents served in place of the bootstrap Ignition config's files.
+const bootstrapContentDir = "bootstrap-content"
+
+// contentName matches the names of served contents, their hex-encoded
+// SHA-512.
+var contentName = regexp.MustCompile(`^[0-9a-f]{128}$`)
+
+// servable returns true if the file at path holds nothing secret, so its
+// contents may be served to anyone who can reach the URL: the bootstrap
+// scripts, and the certificates and CA bundles (but not the keys) in the
+// tls directory.  Manifests are never served, as several of them are
+// Secrets.
+func servable(file *igntypes.File) bool {
+	if file.Mode == nil || *file.Mode&0004 == 0 {
+		return false
+	}
+	switch {
+	case path.Dir(file.Path) == "/usr/local/bin":
+		return true
+	case path.Dir(file.Path) == path.Join(rootDir, "tls") && path.Ext(file.Path) == ".crt":
+		return true
+	default:
+		return false
+	}
+}
+
+// serveContents replaces the embedded contents of the config's storage
+// files of at least content.MinSize bytes which are servable with
+// references below content.URL, verified by their SHA-512, and returns the
+// files to serve there.
+func serveContents(config *igntypes.Config, content *baremetal.BootstrapContent) ([]*asset.File, error) {
+	baseURL := strings.TrimSuffix(content.URL, "/")
+
+	var files []*asset.File
+	served := map[string]bool{}
+	for i := range config.Storage.Files {
+		file := &config.Storage.Files[i]
+		if !servable(file) {
+			continue
+		}
+		contents, err := dataurl.DecodeString(file.Contents.Source)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the contents of %s", file.Path)
+		}
+		if len(contents.Data) < content.MinSize {
+			continue
+		}
+
+		sum := sha512.Sum512(contents.Data)
+		name := hex.EncodeToString(sum[:])
+		hash := "sha512-" + name
+		file.Contents.Source = baseURL + "/" + name
+		file.Contents.Verification.Hash = &hash
+		if !served[name] {
+			served[name] = true
+			files = append(files, &asset.File{
+				Filename: filepath.Join(bootstrapContentDir, name),
+				Data:     contents.Data,
+			})
+		}
+	}
+	return files, nil
+}
+
+// ContentHandler serves the contents serveContents wrote to the asset
+// directory dir, by the last element of the request path, for the
+// bootstrap node to fetch.  Only names of contents are served, so the rest
+// of the asset directory is never exposed.
+func ContentHandler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := path.Base(r.URL.Path)
+		if !contentName.MatchString(name) {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(dir, bootstrapContentDir, name))
+	})
+}
@@ -0,0 +1,100 @@
+package bootstrap
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestServeContents(t *testing.T) {
+	large := strings.Repeat("x", 16)
+	sum := sha512.Sum512([]byte(large))
+	name := hex.EncodeToString(sum[:])
+	hash := "sha512-" + name
+
+	config := &igntypes.Config{}
+	config.Storage.Files = []igntypes.File{
+		ignition.FileFromString("/usr/local/bin/bootkube.sh", "root", 0555, large),
+		ignition.FileFromString("/opt/openshift/manifests/small.yaml", "root", 0644, "small"),
+		ignition.FileFromString("/opt/openshift/tls/private.key", "root", 0600, large),
+		ignition.FileFromString("/opt/openshift/tls/root-ca.crt", "root", 0644, large),
+		ignition.FileFromString("/opt/openshift/manifests/large.yaml", "root", 0644, large+"y"),
+		ignition.FileFromString("/opt/openshift/openshift/99_kubeadmin-password-secret.yaml", "root", 0644, large+"z"),
+	}
+
+	files, err := serveContents(config, &baremetal.BootstrapContent{URL: "http://192.168.111.1:8080/content/", MinSize: 16})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, filepath.Join(bootstrapContentDir, name), files[0].Filename)
+		assert.Equal(t, large, string(files[0].Data))
+	}
+
+	for _, i := range []int{0, 3} {
+		contents := config.Storage.Files[i].Contents
+		assert.Equal(t, "http://192.168.111.1:8080/content/"+name, contents.Source, config.Storage.Files[i].Path)
+		if assert.NotNil(t, contents.Verification.Hash) {
+			assert.Equal(t, hash, *contents.Verification.Hash)
+		}
+	}
+	for _, i := range []int{1, 2, 4, 5} {
+		contents := config.Storage.Files[i].Contents
+		assert.True(t, strings.HasPrefix(contents.Source, "data:"), config.Storage.Files[i].Path)
+		assert.Nil(t, contents.Verification.Hash)
+	}
+}
+
+func TestContentHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap-content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sum := sha512.Sum512([]byte("contents"))
+	name := hex.EncodeToString(sum[:])
+	if err := os.MkdirAll(filepath.Join(dir, bootstrapContentDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, bootstrapContentDir, name), []byte("contents"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "bootstrap.ign"), []byte("secret"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := ContentHandler(dir)
+	cases := []struct {
+		path     string
+		expected int
+	}{
+		{path: "/bootstrap-content/" + name, expected: http.StatusOK},
+		{path: "/bootstrap-content/" + strings.Repeat("0", 128), expected: http.StatusNotFound},
+		{path: "/bootstrap-content/../bootstrap.ign", expected: http.StatusNotFound},
+		{path: "/bootstrap.ign", expected: http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.expected, w.Code)
+			if tc.expected == http.StatusOK {
+				assert.Equal(t, "contents", w.Body.String())
+			}
+		})
+	}
+}
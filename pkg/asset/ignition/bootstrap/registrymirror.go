@@ -0,0 +1,189 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/coreos/ignition/config/util"
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/data"
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/release"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+const (
+	registryMirrorIgnFilename = "registry-mirror.ign"
+	registryMirrorAssets      = "baremetal/registry-mirror"
+)
+
+// registryMirrorTemplateData is the data to use to replace values in
+// registry mirror template files.
+type registryMirrorTemplateData struct {
+	Image        string
+	Port         int
+	ReleaseImage string
+	Repository   string
+}
+
+// RegistryMirror is an asset that generates the ignition config for the
+// registry mirror VM.  It has no files unless the install config asks for
+// a registry mirror.
+type RegistryMirror struct {
+	Config *igntypes.Config
+	File   *asset.File
+}
+
+var _ asset.WritableAsset = (*RegistryMirror)(nil)
+
+// Dependencies returns the assets on which the RegistryMirror asset depends.
+func (a *RegistryMirror) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+	}
+}
+
+// Generate generates the ignition config for the RegistryMirror asset.
+func (a *RegistryMirror) Generate(ctx context.Context, dependencies asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	dependencies.Get(installConfig)
+
+	a.Config, a.File = nil, nil
+	bm := installConfig.Config.Platform.BareMetal
+	if bm == nil || bm.RegistryMirror == nil {
+		return nil
+	}
+	mirror := bm.RegistryMirror
+
+	pullSecret, err := installConfig.Config.MergedPullSecret()
+	if err != nil {
+		return errors.Wrap(err, "failed to merge pull secrets")
+	}
+
+	templateData := &registryMirrorTemplateData{
+		Image:        mirror.Image,
+		Port:         mirror.Port,
+		ReleaseImage: release.ImageFromEnv(asset.SourcesFromContext(ctx).LookupEnv),
+		Repository:   baremetal.RegistryMirrorRepository,
+	}
+
+	a.Config = &igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+	}
+
+	ifcfg, err := ifcfgFile(&mirror.Network)
+	if err != nil {
+		return err
+	}
+	a.Config.Storage.Files = append(a.Config.Storage.Files,
+		ignition.FileFromString(path.Join("/etc/sysconfig/network-scripts", "ifcfg-"+mirror.Network.Interface), "root", 0644, ifcfg),
+		ignition.FileFromString("/etc/registry-mirror/pull-secret.json", "root", 0600, pullSecret),
+	)
+
+	uri := path.Join(registryMirrorAssets, "files/usr/local/bin/mirror-release.sh.template")
+	_, script, err := readAsset(uri, templateData)
+	if err != nil {
+		return err
+	}
+	a.Config.Storage.Files = append(a.Config.Storage.Files, ignition.FileFromBytes("/usr/local/bin/mirror-release.sh", "root", 0555, script))
+
+	for _, unit := range []string{"registry.service.template", "mirror-release.service"} {
+		name, contents, err := readAsset(path.Join(registryMirrorAssets, "systemd/units", unit), templateData)
+		if err != nil {
+			return err
+		}
+		a.Config.Systemd.Units = append(a.Config.Systemd.Units, igntypes.Unit{
+			Name:     name,
+			Contents: string(contents),
+			Enabled:  util.BoolToPtr(true),
+		})
+	}
+
+	a.Config.Passwd.Users = append(
+		a.Config.Passwd.Users,
+		igntypes.PasswdUser{Name: ignitionUser, SSHAuthorizedKeys: []igntypes.SSHAuthorizedKey{igntypes.SSHAuthorizedKey(installConfig.Config.SSHKey)}},
+	)
+
+	data, err := json.Marshal(a.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to Marshal Ignition config")
+	}
+	a.File = &asset.File{
+		Filename: registryMirrorIgnFilename,
+		Data:     data,
+	}
+
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *RegistryMirror) Name() string {
+	return "Registry Mirror Ignition Config"
+}
+
+// Files returns the files generated by the asset.
+func (a *RegistryMirror) Files() []*asset.File {
+	if a.File != nil {
+		return []*asset.File{a.File}
+	}
+	return []*asset.File{}
+}
+
+// Load returns the registry mirror ignition from disk.
+func (a *RegistryMirror) Load(f asset.FileFetcher) (found bool, err error) {
+	file, err := f.FetchByName(registryMirrorIgnFilename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	config := &igntypes.Config{}
+	if err := json.Unmarshal(file.Data, config); err != nil {
+		return false, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	a.File, a.Config = file, config
+	return true, nil
+}
+
+// readAsset reads and, if it is a template, renders the data asset at uri.
+func readAsset(uri string, templateData interface{}) (string, []byte, error) {
+	file, err := data.Assets.Open(uri)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+	return readFile(path.Base(uri), file, templateData)
+}
+
+// ifcfgFile returns the initscripts configuration of a static network.
+func ifcfgFile(n *baremetal.HostNetwork) (string, error) {
+	ip, ipNet, err := net.ParseCIDR(n.Address)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid address %q", n.Address)
+	}
+	prefix, _ := ipNet.Mask.Size()
+
+	var contents strings.Builder
+	fmt.Fprintf(&contents, "DEVICE=%s\nBOOTPROTO=none\nONBOOT=yes\nIPADDR=%s\nPREFIX=%d\n", n.Interface, ip, prefix)
+	if n.Gateway != "" {
+		fmt.Fprintf(&contents, "GATEWAY=%s\n", n.Gateway)
+	}
+	for i, dns := range n.DNS {
+		fmt.Fprintf(&contents, "DNS%d=%s\n", i+1, dns)
+	}
+	return contents.String(), nil
+}
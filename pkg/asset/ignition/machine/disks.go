@@ -0,0 +1,88 @@
+package machine
+
+import (
+	"fmt"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	// defaultInstallDisk is the install disk of a disk layout which does
+	// not name one.
+	defaultInstallDisk = "/dev/sda"
+
+	// sectorsPerMiB converts partition sizes to the 512 byte sectors
+	// Ignition uses.
+	sectorsPerMiB = 2048
+)
+
+// addDiskLayout appends the partitions of a disk layout to the install disk
+// of a config, formats them, and mounts them with systemd mount units.
+// Ignition writes files to the root filesystem before the units mount the
+// new filesystems, so validation refuses mount paths, such as /var, which
+// would hide them.
+func addDiskLayout(config *igntypes.Config, layout *types.DiskLayout) {
+	if layout == nil {
+		return
+	}
+	device := layout.Device
+	if device == "" {
+		device = defaultInstallDisk
+	}
+	disk := igntypes.Disk{Device: device}
+	for _, p := range layout.Partitions {
+		disk.Partitions = append(disk.Partitions, igntypes.Partition{
+			Label: p.Label,
+			Size:  p.SizeMiB * sectorsPerMiB,
+		})
+
+		format := p.Format
+		if format == "" {
+			format = "xfs"
+		}
+		partition := fmt.Sprintf("/dev/disk/by-partlabel/%s", p.Label)
+		config.Storage.Filesystems = append(config.Storage.Filesystems, igntypes.Filesystem{
+			Name: p.Label,
+			Mount: &igntypes.Mount{
+				Device:         partition,
+				Format:         format,
+				WipeFilesystem: true,
+			},
+		})
+
+		enabled := true
+		contents := fmt.Sprintf("[Unit]\nBefore=local-fs.target\n\n[Mount]\nWhat=%s\nWhere=%s\nType=%s\n\n[Install]\nWantedBy=local-fs.target\n", partition, p.MountPath, format)
+		config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+			Name:     mountUnitName(p.MountPath),
+			Enabled:  &enabled,
+			Contents: contents,
+		})
+	}
+	config.Storage.Disks = append(config.Storage.Disks, disk)
+}
+
+// mountUnitName returns the name systemd requires for the mount unit of a
+// path, as "systemd-escape --path --suffix=mount" would.
+func mountUnitName(mountPath string) string {
+	var parts []string
+	for _, part := range strings.Split(strings.Trim(mountPath, "/"), "/") {
+		if part != "" {
+			parts = append(parts, strings.Replace(part, "-", `\x2d`, -1))
+		}
+	}
+	return strings.Join(parts, "-") + ".mount"
+}
+
+// computeDiskLayout returns the disk layout shared by the compute pools, if
+// they have one.
+func computeDiskLayout(c *types.InstallConfig) *types.DiskLayout {
+	for _, p := range c.Compute {
+		if p.DiskLayout != nil {
+			return p.DiskLayout
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package machine
+
+import (
+	"testing"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestAddDiskLayout(t *testing.T) {
+	config := &igntypes.Config{}
+	addDiskLayout(config, &types.DiskLayout{
+		Partitions: []types.DiskPartition{
+			{Label: "log", MountPath: "/var/log", SizeMiB: 10240},
+			{Label: "containers", MountPath: "/var/lib/containers", Format: "ext4"},
+		},
+	})
+
+	assert.Equal(t, []igntypes.Disk{{
+		Device: "/dev/sda",
+		Partitions: []igntypes.Partition{
+			{Label: "log", Size: 20971520},
+			{Label: "containers"},
+		},
+	}}, config.Storage.Disks)
+	assert.Equal(t, "/dev/disk/by-partlabel/containers", config.Storage.Filesystems[1].Mount.Device)
+	assert.Equal(t, "ext4", config.Storage.Filesystems[1].Mount.Format)
+	assert.Equal(t, "xfs", config.Storage.Filesystems[0].Mount.Format)
+	assert.Equal(t, "var-log.mount", config.Systemd.Units[0].Name)
+	assert.Equal(t, "var-lib-containers.mount", config.Systemd.Units[1].Name)
+}
+
+func TestMountUnitName(t *testing.T) {
+	cases := []struct {
+		mountPath string
+		expected  string
+	}{
+		{mountPath: "/var", expected: "var.mount"},
+		{mountPath: "/var/lib/containers/", expected: "var-lib-containers.mount"},
+		{mountPath: "/var/lib/etcd-data", expected: `var-lib-etcd\x2ddata.mount`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.mountPath, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mountUnitName(tc.mountPath))
+		})
+	}
+}
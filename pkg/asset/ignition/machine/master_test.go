@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,14 +40,16 @@ func TestMasterGenerate(t *testing.T) {
 	}
 
 	rootCA := &tls.RootCA{}
-	err := rootCA.Generate(nil)
+	rootCAParents := asset.Parents{}
+	rootCAParents.Add(&installconfig.Ephemeral{}, &tls.UserRootCA{})
+	err := rootCA.Generate(context.Background(), rootCAParents)
 	assert.NoError(t, err, "unexpected error generating root CA")
 
 	parents := asset.Parents{}
 	parents.Add(installConfig, rootCA)
 
 	master := &Master{}
-	err = master.Generate(parents)
+	err = master.Generate(context.Background(), parents)
 	assert.NoError(t, err, "unexpected error generating master asset")
 	expectedIgnitionConfigNames := []string{
 		"master.ign",
@@ -0,0 +1,115 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	poolWorkerIgnFilename = "worker-%s.ign"
+)
+
+// PoolWorkers is an asset that generates the ignition configs of the
+// compute pools which have a machine config pool of their own.  Their
+// machines fetch the pool's rendered config from the machine config
+// server, rather than the worker one, so they boot with the pool's
+// MachineConfigs in place.
+type PoolWorkers struct {
+	FileList []*asset.File
+}
+
+var _ asset.WritableAsset = (*PoolWorkers)(nil)
+
+// Dependencies returns the assets on which the PoolWorkers asset depends.
+func (a *PoolWorkers) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+		&tls.RootCA{},
+	}
+}
+
+// Generate generates an ignition config for each compute pool with a
+// machine config pool of the same name.
+func (a *PoolWorkers) Generate(ctx context.Context, dependencies asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	rootCA := &tls.RootCA{}
+	dependencies.Get(installConfig, rootCA)
+
+	a.FileList = []*asset.File{}
+	for _, pool := range OwnConfigPools(installConfig.Config) {
+		config := pointerIgnitionConfig(installConfig.Config, rootCA.Cert(), pool)
+		addDiskLayout(config, computeDiskLayout(installConfig.Config))
+
+		data, err := json.Marshal(config)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the Ignition config of pool %s", pool)
+		}
+		a.FileList = append(a.FileList, &asset.File{
+			Filename: fmt.Sprintf(poolWorkerIgnFilename, pool),
+			Data:     data,
+		})
+	}
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *PoolWorkers) Name() string {
+	return "Compute Pool Ignition Configs"
+}
+
+// Files returns the files generated by the asset.
+func (a *PoolWorkers) Files() []*asset.File {
+	return a.FileList
+}
+
+// File returns the ignition config of the compute pool, or nil if the
+// pool boots from the worker one.
+func (a *PoolWorkers) File(pool string) *asset.File {
+	filename := fmt.Sprintf(poolWorkerIgnFilename, pool)
+	for _, file := range a.FileList {
+		if file.Filename == filename {
+			return file
+		}
+	}
+	return nil
+}
+
+// Load returns the compute pool ignitions from disk.
+func (a *PoolWorkers) Load(f asset.FileFetcher) (found bool, err error) {
+	files, err := f.FetchByPattern(fmt.Sprintf(poolWorkerIgnFilename, "*"))
+	if err != nil {
+		return false, err
+	}
+	if len(files) == 0 {
+		return false, nil
+	}
+
+	a.FileList = files
+	return true, nil
+}
+
+// OwnConfigPools returns the names of the compute pools which boot from
+// an ignition config of their own: those running RHCOS, other than the
+// worker pool, with a machine config pool of the same name, for whose
+// rendered config the machine config server has an endpoint.
+func OwnConfigPools(installConfig *types.InstallConfig) []string {
+	machineConfigPools := map[string]bool{}
+	for _, p := range installConfig.MachineConfigPools {
+		machineConfigPools[p.Name] = true
+	}
+	var pools []string
+	for _, p := range installConfig.Compute {
+		if p.Name != "worker" && p.OperatingSystem != types.OperatingSystemRHEL && machineConfigPools[p.Name] {
+			pools = append(pools, p.Name)
+		}
+	}
+	return pools
+}
@@ -0,0 +1,68 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/aws"
+)
+
+func TestPoolWorkersGenerate(t *testing.T) {
+	installConfig := &installconfig.InstallConfig{
+		Config: &types.InstallConfig{
+			BaseDomain: "example.com",
+			Compute: []types.MachinePool{
+				{Name: "worker"},
+				{Name: "infra"},
+				{Name: "gpu"},
+				{Name: "legacy", OperatingSystem: types.OperatingSystemRHEL},
+			},
+			MachineConfigPools: []types.MachineConfigPool{
+				{Name: "infra"},
+				{Name: "legacy"},
+			},
+			Platform: types.Platform{
+				AWS: &aws.Platform{
+					Region: "us-east",
+				},
+			},
+		},
+	}
+	installConfig.Config.ObjectMeta.Name = "test-cluster"
+
+	rootCA := &tls.RootCA{}
+	rootCAParents := asset.Parents{}
+	rootCAParents.Add(&installconfig.Ephemeral{}, &tls.UserRootCA{})
+	if err := rootCA.Generate(context.Background(), rootCAParents); err != nil {
+		t.Fatal(err)
+	}
+
+	parents := asset.Parents{}
+	parents.Add(installConfig, rootCA)
+
+	poolWorkers := &PoolWorkers{}
+	if !assert.NoError(t, poolWorkers.Generate(context.Background(), parents)) {
+		return
+	}
+	files := poolWorkers.Files()
+	if !assert.Len(t, files, 1) {
+		return
+	}
+	assert.Equal(t, "worker-infra.ign", files[0].Filename)
+	assert.Equal(t, files[0], poolWorkers.File("infra"))
+	assert.Nil(t, poolWorkers.File("gpu"))
+	assert.Nil(t, poolWorkers.File("worker"))
+
+	config := &igntypes.Config{}
+	if assert.NoError(t, json.Unmarshal(files[0].Data, config)) {
+		assert.Equal(t, "https://api.test-cluster.example.com:22623/config/infra", config.Ignition.Config.Append[0].Source)
+	}
+}
@@ -0,0 +1,199 @@
+package machine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/data"
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/release"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	rhelWorkerUserDataFilename = "rhel-worker-user-data.yaml"
+
+	// rhelRootCAPath is where the root CA is added to the RHEL host's
+	// trust store.
+	rhelRootCAPath = "/etc/pki/ca-trust/source/anchors/kni-root-ca.crt"
+
+	// rhelBootstrapScriptPath is the script which joins the RHEL host to
+	// the cluster.
+	rhelBootstrapScriptPath = "/usr/local/bin/kni-node-bootstrap.sh"
+
+	// rhelPullSecretPath is where the bootstrap script finds the pull
+	// secret, which it removes once the config is applied.
+	rhelPullSecretPath = "/var/lib/kni-node-bootstrap/pull-secret.json"
+)
+
+// rhelNodePackages are the packages the openshift-ansible scaleup playbook
+// installs on RHEL nodes: the container runtime, the kubelet (from
+// openshift-hyperkube) and the tools to pull and run the
+// machine-config-daemon.
+var rhelNodePackages = []string{
+	"conmon",
+	"cri-o",
+	"cri-tools",
+	"openshift-clients",
+	"openshift-hyperkube",
+	"podman",
+	"runc",
+	"skopeo",
+}
+
+// RHELWorker is an asset that generates the cloud-init user data for the
+// hosts of RHEL compute pools, which cannot run Ignition.  It is the
+// equivalent of the worker Ignition config: it trusts the root CA, installs
+// the node packages and joins the host to the cluster by applying the
+// config from the machine config server with the machine-config-daemon.
+type RHELWorker struct {
+	File *asset.File
+}
+
+var _ asset.WritableAsset = (*RHELWorker)(nil)
+
+// cloudConfig is the subset of cloud-init's cloud-config the user data
+// uses.
+type cloudConfig struct {
+	WriteFiles []cloudConfigFile `json:"write_files"`
+	RunCmd     []string          `json:"runcmd"`
+}
+
+type cloudConfigFile struct {
+	Path        string `json:"path"`
+	Owner       string `json:"owner"`
+	Permissions string `json:"permissions"`
+	Content     string `json:"content"`
+}
+
+// Dependencies returns the assets on which the RHELWorker asset depends.
+func (a *RHELWorker) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+		&tls.RootCA{},
+	}
+}
+
+// Generate generates the cloud-init user data for the RHELWorker asset.  It
+// generates nothing if no compute pool runs RHEL.
+func (a *RHELWorker) Generate(ctx context.Context, dependencies asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	rootCA := &tls.RootCA{}
+	dependencies.Get(installConfig, rootCA)
+
+	a.File = nil
+	if !hasRHELComputePool(installConfig.Config) {
+		return nil
+	}
+
+	configURL := url.URL{
+		Scheme: "https",
+		Host:   fmt.Sprintf("api.%s:22623", installConfig.Config.ClusterDomain()),
+		Path:   "/config/worker",
+	}
+	script, err := readTemplate("rhel/files/usr/local/bin/kni-node-bootstrap.sh.template", map[string]string{
+		"ConfigURL":      configURL.String(),
+		"RootCAPath":     rhelRootCAPath,
+		"PullSecretPath": rhelPullSecretPath,
+		"ReleaseImage":   release.ImageFromEnv(asset.SourcesFromContext(ctx).LookupEnv),
+		"Packages":       strings.Join(rhelNodePackages, " "),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to read the RHEL node bootstrap script")
+	}
+
+	pullSecret, err := installConfig.Config.MergedPullSecret()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cloudConfig{
+		WriteFiles: []cloudConfigFile{
+			{Path: rhelRootCAPath, Owner: "root:root", Permissions: "0644", Content: string(rootCA.Cert())},
+			{Path: rhelPullSecretPath, Owner: "root:root", Permissions: "0600", Content: pullSecret},
+			{Path: rhelBootstrapScriptPath, Owner: "root:root", Permissions: "0755", Content: script},
+		},
+		RunCmd: []string{
+			"update-ca-trust extract",
+			rhelBootstrapScriptPath,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the cloud-init user data")
+	}
+	a.File = &asset.File{
+		Filename: rhelWorkerUserDataFilename,
+		Data:     append([]byte("#cloud-config\n"), data...),
+	}
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *RHELWorker) Name() string {
+	return "RHEL Worker User Data"
+}
+
+// Files returns the files generated by the asset.
+func (a *RHELWorker) Files() []*asset.File {
+	if a.File != nil {
+		return []*asset.File{a.File}
+	}
+	return []*asset.File{}
+}
+
+// Load returns the RHEL worker user data from disk.
+func (a *RHELWorker) Load(f asset.FileFetcher) (found bool, err error) {
+	file, err := f.FetchByName(rhelWorkerUserDataFilename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	a.File = file
+	return true, nil
+}
+
+// hasRHELComputePool returns true if any compute pool of installConfig runs
+// RHEL.
+func hasRHELComputePool(installConfig *types.InstallConfig) bool {
+	for _, pool := range installConfig.Compute {
+		if pool.OperatingSystem == types.OperatingSystemRHEL {
+			return true
+		}
+	}
+	return false
+}
+
+// readTemplate reads the data asset at uri and executes it as a template.
+func readTemplate(uri string, templateData interface{}) (string, error) {
+	file, err := data.Assets.Open(uri)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	raw, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(uri).Parse(string(raw))
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,93 @@
+package machine
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/metalkube/kni-installer/data"
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestRHELWorkerGenerate(t *testing.T) {
+	defer func(assets http.FileSystem) { data.Assets = assets }(data.Assets)
+	data.Assets = http.Dir("../../../../data/data")
+
+	rootCA := &tls.RootCA{}
+	rootCAParents := asset.Parents{}
+	rootCAParents.Add(&installconfig.Ephemeral{}, &tls.UserRootCA{})
+	err := rootCA.Generate(context.Background(), rootCAParents)
+	assert.NoError(t, err, "unexpected error generating root CA")
+
+	cases := []struct {
+		name            string
+		operatingSystem types.OperatingSystem
+		expectedFile    bool
+	}{
+		{
+			name: "rhcos compute pool",
+		},
+		{
+			name:            "rhel compute pool",
+			operatingSystem: types.OperatingSystemRHEL,
+			expectedFile:    true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			installConfig := &installconfig.InstallConfig{
+				Config: &types.InstallConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+					BaseDomain: "example.com",
+					PullSecret: `{"auths":{"example.com":{"auth":"dGVzdDp0ZXN0"}}}`,
+					Compute: []types.MachinePool{
+						{Name: "worker", OperatingSystem: tc.operatingSystem},
+					},
+				},
+			}
+			parents := asset.Parents{}
+			parents.Add(installConfig, rootCA)
+
+			worker := &RHELWorker{}
+			err := worker.Generate(context.Background(), parents)
+			assert.NoError(t, err)
+			if !tc.expectedFile {
+				assert.Empty(t, worker.Files())
+				return
+			}
+			if !assert.Len(t, worker.Files(), 1) {
+				return
+			}
+			file := worker.Files()[0]
+			assert.Equal(t, "rhel-worker-user-data.yaml", file.Filename)
+			assert.True(t, strings.HasPrefix(string(file.Data), "#cloud-config\n"))
+
+			var config cloudConfig
+			err = yaml.Unmarshal(file.Data, &config)
+			assert.NoError(t, err)
+			if assert.Len(t, config.WriteFiles, 3) {
+				assert.Equal(t, "/etc/pki/ca-trust/source/anchors/kni-root-ca.crt", config.WriteFiles[0].Path)
+				assert.Equal(t, string(rootCA.Cert()), config.WriteFiles[0].Content)
+				assert.Equal(t, "/var/lib/kni-node-bootstrap/pull-secret.json", config.WriteFiles[1].Path)
+				assert.Equal(t, "0600", config.WriteFiles[1].Permissions)
+				assert.Equal(t, `{"auths":{"example.com":{"auth":"dGVzdDp0ZXN0"}}}`, config.WriteFiles[1].Content)
+				assert.Equal(t, "/usr/local/bin/kni-node-bootstrap.sh", config.WriteFiles[2].Path)
+				script := config.WriteFiles[2].Content
+				assert.Contains(t, script, "yum install --assumeyes conmon cri-o cri-tools openshift-clients openshift-hyperkube podman runc skopeo")
+				assert.Contains(t, script, "https://api.test-cluster.example.com:22623/config/worker")
+				assert.Contains(t, script, "--cacert /etc/pki/ca-trust/source/anchors/kni-root-ca.crt")
+				assert.Contains(t, script, "--image-for=machine-config-operator registry.svc.ci.openshift.org/openshift/origin-release:v4.0")
+				assert.Contains(t, script, "--once-from")
+			}
+			assert.Equal(t, []string{"update-ca-trust extract", "/usr/local/bin/kni-node-bootstrap.sh"}, config.RunCmd)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 
@@ -33,12 +34,13 @@ func (a *Worker) Dependencies() []asset.Asset {
 }
 
 // Generate generates the ignition config for the Worker asset.
-func (a *Worker) Generate(dependencies asset.Parents) error {
+func (a *Worker) Generate(ctx context.Context, dependencies asset.Parents) error {
 	installConfig := &installconfig.InstallConfig{}
 	rootCA := &tls.RootCA{}
 	dependencies.Get(installConfig, rootCA)
 
 	a.Config = pointerIgnitionConfig(installConfig.Config, rootCA.Cert(), "worker")
+	addDiskLayout(a.Config, computeDiskLayout(installConfig.Config))
 
 	data, err := json.Marshal(a.Config)
 	if err != nil {
@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,14 +30,16 @@ func TestWorkerGenerate(t *testing.T) {
 	}
 
 	rootCA := &tls.RootCA{}
-	err := rootCA.Generate(nil)
+	rootCAParents := asset.Parents{}
+	rootCAParents.Add(&installconfig.Ephemeral{}, &tls.UserRootCA{})
+	err := rootCA.Generate(context.Background(), rootCAParents)
 	assert.NoError(t, err, "unexpected error generating root CA")
 
 	parents := asset.Parents{}
 	parents.Add(installConfig, rootCA)
 
 	worker := &Worker{}
-	err = worker.Generate(parents)
+	err = worker.Generate(context.Background(), parents)
 	assert.NoError(t, err, "unexpected error generating worker asset")
 
 	actualFiles := worker.Files()
@@ -0,0 +1,33 @@
+package ignition
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// RegistriesConfPath is where CRI-O and podman read their registry
+// configuration from.
+const RegistriesConfPath = "/etc/containers/registries.conf"
+
+// RegistriesConf returns a registries.conf which pulls the mirror's sources
+// by digest from the registry mirror, falling back to the sources once the
+// mirror is gone.
+func RegistriesConf(m *baremetal.RegistryMirror) string {
+	var contents strings.Builder
+	contents.WriteString(`unqualified-search-registries = ["registry.access.redhat.com", "docker.io"]
+`)
+	for _, source := range m.Sources {
+		fmt.Fprintf(&contents, `
+[[registry]]
+location = %q
+mirror-by-digest-only = true
+
+[[registry.mirror]]
+location = "%s/%s"
+insecure = true
+`, source, m.Endpoint(), baremetal.RegistryMirrorRepository)
+	}
+	return contents.String()
+}
@@ -2,6 +2,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws/defaults"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/metalkube/kni-installer/pkg/answers"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/offline"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/aws/validation"
 	"github.com/metalkube/kni-installer/pkg/version"
@@ -22,7 +26,7 @@ import (
 )
 
 // Platform collects AWS-specific configuration.
-func Platform() (*aws.Platform, error) {
+func Platform(ctx context.Context) (*aws.Platform, error) {
 	longRegions := make([]string, 0, len(validation.Regions))
 	shortRegions := make([]string, 0, len(validation.Regions))
 	for id, location := range validation.Regions {
@@ -36,10 +40,10 @@ func Platform() (*aws.Platform, error) {
 	defaultRegion := "us-east-1"
 	_, ok := validation.Regions[defaultRegion]
 	if !ok {
-		panic(fmt.Sprintf("installer bug: invalid default AWS region %q", defaultRegion))
+		return nil, errors.Errorf("installer bug: invalid default AWS region %q", defaultRegion)
 	}
 
-	ssn, err := GetSession()
+	ssn, err := GetSession(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +62,7 @@ func Platform() (*aws.Platform, error) {
 	sort.Strings(shortRegions)
 
 	var region string
-	err = survey.Ask([]*survey.Question{
+	err = answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Select{
 				Message: "Region",
@@ -88,7 +92,10 @@ func Platform() (*aws.Platform, error) {
 
 // GetSession returns an AWS session by checking credentials
 // and, if no creds are found, asks for them and stores them on disk in a config file
-func GetSession() (*session.Session, error) {
+func GetSession(ctx context.Context) (*session.Session, error) {
+	if err := offline.Check(ctx, "connect to AWS"); err != nil {
+		return nil, err
+	}
 	ssn := session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
 	}))
@@ -112,7 +119,7 @@ func GetSession() (*session.Session, error) {
 
 func getCredentials() error {
 	var keyID string
-	err := survey.Ask([]*survey.Question{
+	err := answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Input{
 				Message: "AWS Access Key ID",
@@ -125,7 +132,7 @@ func getCredentials() error {
 	}
 
 	var secretKey string
-	err = survey.Ask([]*survey.Question{
+	err = answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Password{
 				Message: "AWS Secret Access Key",
@@ -178,5 +185,9 @@ func getCredentials() error {
 		return err
 	}
 
-	return os.Rename(tempPath, path)
+	if err := os.Rename(tempPath, path); err != nil {
+		return err
+	}
+	fileaudit.RecordFile(fileaudit.Write, path, "AWS credentials")
+	return nil
 }
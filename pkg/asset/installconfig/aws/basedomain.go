@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"net/http"
 	"sort"
 	"strings"
@@ -11,6 +12,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	survey "gopkg.in/AlecAivazis/survey.v1"
+
+	"github.com/metalkube/kni-installer/pkg/answers"
 )
 
 // IsForbidden returns true if and only if the input error is an HTTP
@@ -22,8 +25,8 @@ func IsForbidden(err error) bool {
 
 // GetBaseDomain returns a base domain chosen from among the account's
 // public routes.
-func GetBaseDomain() (string, error) {
-	session, err := GetSession()
+func GetBaseDomain(ctx context.Context) (string, error) {
+	session, err := GetSession(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -62,7 +65,7 @@ func GetBaseDomain() (string, error) {
 	}
 
 	var domain string
-	if err := survey.AskOne(&survey.Select{
+	if err := answers.AskOne(&survey.Select{
 		Message: "Base Domain",
 		Help:    "The base domain of the cluster. All DNS records will be sub-domains of this base and will also include the cluster name.\n\nIf you don't see you intended base-domain listed, create a new public Route53 hosted zone and rerun the installer.",
 		Options: publicZones,
@@ -81,7 +84,7 @@ func GetBaseDomain() (string, error) {
 }
 
 // GetPublicZone returns a public route53 zone that matches the name.
-func GetPublicZone(name string) (*route53.HostedZone, error) {
+func GetPublicZone(ctx context.Context, name string) (*route53.HostedZone, error) {
 	var res *route53.HostedZone
 	f := func(resp *route53.ListHostedZonesOutput, lastPage bool) (shouldContinue bool) {
 		for idx, zone := range resp.HostedZones {
@@ -93,7 +96,7 @@ func GetPublicZone(name string) (*route53.HostedZone, error) {
 		return !lastPage
 	}
 
-	session, err := GetSession()
+	session, err := GetSession(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting AWS session")
 	}
@@ -4,6 +4,7 @@ package baremetal
 import (
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
 	baremetaldefaults "github.com/metalkube/kni-installer/pkg/types/baremetal/defaults"
 	"github.com/metalkube/kni-installer/pkg/validate"
@@ -12,7 +13,7 @@ import (
 // Platform collects bare metal specific configuration.
 func Platform() (*baremetal.Platform, error) {
 	var uri string
-	err := survey.Ask([]*survey.Question{
+	err := answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Input{
 				Message: "Libvirt Connection URI",
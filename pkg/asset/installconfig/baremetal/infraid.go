@@ -0,0 +1,46 @@
+// +build libvirt
+
+package baremetal
+
+import (
+	"encoding/xml"
+
+	libvirt "github.com/libvirt/libvirt-go"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// CheckInfraIDCollision returns an error if a libvirt domain reachable at uri
+// is already tagged with infraID.
+func CheckInfraIDCollision(uri, infraID string) error {
+	conn, err := libvirt.NewConnect(uri)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Libvirt daemon")
+	}
+	defer conn.Close()
+
+	domains, err := conn.ListAllDomains(0)
+	if err != nil {
+		return errors.Wrap(err, "list domains")
+	}
+	for _, domain := range domains {
+		defer domain.Free()
+		raw, err := domain.GetMetadata(libvirt.DOMAIN_METADATA_ELEMENT, baremetal.DomainMetadataNamespace, libvirt.DOMAIN_AFFECT_CONFIG)
+		if err != nil {
+			if lerr, ok := err.(libvirt.Error); ok && lerr.Code == libvirt.ERR_NO_DOMAIN_METADATA {
+				continue
+			}
+			return errors.Wrap(err, "get domain metadata")
+		}
+		metadata := &baremetal.DomainMetadata{}
+		if err := xml.Unmarshal([]byte(raw), metadata); err != nil {
+			return errors.Wrap(err, "parse domain metadata")
+		}
+		if metadata.InfraID == infraID {
+			name, _ := domain.GetName()
+			return errors.Errorf("libvirt domain %q already belongs to a cluster with this infra ID", name)
+		}
+	}
+	return nil
+}
@@ -1,11 +1,14 @@
 package installconfig
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig/aws"
 	"github.com/metalkube/kni-installer/pkg/validate"
@@ -25,13 +28,13 @@ func (a *baseDomain) Dependencies() []asset.Asset {
 }
 
 // Generate queries for the base domain from the user.
-func (a *baseDomain) Generate(parents asset.Parents) error {
+func (a *baseDomain) Generate(ctx context.Context, parents asset.Parents) error {
 	platform := &platform{}
 	parents.Get(platform)
 
 	if platform.AWS != nil {
 		var err error
-		a.BaseDomain, err = aws.GetBaseDomain()
+		a.BaseDomain, err = aws.GetBaseDomain(ctx)
 		cause := errors.Cause(err)
 		if !(aws.IsForbidden(cause) || request.IsErrorThrottle(cause)) {
 			return err
@@ -39,7 +42,7 @@ func (a *baseDomain) Generate(parents asset.Parents) error {
 		logrus.Error(err)
 	}
 
-	return survey.Ask([]*survey.Question{
+	return answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Input{
 				Message: "Base Domain",
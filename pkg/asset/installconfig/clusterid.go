@@ -1,20 +1,27 @@
 package installconfig
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 
-	"github.com/pborman/uuid"
-	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	baremetalconfig "github.com/metalkube/kni-installer/pkg/asset/installconfig/baremetal"
+	libvirtconfig "github.com/metalkube/kni-installer/pkg/asset/installconfig/libvirt"
+	"github.com/metalkube/kni-installer/pkg/simulate"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
+	"github.com/metalkube/kni-installer/pkg/types/none"
 )
 
 const (
-	// resource using InfraID usually have suffixes like `[-/_][a-z]{3,4}` eg. `_int`, `-ext` or `-ctlp`
-	maxNameLen = 32 - 5
-	randomLen  = 5
-	maxBaseLen = maxNameLen - randomLen - 1
+	maxNameLen = types.InfraIDMaxLength
+	randomLen  = types.InfraIDDefaultRandomLength
 )
 
 // ClusterID is the unique ID of the cluster, immutable during the cluster's life
@@ -37,25 +44,75 @@ func (a *ClusterID) Dependencies() []asset.Asset {
 }
 
 // Generate generates a new ClusterID
-func (a *ClusterID) Generate(dep asset.Parents) error {
+func (a *ClusterID) Generate(ctx context.Context, dep asset.Parents) error {
 	ica := &InstallConfig{}
 	dep.Get(ica)
 
+	base, length := ica.Config.ObjectMeta.Name, randomLen
+	if infraID := ica.Config.InfraID; infraID != nil {
+		if infraID.Prefix != "" {
+			base = infraID.Prefix
+		}
+		if infraID.RandomLength != nil {
+			length = int(*infraID.RandomLength)
+		}
+		if infraID.Value != "" {
+			base, length = infraID.Value, 0
+		}
+	}
+
 	// add random chars to the end to randomize
-	a.InfraID = generateInfraID(ica.Config.ObjectMeta.Name)
-	a.UUID = uuid.New()
+	sources := asset.SourcesFromContext(ctx)
+	a.InfraID = generateInfraID(base, length, sources.RandomString)
+	a.UUID = sources.UUID()
+
+	// Random IDs are assumed unique, but pinned ones are easily reused.
+	if length == 0 {
+		if err := checkInfraIDCollision(ctx, ica.Config, a.InfraID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// checkInfraIDCollision returns an error if resources belonging to infraID
+// already exist on the platform.  Only the libvirt daemons of the bare
+// metal and libvirt platforms are checked; on the cloud platforms a
+// warning says the ID is not checked.  Simulated installs have no
+// platform to check.
+func checkInfraIDCollision(ctx context.Context, config *types.InstallConfig, infraID string) error {
+	if simulate.FromContext(ctx) {
+		return nil
+	}
+	var err error
+	switch platform := config.Platform.Name(); platform {
+	case baremetal.Name:
+		err = baremetalconfig.CheckInfraIDCollision(config.Platform.BareMetal.URI, infraID)
+	case libvirt.Name:
+		err = libvirtconfig.CheckInfraIDCollision(config.Platform.Libvirt.URI, infraID)
+	case fake.Name, none.Name:
+		// Nothing is provisioned, so nothing can collide.
+	default:
+		logrus.Warnf("Not checking the %s platform for resources already using infra ID %q", platform, infraID)
+	}
+	return errors.Wrapf(err, "infra ID %q cannot be used", infraID)
+}
+
 // Name returns the human-friendly name of the asset.
 func (a *ClusterID) Name() string {
 	return "Cluster ID"
 }
 
 // generateInfraID take base and returns a ID that
-// - is of length maxNameLen
+// - is at most of length maxNameLen
 // - only contains `alphanum` or `-`
-func generateInfraID(base string) string {
+// - ends with length random characters from randomString, unless length is zero
+func generateInfraID(base string, length int, randomString func(int) string) string {
+	maxBaseLen := maxNameLen
+	if length > 0 {
+		maxBaseLen -= length + 1
+	}
+
 	// truncate to maxBaseLen
 	if len(base) > maxBaseLen {
 		base = base[:maxBaseLen]
@@ -65,6 +122,10 @@ func generateInfraID(base string) string {
 	re := regexp.MustCompile("[^A-Za-z0-9-]")
 	base = re.ReplaceAllString(base, "-")
 
+	if length == 0 {
+		return base
+	}
+
 	// add random chars to the end to randomize
-	return fmt.Sprintf("%s-%s", base, utilrand.String(randomLen))
+	return fmt.Sprintf("%s-%s", base, randomString(length))
 }
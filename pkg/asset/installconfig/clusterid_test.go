@@ -4,33 +4,58 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
 )
 
 func Test_generateInfraID(t *testing.T) {
 	tests := []struct {
-		input string
+		input  string
+		length int
 
 		expLen     int
 		expNonRand string
 	}{{
 		input:      "qwertyuiop",
+		length:     randomLen,
 		expLen:     10 + randomLen + 1,
 		expNonRand: "qwertyuiop",
 	}, {
 		input:      "qwertyuiopasdfghjklzxcvbnm",
-		expLen:     maxBaseLen + randomLen + 1,
+		length:     randomLen,
+		expLen:     maxNameLen,
 		expNonRand: "qwertyuiopasdfghjklzx",
 	}, {
 		input:      "qwe.rty.@iop!",
+		length:     randomLen,
 		expLen:     13 + randomLen + 1,
 		expNonRand: "qwe-rty--iop-",
+	}, {
+		input:      "qwertyuiop",
+		length:     2,
+		expLen:     10 + 2 + 1,
+		expNonRand: "qwertyuiop",
+	}, {
+		input:      "qwertyuiopasdfghjklzxcvbnm",
+		length:     0,
+		expLen:     26,
+		expNonRand: "qwertyuiopasdfghjklzxcvbnm",
+	}, {
+		input:      "qwertyuiopasdfghjklzxcvbnm-1234",
+		length:     0,
+		expLen:     maxNameLen,
+		expNonRand: "qwertyuiopasdfghjklzxcvbnm-",
 	}}
 	for _, test := range tests {
 		t.Run("", func(t *testing.T) {
-			got := generateInfraID(test.input)
+			got := generateInfraID(test.input, test.length, asset.SystemSources.RandomString)
 			t.Log("InfraID", got)
 			assert.Equal(t, test.expLen, len(got))
-			assert.Equal(t, test.expNonRand, got[:len(got)-randomLen-1])
+			if test.length == 0 {
+				assert.Equal(t, test.expNonRand, got)
+			} else {
+				assert.Equal(t, test.expNonRand, got[:len(got)-test.length-1])
+			}
 		})
 	}
 }
@@ -1,8 +1,11 @@
 package installconfig
 
 import (
+	"context"
+
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/types/validation"
 	"github.com/metalkube/kni-installer/pkg/validate"
@@ -22,11 +25,11 @@ func (a *clusterName) Dependencies() []asset.Asset {
 }
 
 // Generate queries for the cluster name from the user.
-func (a *clusterName) Generate(parents asset.Parents) error {
+func (a *clusterName) Generate(ctx context.Context, parents asset.Parents) error {
 	bd := &baseDomain{}
 	parents.Get(bd)
 
-	return survey.Ask([]*survey.Question{
+	return answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Input{
 				Message: "Cluster Name",
@@ -0,0 +1,138 @@
+package installconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	dnsRecordsFilename = "dns-records.json"
+)
+
+// DNSRecords is the machine-readable list of the DNS records the cluster
+// requires, by view of a split-horizon DNS, for provisioning them on
+// platforms where the user provides DNS.
+type DNSRecords struct {
+	Views []DNSView
+	File  *asset.File
+}
+
+// DNSView is the records of one view of the DNS: "external" for clients
+// outside the datacenter and "internal" for the cluster's machines, or
+// "default" for both without split-horizon DNS.
+type DNSView struct {
+	Name    string      `json:"name"`
+	Records []DNSRecord `json:"records"`
+}
+
+// DNSRecord is an A, AAAA or CNAME record.
+type DNSRecord struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+var _ asset.WritableAsset = (*DNSRecords)(nil)
+
+// Dependencies returns the install config the records are derived from.
+func (a *DNSRecords) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&InstallConfig{},
+	}
+}
+
+// Generate derives the records from the install config.
+func (a *DNSRecords) Generate(_ context.Context, dependencies asset.Parents) error {
+	installConfig := &InstallConfig{}
+	dependencies.Get(installConfig)
+
+	a.Views = dnsViews(installConfig.Config)
+	data, err := json.MarshalIndent(struct {
+		Views []DNSView `json:"views"`
+	}{Views: a.Views}, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", a.Name())
+	}
+	a.File = &asset.File{
+		Filename: dnsRecordsFilename,
+		Data:     append(data, '\n'),
+	}
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *DNSRecords) Name() string {
+	return "DNS Records"
+}
+
+// Files returns the files generated by the asset.
+func (a *DNSRecords) Files() []*asset.File {
+	if a.File != nil {
+		return []*asset.File{a.File}
+	}
+	return []*asset.File{}
+}
+
+// Load is a no-op because the records are derived from the install
+// config.
+func (a *DNSRecords) Load(asset.FileFetcher) (bool, error) {
+	return false, nil
+}
+
+// dnsViews returns the records of the API and ingress names.  The targets
+// are the views of dns.views, or else the bare metal VIPs; there are no
+// records where neither is known, e.g. on the platforms where the
+// installer creates the records itself.
+func dnsViews(config *types.InstallConfig) []DNSView {
+	api := fmt.Sprintf("api.%s", config.ClusterDomain())
+	apiInt := fmt.Sprintf("api-int.%s", config.ClusterDomain())
+	apps := fmt.Sprintf("*.apps.%s", config.ClusterDomain())
+
+	var apiVIP, ingressVIP []string
+	if bm := config.Platform.BareMetal; bm != nil {
+		if bm.APIVIP != "" {
+			apiVIP = []string{bm.APIVIP}
+		}
+		if bm.IngressVIP != "" {
+			ingressVIP = []string{bm.IngressVIP}
+		}
+	}
+
+	views := []DNSView{}
+	if config.DNS != nil && config.DNS.Views != nil {
+		v := config.DNS.Views
+		views = append(views,
+			DNSView{Name: "external", Records: append(dnsRecords(api, v.External), dnsRecords(apps, ingressVIP)...)},
+			DNSView{Name: "internal", Records: append(append(dnsRecords(api, v.Internal), dnsRecords(apiInt, v.Internal)...), dnsRecords(apps, ingressVIP)...)},
+		)
+	} else if len(apiVIP) > 0 {
+		views = append(views,
+			DNSView{Name: "default", Records: append(append(dnsRecords(api, apiVIP), dnsRecords(apiInt, apiVIP)...), dnsRecords(apps, ingressVIP)...)},
+		)
+	}
+	return views
+}
+
+// dnsRecords returns the records resolving name to targets: A or AAAA
+// records for IP addresses and CNAME records for names.
+func dnsRecords(name string, targets []string) []DNSRecord {
+	records := []DNSRecord{}
+	for _, target := range targets {
+		recordType := "CNAME"
+		if ip := net.ParseIP(target); ip != nil {
+			recordType = "AAAA"
+			if ip.To4() != nil {
+				recordType = "A"
+			}
+		}
+		records = append(records, DNSRecord{Name: name, Type: recordType, Target: target})
+	}
+	return records
+}
@@ -0,0 +1,99 @@
+package installconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/aws"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/none"
+)
+
+func TestDNSViews(t *testing.T) {
+	cases := []struct {
+		name     string
+		platform types.Platform
+		dns      *types.DNS
+		expected []DNSView
+	}{
+		{
+			name:     "aws",
+			platform: types.Platform{AWS: &aws.Platform{Region: "us-east-1"}},
+			expected: []DNSView{},
+		},
+		{
+			name:     "bare metal",
+			platform: types.Platform{BareMetal: &baremetal.Platform{APIVIP: "192.168.111.5", IngressVIP: "192.168.111.4"}},
+			expected: []DNSView{{
+				Name: "default",
+				Records: []DNSRecord{
+					{Name: "api.test-cluster.example.com", Type: "A", Target: "192.168.111.5"},
+					{Name: "api-int.test-cluster.example.com", Type: "A", Target: "192.168.111.5"},
+					{Name: "*.apps.test-cluster.example.com", Type: "A", Target: "192.168.111.4"},
+				},
+			}},
+		},
+		{
+			name:     "split horizon",
+			platform: types.Platform{BareMetal: &baremetal.Platform{APIVIP: "192.168.111.5", IngressVIP: "192.168.111.4"}},
+			dns: &types.DNS{Views: &types.DNSViews{
+				External: []string{"lb.example.com"},
+				Internal: []string{"192.168.111.5", "fd00::5"},
+			}},
+			expected: []DNSView{
+				{
+					Name: "external",
+					Records: []DNSRecord{
+						{Name: "api.test-cluster.example.com", Type: "CNAME", Target: "lb.example.com"},
+						{Name: "*.apps.test-cluster.example.com", Type: "A", Target: "192.168.111.4"},
+					},
+				},
+				{
+					Name: "internal",
+					Records: []DNSRecord{
+						{Name: "api.test-cluster.example.com", Type: "A", Target: "192.168.111.5"},
+						{Name: "api.test-cluster.example.com", Type: "AAAA", Target: "fd00::5"},
+						{Name: "api-int.test-cluster.example.com", Type: "A", Target: "192.168.111.5"},
+						{Name: "api-int.test-cluster.example.com", Type: "AAAA", Target: "fd00::5"},
+						{Name: "*.apps.test-cluster.example.com", Type: "A", Target: "192.168.111.4"},
+					},
+				},
+			},
+		},
+		{
+			name:     "split horizon without VIPs",
+			platform: types.Platform{None: &none.Platform{}},
+			dns: &types.DNS{Views: &types.DNSViews{
+				External: []string{"203.0.113.5"},
+				Internal: []string{"10.0.0.5"},
+			}},
+			expected: []DNSView{
+				{
+					Name:    "external",
+					Records: []DNSRecord{{Name: "api.test-cluster.example.com", Type: "A", Target: "203.0.113.5"}},
+				},
+				{
+					Name: "internal",
+					Records: []DNSRecord{
+						{Name: "api.test-cluster.example.com", Type: "A", Target: "10.0.0.5"},
+						{Name: "api-int.test-cluster.example.com", Type: "A", Target: "10.0.0.5"},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &types.InstallConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+				BaseDomain: "example.com",
+				Platform:   tc.platform,
+				DNS:        tc.dns,
+			}
+			assert.Equal(t, tc.expected, dnsViews(config))
+		})
+	}
+}
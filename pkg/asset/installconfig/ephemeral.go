@@ -0,0 +1,79 @@
+package installconfig
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+const (
+	// EphemeralTTLEnvVar is the environment variable which, when set to a
+	// duration such as 6h, makes the cluster ephemeral.
+	EphemeralTTLEnvVar = "OPENSHIFT_INSTALL_EPHEMERAL_TTL"
+
+	// MinEphemeralTTL is the shortest lifetime which leaves time for the
+	// cluster to install.
+	MinEphemeralTTL = time.Hour
+)
+
+// Ephemeral is the lifetime of a throwaway cluster, e.g. one created for a
+// CI job.  The certificates and credentials generated for an ephemeral
+// cluster expire with it, limiting the damage done by leaked artifacts.
+type Ephemeral struct {
+	// TTL is how long the cluster lives.  It is zero for regular clusters.
+	TTL time.Duration
+
+	// ExpiresAt is when the cluster's certificates and credentials expire,
+	// and the cluster should be destroyed.
+	ExpiresAt *time.Time
+}
+
+var _ asset.Asset = (*Ephemeral)(nil)
+
+// Dependencies returns no dependencies.
+func (a *Ephemeral) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Generate reads the lifetime from the environment, fixing the expiry time
+// so that every asset generated from it agrees.
+func (a *Ephemeral) Generate(ctx context.Context, _ asset.Parents) error {
+	sources := asset.SourcesFromContext(ctx)
+	value, _ := sources.LookupEnv(EphemeralTTLEnvVar)
+	if value == "" {
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s", EphemeralTTLEnvVar)
+	}
+	if ttl < MinEphemeralTTL {
+		return errors.Errorf("the TTL of an ephemeral cluster must be at least %s", MinEphemeralTTL)
+	}
+
+	expiresAt := sources.Now().Add(ttl).UTC().Truncate(time.Second)
+	a.TTL = ttl
+	a.ExpiresAt = &expiresAt
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *Ephemeral) Name() string {
+	return "Ephemeral Cluster Lifetime"
+}
+
+// Validity caps validity at the remaining lifetime of an ephemeral
+// cluster, and returns it unchanged for regular clusters.
+func (a *Ephemeral) Validity(validity time.Duration) time.Duration {
+	if a.ExpiresAt == nil {
+		return validity
+	}
+	if remaining := time.Until(*a.ExpiresAt); remaining < validity {
+		return remaining
+	}
+	return validity
+}
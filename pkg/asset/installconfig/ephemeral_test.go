@@ -0,0 +1,35 @@
+package installconfig
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEphemeral(t *testing.T) {
+	defer os.Unsetenv(EphemeralTTLEnvVar)
+
+	a := &Ephemeral{}
+	assert.NoError(t, a.Generate(context.Background(), nil))
+	assert.Nil(t, a.ExpiresAt)
+	assert.Equal(t, 10*time.Hour, a.Validity(10*time.Hour))
+
+	os.Setenv(EphemeralTTLEnvVar, "6h")
+	a = &Ephemeral{}
+	assert.NoError(t, a.Generate(context.Background(), nil))
+	assert.Equal(t, 6*time.Hour, a.TTL)
+	if assert.NotNil(t, a.ExpiresAt) {
+		assert.WithinDuration(t, time.Now().Add(6*time.Hour), *a.ExpiresAt, time.Minute)
+	}
+	assert.InDelta(t, float64(6*time.Hour), float64(a.Validity(10*time.Hour)), float64(time.Minute))
+	assert.Equal(t, time.Hour, a.Validity(time.Hour))
+
+	os.Setenv(EphemeralTTLEnvVar, "30m")
+	assert.EqualError(t, (&Ephemeral{}).Generate(context.Background(), nil), "the TTL of an ephemeral cluster must be at least 1h0m0s")
+
+	os.Setenv(EphemeralTTLEnvVar, "forever")
+	assert.Error(t, (&Ephemeral{}).Generate(context.Background(), nil))
+}
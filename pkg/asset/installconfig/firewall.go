@@ -0,0 +1,68 @@
+package installconfig
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/firewall"
+)
+
+const (
+	firewallRequirementsFilename = "firewall-requirements.json"
+)
+
+// FirewallRequirements is the machine-readable list of the network flows
+// the cluster requires, for security teams opening firewalls ahead of the
+// install.
+type FirewallRequirements struct {
+	Requirements *firewall.Requirements
+	File         *asset.File
+}
+
+var _ asset.WritableAsset = (*FirewallRequirements)(nil)
+
+// Dependencies returns the install config the flows are derived from.
+func (a *FirewallRequirements) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&InstallConfig{},
+	}
+}
+
+// Generate derives the flows from the install config.
+func (a *FirewallRequirements) Generate(_ context.Context, dependencies asset.Parents) error {
+	installConfig := &InstallConfig{}
+	dependencies.Get(installConfig)
+
+	a.Requirements = firewall.ForConfig(installConfig.Config)
+	data, err := json.MarshalIndent(a.Requirements, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", a.Name())
+	}
+	a.File = &asset.File{
+		Filename: firewallRequirementsFilename,
+		Data:     append(data, '\n'),
+	}
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *FirewallRequirements) Name() string {
+	return "Firewall Requirements"
+}
+
+// Files returns the files generated by the asset.
+func (a *FirewallRequirements) Files() []*asset.File {
+	if a.File != nil {
+		return []*asset.File{a.File}
+	}
+	return []*asset.File{}
+}
+
+// Load is a no-op because the requirements are derived from the install
+// config.
+func (a *FirewallRequirements) Load(asset.FileFetcher) (bool, error) {
+	return false, nil
+}
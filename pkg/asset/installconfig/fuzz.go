@@ -0,0 +1,50 @@
+// +build gofuzz
+
+package installconfig
+
+import (
+	"github.com/ghodss/yaml"
+)
+
+// Fuzz is a fuzz testing function designed to be used with go-fuzz:
+//
+//	https://github.com/dvyukov/go-fuzz
+//
+// It parses the data as an install-config.yaml, as Load does, and then
+// marshals the result back.  It's not included in a normal build due to
+// the gofuzz build tag above.  The install configs under
+// pkg/asset/golden/testdata make a good seed corpus.
+func Fuzz(data []byte) int {
+	a := &InstallConfig{}
+	if err := a.parse(data, fuzzValidValuesFetcher{}); err != nil {
+		return 0
+	}
+	if _, err := yaml.Marshal(a.Config); err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// fuzzValidValuesFetcher answers for an OpenStack cloud without network
+// access, so fuzzing does not depend on one.
+type fuzzValidValuesFetcher struct{}
+
+func (fuzzValidValuesFetcher) GetCloudNames() ([]string, error) {
+	return []string{"fuzz"}, nil
+}
+
+func (fuzzValidValuesFetcher) GetRegionNames(cloud string) ([]string, error) {
+	return []string{"fuzz"}, nil
+}
+
+func (fuzzValidValuesFetcher) GetNetworkNames(cloud string) ([]string, error) {
+	return []string{"fuzz"}, nil
+}
+
+func (fuzzValidValuesFetcher) GetFlavorNames(cloud string) ([]string, error) {
+	return []string{"fuzz"}, nil
+}
+
+func (fuzzValidValuesFetcher) GetNetworkExtensionsAliases(cloud string) ([]string, error) {
+	return []string{"trunk"}, nil
+}
@@ -0,0 +1,133 @@
+package installconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+const (
+	hardwareInventoryJSONFilename = "hardware-inventory.json"
+	hardwareInventoryCSVFilename  = "hardware-inventory.csv"
+)
+
+// HardwareInventory is the report of the bare metal hosts' hardware and
+// firmware at install time, as JSON and as CSV, for support cases and
+// lifecycle tracking.  It is empty for other platforms.
+type HardwareInventory struct {
+	Hosts    []baremetal.InventoryHost
+	FileList []*asset.File
+}
+
+var _ asset.WritableAsset = (*HardwareInventory)(nil)
+
+// Dependencies returns the install config the report is derived from.
+func (a *HardwareInventory) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&InstallConfig{},
+	}
+}
+
+// Generate derives the report from the install config's hosts.
+func (a *HardwareInventory) Generate(_ context.Context, dependencies asset.Parents) error {
+	installConfig := &InstallConfig{}
+	dependencies.Get(installConfig)
+
+	a.Hosts, a.FileList = nil, nil
+	bm := installConfig.Config.Platform.BareMetal
+	if bm == nil || len(bm.Hosts) == 0 {
+		return nil
+	}
+	a.Hosts = baremetal.Inventory(bm.Hosts)
+
+	data, err := json.MarshalIndent(struct {
+		Hosts []baremetal.InventoryHost `json:"hosts"`
+	}{Hosts: a.Hosts}, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", a.Name())
+	}
+	csvData, err := inventoryCSV(a.Hosts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s as CSV", a.Name())
+	}
+	a.FileList = []*asset.File{
+		{Filename: hardwareInventoryJSONFilename, Data: append(data, '\n')},
+		{Filename: hardwareInventoryCSVFilename, Data: csvData},
+	}
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *HardwareInventory) Name() string {
+	return "Hardware Inventory"
+}
+
+// Files returns the files generated by the asset.
+func (a *HardwareInventory) Files() []*asset.File {
+	if a.FileList != nil {
+		return a.FileList
+	}
+	return []*asset.File{}
+}
+
+// Load is a no-op because the report is derived from the install config.
+func (a *HardwareInventory) Load(asset.FileFetcher) (bool, error) {
+	return false, nil
+}
+
+// inventoryColumns are the columns of the CSV report.
+var inventoryColumns = []string{
+	"name", "role", "pool", "bootMACAddress", "unprovisioned",
+	"cpus", "memoryMiB", "rootDiskGiB", "rootDiskRotational",
+	"manufacturer", "productName", "serialNumber",
+	"biosVendor", "biosVersion", "biosDate", "bmcVersion",
+}
+
+// inventoryCSV returns the CSV report of hosts, with a header row and a
+// row per host.  Unknown values are left empty.
+func inventoryCSV(hosts []baremetal.InventoryHost) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(inventoryColumns); err != nil {
+		return nil, err
+	}
+	for _, h := range hosts {
+		hw := h.Hardware
+		if hw == nil {
+			hw = &baremetal.HostHardware{}
+		}
+		fw := hw.Firmware
+		if fw == nil {
+			fw = &baremetal.HostFirmware{}
+		}
+		row := []string{
+			h.Name, h.Role, h.Pool, h.BootMACAddress, strconv.FormatBool(h.Unprovisioned),
+			formatInt(int64(hw.CPUs)), formatInt(hw.MemoryMiB), formatInt(hw.RootDiskGiB), "",
+			fw.Manufacturer, fw.ProductName, fw.SerialNumber,
+			fw.BIOSVendor, fw.BIOSVersion, fw.BIOSDate, fw.BMCVersion,
+		}
+		if h.Hardware != nil {
+			row[8] = strconv.FormatBool(hw.RootDiskRotational)
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// formatInt formats a hardware value, leaving unknown (zero) values empty.
+func formatInt(v int64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
@@ -0,0 +1,119 @@
+package installconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/aws"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestHardwareInventoryGenerate(t *testing.T) {
+	cases := []struct {
+		name         string
+		platform     types.Platform
+		expectedJSON string
+		expectedCSV  string
+	}{
+		{
+			name:     "aws",
+			platform: types.Platform{AWS: &aws.Platform{Region: "us-east-1"}},
+		},
+		{
+			name:     "bare metal without hosts",
+			platform: types.Platform{BareMetal: &baremetal.Platform{}},
+		},
+		{
+			name: "bare metal",
+			platform: types.Platform{BareMetal: &baremetal.Platform{Hosts: []baremetal.Host{
+				{
+					Name:           "master-0",
+					Role:           baremetal.MasterRole,
+					BootMACAddress: "52:54:00:aa:bb:01",
+					Hardware: &baremetal.HostHardware{
+						CPUs:        32,
+						MemoryMiB:   196608,
+						RootDiskGiB: 480,
+						Firmware: &baremetal.HostFirmware{
+							Manufacturer: "Dell Inc.",
+							ProductName:  "PowerEdge R640",
+							SerialNumber: "ABC1234",
+							BIOSVendor:   "Dell Inc.",
+							BIOSVersion:  "2.1.8",
+							BIOSDate:     "04/30/2019",
+							BMCVersion:   "3.30.30.30",
+						},
+					},
+				},
+				{
+					Name:          "worker-0",
+					Role:          baremetal.WorkerRole,
+					Pool:          "edge",
+					Unprovisioned: true,
+				},
+			}}},
+			expectedJSON: `{
+  "hosts": [
+    {
+      "name": "master-0",
+      "role": "master",
+      "pool": "master",
+      "bootMACAddress": "52:54:00:aa:bb:01",
+      "hardware": {
+        "cpus": 32,
+        "memoryMiB": 196608,
+        "rootDiskGiB": 480,
+        "firmware": {
+          "manufacturer": "Dell Inc.",
+          "productName": "PowerEdge R640",
+          "serialNumber": "ABC1234",
+          "biosVendor": "Dell Inc.",
+          "biosVersion": "2.1.8",
+          "biosDate": "04/30/2019",
+          "bmcVersion": "3.30.30.30"
+        }
+      }
+    },
+    {
+      "name": "worker-0",
+      "role": "worker",
+      "pool": "edge",
+      "unprovisioned": true
+    }
+  ]
+}
+`,
+			expectedCSV: `name,role,pool,bootMACAddress,unprovisioned,cpus,memoryMiB,rootDiskGiB,rootDiskRotational,manufacturer,productName,serialNumber,biosVendor,biosVersion,biosDate,bmcVersion
+master-0,master,master,52:54:00:aa:bb:01,false,32,196608,480,false,Dell Inc.,PowerEdge R640,ABC1234,Dell Inc.,2.1.8,04/30/2019,3.30.30.30
+worker-0,worker,edge,,true,,,,,,,,,,,
+`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parents := asset.Parents{}
+			parents.Add(&InstallConfig{Config: &types.InstallConfig{Platform: tc.platform}})
+
+			inventory := &HardwareInventory{}
+			if !assert.NoError(t, inventory.Generate(context.Background(), parents)) {
+				return
+			}
+			if tc.expectedJSON == "" {
+				assert.Empty(t, inventory.Files())
+				return
+			}
+			files := inventory.Files()
+			if !assert.Len(t, files, 2) {
+				return
+			}
+			assert.Equal(t, "hardware-inventory.json", files[0].Filename)
+			assert.Equal(t, tc.expectedJSON, string(files[0].Data))
+			assert.Equal(t, "hardware-inventory.csv", files[1].Filename)
+			assert.Equal(t, tc.expectedCSV, string(files[1].Data))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package installconfig
 
 import (
+	"context"
 	"os"
 
 	"github.com/ghodss/yaml"
@@ -13,6 +14,7 @@ import (
 	"github.com/metalkube/kni-installer/pkg/types/defaults"
 	openstackvalidation "github.com/metalkube/kni-installer/pkg/types/openstack/validation"
 	"github.com/metalkube/kni-installer/pkg/types/validation"
+	"github.com/metalkube/kni-installer/pkg/warnings"
 )
 
 const (
@@ -40,7 +42,7 @@ func (a *InstallConfig) Dependencies() []asset.Asset {
 }
 
 // Generate generates the install-config.yaml file.
-func (a *InstallConfig) Generate(parents asset.Parents) error {
+func (a *InstallConfig) Generate(ctx context.Context, parents asset.Parents) error {
 	sshPublicKey := &sshPublicKey{}
 	baseDomain := &baseDomain{}
 	clusterName := &clusterName{}
@@ -67,6 +69,7 @@ func (a *InstallConfig) Generate(parents asset.Parents) error {
 	}
 
 	a.Config.AWS = platform.AWS
+	a.Config.Fake = platform.Fake
 	a.Config.Libvirt = platform.Libvirt
 	a.Config.None = platform.None
 	a.Config.OpenStack = platform.OpenStack
@@ -79,6 +82,7 @@ func (a *InstallConfig) Generate(parents asset.Parents) error {
 	if err := validation.ValidateInstallConfig(a.Config, openstackvalidation.NewValidValuesFetcher()).ToAggregate(); err != nil {
 		return errors.Wrap(err, "invalid install config")
 	}
+	a.warn()
 
 	data, err := yaml.Marshal(a.Config)
 	if err != nil {
@@ -114,35 +118,52 @@ func (a *InstallConfig) Load(f asset.FileFetcher) (found bool, err error) {
 		return false, err
 	}
 
+	if err := a.parse(file.Data, openstackvalidation.NewValidValuesFetcher()); err != nil {
+		return false, err
+	}
+
+	data, err := yaml.Marshal(a.Config)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to Marshal InstallConfig")
+	}
+	a.File = &asset.File{
+		Filename: installConfigFilename,
+		Data:     data,
+	}
+
+	return true, nil
+}
+
+// parse unmarshals, upconverts, defaults and validates the user's install
+// config, checking OpenStack values against fetcher.
+func (a *InstallConfig) parse(data []byte, fetcher openstackvalidation.ValidValuesFetcher) error {
 	config := &types.InstallConfig{}
-	if err := yaml.Unmarshal(file.Data, config); err != nil {
-		return false, errors.Wrap(err, "failed to unmarshal")
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return errors.Wrap(err, "failed to unmarshal")
 	}
 	a.Config = config
 
 	// Upconvert any deprecated fields
 	if err := a.convert(); err != nil {
-		return false, errors.Wrap(err, "failed to upconvert install config")
+		return errors.Wrap(err, "failed to upconvert install config")
 	}
 
 	if err := a.setDefaults(); err != nil {
-		return false, errors.Wrap(err, "failed to set defaults for install config")
+		return errors.Wrap(err, "failed to set defaults for install config")
 	}
 
-	if err := validation.ValidateInstallConfig(a.Config, openstackvalidation.NewValidValuesFetcher()).ToAggregate(); err != nil {
-		return false, errors.Wrapf(err, "invalid %q file", installConfigFilename)
+	if err := validation.ValidateInstallConfig(a.Config, fetcher).ToAggregate(); err != nil {
+		return errors.Wrapf(err, "invalid %q file", installConfigFilename)
 	}
+	a.warn()
+	return nil
+}
 
-	data, err := yaml.Marshal(a.Config)
-	if err != nil {
-		return false, errors.Wrap(err, "failed to Marshal InstallConfig")
+// warn logs the risky settings of the valid install config.
+func (a *InstallConfig) warn() {
+	for _, w := range validation.InstallConfigWarnings(a.Config) {
+		warnings.Warnf(w.Category, "%s", w.Message)
 	}
-	a.File = &asset.File{
-		Filename: installConfigFilename,
-		Data:     data,
-	}
-
-	return true, nil
 }
 
 func (a *InstallConfig) setDefaults() error {
@@ -1,6 +1,7 @@
 package installconfig
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
@@ -53,7 +54,7 @@ func TestInstallConfigGenerate_FillsInDefaults(t *testing.T) {
 		pullSecret,
 		platform,
 	)
-	if err := installConfig.Generate(parents); err != nil {
+	if err := installConfig.Generate(context.Background(), parents); err != nil {
 		t.Errorf("unexpected error generating install config: %v", err)
 	}
 	expected := &types.InstallConfig{
@@ -76,19 +77,22 @@ func TestInstallConfigGenerate_FillsInDefaults(t *testing.T) {
 			},
 		},
 		ControlPlane: &types.MachinePool{
-			Name:     "master",
-			Replicas: pointer.Int64Ptr(3),
+			Name:         "master",
+			Replicas:     pointer.Int64Ptr(3),
+			Architecture: types.ArchitectureAMD64,
 		},
 		Compute: []types.MachinePool{
 			{
-				Name:     "worker",
-				Replicas: pointer.Int64Ptr(3),
+				Name:         "worker",
+				Replicas:     pointer.Int64Ptr(3),
+				Architecture: types.ArchitectureAMD64,
 			},
 		},
 		Platform: types.Platform{
 			None: &none.Platform{},
 		},
 		PullSecret: `{"auths":{"example.com":{"auth":"authorization value"}}}`,
+		Profile:    types.ProfileStandard,
 	}
 	assert.Equal(t, expected, installConfig.Config, "unexpected config generated")
 }
@@ -135,13 +139,15 @@ pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
 					},
 				},
 				ControlPlane: &types.MachinePool{
-					Name:     "master",
-					Replicas: pointer.Int64Ptr(3),
+					Name:         "master",
+					Replicas:     pointer.Int64Ptr(3),
+					Architecture: types.ArchitectureAMD64,
 				},
 				Compute: []types.MachinePool{
 					{
-						Name:     "worker",
-						Replicas: pointer.Int64Ptr(3),
+						Name:         "worker",
+						Replicas:     pointer.Int64Ptr(3),
+						Architecture: types.ArchitectureAMD64,
 					},
 				},
 				Platform: types.Platform{
@@ -150,6 +156,7 @@ pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
 					},
 				},
 				PullSecret: `{"auths":{"example.com":{"auth":"authorization value"}}}`,
+				Profile:    types.ProfileStandard,
 			},
 		},
 		{
@@ -214,13 +221,15 @@ network:
 					},
 				},
 				ControlPlane: &types.MachinePool{
-					Name:     "master",
-					Replicas: pointer.Int64Ptr(3),
+					Name:         "master",
+					Replicas:     pointer.Int64Ptr(3),
+					Architecture: types.ArchitectureAMD64,
 				},
 				Compute: []types.MachinePool{
 					{
-						Name:     "worker",
-						Replicas: pointer.Int64Ptr(3),
+						Name:         "worker",
+						Replicas:     pointer.Int64Ptr(3),
+						Architecture: types.ArchitectureAMD64,
 					},
 				},
 				Platform: types.Platform{
@@ -229,6 +238,7 @@ network:
 					},
 				},
 				PullSecret: `{"auths":{"example.com":{"auth":"authorization value"}}}`,
+				Profile:    types.ProfileStandard,
 			},
 		},
 	}
@@ -0,0 +1,52 @@
+// +build libvirt
+
+package libvirt
+
+import (
+	"strings"
+
+	libvirt "github.com/libvirt/libvirt-go"
+	"github.com/pkg/errors"
+)
+
+// CheckInfraIDCollision returns an error if a libvirt domain or network
+// reachable at uri is named after infraID, since destroying either cluster
+// would delete the other's resources.
+func CheckInfraIDCollision(uri, infraID string) error {
+	conn, err := libvirt.NewConnect(uri)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Libvirt daemon")
+	}
+	defer conn.Close()
+
+	domains, err := conn.ListAllDomains(0)
+	if err != nil {
+		return errors.Wrap(err, "list domains")
+	}
+	for _, domain := range domains {
+		defer domain.Free()
+		name, err := domain.GetName()
+		if err != nil {
+			return errors.Wrap(err, "get domain name")
+		}
+		if strings.HasPrefix(name, infraID) {
+			return errors.Errorf("libvirt domain %q already belongs to a cluster with this infra ID", name)
+		}
+	}
+
+	networks, err := conn.ListAllNetworks(0)
+	if err != nil {
+		return errors.Wrap(err, "list networks")
+	}
+	for _, network := range networks {
+		defer network.Free()
+		name, err := network.GetName()
+		if err != nil {
+			return errors.Wrap(err, "get network name")
+		}
+		if strings.HasPrefix(name, infraID) {
+			return errors.Errorf("libvirt network %q already belongs to a cluster with this infra ID", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+// +build !libvirt
+
+package libvirt
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// CheckInfraIDCollision cannot inspect libvirt without the libvirt build tag,
+// so it only warns that the check was skipped.
+func CheckInfraIDCollision(uri, infraID string) error {
+	logrus.Warnf("Not checking %s for resources already using infra ID %q: kni-install was built without the libvirt tag", uri, infraID)
+	return nil
+}
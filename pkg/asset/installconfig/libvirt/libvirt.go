@@ -4,6 +4,7 @@ package libvirt
 import (
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	libvirtdefaults "github.com/metalkube/kni-installer/pkg/types/libvirt/defaults"
 	"github.com/metalkube/kni-installer/pkg/validate"
@@ -12,7 +13,7 @@ import (
 // Platform collects libvirt-specific configuration.
 func Platform() (*libvirt.Platform, error) {
 	var uri string
-	err := survey.Ask([]*survey.Question{
+	err := answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Input{
 				Message: "Libvirt Connection URI",
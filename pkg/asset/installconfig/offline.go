@@ -0,0 +1,43 @@
+package installconfig
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
+	"github.com/metalkube/kni-installer/pkg/types/none"
+)
+
+// validateOffline checks, in offline mode, that nothing the install needs
+// would have to be fetched over the network, so disconnected installs fail
+// up front rather than part way through.
+func validateOffline(ctx context.Context, config *types.InstallConfig) error {
+	if !offline.FromContext(ctx) {
+		return nil
+	}
+
+	var problems []string
+	switch platform := config.Platform.Name(); platform {
+	case baremetal.Name, fake.Name, libvirt.Name, none.Name:
+	default:
+		problems = append(problems, "the "+platform+" platform needs network access to its API")
+	}
+	if image := os.Getenv("OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE"); !strings.HasPrefix(image, "file://") && config.Platform.None == nil && config.Platform.Fake == nil {
+		problems = append(problems, "OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE must be set to a file:// URI of a local RHCOS image")
+	}
+	if os.Getenv("OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE") == "" {
+		problems = append(problems, "OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE must be set to the release image in a local mirror")
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("cannot install offline: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package installconfig
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/aws"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestValidateOffline(t *testing.T) {
+	for _, env := range []string{"OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE", "OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE"} {
+		defer os.Setenv(env, os.Getenv(env))
+		os.Unsetenv(env)
+	}
+
+	baremetalConfig := &types.InstallConfig{Platform: types.Platform{BareMetal: &baremetal.Platform{}}}
+	awsConfig := &types.InstallConfig{Platform: types.Platform{AWS: &aws.Platform{}}}
+
+	assert.NoError(t, validateOffline(context.Background(), awsConfig), "online installs are not checked")
+
+	ctx := offline.NewContext(context.Background())
+	assert.EqualError(t, validateOffline(ctx, baremetalConfig), "cannot install offline: OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE must be set to a file:// URI of a local RHCOS image; OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE must be set to the release image in a local mirror")
+
+	os.Setenv("OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE", "file:///var/lib/images/rhcos.qcow2")
+	os.Setenv("OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE", "mirror.example.com:5000/ocp/release:4.1")
+	assert.NoError(t, validateOffline(ctx, baremetalConfig))
+	assert.EqualError(t, validateOffline(ctx, awsConfig), "cannot install offline: the aws platform needs network access to its API")
+}
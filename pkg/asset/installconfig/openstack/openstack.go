@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
 	openstackvalidation "github.com/metalkube/kni-installer/pkg/types/openstack/validation"
 )
@@ -23,7 +24,7 @@ func Platform() (*openstack.Platform, error) {
 	// Sort cloudNames so we can use sort.SearchStrings
 	sort.Strings(cloudNames)
 	var cloud string
-	err = survey.Ask([]*survey.Question{
+	err = answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Select{
 				Message: "Cloud",
@@ -50,7 +51,7 @@ func Platform() (*openstack.Platform, error) {
 	}
 	sort.Strings(regionNames)
 	var region string
-	err = survey.Ask([]*survey.Question{
+	err = answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Select{
 				Message: "Region",
@@ -78,7 +79,7 @@ func Platform() (*openstack.Platform, error) {
 	}
 	sort.Strings(networkNames)
 	var extNet string
-	err = survey.Ask([]*survey.Question{
+	err = answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Select{
 				Message: "ExternalNetwork",
@@ -105,7 +106,7 @@ func Platform() (*openstack.Platform, error) {
 	}
 	sort.Strings(flavorNames)
 	var flavor string
-	err = survey.Ask([]*survey.Question{
+	err = answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Select{
 				Message: "FlavorName",
@@ -1,12 +1,14 @@
 package installconfig
 
 import (
+	"context"
 	"fmt"
 	"sort"
 
 	"github.com/pkg/errors"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/asset"
 	awsconfig "github.com/metalkube/kni-installer/pkg/asset/installconfig/aws"
 	baremetalconfig "github.com/metalkube/kni-installer/pkg/asset/installconfig/baremetal"
@@ -15,6 +17,7 @@ import (
 	"github.com/metalkube/kni-installer/pkg/types"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	"github.com/metalkube/kni-installer/pkg/types/none"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
@@ -32,7 +35,7 @@ func (a *platform) Dependencies() []asset.Asset {
 }
 
 // Generate queries for input from the user.
-func (a *platform) Generate(asset.Parents) error {
+func (a *platform) Generate(ctx context.Context, _ asset.Parents) error {
 	platform, err := a.queryUserForPlatform()
 	if err != nil {
 		return err
@@ -40,10 +43,12 @@ func (a *platform) Generate(asset.Parents) error {
 
 	switch platform {
 	case aws.Name:
-		a.AWS, err = awsconfig.Platform()
+		a.AWS, err = awsconfig.Platform(ctx)
 		if err != nil {
 			return err
 		}
+	case fake.Name:
+		a.Fake = &fake.Platform{}
 	case libvirt.Name:
 		a.Libvirt, err = libvirtconfig.Platform()
 		if err != nil {
@@ -74,7 +79,7 @@ func (a *platform) Name() string {
 }
 
 func (a *platform) queryUserForPlatform() (platform string, err error) {
-	err = survey.Ask([]*survey.Question{
+	err = answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Select{
 				Message: "Platform",
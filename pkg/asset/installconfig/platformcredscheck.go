@@ -1,6 +1,7 @@
 package installconfig
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gophercloud/utils/openstack/clientconfig"
@@ -8,6 +9,7 @@ import (
 	awsconfig "github.com/metalkube/kni-installer/pkg/asset/installconfig/aws"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	"github.com/metalkube/kni-installer/pkg/types/none"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
@@ -29,15 +31,21 @@ func (a *PlatformCredsCheck) Dependencies() []asset.Asset {
 }
 
 // Generate queries for input from the user.
-func (a *PlatformCredsCheck) Generate(dependencies asset.Parents) error {
+func (a *PlatformCredsCheck) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ic := &InstallConfig{}
 	dependencies.Get(ic)
 
+	// The install config may have been loaded from disk, so it is checked
+	// here rather than when it is parsed.
+	if err := validateOffline(ctx, ic.Config); err != nil {
+		return err
+	}
+
 	var err error
 	platform := ic.Config.Platform.Name()
 	switch platform {
 	case aws.Name:
-		ssn, err := awsconfig.GetSession()
+		ssn, err := awsconfig.GetSession(ctx)
 		if err != nil {
 			return errors.Wrap(err, "creating AWS session")
 		}
@@ -45,6 +53,7 @@ func (a *PlatformCredsCheck) Generate(dependencies asset.Parents) error {
 		if err != nil {
 			return errors.Wrap(err, "validate AWS credentials")
 		}
+	case fake.Name:
 	case libvirt.Name:
 	case none.Name:
 	case baremetal.Name:
@@ -1,9 +1,13 @@
 package installconfig
 
 import (
+	"context"
+
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/credentials"
 	"github.com/metalkube/kni-installer/pkg/validate"
 )
 
@@ -19,15 +23,19 @@ func (a *pullSecret) Dependencies() []asset.Asset {
 }
 
 // Generate queries for the pull secret from the user.
-func (a *pullSecret) Generate(asset.Parents) error {
-	return survey.Ask([]*survey.Question{
+func (a *pullSecret) Generate(context.Context, asset.Parents) error {
+	return answers.Ask([]*survey.Question{
 		{
 			Prompt: &survey.Password{
 				Message: "Pull Secret",
-				Help:    "The container registry pull secret for this cluster, as a single line of JSON (e.g. {\"auths\": {...}}).\n\nYou can get this secret from https://cloud.openshift.com/clusters/install#pull-secret",
+				Help:    "The container registry pull secret for this cluster, as a single line of JSON (e.g. {\"auths\": {...}}), or a reference to read it from (e.g. env:PULL_SECRET or file:/path/to/pull-secret.json).\n\nYou can get this secret from https://cloud.openshift.com/clusters/install#pull-secret",
 			},
 			Validate: survey.ComposeValidators(survey.Required, func(ans interface{}) error {
-				return validate.ImagePullSecret(ans.(string))
+				secret, err := credentials.Resolve(ans.(string))
+				if err != nil {
+					return err
+				}
+				return validate.ImagePullSecret(secret)
 			}),
 		},
 	}, &a.PullSecret)
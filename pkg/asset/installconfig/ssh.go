@@ -1,8 +1,8 @@
 package installconfig
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,7 +10,9 @@ import (
 	"github.com/pkg/errors"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	"github.com/metalkube/kni-installer/pkg/answers"
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 	"github.com/metalkube/kni-installer/pkg/validate"
 )
 
@@ -30,7 +32,7 @@ func (a *sshPublicKey) Dependencies() []asset.Asset {
 }
 
 func readSSHKey(path string) (string, error) {
-	keyAsBytes, err := ioutil.ReadFile(path)
+	keyAsBytes, err := fileaudit.ReadFile(path, "SSH public key")
 	if err != nil {
 		return "", err
 	}
@@ -46,7 +48,7 @@ func readSSHKey(path string) (string, error) {
 }
 
 // Generate generates the SSH public key asset.
-func (a *sshPublicKey) Generate(asset.Parents) error {
+func (a *sshPublicKey) Generate(context.Context, asset.Parents) error {
 	pubKeys := map[string]string{
 		noSSHKey: "",
 	}
@@ -79,7 +81,7 @@ func (a *sshPublicKey) Generate(asset.Parents) error {
 	sort.Strings(paths)
 
 	var path string
-	if err := survey.AskOne(&survey.Select{
+	if err := answers.AskOne(&survey.Select{
 		Message: "SSH Public Key",
 		Help:    "The SSH public key used to access all nodes within the cluster. This is optional.",
 		Options: paths,
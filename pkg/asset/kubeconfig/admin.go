@@ -1,8 +1,11 @@
 package kubeconfig
 
 import (
+	"context"
 	"path/filepath"
 
+	clientcmd "k8s.io/client-go/tools/clientcmd/api/v1"
+
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 	"github.com/metalkube/kni-installer/pkg/asset/tls"
@@ -29,18 +32,24 @@ func (k *AdminClient) Dependencies() []asset.Asset {
 }
 
 // Generate generates the kubeconfig.
-func (k *AdminClient) Generate(parents asset.Parents) error {
+func (k *AdminClient) Generate(ctx context.Context, parents asset.Parents) error {
 	ca := &tls.KubeAPIServerCompleteCABundle{}
 	clientCertKey := &tls.AdminKubeConfigClientCertKey{}
 	installConfig := &installconfig.InstallConfig{}
 	parents.Get(ca, clientCertKey, installConfig)
 
+	var exec *clientcmd.ExecConfig
+	if installConfig.Config.AdminKubeconfig != nil {
+		exec = installConfig.Config.AdminKubeconfig.Exec
+	}
+
 	return k.kubeconfig.generate(
 		ca,
 		clientCertKey,
 		installConfig.Config,
 		"admin",
 		kubeconfigAdminPath,
+		exec,
 	)
 }
 
@@ -53,3 +62,50 @@ func (k *AdminClient) Name() string {
 func (k *AdminClient) Load(f asset.FileFetcher) (found bool, err error) {
 	return k.load(f, kubeconfigAdminPath)
 }
+
+// AdminBootstrapClient is the admin kubeconfig for the bootstrap machine.
+// It always authenticates with the admin client certificate, as the
+// bootstrap machine has neither the credential plugin of
+// adminKubeconfig.exec nor the credentials it would use.
+type AdminBootstrapClient struct {
+	kubeconfig
+}
+
+var _ asset.WritableAsset = (*AdminBootstrapClient)(nil)
+
+// Dependencies returns the dependency of the kubeconfig.
+func (k *AdminBootstrapClient) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&tls.AdminKubeConfigClientCertKey{},
+		&tls.KubeAPIServerCompleteCABundle{},
+		&installconfig.InstallConfig{},
+	}
+}
+
+// Generate generates the kubeconfig.
+func (k *AdminBootstrapClient) Generate(ctx context.Context, parents asset.Parents) error {
+	ca := &tls.KubeAPIServerCompleteCABundle{}
+	clientCertKey := &tls.AdminKubeConfigClientCertKey{}
+	installConfig := &installconfig.InstallConfig{}
+	parents.Get(ca, clientCertKey, installConfig)
+
+	return k.kubeconfig.generate(
+		ca,
+		clientCertKey,
+		installConfig.Config,
+		"admin",
+		kubeconfigAdminPath,
+		nil,
+	)
+}
+
+// Name returns the human-friendly name of the asset.
+func (k *AdminBootstrapClient) Name() string {
+	return "Kubeconfig Admin Client for the Bootstrap Machine"
+}
+
+// Load is a no-op because the kubeconfig is only written to the bootstrap
+// Ignition config, and auth/kubeconfig on disk is AdminClient's.
+func (k *AdminBootstrapClient) Load(asset.FileFetcher) (bool, error) {
+	return false, nil
+}
@@ -18,14 +18,25 @@ type kubeconfig struct {
 	File   *asset.File
 }
 
-// generate generates the kubeconfig.
+// generate generates the kubeconfig.  The user authenticates with the
+// client cert/key, unless exec is set, in which case the kubeconfig runs
+// that credential plugin instead and the client cert is left out.
 func (k *kubeconfig) generate(
 	ca tls.CertInterface,
 	clientCertKey tls.CertKeyInterface,
 	installConfig *types.InstallConfig,
 	userName string,
 	kubeconfigPath string,
+	exec *clientcmd.ExecConfig,
 ) error {
+	authInfo := clientcmd.AuthInfo{
+		ClientCertificateData: clientCertKey.Cert(),
+		ClientKeyData:         clientCertKey.Key(),
+	}
+	if exec != nil {
+		authInfo = clientcmd.AuthInfo{Exec: exec}
+	}
+
 	k.Config = &clientcmd.Config{
 		Clusters: []clientcmd.NamedCluster{
 			{
@@ -38,11 +49,8 @@ func (k *kubeconfig) generate(
 		},
 		AuthInfos: []clientcmd.NamedAuthInfo{
 			{
-				Name: userName,
-				AuthInfo: clientcmd.AuthInfo{
-					ClientCertificateData: clientCertKey.Cert(),
-					ClientKeyData:         clientCertKey.Key(),
-				},
+				Name:     userName,
+				AuthInfo: authInfo,
 			},
 		},
 		Contexts: []clientcmd.NamedContext{
@@ -1,12 +1,16 @@
 package kubeconfig
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmd "k8s.io/client-go/tools/clientcmd/api/v1"
 
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 	"github.com/metalkube/kni-installer/pkg/asset/tls"
 	"github.com/metalkube/kni-installer/pkg/types"
 )
@@ -52,6 +56,7 @@ func TestKubeconfigGenerate(t *testing.T) {
 		userName     string
 		filename     string
 		clientCert   tls.CertKeyInterface
+		exec         *clientcmd.ExecConfig
 		expectedData []byte
 	}{
 		{
@@ -76,6 +81,39 @@ users:
   user:
     client-certificate-data: VEhJUyBJUyBBRE1JTiBDRVJUIERBVEE=
     client-key-data: VEhJUyBJUyBBRE1JTiBLRVkgREFUQQ==
+`),
+		},
+		{
+			name:       "admin kubeconfig with exec plugin",
+			userName:   "admin",
+			filename:   "auth/kubeconfig",
+			clientCert: adminCert,
+			exec: &clientcmd.ExecConfig{
+				Command:    "kubectl-oidc_login",
+				Args:       []string{"get-token"},
+				APIVersion: "client.authentication.k8s.io/v1beta1",
+			},
+			expectedData: []byte(`clusters:
+- cluster:
+    certificate-authority-data: VEhJUyBJUyBST09UIENBIENFUlQgREFUQQ==
+    server: https://api.test-cluster-name.test.example.com:6443
+  name: test-cluster-name
+contexts:
+- context:
+    cluster: test-cluster-name
+    user: admin
+  name: admin
+current-context: admin
+preferences: {}
+users:
+- name: admin
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      args:
+      - get-token
+      command: kubectl-oidc_login
+      env: null
 `),
 		},
 		{
@@ -107,7 +145,7 @@ users:
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			kc := &kubeconfig{}
-			err := kc.generate(rootCA, tt.clientCert, installConfig, tt.userName, tt.filename)
+			err := kc.generate(rootCA, tt.clientCert, installConfig, tt.userName, tt.filename, tt.exec)
 			assert.NoError(t, err, "unexpected error generating config")
 			actualFiles := kc.Files()
 			assert.Equal(t, 1, len(actualFiles), "unexpected number of files generated")
@@ -117,3 +155,30 @@ users:
 	}
 
 }
+
+func TestAdminClientExec(t *testing.T) {
+	exec := &clientcmd.ExecConfig{APIVersion: "client.authentication.k8s.io/v1beta1", Command: "kubectl-oidc_login"}
+	parents := asset.Parents{}
+	parents.Add(
+		&tls.KubeAPIServerCompleteCABundle{CertBundle: tls.CertBundle{BundleRaw: []byte("CA")}},
+		&tls.AdminKubeConfigClientCertKey{SignedCertKey: tls.SignedCertKey{CertKey: tls.CertKey{CertRaw: []byte("CERT"), KeyRaw: []byte("KEY")}}},
+		&installconfig.InstallConfig{Config: &types.InstallConfig{
+			ObjectMeta:      metav1.ObjectMeta{Name: "test-cluster-name"},
+			BaseDomain:      "test.example.com",
+			AdminKubeconfig: &types.AdminKubeconfig{Exec: exec},
+		}},
+	)
+
+	admin := &AdminClient{}
+	if assert.NoError(t, admin.Generate(context.Background(), parents)) {
+		assert.Equal(t, exec, admin.Config.AuthInfos[0].AuthInfo.Exec)
+		assert.Empty(t, admin.Config.AuthInfos[0].AuthInfo.ClientCertificateData)
+	}
+
+	bootstrap := &AdminBootstrapClient{}
+	if assert.NoError(t, bootstrap.Generate(context.Background(), parents)) {
+		assert.Nil(t, bootstrap.Config.AuthInfos[0].AuthInfo.Exec)
+		assert.Equal(t, []byte("CERT"), bootstrap.Config.AuthInfos[0].AuthInfo.ClientCertificateData)
+		assert.Equal(t, admin.File.Filename, bootstrap.File.Filename)
+	}
+}
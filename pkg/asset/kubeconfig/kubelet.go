@@ -1,6 +1,7 @@
 package kubeconfig
 
 import (
+	"context"
 	"path/filepath"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
@@ -31,7 +32,7 @@ func (k *Kubelet) Dependencies() []asset.Asset {
 }
 
 // Generate generates the kubeconfig.
-func (k *Kubelet) Generate(parents asset.Parents) error {
+func (k *Kubelet) Generate(ctx context.Context, parents asset.Parents) error {
 	kubeCA := &tls.KubeCA{}
 	kubeletCertKey := &tls.KubeletCertKey{}
 	installConfig := &installconfig.InstallConfig{}
@@ -43,6 +44,7 @@ func (k *Kubelet) Generate(parents asset.Parents) error {
 		installConfig.Config,
 		"kubelet",
 		kubeconfigKubeletPath,
+		nil,
 	)
 }
 
@@ -73,7 +75,7 @@ func (k *KubeletClient) Dependencies() []asset.Asset {
 }
 
 // Generate generates the kubeconfig.
-func (k *KubeletClient) Generate(parents asset.Parents) error {
+func (k *KubeletClient) Generate(ctx context.Context, parents asset.Parents) error {
 	ca := &tls.KubeAPIServerCompleteCABundle{}
 	clientcertkey := &tls.KubeletClientCertKey{}
 	installConfig := &installconfig.InstallConfig{}
@@ -85,6 +87,7 @@ func (k *KubeletClient) Generate(parents asset.Parents) error {
 		installConfig.Config,
 		"kubelet",
 		kubeconfigKubeletClientPath,
+		nil,
 	)
 }
 
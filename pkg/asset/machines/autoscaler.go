@@ -0,0 +1,76 @@
+package machines
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+var clusterAutoscalerTmpl = template.Must(template.New("cluster-autoscaler").Parse(`
+apiVersion: autoscaling.openshift.io/v1
+kind: ClusterAutoscaler
+metadata:
+  name: default
+spec:
+  scaleDown:
+    enabled: true
+`))
+
+var machineAutoscalerListTmpl = template.Must(template.New("machine-autoscaler-list").Parse(`
+kind: List
+apiVersion: v1
+metadata:
+  resourceVersion: ""
+  selfLink: ""
+items:
+{{- range . }}
+- apiVersion: autoscaling.openshift.io/v1beta1
+  kind: MachineAutoscaler
+  metadata:
+    name: {{.Name}}
+    namespace: openshift-machine-api
+  spec:
+    minReplicas: {{.MinReplicas}}
+    maxReplicas: {{.MaxReplicas}}
+    scaleTargetRef:
+      apiVersion: {{.APIVersion}}
+      kind: MachineSet
+      name: {{.Name}}
+{{- end}}
+`))
+
+// machineAutoscaler is the data needed to scope a MachineAutoscaler to a
+// single MachineSet.
+type machineAutoscaler struct {
+	Name        string
+	APIVersion  string
+	MinReplicas int64
+	MaxReplicas int64
+}
+
+// spreadReplicas returns the share of total given to the idx-th of count
+// MachineSets, matching how pool replicas are spread across zones.
+func spreadReplicas(total int64, idx, count int) int64 {
+	share := total / int64(count)
+	if int64(idx) < total%int64(count) {
+		share++
+	}
+	return share
+}
+
+func clusterAutoscaler() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := clusterAutoscalerTmpl.Execute(buf, nil); err != nil {
+		return nil, errors.Wrap(err, "failed to execute cluster autoscaler template")
+	}
+	return buf.Bytes(), nil
+}
+
+func machineAutoscalerList(autoscalers []machineAutoscaler) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := machineAutoscalerListTmpl.Execute(buf, autoscalers); err != nil {
+		return nil, errors.Wrap(err, "failed to execute machine autoscaler template")
+	}
+	return buf.Bytes(), nil
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -9,8 +10,8 @@ import (
 )
 
 // AvailabilityZones retrieves a list of availability zones for the given region.
-func AvailabilityZones(region string) ([]string, error) {
-	ec2Client, err := ec2Client(region)
+func AvailabilityZones(ctx context.Context, region string) ([]string, error) {
+	ec2Client, err := ec2Client(ctx, region)
 	if err != nil {
 		return nil, err
 	}
@@ -21,8 +22,8 @@ func AvailabilityZones(region string) ([]string, error) {
 	return zones, nil
 }
 
-func ec2Client(region string) (*ec2.EC2, error) {
-	ssn, err := awsutil.GetSession()
+func ec2Client(ctx context.Context, region string) (*ec2.EC2, error) {
+	ssn, err := awsutil.GetSession(ctx)
 	if err != nil {
 		return nil, err
 	}
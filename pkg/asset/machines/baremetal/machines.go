@@ -30,9 +30,14 @@ func Machines(clusterID string, config *types.InstallConfig, pool *types.Machine
 	if pool.Replicas != nil {
 		total = *pool.Replicas
 	}
-	provider := provider(clustername, config.Networking.MachineCIDR.String(), platform, userDataSecret)
+	provider := provider(clustername, pool.MachineNetwork(config.Networking).String(), platform, userDataSecret)
+	domains := pool.Platform.BareMetal.FailureDomains
 	var machines []machineapi.Machine
 	for idx := int64(0); idx < total; idx++ {
+		var domain *baremetal.FailureDomain
+		if len(domains) > 0 {
+			domain = &domains[int(idx)%len(domains)]
+		}
 		machine := machineapi.Machine{
 			TypeMeta: metav1.TypeMeta{
 				APIVersion: "machine.openshift.io/v1beta1",
@@ -48,6 +53,9 @@ func Machines(clusterID string, config *types.InstallConfig, pool *types.Machine
 				},
 			},
 			Spec: machineapi.MachineSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: nodeLabels(pool, domain),
+				},
 				ProviderSpec: machineapi.ProviderSpec{
 					Value: &runtime.RawExtension{Object: provider},
 				},
@@ -60,6 +68,22 @@ func Machines(clusterID string, config *types.InstallConfig, pool *types.Machine
 	return machines, nil
 }
 
+// nodeLabels returns the labels for the nodes of pool, and of the failure
+// domain if it is not nil.
+func nodeLabels(pool *types.MachinePool, domain *baremetal.FailureDomain) map[string]string {
+	labels := map[string]string{}
+	if pool.Architecture != "" {
+		labels[types.ArchitectureLabel] = string(pool.Architecture)
+	}
+	if domain != nil {
+		labels[baremetal.RackLabel] = domain.Rack
+		if domain.Zone != "" {
+			labels[types.ZoneLabel] = domain.Zone
+		}
+	}
+	return labels
+}
+
 func provider(clusterName string, networkInterfaceAddress string, platform *baremetal.Platform, userDataSecret string) *libvirtprovider.LibvirtMachineProviderConfig {
 	// FIXME: baremetal
 	return &libvirtprovider.LibvirtMachineProviderConfig{}
@@ -32,9 +32,33 @@ func MachineSets(clusterID string, config *types.InstallConfig, pool *types.Mach
 		total = *pool.Replicas
 	}
 
-	provider := provider(clustername, config.Networking.MachineCIDR.String(), platform, userDataSecret)
-	name := fmt.Sprintf("%s-%s-%d", clustername, pool.Name, 0)
-	mset := &machineapi.MachineSet{
+	provider := provider(clustername, pool.MachineNetwork(config.Networking).String(), platform, userDataSecret)
+
+	var domains []baremetal.FailureDomain
+	if pool.Platform.BareMetal != nil {
+		domains = pool.Platform.BareMetal.FailureDomains
+	}
+	if len(domains) == 0 {
+		name := fmt.Sprintf("%s-%s-%d", clustername, pool.Name, 0)
+		return []*machineapi.MachineSet{machineSet(name, clustername, role, total, provider, nodeLabels(pool, nil))}, nil
+	}
+
+	// One MachineSet per failure domain, so each keeps its share of the
+	// replicas as machines come and go.
+	var machinesets []*machineapi.MachineSet
+	for idx, domain := range domains {
+		replicas := total / int64(len(domains))
+		if int64(idx) < total%int64(len(domains)) {
+			replicas++
+		}
+		name := fmt.Sprintf("%s-%s-%s", clustername, pool.Name, domain.Rack)
+		machinesets = append(machinesets, machineSet(name, clustername, role, replicas, provider, nodeLabels(pool, &domains[idx])))
+	}
+	return machinesets, nil
+}
+
+func machineSet(name, clustername, role string, replicas int64, provider runtime.Object, nodeLabels map[string]string) *machineapi.MachineSet {
+	return &machineapi.MachineSet{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "machine.openshift.io/v1beta1",
 			Kind:       "MachineSet",
@@ -49,7 +73,7 @@ func MachineSets(clusterID string, config *types.InstallConfig, pool *types.Mach
 			},
 		},
 		Spec: machineapi.MachineSetSpec{
-			Replicas: pointer.Int32Ptr(int32(total)),
+			Replicas: pointer.Int32Ptr(int32(replicas)),
 			Selector: metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"sigs.k8s.io/cluster-api-machineset": name,
@@ -66,6 +90,9 @@ func MachineSets(clusterID string, config *types.InstallConfig, pool *types.Mach
 					},
 				},
 				Spec: machineapi.MachineSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: nodeLabels,
+					},
 					ProviderSpec: machineapi.ProviderSpec{
 						Value: &runtime.RawExtension{Object: provider},
 					},
@@ -74,6 +101,4 @@ func MachineSets(clusterID string, config *types.InstallConfig, pool *types.Mach
 			},
 		},
 	}
-
-	return []*machineapi.MachineSet{mset}, nil
 }
@@ -0,0 +1,46 @@
+// Package fake generates Machine objects for the fake platform.
+package fake
+
+import (
+	"fmt"
+
+	machineapi "github.com/openshift/cluster-api/pkg/apis/machine/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
+)
+
+// Machines returns a list of machines for a machinepool.  The machines
+// have no provider spec, because there is nothing to provision them on.
+func Machines(clusterID string, config *types.InstallConfig, pool *types.MachinePool, role string) ([]machineapi.Machine, error) {
+	if configPlatform := config.Platform.Name(); configPlatform != fake.Name {
+		return nil, fmt.Errorf("non-fake configuration: %q", configPlatform)
+	}
+
+	total := int64(1)
+	if pool.Replicas != nil {
+		total = *pool.Replicas
+	}
+	var machines []machineapi.Machine
+	for idx := int64(0); idx < total; idx++ {
+		machine := machineapi.Machine{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "machine.openshift.io/v1beta1",
+				Kind:       "Machine",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-machine-api",
+				Name:      fmt.Sprintf("%s-%s-%d", clusterID, pool.Name, idx),
+				Labels: map[string]string{
+					"machine.openshift.io/cluster-api-cluster":      clusterID,
+					"machine.openshift.io/cluster-api-machine-role": role,
+					"machine.openshift.io/cluster-api-machine-type": role,
+				},
+			},
+		}
+		machines = append(machines, machine)
+	}
+
+	return machines, nil
+}
@@ -1,6 +1,7 @@
 package machines
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,12 +12,14 @@ import (
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 	"github.com/metalkube/kni-installer/pkg/asset/machines/aws"
 	"github.com/metalkube/kni-installer/pkg/asset/machines/baremetal"
+	"github.com/metalkube/kni-installer/pkg/asset/machines/fake"
 	"github.com/metalkube/kni-installer/pkg/asset/machines/libvirt"
 	"github.com/metalkube/kni-installer/pkg/asset/machines/openstack"
 	"github.com/metalkube/kni-installer/pkg/asset/rhcos"
 	awstypes "github.com/metalkube/kni-installer/pkg/types/aws"
 	awsdefaults "github.com/metalkube/kni-installer/pkg/types/aws/defaults"
 	baremetaltypes "github.com/metalkube/kni-installer/pkg/types/baremetal"
+	faketypes "github.com/metalkube/kni-installer/pkg/types/fake"
 	libvirttypes "github.com/metalkube/kni-installer/pkg/types/libvirt"
 	nonetypes "github.com/metalkube/kni-installer/pkg/types/none"
 	openstacktypes "github.com/metalkube/kni-installer/pkg/types/openstack"
@@ -76,7 +79,7 @@ func awsDefaultMasterMachineType(installconfig *installconfig.InstallConfig) str
 }
 
 // Generate generates the Master asset.
-func (m *Master) Generate(dependencies asset.Parents) error {
+func (m *Master) Generate(ctx context.Context, dependencies asset.Parents) error {
 	clusterID := &installconfig.ClusterID{}
 	installconfig := &installconfig.InstallConfig{}
 	rhcosImage := new(rhcos.Image)
@@ -94,7 +97,7 @@ func (m *Master) Generate(dependencies asset.Parents) error {
 		mpool.Set(ic.Platform.AWS.DefaultMachinePlatform)
 		mpool.Set(pool.Platform.AWS)
 		if len(mpool.Zones) == 0 {
-			azs, err := aws.AvailabilityZones(ic.Platform.AWS.Region)
+			azs, err := aws.AvailabilityZones(ctx, ic.Platform.AWS.Region)
 			if err != nil {
 				return errors.Wrap(err, "failed to fetch availability zones")
 			}
@@ -115,6 +118,11 @@ func (m *Master) Generate(dependencies asset.Parents) error {
 		if err != nil {
 			return errors.Wrap(err, "failed to create master machine objects")
 		}
+	case faketypes.Name:
+		machines, err = fake.Machines(clusterID.InfraID, ic, pool, "master")
+		if err != nil {
+			return errors.Wrap(err, "failed to create master machine objects")
+		}
 	case nonetypes.Name:
 		return nil
 	case openstacktypes.Name:
@@ -150,6 +158,7 @@ func (m *Master) Generate(dependencies asset.Parents) error {
 	m.FileList = []*asset.File{{
 		Filename: filepath.Join(directory, MasterUserDataFileName),
 		Data:     data,
+		Mode:     asset.PrivateMode,
 	}}
 
 	count := len(machines)
@@ -0,0 +1,55 @@
+package machines
+
+import (
+	"strconv"
+
+	machineapi "github.com/openshift/cluster-api/pkg/apis/machine/v1beta1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterapi "sigs.k8s.io/cluster-api/pkg/apis/cluster/v1alpha1"
+
+	"github.com/metalkube/kni-installer/pkg/rollout"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// rolloutFirstWave scales the MachineSets of a pool with a rollout policy
+// down to the pool's first wave, and annotates them with their replicas as
+// their targets, which the installer scales them up to after the install.
+func rolloutFirstWave(sets []runtime.Object, pool *types.MachinePool) error {
+	replicas := make([]*int32, len(sets))
+	targets := make([]int64, len(sets))
+	for i, set := range sets {
+		switch s := set.(type) {
+		case *machineapi.MachineSet:
+			replicas[i] = s.Spec.Replicas
+		case *clusterapi.MachineSet:
+			replicas[i] = s.Spec.Replicas
+		default:
+			return errors.Errorf("unexpected MachineSet type %T", set)
+		}
+		if replicas[i] != nil {
+			targets[i] = int64(*replicas[i])
+		}
+	}
+
+	first := rollout.NextWave(make([]int64, len(sets)), targets, pool.Rollout.WaveSize)
+	for i, set := range sets {
+		accessor, err := meta.Accessor(set)
+		if err != nil {
+			return err
+		}
+		annotations := accessor.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[rollout.PoolAnnotation] = pool.Name
+		annotations[rollout.WaveSizeAnnotation] = strconv.FormatInt(pool.Rollout.WaveSize, 10)
+		annotations[rollout.TargetReplicasAnnotation] = strconv.FormatInt(targets[i], 10)
+		accessor.SetAnnotations(annotations)
+		if replicas[i] != nil {
+			*replicas[i] = int32(first[i])
+		}
+	}
+	return nil
+}
@@ -1,12 +1,13 @@
 package machines
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"text/template"
 
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -18,9 +19,11 @@ import (
 	"github.com/metalkube/kni-installer/pkg/asset/machines/libvirt"
 	"github.com/metalkube/kni-installer/pkg/asset/machines/openstack"
 	"github.com/metalkube/kni-installer/pkg/asset/rhcos"
+	"github.com/metalkube/kni-installer/pkg/types"
 	awstypes "github.com/metalkube/kni-installer/pkg/types/aws"
 	awsdefaults "github.com/metalkube/kni-installer/pkg/types/aws/defaults"
 	baremetaltypes "github.com/metalkube/kni-installer/pkg/types/baremetal"
+	faketypes "github.com/metalkube/kni-installer/pkg/types/fake"
 	libvirttypes "github.com/metalkube/kni-installer/pkg/types/libvirt"
 	nonetypes "github.com/metalkube/kni-installer/pkg/types/none"
 	openstacktypes "github.com/metalkube/kni-installer/pkg/types/openstack"
@@ -53,6 +56,11 @@ func defaultBareMetalMachinePoolPlatform() baremetaltypes.MachinePool {
 type Worker struct {
 	MachineSetRaw     []byte
 	UserDataSecretRaw []byte
+
+	// ClusterAutoscalerRaw and MachineAutoscalerRaw are only set when at
+	// least one compute pool has autoscaling configured.
+	ClusterAutoscalerRaw []byte
+	MachineAutoscalerRaw []byte
 }
 
 var _ asset.Asset = (*Worker)(nil)
@@ -74,6 +82,7 @@ func (w *Worker) Dependencies() []asset.Asset {
 		&installconfig.InstallConfig{},
 		new(rhcos.Image),
 		&machine.Worker{},
+		&machine.PoolWorkers{},
 	}
 }
 
@@ -84,24 +93,40 @@ func awsDefaultWorkerMachineType(installconfig *installconfig.InstallConfig) str
 }
 
 // Generate generates the Worker asset.
-func (w *Worker) Generate(dependencies asset.Parents) error {
+func (w *Worker) Generate(ctx context.Context, dependencies asset.Parents) error {
 	clusterID := &installconfig.ClusterID{}
 	installconfig := &installconfig.InstallConfig{}
 	rhcosImage := new(rhcos.Image)
 	wign := &machine.Worker{}
-	dependencies.Get(clusterID, installconfig, rhcosImage, wign)
+	poolIgns := &machine.PoolWorkers{}
+	dependencies.Get(clusterID, installconfig, rhcosImage, wign, poolIgns)
 
 	var err error
 	userDataMap := map[string][]byte{"worker-user-data": wign.File.Data}
+	for _, pool := range installconfig.Config.Compute {
+		if file := poolIgns.File(pool.Name); file != nil {
+			userDataMap[poolUserDataSecret(&pool, poolIgns)] = file.Data
+		}
+	}
 	w.UserDataSecretRaw, err = userDataList(userDataMap)
 	if err != nil {
 		return errors.Wrap(err, "failed to create user-data secret for worker machines")
 	}
 
 	machineSets := []runtime.Object{}
+	autoscalers := []machineAutoscaler{}
 
 	ic := installconfig.Config
 	for _, pool := range ic.Compute {
+		if pool.OperatingSystem == types.OperatingSystemRHEL {
+			// The machine API provisions RHCOS, so RHEL hosts are
+			// installed by their owners and join with the RHEL user data.
+			logrus.Infof("Not creating machine sets for compute pool %s, which runs %s; install its hosts with rhel-worker-user-data.yaml as their cloud-init user data", pool.Name, pool.OperatingSystem)
+			continue
+		}
+		nodeLabels := poolNodeLabels(&pool)
+		userDataSecret := poolUserDataSecret(&pool, poolIgns)
+		first := len(machineSets)
 		switch ic.Platform.Name() {
 		case awstypes.Name:
 			mpool := defaultAWSMachinePoolPlatform()
@@ -109,18 +134,19 @@ func (w *Worker) Generate(dependencies asset.Parents) error {
 			mpool.Set(ic.Platform.AWS.DefaultMachinePlatform)
 			mpool.Set(pool.Platform.AWS)
 			if len(mpool.Zones) == 0 {
-				azs, err := aws.AvailabilityZones(ic.Platform.AWS.Region)
+				azs, err := aws.AvailabilityZones(ctx, ic.Platform.AWS.Region)
 				if err != nil {
 					return errors.Wrap(err, "failed to fetch availability zones")
 				}
 				mpool.Zones = azs
 			}
 			pool.Platform.AWS = &mpool
-			sets, err := aws.MachineSets(clusterID.InfraID, ic, &pool, string(*rhcosImage), "worker", "worker-user-data")
+			sets, err := aws.MachineSets(clusterID.InfraID, ic, &pool, string(*rhcosImage), "worker", userDataSecret)
 			if err != nil {
 				return errors.Wrap(err, "failed to create worker machine objects")
 			}
 			for _, set := range sets {
+				set.Spec.Template.Spec.ObjectMeta.Labels = nodeLabels
 				machineSets = append(machineSets, set)
 			}
 		case libvirttypes.Name:
@@ -128,25 +154,27 @@ func (w *Worker) Generate(dependencies asset.Parents) error {
 			mpool.Set(ic.Platform.Libvirt.DefaultMachinePlatform)
 			mpool.Set(pool.Platform.Libvirt)
 			pool.Platform.Libvirt = &mpool
-			sets, err := libvirt.MachineSets(clusterID.InfraID, ic, &pool, "worker", "worker-user-data")
+			sets, err := libvirt.MachineSets(clusterID.InfraID, ic, &pool, "worker", userDataSecret)
 			if err != nil {
 				return errors.Wrap(err, "failed to create worker machine objects")
 			}
 			for _, set := range sets {
+				set.Spec.Template.Spec.ObjectMeta.Labels = nodeLabels
 				machineSets = append(machineSets, set)
 			}
-		case nonetypes.Name:
+		case faketypes.Name, nonetypes.Name:
 		case openstacktypes.Name:
 			mpool := defaultOpenStackMachinePoolPlatform(ic.Platform.OpenStack.FlavorName)
 			mpool.Set(ic.Platform.OpenStack.DefaultMachinePlatform)
 			mpool.Set(pool.Platform.OpenStack)
 			pool.Platform.OpenStack = &mpool
 
-			sets, err := openstack.MachineSets(clusterID.InfraID, ic, &pool, string(*rhcosImage), "worker", "worker-user-data")
+			sets, err := openstack.MachineSets(clusterID.InfraID, ic, &pool, string(*rhcosImage), "worker", userDataSecret)
 			if err != nil {
 				return errors.Wrap(err, "failed to create master machine objects")
 			}
 			for _, set := range sets {
+				set.Spec.Template.Spec.ObjectMeta.Labels = nodeLabels
 				machineSets = append(machineSets, set)
 			}
 		case baremetaltypes.Name:
@@ -154,16 +182,55 @@ func (w *Worker) Generate(dependencies asset.Parents) error {
 			mpool.Set(ic.Platform.BareMetal.DefaultMachinePlatform)
 			mpool.Set(pool.Platform.BareMetal)
 			pool.Platform.BareMetal = &mpool
-			sets, err := baremetal.MachineSets(clusterID.InfraID, ic, &pool, "worker", "worker-user-data")
+			sets, err := baremetal.MachineSets(clusterID.InfraID, ic, &pool, "worker", userDataSecret)
 			if err != nil {
 				return errors.Wrap(err, "failed to create worker machine objects")
 			}
 			for _, set := range sets {
+				// Keep the failure domain labels of each set.
+				labels := set.Spec.Template.Spec.ObjectMeta.Labels
+				if labels == nil && len(nodeLabels) > 0 {
+					labels = make(map[string]string, len(nodeLabels))
+				}
+				for k, v := range nodeLabels {
+					labels[k] = v
+				}
+				set.Spec.Template.Spec.ObjectMeta.Labels = labels
 				machineSets = append(machineSets, set)
 			}
 		default:
 			return fmt.Errorf("invalid Platform")
 		}
+
+		if pool.Rollout != nil {
+			if err := rolloutFirstWave(machineSets[first:], &pool); err != nil {
+				return errors.Wrapf(err, "failed to roll out pool %q", pool.Name)
+			}
+		}
+
+		if pool.Autoscaling != nil {
+			poolSets := machineSets[first:]
+			for i, set := range poolSets {
+				a, err := newMachineAutoscaler(set, pool.Autoscaling, i, len(poolSets))
+				if err != nil {
+					return errors.Wrapf(err, "failed to create machine autoscaler for pool %q", pool.Name)
+				}
+				if a.MaxReplicas > 0 {
+					autoscalers = append(autoscalers, *a)
+				}
+			}
+		}
+	}
+
+	if len(autoscalers) > 0 {
+		w.ClusterAutoscalerRaw, err = clusterAutoscaler()
+		if err != nil {
+			return err
+		}
+		w.MachineAutoscalerRaw, err = machineAutoscalerList(autoscalers)
+		if err != nil {
+			return err
+		}
 	}
 
 	list := &metav1.List{
@@ -185,10 +252,42 @@ func (w *Worker) Generate(dependencies asset.Parents) error {
 	return nil
 }
 
-func applyTemplateData(template *template.Template, templateData interface{}) []byte {
-	buf := &bytes.Buffer{}
-	if err := template.Execute(buf, templateData); err != nil {
-		panic(err)
+func newMachineAutoscaler(set runtime.Object, scaling *types.MachinePoolAutoscaling, idx, count int) (*machineAutoscaler, error) {
+	accessor, err := meta.Accessor(set)
+	if err != nil {
+		return nil, err
+	}
+	return &machineAutoscaler{
+		Name:        accessor.GetName(),
+		APIVersion:  set.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+		MinReplicas: spreadReplicas(scaling.MinReplicas, idx, count),
+		MaxReplicas: spreadReplicas(scaling.MaxReplicas, idx, count),
+	}, nil
+}
+
+// poolUserDataSecret returns the name of the secret with the user data
+// of the pool's machines: the pool's own ignition config, if it has one,
+// otherwise the worker one.
+func poolUserDataSecret(pool *types.MachinePool, poolIgns *machine.PoolWorkers) string {
+	if poolIgns.File(pool.Name) != nil {
+		return fmt.Sprintf("%s-user-data", pool.Name)
+	}
+	return "worker-user-data"
+}
+
+// poolNodeLabels returns the labels to apply to the nodes of a compute
+// pool. Pools other than the default "worker" pool get their own node role
+// so they can be targeted separately by MachineConfigPools and schedulers.
+func poolNodeLabels(pool *types.MachinePool) map[string]string {
+	if len(pool.Labels) == 0 && pool.Name == "worker" {
+		return nil
+	}
+	labels := make(map[string]string, len(pool.Labels)+1)
+	for k, v := range pool.Labels {
+		labels[k] = v
+	}
+	if pool.Name != "worker" {
+		labels[fmt.Sprintf("node-role.kubernetes.io/%s", pool.Name)] = ""
 	}
-	return buf.Bytes()
+	return labels
 }
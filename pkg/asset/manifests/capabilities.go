@@ -0,0 +1,42 @@
+package manifests
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// capabilityComponents are the resources of each optional component which
+// the cluster-version operator must leave alone for the component not to
+// be installed: the operator's deployment, and the cluster operator it
+// would otherwise wait for.
+var capabilityComponents = map[types.Capability][]configv1.ComponentOverride{
+	types.CapabilityConsole: {
+		{Kind: "Deployment", Group: "apps", Namespace: "openshift-console-operator", Name: "console-operator"},
+		{Kind: "ClusterOperator", Group: configv1.GroupName, Name: "console"},
+	},
+	types.CapabilityMarketplace: {
+		{Kind: "Deployment", Group: "apps", Namespace: "openshift-marketplace", Name: "marketplace-operator"},
+		{Kind: "ClusterOperator", Group: configv1.GroupName, Name: "marketplace"},
+	},
+	types.CapabilitySamples: {
+		{Kind: "Deployment", Group: "apps", Namespace: "openshift-cluster-samples-operator", Name: "cluster-samples-operator"},
+		{Kind: "ClusterOperator", Group: configv1.GroupName, Name: "openshift-samples"},
+	},
+}
+
+// capabilityOverrides returns the cluster-version operator overrides which
+// keep the disabled capabilities from being installed.
+func capabilityOverrides(installConfig *types.InstallConfig) []configv1.ComponentOverride {
+	if installConfig.Capabilities == nil {
+		return nil
+	}
+	var overrides []configv1.ComponentOverride
+	for _, capability := range installConfig.Capabilities.Disabled {
+		for _, override := range capabilityComponents[capability] {
+			override.Unmanaged = true
+			overrides = append(overrides, override)
+		}
+	}
+	return overrides
+}
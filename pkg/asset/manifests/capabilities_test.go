@@ -0,0 +1,59 @@
+package manifests
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/templates/content/bootkube"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestCapabilityOverrides(t *testing.T) {
+	template, err := ioutil.ReadFile("../../../data/data/manifests/bootkube/cvo-overrides.yaml.template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cvoOverrides := &bootkube.CVOOverrides{FileList: []*asset.File{{Filename: "manifests/cvo-overrides.yaml", Data: template}}}
+
+	cases := []struct {
+		name         string
+		capabilities *types.Capabilities
+		expected     []configv1.ComponentOverride
+	}{
+		{
+			name: "none disabled",
+		},
+		{
+			name:         "console and samples disabled",
+			capabilities: &types.Capabilities{Disabled: []types.Capability{types.CapabilityConsole, types.CapabilitySamples}},
+			expected: []configv1.ComponentOverride{
+				{Kind: "Deployment", Group: "apps", Namespace: "openshift-console-operator", Name: "console-operator", Unmanaged: true},
+				{Kind: "ClusterOperator", Group: "config.openshift.io", Name: "console", Unmanaged: true},
+				{Kind: "Deployment", Group: "apps", Namespace: "openshift-cluster-samples-operator", Name: "cluster-samples-operator", Unmanaged: true},
+				{Kind: "ClusterOperator", Group: "config.openshift.io", Name: "openshift-samples", Unmanaged: true},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := applyTemplateData(cvoOverrides, &bootkubeTemplateData{
+				CVOClusterID: "0b5a64f6-cc7a-4ae9-a9ba-f6a2c30e2dd5",
+				CVOOverrides: capabilityOverrides(&types.InstallConfig{Capabilities: tc.capabilities}),
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			clusterVersion := &configv1.ClusterVersion{}
+			if !assert.NoError(t, yaml.Unmarshal(data, clusterVersion)) {
+				return
+			}
+			assert.Equal(t, configv1.ClusterID("0b5a64f6-cc7a-4ae9-a9ba-f6a2c30e2dd5"), clusterVersion.Spec.ClusterID)
+			assert.Equal(t, tc.expected, clusterVersion.Spec.Overrides)
+		})
+	}
+}
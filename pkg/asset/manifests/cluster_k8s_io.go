@@ -1,6 +1,8 @@
 package manifests
 
 import (
+	"context"
+
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,7 +37,7 @@ func (c *ClusterK8sIO) Dependencies() []asset.Asset {
 }
 
 // Generate generates the Worker asset.
-func (c *ClusterK8sIO) Generate(dependencies asset.Parents) error {
+func (c *ClusterK8sIO) Generate(ctx context.Context, dependencies asset.Parents) error {
 	clusterID := &installconfig.ClusterID{}
 	net := &Networking{}
 	dependencies.Get(clusterID, net)
@@ -0,0 +1,66 @@
+package manifests
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+const (
+	cniManifestDir = "cni"
+)
+
+var (
+	_ asset.WritableAsset = (*CNI)(nil)
+)
+
+// CNI is the manifests of a third-party CNI, e.g. Cilium, which the user
+// places in the cni/ directory for a cluster with the Custom network type.
+// The installer does not generate them.
+type CNI struct {
+	FileList []*asset.File
+}
+
+// Name returns a human friendly name for the asset.
+func (c *CNI) Name() string {
+	return "CNI Manifests"
+}
+
+// Dependencies returns all of the dependencies directly needed by the asset.
+func (c *CNI) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Generate generates nothing, since the manifests are only provided by the
+// user.
+func (c *CNI) Generate(context.Context, asset.Parents) error {
+	return nil
+}
+
+// Files returns the files generated by the asset.
+func (c *CNI) Files() []*asset.File {
+	return c.FileList
+}
+
+// Load reads the manifests from the cni/ directory, and checks that they
+// are valid.
+func (c *CNI) Load(f asset.FileFetcher) (bool, error) {
+	fileList, err := f.FetchByPattern(filepath.Join(cniManifestDir, "*"))
+	if err != nil {
+		return false, err
+	}
+	if len(fileList) == 0 {
+		return false, nil
+	}
+
+	if err := Lint(fileList); err != nil {
+		return false, errors.Wrapf(err, "invalid %s", c.Name())
+	}
+
+	asset.SortFiles(fileList)
+	c.FileList = fileList
+	return true, nil
+}
@@ -1,6 +1,7 @@
 package manifests
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	icaws "github.com/metalkube/kni-installer/pkg/asset/installconfig/aws"
 	awstypes "github.com/metalkube/kni-installer/pkg/types/aws"
 	baremetaltypes "github.com/metalkube/kni-installer/pkg/types/baremetal"
+	faketypes "github.com/metalkube/kni-installer/pkg/types/fake"
 	libvirttypes "github.com/metalkube/kni-installer/pkg/types/libvirt"
 	nonetypes "github.com/metalkube/kni-installer/pkg/types/none"
 	openstacktypes "github.com/metalkube/kni-installer/pkg/types/openstack"
@@ -51,7 +53,7 @@ func (*DNS) Dependencies() []asset.Asset {
 }
 
 // Generate generates the DNS config and its CRD.
-func (d *DNS) Generate(dependencies asset.Parents) error {
+func (d *DNS) Generate(ctx context.Context, dependencies asset.Parents) error {
 	installConfig := &installconfig.InstallConfig{}
 	clusterID := &installconfig.ClusterID{}
 	dependencies.Get(installConfig, clusterID)
@@ -72,7 +74,7 @@ func (d *DNS) Generate(dependencies asset.Parents) error {
 
 	switch installConfig.Config.Platform.Name() {
 	case awstypes.Name:
-		zone, err := icaws.GetPublicZone(installConfig.Config.BaseDomain)
+		zone, err := icaws.GetPublicZone(ctx, installConfig.Config.BaseDomain)
 		if err != nil {
 			return errors.Wrapf(err, "getting public zone for %q", installConfig.Config.BaseDomain)
 		}
@@ -81,7 +83,7 @@ func (d *DNS) Generate(dependencies asset.Parents) error {
 			fmt.Sprintf("kubernetes.io/cluster/%s", clusterID.InfraID): "owned",
 			"Name": fmt.Sprintf("%s-int", clusterID.InfraID),
 		}}
-	case libvirttypes.Name, openstacktypes.Name, baremetaltypes.Name, nonetypes.Name:
+	case libvirttypes.Name, openstacktypes.Name, baremetaltypes.Name, faketypes.Name, nonetypes.Name:
 	default:
 		return errors.New("invalid Platform")
 	}
@@ -0,0 +1,82 @@
+package manifests
+
+import (
+	"fmt"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	// entitlementDir is where subscription-manager keeps the host's
+	// entitlement certificates, and where yum and builds look for them.
+	entitlementDir = "/etc/pki/entitlement"
+
+	// rhsmConfPath is subscription-manager's configuration.
+	rhsmConfPath = "/etc/rhsm/rhsm.conf"
+)
+
+// entitlementsSecret returns a Secret holding the entitlement, from which
+// in-cluster builds and RHEL hosts joining the cluster can take it.
+func entitlementsSecret(entitlements *types.Entitlements) ([]byte, error) {
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]interface{}{
+			"name":      "etc-pki-entitlement",
+			"namespace": "openshift-config-managed",
+		},
+		"data": map[string][]byte{
+			"entitlement.pem":     []byte(entitlements.Certificate),
+			"entitlement-key.pem": []byte(entitlements.Key),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the entitlement Secret")
+	}
+	return data, nil
+}
+
+// entitlementsMachineConfig returns a MachineConfig which writes the
+// entitlement, and subscription-manager's configuration if given, to the
+// nodes with the given role.
+func entitlementsMachineConfig(role string, entitlements *types.Entitlements) ([]byte, error) {
+	files := []igntypes.File{
+		ignition.FileFromString(entitlementDir+"/entitlement.pem", "root", 0644, entitlements.Certificate),
+		ignition.FileFromString(entitlementDir+"/entitlement-key.pem", "root", 0600, entitlements.Key),
+	}
+	if entitlements.RHSMConfig != "" {
+		files = append(files, ignition.FileFromString(rhsmConfPath, "root", 0644, entitlements.RHSMConfig))
+	}
+	config := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+		Storage: igntypes.Storage{
+			Files: files,
+		},
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": fmt.Sprintf("99-%s-entitlements", role),
+			"labels": map[string]string{
+				"machineconfiguration.openshift.io/role": role,
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": config,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s entitlements MachineConfig", role)
+	}
+	return data, nil
+}
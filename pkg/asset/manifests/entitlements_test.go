@@ -0,0 +1,107 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/vincent-petithory/dataurl"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestEntitlementsSecret(t *testing.T) {
+	data, err := entitlementsSecret(&types.Entitlements{
+		Certificate: "certificate",
+		Key:         "key",
+	})
+	assert.NoError(t, err)
+
+	var secret struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Data map[string][]byte `json:"data"`
+	}
+	err = yaml.Unmarshal(data, &secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "Secret", secret.Kind)
+	assert.Equal(t, "etc-pki-entitlement", secret.Metadata.Name)
+	assert.Equal(t, "openshift-config-managed", secret.Metadata.Namespace)
+	assert.Equal(t, map[string][]byte{
+		"entitlement.pem":     []byte("certificate"),
+		"entitlement-key.pem": []byte("key"),
+	}, secret.Data)
+}
+
+func TestEntitlementsMachineConfig(t *testing.T) {
+	cases := []struct {
+		name          string
+		entitlements  *types.Entitlements
+		expectedFiles map[string]string
+	}{
+		{
+			name: "entitlement only",
+			entitlements: &types.Entitlements{
+				Certificate: "certificate",
+				Key:         "key",
+			},
+			expectedFiles: map[string]string{
+				"/etc/pki/entitlement/entitlement.pem":     "certificate",
+				"/etc/pki/entitlement/entitlement-key.pem": "key",
+			},
+		},
+		{
+			name: "with rhsm.conf",
+			entitlements: &types.Entitlements{
+				Certificate: "certificate",
+				Key:         "key",
+				RHSMConfig:  "[server]\nhostname = satellite.example.com\n",
+			},
+			expectedFiles: map[string]string{
+				"/etc/pki/entitlement/entitlement.pem":     "certificate",
+				"/etc/pki/entitlement/entitlement-key.pem": "key",
+				"/etc/rhsm/rhsm.conf":                      "[server]\nhostname = satellite.example.com\n",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := entitlementsMachineConfig("worker", tc.entitlements)
+			assert.NoError(t, err)
+
+			var machineConfig struct {
+				Metadata struct {
+					Name   string            `json:"name"`
+					Labels map[string]string `json:"labels"`
+				} `json:"metadata"`
+				Spec struct {
+					Config struct {
+						Storage struct {
+							Files []struct {
+								Path     string `json:"path"`
+								Contents struct {
+									Source string `json:"source"`
+								} `json:"contents"`
+							} `json:"files"`
+						} `json:"storage"`
+					} `json:"config"`
+				} `json:"spec"`
+			}
+			err = yaml.Unmarshal(data, &machineConfig)
+			assert.NoError(t, err)
+			assert.Equal(t, "99-worker-entitlements", machineConfig.Metadata.Name)
+			assert.Equal(t, map[string]string{"machineconfiguration.openshift.io/role": "worker"}, machineConfig.Metadata.Labels)
+
+			files := map[string]string{}
+			for _, file := range machineConfig.Spec.Config.Storage.Files {
+				contents, err := dataurl.DecodeString(file.Contents.Source)
+				assert.NoError(t, err)
+				files[file.Path] = string(contents.Data)
+			}
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
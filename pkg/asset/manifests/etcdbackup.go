@@ -0,0 +1,179 @@
+package manifests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	// etcdBackupName names the etcd backup objects, which live in
+	// kube-system beside the etcd client secret and serving CA they use.
+	etcdBackupName      = "etcd-backup"
+	etcdBackupNamespace = "kube-system"
+
+	// etcdBackupScript takes a snapshot from the first etcd member which
+	// answers and prunes the snapshots beyond the retention.
+	etcdBackupScript = `set -eu
+snapshot="/backup/snapshot-$(date -u +%Y%m%dT%H%M%SZ).db"
+for endpoint in ${ENDPOINTS}; do
+	if etcdctl --endpoints="${endpoint}" --cacert=/etc/etcd/ca/ca-bundle.crt --cert=/etc/etcd/client/tls.crt --key=/etc/etcd/client/tls.key snapshot save "${snapshot}"; then
+		ls -1t /backup/snapshot-*.db | tail -n "+$((RETENTION + 1))" | xargs -r rm -f
+		exit 0
+	fi
+	echo "Failed to take a snapshot from ${endpoint}" >&2
+done
+echo "Failed to take a snapshot from any etcd member" >&2
+exit 1
+`
+)
+
+// etcdBackupManifests returns the manifests which schedule snapshots of the
+// etcd members at endpoints, keyed by file name: a CronJob, the service
+// account it runs as, which may use the privileged SCC to write to the
+// host, and the claim the snapshots go to if they are not kept on the
+// control plane hosts.
+func etcdBackupManifests(backup *types.EtcdBackup, endpoints []string) (map[string][]byte, error) {
+	objects := map[string]interface{}{
+		"serviceaccount": &corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: etcdBackupObjectMeta(),
+		},
+		"role": &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+			ObjectMeta: etcdBackupObjectMeta(),
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups:     []string{"security.openshift.io"},
+				Resources:     []string{"securitycontextconstraints"},
+				ResourceNames: []string{"privileged"},
+				Verbs:         []string{"use"},
+			}},
+		},
+		"rolebinding": &rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: etcdBackupObjectMeta(),
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     etcdBackupName,
+			},
+			Subjects: []rbacv1.Subject{{
+				Kind:      "ServiceAccount",
+				Name:      etcdBackupName,
+				Namespace: etcdBackupNamespace,
+			}},
+		},
+	}
+
+	podSpec := corev1.PodSpec{
+		ServiceAccountName: etcdBackupName,
+		RestartPolicy:      corev1.RestartPolicyOnFailure,
+		Containers: []corev1.Container{{
+			Name:    "etcd-backup",
+			Image:   backup.Image,
+			Command: []string{"/bin/sh", "-c", etcdBackupScript},
+			Env: []corev1.EnvVar{
+				{Name: "ETCDCTL_API", Value: "3"},
+				{Name: "ENDPOINTS", Value: strings.Join(endpoints, " ")},
+				{Name: "RETENTION", Value: strconv.Itoa(backup.Retention)},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "etcd-client", MountPath: "/etc/etcd/client", ReadOnly: true},
+				{Name: "etcd-serving-ca", MountPath: "/etc/etcd/ca", ReadOnly: true},
+				{Name: "backup", MountPath: "/backup"},
+			},
+		}},
+		Volumes: []corev1.Volume{
+			{Name: "etcd-client", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "etcd-client"}}},
+			{Name: "etcd-serving-ca", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "etcd-serving-ca"}}}},
+		},
+	}
+	if claim := backup.PersistentVolumeClaim; claim != nil {
+		size, err := resource.ParseQuantity(claim.Size)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid etcd backup volume size %q", claim.Size)
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+			ObjectMeta: etcdBackupObjectMeta(),
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+				},
+			},
+		}
+		if claim.StorageClassName != "" {
+			pvc.Spec.StorageClassName = pointer.StringPtr(claim.StorageClassName)
+		}
+		objects["pvc"] = pvc
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name:         "backup",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: etcdBackupName}},
+		})
+	} else {
+		// Keep the snapshots on the control plane, and write them as
+		// spc_t, which may write anywhere on the host.
+		directoryOrCreate := corev1.HostPathDirectoryOrCreate
+		podSpec.NodeSelector = map[string]string{"node-role.kubernetes.io/master": ""}
+		podSpec.Tolerations = []corev1.Toleration{{
+			Key:      "node-role.kubernetes.io/master",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		}}
+		podSpec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: pointer.BoolPtr(true)}
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name:         "backup",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: backup.HostPath, Type: &directoryOrCreate}},
+		})
+	}
+
+	objects["cronjob"] = &batchv1beta1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1beta1", Kind: "CronJob"},
+		ObjectMeta: etcdBackupObjectMeta(),
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   backup.Schedule,
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: pointer.Int32Ptr(3),
+			FailedJobsHistoryLimit:     pointer.Int32Ptr(3),
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: pointer.Int32Ptr(3),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": etcdBackupName}},
+						Spec:       podSpec,
+					},
+				},
+			},
+		},
+	}
+
+	manifests := map[string][]byte{}
+	for kind, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal the etcd backup %s", kind)
+		}
+		manifests[fmt.Sprintf("99_%s_%s-%s.yaml", etcdBackupNamespace, etcdBackupName, kind)] = data
+	}
+	return manifests, nil
+}
+
+func etcdBackupObjectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      etcdBackupName,
+		Namespace: etcdBackupNamespace,
+	}
+}
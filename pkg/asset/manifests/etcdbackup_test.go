@@ -0,0 +1,94 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestEtcdBackupManifests(t *testing.T) {
+	endpoints := []string{"https://etcd-0.test.example.com:2379", "https://etcd-1.test.example.com:2379"}
+	cases := []struct {
+		name          string
+		backup        *types.EtcdBackup
+		expectedFiles []string
+		check         func(t *testing.T, spec *corev1.PodSpec)
+	}{
+		{
+			name: "host path",
+			backup: &types.EtcdBackup{
+				Schedule:  "0 */6 * * *",
+				Retention: 14,
+				Image:     "quay.io/coreos/etcd:v3.3.10",
+				HostPath:  "/var/lib/etcd-backup",
+			},
+			expectedFiles: []string{
+				"99_kube-system_etcd-backup-cronjob.yaml",
+				"99_kube-system_etcd-backup-role.yaml",
+				"99_kube-system_etcd-backup-rolebinding.yaml",
+				"99_kube-system_etcd-backup-serviceaccount.yaml",
+			},
+			check: func(t *testing.T, spec *corev1.PodSpec) {
+				assert.Equal(t, map[string]string{"node-role.kubernetes.io/master": ""}, spec.NodeSelector)
+				if assert.Len(t, spec.Volumes, 3) && assert.NotNil(t, spec.Volumes[2].HostPath) {
+					assert.Equal(t, "/var/lib/etcd-backup", spec.Volumes[2].HostPath.Path)
+				}
+			},
+		},
+		{
+			name: "volume claim",
+			backup: &types.EtcdBackup{
+				Schedule:              "0 2 * * *",
+				Retention:             7,
+				Image:                 "quay.io/coreos/etcd:v3.3.10",
+				PersistentVolumeClaim: &types.EtcdBackupVolumeClaim{StorageClassName: "nfs", Size: "20Gi"},
+			},
+			expectedFiles: []string{
+				"99_kube-system_etcd-backup-cronjob.yaml",
+				"99_kube-system_etcd-backup-pvc.yaml",
+				"99_kube-system_etcd-backup-role.yaml",
+				"99_kube-system_etcd-backup-rolebinding.yaml",
+				"99_kube-system_etcd-backup-serviceaccount.yaml",
+			},
+			check: func(t *testing.T, spec *corev1.PodSpec) {
+				assert.Empty(t, spec.NodeSelector)
+				if assert.Len(t, spec.Volumes, 3) && assert.NotNil(t, spec.Volumes[2].PersistentVolumeClaim) {
+					assert.Equal(t, "etcd-backup", spec.Volumes[2].PersistentVolumeClaim.ClaimName)
+				}
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := etcdBackupManifests(tc.backup, endpoints)
+			if !assert.NoError(t, err) {
+				return
+			}
+			names := []string{}
+			for name := range manifests {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tc.expectedFiles, names)
+
+			cronJob := &batchv1beta1.CronJob{}
+			err = yaml.Unmarshal(manifests["99_kube-system_etcd-backup-cronjob.yaml"], cronJob)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "kube-system", cronJob.Namespace)
+			assert.Equal(t, tc.backup.Schedule, cronJob.Spec.Schedule)
+			assert.Equal(t, batchv1beta1.ForbidConcurrent, cronJob.Spec.ConcurrencyPolicy)
+			spec := &cronJob.Spec.JobTemplate.Spec.Template.Spec
+			if assert.Len(t, spec.Containers, 1) {
+				assert.Equal(t, tc.backup.Image, spec.Containers[0].Image)
+				assert.Contains(t, spec.Containers[0].Env, corev1.EnvVar{Name: "ENDPOINTS", Value: "https://etcd-0.test.example.com:2379 https://etcd-1.test.example.com:2379"})
+			}
+			tc.check(t, spec)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package manifests
+
+import (
+	"fmt"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+)
+
+// firewallRulesPath is where the nodes' nftables rules are written.
+const firewallRulesPath = "/etc/nftables/openshift-firewall.nft"
+
+// firewallMachineConfig returns a MachineConfig which loads the nftables
+// rules on the nodes with the given role before the kubelet starts.
+func firewallMachineConfig(role string, rules string) ([]byte, error) {
+	enabled := true
+	config := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+		Storage: igntypes.Storage{
+			Files: []igntypes.File{
+				ignition.FileFromString(firewallRulesPath, "root", 0644, rules),
+			},
+		},
+		Systemd: igntypes.Systemd{
+			Units: []igntypes.Unit{{
+				Name:    "openshift-firewall.service",
+				Enabled: &enabled,
+				Contents: fmt.Sprintf(`[Unit]
+Description=Apply the cluster's nftables rules
+Wants=network-pre.target
+Before=network-pre.target kubelet.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/sbin/nft -f %s
+
+[Install]
+WantedBy=multi-user.target
+`, firewallRulesPath),
+			}},
+		},
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": fmt.Sprintf("99-%s-firewall", role),
+			"labels": map[string]string{
+				"machineconfiguration.openshift.io/role": role,
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": config,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s firewall MachineConfig", role)
+	}
+	return data, nil
+}
@@ -1,6 +1,7 @@
 package manifests
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -9,12 +10,14 @@ import (
 
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/types"
 	configv1 "github.com/openshift/api/config/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
-	ingCfgFilename = filepath.Join(manifestDir, "cluster-ingress-02-config.yml")
+	ingCfgFilename        = filepath.Join(manifestDir, "cluster-ingress-02-config.yml")
+	ingControllerFilename = filepath.Join(manifestDir, "cluster-ingress-default-ingresscontroller.yaml")
 )
 
 // Ingress generates the cluster-ingress-*.yml files.
@@ -38,7 +41,7 @@ func (*Ingress) Dependencies() []asset.Asset {
 }
 
 // Generate generates the ingress config and its CRD.
-func (ing *Ingress) Generate(dependencies asset.Parents) error {
+func (ing *Ingress) Generate(ctx context.Context, dependencies asset.Parents) error {
 	installConfig := &installconfig.InstallConfig{}
 	dependencies.Get(installConfig)
 
@@ -68,9 +71,52 @@ func (ing *Ingress) Generate(dependencies asset.Parents) error {
 		},
 	}
 
+	// The default ingress controller runs two routers, which cannot both be
+	// scheduled on a single node.
+	controllerSpec := map[string]interface{}{}
+	if routerNodes := routerNodeCount(installConfig.Config); routerNodes == 1 {
+		controllerSpec["replicas"] = routerNodes
+	}
+	if profile := installConfig.Config.TLSSecurityProfile; profile != nil {
+		controllerSpec["tlsSecurityProfile"] = tlsSecurityProfileSpec(profile)
+	}
+	if len(controllerSpec) > 0 {
+		controllerData, err := yaml.Marshal(map[string]interface{}{
+			"apiVersion": "operator.openshift.io/v1",
+			"kind":       "IngressController",
+			"metadata": map[string]string{
+				"name":      "default",
+				"namespace": "openshift-ingress-operator",
+			},
+			"spec": controllerSpec,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create %s manifests from InstallConfig", ing.Name())
+		}
+		ing.FileList = append(ing.FileList, &asset.File{
+			Filename: ingControllerFilename,
+			Data:     controllerData,
+		})
+	}
+
 	return nil
 }
 
+// routerNodeCount returns the number of nodes the routers can be scheduled
+// on: the compute nodes, or the control plane if there are none.
+func routerNodeCount(c *types.InstallConfig) int64 {
+	var compute int64
+	for _, p := range c.Compute {
+		if p.Replicas != nil {
+			compute += *p.Replicas
+		}
+	}
+	if compute > 0 || c.ControlPlane == nil || c.ControlPlane.Replicas == nil {
+		return compute
+	}
+	return *c.ControlPlane.Replicas
+}
+
 // Files returns the files generated by the asset.
 func (ing *Ingress) Files() []*asset.File {
 	return ing.FileList
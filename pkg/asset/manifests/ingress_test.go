@@ -0,0 +1,118 @@
+package manifests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestIngressGenerate(t *testing.T) {
+	cases := []struct {
+		name               string
+		controlPlane       int64
+		compute            int64
+		tlsSecurityProfile *types.TLSSecurityProfile
+		expectedFiles      []string
+		expectedController string
+	}{
+		{
+			name:          "standard",
+			controlPlane:  3,
+			compute:       3,
+			expectedFiles: []string{"manifests/cluster-ingress-02-config.yml"},
+		},
+		{
+			name:          "single node",
+			controlPlane:  1,
+			expectedFiles: []string{"manifests/cluster-ingress-02-config.yml", "manifests/cluster-ingress-default-ingresscontroller.yaml"},
+			expectedController: `apiVersion: operator.openshift.io/v1
+kind: IngressController
+metadata:
+  name: default
+  namespace: openshift-ingress-operator
+spec:
+  replicas: 1
+`,
+		},
+		{
+			name:          "single worker",
+			controlPlane:  3,
+			compute:       1,
+			expectedFiles: []string{"manifests/cluster-ingress-02-config.yml", "manifests/cluster-ingress-default-ingresscontroller.yaml"},
+		},
+		{
+			name:         "custom tls security profile",
+			controlPlane: 3,
+			compute:      3,
+			tlsSecurityProfile: &types.TLSSecurityProfile{
+				Type: types.TLSProfileCustom,
+				Custom: &types.CustomTLSProfile{
+					Ciphers:       []string{"ECDHE-RSA-AES256-GCM-SHA384"},
+					MinTLSVersion: types.VersionTLS12,
+				},
+			},
+			expectedFiles: []string{"manifests/cluster-ingress-02-config.yml", "manifests/cluster-ingress-default-ingresscontroller.yaml"},
+			expectedController: `apiVersion: operator.openshift.io/v1
+kind: IngressController
+metadata:
+  name: default
+  namespace: openshift-ingress-operator
+spec:
+  tlsSecurityProfile:
+    custom:
+      ciphers:
+      - ECDHE-RSA-AES256-GCM-SHA384
+      minTLSVersion: VersionTLS12
+    type: Custom
+`,
+		},
+		{
+			name:          "five masters",
+			controlPlane:  5,
+			expectedFiles: []string{"manifests/cluster-ingress-02-config.yml"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			installConfig := &installconfig.InstallConfig{
+				Config: &types.InstallConfig{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-cluster",
+					},
+					BaseDomain: "test-domain",
+					ControlPlane: &types.MachinePool{
+						Name:     "master",
+						Replicas: pointer.Int64Ptr(tc.controlPlane),
+					},
+					Compute: []types.MachinePool{{
+						Name:     "worker",
+						Replicas: pointer.Int64Ptr(tc.compute),
+					}},
+					TLSSecurityProfile: tc.tlsSecurityProfile,
+				},
+			}
+			parents := asset.Parents{}
+			parents.Add(installConfig)
+
+			ingress := &Ingress{}
+			err := ingress.Generate(context.Background(), parents)
+			assert.NoError(t, err)
+
+			var filenames []string
+			for _, f := range ingress.Files() {
+				filenames = append(filenames, f.Filename)
+			}
+			assert.Equal(t, tc.expectedFiles, filenames)
+			if tc.expectedController != "" {
+				assert.Equal(t, tc.expectedController, string(ingress.Files()[1].Data))
+			}
+		})
+	}
+}
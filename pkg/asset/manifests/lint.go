@@ -0,0 +1,162 @@
+package manifests
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+// knownAPIVersions are the API versions served by the target release,
+// before any CustomResourceDefinitions in the manifests are created.
+var knownAPIVersions = map[string]bool{
+	"v1":                                     true,
+	"admissionregistration.k8s.io/v1beta1":   true,
+	"apiextensions.k8s.io/v1beta1":           true,
+	"apps/v1":                                true,
+	"autoscaling.openshift.io/v1":            true,
+	"autoscaling.openshift.io/v1beta1":       true,
+	"batch/v1":                               true,
+	"cloudcredential.openshift.io/v1":        true,
+	"clusterversion.openshift.io/v1":         true,
+	"config.openshift.io/v1":                 true,
+	"imageregistry.operator.openshift.io/v1": true,
+	"machine.openshift.io/v1beta1":           true,
+	"machineconfiguration.openshift.io/v1":   true,
+	"metal3.io/v1alpha1":                     true,
+	"monitoring.coreos.com/v1":               true,
+	"operator.openshift.io/v1":               true,
+	"policy/v1beta1":                         true,
+	"rbac.authorization.k8s.io/v1":           true,
+	"rbac.authorization.k8s.io/v1beta1":      true,
+	"scheduling.k8s.io/v1beta1":              true,
+	"security.openshift.io/v1":               true,
+	"storage.k8s.io/v1":                      true,
+}
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// manifest is a single Kubernetes object parsed from a file.
+type manifest struct {
+	source string
+	object map[string]interface{}
+}
+
+// Lint checks that generated manifests are valid YAML or JSON, use API
+// versions the target release (or a CustomResourceDefinition among the
+// manifests) serves, and set apiVersion, kind and metadata.name.  It is
+// run on the generated manifests before they are written, so a broken
+// template is caught immediately instead of by a bootstrap which never
+// completes.
+func Lint(files []*asset.File) error {
+	var manifests []manifest
+	var problems []string
+	for _, file := range files {
+		switch filepath.Ext(file.Filename) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		for i, doc := range documentSeparator.Split(string(file.Data), -1) {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			source := file.Filename
+			if i > 0 {
+				source = fmt.Sprintf("%s (document %d)", file.Filename, i+1)
+			}
+			object := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(doc), &object); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", source, err))
+				continue
+			}
+			if len(object) == 0 {
+				continue
+			}
+			manifests = append(manifests, manifest{source: source, object: object})
+		}
+	}
+
+	apiVersions := map[string]bool{}
+	for version := range knownAPIVersions {
+		apiVersions[version] = true
+	}
+	for _, m := range manifests {
+		for _, version := range crdAPIVersions(m.object) {
+			apiVersions[version] = true
+		}
+	}
+
+	for _, m := range manifests {
+		problems = append(problems, lintObject(m.source, m.object, apiVersions)...)
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid manifests:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+func lintObject(source string, object map[string]interface{}, apiVersions map[string]bool) []string {
+	var problems []string
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+	if apiVersion == "" {
+		problems = append(problems, fmt.Sprintf("%s: apiVersion is required", source))
+	} else if !apiVersions[apiVersion] {
+		problems = append(problems, fmt.Sprintf("%s: unknown apiVersion %q", source, apiVersion))
+	}
+	if kind == "" {
+		problems = append(problems, fmt.Sprintf("%s: kind is required", source))
+	}
+
+	if kind == "List" {
+		items, _ := object["items"].([]interface{})
+		for i, item := range items {
+			itemObject, ok := item.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: items[%d] is not an object", source, i))
+				continue
+			}
+			problems = append(problems, lintObject(fmt.Sprintf("%s items[%d]", source, i), itemObject, apiVersions)...)
+		}
+		return problems
+	}
+
+	metadata, _ := object["metadata"].(map[string]interface{})
+	if name, _ := metadata["name"].(string); name == "" {
+		problems = append(problems, fmt.Sprintf("%s: metadata.name is required", source))
+	}
+	return problems
+}
+
+// crdAPIVersions returns the API versions served by a
+// CustomResourceDefinition, or nothing for other objects.
+func crdAPIVersions(object map[string]interface{}) []string {
+	if object["kind"] != "CustomResourceDefinition" {
+		return nil
+	}
+	spec, _ := object["spec"].(map[string]interface{})
+	group, _ := spec["group"].(string)
+	if group == "" {
+		return nil
+	}
+
+	var versions []string
+	if version, ok := spec["version"].(string); ok && version != "" {
+		versions = append(versions, group+"/"+version)
+	}
+	list, _ := spec["versions"].([]interface{})
+	for _, v := range list {
+		entry, _ := v.(map[string]interface{})
+		if name, ok := entry["name"].(string); ok && name != "" {
+			versions = append(versions, group+"/"+name)
+		}
+	}
+	return versions
+}
@@ -0,0 +1,68 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name          string
+		files         map[string]string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			files: map[string]string{
+				"manifests/secret.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: pull-secret\n",
+				"manifests/pull.json":   `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "pull"}}`,
+				"openshift/list.yaml":   "apiVersion: v1\nkind: List\nitems:\n- apiVersion: autoscaling.openshift.io/v1beta1\n  kind: MachineAutoscaler\n  metadata:\n    name: worker\n",
+				"manifests/notes.txt":   "not a manifest",
+			},
+		},
+		{
+			name: "types defined by CRDs",
+			files: map[string]string{
+				"manifests/crd.yaml":    "apiVersion: apiextensions.k8s.io/v1beta1\nkind: CustomResourceDefinition\nmetadata:\n  name: widgets.example.com\nspec:\n  group: example.com\n  versions:\n  - name: v1alpha1\n",
+				"manifests/widget.yaml": "---\napiVersion: example.com/v1alpha1\nkind: Widget\nmetadata:\n  name: w\n---\n",
+			},
+		},
+		{
+			name: "invalid YAML",
+			files: map[string]string{
+				"manifests/broken.yaml": "apiVersion: v1\nkind: [Secret\n",
+			},
+			expectedError: `^invalid manifests:\nmanifests/broken\.yaml: error converting YAML to JSON: .*$`,
+		},
+		{
+			name: "missing fields and unknown version",
+			files: map[string]string{
+				"manifests/a.yaml": "kind: Secret\nmetadata:\n  name: a\n---\napiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: \"\"\n",
+				"openshift/b.yaml": "apiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  metadata:\n    name: b\n",
+			},
+			expectedError: `^invalid manifests:
+manifests/a\.yaml: apiVersion is required
+manifests/a\.yaml \(document 2\): unknown apiVersion "example\.com/v1"
+manifests/a\.yaml \(document 2\): metadata\.name is required
+openshift/b\.yaml items\[0\]: kind is required$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var files []*asset.File
+			for name, data := range tc.files {
+				files = append(files, &asset.File{Filename: name, Data: []byte(data)})
+			}
+			asset.SortFiles(files)
+			err := Lint(files)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
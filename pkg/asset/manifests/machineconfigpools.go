@@ -0,0 +1,78 @@
+package manifests
+
+import (
+	"fmt"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// machineConfigPoolManifests returns the manifests for an additional
+// MachineConfigPool, keyed by file name: the pool itself and, if it has
+// files, a MachineConfig writing them.  Like the infra pools documented for
+// OpenShift, the pool selects the worker MachineConfigs as well as its own,
+// so its nodes stay configured as workers.
+func machineConfigPoolManifests(pool *types.MachineConfigPool) (map[string][]byte, error) {
+	manifests := map[string][]byte{}
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfigPool",
+		"metadata": map[string]interface{}{
+			"name": pool.Name,
+		},
+		"spec": map[string]interface{}{
+			"machineConfigSelector": map[string]interface{}{
+				"matchExpressions": []map[string]interface{}{{
+					"key":      "machineconfiguration.openshift.io/role",
+					"operator": "In",
+					"values":   []string{"worker", pool.Name},
+				}},
+			},
+			"nodeSelector": map[string]interface{}{
+				"matchLabels": pool.NodeSelector,
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s MachineConfigPool", pool.Name)
+	}
+	manifests[fmt.Sprintf("99_openshift-machineconfigpool_%s.yaml", pool.Name)] = data
+
+	if len(pool.Files) == 0 {
+		return manifests, nil
+	}
+	config := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+	}
+	for _, f := range pool.Files {
+		mode := 0644
+		if f.Mode != nil {
+			mode = *f.Mode
+		}
+		config.Storage.Files = append(config.Storage.Files, ignition.FileFromString(f.Path, "root", mode, f.Contents))
+	}
+	data, err = yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": fmt.Sprintf("99-%s-files", pool.Name),
+			"labels": map[string]string{
+				"machineconfiguration.openshift.io/role": pool.Name,
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": config,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s files MachineConfig", pool.Name)
+	}
+	manifests[fmt.Sprintf("99_openshift-machineconfig_%s-files.yaml", pool.Name)] = data
+	return manifests, nil
+}
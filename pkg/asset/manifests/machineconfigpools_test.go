@@ -0,0 +1,62 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestMachineConfigPoolManifests(t *testing.T) {
+	cases := []struct {
+		name          string
+		pool          types.MachineConfigPool
+		expectedFiles []string
+	}{
+		{
+			name: "selector only",
+			pool: types.MachineConfigPool{
+				Name:         "infra",
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+			},
+			expectedFiles: []string{"99_openshift-machineconfigpool_infra.yaml"},
+		},
+		{
+			name: "with files",
+			pool: types.MachineConfigPool{
+				Name:         "infra",
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Files:        []types.MachineConfigFile{{Path: "/etc/infra.conf", Contents: "infra"}},
+			},
+			expectedFiles: []string{"99_openshift-machineconfig_infra-files.yaml", "99_openshift-machineconfigpool_infra.yaml"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := machineConfigPoolManifests(&tc.pool)
+			assert.NoError(t, err)
+			var filenames []string
+			for name := range manifests {
+				filenames = append(filenames, name)
+			}
+			assert.ElementsMatch(t, tc.expectedFiles, filenames)
+			assert.Equal(t, `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfigPool
+metadata:
+  name: infra
+spec:
+  machineConfigSelector:
+    matchExpressions:
+    - key: machineconfiguration.openshift.io/role
+      operator: In
+      values:
+      - worker
+      - infra
+  nodeSelector:
+    matchLabels:
+      node-role.kubernetes.io/infra: ""
+`, string(manifests["99_openshift-machineconfigpool_infra.yaml"]))
+		})
+	}
+}
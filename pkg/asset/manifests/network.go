@@ -1,6 +1,7 @@
 package manifests
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 	"github.com/metalkube/kni-installer/pkg/asset/templates/content/openshift"
+	"github.com/metalkube/kni-installer/pkg/types"
 	configv1 "github.com/openshift/api/config/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +23,10 @@ var (
 	noCfgFilename = filepath.Join(manifestDir, "cluster-network-02-config.yml")
 )
 
+// cniFilenamePrefix prefixes the user's CNI manifests in the manifests
+// directory, so they cannot collide with the generated manifests.
+const cniFilenamePrefix = "cluster-network-03-cni-"
+
 // We need to manually create our CRDs first, so we can create the
 // configuration instance of it in the installer. Other operators have
 // their CRD created by the CVO, but we need to create the corresponding
@@ -49,16 +55,27 @@ func (no *Networking) Dependencies() []asset.Asset {
 	return []asset.Asset{
 		&installconfig.InstallConfig{},
 		&openshift.NetworkCRDs{},
+		&CNI{},
 	}
 }
 
-// Generate generates the network operator config and its CRD.
-func (no *Networking) Generate(dependencies asset.Parents) error {
+// Generate generates the network operator config and its CRD.  For the
+// Custom network type, the network operator's CRD is replaced by the
+// user's CNI manifests.
+func (no *Networking) Generate(ctx context.Context, dependencies asset.Parents) error {
 	installConfig := &installconfig.InstallConfig{}
 	crds := &openshift.NetworkCRDs{}
-	dependencies.Get(installConfig, crds)
+	cni := &CNI{}
+	dependencies.Get(installConfig, crds, cni)
 
 	netConfig := installConfig.Config.Networking
+	custom := netConfig.NetworkType == types.NetworkTypeCustom
+	if custom && len(cni.Files()) == 0 {
+		return errors.Errorf("networkType %s requires the CNI's manifests in the %s directory", types.NetworkTypeCustom, cniManifestDir)
+	}
+	if !custom && len(cni.Files()) > 0 {
+		return errors.Errorf("the manifests in the %s directory require networkType %s, not %s", cniManifestDir, types.NetworkTypeCustom, netConfig.NetworkType)
+	}
 
 	clusterNet := []configv1.ClusterNetworkEntry{}
 	if len(netConfig.ClusterNetwork) > 0 {
@@ -98,20 +115,28 @@ func (no *Networking) Generate(dependencies asset.Parents) error {
 		return errors.Wrapf(err, "failed to create %s manifests from InstallConfig", no.Name())
 	}
 
-	crdContents := ""
-	for _, crdFile := range crds.Files() {
-		crdContents = fmt.Sprintf("%s\n---\n%s", crdContents, crdFile.Data)
-	}
-
-	no.FileList = []*asset.File{
-		{
+	no.FileList = nil
+	if !custom {
+		crdContents := ""
+		for _, crdFile := range crds.Files() {
+			crdContents = fmt.Sprintf("%s\n---\n%s", crdContents, crdFile.Data)
+		}
+		no.FileList = append(no.FileList, &asset.File{
 			Filename: noCrdFilename,
 			Data:     []byte(crdContents),
-		},
-		{
-			Filename: noCfgFilename,
-			Data:     configData,
-		},
+		})
+	}
+	no.FileList = append(no.FileList, &asset.File{
+		Filename: noCfgFilename,
+		Data:     configData,
+	})
+	if custom {
+		for _, file := range cni.Files() {
+			no.FileList = append(no.FileList, &asset.File{
+				Filename: filepath.Join(manifestDir, cniFilenamePrefix+filepath.Base(file.Filename)),
+				Data:     file.Data,
+			})
+		}
 	}
 
 	return nil
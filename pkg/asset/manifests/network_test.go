@@ -0,0 +1,130 @@
+package manifests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/mock"
+	"github.com/metalkube/kni-installer/pkg/asset/templates/content/openshift"
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const ciliumManifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: cilium
+`
+
+func TestNetworkingGenerate(t *testing.T) {
+	cniFiles := []*asset.File{{Filename: "cni/cilium.yaml", Data: []byte(ciliumManifest)}}
+	cases := []struct {
+		name          string
+		networkType   string
+		cni           []*asset.File
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:        "default",
+			networkType: "OpenShiftSDN",
+			expected:    []string{"manifests/cluster-network-01-crd.yml", "manifests/cluster-network-02-config.yml"},
+		},
+		{
+			name:        "custom",
+			networkType: types.NetworkTypeCustom,
+			cni:         cniFiles,
+			expected:    []string{"manifests/cluster-network-02-config.yml", "manifests/cluster-network-03-cni-cilium.yaml"},
+		},
+		{
+			name:          "custom without manifests",
+			networkType:   types.NetworkTypeCustom,
+			expectedError: `^networkType Custom requires the CNI's manifests in the cni directory$`,
+		},
+		{
+			name:          "manifests without custom",
+			networkType:   "OpenShiftSDN",
+			cni:           cniFiles,
+			expectedError: `^the manifests in the cni directory require networkType Custom, not OpenShiftSDN$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parents := asset.Parents{}
+			parents.Add(
+				&installconfig.InstallConfig{Config: &types.InstallConfig{
+					Networking: &types.Networking{
+						NetworkType:    tc.networkType,
+						ClusterNetwork: []types.ClusterNetworkEntry{{CIDR: *ipnet.MustParseCIDR("10.128.0.0/14"), HostPrefix: 23}},
+						ServiceNetwork: []ipnet.IPNet{*ipnet.MustParseCIDR("172.30.0.0/16")},
+					},
+				}},
+				&openshift.NetworkCRDs{},
+				&CNI{FileList: tc.cni},
+			)
+
+			network := &Networking{}
+			err := network.Generate(context.Background(), parents)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			var filenames []string
+			for _, file := range network.Files() {
+				filenames = append(filenames, file.Filename)
+			}
+			assert.Equal(t, tc.expected, filenames)
+			assert.Equal(t, tc.networkType, network.Config.Spec.NetworkType)
+		})
+	}
+}
+
+func TestCNILoad(t *testing.T) {
+	cases := []struct {
+		name          string
+		files         []*asset.File
+		expectedFound bool
+		expectedError string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:          "valid",
+			files:         []*asset.File{{Filename: "cni/cilium.yaml", Data: []byte(ciliumManifest)}},
+			expectedFound: true,
+		},
+		{
+			name:          "invalid",
+			files:         []*asset.File{{Filename: "cni/cilium.yaml", Data: []byte("apiVersion: v1\nkind: Namespace\n")}},
+			expectedError: `^invalid CNI Manifests: invalid manifests:\ncni/cilium.yaml: `,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			fileFetcher := mock.NewMockFileFetcher(mockCtrl)
+			fileFetcher.EXPECT().FetchByPattern("cni/*").Return(tc.files, nil)
+
+			cni := &CNI{}
+			found, err := cni.Load(fileFetcher)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.files, cni.Files())
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package manifests
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/ghodss/yaml"
@@ -15,6 +17,8 @@ import (
 	osmachine "github.com/metalkube/kni-installer/pkg/asset/machines/openstack"
 	"github.com/metalkube/kni-installer/pkg/asset/password"
 	"github.com/metalkube/kni-installer/pkg/asset/templates/content/openshift"
+	"github.com/metalkube/kni-installer/pkg/firewall"
+	"github.com/metalkube/kni-installer/pkg/types"
 )
 
 const (
@@ -43,6 +47,8 @@ func (o *Openshift) Dependencies() []asset.Asset {
 		&ClusterK8sIO{},
 		&machines.Worker{},
 		&password.KubeadminPassword{},
+		&installconfig.Ephemeral{},
+		&installconfig.FirewallRequirements{},
 
 		&openshift.BindingDiscovery{},
 		&openshift.CloudCredsSecret{},
@@ -52,12 +58,13 @@ func (o *Openshift) Dependencies() []asset.Asset {
 }
 
 // Generate generates the respective operator config.yml files
-func (o *Openshift) Generate(dependencies asset.Parents) error {
+func (o *Openshift) Generate(ctx context.Context, dependencies asset.Parents) error {
 	installConfig := &installconfig.InstallConfig{}
 	kubeadminPassword := &password.KubeadminPassword{}
+	ephemeral := &installconfig.Ephemeral{}
 	clusterk8sio := &ClusterK8sIO{}
 	worker := &machines.Worker{}
-	dependencies.Get(installConfig, clusterk8sio, worker, kubeadminPassword)
+	dependencies.Get(installConfig, clusterk8sio, worker, kubeadminPassword, ephemeral)
 	var cloudCreds cloudCredsSecretData
 	platform := installConfig.Config.Platform.Name()
 	switch platform {
@@ -104,6 +111,9 @@ func (o *Openshift) Generate(dependencies asset.Parents) error {
 		CloudCreds:                   cloudCreds,
 		Base64EncodedKubeadminPwHash: base64.StdEncoding.EncodeToString(kubeadminPassword.PasswordHash),
 	}
+	if ephemeral.ExpiresAt != nil {
+		templateData.KubeadminExpiresAt = ephemeral.ExpiresAt.Format(time.RFC3339)
+	}
 
 	bindingDiscovery := &openshift.BindingDiscovery{}
 	cloudCredsSecret := &openshift.CloudCredsSecret{}
@@ -117,16 +127,131 @@ func (o *Openshift) Generate(dependencies asset.Parents) error {
 
 	assetData := map[string][]byte{
 		"99_binding-discovery.yaml":                             []byte(bindingDiscovery.Files()[0].Data),
-		"99_kubeadmin-password-secret.yaml":                     applyTemplateData(kubeadminPasswordSecret.Files()[0].Data, templateData),
 		"99_openshift-cluster-api_cluster.yaml":                 clusterk8sio.Raw,
 		"99_openshift-cluster-api_worker-machineset.yaml":       worker.MachineSetRaw,
 		"99_openshift-cluster-api_worker-user-data-secret.yaml": worker.UserDataSecretRaw,
 	}
 
+	templates := map[string]asset.WritableAsset{
+		"99_kubeadmin-password-secret.yaml": kubeadminPasswordSecret,
+	}
 	switch platform {
 	case "aws", "openstack":
-		assetData["99_cloud-creds-secret.yaml"] = applyTemplateData(cloudCredsSecret.Files()[0].Data, templateData)
-		assetData["99_role-cloud-creds-secret-reader.yaml"] = applyTemplateData(roleCloudCredsSecretReader.Files()[0].Data, templateData)
+		templates["99_cloud-creds-secret.yaml"] = cloudCredsSecret
+		templates["99_role-cloud-creds-secret-reader.yaml"] = roleCloudCredsSecretReader
+	}
+	for name, template := range templates {
+		data, err := applyTemplateData(template, templateData)
+		if err != nil {
+			return err
+		}
+		assetData[name] = data
+	}
+
+	if worker.MachineAutoscalerRaw != nil {
+		assetData["99_openshift-cluster-autoscaler.yaml"] = worker.ClusterAutoscalerRaw
+		assetData["99_openshift-cluster-api_worker-machineautoscaler.yaml"] = worker.MachineAutoscalerRaw
+	}
+
+	tunedPools := map[string]*types.MachinePool{"master": installConfig.Config.ControlPlane}
+	for i, pool := range installConfig.Config.Compute {
+		tunedPools[pool.Name] = &installConfig.Config.Compute[i]
+	}
+	for role, pool := range tunedPools {
+		data, err := tuningMachineConfig(role, pool)
+		if err != nil {
+			return err
+		}
+		if data != nil {
+			assetData[fmt.Sprintf("99_openshift-machineconfig_%s-tuning.yaml", role)] = data
+		}
+	}
+
+	if profile := installConfig.Config.TLSSecurityProfile; profile != nil {
+		if policy := cryptoPolicy(profile); policy != "DEFAULT" {
+			for role := range tunedPools {
+				data, err := cryptoPolicyMachineConfig(role, policy)
+				if err != nil {
+					return err
+				}
+				assetData[fmt.Sprintf("99_openshift-machineconfig_%s-crypto-policy.yaml", role)] = data
+			}
+		}
+	}
+
+	if bm := installConfig.Config.Platform.BareMetal; bm != nil && bm.RegistryMirror != nil {
+		for role := range tunedPools {
+			data, err := registryMirrorMachineConfig(role, bm.RegistryMirror)
+			if err != nil {
+				return err
+			}
+			assetData[fmt.Sprintf("99_openshift-machineconfig_%s-registry-mirror.yaml", role)] = data
+		}
+	}
+
+	if dns := installConfig.Config.DNS; dns != nil && len(dns.Upstreams) > 0 {
+		for role := range tunedPools {
+			data, err := resolversMachineConfig(role, dns.Upstreams)
+			if err != nil {
+				return err
+			}
+			assetData[fmt.Sprintf("99_openshift-machineconfig_%s-resolvers.yaml", role)] = data
+		}
+	}
+
+	if backup := installConfig.Config.EtcdBackup; backup != nil {
+		endpoints := make([]string, *installConfig.Config.ControlPlane.Replicas)
+		for i := range endpoints {
+			endpoints[i] = fmt.Sprintf("https://etcd-%d.%s:2379", i, getEtcdDiscoveryDomain(installConfig.Config))
+		}
+		manifests, err := etcdBackupManifests(backup, endpoints)
+		if err != nil {
+			return err
+		}
+		for name, data := range manifests {
+			assetData[name] = data
+		}
+	}
+
+	if entitlements := installConfig.Config.Entitlements; entitlements != nil {
+		data, err := entitlementsSecret(entitlements)
+		if err != nil {
+			return err
+		}
+		assetData["99_openshift-config-managed_etc-pki-entitlement-secret.yaml"] = data
+		for _, pool := range installConfig.Config.Compute {
+			data, err := entitlementsMachineConfig(pool.Name, entitlements)
+			if err != nil {
+				return err
+			}
+			assetData[fmt.Sprintf("99_openshift-machineconfig_%s-entitlements.yaml", pool.Name)] = data
+		}
+	}
+
+	if fw := installConfig.Config.Firewall; fw != nil && fw.NFTables {
+		requirements := &installconfig.FirewallRequirements{}
+		dependencies.Get(requirements)
+		for role := range tunedPools {
+			destination := firewall.Worker
+			if role == "master" {
+				destination = firewall.Master
+			}
+			data, err := firewallMachineConfig(role, requirements.Requirements.NFTables(destination))
+			if err != nil {
+				return err
+			}
+			assetData[fmt.Sprintf("99_openshift-machineconfig_%s-firewall.yaml", role)] = data
+		}
+	}
+
+	for i := range installConfig.Config.MachineConfigPools {
+		manifests, err := machineConfigPoolManifests(&installConfig.Config.MachineConfigPools[i])
+		if err != nil {
+			return err
+		}
+		for name, data := range manifests {
+			assetData[name] = data
+		}
 	}
 
 	o.FileList = []*asset.File{}
@@ -134,6 +259,7 @@ func (o *Openshift) Generate(dependencies asset.Parents) error {
 		o.FileList = append(o.FileList, &asset.File{
 			Filename: filepath.Join(openshiftManifestDir, name),
 			Data:     data,
+			Mode:     asset.SecretMode(data),
 		})
 	}
 
@@ -3,6 +3,7 @@ package manifests
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
@@ -23,7 +24,8 @@ const (
 )
 
 var (
-	kubeSysConfigPath = filepath.Join(manifestDir, "cluster-config.yaml")
+	kubeSysConfigPath    = filepath.Join(manifestDir, "cluster-config.yaml")
+	apiServerCfgFilename = filepath.Join(manifestDir, "cluster-apiserver-02-config.yml")
 
 	_ asset.WritableAsset = (*Manifests)(nil)
 
@@ -63,6 +65,7 @@ func (m *Manifests) Dependencies() []asset.Asset {
 		&tls.EtcdClientCertKey{},
 		&tls.EtcdMetricsCABundle{},
 		&tls.EtcdMetricsSignerClientCertKey{},
+		&tls.KubeletServingCABundle{},
 		&tls.MCSCertKey{},
 
 		&bootkube.KubeCloudConfig{},
@@ -75,6 +78,7 @@ func (m *Manifests) Dependencies() []asset.Asset {
 		&bootkube.KubeSystemSecretEtcdClient{},
 		&bootkube.OpenshiftConfigSecretEtcdMetricsClient{},
 		&bootkube.OpenshiftConfigConfigmapEtcdMetricsServingCA{},
+		&bootkube.OpenshiftConfigManagedConfigmapKubeletServingCA{},
 
 		&bootkube.OpenshiftMachineConfigOperator{},
 		&bootkube.EtcdServiceKubeSystem{},
@@ -83,7 +87,7 @@ func (m *Manifests) Dependencies() []asset.Asset {
 }
 
 // Generate generates the respective operator config.yml files
-func (m *Manifests) Generate(dependencies asset.Parents) error {
+func (m *Manifests) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ingress := &Ingress{}
 	dns := &DNS{}
 	network := &Networking{}
@@ -92,8 +96,23 @@ func (m *Manifests) Generate(dependencies asset.Parents) error {
 	dependencies.Get(installConfig, ingress, dns, network, infra)
 
 	// mao go to kube-system config map
+	installConfigData := installConfig.Files()[0].Data
+	if t := installConfig.Config.TLS; t != nil && t.RootCA != nil {
+		// The key of the user's root CA never leaves the installer.
+		config := *installConfig.Config
+		tlsConfig := *t
+		rootCA := *t.RootCA
+		rootCA.Key = ""
+		tlsConfig.RootCA = &rootCA
+		config.TLS = &tlsConfig
+		data, err := yaml.Marshal(&config)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the install-config")
+		}
+		installConfigData = data
+	}
 	m.KubeSysConfig = configMap("kube-system", "cluster-config-v1", genericData{
-		"install-config": string(installConfig.Files()[0].Data),
+		"install-config": string(installConfigData),
 	})
 	kubeSysConfigData, err := yaml.Marshal(m.KubeSysConfig)
 	if err != nil {
@@ -106,7 +125,21 @@ func (m *Manifests) Generate(dependencies asset.Parents) error {
 			Data:     kubeSysConfigData,
 		},
 	}
-	m.FileList = append(m.FileList, m.generateBootKubeManifests(dependencies)...)
+	if profile := installConfig.Config.TLSSecurityProfile; profile != nil {
+		data, err := apiServerConfig(profile)
+		if err != nil {
+			return err
+		}
+		m.FileList = append(m.FileList, &asset.File{
+			Filename: apiServerCfgFilename,
+			Data:     data,
+		})
+	}
+	bootKubeFiles, err := m.generateBootKubeManifests(dependencies)
+	if err != nil {
+		return err
+	}
+	m.FileList = append(m.FileList, bootKubeFiles...)
 
 	m.FileList = append(m.FileList, ingress.Files()...)
 	m.FileList = append(m.FileList, dns.Files()...)
@@ -123,7 +156,7 @@ func (m *Manifests) Files() []*asset.File {
 	return m.FileList
 }
 
-func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*asset.File {
+func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) ([]*asset.File, error) {
 	clusterID := &installconfig.ClusterID{}
 	installConfig := &installconfig.InstallConfig{}
 	etcdCA := &tls.EtcdCA{}
@@ -131,6 +164,7 @@ func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*ass
 	etcdClientCertKey := &tls.EtcdClientCertKey{}
 	etcdMetricsCABundle := &tls.EtcdMetricsCABundle{}
 	etcdMetricsSignerClientCertKey := &tls.EtcdMetricsSignerClientCertKey{}
+	kubeletServingCABundle := &tls.KubeletServingCABundle{}
 	rootCA := &tls.RootCA{}
 	dependencies.Get(
 		clusterID,
@@ -139,6 +173,7 @@ func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*ass
 		etcdClientCertKey,
 		etcdMetricsCABundle,
 		etcdMetricsSignerClientCertKey,
+		kubeletServingCABundle,
 		mcsCertKey,
 		rootCA,
 	)
@@ -148,6 +183,11 @@ func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*ass
 		etcdEndpointHostnames[i] = fmt.Sprintf("etcd-%d", i)
 	}
 
+	pullSecret, err := installConfig.Config.MergedPullSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge pull secrets")
+	}
+
 	templateData := &bootkubeTemplateData{
 		Base64encodeCloudProviderConfig: "", // FIXME
 		EtcdCaCert:                      string(etcdCA.Cert()),
@@ -156,11 +196,13 @@ func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*ass
 		EtcdMetricsCaCert:               string(etcdMetricsCABundle.Cert()),
 		EtcdMetricsClientCert:           base64.StdEncoding.EncodeToString(etcdMetricsSignerClientCertKey.Cert()),
 		EtcdMetricsClientKey:            base64.StdEncoding.EncodeToString(etcdMetricsSignerClientCertKey.Key()),
+		KubeletServingCaCert:            string(kubeletServingCABundle.Cert()),
 		McsTLSCert:                      base64.StdEncoding.EncodeToString(mcsCertKey.Cert()),
 		McsTLSKey:                       base64.StdEncoding.EncodeToString(mcsCertKey.Key()),
-		PullSecretBase64:                base64.StdEncoding.EncodeToString([]byte(installConfig.Config.PullSecret)),
+		PullSecretBase64:                base64.StdEncoding.EncodeToString([]byte(pullSecret)),
 		RootCaCert:                      string(rootCA.Cert()),
 		CVOClusterID:                    clusterID.UUID,
+		CVOOverrides:                    capabilityOverrides(installConfig.Config),
 		EtcdEndpointHostnames:           etcdEndpointHostnames,
 		EtcdEndpointDNSSuffix:           installConfig.Config.ClusterDomain(),
 	}
@@ -175,6 +217,7 @@ func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*ass
 	kubeSystemSecretEtcdClient := &bootkube.KubeSystemSecretEtcdClient{}
 	openshiftConfigSecretEtcdMetricsClient := &bootkube.OpenshiftConfigSecretEtcdMetricsClient{}
 	openshiftConfigConfigmapEtcdMetricsServingCA := &bootkube.OpenshiftConfigConfigmapEtcdMetricsServingCA{}
+	openshiftConfigManagedConfigmapKubeletServingCA := &bootkube.OpenshiftConfigManagedConfigmapKubeletServingCA{}
 
 	openshiftMachineConfigOperator := &bootkube.OpenshiftMachineConfigOperator{}
 	etcdServiceKubeSystem := &bootkube.EtcdServiceKubeSystem{}
@@ -190,45 +233,66 @@ func (m *Manifests) generateBootKubeManifests(dependencies asset.Parents) []*ass
 		kubeSystemSecretEtcdClient,
 		openshiftConfigSecretEtcdMetricsClient,
 		openshiftConfigConfigmapEtcdMetricsServingCA,
+		openshiftConfigManagedConfigmapKubeletServingCA,
 		openshiftMachineConfigOperator,
 		etcdServiceKubeSystem,
 		hostEtcdServiceKubeSystem,
 	)
-	assetData := map[string][]byte{
-		"kube-cloud-config.yaml":                                  applyTemplateData(kubeCloudConfig.Files()[0].Data, templateData),
-		"machine-config-server-tls-secret.yaml":                   applyTemplateData(machineConfigServerTLSSecret.Files()[0].Data, templateData),
-		"pull.json":                                               applyTemplateData(pull.Files()[0].Data, templateData),
-		"cvo-overrides.yaml":                                      applyTemplateData(cVOOverrides.Files()[0].Data, templateData),
-		"host-etcd-service-endpoints.yaml":                        applyTemplateData(hostEtcdServiceEndpointsKubeSystem.Files()[0].Data, templateData),
-		"kube-system-configmap-etcd-serving-ca.yaml":              applyTemplateData(kubeSystemConfigmapEtcdServingCA.Files()[0].Data, templateData),
-		"kube-system-configmap-root-ca.yaml":                      applyTemplateData(kubeSystemConfigmapRootCA.Files()[0].Data, templateData),
-		"kube-system-secret-etcd-client.yaml":                     applyTemplateData(kubeSystemSecretEtcdClient.Files()[0].Data, templateData),
-		"openshift-config-secret-etcd-metrics-client.yaml":        applyTemplateData(openshiftConfigSecretEtcdMetricsClient.Files()[0].Data, templateData),
-		"openshift-config-configmap-etcd-metrics-serving-ca.yaml": applyTemplateData(openshiftConfigConfigmapEtcdMetricsServingCA.Files()[0].Data, templateData),
+	templates := map[string]asset.WritableAsset{
+		"kube-cloud-config.yaml":                                     kubeCloudConfig,
+		"machine-config-server-tls-secret.yaml":                      machineConfigServerTLSSecret,
+		"pull.json":                                                  pull,
+		"cvo-overrides.yaml":                                         cVOOverrides,
+		"host-etcd-service-endpoints.yaml":                           hostEtcdServiceEndpointsKubeSystem,
+		"kube-system-configmap-etcd-serving-ca.yaml":                 kubeSystemConfigmapEtcdServingCA,
+		"kube-system-configmap-root-ca.yaml":                         kubeSystemConfigmapRootCA,
+		"kube-system-secret-etcd-client.yaml":                        kubeSystemSecretEtcdClient,
+		"openshift-config-secret-etcd-metrics-client.yaml":           openshiftConfigSecretEtcdMetricsClient,
+		"openshift-config-configmap-etcd-metrics-serving-ca.yaml":    openshiftConfigConfigmapEtcdMetricsServingCA,
+		"openshift-config-managed-configmap-kubelet-serving-ca.yaml": openshiftConfigManagedConfigmapKubeletServingCA,
+	}
 
+	assetData := map[string][]byte{
 		"04-openshift-machine-config-operator.yaml": []byte(openshiftMachineConfigOperator.Files()[0].Data),
 		"etcd-service.yaml":                         []byte(etcdServiceKubeSystem.Files()[0].Data),
 		"host-etcd-service.yaml":                    []byte(hostEtcdServiceKubeSystem.Files()[0].Data),
 	}
+	for name, template := range templates {
+		data, err := applyTemplateData(template, templateData)
+		if err != nil {
+			return nil, err
+		}
+		assetData[name] = data
+	}
 
 	files := make([]*asset.File, 0, len(assetData))
 	for name, data := range assetData {
 		files = append(files, &asset.File{
 			Filename: filepath.Join(manifestDir, name),
 			Data:     data,
+			Mode:     asset.SecretMode(data),
 		})
 	}
 
-	return files
+	return files, nil
 }
 
-func applyTemplateData(data []byte, templateData interface{}) []byte {
-	template := template.Must(template.New("template").Funcs(customTmplFuncs).Parse(string(data)))
+// applyTemplateData renders the file of the template asset with the
+// template data.
+func applyTemplateData(a asset.WritableAsset, templateData interface{}) ([]byte, error) {
+	files := a.Files()
+	if len(files) == 0 {
+		return nil, errors.Errorf("%s has no template", a.Name())
+	}
+	template, err := template.New(files[0].Filename).Funcs(customTmplFuncs).Parse(string(files[0].Data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", a.Name())
+	}
 	buf := &bytes.Buffer{}
 	if err := template.Execute(buf, templateData); err != nil {
-		panic(err)
+		return nil, errors.Wrapf(err, "failed to render %s", a.Name())
 	}
-	return buf.Bytes()
+	return buf.Bytes(), nil
 }
 
 // Load returns the manifests asset from disk.
@@ -0,0 +1,59 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/templates/content/bootkube"
+)
+
+func TestApplyTemplateData(t *testing.T) {
+	cases := []struct {
+		name          string
+		template      string
+		files         bool
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "valid",
+			template: `{"auths": "{{.PullSecretBase64}}"}`,
+			files:    true,
+			expected: `{"auths": "c2VjcmV0"}`,
+		},
+		{
+			name:          "no template",
+			expectedError: `^Pull has no template$`,
+		},
+		{
+			name:          "unparseable",
+			template:      `{{.PullSecretBase64`,
+			files:         true,
+			expectedError: `^failed to parse Pull: template: manifests/pull.json:1: `,
+		},
+		{
+			name:          "unknown field",
+			template:      `{{.PullSecret}}`,
+			files:         true,
+			expectedError: `^failed to render Pull: template: manifests/pull.json:1:2: executing "manifests/pull.json" at <.PullSecret>: `,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pull := &bootkube.Pull{}
+			if tc.files {
+				pull.FileList = []*asset.File{{Filename: "manifests/pull.json", Data: []byte(tc.template)}}
+			}
+			data, err := applyTemplateData(pull, &bootkubeTemplateData{PullSecretBase64: "c2VjcmV0"})
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package manifests
+
+import (
+	"fmt"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// registryMirrorMachineConfig returns a MachineConfig which points CRI-O on
+// the nodes with the given role at the registry mirror, so they pull the
+// release payload over the local network rather than the WAN.
+func registryMirrorMachineConfig(role string, mirror *baremetal.RegistryMirror) ([]byte, error) {
+	config := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+		Storage: igntypes.Storage{
+			Files: []igntypes.File{
+				ignition.FileFromString(ignition.RegistriesConfPath, "root", 0644, ignition.RegistriesConf(mirror)),
+			},
+		},
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": fmt.Sprintf("99-%s-registry-mirror", role),
+			"labels": map[string]string{
+				"machineconfiguration.openshift.io/role": role,
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": config,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s registry mirror MachineConfig", role)
+	}
+	return data, nil
+}
@@ -0,0 +1,71 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/vincent-petithory/dataurl"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestRegistryMirrorMachineConfig(t *testing.T) {
+	data, err := registryMirrorMachineConfig("master", &baremetal.RegistryMirror{
+		Network: baremetal.HostNetwork{Address: "192.168.111.3/24"},
+		Sources: []string{
+			"quay.io/openshift-release-dev/ocp-release",
+			"quay.io/openshift-release-dev/ocp-v4.0-art-dev",
+		},
+		Port: 5000,
+	})
+	assert.NoError(t, err)
+
+	var machineConfig struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Config struct {
+				Storage struct {
+					Files []struct {
+						Path     string `json:"path"`
+						Contents struct {
+							Source string `json:"source"`
+						} `json:"contents"`
+					} `json:"files"`
+				} `json:"storage"`
+			} `json:"config"`
+		} `json:"spec"`
+	}
+	err = yaml.Unmarshal(data, &machineConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "99-master-registry-mirror", machineConfig.Metadata.Name)
+	assert.Equal(t, map[string]string{"machineconfiguration.openshift.io/role": "master"}, machineConfig.Metadata.Labels)
+
+	files := machineConfig.Spec.Config.Storage.Files
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "/etc/containers/registries.conf", files[0].Path)
+		contents, err := dataurl.DecodeString(files[0].Contents.Source)
+		assert.NoError(t, err)
+		assert.Equal(t, `unqualified-search-registries = ["registry.access.redhat.com", "docker.io"]
+
+[[registry]]
+location = "quay.io/openshift-release-dev/ocp-release"
+mirror-by-digest-only = true
+
+[[registry.mirror]]
+location = "192.168.111.3:5000/ocp/release"
+insecure = true
+
+[[registry]]
+location = "quay.io/openshift-release-dev/ocp-v4.0-art-dev"
+mirror-by-digest-only = true
+
+[[registry.mirror]]
+location = "192.168.111.3:5000/ocp/release"
+insecure = true
+`, string(contents.Data))
+	}
+}
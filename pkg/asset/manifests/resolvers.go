@@ -0,0 +1,63 @@
+package manifests
+
+import (
+	"fmt"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// resolversConfPath is the NetworkManager config which sets the nodes'
+// resolvers.
+const resolversConfPath = "/etc/NetworkManager/conf.d/99-kni-dns.conf"
+
+// resolversMachineConfig returns a MachineConfig which points the
+// resolv.conf of the nodes with the given role at the upstream resolvers,
+// instead of those from DHCP.  The cluster's DNS forwards the queries for
+// names outside the cluster to the nodes' resolvers, so it uses them too.
+func resolversMachineConfig(role string, upstreams []string) ([]byte, error) {
+	servers := make([]string, 0, len(upstreams))
+	for _, upstream := range upstreams {
+		address, _, err := types.SplitDNSUpstream(upstream)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, address)
+	}
+
+	config := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+		Storage: igntypes.Storage{
+			Files: []igntypes.File{
+				ignition.FileFromString(resolversConfPath, "root", 0644, fmt.Sprintf(`[global-dns-domain-*]
+servers=%s
+`, strings.Join(servers, ","))),
+			},
+		},
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": fmt.Sprintf("99-%s-resolvers", role),
+			"labels": map[string]string{
+				"machineconfiguration.openshift.io/role": role,
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": config,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s resolvers MachineConfig", role)
+	}
+	return data, nil
+}
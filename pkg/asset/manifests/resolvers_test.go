@@ -0,0 +1,46 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/vincent-petithory/dataurl"
+)
+
+func TestResolversMachineConfig(t *testing.T) {
+	data, err := resolversMachineConfig("worker", []string{"10.0.0.1", "[fd00::1]:53"})
+	assert.NoError(t, err)
+
+	var machineConfig struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Config struct {
+				Storage struct {
+					Files []struct {
+						Path     string `json:"path"`
+						Contents struct {
+							Source string `json:"source"`
+						} `json:"contents"`
+					} `json:"files"`
+				} `json:"storage"`
+			} `json:"config"`
+		} `json:"spec"`
+	}
+	err = yaml.Unmarshal(data, &machineConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "99-worker-resolvers", machineConfig.Metadata.Name)
+	assert.Equal(t, map[string]string{"machineconfiguration.openshift.io/role": "worker"}, machineConfig.Metadata.Labels)
+
+	files := machineConfig.Spec.Config.Storage.Files
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, resolversConfPath, files[0].Path)
+		contents, err := dataurl.DecodeString(files[0].Contents.Source)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "[global-dns-domain-*]\nservers=10.0.0.1,fd00::1\n", string(contents.Data))
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package manifests
 
+import (
+	configv1 "github.com/openshift/api/config/v1"
+)
+
 // AwsCredsSecretData holds encoded credentials and is used to generate cloud-creds secret
 type AwsCredsSecretData struct {
 	Base64encodeAccessKeyID     string
@@ -24,12 +28,14 @@ type bootkubeTemplateData struct {
 	EtcdMetricsCaCert               string
 	EtcdMetricsClientCert           string
 	EtcdMetricsClientKey            string
+	KubeletServingCaCert            string
 	McsTLSCert                      string
 	McsTLSKey                       string
 	PullSecretBase64                string
 	RootCaCert                      string
 	WorkerIgnConfig                 string
 	CVOClusterID                    string
+	CVOOverrides                    []configv1.ComponentOverride
 	EtcdEndpointHostnames           []string
 	EtcdEndpointDNSSuffix           string
 }
@@ -37,4 +43,5 @@ type bootkubeTemplateData struct {
 type openshiftTemplateData struct {
 	CloudCreds                   cloudCredsSecretData
 	Base64EncodedKubeadminPwHash string
+	KubeadminExpiresAt           string
 }
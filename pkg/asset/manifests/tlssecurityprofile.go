@@ -0,0 +1,115 @@
+package manifests
+
+import (
+	"fmt"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// tlsSecurityProfileSpec returns the tlsSecurityProfile of the APIServer
+// and IngressController resources for profile, which sets the field named
+// for the profile's type alongside the type.
+func tlsSecurityProfileSpec(profile *types.TLSSecurityProfile) map[string]interface{} {
+	var spec interface{} = map[string]interface{}{}
+	if profile.Type == types.TLSProfileCustom {
+		spec = profile.Custom
+	}
+	return map[string]interface{}{
+		"type":                                profile.Type,
+		strings.ToLower(string(profile.Type)): spec,
+	}
+}
+
+// apiServerConfig returns the cluster's APIServer config with profile, which
+// the API servers and the machine config server take their TLS settings
+// from.
+func apiServerConfig(profile *types.TLSSecurityProfile) ([]byte, error) {
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "config.openshift.io/v1",
+		"kind":       "APIServer",
+		"metadata": map[string]interface{}{
+			"name": "cluster",
+		},
+		"spec": map[string]interface{}{
+			"tlsSecurityProfile": tlsSecurityProfileSpec(profile),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the APIServer config")
+	}
+	return data, nil
+}
+
+// cryptoPolicy returns the system-wide crypto policy of the nodes closest
+// to profile, which the OpenSSL and GnuTLS services of the host follow.
+func cryptoPolicy(profile *types.TLSSecurityProfile) string {
+	minTLSVersion := types.VersionTLS12
+	switch profile.Type {
+	case types.TLSProfileOld:
+		minTLSVersion = types.VersionTLS10
+	case types.TLSProfileModern:
+		minTLSVersion = types.VersionTLS13
+	case types.TLSProfileCustom:
+		minTLSVersion = profile.Custom.MinTLSVersion
+	}
+	switch minTLSVersion {
+	case types.VersionTLS10, types.VersionTLS11:
+		return "LEGACY"
+	case types.VersionTLS13:
+		return "FUTURE"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// cryptoPolicyMachineConfig returns a MachineConfig which sets the
+// system-wide crypto policy of the nodes with the given role.
+func cryptoPolicyMachineConfig(role string, policy string) ([]byte, error) {
+	enabled := true
+	config := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+		Systemd: igntypes.Systemd{
+			Units: []igntypes.Unit{{
+				Name:    "crypto-policy.service",
+				Enabled: &enabled,
+				Contents: fmt.Sprintf(`[Unit]
+Description=Set the system-wide crypto policy
+Before=kubelet.service crio.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/bin/update-crypto-policies --set %s
+
+[Install]
+WantedBy=multi-user.target
+`, policy),
+			}},
+		},
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": fmt.Sprintf("99-%s-crypto-policy", role),
+			"labels": map[string]string{
+				"machineconfiguration.openshift.io/role": role,
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": config,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s crypto policy MachineConfig", role)
+	}
+	return data, nil
+}
@@ -0,0 +1,53 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestAPIServerConfig(t *testing.T) {
+	data, err := apiServerConfig(&types.TLSSecurityProfile{Type: types.TLSProfileModern})
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: config.openshift.io/v1
+kind: APIServer
+metadata:
+  name: cluster
+spec:
+  tlsSecurityProfile:
+    modern: {}
+    type: Modern
+`, string(data))
+}
+
+func TestCryptoPolicy(t *testing.T) {
+	cases := []struct {
+		profile  *types.TLSSecurityProfile
+		expected string
+	}{
+		{profile: &types.TLSSecurityProfile{Type: types.TLSProfileOld}, expected: "LEGACY"},
+		{profile: &types.TLSSecurityProfile{Type: types.TLSProfileIntermediate}, expected: "DEFAULT"},
+		{profile: &types.TLSSecurityProfile{Type: types.TLSProfileModern}, expected: "FUTURE"},
+		{
+			profile: &types.TLSSecurityProfile{
+				Type:   types.TLSProfileCustom,
+				Custom: &types.CustomTLSProfile{MinTLSVersion: types.VersionTLS11},
+			},
+			expected: "LEGACY",
+		},
+		{
+			profile: &types.TLSSecurityProfile{
+				Type:   types.TLSProfileCustom,
+				Custom: &types.CustomTLSProfile{MinTLSVersion: types.VersionTLS12},
+			},
+			expected: "DEFAULT",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.profile.Type), func(t *testing.T) {
+			assert.Equal(t, tc.expected, cryptoPolicy(tc.profile))
+		})
+	}
+}
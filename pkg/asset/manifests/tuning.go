@@ -0,0 +1,94 @@
+package manifests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// tuningMachineConfig returns a MachineConfig which applies the sysctls and
+// SELinux booleans of a machine pool to the nodes with the given role, or
+// nil if the pool has neither.  The machine-config operator applies it on
+// first boot, before the kubelet starts, so the tuning is in place before
+// any workload is scheduled.
+func tuningMachineConfig(role string, pool *types.MachinePool) ([]byte, error) {
+	if len(pool.Sysctls) == 0 && len(pool.SELinuxBooleans) == 0 {
+		return nil, nil
+	}
+	config := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+	}
+
+	if len(pool.Sysctls) > 0 {
+		keys := make([]string, 0, len(pool.Sysctls))
+		for key := range pool.Sysctls {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var contents strings.Builder
+		for _, key := range keys {
+			fmt.Fprintf(&contents, "%s = %s\n", key, pool.Sysctls[key])
+		}
+		config.Storage.Files = append(config.Storage.Files, ignition.FileFromString(fmt.Sprintf("/etc/sysctl.d/99-%s-tuning.conf", role), "root", 0644, contents.String()))
+	}
+
+	if len(pool.SELinuxBooleans) > 0 {
+		names := make([]string, 0, len(pool.SELinuxBooleans))
+		for name := range pool.SELinuxBooleans {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		settings := make([]string, 0, len(names))
+		for _, name := range names {
+			value := "off"
+			if pool.SELinuxBooleans[name] {
+				value = "on"
+			}
+			settings = append(settings, fmt.Sprintf("%s=%s", name, value))
+		}
+		enabled := true
+		config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+			Name:    fmt.Sprintf("%s-selinux-booleans.service", role),
+			Enabled: &enabled,
+			Contents: fmt.Sprintf(`[Unit]
+Description=Set SELinux booleans of the %s pool
+Before=kubelet.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/sbin/setsebool -P %s
+
+[Install]
+WantedBy=multi-user.target
+`, role, strings.Join(settings, " ")),
+		})
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": fmt.Sprintf("99-%s-tuning", role),
+			"labels": map[string]string{
+				"machineconfiguration.openshift.io/role": role,
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": config,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal the %s tuning MachineConfig", role)
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/vincent-petithory/dataurl"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestTuningMachineConfig(t *testing.T) {
+	data, err := tuningMachineConfig("worker", &types.MachinePool{Name: "worker"})
+	assert.NoError(t, err)
+	assert.Nil(t, data, "unexpected MachineConfig for a pool without tuning")
+
+	data, err = tuningMachineConfig("worker", &types.MachinePool{
+		Name: "worker",
+		Sysctls: map[string]string{
+			"net.netfilter.nf_conntrack_max": "1048576",
+			"net.ipv4.conf.all.rp_filter":    "2",
+		},
+		SELinuxBooleans: map[string]bool{
+			"virt_use_nfs":            false,
+			"container_manage_cgroup": true,
+		},
+	})
+	assert.NoError(t, err)
+
+	var machineConfig struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Config struct {
+				Storage struct {
+					Files []struct {
+						Path     string `json:"path"`
+						Contents struct {
+							Source string `json:"source"`
+						} `json:"contents"`
+					} `json:"files"`
+				} `json:"storage"`
+				Systemd struct {
+					Units []struct {
+						Name     string `json:"name"`
+						Contents string `json:"contents"`
+					} `json:"units"`
+				} `json:"systemd"`
+			} `json:"config"`
+		} `json:"spec"`
+	}
+	err = yaml.Unmarshal(data, &machineConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "99-worker-tuning", machineConfig.Metadata.Name)
+	assert.Equal(t, map[string]string{"machineconfiguration.openshift.io/role": "worker"}, machineConfig.Metadata.Labels)
+
+	files := machineConfig.Spec.Config.Storage.Files
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "/etc/sysctl.d/99-worker-tuning.conf", files[0].Path)
+		contents, err := dataurl.DecodeString(files[0].Contents.Source)
+		assert.NoError(t, err)
+		assert.Equal(t, "net.ipv4.conf.all.rp_filter = 2\nnet.netfilter.nf_conntrack_max = 1048576\n", string(contents.Data))
+	}
+	units := machineConfig.Spec.Config.Systemd.Units
+	if assert.Len(t, units, 1) {
+		assert.Equal(t, "worker-selinux-booleans.service", units[0].Name)
+		assert.Contains(t, units[0].Contents, "ExecStart=/usr/sbin/setsebool -P container_manage_cgroup=on virt_use_nfs=off\n")
+	}
+}
@@ -0,0 +1,109 @@
+package manifests
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+)
+
+const (
+	workloadIssuerFilename = "workload-issuer.yaml"
+)
+
+// WorkloadIssuer is a cert-manager ClusterIssuer of the workload issuing
+// CA, and the Secret of the CA, for platform teams to apply once
+// cert-manager is installed.  It is not applied by the installer, as the
+// cluster has no ClusterIssuer resource until then.
+type WorkloadIssuer struct {
+	File *asset.File
+}
+
+var _ asset.WritableAsset = (*WorkloadIssuer)(nil)
+
+// Dependencies returns the dependencies of the issuer.
+func (a *WorkloadIssuer) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+		&tls.RootCA{},
+		&tls.WorkloadIssuingCA{},
+	}
+}
+
+// Generate generates the issuer if the install config asks for it.
+func (a *WorkloadIssuer) Generate(_ context.Context, dependencies asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	rootCA := &tls.RootCA{}
+	workloadCA := &tls.WorkloadIssuingCA{}
+	dependencies.Get(installConfig, rootCA, workloadCA)
+
+	a.File = nil
+	if t := installConfig.Config.TLS; t == nil || t.WorkloadIssuer == nil {
+		return nil
+	}
+	issuer := installConfig.Config.TLS.WorkloadIssuer
+
+	secret, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "kubernetes.io/tls",
+		"metadata": map[string]interface{}{
+			"name":      issuer.Name,
+			"namespace": issuer.Namespace,
+		},
+		"data": map[string][]byte{
+			"tls.crt": workloadCA.Cert(),
+			"tls.key": workloadCA.Key(),
+			"ca.crt":  rootCA.Cert(),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the workload issuing CA Secret")
+	}
+	clusterIssuer, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "ClusterIssuer",
+		"metadata": map[string]interface{}{
+			"name": issuer.Name,
+		},
+		"spec": map[string]interface{}{
+			"ca": map[string]interface{}{
+				"secretName": issuer.Name,
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the workload ClusterIssuer")
+	}
+
+	a.File = &asset.File{
+		Filename: workloadIssuerFilename,
+		Data:     bytes.Join([][]byte{secret, clusterIssuer}, []byte("---\n")),
+		Mode:     asset.PrivateMode,
+	}
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *WorkloadIssuer) Name() string {
+	return "Workload Issuer"
+}
+
+// Files returns the files generated by the asset.
+func (a *WorkloadIssuer) Files() []*asset.File {
+	if a.File != nil {
+		return []*asset.File{a.File}
+	}
+	return []*asset.File{}
+}
+
+// Load is a no-op because the issuer is regenerated from the certificates
+// in the state file.
+func (a *WorkloadIssuer) Load(asset.FileFetcher) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,77 @@
+package manifests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestWorkloadIssuer(t *testing.T) {
+	installConfig := &installconfig.InstallConfig{Config: &types.InstallConfig{
+		TLS: &types.TLS{WorkloadIssuer: &types.WorkloadIssuer{Name: "apps-ca", Namespace: "cert-manager"}},
+	}}
+
+	rootCA := &tls.RootCA{}
+	parents := asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, &tls.UserRootCA{})
+	if !assert.NoError(t, rootCA.Generate(context.Background(), parents)) {
+		return
+	}
+	parents = asset.Parents{}
+	parents.Add(rootCA, installConfig)
+	workloadCA := &tls.WorkloadIssuingCA{}
+	if !assert.NoError(t, workloadCA.Generate(context.Background(), parents)) {
+		return
+	}
+	parents.Add(workloadCA)
+
+	issuer := &WorkloadIssuer{}
+	if !assert.NoError(t, issuer.Generate(context.Background(), parents)) {
+		return
+	}
+	if !assert.Len(t, issuer.Files(), 1) {
+		return
+	}
+	docs := strings.Split(string(issuer.Files()[0].Data), "---\n")
+	if !assert.Len(t, docs, 2) {
+		return
+	}
+
+	var secret struct {
+		Kind     string
+		Metadata map[string]string
+		Data     map[string][]byte
+	}
+	if assert.NoError(t, yaml.Unmarshal([]byte(docs[0]), &secret)) {
+		assert.Equal(t, "Secret", secret.Kind)
+		assert.Equal(t, map[string]string{"name": "apps-ca", "namespace": "cert-manager"}, secret.Metadata)
+		assert.Equal(t, workloadCA.Cert(), secret.Data["tls.crt"])
+		assert.Equal(t, workloadCA.Key(), secret.Data["tls.key"])
+		assert.Equal(t, rootCA.Cert(), secret.Data["ca.crt"])
+	}
+	assert.Contains(t, docs[1], "kind: ClusterIssuer")
+	assert.Contains(t, docs[1], "secretName: apps-ca")
+
+	cert, err := tls.PemToCertificate(workloadCA.Cert())
+	if assert.NoError(t, err) {
+		assert.True(t, cert.IsCA)
+	}
+
+	installConfig.Config.TLS = nil
+	workloadCA = &tls.WorkloadIssuingCA{}
+	if !assert.NoError(t, workloadCA.Generate(context.Background(), parents)) {
+		return
+	}
+	parents.Add(workloadCA)
+	if assert.NoError(t, issuer.Generate(context.Background(), parents)) {
+		assert.Empty(t, issuer.Files())
+	}
+}
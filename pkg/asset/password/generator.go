@@ -0,0 +1,124 @@
+package password
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// characterSets are the characters of each class.  Letters and digits
+// which are easily confused, like l, O, 0 and 1, are left out, as are
+// symbols which need quoting in shells or YAML.
+var characterSets = map[types.CharacterClass]string{
+	types.CharacterClassLower:  "abcdefghijkmnopqrstuvwxyz",
+	types.CharacterClassUpper:  "ABCDEFGHIJKLMNPQRSTUVWXYZ",
+	types.CharacterClassDigit:  "23456789",
+	types.CharacterClassSymbol: "!%+-=?@^_~",
+}
+
+// DefaultPolicy is the credentials policy used when the install config has
+// none: 5char-5char-5char-5char passwords with letters and digits.
+var DefaultPolicy = types.CredentialsPolicy{
+	Length:           23,
+	CharacterClasses: []types.CharacterClass{types.CharacterClassLower, types.CharacterClassUpper, types.CharacterClassDigit},
+	BcryptCost:       bcrypt.DefaultCost,
+}
+
+// Generator generates passwords, and their hashes, satisfying a
+// credentials policy.  Every credential the installer generates should
+// come from a Generator for the install config's policy.
+type Generator struct {
+	policy  types.CredentialsPolicy
+	sources asset.Sources
+}
+
+// NewGenerator returns a Generator, with the sources of ctx, for policy,
+// or for DefaultPolicy if policy is nil.  The policy must have been
+// defaulted and validated.
+func NewGenerator(ctx context.Context, policy *types.CredentialsPolicy) *Generator {
+	g := &Generator{policy: DefaultPolicy, sources: asset.SourcesFromContext(ctx)}
+	if policy != nil {
+		g.policy = *policy
+	}
+	return g
+}
+
+// Password returns a random password with at least one character of each
+// of the policy's classes.  Passwords without symbols are split into
+// groups of five characters with dashes.
+func (g *Generator) Password() (string, error) {
+	length := g.policy.Length
+	grouped := true
+	sets := make([]string, 0, len(g.policy.CharacterClasses))
+	for _, class := range g.policy.CharacterClasses {
+		sets = append(sets, characterSets[class])
+		if class == types.CharacterClassSymbol {
+			grouped = false
+		}
+	}
+	dash := func(i int) bool {
+		return grouped && i%6 == 5 && i < length-1
+	}
+
+	slots := 0
+	for i := 0; i < length; i++ {
+		if !dash(i) {
+			slots++
+		}
+	}
+
+	chars := make([]byte, 0, slots)
+	for _, set := range sets {
+		c, err := g.pick(set)
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+	}
+	all := strings.Join(sets, "")
+	for len(chars) < slots {
+		c, err := g.pick(all)
+		if err != nil {
+			return "", err
+		}
+		chars = append(chars, c)
+	}
+	for i := len(chars) - 1; i > 0; i-- {
+		j, err := rand.Int(g.sources.Rand(), big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		chars[i], chars[j.Int64()] = chars[j.Int64()], chars[i]
+	}
+
+	password := make([]byte, 0, length)
+	for i := 0; i < length; i++ {
+		if dash(i) {
+			password = append(password, '-')
+			continue
+		}
+		password = append(password, chars[0])
+		chars = chars[1:]
+	}
+	return string(password), nil
+}
+
+// Hash returns the bcrypt hash of password with the policy's cost.
+func (g *Generator) Hash(password string) ([]byte, error) {
+	return g.sources.HashPassword([]byte(password), g.policy.BcryptCost)
+}
+
+// pick returns a random character of set.
+func (g *Generator) pick(set string) (byte, error) {
+	n, err := rand.Int(g.sources.Rand(), big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
@@ -0,0 +1,77 @@
+package password
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestGenerator(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   *types.CredentialsPolicy
+		expected string
+		cost     int
+	}{
+		{
+			name:     "default",
+			expected: `^[a-km-zA-NP-Z2-9]{5}-[a-km-zA-NP-Z2-9]{5}-[a-km-zA-NP-Z2-9]{5}-[a-km-zA-NP-Z2-9]{5}$`,
+			cost:     bcrypt.DefaultCost,
+		},
+		{
+			name: "long grouped",
+			policy: &types.CredentialsPolicy{
+				Length:           12,
+				CharacterClasses: []types.CharacterClass{types.CharacterClassLower, types.CharacterClassDigit},
+				BcryptCost:       4,
+			},
+			expected: `^[a-km-z2-9]{5}-[a-km-z2-9]{6}$`,
+			cost:     4,
+		},
+		{
+			name: "symbols",
+			policy: &types.CredentialsPolicy{
+				Length:           16,
+				CharacterClasses: []types.CharacterClass{types.CharacterClassUpper, types.CharacterClassSymbol},
+				BcryptCost:       5,
+			},
+			expected: `^[A-NP-Z!%+=?@^_~-]{16}$`,
+			cost:     5,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator := NewGenerator(context.Background(), tc.policy)
+			policy := DefaultPolicy
+			if tc.policy != nil {
+				policy = *tc.policy
+			}
+			// Each password must hold a character of every class, so
+			// generate a few to catch an unlucky draw.
+			for i := 0; i < 20; i++ {
+				password, err := generator.Password()
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Regexp(t, tc.expected, password)
+				for _, class := range policy.CharacterClasses {
+					assert.True(t, strings.ContainsAny(password, characterSets[class]), "%q has no %s characters", password, class)
+				}
+			}
+
+			password, err := generator.Password()
+			assert.NoError(t, err)
+			hash, err := generator.Hash(password)
+			assert.NoError(t, err)
+			cost, err := bcrypt.Cost(hash)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.cost, cost)
+			assert.NoError(t, bcrypt.CompareHashAndPassword(hash, []byte(password)))
+		})
+	}
+}
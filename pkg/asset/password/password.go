@@ -1,11 +1,10 @@
 package password
 
 import (
-	"crypto/rand"
-	"math/big"
+	"context"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // KubeadminPassword is the asset for the kubeadmin user password
@@ -16,60 +15,32 @@ type KubeadminPassword struct {
 
 var _ asset.Asset = (*KubeadminPassword)(nil)
 
-// Dependencies returns no dependencies.
+// Dependencies returns the install config, whose credentials policy the
+// password satisfies.
 func (a *KubeadminPassword) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+	}
 }
 
 // Generate the kubeadmin password
-func (a *KubeadminPassword) Generate(asset.Parents) error {
-	err := a.generateRandomPasswordHash(23)
-	if err != nil {
-		return err
-	}
-	return nil
-}
+func (a *KubeadminPassword) Generate(ctx context.Context, parents asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	parents.Get(installConfig)
 
-// generateRandomPasswordHash generates a hash of a random ASCII password
-// 5char-5char-5char-5char
-func (a *KubeadminPassword) generateRandomPasswordHash(length int) error {
-	const (
-		lowerLetters = "abcdefghijkmnopqrstuvwxyz"
-		upperLetters = "ABCDEFGHIJKLMNPQRSTUVWXYZ"
-		digits       = "23456789"
-		all          = lowerLetters + upperLetters + digits
-	)
-	var password string
-	for i := 0; i < length; i++ {
-		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(all))))
+	generator := NewGenerator(ctx, installConfig.Config.CredentialsPolicy)
+	if a.Password == "" {
+		password, err := generator.Password()
 		if err != nil {
 			return err
 		}
-		newchar := string(all[n.Int64()])
-		if password == "" {
-			password = newchar
-		}
-		if i < length-1 {
-			n, err = rand.Int(rand.Reader, big.NewInt(int64(len(password)+1)))
-			if err != nil {
-				return err
-			}
-			j := n.Int64()
-			password = password[0:j] + newchar + password[j:]
-		}
-	}
-	pw := []rune(password)
-	for _, replace := range []int{5, 11, 17} {
-		pw[replace] = '-'
-	}
-	if a.Password == "" {
-		a.Password = string(pw)
+		a.Password = password
 	}
-	bytes, err := bcrypt.GenerateFromPassword([]byte(a.Password), bcrypt.DefaultCost)
+	hash, err := generator.Hash(a.Password)
 	if err != nil {
 		return err
 	}
-	a.PasswordHash = bytes
+	a.PasswordHash = hash
 	return nil
 }
 
@@ -0,0 +1,5 @@
+// Package render generates assets from an install config with given
+// sources, so that the output of two install configs can be compared file
+// by file.  It backs plan-change, and, with the deterministic sources of
+// rendertest, the golden-file tests.
+package render
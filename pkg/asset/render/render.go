@@ -0,0 +1,126 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/pkg/errors"
+	"github.com/vincent-petithory/dataurl"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+)
+
+const (
+	// installConfigFilename is the name of the install config in the
+	// asset directory.
+	installConfigFilename = "install-config.yaml"
+
+	// ignitionSuffix is the suffix of the Ignition configs, which are
+	// expanded for review.
+	ignitionSuffix = ".ign"
+)
+
+// File is a rendered file.
+type File struct {
+	// Asset is the name of the target asset which generated the file.
+	Asset string
+
+	// Data is the contents of the file.
+	Data []byte
+}
+
+// Render generates the targets from the install config, each asset with
+// the sources returned for it, e.g. those of a Replay, and returns their
+// files keyed by path.  Ignition configs are expanded: their storage
+// files are ordered by path and their contents moved to files of their own
+// under <config>.d, and the remainder is indented.
+func Render(ctx context.Context, installConfig []byte, targets [][]asset.WritableAsset, sources func(asset.Asset) asset.Sources) (map[string]File, error) {
+	dir, err := ioutil.TempDir("", "render")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, installConfigFilename), installConfig, 0600); err != nil {
+		return nil, err
+	}
+
+	store, err := assetstore.NewStoreWithSources(dir, sources)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+
+	files := map[string]File{}
+	for _, assets := range targets {
+		for _, target := range assets {
+			a := reflect.New(reflect.TypeOf(target).Elem()).Interface().(asset.WritableAsset)
+			if err := store.Fetch(ctx, a); err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch %s", a.Name())
+			}
+			for _, file := range a.Files() {
+				name := filepath.ToSlash(file.Filename)
+				if !strings.HasSuffix(name, ignitionSuffix) {
+					files[name] = File{Asset: a.Name(), Data: file.Data}
+					continue
+				}
+				expanded, err := expandIgnition(name, file.Data)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to expand %s", name)
+				}
+				for name, data := range expanded {
+					files[name] = File{Asset: a.Name(), Data: data}
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// expandIgnition splits the Ignition config into the config, with
+// stable ordering, and the contents of its storage files.
+func expandIgnition(name string, data []byte) (map[string][]byte, error) {
+	config := &igntypes.Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(config.Storage.Files, func(i, j int) bool {
+		return config.Storage.Files[i].Path < config.Storage.Files[j].Path
+	})
+	sort.SliceStable(config.Systemd.Units, func(i, j int) bool {
+		return config.Systemd.Units[i].Name < config.Systemd.Units[j].Name
+	})
+
+	files := map[string][]byte{}
+	for i := range config.Storage.Files {
+		file := &config.Storage.Files[i]
+		contents, err := dataurl.DecodeString(file.Contents.Source)
+		if err != nil {
+			// Remote sources are left in the config.
+			continue
+		}
+		contentsName := path.Join(name+".d", file.Path)
+		if _, ok := files[contentsName]; ok {
+			contentsName = fmt.Sprintf("%s.%d", contentsName, i)
+		}
+		files[contentsName] = contents.Data
+		file.Contents.Source = "render:" + contentsName
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[name] = append(data, '\n')
+	return files, nil
+}
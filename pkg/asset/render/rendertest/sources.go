@@ -0,0 +1,277 @@
+// Package rendertest provides deterministic sources for rendering assets
+// in tests, e.g. the golden-file tests, so the same install config always
+// renders the same files, run after run.  The keys are derived from
+// math/rand and are not secret, so it must only be imported by tests.
+package rendertest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/big"
+	mathrand "math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+// Epoch is the time from which reproducibly generated certificates are
+// valid.
+var Epoch = time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	keysMu sync.Mutex
+	keys   = map[int64]*rsa.PrivateKey{}
+)
+
+// reproducibleSources replace the random and time-dependent parts of
+// generating an asset with deterministic ones, so the same install config
+// always generates the same assets.  Keys, serial numbers and IDs are
+// seeded by the asset generating them, so an asset's are the same
+// whichever other assets the install config enables.  The environment
+// is empty, so overrides do not change the assets.
+type reproducibleSources struct {
+	// generator is the package-qualified type of the asset.
+	generator string
+
+	mu    sync.Mutex
+	rand  io.Reader
+	keys  int
+	uuids int
+}
+
+var _ asset.Sources = (*reproducibleSources)(nil)
+
+// Sources returns the sources with which a is generated reproducibly.
+func Sources(a asset.Asset) asset.Sources {
+	t := reflect.Indirect(reflect.ValueOf(a)).Type()
+	generator := t.PkgPath() + "." + t.Name()
+	return &reproducibleSources{
+		generator: generator,
+		rand:      seededReader(seed(generator, 0)),
+	}
+}
+
+// Rand returns the asset's random stream.
+func (s *reproducibleSources) Rand() io.Reader {
+	return s
+}
+
+// Read reads from the asset's random stream.
+func (s *reproducibleSources) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Read(p)
+}
+
+// nextKey returns the seed of the asset's next key.
+func (s *reproducibleSources) nextKey() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys++
+	return seed(s.generator, s.keys)
+}
+
+// RSAKey returns the asset's next RSA key.
+func (s *reproducibleSources) RSAKey(bits int) (*rsa.PrivateKey, error) {
+	return reproducibleKey(s.nextKey(), bits)
+}
+
+// ECDSAKey returns the asset's next ECDSA key.
+func (s *reproducibleSources) ECDSAKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	return reproducibleECDSAKey(s.nextKey(), curve), nil
+}
+
+// Signer returns a signer which signs deterministically with ECDSA keys,
+// as crypto/ecdsa picks a random nonce for every signature.  RSA
+// signatures are deterministic already.
+func (s *reproducibleSources) Signer(key crypto.Signer) crypto.Signer {
+	if key, ok := key.(*ecdsa.PrivateKey); ok {
+		return deterministicSigner{key}
+	}
+	return key
+}
+
+// Now returns Epoch.
+func (s *reproducibleSources) Now() time.Time {
+	return Epoch
+}
+
+// HashPassword returns a fixed-cost stand-in for the bcrypt hash, which
+// is salted at random.
+func (s *reproducibleSources) HashPassword(password []byte, cost int) ([]byte, error) {
+	return []byte(fmt.Sprintf("$2a$%02d$%x", cost, sha256.Sum256(password))), nil
+}
+
+// UUID returns the asset's next UUID.
+func (s *reproducibleSources) UUID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uuids++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.uuids)
+}
+
+// RandomString returns n x's.
+func (s *reproducibleSources) RandomString(n int) string {
+	return strings.Repeat("x", n)
+}
+
+// LookupEnv returns false: nothing is set in the environment.
+func (s *reproducibleSources) LookupEnv(string) (string, bool) {
+	return "", false
+}
+
+func seededReader(seed int64) io.Reader {
+	return mathrand.New(mathrand.NewSource(seed))
+}
+
+// seed returns the seed of the index'th random value of the generator.
+func seed(generator string, index int) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s#%d", generator, index)
+	return int64(h.Sum64())
+}
+
+// reproducibleKey returns the RSA key of the given size for the seed.
+// crypto/rsa does not promise the same key for the same random stream, so
+// the primes are picked here.  Keys are cached, as finding primes is slow.
+func reproducibleKey(seed int64, bits int) (*rsa.PrivateKey, error) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if key, ok := keys[seed]; ok && key.N.BitLen() == bits {
+		return key, nil
+	}
+
+	rnd := mathrand.New(mathrand.NewSource(seed))
+	e := big.NewInt(65537)
+	one := big.NewInt(1)
+	for {
+		p, q := prime(rnd, bits/2), prime(rnd, bits-bits/2)
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits {
+			continue
+		}
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(e, phi)
+		if d == nil {
+			continue
+		}
+
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		key.Precompute()
+		if err := key.Validate(); err != nil {
+			return nil, err
+		}
+		keys[seed] = key
+		return key, nil
+	}
+}
+
+// prime returns a random prime of exactly the given number of bits,
+// with the top two set, so the product of two has twice the bits.
+func prime(rnd *mathrand.Rand, bits int) *big.Int {
+	buf := make([]byte, (bits+7)/8)
+	top := uint(bits % 8)
+	if top == 0 {
+		top = 8
+	}
+	for {
+		rnd.Read(buf)
+		buf[0] &= byte(1<<top) - 1
+		if top >= 2 {
+			buf[0] |= 3 << (top - 2)
+		} else {
+			buf[0] |= 1
+			buf[1] |= 0x80
+		}
+		buf[len(buf)-1] |= 1
+		p := new(big.Int).SetBytes(buf)
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+// reproducibleECDSAKey returns the ECDSA key on the curve for the seed.
+func reproducibleECDSAKey(seed int64, curve elliptic.Curve) *ecdsa.PrivateKey {
+	rnd := mathrand.New(mathrand.NewSource(seed))
+	d := scalar(rnd.Read, curve.Params().N)
+	key := &ecdsa.PrivateKey{D: d}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+	return key
+}
+
+// scalar returns a number in [1, n) from read, with negligible bias.
+func scalar(read func([]byte) (int, error), n *big.Int) *big.Int {
+	buf := make([]byte, (n.BitLen()+7)/8+8)
+	read(buf)
+	k := new(big.Int).SetBytes(buf)
+	k.Mod(k, new(big.Int).Sub(n, big.NewInt(1)))
+	return k.Add(k, big.NewInt(1))
+}
+
+// deterministicSigner signs with an ECDSA key with a nonce derived from
+// the key and the digest, like RFC 6979, so the same digest always has
+// the same signature.
+type deterministicSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s deterministicSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s deterministicSigner) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	params := s.key.Curve.Params()
+	n := params.N
+
+	// The digest is truncated to the bit length of the curve's order.
+	e := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - n.BitLen(); excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+
+	for counter := 0; ; counter++ {
+		nonce := func(p []byte) (int, error) {
+			for i := 0; i < len(p); i += sha256.Size {
+				mac := hmac.New(sha256.New, s.key.D.Bytes())
+				fmt.Fprintf(mac, "%x#%d#%d", digest, counter, i)
+				copy(p[i:], mac.Sum(nil))
+			}
+			return len(p), nil
+		}
+		k := scalar(nonce, n)
+
+		x, _ := s.key.Curve.ScalarBaseMult(k.Bytes())
+		r := x.Mod(x, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		sig := new(big.Int).Mul(s.key.D, r)
+		sig.Add(sig, e)
+		sig.Mul(sig, new(big.Int).ModInverse(k, n))
+		sig.Mod(sig, n)
+		if sig.Sign() == 0 {
+			continue
+		}
+		return asn1.Marshal(struct{ R, S *big.Int }{r, sig})
+	}
+}
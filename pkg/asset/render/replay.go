@@ -0,0 +1,239 @@
+package render
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+// Replay records the random and time-dependent inputs of generating
+// assets, taken from the system sources, and replays them whenever the
+// same asset is generated again, so that rendering install configs with
+// the same Replay only changes the files the differences between the
+// install configs change.  The inputs, keys included, are as random as
+// the installer's own, but are only recorded in memory, so a Replay must
+// not outlive the comparison it is made for.  Generating an asset with a
+// key of another size or algorithm generates a new key, recorded too.
+type Replay struct {
+	now time.Time
+
+	mu         sync.Mutex
+	generators map[string]*recording
+	hashes     map[string][]byte
+	signatures map[string][]byte
+}
+
+// recording is what was generated for one asset.
+type recording struct {
+	rand    []byte
+	keys    map[string]crypto.Signer
+	uuids   []string
+	strings map[string]string
+}
+
+// NewReplay returns a Replay which has recorded nothing yet.
+func NewReplay() *Replay {
+	return &Replay{
+		now:        asset.SystemSources.Now(),
+		generators: map[string]*recording{},
+		hashes:     map[string][]byte{},
+		signatures: map[string][]byte{},
+	}
+}
+
+// Sources returns the sources with which a is generated, replaying what
+// was recorded when it was generated before.
+func (r *Replay) Sources(a asset.Asset) asset.Sources {
+	t := reflect.Indirect(reflect.ValueOf(a)).Type()
+	generator := t.PkgPath() + "." + t.Name()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.generators[generator]
+	if !ok {
+		rec = &recording{keys: map[string]crypto.Signer{}, strings: map[string]string{}}
+		r.generators[generator] = rec
+	}
+	return &replaySources{replay: r, recording: rec}
+}
+
+// replaySources are the sources of one generation of an asset.  The
+// counters are how much of the recording has been replayed.
+type replaySources struct {
+	replay    *Replay
+	recording *recording
+
+	read    int
+	keys    int
+	uuids   int
+	strings int
+}
+
+var _ asset.Sources = (*replaySources)(nil)
+
+// Rand returns the asset's random stream.
+func (s *replaySources) Rand() io.Reader {
+	return s
+}
+
+// Read replays the asset's random stream, extending it as needed.
+func (s *replaySources) Read(p []byte) (int, error) {
+	s.replay.mu.Lock()
+	defer s.replay.mu.Unlock()
+	rec := s.recording
+	if missing := s.read + len(p) - len(rec.rand); missing > 0 {
+		more := make([]byte, missing)
+		if _, err := io.ReadFull(asset.SystemSources.Rand(), more); err != nil {
+			return 0, err
+		}
+		rec.rand = append(rec.rand, more...)
+	}
+	n := copy(p, rec.rand[s.read:])
+	s.read += n
+	return n, nil
+}
+
+// key returns the asset's next key of the kind, generating it the first
+// time.
+func (s *replaySources) key(kind string, generate func() (crypto.Signer, error)) (crypto.Signer, error) {
+	s.replay.mu.Lock()
+	defer s.replay.mu.Unlock()
+	s.keys++
+	id := fmt.Sprintf("%d/%s", s.keys, kind)
+	if key, ok := s.recording.keys[id]; ok {
+		return key, nil
+	}
+	key, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	s.recording.keys[id] = key
+	return key, nil
+}
+
+// RSAKey returns the asset's next RSA key.
+func (s *replaySources) RSAKey(bits int) (*rsa.PrivateKey, error) {
+	key, err := s.key(fmt.Sprintf("rsa-%d", bits), func() (crypto.Signer, error) {
+		return asset.SystemSources.RSAKey(bits)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key.(*rsa.PrivateKey), nil
+}
+
+// ECDSAKey returns the asset's next ECDSA key.
+func (s *replaySources) ECDSAKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	key, err := s.key("ecdsa-"+curve.Params().Name, func() (crypto.Signer, error) {
+		return asset.SystemSources.ECDSAKey(curve)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key.(*ecdsa.PrivateKey), nil
+}
+
+// Signer returns a signer which replays the signature of a digest it
+// signed before, as crypto/ecdsa picks a random nonce for every
+// signature.  RSA signatures are deterministic already.
+func (s *replaySources) Signer(key crypto.Signer) crypto.Signer {
+	if key, ok := key.(*ecdsa.PrivateKey); ok {
+		return replaySigner{key: key, replay: s.replay}
+	}
+	return key
+}
+
+// Now returns the time the Replay was created.
+func (s *replaySources) Now() time.Time {
+	return s.replay.now
+}
+
+// HashPassword replays the bcrypt hash of the password, which is salted
+// at random.
+func (s *replaySources) HashPassword(password []byte, cost int) ([]byte, error) {
+	s.replay.mu.Lock()
+	defer s.replay.mu.Unlock()
+	id := fmt.Sprintf("%d/%s", cost, password)
+	if hash, ok := s.replay.hashes[id]; ok {
+		return hash, nil
+	}
+	hash, err := asset.SystemSources.HashPassword(password, cost)
+	if err != nil {
+		return nil, err
+	}
+	s.replay.hashes[id] = hash
+	return hash, nil
+}
+
+// UUID returns the asset's next UUID.
+func (s *replaySources) UUID() string {
+	s.replay.mu.Lock()
+	defer s.replay.mu.Unlock()
+	if s.uuids == len(s.recording.uuids) {
+		s.recording.uuids = append(s.recording.uuids, asset.SystemSources.UUID())
+	}
+	s.uuids++
+	return s.recording.uuids[s.uuids-1]
+}
+
+// RandomString returns the asset's next random string of length n.
+func (s *replaySources) RandomString(n int) string {
+	s.replay.mu.Lock()
+	defer s.replay.mu.Unlock()
+	s.strings++
+	id := fmt.Sprintf("%d/%d", s.strings, n)
+	str, ok := s.recording.strings[id]
+	if !ok {
+		str = asset.SystemSources.RandomString(n)
+		s.recording.strings[id] = str
+	}
+	return str
+}
+
+// LookupEnv returns false: nothing is set in the environment, so
+// overrides do not change the assets.
+func (s *replaySources) LookupEnv(string) (string, bool) {
+	return "", false
+}
+
+// replaySigner signs with an ECDSA key, replaying the signature of a
+// digest signed before.  Signatures are recorded by public key, as keys
+// loaded from files are new objects every time.
+type replaySigner struct {
+	key    *ecdsa.PrivateKey
+	replay *Replay
+}
+
+func (s replaySigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+// Sign signs with crypto/rand rather than the reader it is given, which
+// may be the random stream of the asset being replayed.
+func (s replaySigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	pub := s.key.PublicKey
+	id := fmt.Sprintf("%s/%x/%x/%x", pub.Curve.Params().Name, pub.X, pub.Y, digest)
+	s.replay.mu.Lock()
+	signature, ok := s.replay.signatures[id]
+	s.replay.mu.Unlock()
+	if ok {
+		return signature, nil
+	}
+
+	signature, err := s.key.Sign(asset.SystemSources.Rand(), digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.replay.mu.Lock()
+	s.replay.signatures[id] = signature
+	s.replay.mu.Unlock()
+	return signature, nil
+}
@@ -0,0 +1,50 @@
+package render
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestReplayECDSACertificates(t *testing.T) {
+	generate := func(replay *Replay) ([]byte, []byte) {
+		ctx := asset.NewSourcesContext(context.Background(), replay.Sources(&tls.RootCA{}))
+		caKey, caCert, err := tls.GenerateSelfSignedCertificate(ctx, &tls.CertCfg{
+			Subject:      pkix.Name{CommonName: "test-ca", OrganizationalUnit: []string{"openshift"}},
+			KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+			Validity:     tls.ValidityOneDay,
+			IsCA:         true,
+			KeyAlgorithm: types.KeyAlgorithmECDSAP256,
+		})
+		if !assert.NoError(t, err) {
+			return nil, nil
+		}
+		_, cert, err := tls.GenerateSignedCertificate(ctx, caKey, caCert, &tls.CertCfg{
+			Subject:      pkix.Name{CommonName: "test"},
+			KeyUsages:    x509.KeyUsageDigitalSignature,
+			ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			Validity:     tls.ValidityOneDay,
+		})
+		if !assert.NoError(t, err) {
+			return nil, nil
+		}
+		assert.NoError(t, cert.CheckSignatureFrom(caCert))
+		return caCert.Raw, cert.Raw
+	}
+
+	replay := NewReplay()
+	caCert, cert := generate(replay)
+	againCACert, againCert := generate(replay)
+	assert.Equal(t, caCert, againCACert, "the CA certificate changed")
+	assert.Equal(t, cert, againCert, "the signed certificate changed")
+
+	otherCACert, _ := generate(NewReplay())
+	assert.NotEqual(t, caCert, otherCACert, "another replay reused the key")
+}
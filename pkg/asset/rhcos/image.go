@@ -3,20 +3,21 @@ package rhcos
 
 import (
 	"context"
-	"os"
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 	"github.com/metalkube/kni-installer/pkg/rhcos"
+	"github.com/metalkube/kni-installer/pkg/simulate"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	"github.com/metalkube/kni-installer/pkg/types/none"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
+	"github.com/metalkube/kni-installer/pkg/warnings"
 )
 
 // Image is location of RHCOS image.
@@ -39,9 +40,9 @@ func (i *Image) Dependencies() []asset.Asset {
 }
 
 // Generate the RHCOS image location.
-func (i *Image) Generate(p asset.Parents) error {
-	if oi, ok := os.LookupEnv("OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE"); ok && oi != "" {
-		logrus.Warn("Found override for OS Image. Please be warned, this is not advised")
+func (i *Image) Generate(ctx context.Context, p asset.Parents) error {
+	if oi, ok := asset.SourcesFromContext(ctx).LookupEnv("OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE"); ok && oi != "" {
+		warnings.Warnf(warnings.Skew, "Found override for OS Image. Please be warned, this is not advised")
 		*i = Image(oi)
 		return nil
 	}
@@ -52,18 +53,21 @@ func (i *Image) Generate(p asset.Parents) error {
 
 	var osimage string
 	var err error
-	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	simulated := simulate.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	switch config.Platform.Name() {
 	case aws.Name:
 		osimage, err = rhcos.AMI(ctx, rhcos.DefaultChannel, config.Platform.AWS.Region)
-	case libvirt.Name:
+	case libvirt.Name, baremetal.Name:
+		if simulated {
+			osimage = simulate.OSImage
+			break
+		}
 		osimage, err = rhcos.QEMU(ctx, rhcos.DefaultChannel)
 	case openstack.Name:
 		osimage = "rhcos"
-	case baremetal.Name:
-		osimage, err = rhcos.QEMU(ctx, rhcos.DefaultChannel)
-	case none.Name:
+	case fake.Name, none.Name:
 	default:
 		return errors.New("invalid Platform")
 	}
@@ -0,0 +1,110 @@
+package asset
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pborman/uuid"
+	"golang.org/x/crypto/bcrypt"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// Sources are the random, time-dependent and environment-dependent inputs
+// of asset generation.  Assets take them from the context passed to
+// Generate, so that generation can be made reproducible, e.g. in
+// golden-file tests, without replacing process-wide state.
+type Sources interface {
+	// Rand is the source of random bytes, e.g. of certificate serial
+	// numbers and password characters.
+	Rand() io.Reader
+
+	// RSAKey generates an RSA private key of the given size.
+	RSAKey(bits int) (*rsa.PrivateKey, error)
+
+	// ECDSAKey generates an ECDSA private key on the given curve.
+	ECDSAKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error)
+
+	// Signer returns the signer with which certificates are signed by key.
+	Signer(key crypto.Signer) crypto.Signer
+
+	// Now returns the current time.
+	Now() time.Time
+
+	// HashPassword returns the bcrypt hash of a password at a cost.
+	HashPassword(password []byte, cost int) ([]byte, error)
+
+	// UUID returns a new random UUID.
+	UUID() string
+
+	// RandomString returns a random alphanumeric string of a length.
+	RandomString(n int) string
+
+	// LookupEnv returns the value of an environment variable, and whether
+	// it is set.
+	LookupEnv(name string) (string, bool)
+}
+
+// SystemSources are the sources of the installer: crypto/rand, the
+// system clock and the process environment.
+var SystemSources Sources = systemSources{}
+
+type systemSources struct{}
+
+func (systemSources) Rand() io.Reader {
+	return rand.Reader
+}
+
+func (systemSources) RSAKey(bits int) (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, bits)
+}
+
+func (systemSources) ECDSAKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(curve, rand.Reader)
+}
+
+func (systemSources) Signer(key crypto.Signer) crypto.Signer {
+	return key
+}
+
+func (systemSources) Now() time.Time {
+	return time.Now()
+}
+
+func (systemSources) HashPassword(password []byte, cost int) ([]byte, error) {
+	return bcrypt.GenerateFromPassword(password, cost)
+}
+
+func (systemSources) UUID() string {
+	return uuid.New()
+}
+
+func (systemSources) RandomString(n int) string {
+	return utilrand.String(n)
+}
+
+func (systemSources) LookupEnv(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+type sourcesKey struct{}
+
+// NewSourcesContext returns a copy of ctx from which assets take s.
+func NewSourcesContext(ctx context.Context, s Sources) context.Context {
+	return context.WithValue(ctx, sourcesKey{}, s)
+}
+
+// SourcesFromContext returns the Sources of ctx, or SystemSources if it
+// has none.
+func SourcesFromContext(ctx context.Context) Sources {
+	if s, ok := ctx.Value(sourcesKey{}).(Sources); ok {
+		return s
+	}
+	return SystemSources
+}
@@ -1,10 +1,18 @@
 package asset
 
+import "context"
+
 // Store is a store for the states of assets.
 type Store interface {
 	// Fetch retrieves the state of the given asset, generating it and its
-	// dependencies if necessary.
-	Fetch(Asset) error
+	// dependencies if necessary.  Fetching stops with the context's error
+	// once it is cancelled.
+	Fetch(context.Context, Asset) error
+
+	// LoadFromState retrieves the state of the given asset from the state
+	// file without generating anything.  It returns false if the asset is
+	// not in the state file.
+	LoadFromState(Asset) (found bool, err error)
 
 	// Destroy removes the asset from all its internal state and also from
 	// disk if possible.
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -72,7 +73,7 @@ func TestCreatedAssetsAreNotDirty(t *testing.T) {
 			}
 
 			for _, a := range tc.targets {
-				if err := assetStore.Fetch(a); err != nil {
+				if err := assetStore.Fetch(context.Background(), a); err != nil {
 					t.Fatalf("failed to fetch %q: %v", a.Name(), err)
 				}
 
@@ -88,13 +89,22 @@ func TestCreatedAssetsAreNotDirty(t *testing.T) {
 
 			exists := struct{}{}
 			emptyAssets := map[string]struct{}{
-				"Master Machines": exists, // no files for the 'none' platform
-				"Metadata":        exists, // read-only
+				"Master Machines":                          exists, // no files for the 'none' platform
+				"Metadata":                                 exists, // read-only
+				"Registry Mirror Ignition Config":          exists, // no files without a registry mirror
+				"RHEL Worker User Data":                    exists, // no files without RHEL compute pools
+				"Compute Pool Ignition Configs":            exists, // no files without compute pools with machine config pools
+				"Certificate Audit Log":                    exists, // regenerated from the state file
+				"Firewall Requirements":                    exists, // derived from the install config
+				"DNS Records":                              exists, // derived from the install config
+				"Hardware Inventory":                       exists, // no files without bare metal hosts
+				"Certificate (kube-apiserver-lb-frontend)": exists, // no files without a load balancer frontend name
+				"Workload Issuer":                          exists, // no files without a workload issuer
 			}
 			for _, a := range tc.targets {
 				name := a.Name()
 				newAsset := reflect.New(reflect.TypeOf(a).Elem()).Interface().(asset.WritableAsset)
-				if err := newAssetStore.Fetch(newAsset); err != nil {
+				if err := newAssetStore.Fetch(context.Background(), newAsset); err != nil {
 					t.Fatalf("failed to fetch %q in new store: %v", a.Name(), err)
 				}
 				assetState := newAssetStore.assets[reflect.TypeOf(a)]
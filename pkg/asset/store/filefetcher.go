@@ -1,10 +1,12 @@
 package store
 
 import (
-	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 )
 
 type fileFetcher struct {
@@ -13,11 +15,16 @@ type fileFetcher struct {
 
 // FetchByName returns the file with the given name.
 func (f *fileFetcher) FetchByName(name string) (*asset.File, error) {
-	data, err := ioutil.ReadFile(filepath.Join(f.directory, name))
+	path := filepath.Join(f.directory, name)
+	data, err := fileaudit.ReadFile(path, "asset loaded from the asset directory")
 	if err != nil {
 		return nil, err
 	}
-	return &asset.File{Filename: name, Data: data}, nil
+	file := &asset.File{Filename: name, Data: data, Mode: manifestMode(name, data)}
+	if err := asset.VerifyFile(path, file.FileMode()); err != nil {
+		return nil, err
+	}
+	return file, nil
 }
 
 // FetchByPattern returns the files whose name match the given regexp.
@@ -29,7 +36,7 @@ func (f *fileFetcher) FetchByPattern(pattern string) (files []*asset.File, err e
 
 	files = make([]*asset.File, 0, len(matches))
 	for _, path := range matches {
-		data, err := ioutil.ReadFile(path)
+		data, err := fileaudit.ReadFile(path, "asset loaded from the asset directory")
 		if err != nil {
 			return nil, err
 		}
@@ -38,12 +45,29 @@ func (f *fileFetcher) FetchByPattern(pattern string) (files []*asset.File, err e
 		if err != nil {
 			return nil, err
 		}
-
-		files = append(files, &asset.File{
+		file := &asset.File{
 			Filename: filename,
 			Data:     data,
-		})
+			Mode:     manifestMode(filename, data),
+		}
+		if err := asset.VerifyFile(path, file.FileMode()); err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
 	}
 
 	return files, nil
 }
+
+// manifestMode returns the mode of a loaded manifest holding a Secret, so
+// that it is kept private when the asset is written again, and zero for
+// all other files, including the manifest templates.
+func manifestMode(filename string, data []byte) os.FileMode {
+	switch strings.SplitN(filepath.ToSlash(filename), "/", 2)[0] {
+	case "manifests", "openshift":
+		return asset.SecretMode(data)
+	default:
+		return 0
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -174,3 +175,57 @@ func TestFetchByPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchRemovesPermissiveModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes only mark files read-only on Windows")
+	}
+
+	tempDir, err := ioutil.TempDir("", "kni-install-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	expected := map[string]os.FileMode{
+		"tls/admin.key":          0600,
+		"master.ign":             0640,
+		"manifests/cluster.yaml": 0644,
+		"manifests/secret.yaml":  0600,
+		"install-config.yaml":    0400,
+	}
+	for filename, mode := range map[string]os.FileMode{
+		"tls/admin.key":          0666,
+		"master.ign":             0666,
+		"manifests/cluster.yaml": 0644,
+		"manifests/secret.yaml":  0644,
+		"install-config.yaml":    0400,
+	} {
+		path := filepath.Join(tempDir, filename)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		data := []byte("data")
+		if filename == "manifests/secret.yaml" {
+			data = []byte("apiVersion: v1\nkind: Secret\n")
+		}
+		if err := ioutil.WriteFile(path, data, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := &fileFetcher{directory: tempDir}
+	for filename, mode := range expected {
+		_, err := f.FetchByName(filename)
+		if !assert.NoError(t, err, filename) {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(tempDir, filename))
+		if assert.NoError(t, err, filename) {
+			assert.Equal(t, mode, info.Mode().Perm(), filename)
+		}
+	}
+}
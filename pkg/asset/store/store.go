@@ -1,16 +1,21 @@
 package store
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/timing"
 )
 
 const (
@@ -42,6 +47,9 @@ type assetState struct {
 	// presentOnDisk is true if the asset in on-disk. This is set whether the
 	// asset is sourced from on-disk or not. It is used in purging consumed assets.
 	presentOnDisk bool
+	// previous is the state an appendable asset had, on disk or else in
+	// the state file, which it is extended from when it is regenerated.
+	previous asset.Asset
 }
 
 // storeImpl is the implementation of Store.
@@ -50,6 +58,10 @@ type storeImpl struct {
 	assets          map[reflect.Type]*assetState
 	stateFileAssets map[string]json.RawMessage
 	fileFetcher     asset.FileFetcher
+
+	// sources returns the sources with which an asset is generated.  If
+	// it is nil, assets are generated with the sources of the context.
+	sources func(asset.Asset) asset.Sources
 }
 
 // NewStore returns an asset store that implements the asset.Store interface.
@@ -57,6 +69,17 @@ func NewStore(dir string) (asset.Store, error) {
 	return newStore(dir)
 }
 
+// NewStoreWithSources returns an asset store which generates each asset
+// with the sources returned for it, e.g. reproducible ones.
+func NewStoreWithSources(dir string, sources func(asset.Asset) asset.Sources) (asset.Store, error) {
+	store, err := newStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	store.sources = sources
+	return store, nil
+}
+
 func newStore(dir string) (*storeImpl, error) {
 	store := &storeImpl{
 		directory:   dir,
@@ -70,10 +93,26 @@ func newStore(dir string) (*storeImpl, error) {
 	return store, nil
 }
 
+// StateDigests returns the hex-encoded SHA-256 digest of each asset in the
+// state file in dir, by asset type, identifying the versions of the assets
+// a cluster was installed with.
+func StateDigests(dir string) (map[string]string, error) {
+	store, err := newStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	digests := make(map[string]string, len(store.stateFileAssets))
+	for name, data := range store.stateFileAssets {
+		digest := sha256.Sum256(data)
+		digests[name] = hex.EncodeToString(digest[:])
+	}
+	return digests, nil
+}
+
 // Fetch retrieves the state of the given asset, generating it and its
 // dependencies if necessary.
-func (s *storeImpl) Fetch(a asset.Asset) error {
-	if err := s.fetch(a, ""); err != nil {
+func (s *storeImpl) Fetch(ctx context.Context, a asset.Asset) error {
+	if err := s.fetch(ctx, a, ""); err != nil {
 		return err
 	}
 	if err := s.saveStateFile(); err != nil {
@@ -85,6 +124,18 @@ func (s *storeImpl) Fetch(a asset.Asset) error {
 	return nil
 }
 
+// LoadFromState retrieves the state of the given asset from the state file
+// without generating anything.
+func (s *storeImpl) LoadFromState(a asset.Asset) (bool, error) {
+	if !s.isAssetInState(a) {
+		return false, nil
+	}
+	if err := s.loadAssetFromState(a); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Destroy removes the asset from all its internal state and also from
 // disk if possible.
 func (s *storeImpl) Destroy(a asset.Asset) error {
@@ -121,6 +172,7 @@ func (s *storeImpl) DestroyState() error {
 		}
 		return err
 	}
+	fileaudit.Record(fileaudit.Remove, path, "asset state", nil)
 	return nil
 }
 
@@ -129,13 +181,16 @@ func (s *storeImpl) DestroyState() error {
 func (s *storeImpl) loadStateFile() error {
 	path := filepath.Join(s.directory, stateFileName)
 	assets := map[string]json.RawMessage{}
-	data, err := ioutil.ReadFile(path)
+	data, err := fileaudit.ReadFile(path, "asset state")
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
 		return err
 	}
+	if err := asset.VerifyFile(path, asset.PrivateMode); err != nil {
+		return err
+	}
 	err = json.Unmarshal(data, &assets)
 	if err != nil {
 		return errors.Wrapf(err, "failed to unmarshal state file %q", path)
@@ -183,7 +238,7 @@ func (s *storeImpl) saveStateFile() error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
-	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+	if err := asset.WriteFile(path, data, asset.PrivateMode, "asset state"); err != nil {
 		return err
 	}
 	return nil
@@ -192,7 +247,7 @@ func (s *storeImpl) saveStateFile() error {
 // fetch populates the given asset, generating it and its dependencies if
 // necessary, and returns whether or not the asset had to be regenerated and
 // any errors.
-func (s *storeImpl) fetch(a asset.Asset, indent string) error {
+func (s *storeImpl) fetch(ctx context.Context, a asset.Asset, indent string) error {
 	logrus.Debugf("%sFetching %q...", indent, a.Name())
 
 	assetState, ok := s.assets[reflect.TypeOf(a)]
@@ -217,13 +272,26 @@ func (s *storeImpl) fetch(a asset.Asset, indent string) error {
 	dependencies := a.Dependencies()
 	parents := make(asset.Parents, len(dependencies))
 	for _, d := range dependencies {
-		if err := s.fetch(d, increaseIndent(indent)); err != nil {
+		if err := s.fetch(ctx, d, increaseIndent(indent)); err != nil {
 			return errors.Wrapf(err, "failed to fetch dependency of %q", a.Name())
 		}
 		parents.Add(d)
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	logrus.Debugf("%sGenerating %q...", indent, a.Name())
-	if err := a.Generate(parents); err != nil {
+	stop := timing.Start(ctx, timing.Assets, assetGroup(a))
+	generateCtx := ctx
+	if s.sources != nil {
+		generateCtx = asset.NewSourcesContext(ctx, s.sources(a))
+	}
+	if aa, ok := a.(asset.AppendableAsset); ok && assetState.previous != nil {
+		aa.SetPrevious(assetState.previous)
+	}
+	err := a.Generate(generateCtx, parents)
+	stop()
+	if err != nil {
 		return errors.Wrapf(err, "failed to generate asset %q", a.Name())
 	}
 	assetState.asset = a
@@ -231,6 +299,16 @@ func (s *storeImpl) fetch(a asset.Asset, indent string) error {
 	return nil
 }
 
+// assetGroup returns the package of the asset below pkg/asset, e.g. tls
+// or ignition/bootstrap, which its generation time is reported under.
+func assetGroup(a asset.Asset) string {
+	pkg := reflect.TypeOf(a).Elem().PkgPath()
+	if i := strings.Index(pkg, "/pkg/asset/"); i >= 0 {
+		return pkg[i+len("/pkg/asset/"):]
+	}
+	return pkg[strings.LastIndex(pkg, "/")+1:]
+}
+
 // load loads the asset and all of its ancestors from on-disk and the state file.
 func (s *storeImpl) load(a asset.Asset, indent string) (*assetState, error) {
 	logrus.Debugf("%sLoading %q...", indent, a.Name())
@@ -328,6 +406,17 @@ func (s *storeImpl) load(a asset.Asset, indent string) (*assetState, error) {
 		anyParentsDirty: anyParentsDirty,
 		presentOnDisk:   foundOnDisk,
 	}
+	if _, ok := a.(asset.AppendableAsset); ok {
+		switch {
+		case foundOnDisk:
+			state.previous = onDiskAsset
+		case s.isAssetInState(a):
+			state.previous = reflect.New(reflect.TypeOf(a).Elem()).Interface().(asset.Asset)
+			if err := s.loadAssetFromState(state.previous); err != nil {
+				return nil, errors.Wrapf(err, "failed to load asset %q from state file", a.Name())
+			}
+		}
+	}
 	s.assets[reflect.TypeOf(a)] = state
 	return state, nil
 }
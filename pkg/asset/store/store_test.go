@@ -1,6 +1,8 @@
 package store
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -53,7 +55,7 @@ func (a *testStoreAssetA) Dependencies() []asset.Asset {
 	return dependenciesTestStoreAsset(a)
 }
 
-func (a *testStoreAssetA) Generate(asset.Parents) error {
+func (a *testStoreAssetA) Generate(context.Context, asset.Parents) error {
 	return generateTestStoreAsset(a)
 }
 
@@ -75,7 +77,7 @@ func (a *testStoreAssetB) Dependencies() []asset.Asset {
 	return dependenciesTestStoreAsset(a)
 }
 
-func (a *testStoreAssetB) Generate(asset.Parents) error {
+func (a *testStoreAssetB) Generate(context.Context, asset.Parents) error {
 	return generateTestStoreAsset(a)
 }
 
@@ -97,7 +99,7 @@ func (a *testStoreAssetC) Dependencies() []asset.Asset {
 	return dependenciesTestStoreAsset(a)
 }
 
-func (a *testStoreAssetC) Generate(asset.Parents) error {
+func (a *testStoreAssetC) Generate(context.Context, asset.Parents) error {
 	return generateTestStoreAsset(a)
 }
 
@@ -119,7 +121,7 @@ func (a *testStoreAssetD) Dependencies() []asset.Asset {
 	return dependenciesTestStoreAsset(a)
 }
 
-func (a *testStoreAssetD) Generate(asset.Parents) error {
+func (a *testStoreAssetD) Generate(context.Context, asset.Parents) error {
 	return generateTestStoreAsset(a)
 }
 
@@ -286,7 +288,7 @@ func TestStoreFetch(t *testing.T) {
 					source: generatedSource,
 				}
 			}
-			err = store.Fetch(assets[tc.target])
+			err = store.Fetch(context.Background(), assets[tc.target])
 			assert.NoError(t, err, "error fetching asset")
 			assert.EqualValues(t, tc.expectedGenerationLog, generationLog)
 		})
@@ -380,10 +382,94 @@ func TestStoreFetchOnDiskAssets(t *testing.T) {
 			for _, name := range tc.onDiskAssets {
 				onDiskAssets[reflect.TypeOf(assets[name])] = true
 			}
-			err := store.fetch(assets[tc.target], "")
+			err := store.fetch(context.Background(), assets[tc.target], "")
 			assert.NoError(t, err, "unexpected error")
 			assert.EqualValues(t, tc.expectedGenerationLog, generationLog)
 			assert.Equal(t, tc.expectedDirty, store.assets[reflect.TypeOf(assets[tc.target])].anyParentsDirty)
 		})
 	}
 }
+
+// testStoreAppendableAsset records the entries of its previous state
+// ahead of its own.
+type testStoreAppendableAsset struct {
+	Log      []string
+	previous []string
+}
+
+func (a *testStoreAppendableAsset) Name() string {
+	return "appendable"
+}
+
+func (a *testStoreAppendableAsset) Dependencies() []asset.Asset {
+	return dependenciesTestStoreAsset(a)
+}
+
+func (a *testStoreAppendableAsset) Generate(context.Context, asset.Parents) error {
+	a.Log = append(append([]string{}, a.previous...), "generated")
+	return generateTestStoreAsset(a)
+}
+
+func (a *testStoreAppendableAsset) Files() []*asset.File {
+	return fileTestStoreAsset(a)
+}
+
+func (a *testStoreAppendableAsset) Load(asset.FileFetcher) (bool, error) {
+	if !onDiskAssets[reflect.TypeOf(a)] {
+		return false, nil
+	}
+	a.Log = []string{"on disk"}
+	return true, nil
+}
+
+func (a *testStoreAppendableAsset) SetPrevious(previous asset.Asset) {
+	a.previous = previous.(*testStoreAppendableAsset).Log
+}
+
+func TestStoreFetchAppendableAsset(t *testing.T) {
+	cases := []struct {
+		name        string
+		onDisk      bool
+		inState     bool
+		expectedLog []string
+	}{
+		{
+			name:        "new",
+			expectedLog: []string{"generated"},
+		},
+		{
+			name:        "on disk",
+			onDisk:      true,
+			inState:     true,
+			expectedLog: []string{"on disk", "generated"},
+		},
+		{
+			name:        "in state file",
+			inState:     true,
+			expectedLog: []string{"in state", "generated"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clearAssetBehaviors()
+			store := &storeImpl{
+				assets:          map[reflect.Type]*assetState{},
+				stateFileAssets: map[string]json.RawMessage{},
+			}
+			a := &testStoreAppendableAsset{}
+			b := &testStoreAssetB{}
+			dependencies[reflect.TypeOf(a)] = []asset.Asset{b}
+			// b is on disk, so a is regenerated.
+			onDiskAssets[reflect.TypeOf(b)] = true
+			onDiskAssets[reflect.TypeOf(a)] = tc.onDisk
+			if tc.inState {
+				store.stateFileAssets[reflect.TypeOf(a).String()] = json.RawMessage(`{"Log": ["in state"]}`)
+			}
+
+			err := store.fetch(context.Background(), a, "")
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, []string{"appendable"}, generationLog)
+			assert.Equal(t, tc.expectedLog, a.Log)
+		})
+	}
+}
@@ -25,6 +25,10 @@ var (
 		&machines.Master{},
 		&manifests.Manifests{},
 		&manifests.Openshift{},
+		&installconfig.FirewallRequirements{},
+		&installconfig.DNSRecords{},
+		&installconfig.HardwareInventory{},
+		&manifests.WorkloadIssuer{},
 	}
 
 	// ManifestTemplates are the manifest-templates targeted assets.
@@ -42,6 +46,7 @@ var (
 		&bootkube.HostEtcdServiceKubeSystem{},
 		&bootkube.OpenshiftConfigSecretEtcdMetricsClient{},
 		&bootkube.OpenshiftConfigConfigmapEtcdMetricsServingCA{},
+		&bootkube.OpenshiftConfigManagedConfigmapKubeletServingCA{},
 		&openshift.BindingDiscovery{},
 		&openshift.CloudCredsSecret{},
 		&openshift.KubeadminPasswordSecret{},
@@ -53,15 +58,38 @@ var (
 		&kubeconfig.AdminClient{},
 		&machine.Master{},
 		&machine.Worker{},
+		&machine.PoolWorkers{},
+		&machine.RHELWorker{},
 		&bootstrap.Bootstrap{},
+		&bootstrap.RegistryMirror{},
+		&tls.KubeAPIServerLBFrontendCertKey{},
+		&cluster.CertificateAuditLog{},
+		&installconfig.FirewallRequirements{},
+		&installconfig.DNSRecords{},
+		&installconfig.HardwareInventory{},
+		&manifests.WorkloadIssuer{},
 		&cluster.Metadata{},
 	}
 
+	// IgnitionConfigsByRole are the ignition-configs targeted assets when
+	// only a single role's Ignition config is requested.
+	IgnitionConfigsByRole = map[string][]asset.WritableAsset{
+		"bootstrap": {&bootstrap.Bootstrap{}},
+		"master":    {&machine.Master{}},
+		"worker":    {&machine.Worker{}, &machine.PoolWorkers{}, &machine.RHELWorker{}},
+	}
+
 	// Cluster are the cluster targeted assets.
 	Cluster = []asset.WritableAsset{
 		&cluster.TerraformVariables{},
 		&kubeconfig.AdminClient{},
 		&tls.JournalCertKey{},
+		&tls.KubeAPIServerLBFrontendCertKey{},
+		&cluster.CertificateAuditLog{},
+		&installconfig.FirewallRequirements{},
+		&installconfig.DNSRecords{},
+		&installconfig.HardwareInventory{},
+		&manifests.WorkloadIssuer{},
 		&cluster.Metadata{},
 		&cluster.Cluster{},
 	}
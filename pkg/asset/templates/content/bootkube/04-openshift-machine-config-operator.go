@@ -1,6 +1,7 @@
 package bootkube
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -30,7 +31,7 @@ func (t *OpenshiftMachineConfigOperator) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *OpenshiftMachineConfigOperator) Generate(parents asset.Parents) error {
+func (t *OpenshiftMachineConfigOperator) Generate(ctx context.Context, parents asset.Parents) error {
 	fileName := openshiftMachineConfigOperatorFileName
 	data, err := content.GetBootkubeTemplate(fileName)
 	if err != nil {
@@ -1,6 +1,7 @@
 package bootkube
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -30,7 +31,7 @@ func (t *HostEtcdServiceEndpointsKubeSystem) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *HostEtcdServiceEndpointsKubeSystem) Generate(parents asset.Parents) error {
+func (t *HostEtcdServiceEndpointsKubeSystem) Generate(ctx context.Context, parents asset.Parents) error {
 	fileName := hostEtcdServiceEndpointsKubeSystemFileName
 	data, err := content.GetBootkubeTemplate(fileName)
 	if err != nil {
@@ -1,6 +1,7 @@
 package bootkube
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -30,7 +31,7 @@ func (t *KubeSystemSecretEtcdClient) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *KubeSystemSecretEtcdClient) Generate(parents asset.Parents) error {
+func (t *KubeSystemSecretEtcdClient) Generate(ctx context.Context, parents asset.Parents) error {
 	fileName := kubeSystemSecretEtcdClientFileName
 	data, err := content.GetBootkubeTemplate(fileName)
 	if err != nil {
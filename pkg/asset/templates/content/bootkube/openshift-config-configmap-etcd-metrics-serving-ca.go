@@ -1,6 +1,7 @@
 package bootkube
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -30,7 +31,7 @@ func (t *OpenshiftConfigConfigmapEtcdMetricsServingCA) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *OpenshiftConfigConfigmapEtcdMetricsServingCA) Generate(parents asset.Parents) error {
+func (t *OpenshiftConfigConfigmapEtcdMetricsServingCA) Generate(ctx context.Context, parents asset.Parents) error {
 	fileName := openshiftConfigConfigmapEtcdMetricsServingCAFileName
 	data, err := content.GetBootkubeTemplate(fileName)
 	if err != nil {
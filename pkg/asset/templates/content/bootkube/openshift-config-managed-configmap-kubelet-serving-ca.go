@@ -0,0 +1,65 @@
+package bootkube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/templates/content"
+)
+
+const (
+	openshiftConfigManagedConfigmapKubeletServingCAFileName = "openshift-config-managed-configmap-kubelet-serving-ca.yaml.template"
+)
+
+var _ asset.WritableAsset = (*OpenshiftConfigManagedConfigmapKubeletServingCA)(nil)
+
+// OpenshiftConfigManagedConfigmapKubeletServingCA is the constant to represent contents of openshift-config-managed-configmap-kubelet-serving-ca.yaml.template file.
+type OpenshiftConfigManagedConfigmapKubeletServingCA struct {
+	FileList []*asset.File
+}
+
+// Dependencies returns all of the dependencies directly needed by the asset
+func (t *OpenshiftConfigManagedConfigmapKubeletServingCA) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Name returns the human-friendly name of the asset.
+func (t *OpenshiftConfigManagedConfigmapKubeletServingCA) Name() string {
+	return "OpenshiftConfigManagedConfigmapKubeletServingCA"
+}
+
+// Generate generates the actual files by this asset
+func (t *OpenshiftConfigManagedConfigmapKubeletServingCA) Generate(ctx context.Context, parents asset.Parents) error {
+	fileName := openshiftConfigManagedConfigmapKubeletServingCAFileName
+	data, err := content.GetBootkubeTemplate(fileName)
+	if err != nil {
+		return err
+	}
+	t.FileList = []*asset.File{
+		{
+			Filename: filepath.Join(content.TemplateDir, fileName),
+			Data:     []byte(data),
+		},
+	}
+	return nil
+}
+
+// Files returns the files generated by the asset.
+func (t *OpenshiftConfigManagedConfigmapKubeletServingCA) Files() []*asset.File {
+	return t.FileList
+}
+
+// Load returns the asset from disk.
+func (t *OpenshiftConfigManagedConfigmapKubeletServingCA) Load(f asset.FileFetcher) (bool, error) {
+	file, err := f.FetchByName(filepath.Join(content.TemplateDir, openshiftConfigManagedConfigmapKubeletServingCAFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	t.FileList = []*asset.File{file}
+	return true, nil
+}
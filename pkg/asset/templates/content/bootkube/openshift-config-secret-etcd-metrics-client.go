@@ -1,6 +1,7 @@
 package bootkube
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -30,7 +31,7 @@ func (t *OpenshiftConfigSecretEtcdMetricsClient) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *OpenshiftConfigSecretEtcdMetricsClient) Generate(parents asset.Parents) error {
+func (t *OpenshiftConfigSecretEtcdMetricsClient) Generate(ctx context.Context, parents asset.Parents) error {
 	fileName := openshiftConfigSecretEtcdMetricsClientFileName
 	data, err := content.GetBootkubeTemplate(fileName)
 	if err != nil {
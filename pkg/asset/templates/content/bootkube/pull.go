@@ -1,6 +1,7 @@
 package bootkube
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -30,7 +31,7 @@ func (t *Pull) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *Pull) Generate(parents asset.Parents) error {
+func (t *Pull) Generate(ctx context.Context, parents asset.Parents) error {
 	fileName := pullFileName
 	data, err := content.GetBootkubeTemplate(fileName)
 	if err != nil {
@@ -1,6 +1,7 @@
 package openshift
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -30,7 +31,7 @@ func (t *BindingDiscovery) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *BindingDiscovery) Generate(parents asset.Parents) error {
+func (t *BindingDiscovery) Generate(ctx context.Context, parents asset.Parents) error {
 	fileName := bindingDiscoveryFileName
 	data, err := content.GetOpenshiftTemplate(fileName)
 	if err != nil {
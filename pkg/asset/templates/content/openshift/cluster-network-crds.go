@@ -1,6 +1,7 @@
 package openshift
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -31,7 +32,7 @@ func (t *NetworkCRDs) Name() string {
 }
 
 // Generate generates the actual files by this asset
-func (t *NetworkCRDs) Generate(parents asset.Parents) error {
+func (t *NetworkCRDs) Generate(ctx context.Context, parents asset.Parents) error {
 	data, err := content.GetOpenshiftTemplate(netopCRDfilename)
 	if err != nil {
 		return err
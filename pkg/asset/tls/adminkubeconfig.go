@@ -1,10 +1,12 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // AdminKubeConfigSignerCertKey is a key/cert pair that signs the admin kubeconfig client certs.
@@ -14,21 +16,30 @@ type AdminKubeConfigSignerCertKey struct {
 
 var _ asset.WritableAsset = (*AdminKubeConfigSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the admin-kubeconfig-signer: the
+// ephemeral cluster lifetime, which caps its validity, and the user's root
+// CA, which signs it if one is given.
 func (c *AdminKubeConfigSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *AdminKubeConfigSignerCertKey) Generate(parents asset.Parents) error {
+func (c *AdminKubeConfigSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "admin-kubeconfig-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "admin-kubeconfig-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "admin-kubeconfig-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -52,7 +63,7 @@ func (a *AdminKubeConfigCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *AdminKubeConfigCABundle) Generate(deps asset.Parents) error {
+func (a *AdminKubeConfigCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -66,7 +77,7 @@ func (a *AdminKubeConfigCABundle) Name() string {
 	return "Certificate (admin-kubeconfig-ca-bundle)"
 }
 
-//AdminKubeConfigClientCertKey is the asset that generates the key/cert pair for admin client to apiserver.
+// AdminKubeConfigClientCertKey is the asset that generates the key/cert pair for admin client to apiserver.
 type AdminKubeConfigClientCertKey struct {
 	SignedCertKey
 }
@@ -83,7 +94,7 @@ func (a *AdminKubeConfigClientCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *AdminKubeConfigClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *AdminKubeConfigClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &AdminKubeConfigSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -94,7 +105,7 @@ func (a *AdminKubeConfigClientCertKey) Generate(dependencies asset.Parents) erro
 		Validity:     ValidityTenYears,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "admin-kubeconfig-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "admin-kubeconfig-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -1,10 +1,12 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // AggregatorCA is the asset that generates the aggregator-ca key/cert pair.
@@ -19,19 +21,26 @@ var _ asset.Asset = (*AggregatorCA)(nil)
 // the parent CA, and install config if it depends on the install config for
 // DNS names, etc.
 func (a *AggregatorCA) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *AggregatorCA) Generate(dependencies asset.Parents) error {
+func (a *AggregatorCA) Generate(ctx context.Context, dependencies asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	dependencies.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "aggregator", OrganizationalUnit: []string{"bootkube"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityOneDay,
+		Validity:  ephemeral.Validity(ValidityOneDay),
 		IsCA:      true,
 	}
 
-	return a.SelfSignedCertKey.Generate(cfg, "aggregator-ca")
+	return a.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "aggregator-ca")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -57,7 +66,7 @@ func (a *APIServerProxyCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *APIServerProxyCertKey) Generate(dependencies asset.Parents) error {
+func (a *APIServerProxyCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	aggregatorCA := &AggregatorCA{}
 	dependencies.Get(aggregatorCA)
 
@@ -68,7 +77,7 @@ func (a *APIServerProxyCertKey) Generate(dependencies asset.Parents) error {
 		Validity:     ValidityOneDay,
 	}
 
-	return a.SignedCertKey.Generate(cfg, aggregatorCA, "apiserver-proxy", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, aggregatorCA, "apiserver-proxy", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -83,21 +92,30 @@ type AggregatorSignerCertKey struct {
 
 var _ asset.WritableAsset = (*AggregatorSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the aggregator-signer: the
+// ephemeral cluster lifetime, which caps its validity, and the user's root
+// CA, which signs it if one is given.
 func (c *AggregatorSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *AggregatorSignerCertKey) Generate(parents asset.Parents) error {
+func (c *AggregatorSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "aggregator-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityOneDay,
+		Validity:  ephemeral.Validity(ValidityOneDay),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "aggregator-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "aggregator-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -121,7 +139,7 @@ func (a *AggregatorCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *AggregatorCABundle) Generate(deps asset.Parents) error {
+func (a *AggregatorCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -150,7 +168,7 @@ func (a *AggregatorClientCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *AggregatorClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *AggregatorClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &AggregatorSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -161,7 +179,7 @@ func (a *AggregatorClientCertKey) Generate(dependencies asset.Parents) error {
 		Validity:     ValidityOneDay,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "apiserver-proxy", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "apiserver-proxy", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
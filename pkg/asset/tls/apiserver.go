@@ -1,6 +1,7 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"net"
@@ -29,12 +30,12 @@ func (a *APIServerCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *APIServerCertKey) Generate(dependencies asset.Parents) error {
+func (a *APIServerCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	kubeCA := &KubeCA{}
 	installConfig := &installconfig.InstallConfig{}
 	dependencies.Get(kubeCA, installConfig)
 
-	apiServerAddress, err := cidrhost(installConfig.Config.Networking.ServiceNetwork[0].IPNet, 1)
+	apiServerAddress, err := kubernetesServiceAddress(installConfig.Config)
 	if err != nil {
 		return errors.Wrap(err, "failed to get API Server address from InstallConfig")
 	}
@@ -46,15 +47,16 @@ func (a *APIServerCertKey) Generate(dependencies asset.Parents) error {
 		Validity:     ValidityTenYears,
 		DNSNames: []string{
 			apiAddress(installConfig.Config),
+			apiIntAddress(installConfig.Config),
 			"kubernetes", "kubernetes.default",
 			"kubernetes.default.svc",
 			"kubernetes.default.svc.cluster.local",
 			"localhost",
 		},
-		IPAddresses: []net.IP{net.ParseIP(apiServerAddress), net.ParseIP("127.0.0.1")},
+		IPAddresses: append([]net.IP{net.ParseIP(apiServerAddress), net.ParseIP("127.0.0.1")}, apiIPs(installConfig.Config)...),
 	}
 
-	return a.SignedCertKey.Generate(cfg, kubeCA, "apiserver", AppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, kubeCA, "apiserver", AppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -69,21 +71,30 @@ type KubeAPIServerToKubeletSignerCertKey struct {
 
 var _ asset.WritableAsset = (*KubeAPIServerToKubeletSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the
+// kube-apiserver-to-kubelet-signer: the ephemeral cluster lifetime, which
+// caps its validity, and the user's root CA, which signs it if one is given.
 func (c *KubeAPIServerToKubeletSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *KubeAPIServerToKubeletSignerCertKey) Generate(parents asset.Parents) error {
+func (c *KubeAPIServerToKubeletSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kube-apiserver-to-kubelet-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityOneYear,
+		Validity:  ephemeral.Validity(ValidityOneYear),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "kube-apiserver-to-kubelet-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kube-apiserver-to-kubelet-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -107,7 +118,7 @@ func (a *KubeAPIServerToKubeletCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeAPIServerToKubeletCABundle) Generate(deps asset.Parents) error {
+func (a *KubeAPIServerToKubeletCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -136,7 +147,7 @@ func (a *KubeAPIServerToKubeletClientCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeAPIServerToKubeletClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeAPIServerToKubeletClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &KubeAPIServerToKubeletSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -147,7 +158,7 @@ func (a *KubeAPIServerToKubeletClientCertKey) Generate(dependencies asset.Parent
 		Validity:     ValidityOneYear,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "kube-apiserver-to-kubelet-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kube-apiserver-to-kubelet-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -162,21 +173,30 @@ type KubeAPIServerLocalhostSignerCertKey struct {
 
 var _ asset.WritableAsset = (*KubeAPIServerLocalhostSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the
+// kube-apiserver-localhost-signer: the ephemeral cluster lifetime, which
+// caps its validity, and the user's root CA, which signs it if one is given.
 func (c *KubeAPIServerLocalhostSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *KubeAPIServerLocalhostSignerCertKey) Generate(parents asset.Parents) error {
+func (c *KubeAPIServerLocalhostSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kube-apiserver-localhost-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "kube-apiserver-localhost-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kube-apiserver-localhost-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -200,7 +220,7 @@ func (a *KubeAPIServerLocalhostCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeAPIServerLocalhostCABundle) Generate(deps asset.Parents) error {
+func (a *KubeAPIServerLocalhostCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -225,26 +245,29 @@ var _ asset.Asset = (*KubeAPIServerLocalhostServerCertKey)(nil)
 func (a *KubeAPIServerLocalhostServerCertKey) Dependencies() []asset.Asset {
 	return []asset.Asset{
 		&KubeAPIServerLocalhostSignerCertKey{},
+		&installconfig.InstallConfig{},
 	}
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeAPIServerLocalhostServerCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeAPIServerLocalhostServerCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &KubeAPIServerLocalhostSignerCertKey{}
-	dependencies.Get(ca)
+	installConfig := &installconfig.InstallConfig{}
+	dependencies.Get(ca, installConfig)
+	extraNames, extraIPs := additionalSANs(installConfig.Config)
 
 	cfg := &CertCfg{
 		Subject:      pkix.Name{CommonName: "system:kube-apiserver", Organization: []string{"kube-master"}},
 		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		Validity:     ValidityOneDay,
-		DNSNames: []string{
+		DNSNames: append([]string{
 			"localhost",
-		},
-		IPAddresses: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		}, extraNames...),
+		IPAddresses: append([]net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}, extraIPs...),
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "kube-apiserver-localhost-server", AppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kube-apiserver-localhost-server", AppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -259,21 +282,31 @@ type KubeAPIServerServiceNetworkSignerCertKey struct {
 
 var _ asset.WritableAsset = (*KubeAPIServerServiceNetworkSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the
+// kube-apiserver-service-network-signer: the ephemeral cluster lifetime,
+// which caps its validity, and the user's root CA, which signs it if one is
+// given.
 func (c *KubeAPIServerServiceNetworkSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *KubeAPIServerServiceNetworkSignerCertKey) Generate(parents asset.Parents) error {
+func (c *KubeAPIServerServiceNetworkSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kube-apiserver-service-network-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "kube-apiserver-service-network-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kube-apiserver-service-network-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -297,7 +330,7 @@ func (a *KubeAPIServerServiceNetworkCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeAPIServerServiceNetworkCABundle) Generate(deps asset.Parents) error {
+func (a *KubeAPIServerServiceNetworkCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -327,29 +360,30 @@ func (a *KubeAPIServerServiceNetworkServerCertKey) Dependencies() []asset.Asset
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeAPIServerServiceNetworkServerCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeAPIServerServiceNetworkServerCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &KubeAPIServerServiceNetworkSignerCertKey{}
 	installConfig := &installconfig.InstallConfig{}
 	dependencies.Get(ca, installConfig)
-	serviceAddress, err := cidrhost(installConfig.Config.Networking.ServiceNetwork[0].IPNet, 1)
+	serviceAddress, err := kubernetesServiceAddress(installConfig.Config)
 	if err != nil {
 		return errors.Wrap(err, "failed to get service address for kube-apiserver from InstallConfig")
 	}
+	extraNames, extraIPs := additionalSANs(installConfig.Config)
 
 	cfg := &CertCfg{
 		Subject:      pkix.Name{CommonName: "system:kube-apiserver", Organization: []string{"kube-master"}},
 		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		Validity:     ValidityOneDay,
-		DNSNames: []string{
+		DNSNames: append([]string{
 			"kubernetes", "kubernetes.default",
 			"kubernetes.default.svc",
 			"kubernetes.default.svc.cluster.local",
-		},
-		IPAddresses: []net.IP{net.ParseIP(serviceAddress)},
+		}, extraNames...),
+		IPAddresses: append([]net.IP{net.ParseIP(serviceAddress)}, extraIPs...),
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "kube-apiserver-service-network-server", AppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kube-apiserver-service-network-server", AppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -364,21 +398,30 @@ type KubeAPIServerLBSignerCertKey struct {
 
 var _ asset.WritableAsset = (*KubeAPIServerLBSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the kube-apiserver-lb-signer: the
+// ephemeral cluster lifetime, which caps its validity, and the user's root
+// CA, which signs it if one is given.
 func (c *KubeAPIServerLBSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *KubeAPIServerLBSignerCertKey) Generate(parents asset.Parents) error {
+func (c *KubeAPIServerLBSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kube-apiserver-lb-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "kube-apiserver-lb-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kube-apiserver-lb-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -387,7 +430,8 @@ func (c *KubeAPIServerLBSignerCertKey) Name() string {
 }
 
 // KubeAPIServerLBCABundle is the asset the generates the kube-apiserver-lb-ca-bundle,
-// which contains all the individual client CAs.
+// which contains all the individual client CAs, including those of a
+// re-encrypting load balancer from the install config.
 type KubeAPIServerLBCABundle struct {
 	CertBundle
 }
@@ -398,15 +442,19 @@ var _ asset.Asset = (*KubeAPIServerLBCABundle)(nil)
 func (a *KubeAPIServerLBCABundle) Dependencies() []asset.Asset {
 	return []asset.Asset{
 		&KubeAPIServerLBSignerCertKey{},
+		&installconfig.InstallConfig{},
 	}
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeAPIServerLBCABundle) Generate(deps asset.Parents) error {
-	var certs []CertInterface
-	for _, asset := range a.Dependencies() {
-		deps.Get(asset)
-		certs = append(certs, asset.(CertInterface))
+func (a *KubeAPIServerLBCABundle) Generate(ctx context.Context, deps asset.Parents) error {
+	signer := &KubeAPIServerLBSignerCertKey{}
+	installConfig := &installconfig.InstallConfig{}
+	deps.Get(signer, installConfig)
+
+	certs := []CertInterface{signer}
+	if tls := installConfig.Config.TLS; tls != nil && tls.LoadBalancer != nil {
+		certs = append(certs, pemCerts([]byte(tls.LoadBalancer.ClientCA))...)
 	}
 	return a.CertBundle.Generate("kube-apiserver-lb-ca-bundle", certs...)
 }
@@ -432,22 +480,25 @@ func (a *KubeAPIServerLBServerCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeAPIServerLBServerCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeAPIServerLBServerCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &KubeAPIServerLBSignerCertKey{}
 	installConfig := &installconfig.InstallConfig{}
 	dependencies.Get(ca, installConfig)
+	extraNames, extraIPs := additionalSANs(installConfig.Config)
 
 	cfg := &CertCfg{
 		Subject:      pkix.Name{CommonName: "system:kube-apiserver", Organization: []string{"kube-master"}},
 		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		Validity:     ValidityOneDay,
-		DNSNames: []string{
+		DNSNames: append([]string{
 			apiAddress(installConfig.Config),
-		},
+			apiIntAddress(installConfig.Config),
+		}, extraNames...),
+		IPAddresses: append(apiIPs(installConfig.Config), extraIPs...),
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "kube-apiserver-lb-server", AppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kube-apiserver-lb-server", AppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -455,6 +506,52 @@ func (a *KubeAPIServerLBServerCertKey) Name() string {
 	return "Certificate (kube-apiserver-lb-server)"
 }
 
+// KubeAPIServerLBFrontendCertKey is the asset that generates the serving
+// key/cert pair for the front side of a re-encrypting load balancer.  It is
+// empty unless the install config names the load balancer.
+type KubeAPIServerLBFrontendCertKey struct {
+	SignedCertKey
+}
+
+var _ asset.WritableAsset = (*KubeAPIServerLBFrontendCertKey)(nil)
+
+// Dependencies returns the dependency of the the cert/key pair
+func (a *KubeAPIServerLBFrontendCertKey) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&KubeAPIServerLBSignerCertKey{},
+		&installconfig.InstallConfig{},
+	}
+}
+
+// Generate generates the cert/key pair based on its dependencies.
+func (a *KubeAPIServerLBFrontendCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
+	ca := &KubeAPIServerLBSignerCertKey{}
+	installConfig := &installconfig.InstallConfig{}
+	dependencies.Get(ca, installConfig)
+
+	tls := installConfig.Config.TLS
+	if tls == nil || tls.LoadBalancer == nil || tls.LoadBalancer.FrontendName == "" {
+		return nil
+	}
+
+	// The load balancer's certificate is installed by hand, so it is
+	// not rotated as quickly as the API servers'.
+	cfg := &CertCfg{
+		Subject:      pkix.Name{CommonName: tls.LoadBalancer.FrontendName, Organization: []string{"kube-master"}},
+		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Validity:     ValidityOneYear,
+		DNSNames:     []string{tls.LoadBalancer.FrontendName},
+	}
+
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kube-apiserver-lb-frontend", AppendParent)
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *KubeAPIServerLBFrontendCertKey) Name() string {
+	return "Certificate (kube-apiserver-lb-frontend)"
+}
+
 // KubeAPIServerCompleteCABundle is the asset the generates the kube-apiserver-complete-server-ca-bundle,
 // which contains all the certs that are valid to confirm the kube-apiserver identity.
 type KubeAPIServerCompleteCABundle struct {
@@ -474,7 +571,7 @@ func (a *KubeAPIServerCompleteCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeAPIServerCompleteCABundle) Generate(deps asset.Parents) error {
+func (a *KubeAPIServerCompleteCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -509,7 +606,7 @@ func (a *KubeAPIServerCompleteClientCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeAPIServerCompleteClientCABundle) Generate(deps asset.Parents) error {
+func (a *KubeAPIServerCompleteClientCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -0,0 +1,121 @@
+package tls
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestKubeAPIServerLBReencrypt(t *testing.T) {
+	_, lbCA, err := GenerateSelfSignedCertificate(context.Background(), &CertCfg{
+		Subject:   pkix.Name{CommonName: "lb-ca", OrganizationalUnit: []string{"network"}},
+		KeyUsages: x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  ValidityTenYears,
+		IsCA:      true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	installConfig := &installconfig.InstallConfig{Config: &types.InstallConfig{
+		TLS: &types.TLS{LoadBalancer: &types.LoadBalancerTLS{
+			ClientCA:     string(CertToPem(lbCA)),
+			FrontendName: "api.example.com",
+		}},
+	}}
+
+	signer := &KubeAPIServerLBSignerCertKey{}
+	parents := asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, &UserRootCA{})
+	if !assert.NoError(t, signer.Generate(context.Background(), parents)) {
+		return
+	}
+	parents = asset.Parents{}
+	parents.Add(signer, installConfig)
+
+	bundle := &KubeAPIServerLBCABundle{}
+	if !assert.NoError(t, bundle.Generate(context.Background(), parents)) {
+		return
+	}
+	assert.Len(t, pemCerts(bundle.Cert()), 2)
+	assert.Contains(t, string(bundle.Cert()), string(CertToPem(lbCA)))
+
+	frontend := &KubeAPIServerLBFrontendCertKey{}
+	if !assert.NoError(t, frontend.Generate(context.Background(), parents)) {
+		return
+	}
+	assert.Len(t, frontend.Files(), 2)
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(signer.Cert())
+	cert, err := PemToCertificate(frontend.Cert())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "api.example.com"})
+	assert.NoError(t, err)
+
+	installConfig.Config.TLS = nil
+	frontend = &KubeAPIServerLBFrontendCertKey{}
+	if !assert.NoError(t, frontend.Generate(context.Background(), parents)) {
+		return
+	}
+	assert.Empty(t, frontend.Files())
+}
+
+func TestKubeAPIServerAdditionalSANs(t *testing.T) {
+	installConfig := &installconfig.InstallConfig{Config: &types.InstallConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+		BaseDomain: "example.com",
+		Networking: &types.Networking{
+			ServiceNetwork: []ipnet.IPNet{*ipnet.MustParseCIDR("172.30.0.0/16")},
+		},
+		TLS: &types.TLS{AdditionalSANs: []string{"api.corp.example.com", "192.0.2.10"}},
+	}}
+
+	for _, pair := range []struct {
+		signer asset.Asset
+		server interface {
+			asset.Asset
+			CertInterface
+		}
+	}{
+		{&KubeAPIServerLBSignerCertKey{}, &KubeAPIServerLBServerCertKey{}},
+		{&KubeAPIServerLocalhostSignerCertKey{}, &KubeAPIServerLocalhostServerCertKey{}},
+		{&KubeAPIServerServiceNetworkSignerCertKey{}, &KubeAPIServerServiceNetworkServerCertKey{}},
+	} {
+		parents := asset.Parents{}
+		parents.Add(&installconfig.Ephemeral{}, &UserRootCA{})
+		if !assert.NoError(t, pair.signer.Generate(context.Background(), parents)) {
+			return
+		}
+		parents = asset.Parents{}
+		parents.Add(pair.signer, installConfig)
+		if !assert.NoError(t, pair.server.Generate(context.Background(), parents), pair.server.Name()) {
+			return
+		}
+
+		cert, err := PemToCertificate(pair.server.Cert())
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Contains(t, cert.DNSNames, "api.corp.example.com", pair.server.Name())
+		assert.Contains(t, ipStrings(cert.IPAddresses), "192.0.2.10", pair.server.Name())
+	}
+}
+
+func ipStrings(ips []net.IP) []string {
+	strs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		strs = append(strs, ip.String())
+	}
+	return strs
+}
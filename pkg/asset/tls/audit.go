@@ -0,0 +1,230 @@
+package tls
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/warnings"
+)
+
+// AuditParent identifies the CA that signed an audited certificate.
+type AuditParent struct {
+	// Serial is the hex-encoded serial number of the parent CA, if the
+	// parent was issued by the installer.
+	Serial string `json:"serial,omitempty"`
+	// Subject is the distinguished name of the parent CA.
+	Subject string `json:"subject"`
+}
+
+// AuditEntry is a single line of the certificate audit log.
+type AuditEntry struct {
+	// Name is the human-friendly name of the asset which issued the
+	// certificate.
+	Name string `json:"name"`
+	// Serial is the hex-encoded serial number of the certificate.
+	Serial string `json:"serial"`
+	// Subject is the distinguished name of the certificate.
+	Subject     string    `json:"subject"`
+	DNSNames    []string  `json:"dnsNames,omitempty"`
+	IPAddresses []string  `json:"ipAddresses,omitempty"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+	// Parent is the signing CA.  It is unset for self-signed
+	// certificates.
+	Parent *AuditParent `json:"parent,omitempty"`
+	// Previous is the hex-encoded SHA-256 digest of the previous line
+	// of the log.  It is empty for the first entry.
+	Previous string `json:"previous,omitempty"`
+	// Signature is the root CA's SHA-256 signature over the entry
+	// marshalled without its signature: PKCS #1 v1.5 for an RSA root CA,
+	// and ASN.1 ECDSA for an ECDSA one.
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// AppendAuditLog records the certificates which are not in the log yet,
+// in order, so each CA must come ahead of the certificates it signs, and
+// returns the extended log along with the digest of its last line.  The
+// log is signed by the root CA, so if the log does not verify against it,
+// e.g. because the root CA was regenerated, a new log is started.
+func AppendAuditLog(log []byte, rootCA *RootCA, certKeys []CertInterface) ([]byte, string, error) {
+	key, err := PemToPrivateKey(rootCA.Key())
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse the root CA private key")
+	}
+	rootCert, err := PemToCertificate(rootCA.Cert())
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse the root CA certificate")
+	}
+
+	recorded := map[string]bool{}
+	var previous string
+	if len(log) > 0 {
+		entries, head, err := VerifyAuditLog(log, rootCert)
+		if err != nil {
+			warnings.Warnf(warnings.Other, "Starting a new certificate audit log, as the previous one does not verify against the root CA: %v", err)
+			log = nil
+		} else {
+			for _, entry := range entries {
+				recorded[entry.Serial] = true
+			}
+			previous = head
+		}
+	}
+
+	serials := map[string]string{}
+	var buf bytes.Buffer
+	buf.Write(log)
+	for _, certKey := range certKeys {
+		if len(certKey.Cert()) == 0 {
+			// The certificate is optional and was not issued.
+			continue
+		}
+		crt, err := PemToCertificate(certKey.Cert())
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to parse the %s", auditName(certKey))
+		}
+
+		entry := newAuditEntry(auditName(certKey), crt, serials)
+		serials[string(crt.SubjectKeyId)] = entry.Serial
+		if recorded[entry.Serial] {
+			continue
+		}
+
+		entry.Previous = previous
+		line, err := signAuditEntry(entry, key)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to record the %s", auditName(certKey))
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+		previous = auditDigest(line)
+		recorded[entry.Serial] = true
+	}
+	return buf.Bytes(), previous, nil
+}
+
+// auditName returns the human-friendly name of the certificate's asset.
+func auditName(certKey CertInterface) string {
+	if a, ok := certKey.(asset.Asset); ok {
+		return a.Name()
+	}
+	return "certificate"
+}
+
+// newAuditEntry describes the certificate.  serials maps the subject
+// key IDs of the CAs already recorded to their serials, so the
+// entry's parent may be identified by more than its subject.
+func newAuditEntry(name string, crt *x509.Certificate, serials map[string]string) *AuditEntry {
+	entry := &AuditEntry{
+		Name:      name,
+		Serial:    crt.SerialNumber.Text(16),
+		Subject:   crt.Subject.String(),
+		DNSNames:  crt.DNSNames,
+		NotBefore: crt.NotBefore.UTC(),
+		NotAfter:  crt.NotAfter.UTC(),
+	}
+	for _, ip := range crt.IPAddresses {
+		entry.IPAddresses = append(entry.IPAddresses, ip.String())
+	}
+	if !bytes.Equal(crt.RawIssuer, crt.RawSubject) {
+		entry.Parent = &AuditParent{
+			Serial:  serials[string(crt.AuthorityKeyId)],
+			Subject: crt.Issuer.String(),
+		}
+	}
+	return entry
+}
+
+// signAuditEntry signs the entry and returns it as a log line, without
+// the trailing newline.
+func signAuditEntry(entry *AuditEntry, key crypto.Signer) ([]byte, error) {
+	entry.Signature = nil
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(data)
+	entry.Signature, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entry)
+}
+
+func auditDigest(line []byte) string {
+	digest := sha256.Sum256(line)
+	return hex.EncodeToString(digest[:])
+}
+
+// AuditHead returns the hex-encoded SHA-256 digest of the last line of
+// the log, or an empty string if the log is empty.
+func AuditHead(log []byte) string {
+	log = bytes.TrimSuffix(log, []byte("\n"))
+	if len(log) == 0 {
+		return ""
+	}
+	return auditDigest(log[bytes.LastIndexByte(log, '\n')+1:])
+}
+
+// VerifyAuditLog checks the signature and chaining of every line of
+// the log against the root CA certificate, and returns the entries
+// along with the digest of the last line.
+func VerifyAuditLog(data []byte, rootCA *x509.Certificate) ([]*AuditEntry, string, error) {
+	var algorithm x509.SignatureAlgorithm
+	switch rootCA.PublicKey.(type) {
+	case *rsa.PublicKey:
+		algorithm = x509.SHA256WithRSA
+	case *ecdsa.PublicKey:
+		algorithm = x509.ECDSAWithSHA256
+	default:
+		return nil, "", errors.New("the root CA has neither an RSA nor an ECDSA public key")
+	}
+
+	var entries []*AuditEntry
+	var previous string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	for i := 1; scanner.Scan(); i++ {
+		line := scanner.Bytes()
+		entry := &AuditEntry{}
+		if err := json.Unmarshal(line, entry); err != nil {
+			return nil, "", errors.Wrapf(err, "line %d", i)
+		}
+		if entry.Previous != previous {
+			return nil, "", errors.Errorf("line %d: does not follow the previous line", i)
+		}
+
+		signature := entry.Signature
+		entry.Signature = nil
+		unsigned, err := json.Marshal(entry)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "line %d", i)
+		}
+		if err := rootCA.CheckSignature(algorithm, unsigned, signature); err != nil {
+			return nil, "", errors.Wrapf(err, "line %d: invalid signature", i)
+		}
+		entry.Signature = signature
+
+		entries = append(entries, entry)
+		previous = auditDigest(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+	return entries, previous, nil
+}
@@ -0,0 +1,148 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+)
+
+func TestAuditLog(t *testing.T) {
+	rootCA := &RootCA{}
+	parents := asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, &UserRootCA{})
+	if !assert.NoError(t, rootCA.Generate(context.Background(), parents)) {
+		return
+	}
+
+	journal := &JournalCertKey{}
+	parents = asset.Parents{}
+	parents.Add(rootCA)
+	if !assert.NoError(t, journal.Generate(context.Background(), parents)) {
+		return
+	}
+
+	data, head, err := AppendAuditLog(nil, rootCA, []CertInterface{rootCA, journal})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	caCert, err := PemToCertificate(rootCA.Cert())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	entries, verifiedHead, err := VerifyAuditLog(data, caCert)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, head, verifiedHead)
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+
+	assert.Equal(t, "Root CA", entries[0].Name)
+	assert.Equal(t, "CN=root-ca,OU=openshift", entries[0].Subject)
+	assert.Nil(t, entries[0].Parent)
+	assert.Empty(t, entries[0].Previous)
+
+	assert.Equal(t, "Certificate (journal-gatewayd)", entries[1].Name)
+	assert.Equal(t, &AuditParent{Serial: entries[0].Serial, Subject: entries[0].Subject}, entries[1].Parent)
+	assert.Equal(t, auditDigest(bytes.SplitN(data, []byte("\n"), 2)[0]), entries[1].Previous)
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	cases := []struct {
+		name          string
+		data          []byte
+		expectedError string
+	}{
+		{
+			name:          "reordered",
+			data:          bytes.Join([][]byte{lines[1], lines[0]}, nil),
+			expectedError: "^line 1: does not follow the previous line$",
+		},
+		{
+			name:          "truncated",
+			data:          lines[1],
+			expectedError: "^line 1: does not follow the previous line$",
+		},
+		{
+			name:          "modified",
+			data:          bytes.Replace(data, []byte("journal-gatewayd"), []byte("journal-gatewayx"), 1),
+			expectedError: "^line 2: invalid signature: ",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := VerifyAuditLog(tc.data, caCert)
+			assert.Regexp(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestAppendAuditLog(t *testing.T) {
+	rootCA := &RootCA{}
+	parents := asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, &UserRootCA{})
+	if !assert.NoError(t, rootCA.Generate(context.Background(), parents)) {
+		return
+	}
+
+	journal := &JournalCertKey{}
+	parents = asset.Parents{}
+	parents.Add(rootCA)
+	if !assert.NoError(t, journal.Generate(context.Background(), parents)) {
+		return
+	}
+
+	data, head, err := AppendAuditLog(nil, rootCA, []CertInterface{rootCA, journal})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Certificates which are already recorded are not recorded again.
+	unchanged, unchangedHead, err := AppendAuditLog(data, rootCA, []CertInterface{rootCA, journal})
+	if assert.NoError(t, err) {
+		assert.Equal(t, data, unchanged)
+		assert.Equal(t, head, unchangedHead)
+	}
+
+	// A regenerated certificate is appended, after its signer's entry.
+	reissued := &JournalCertKey{}
+	if !assert.NoError(t, reissued.Generate(context.Background(), parents)) {
+		return
+	}
+	appended, appendedHead, err := AppendAuditLog(data, rootCA, []CertInterface{rootCA, reissued})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, bytes.HasPrefix(appended, data), "the previous entries changed")
+
+	caCert, err := PemToCertificate(rootCA.Cert())
+	if !assert.NoError(t, err) {
+		return
+	}
+	entries, verifiedHead, err := VerifyAuditLog(appended, caCert)
+	if !assert.NoError(t, err) || !assert.Len(t, entries, 3) {
+		return
+	}
+	assert.Equal(t, appendedHead, verifiedHead)
+	assert.Equal(t, head, entries[2].Previous)
+	assert.Equal(t, &AuditParent{Serial: entries[0].Serial, Subject: entries[0].Subject}, entries[2].Parent)
+
+	// A log signed by another root CA is replaced.
+	otherRootCA := &RootCA{}
+	parents = asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, &UserRootCA{})
+	if !assert.NoError(t, otherRootCA.Generate(context.Background(), parents)) {
+		return
+	}
+	replaced, _, err := AppendAuditLog(data, otherRootCA, []CertInterface{otherRootCA})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, bytes.Count(replaced, []byte("\n")))
+	}
+}
@@ -54,3 +54,27 @@ func (b *CertBundle) Files() []*asset.File {
 func (b *CertBundle) Load(asset.FileFetcher) (bool, error) {
 	return false, nil
 }
+
+// pemCert is a PEM certificate which is not an asset, e.g. one given in
+// the install-config.
+type pemCert []byte
+
+// Cert returns the certificate.
+func (c pemCert) Cert() []byte {
+	return c
+}
+
+// pemCerts splits a PEM bundle into its certificates.
+func pemCerts(data []byte) []CertInterface {
+	var certs []CertInterface
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, pemCert(pem.EncodeToMemory(block)))
+		}
+	}
+}
@@ -2,7 +2,8 @@ package tls
 
 import (
 	"bytes"
-	"crypto/rsa"
+	"context"
+	"crypto"
 	"crypto/x509"
 
 	"github.com/pkg/errors"
@@ -87,18 +88,19 @@ type SignedCertKey struct {
 
 // Generate generates a cert/key pair signed by the specified parent CA.
 func (c *SignedCertKey) Generate(
+	ctx context.Context,
 	cfg *CertCfg,
 	parentCA CertKeyInterface,
 	filenameBase string,
 	appendParent AppendParentChoice,
 ) error {
-	var key *rsa.PrivateKey
+	var key crypto.Signer
 	var crt *x509.Certificate
 	var err error
 
 	caKey, err := PemToPrivateKey(parentCA.Key())
 	if err != nil {
-		return errors.Wrap(err, "failed to parse rsa private key")
+		return errors.Wrap(err, "failed to parse private key")
 	}
 
 	caCert, err := PemToCertificate(parentCA.Cert())
@@ -106,7 +108,7 @@ func (c *SignedCertKey) Generate(
 		return errors.Wrap(err, "failed to parse x509 certificate")
 	}
 
-	key, crt, err = GenerateSignedCertificate(caKey, caCert, cfg)
+	key, crt, err = GenerateSignedCertificate(ctx, caKey, caCert, cfg)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate signed cert/key pair")
 	}
@@ -130,10 +132,11 @@ type SelfSignedCertKey struct {
 
 // Generate generates a cert/key pair signed by the specified parent CA.
 func (c *SelfSignedCertKey) Generate(
+	ctx context.Context,
 	cfg *CertCfg,
 	filenameBase string,
 ) error {
-	key, crt, err := GenerateSelfSignedCertificate(cfg)
+	key, crt, err := GenerateSelfSignedCertificate(ctx, cfg)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate self-signed cert/key pair")
 	}
@@ -145,3 +148,32 @@ func (c *SelfSignedCertKey) Generate(
 
 	return nil
 }
+
+// GenerateSigner generates the cert/key pair of a signer, which is signed
+// by the user's root CA if the install-config has one and is self-signed
+// otherwise.  Its key has the install-config's algorithm and size, which
+// the certificates it signs inherit.
+func (c *SelfSignedCertKey) GenerateSigner(
+	ctx context.Context,
+	cfg *CertCfg,
+	userRoot *UserRootCA,
+	filenameBase string,
+) error {
+	if cfg.KeyAlgorithm == "" {
+		cfg.KeyAlgorithm = userRoot.KeyAlgorithm
+	}
+	if cfg.KeySize == 0 {
+		cfg.KeySize = userRoot.KeySize
+	}
+	if len(userRoot.Cert()) == 0 {
+		return c.Generate(ctx, cfg, filenameBase)
+	}
+
+	signed := &SignedCertKey{}
+	if err := signed.Generate(ctx, cfg, userRoot, filenameBase, DoNotAppendParent); err != nil {
+		return errors.Wrap(err, "failed to generate a signer under the user's root CA")
+	}
+	c.CertKey = signed.CertKey
+
+	return nil
+}
@@ -1,12 +1,19 @@
 package tls
 
 import (
+	"context"
+	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
 	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/types"
 )
 
 func TestSignedCertKeyGenerate(t *testing.T) {
@@ -45,11 +52,13 @@ func TestSignedCertKeyGenerate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rootCA := &RootCA{}
-			err := rootCA.Generate(nil)
+			rootCAParents := asset.Parents{}
+			rootCAParents.Add(&installconfig.Ephemeral{}, &UserRootCA{})
+			err := rootCA.Generate(context.Background(), rootCAParents)
 			assert.NoError(t, err, "failed to generate root CA")
 
 			certKey := &SignedCertKey{}
-			err = certKey.Generate(tt.certCfg, rootCA, tt.filenameBase, tt.appendParent)
+			err = certKey.Generate(context.Background(), tt.certCfg, rootCA, tt.filenameBase, tt.appendParent)
 			if err != nil {
 				assert.EqualErrorf(t, err, tt.errString, tt.name)
 				return
@@ -88,3 +97,108 @@ func TestSignedCertKeyGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestSignerUnderUserRootCA(t *testing.T) {
+	key, crt, err := GenerateSelfSignedCertificate(context.Background(), &CertCfg{
+		Subject:   pkix.Name{CommonName: "corporate-root", OrganizationalUnit: []string{"pki"}},
+		KeyUsages: x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  ValidityTenYears,
+		IsCA:      true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	keyData, err := x509.MarshalPKCS8PrivateKey(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	userRoot := &UserRootCA{CertKey: CertKey{
+		CertRaw: CertToPem(crt),
+		KeyRaw:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyData}),
+	}}
+
+	rootCA := &RootCA{}
+	parents := asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, userRoot)
+	if !assert.NoError(t, rootCA.Generate(context.Background(), parents)) {
+		return
+	}
+
+	cert, err := PemToCertificate(rootCA.Cert())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, cert.IsCA)
+	assert.Equal(t, crt.Subject.String(), cert.Issuer.String())
+	assert.NotEqual(t, crt.SubjectKeyId, cert.SubjectKeyId)
+	assert.Len(t, rootCA.Files(), 2)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(crt)
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	assert.NoError(t, err)
+}
+
+func TestSignerKeyAlgorithm(t *testing.T) {
+	rootCA := &RootCA{}
+	parents := asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, &UserRootCA{KeyAlgorithm: types.KeyAlgorithmECDSAP384})
+	if !assert.NoError(t, rootCA.Generate(context.Background(), parents)) {
+		return
+	}
+	key, err := PemToPrivateKey(rootCA.Key())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, types.KeyAlgorithmECDSAP384, keyAlgorithm(key))
+
+	// The certificates the signer signs inherit its algorithm.
+	journal := &JournalCertKey{}
+	parents = asset.Parents{}
+	parents.Add(rootCA)
+	if !assert.NoError(t, journal.Generate(context.Background(), parents)) {
+		return
+	}
+	key, err = PemToPrivateKey(journal.Key())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, types.KeyAlgorithmECDSAP384, keyAlgorithm(key))
+
+	data, head, err := AppendAuditLog(nil, rootCA, []CertInterface{rootCA, journal})
+	if !assert.NoError(t, err) {
+		return
+	}
+	caCert, err := PemToCertificate(rootCA.Cert())
+	if !assert.NoError(t, err) {
+		return
+	}
+	entries, verifiedHead, err := VerifyAuditLog(data, caCert)
+	if assert.NoError(t, err) {
+		assert.Len(t, entries, 2)
+		assert.Equal(t, head, verifiedHead)
+	}
+}
+
+func TestSignerKeySize(t *testing.T) {
+	rootCA := &RootCA{}
+	parents := asset.Parents{}
+	parents.Add(&installconfig.Ephemeral{}, &UserRootCA{KeySize: 3072})
+	if !assert.NoError(t, rootCA.Generate(context.Background(), parents)) {
+		return
+	}
+
+	// The certificates the signer signs inherit its key size.
+	journal := &JournalCertKey{}
+	parents = asset.Parents{}
+	parents.Add(rootCA)
+	if !assert.NoError(t, journal.Generate(context.Background(), parents)) {
+		return
+	}
+	for _, data := range [][]byte{rootCA.Key(), journal.Key()} {
+		key, err := PemToPrivateKey(data)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 3072, key.(*rsa.PrivateKey).N.BitLen())
+		}
+	}
+}
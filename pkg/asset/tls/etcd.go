@@ -1,10 +1,12 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // EtcdCA is the asset that generates the etcd-ca key/cert pair.
@@ -19,19 +21,26 @@ var _ asset.Asset = (*EtcdCA)(nil)
 // the parent CA, and install config if it depends on the install config for
 // DNS names, etc.
 func (a *EtcdCA) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *EtcdCA) Generate(dependencies asset.Parents) error {
+func (a *EtcdCA) Generate(ctx context.Context, dependencies asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	dependencies.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "etcd", OrganizationalUnit: []string{"etcd"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return a.SelfSignedCertKey.Generate(cfg, "etcd-client-ca")
+	return a.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "etcd-client-ca")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -57,7 +66,7 @@ func (a *EtcdClientCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *EtcdClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *EtcdClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	etcdCA := &EtcdCA{}
 	dependencies.Get(etcdCA)
 
@@ -68,7 +77,7 @@ func (a *EtcdClientCertKey) Generate(dependencies asset.Parents) error {
 		Validity:     ValidityTenYears,
 	}
 
-	return a.SignedCertKey.Generate(cfg, etcdCA, "etcd-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, etcdCA, "etcd-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -83,21 +92,30 @@ type EtcdSignerCertKey struct {
 
 var _ asset.WritableAsset = (*EtcdSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the etcd-signer: the ephemeral
+// cluster lifetime, which caps its validity, and the user's root CA, which
+// signs it if one is given.
 func (c *EtcdSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *EtcdSignerCertKey) Generate(parents asset.Parents) error {
+func (c *EtcdSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "etcd-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "etcd-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "etcd-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -121,7 +139,7 @@ func (a *EtcdCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *EtcdCABundle) Generate(deps asset.Parents) error {
+func (a *EtcdCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -152,7 +170,7 @@ func (a *EtcdSignerClientCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *EtcdSignerClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *EtcdSignerClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &EtcdSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -163,7 +181,7 @@ func (a *EtcdSignerClientCertKey) Generate(dependencies asset.Parents) error {
 		Validity:     ValidityTenYears,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "etcd-signer-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "etcd-signer-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
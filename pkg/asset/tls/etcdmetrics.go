@@ -1,10 +1,12 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // EtcdMetricsSignerCertKey is a key/cert pair that signs the etcd-metrics client and peer certs.
@@ -14,21 +16,30 @@ type EtcdMetricsSignerCertKey struct {
 
 var _ asset.WritableAsset = (*EtcdMetricsSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the etcd-metrics-signer: the
+// ephemeral cluster lifetime, which caps its validity, and the user's root
+// CA, which signs it if one is given.
 func (c *EtcdMetricsSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *EtcdMetricsSignerCertKey) Generate(parents asset.Parents) error {
+func (c *EtcdMetricsSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "etcd-metrics-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "etcd-metrics-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "etcd-metrics-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -52,7 +63,7 @@ func (a *EtcdMetricsCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *EtcdMetricsCABundle) Generate(deps asset.Parents) error {
+func (a *EtcdMetricsCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -83,7 +94,7 @@ func (a *EtcdMetricsSignerClientCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *EtcdMetricsSignerClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *EtcdMetricsSignerClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &EtcdMetricsSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -94,7 +105,7 @@ func (a *EtcdMetricsSignerClientCertKey) Generate(dependencies asset.Parents) er
 		Validity:     ValidityTenYears,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "etcd-metrics-signer-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "etcd-metrics-signer-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -119,7 +130,7 @@ func (a *EtcdMetricsSignerServerCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *EtcdMetricsSignerServerCertKey) Generate(dependencies asset.Parents) error {
+func (a *EtcdMetricsSignerServerCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &EtcdMetricsSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -137,7 +148,7 @@ func (a *EtcdMetricsSignerServerCertKey) Generate(dependencies asset.Parents) er
 		Validity: ValidityTenYears,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "etcd-metrics-signer-server", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "etcd-metrics-signer-server", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
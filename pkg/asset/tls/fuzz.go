@@ -0,0 +1,33 @@
+//go:build gofuzz
+// +build gofuzz
+
+package tls
+
+import (
+	"encoding/binary"
+
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+)
+
+// Fuzz is a fuzz testing function designed to be used with go-fuzz:
+//
+//	https://github.com/dvyukov/go-fuzz
+//
+// It takes a host number from the first two bytes of the data and
+// parses the rest as a CIDR, as in the install config, and then finds
+// the host's address with cidrhost.  It's not included in a normal
+// build due to the gofuzz build tag above.
+func Fuzz(data []byte) int {
+	if len(data) < 2 {
+		return -1
+	}
+	hostNum := int(int16(binary.BigEndian.Uint16(data)))
+	network, err := ipnet.ParseCIDR(string(data[2:]))
+	if err != nil {
+		return 0
+	}
+	if _, err := cidrhost(network.IPNet, hostNum); err != nil {
+		return 0
+	}
+	return 1
+}
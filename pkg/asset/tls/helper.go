@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/apparentlymart/go-cidr/cidr"
+	"github.com/pkg/errors"
 
 	"github.com/metalkube/kni-installer/pkg/types"
 )
@@ -22,11 +23,84 @@ func apiAddress(cfg *types.InstallConfig) string {
 	return fmt.Sprintf("api.%s", cfg.ClusterDomain())
 }
 
+// apiIntAddress returns the name the cluster's machines reach the API on
+// in the internal view of a split-horizon DNS.
+func apiIntAddress(cfg *types.InstallConfig) string {
+	return fmt.Sprintf("api-int.%s", cfg.ClusterDomain())
+}
+
+// apiIPs returns the IP addresses clients may reach the API on besides its
+// names: the bare metal API VIP, which machines on other routed subnets
+// than the control plane's may be pointed at directly, and the addresses
+// the API names resolve to in the views of a split-horizon DNS.
+func apiIPs(cfg *types.InstallConfig) []net.IP {
+	var targets []string
+	if bm := cfg.Platform.BareMetal; bm != nil && bm.APIVIP != "" {
+		targets = append(targets, bm.APIVIP)
+	}
+	if cfg.DNS != nil && cfg.DNS.Views != nil {
+		targets = append(append(targets, cfg.DNS.Views.External...), cfg.DNS.Views.Internal...)
+	}
+	var ips []net.IP
+	seen := map[string]bool{}
+	for _, target := range targets {
+		if ip := net.ParseIP(target); ip != nil && !seen[ip.String()] {
+			ips = append(ips, ip)
+			seen[ip.String()] = true
+		}
+	}
+	return ips
+}
+
+// additionalSANs returns the install config's extra DNS names and IP
+// addresses of the API servers' serving certificates.
+func additionalSANs(cfg *types.InstallConfig) (dnsNames []string, ips []net.IP) {
+	if cfg.TLS == nil {
+		return nil, nil
+	}
+	for _, san := range cfg.TLS.AdditionalSANs {
+		if ip := net.ParseIP(san); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			dnsNames = append(dnsNames, san)
+		}
+	}
+	return dnsNames, ips
+}
+
+// kubernetesServiceAddress returns the address of the kubernetes service, the
+// first host of the service network.
+func kubernetesServiceAddress(cfg *types.InstallConfig) (string, error) {
+	if cfg.Networking == nil || len(cfg.Networking.ServiceNetwork) == 0 {
+		return "", errors.New("no service network")
+	}
+	return cidrhost(cfg.Networking.ServiceNetwork[0].IPNet, 1)
+}
+
+// cidrhost returns the address of the hostNum'th host of the network, or
+// counting back from the last address if hostNum is negative.  Networks
+// which cidr.Host would panic on, as their address and mask do not
+// match, are errors.
 func cidrhost(network net.IPNet, hostNum int) (string, error) {
-	ip, err := cidr.Host(&network, hostNum)
+	ones, bits := network.Mask.Size()
+	if bits == 0 {
+		return "", errors.Errorf("invalid network mask %s", network.Mask)
+	}
+	ip := network.IP.To16()
+	if bits == 8*net.IPv4len {
+		ip = network.IP.To4()
+	} else if ip.To4() != nil {
+		// cidr.Host treats IPv4-mapped addresses as IPv4, whatever the mask.
+		return "", errors.Errorf("IPv4-mapped network %s/%d must be given as IPv4", network.IP, ones)
+	}
+	if ip == nil {
+		return "", errors.Errorf("invalid network address %s for a %d-bit mask", network.IP, bits)
+	}
+
+	host, err := cidr.Host(&net.IPNet{IP: ip, Mask: network.Mask}, hostNum)
 	if err != nil {
 		return "", err
 	}
 
-	return ip.String(), nil
+	return host.String(), nil
 }
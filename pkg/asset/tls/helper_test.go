@@ -0,0 +1,104 @@
+package tls
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestCIDRHost(t *testing.T) {
+	cases := []struct {
+		name          string
+		network       net.IPNet
+		hostNum       int
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "IPv4",
+			network:  ipnet.MustParseCIDR("172.30.0.0/16").IPNet,
+			hostNum:  1,
+			expected: "172.30.0.1",
+		},
+		{
+			name:     "IPv4 from the end",
+			network:  ipnet.MustParseCIDR("172.30.0.0/16").IPNet,
+			hostNum:  -2,
+			expected: "172.30.255.254",
+		},
+		{
+			name:     "IPv6",
+			network:  ipnet.MustParseCIDR("fd02::/112").IPNet,
+			hostNum:  1,
+			expected: "fd02::1",
+		},
+		{
+			name:          "too small",
+			network:       ipnet.MustParseCIDR("172.30.0.0/31").IPNet,
+			hostNum:       2,
+			expectedError: "^prefix of 31 does not accommodate a host numbered 2$",
+		},
+		{
+			name:          "empty",
+			expectedError: "^invalid network mask <nil>$",
+		},
+		{
+			name:          "no address",
+			network:       net.IPNet{Mask: net.CIDRMask(16, 32)},
+			hostNum:       1,
+			expectedError: "^invalid network address <nil> for a 32-bit mask$",
+		},
+		{
+			name:          "IPv4-mapped",
+			network:       ipnet.MustParseCIDR("::ffff:172.30.0.0/112").IPNet,
+			hostNum:       1,
+			expectedError: "^IPv4-mapped network 172.30.0.0/112 must be given as IPv4$",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			address, err := cidrhost(tc.network, tc.hostNum)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, address)
+			}
+		})
+	}
+}
+
+func TestAPIIPs(t *testing.T) {
+	cfg := &types.InstallConfig{}
+	assert.Empty(t, apiIPs(cfg))
+
+	cfg.DNS = &types.DNS{Views: &types.DNSViews{
+		External: []string{"lb.example.com"},
+		Internal: []string{"10.0.0.5", "fd00::5"},
+	}}
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.5"), net.ParseIP("fd00::5")}, apiIPs(cfg))
+
+	cfg.Platform.BareMetal = &baremetal.Platform{APIVIP: "192.168.111.5"}
+	assert.Equal(t, []net.IP{net.ParseIP("192.168.111.5"), net.ParseIP("10.0.0.5"), net.ParseIP("fd00::5")}, apiIPs(cfg))
+
+	cfg.DNS.Views.Internal = []string{"192.168.111.5"}
+	assert.Equal(t, []net.IP{net.ParseIP("192.168.111.5")}, apiIPs(cfg))
+}
+
+func TestAdditionalSANs(t *testing.T) {
+	cfg := &types.InstallConfig{}
+	names, ips := additionalSANs(cfg)
+	assert.Empty(t, names)
+	assert.Empty(t, ips)
+
+	cfg.TLS = &types.TLS{AdditionalSANs: []string{"api.corp.example.com", "192.0.2.10", "2001:db8::10"}}
+	names, ips = additionalSANs(cfg)
+	assert.Equal(t, []string{"api.corp.example.com"}, names)
+	assert.Equal(t, []net.IP{net.ParseIP("192.0.2.10"), net.ParseIP("2001:db8::10")}, ips)
+}
@@ -1,6 +1,8 @@
 package tls
 
 import (
+	"context"
+
 	"github.com/metalkube/kni-installer/pkg/asset"
 	"github.com/pkg/errors"
 )
@@ -20,9 +22,9 @@ type KeyPair struct {
 	FileList []*asset.File
 }
 
-// Generate generates the rsa private / public key pair.
-func (k *KeyPair) Generate(filenameBase string) error {
-	key, err := PrivateKey()
+// Generate generates the rsa private / public key pair of the given size.
+func (k *KeyPair) Generate(ctx context.Context, filenameBase string, bits int) error {
+	key, err := RSAPrivateKey(ctx, bits)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate private key")
 	}
@@ -0,0 +1,150 @@
+package tls
+
+import (
+	"crypto/rsa"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+// KeyPool generates the cluster's RSA keys ahead of time, concurrently, so
+// the certificate assets need not wait on key generation one at a time.
+// Key generation dominates generating the Ignition configs, especially on
+// VMs short of entropy.  It is the sources it was created with, but takes
+// RSA keys from the pool until it runs dry or is stopped.  Nothing is
+// generated until the first key is asked for, so the pool costs nothing
+// when every certificate already exists.
+type KeyPool struct {
+	asset.Sources
+	workers int
+
+	// needed is the number of keys the cluster needs which have not been
+	// taken from the pool yet.
+	needed int32
+
+	mu sync.Mutex
+	// batch is the keys being generated, nil until the first key is
+	// asked for.
+	batch *keyBatch
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// keyBatch is the keys of one size being generated into the pool.
+type keyBatch struct {
+	bits      int
+	remaining int32
+	keys      chan *rsa.PrivateKey
+
+	// abandoned is closed when the pool switches to another size.
+	abandoned chan struct{}
+}
+
+// NewKeyPool returns a pool of count keys, one for each certificate and key
+// pair asset, generated with sources on the given number of goroutines.
+// The pool starts generating keys of the size of the first key asked for,
+// and switches to another size for the keys which remain when a key of
+// that size is asked for.
+func NewKeyPool(sources asset.Sources, workers int, count int) *KeyPool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &KeyPool{
+		Sources: sources,
+		workers: workers,
+		needed:  int32(count),
+		stop:    make(chan struct{}),
+	}
+}
+
+// RSAKey takes a key of the given size from the pool, or generates one if
+// the pool has run dry or been stopped.
+func (p *KeyPool) RSAKey(bits int) (*rsa.PrivateKey, error) {
+	if key, ok := p.take(bits); ok {
+		return key, nil
+	}
+	return p.Sources.RSAKey(bits)
+}
+
+// start starts generating count keys of the given size.
+func (p *KeyPool) start(bits int, count int) *keyBatch {
+	b := &keyBatch{
+		bits:      bits,
+		remaining: int32(count),
+		keys:      make(chan *rsa.PrivateKey, count),
+		abandoned: make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(b)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(b.keys)
+	}()
+	return b
+}
+
+// work generates keys into the batch until it holds as many as were asked
+// for, or the batch is abandoned or the pool is stopped.
+func (p *KeyPool) work(b *keyBatch) {
+	for atomic.AddInt32(&b.remaining, -1) >= 0 {
+		key, err := p.Sources.RSAKey(b.bits)
+		if err != nil {
+			logrus.Debugf("Failed to pre-generate an RSA key: %v", err)
+			return
+		}
+		select {
+		case b.keys <- key:
+		case <-b.abandoned:
+			return
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// take returns a key of the given size from the pool, waiting for one if
+// it is still being generated, or false if the pool has run dry or been
+// stopped.  Asking for another size than the pool's switches the pool to
+// it.
+func (p *KeyPool) take(bits int) (*rsa.PrivateKey, bool) {
+	p.mu.Lock()
+	b := p.batch
+	if b == nil || b.bits != bits {
+		if b != nil {
+			close(b.abandoned)
+		}
+		b = p.start(bits, int(atomic.LoadInt32(&p.needed)))
+		p.batch = b
+		logrus.Debugf("Generating %d-bit RSA keys ahead of time", bits)
+	}
+	p.mu.Unlock()
+
+	select {
+	case key, ok := <-b.keys:
+		if ok {
+			atomic.AddInt32(&p.needed, -1)
+		}
+		return key, ok
+	case <-p.stop:
+		return nil, false
+	}
+}
+
+// Stop stops generating keys.
+func (p *KeyPool) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
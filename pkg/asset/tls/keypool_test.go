@@ -0,0 +1,124 @@
+package tls
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+// clusterKeys is about the number of keys a cluster needs.
+const clusterKeys = 40
+
+// keySources returns the same key whatever the size asked for.
+type keySources struct {
+	asset.Sources
+	generate func(bits int) (*rsa.PrivateKey, error)
+}
+
+func (s keySources) RSAKey(bits int) (*rsa.PrivateKey, error) {
+	return s.generate(bits)
+}
+
+func TestKeyPool(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var generated int32
+	sources := keySources{Sources: asset.SystemSources, generate: func(bits int) (*rsa.PrivateKey, error) {
+		atomic.AddInt32(&generated, 1)
+		return key, nil
+	}}
+
+	count := clusterKeys
+	pool := NewKeyPool(sources, 4, count)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&generated), "keys were generated before any was asked for")
+	ctx := asset.NewSourcesContext(context.Background(), pool)
+	for i := 0; i < count; i++ {
+		_, err := PrivateKey(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(count), atomic.LoadInt32(&generated), "keys were not all taken from the pool")
+
+	_, err = PrivateKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(count+1), atomic.LoadInt32(&generated), "a key was not generated once the pool ran dry")
+
+	pool.Stop()
+	_, err = PrivateKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(count+2), atomic.LoadInt32(&generated), "a key was not generated once the pool was stopped")
+}
+
+func TestKeyPoolKeySize(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var mu sync.Mutex
+	generated := map[int]int{}
+	sources := keySources{Sources: asset.SystemSources, generate: func(bits int) (*rsa.PrivateKey, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		generated[bits]++
+		return key, nil
+	}}
+
+	count := clusterKeys
+	pool := NewKeyPool(sources, 4, count)
+	defer pool.Stop()
+	ctx := asset.NewSourcesContext(context.Background(), pool)
+	for i := 0; i < count/2; i++ {
+		_, err := PrivateKey(ctx)
+		assert.NoError(t, err)
+	}
+	// The install config asks for larger keys, so the pool switches to
+	// generating them for the remaining keys.
+	for i := count / 2; i < count; i++ {
+		_, err := RSAPrivateKey(ctx, 4096)
+		assert.NoError(t, err)
+	}
+	mu.Lock()
+	assert.Equal(t, count-count/2, generated[4096], "the remaining keys were not all taken from the pool")
+	mu.Unlock()
+
+	_, err = RSAPrivateKey(ctx, 4096)
+	assert.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, count-count/2+1, generated[4096], "a key was not generated once the pool ran dry")
+	mu.Unlock()
+}
+
+func BenchmarkPrivateKey(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := PrivateKey(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkKeyPool measures taking every key a cluster needs from a new
+// pool, as when creating the Ignition configs.
+func BenchmarkKeyPool(b *testing.B) {
+	count := clusterKeys
+	for i := 0; i < b.N; i++ {
+		pool := NewKeyPool(asset.SystemSources, runtime.NumCPU(), count)
+		ctx := asset.NewSourcesContext(context.Background(), pool)
+		for j := 0; j < count; j++ {
+			if _, err := PrivateKey(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+		pool.Stop()
+	}
+}
@@ -1,10 +1,12 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // KubeCA is the asset that generates the kube-ca key/cert pair.
@@ -19,20 +21,26 @@ var _ asset.Asset = (*KubeCA)(nil)
 // the parent CA, and install config if it depends on the install config for
 // DNS names, etc.
 func (a *KubeCA) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeCA) Generate(dependencies asset.Parents) error {
+func (a *KubeCA) Generate(ctx context.Context, dependencies asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	dependencies.Get(ephemeral, userRoot)
 
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kube-ca", OrganizationalUnit: []string{"bootkube"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return a.SelfSignedCertKey.Generate(cfg, "kube-ca")
+	return a.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kube-ca")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -1,10 +1,12 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // KubeControlPlaneSignerCertKey is a key/cert pair that signs the kube control-plane client certs.
@@ -14,21 +16,30 @@ type KubeControlPlaneSignerCertKey struct {
 
 var _ asset.WritableAsset = (*KubeControlPlaneSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the kube-control-plane-signer:
+// the ephemeral cluster lifetime, which caps its validity, and the user's
+// root CA, which signs it if one is given.
 func (c *KubeControlPlaneSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *KubeControlPlaneSignerCertKey) Generate(parents asset.Parents) error {
+func (c *KubeControlPlaneSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kube-control-plane-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityOneYear,
+		Validity:  ephemeral.Validity(ValidityOneYear),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "kube-control-plane-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kube-control-plane-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -55,7 +66,7 @@ func (a *KubeControlPlaneCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeControlPlaneCABundle) Generate(deps asset.Parents) error {
+func (a *KubeControlPlaneCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -84,7 +95,7 @@ func (a *KubeControlPlaneKubeControllerManagerClientCertKey) Dependencies() []as
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeControlPlaneKubeControllerManagerClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeControlPlaneKubeControllerManagerClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &KubeControlPlaneSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -95,7 +106,7 @@ func (a *KubeControlPlaneKubeControllerManagerClientCertKey) Generate(dependenci
 		Validity:     ValidityOneYear,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "kube-control-plane-kube-controller-manager-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kube-control-plane-kube-controller-manager-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -118,7 +129,7 @@ func (a *KubeControlPlaneKubeSchedulerClientCertKey) Dependencies() []asset.Asse
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeControlPlaneKubeSchedulerClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeControlPlaneKubeSchedulerClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &KubeControlPlaneSignerCertKey{}
 	dependencies.Get(ca)
 
@@ -129,7 +140,7 @@ func (a *KubeControlPlaneKubeSchedulerClientCertKey) Generate(dependencies asset
 		Validity:     ValidityOneYear,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "kube-control-plane-kube-scheduler-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kube-control-plane-kube-scheduler-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -1,10 +1,12 @@
 package tls
 
 import (
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 
 	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
 )
 
 // KubeletCertKey is the asset that generates the kubelet key/cert pair.
@@ -25,7 +27,7 @@ func (a *KubeletCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeletCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeletCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	kubeCA := &KubeCA{}
 	dependencies.Get(kubeCA)
 
@@ -36,7 +38,7 @@ func (a *KubeletCertKey) Generate(dependencies asset.Parents) error {
 		Validity:     ValidityOneDay,
 	}
 
-	return a.SignedCertKey.Generate(cfg, kubeCA, "kubelet", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, kubeCA, "kubelet", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
@@ -51,21 +53,30 @@ type KubeletCSRSignerCertKey struct {
 
 var _ asset.WritableAsset = (*KubeletCSRSignerCertKey)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the kubelet-signer: the ephemeral
+// cluster lifetime, which caps its validity, and the user's root CA, which
+// signs it if one is given.
 func (c *KubeletCSRSignerCertKey) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *KubeletCSRSignerCertKey) Generate(parents asset.Parents) error {
+func (c *KubeletCSRSignerCertKey) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kubelet-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityOneDay,
+		Validity:  ephemeral.Validity(ValidityOneDay),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "kubelet-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kubelet-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -89,7 +100,7 @@ func (a *KubeletClientCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeletClientCABundle) Generate(deps asset.Parents) error {
+func (a *KubeletClientCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -119,7 +130,7 @@ func (a *KubeletServingCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeletServingCABundle) Generate(deps asset.Parents) error {
+func (a *KubeletServingCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -141,21 +152,30 @@ type KubeletBootstrapCertSigner struct {
 
 var _ asset.WritableAsset = (*KubeletBootstrapCertSigner)(nil)
 
-// Dependencies returns the dependency of the root-ca, which is empty.
+// Dependencies returns the dependencies of the
+// kubelet-bootstrap-kubeconfig-signer: the ephemeral cluster lifetime, which
+// caps its validity, and the user's root CA, which signs it if one is given.
 func (c *KubeletBootstrapCertSigner) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.Ephemeral{},
+		&UserRootCA{},
+	}
 }
 
 // Generate generates the root-ca key and cert pair.
-func (c *KubeletBootstrapCertSigner) Generate(parents asset.Parents) error {
+func (c *KubeletBootstrapCertSigner) Generate(ctx context.Context, parents asset.Parents) error {
+	ephemeral := &installconfig.Ephemeral{}
+	userRoot := &UserRootCA{}
+	parents.Get(ephemeral, userRoot)
+
 	cfg := &CertCfg{
 		Subject:   pkix.Name{CommonName: "kubelet-bootstrap-kubeconfig-signer", OrganizationalUnit: []string{"openshift"}},
 		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		Validity:  ValidityTenYears,
+		Validity:  ephemeral.Validity(ValidityTenYears),
 		IsCA:      true,
 	}
 
-	return c.SelfSignedCertKey.Generate(cfg, "kubelet-bootstrap-kubeconfig-signer")
+	return c.SelfSignedCertKey.GenerateSigner(ctx, cfg, userRoot, "kubelet-bootstrap-kubeconfig-signer")
 }
 
 // Name returns the human-friendly name of the asset.
@@ -179,7 +199,7 @@ func (a *KubeletBootstrapCABundle) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert bundle based on its dependencies.
-func (a *KubeletBootstrapCABundle) Generate(deps asset.Parents) error {
+func (a *KubeletBootstrapCABundle) Generate(ctx context.Context, deps asset.Parents) error {
 	var certs []CertInterface
 	for _, asset := range a.Dependencies() {
 		deps.Get(asset)
@@ -210,7 +230,7 @@ func (a *KubeletClientCertKey) Dependencies() []asset.Asset {
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *KubeletClientCertKey) Generate(dependencies asset.Parents) error {
+func (a *KubeletClientCertKey) Generate(ctx context.Context, dependencies asset.Parents) error {
 	ca := &KubeletBootstrapCertSigner{}
 	dependencies.Get(ca)
 
@@ -221,7 +241,7 @@ func (a *KubeletClientCertKey) Generate(dependencies asset.Parents) error {
 		Validity:     ValidityOneDay,
 	}
 
-	return a.SignedCertKey.Generate(cfg, ca, "kubelet-client", DoNotAppendParent)
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "kubelet-client", DoNotAppendParent)
 }
 
 // Name returns the human-friendly name of the asset.
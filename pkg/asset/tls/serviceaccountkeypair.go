@@ -1,6 +1,11 @@
 package tls
 
-import "github.com/metalkube/kni-installer/pkg/asset"
+import (
+	"context"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+)
 
 // ServiceAccountKeyPair is the asset that generates the service-account public/private key pair.
 type ServiceAccountKeyPair struct {
@@ -13,12 +18,21 @@ var _ asset.WritableAsset = (*ServiceAccountKeyPair)(nil)
 // the parent CA, and install config if it depends on the install config for
 // DNS names, etc.
 func (a *ServiceAccountKeyPair) Dependencies() []asset.Asset {
-	return []asset.Asset{}
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+	}
 }
 
 // Generate generates the cert/key pair based on its dependencies.
-func (a *ServiceAccountKeyPair) Generate(dependencies asset.Parents) error {
-	return a.KeyPair.Generate("service-account")
+func (a *ServiceAccountKeyPair) Generate(ctx context.Context, dependencies asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	dependencies.Get(installConfig)
+
+	bits := keySize
+	if tls := installConfig.Config.TLS; tls != nil && tls.KeySize != 0 {
+		bits = tls.KeySize
+	}
+	return a.KeyPair.Generate(ctx, "service-account", bits)
 }
 
 // Name returns the human-friendly name of the asset.
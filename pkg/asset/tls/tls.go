@@ -1,6 +1,7 @@
 package tls
 
 import (
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
@@ -16,9 +17,13 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/types"
 )
 
 const (
+	// keySize is the default size of RSA keys.
 	keySize = 2048
 
 	// ValidityOneDay sets the validity of a cert to 24 hours.
@@ -40,6 +45,14 @@ type CertCfg struct {
 	Subject      pkix.Name
 	Validity     time.Duration
 	IsCA         bool
+	// KeyAlgorithm is the algorithm of the certificate's key.  If unset,
+	// a signed certificate's key has its CA's algorithm, and a
+	// self-signed certificate's is RSA.
+	KeyAlgorithm types.KeyAlgorithm
+	// KeySize is the size of the certificate's key, if RSA.  If unset, a
+	// signed certificate's key has its CA's size, if its CA's key is RSA,
+	// and other keys are 2048 bits.
+	KeySize int
 }
 
 // rsaPublicKey reflects the ASN.1 structure of a PKCS#1 public key.
@@ -49,8 +62,14 @@ type rsaPublicKey struct {
 }
 
 // PrivateKey generates an RSA Private key and returns the value
-func PrivateKey() (*rsa.PrivateKey, error) {
-	rsaKey, err := rsa.GenerateKey(rand.Reader, keySize)
+func PrivateKey(ctx context.Context) (*rsa.PrivateKey, error) {
+	return RSAPrivateKey(ctx, keySize)
+}
+
+// RSAPrivateKey generates an RSA private key of the given size with the
+// sources of ctx.
+func RSAPrivateKey(ctx context.Context, bits int) (*rsa.PrivateKey, error) {
+	rsaKey, err := asset.SourcesFromContext(ctx).RSAKey(bits)
 	if err != nil {
 		return nil, errors.Wrap(err, "error generating RSA private key")
 	}
@@ -58,18 +77,52 @@ func PrivateKey() (*rsa.PrivateKey, error) {
 	return rsaKey, nil
 }
 
-// SelfSignedCertificate creates a self signed certificate
-func SelfSignedCertificate(cfg *CertCfg, key *rsa.PrivateKey) (*x509.Certificate, error) {
-	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+// privateKey generates a private key of the given algorithm, and of the
+// given size if RSA, or the default size if that is unset.
+func privateKey(ctx context.Context, algorithm types.KeyAlgorithm, bits int) (crypto.Signer, error) {
+	switch algorithm {
+	case "", types.KeyAlgorithmRSA:
+		if bits == 0 {
+			bits = keySize
+		}
+		return RSAPrivateKey(ctx, bits)
+	case types.KeyAlgorithmECDSAP256:
+		return asset.SourcesFromContext(ctx).ECDSAKey(elliptic.P256())
+	case types.KeyAlgorithmECDSAP384:
+		return asset.SourcesFromContext(ctx).ECDSAKey(elliptic.P384())
+	default:
+		return nil, errors.Errorf("unsupported key algorithm %q", algorithm)
+	}
+}
+
+// keyAlgorithm returns the algorithm of the private key.
+func keyAlgorithm(key crypto.Signer) types.KeyAlgorithm {
+	if key, ok := key.(*ecdsa.PrivateKey); ok {
+		switch key.Curve {
+		case elliptic.P256():
+			return types.KeyAlgorithmECDSAP256
+		case elliptic.P384():
+			return types.KeyAlgorithmECDSAP384
+		}
+	}
+	return types.KeyAlgorithmRSA
+}
+
+// SelfSignedCertificate creates a self signed certificate with the sources
+// of ctx.
+func SelfSignedCertificate(ctx context.Context, cfg *CertCfg, key crypto.Signer) (*x509.Certificate, error) {
+	sources := asset.SourcesFromContext(ctx)
+	serial, err := rand.Int(sources.Rand(), new(big.Int).SetInt64(math.MaxInt64))
 	if err != nil {
 		return nil, err
 	}
+	now := sources.Now()
 	cert := x509.Certificate{
 		BasicConstraintsValid: true,
 		IsCA:         cfg.IsCA,
 		KeyUsage:     cfg.KeyUsages,
-		NotAfter:     time.Now().Add(cfg.Validity),
-		NotBefore:    time.Now(),
+		NotAfter:     now.Add(cfg.Validity),
+		NotBefore:    now,
 		SerialNumber: serial,
 		Subject:      cfg.Subject,
 	}
@@ -82,32 +135,42 @@ func SelfSignedCertificate(cfg *CertCfg, key *rsa.PrivateKey) (*x509.Certificate
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to set subject key identifier")
 	}
-	certBytes, err := x509.CreateCertificate(rand.Reader, &cert, &cert, key.Public(), key)
+	certBytes, err := x509.CreateCertificate(sources.Rand(), &cert, &cert, key.Public(), sources.Signer(key))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create certificate")
 	}
 	return x509.ParseCertificate(certBytes)
 }
 
-// SignedCertificate creates a new X.509 certificate based on a template.
+// SignedCertificate creates a new X.509 certificate based on a template
+// with the sources of ctx.
 func SignedCertificate(
+	ctx context.Context,
 	cfg *CertCfg,
 	csr *x509.CertificateRequest,
-	key *rsa.PrivateKey,
+	key crypto.Signer,
 	caCert *x509.Certificate,
-	caKey *rsa.PrivateKey,
+	caKey crypto.Signer,
 ) (*x509.Certificate, error) {
-	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	sources := asset.SourcesFromContext(ctx)
+	serial, err := rand.Int(sources.Rand(), new(big.Int).SetInt64(math.MaxInt64))
 	if err != nil {
 		return nil, err
 	}
 
+	// A certificate is useless once its issuer expires, so it never
+	// outlives it.  This is what caps the chains of ephemeral clusters.
+	notAfter := sources.Now().Add(cfg.Validity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+
 	certTmpl := x509.Certificate{
 		DNSNames:              csr.DNSNames,
 		ExtKeyUsage:           cfg.ExtKeyUsages,
 		IPAddresses:           csr.IPAddresses,
 		KeyUsage:              cfg.KeyUsages,
-		NotAfter:              time.Now().Add(cfg.Validity),
+		NotAfter:              notAfter,
 		NotBefore:             caCert.NotBefore,
 		SerialNumber:          serial,
 		Subject:               csr.Subject,
@@ -115,12 +178,11 @@ func SignedCertificate(
 		Version:               3,
 		BasicConstraintsValid: true,
 	}
-	pub := caCert.PublicKey.(*rsa.PublicKey)
-	certTmpl.SubjectKeyId, err = generateSubjectKeyID(pub)
+	certTmpl.SubjectKeyId, err = generateSubjectKeyID(key.Public())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to set subject key identifier")
 	}
-	certBytes, err := x509.CreateCertificate(rand.Reader, &certTmpl, caCert, key.Public(), caKey)
+	certBytes, err := x509.CreateCertificate(sources.Rand(), &certTmpl, caCert, key.Public(), sources.Signer(caKey))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create x509 certificate")
 	}
@@ -149,18 +211,26 @@ func generateSubjectKeyID(pub crypto.PublicKey) ([]byte, error) {
 }
 
 // GenerateSignedCertificate generate a key and cert defined by CertCfg and signed by CA.
-func GenerateSignedCertificate(caKey *rsa.PrivateKey, caCert *x509.Certificate,
-	cfg *CertCfg) (*rsa.PrivateKey, *x509.Certificate, error) {
+func GenerateSignedCertificate(ctx context.Context, caKey crypto.Signer, caCert *x509.Certificate,
+	cfg *CertCfg) (crypto.Signer, *x509.Certificate, error) {
 
 	// create a private key
-	key, err := PrivateKey()
+	algorithm, bits := cfg.KeyAlgorithm, cfg.KeySize
+	if algorithm == "" {
+		algorithm = keyAlgorithm(caKey)
+	}
+	if caKey, ok := caKey.(*rsa.PrivateKey); ok && bits == 0 {
+		bits = caKey.N.BitLen()
+	}
+	key, err := privateKey(ctx, algorithm, bits)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to generate private key")
 	}
 
 	// create a CSR
+	sources := asset.SourcesFromContext(ctx)
 	csrTmpl := x509.CertificateRequest{Subject: cfg.Subject, DNSNames: cfg.DNSNames, IPAddresses: cfg.IPAddresses}
-	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTmpl, key)
+	csrBytes, err := x509.CreateCertificateRequest(sources.Rand(), &csrTmpl, sources.Signer(key))
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to create certificate request")
 	}
@@ -170,7 +240,7 @@ func GenerateSignedCertificate(caKey *rsa.PrivateKey, caCert *x509.Certificate,
 	}
 
 	// create a cert
-	cert, err := SignedCertificate(cfg, csr, key, caCert, caKey)
+	cert, err := SignedCertificate(ctx, cfg, csr, key, caCert, caKey)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to create a signed certificate")
 	}
@@ -178,13 +248,13 @@ func GenerateSignedCertificate(caKey *rsa.PrivateKey, caCert *x509.Certificate,
 }
 
 // GenerateSelfSignedCertificate generates a key/cert pair defined by CertCfg.
-func GenerateSelfSignedCertificate(cfg *CertCfg) (*rsa.PrivateKey, *x509.Certificate, error) {
-	key, err := PrivateKey()
+func GenerateSelfSignedCertificate(ctx context.Context, cfg *CertCfg) (crypto.Signer, *x509.Certificate, error) {
+	key, err := privateKey(ctx, cfg.KeyAlgorithm, cfg.KeySize)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to generate private key")
 	}
 
-	crt, err := SelfSignedCertificate(cfg, key)
+	crt, err := SelfSignedCertificate(ctx, cfg, key)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to create self-signed certificate")
 	}
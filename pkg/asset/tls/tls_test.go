@@ -1,6 +1,7 @@
 package tls
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -9,7 +10,7 @@ import (
 )
 
 func TestSelfSignedCertificate(t *testing.T) {
-	key, err := PrivateKey()
+	key, err := PrivateKey(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to generate Private Key: %v", err)
 	}
@@ -50,7 +51,7 @@ func TestSelfSignedCertificate(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		if _, err := SelfSignedCertificate(c.cfg, key); (err != nil) != c.err {
+		if _, err := SelfSignedCertificate(context.Background(), c.cfg, key); (err != nil) != c.err {
 			no := "no"
 			if c.err {
 				no = "an"
@@ -61,7 +62,7 @@ func TestSelfSignedCertificate(t *testing.T) {
 }
 
 func TestSignedCertificate(t *testing.T) {
-	key, err := PrivateKey()
+	key, err := PrivateKey(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to generate private key: %v", err)
 	}
@@ -105,3 +106,28 @@ func TestSignedCertificate(t *testing.T) {
 		}
 	}
 }
+
+func TestSignedCertificateDoesNotOutliveCA(t *testing.T) {
+	caKey, caCert, err := GenerateSelfSignedCertificate(context.Background(), &CertCfg{
+		Validity:  time.Hour * 5,
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Subject:   pkix.Name{CommonName: "root_ca", OrganizationalUnit: []string{"openshift"}},
+		IsCA:      true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate CA: %v", err)
+	}
+
+	_, cert, err := GenerateSignedCertificate(context.Background(), caKey, caCert, &CertCfg{
+		Validity:     ValidityTenYears,
+		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Subject:      pkix.Name{CommonName: "client", OrganizationalUnit: []string{"openshift"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate certificate: %v", err)
+	}
+	if !cert.NotAfter.Equal(caCert.NotAfter) {
+		t.Errorf("expected the certificate to expire with its CA at %s, got %s", caCert.NotAfter, cert.NotAfter)
+	}
+}
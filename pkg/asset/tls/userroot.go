@@ -0,0 +1,59 @@
+package tls
+
+import (
+	"context"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// UserRootCA is the root CA of the user's PKI, from the install-config,
+// which signs all of the signers.  It is empty if the install-config has
+// none, and the signers are self-signed.  It is never written to disk.
+type UserRootCA struct {
+	CertKey
+
+	// KeyAlgorithm is the algorithm of the signers' keys, from the
+	// install-config.
+	KeyAlgorithm types.KeyAlgorithm
+
+	// KeySize is the size of the signers' keys, if RSA, from the
+	// install-config.
+	KeySize int
+}
+
+var _ asset.Asset = (*UserRootCA)(nil)
+
+// Dependencies returns the dependency of the user's root CA, which is the
+// install-config.
+func (c *UserRootCA) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&installconfig.InstallConfig{},
+	}
+}
+
+// Generate reads the user's root CA, key algorithm and key size from the
+// install-config.
+func (c *UserRootCA) Generate(ctx context.Context, parents asset.Parents) error {
+	installConfig := &installconfig.InstallConfig{}
+	parents.Get(installConfig)
+
+	c.KeySize = keySize
+	if tls := installConfig.Config.TLS; tls != nil {
+		if tls.RootCA != nil {
+			c.CertRaw = []byte(tls.RootCA.Certificate)
+			c.KeyRaw = []byte(tls.RootCA.Key)
+		}
+		c.KeyAlgorithm = tls.KeyAlgorithm
+		if tls.KeySize != 0 {
+			c.KeySize = tls.KeySize
+		}
+	}
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (c *UserRootCA) Name() string {
+	return "Certificate (user root CA)"
+}
@@ -0,0 +1,55 @@
+package tls
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+)
+
+// WorkloadIssuingCA is the asset that generates the key/cert pair of the CA
+// which cert-manager issues applications' certificates from.  It is empty
+// unless the install config asks for a workload issuer.
+type WorkloadIssuingCA struct {
+	SignedCertKey
+}
+
+var _ asset.WritableAsset = (*WorkloadIssuingCA)(nil)
+
+// Dependencies returns the dependency of the the cert/key pair, which
+// includes the parent CA, and the install config which enables it.
+func (a *WorkloadIssuingCA) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&RootCA{},
+		&installconfig.InstallConfig{},
+	}
+}
+
+// Generate generates the cert/key pair based on its dependencies.
+func (a *WorkloadIssuingCA) Generate(ctx context.Context, dependencies asset.Parents) error {
+	ca := &RootCA{}
+	installConfig := &installconfig.InstallConfig{}
+	dependencies.Get(ca, installConfig)
+
+	if tls := installConfig.Config.TLS; tls == nil || tls.WorkloadIssuer == nil {
+		return nil
+	}
+
+	// Key encipherment only applies to RSA keys, and the CA may be
+	// generated with an ECDSA key.
+	cfg := &CertCfg{
+		Subject:   pkix.Name{CommonName: "workload-issuing-ca", OrganizationalUnit: []string{"openshift"}},
+		KeyUsages: x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  ValidityTenYears,
+		IsCA:      true,
+	}
+
+	return a.SignedCertKey.Generate(ctx, cfg, ca, "workload-issuing-ca", AppendParent)
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *WorkloadIssuingCA) Name() string {
+	return "Certificate (workload-issuing-ca)"
+}
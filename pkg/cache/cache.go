@@ -0,0 +1,165 @@
+// Package cache locates the installer's cache of downloads, such as RHCOS
+// images, and lists and cleans it.  The cache follows the XDG base
+// directory specification [1] unless --cache-dir moves it.
+//
+// [1]: https://standards.freedesktop.org/basedir-spec/basedir-spec-0.7.html
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+// EnvVar overrides the cache directory.  The --cache-dir flag sets it, so
+// child processes use the same cache.
+const EnvVar = "OPENSHIFT_INSTALL_CACHE_DIR"
+
+// sidecarSuffixes are the suffixes of the files kept next to a cached
+// item: its digest and source, and the lock and partial download of an
+// item being cached.
+var sidecarSuffixes = []string{".sha256", ".source", ".lock", ".tmp"}
+
+// Dir returns the installer's cache directory: $OPENSHIFT_INSTALL_CACHE_DIR
+// if set, and otherwise kni-install in the user's cache directory, e.g.
+// $XDG_CACHE_HOME/kni-install or ~/.cache/kni-install.
+func Dir() (string, error) {
+	if dir := os.Getenv(EnvVar); dir != "" {
+		return dir, nil
+	}
+	base, err := userCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "kni-install"), nil
+}
+
+// userCacheDir is os.UserCacheDir, which needs Go 1.11.
+func userCacheDir() (string, error) {
+	var dir string
+	switch runtime.GOOS {
+	case "windows":
+		dir = os.Getenv("LocalAppData")
+		if dir == "" {
+			return "", errors.New("%LocalAppData% is not defined")
+		}
+	case "darwin":
+		dir = os.Getenv("HOME")
+		if dir == "" {
+			return "", errors.New("$HOME is not defined")
+		}
+		dir = filepath.Join(dir, "Library", "Caches")
+	default:
+		dir = os.Getenv("XDG_CACHE_HOME")
+		if dir == "" {
+			dir = os.Getenv("HOME")
+			if dir == "" {
+				return "", errors.New("neither $XDG_CACHE_HOME nor $HOME are defined")
+			}
+			dir = filepath.Join(dir, ".cache")
+		}
+	}
+	return dir, nil
+}
+
+// Item is a cached download, with its sidecar files.
+type Item struct {
+	// Path is the path of the item, relative to the cache directory.
+	Path string
+
+	// Size is the size of the item and its sidecars, in bytes.
+	Size int64
+
+	// ModTime is the latest modification time of the item or its
+	// sidecars, i.e. when it was cached.
+	ModTime time.Time
+
+	// Partial is true if the item is still being, or was never
+	// completely, downloaded.
+	Partial bool
+
+	files []string
+}
+
+// List returns the items in the cache directory dir, ordered by path.
+func List(dir string) ([]*Item, error) {
+	items := map[string]*Item{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		name, sidecar := rel, false
+		for _, suffix := range sidecarSuffixes {
+			if strings.HasSuffix(rel, suffix) {
+				name, sidecar = strings.TrimSuffix(rel, suffix), true
+				break
+			}
+		}
+		item, ok := items[name]
+		if !ok {
+			item = &Item{Path: name, Partial: true}
+			items[name] = item
+		}
+		if !sidecar {
+			item.Partial = false
+		}
+		item.Size += info.Size()
+		if info.ModTime().After(item.ModTime) {
+			item.ModTime = info.ModTime()
+		}
+		item.files = append(item.files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", dir)
+	}
+
+	list := make([]*Item, 0, len(items))
+	for _, item := range items {
+		list = append(list, item)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list, nil
+}
+
+// Clean removes the items in the cache directory dir which were cached
+// before cutoff, or every item if cutoff is zero, and returns them.
+func Clean(dir string, cutoff time.Time) ([]*Item, error) {
+	items, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []*Item
+	for _, item := range items {
+		if !cutoff.IsZero() && !item.ModTime.Before(cutoff) {
+			continue
+		}
+		for _, path := range item.files {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return removed, errors.Wrapf(err, "failed to remove %s", item.Path)
+			}
+			fileaudit.Record(fileaudit.Remove, path, "cache clean", nil)
+		}
+		removed = append(removed, item)
+	}
+	return removed, nil
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDir(t *testing.T) {
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	defer os.Unsetenv(EnvVar)
+
+	os.Setenv(EnvVar, "/srv/cache")
+	dir, err := Dir()
+	assert.NoError(t, err)
+	assert.Equal(t, "/srv/cache", dir)
+
+	os.Unsetenv(EnvVar)
+	os.Setenv("HOME", "/home/user")
+	os.Setenv("XDG_CACHE_HOME", "/var/cache/user")
+	dir, err = Dir()
+	assert.NoError(t, err)
+	if base, err := userCacheDir(); assert.NoError(t, err) {
+		assert.Equal(t, filepath.Join(base, "kni-install"), dir)
+	}
+}
+
+func TestListAndClean(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	old := time.Now().Add(-48 * time.Hour)
+	for path, modTime := range map[string]time.Time{
+		"libvirt/image/old":         old,
+		"libvirt/image/old.sha256":  old,
+		"libvirt/image/new":         time.Now(),
+		"libvirt/image/new.sha256":  time.Now(),
+		"libvirt/image/partial.tmp": time.Now(),
+	} {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0777)) {
+			return
+		}
+		if !assert.NoError(t, ioutil.WriteFile(path, []byte("data"), 0644)) {
+			return
+		}
+		if !assert.NoError(t, os.Chtimes(path, modTime, modTime)) {
+			return
+		}
+	}
+
+	items, err := List(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var paths []string
+	for _, item := range items {
+		paths = append(paths, filepath.ToSlash(item.Path))
+		assert.Equal(t, item.Path == filepath.FromSlash("libvirt/image/partial"), item.Partial, item.Path)
+	}
+	assert.Equal(t, []string{"libvirt/image/new", "libvirt/image/old", "libvirt/image/partial"}, paths)
+	assert.Equal(t, int64(8), items[1].Size)
+
+	removed, err := Clean(dir, time.Now().Add(-24*time.Hour))
+	if assert.NoError(t, err) && assert.Len(t, removed, 1) {
+		assert.Equal(t, filepath.FromSlash("libvirt/image/old"), removed[0].Path)
+	}
+	for _, name := range []string{"old", "old.sha256"} {
+		_, err := os.Stat(filepath.Join(dir, "libvirt", "image", name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	removed, err = Clean(dir, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, removed, 2)
+	items, err = List(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	items, err = List(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,214 @@
+// Package credentials reads secrets, such as pull secrets, from providers
+// outside the install-config, so CI systems need not keep them in
+// install-config.yaml on disk.
+//
+// A secret is given either literally, or as a reference to a provider,
+// scheme:reference:
+//
+//	file:/path/to/pull-secret.json
+//	env:PULL_SECRET
+//	exec:pass show ci/pull-secret
+//	vault:secret/data/ci/pull-secret#pullSecret
+//
+// The file, exec and vault providers read local files, run commands and
+// reach the network on the installer's behalf, so they are only used when
+// the installer's user enables them with EnvVar, never because an
+// install-config references them.
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+// EnvVar lists, comma-separated, the opt-in providers which may be
+// referenced.  The --credential-providers flag sets it, so child processes
+// inherit it.
+const EnvVar = "OPENSHIFT_INSTALL_CREDENTIAL_PROVIDERS"
+
+// optIn are the providers which must be enabled with EnvVar.
+var optIn = map[string]bool{"file": true, "exec": true, "vault": true}
+
+// Provider reads secrets.
+type Provider interface {
+	// Get returns the secret reference names.
+	Get(reference string) (string, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{
+		"file":  fileProvider{},
+		"env":   envProvider{},
+		"exec":  execProvider{},
+		"vault": vaultProvider{},
+	}
+)
+
+// Register makes provider read the secrets referenced with scheme,
+// replacing any provider already registered for it.
+func Register(scheme string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[scheme] = provider
+}
+
+// provider returns the provider of value, if it is a reference, its
+// scheme and the reference.
+func provider(value string) (Provider, string, string) {
+	i := strings.Index(value, ":")
+	if i <= 0 {
+		return nil, "", ""
+	}
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return providers[value[:i]], value[:i], value[i+1:]
+}
+
+// IsReference returns true if value is a reference to a registered
+// provider, rather than a literal secret.
+func IsReference(value string) bool {
+	p, _, _ := provider(value)
+	return p != nil
+}
+
+// Enabled returns true if the provider registered for scheme may be used:
+// it is not an opt-in provider, or EnvVar lists it.
+func Enabled(scheme string) bool {
+	if !optIn[scheme] {
+		return true
+	}
+	for _, enabled := range strings.Split(os.Getenv(EnvVar), ",") {
+		if strings.TrimSpace(enabled) == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the secret value references, or value itself if it is a
+// literal secret.  Trailing newlines are trimmed from the secrets
+// providers return.
+func Resolve(value string) (string, error) {
+	p, scheme, reference := provider(value)
+	if p == nil {
+		return value, nil
+	}
+	if !Enabled(scheme) {
+		return "", errors.Errorf("%s: references are disabled; enable them with --credential-providers=%s", value, scheme)
+	}
+	secret, err := p.Get(reference)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", value)
+	}
+	return strings.TrimRight(secret, "\r\n"), nil
+}
+
+// fileProvider reads a secret from the file at the reference.
+type fileProvider struct{}
+
+func (fileProvider) Get(path string) (string, error) {
+	data, err := fileaudit.ReadFile(path, "credentials")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// envProvider reads a secret from the environment variable named by the
+// reference.
+type envProvider struct{}
+
+func (envProvider) Get(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", errors.Errorf("$%s is not set", name)
+	}
+	return value, nil
+}
+
+// execProvider reads a secret from the output of the command line of the
+// reference, which is split on whitespace without a shell.
+type execProvider struct{}
+
+func (execProvider) Get(commandLine string) (string, error) {
+	args := strings.Fields(commandLine)
+	if len(args) == 0 {
+		return "", errors.New("no command")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.Wrap(err, msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// vaultProvider reads a secret from a field of a HashiCorp Vault secret,
+// path#field, using $VAULT_ADDR and $VAULT_TOKEN like the vault CLI.  Both
+// the v1 and v2 key/value secrets engines are supported.
+type vaultProvider struct{}
+
+func (vaultProvider) Get(reference string) (string, error) {
+	i := strings.LastIndex(reference, "#")
+	if i < 0 {
+		return "", errors.New("no field; use vault:path#field")
+	}
+	path, field := strings.Trim(reference[:i], "/"), reference[i+1:]
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", errors.New("$VAULT_ADDR is not set")
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimRight(addr, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("vault returned %s", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", errors.Wrap(err, "failed to parse the vault secret")
+	}
+	data := secret.Data
+	// The v2 key/value engine nests the secret's data, beside its metadata.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", errors.Errorf("the vault secret has no %q field", field)
+	}
+	return value, nil
+}
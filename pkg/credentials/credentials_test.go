@@ -0,0 +1,114 @@
+package credentials
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const pullSecret = `{"auths":{"quay.io":{"auth":"c3VwZXItc2VjcmV0Cg=="}}}`
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pull-secret.json")
+	if err := ioutil.WriteFile(path, []byte(pullSecret+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/ci":
+			w.Write([]byte(`{"data": {"data": {"pullSecret": "` + `{\"auths\":{}}` + `"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/ci":
+			w.Write([]byte(`{"data": {"pullSecret": "v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv(EnvVar, "file,exec,vault")
+	defer os.Unsetenv(EnvVar)
+	os.Setenv("KNI_TEST_PULL_SECRET", pullSecret)
+	defer os.Unsetenv("KNI_TEST_PULL_SECRET")
+	os.Setenv("VAULT_ADDR", server.URL)
+	defer os.Unsetenv("VAULT_ADDR")
+	os.Setenv("VAULT_TOKEN", "s.token")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	cases := []struct {
+		value         string
+		expected      string
+		expectedError string
+	}{
+		{value: pullSecret, expected: pullSecret},
+		{value: "unknown:value", expected: "unknown:value"},
+		{value: "file:" + path, expected: pullSecret},
+		{value: "file:" + filepath.Join(dir, "missing"), expectedError: `^failed to read file:.*/missing: open .*: no such file or directory$`},
+		{value: "env:KNI_TEST_PULL_SECRET", expected: pullSecret},
+		{value: "env:KNI_TEST_UNSET", expectedError: `^failed to read env:KNI_TEST_UNSET: \$KNI_TEST_UNSET is not set$`},
+		{value: "exec:echo secret", expected: "secret"},
+		{value: "exec:false", expectedError: `^failed to read exec:false: exit status 1$`},
+		{value: "vault:secret/data/ci#pullSecret", expected: `{"auths":{}}`},
+		{value: "vault:/kv/ci#pullSecret", expected: "v1"},
+		{value: "vault:secret/data/ci#missing", expectedError: `^failed to read vault:secret/data/ci#missing: the vault secret has no "missing" field$`},
+		{value: "vault:secret/data/other#pullSecret", expectedError: `^failed to read vault:secret/data/other#pullSecret: vault returned 404 Not Found$`},
+		{value: "vault:secret/data/ci", expectedError: `^failed to read vault:secret/data/ci: no field; use vault:path#field$`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.expected != tc.value, IsReference(tc.value))
+			secret, err := Resolve(tc.value)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, secret)
+		})
+	}
+}
+
+func TestResolveDisabled(t *testing.T) {
+	os.Setenv(EnvVar, "vault")
+	defer os.Unsetenv(EnvVar)
+
+	for _, value := range []string{"file:/etc/passwd", "exec:id"} {
+		_, err := Resolve(value)
+		assert.EqualError(t, err, value+": references are disabled; enable them with --credential-providers="+value[:4])
+	}
+	assert.True(t, Enabled("env"))
+	assert.True(t, Enabled("vault"))
+}
+
+type staticProvider string
+
+func (p staticProvider) Get(reference string) (string, error) {
+	return string(p) + reference, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("static", staticProvider("secret-"))
+	defer func() {
+		providersMu.Lock()
+		delete(providers, "static")
+		providersMu.Unlock()
+	}()
+
+	secret, err := Resolve("static:value")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret-value", secret)
+}
@@ -22,6 +22,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	"github.com/metalkube/kni-installer/pkg/destroy"
+	"github.com/metalkube/kni-installer/pkg/types"
 	"github.com/metalkube/kni-installer/pkg/version"
 )
 
@@ -55,6 +57,13 @@ type ClusterUninstaller struct {
 	Filters []Filter // filter(s) we will be searching for
 	Logger  logrus.FieldLogger
 	Region  string
+
+	// Timeout is how long a resource may keep failing to delete before
+	// the uninstaller gives up on it.  Zero means retry forever.
+	Timeout time.Duration
+
+	// Force skips resources which hit Timeout, rather than failing.
+	Force bool
 }
 
 func (o *ClusterUninstaller) validate() error {
@@ -71,17 +80,10 @@ func (o *ClusterUninstaller) Run() error {
 		return err
 	}
 
-	awsConfig := &aws.Config{Region: aws.String(o.Region)}
-
-	// Relying on appropriate AWS ENV vars (eg AWS_PROFILE, AWS_ACCESS_KEY_ID, etc)
-	awsSession, err := session.NewSession(awsConfig)
+	awsSession, err := o.newSession()
 	if err != nil {
 		return err
 	}
-	awsSession.Handlers.Build.PushBackNamed(request.NamedHandler{
-		Name: "openshiftInstaller.OpenshiftInstallerUserAgentHandler",
-		Fn:   request.MakeAddToUserAgentHandler("OpenShift/4.x Destroyer", version.Raw),
-	})
 
 	tagClients := []*resourcegroupstaggingapi.ResourceGroupsTaggingAPI{
 		resourcegroupstaggingapi.New(awsSession),
@@ -110,6 +112,42 @@ func (o *ClusterUninstaller) Run() error {
 		logger:  o.Logger,
 	}
 
+	progress := destroy.NewProgress(o.Logger, 0, o.Force)
+	defer progress.Stop()
+	failingSince := map[string]time.Time{}
+	var timeoutError error
+
+	// tryDelete deletes arn, returning the error so it can be retried.
+	// Once it has been failing for longer than Timeout, it is skipped with
+	// Force, or timeoutError is set.
+	tryDelete := func(arn string, filter Filter) error {
+		resource := arnResource(arn)
+		progress.Add(resource)
+		err := deleteARN(awsSession, arn, filter, o.Logger)
+		if err == nil {
+			deleted[arn] = exists
+			progress.Done(resource)
+			return nil
+		}
+
+		err = errors.Wrapf(err, "deleting %s", arn)
+		o.Logger.Debug(err)
+		if _, ok := failingSince[arn]; !ok {
+			failingSince[arn] = time.Now()
+		}
+		if o.Timeout > 0 && time.Since(failingSince[arn]) > o.Timeout {
+			if !o.Force {
+				timeoutError = errors.Wrapf(err, "timed out after %s", o.Timeout)
+				return err
+			}
+			o.Logger.Warnf("Skipping %s %s: %v", resource.Type, resource.Name, err)
+			deleted[arn] = exists
+			progress.Done(resource)
+			return nil
+		}
+		return err
+	}
+
 	return wait.PollImmediateInfinite(
 		time.Second*10,
 		func() (done bool, err error) {
@@ -146,6 +184,9 @@ func (o *ClusterUninstaller) Run() error {
 							return !lastPage
 						},
 					)
+					if timeoutError != nil {
+						return false, timeoutError
+					}
 					if err != nil {
 						err = errors.Wrap(err, "get tagged resources")
 						o.Logger.Info(err)
@@ -182,14 +223,12 @@ func (o *ClusterUninstaller) Run() error {
 			}
 			for _, arn := range arns {
 				if _, ok := deleted[arn]; !ok {
-					err = deleteARN(awsSession, arn, nil, o.Logger)
-					if err != nil {
-						err = errors.Wrapf(err, "deleting %s", arn)
-						o.Logger.Debug(err)
+					if err := tryDelete(arn, nil); err != nil {
+						if timeoutError != nil {
+							return false, timeoutError
+						}
 						loopError = err
-						continue
 					}
-					deleted[arn] = exists
 				}
 			}
 
@@ -198,6 +237,21 @@ func (o *ClusterUninstaller) Run() error {
 	)
 }
 
+func (o *ClusterUninstaller) newSession() (*session.Session, error) {
+	awsConfig := &aws.Config{Region: aws.String(o.Region)}
+
+	// Relying on appropriate AWS ENV vars (eg AWS_PROFILE, AWS_ACCESS_KEY_ID, etc)
+	awsSession, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	awsSession.Handlers.Build.PushBackNamed(request.NamedHandler{
+		Name: "openshiftInstaller.OpenshiftInstallerUserAgentHandler",
+		Fn:   request.MakeAddToUserAgentHandler("OpenShift/4.x Destroyer", version.Raw),
+	})
+	return awsSession, nil
+}
+
 func splitSlash(name string, input string) (base string, suffix string, err error) {
 	segments := strings.SplitN(input, "/", 2)
 	if len(segments) != 2 {
@@ -1448,3 +1502,22 @@ func deleteS3(session *session.Session, arn arn.ARN, logger logrus.FieldLogger)
 	logger.Info("Deleted")
 	return nil
 }
+
+// New returns an AWS destroyer from ClusterMetadata.
+func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata, opts destroy.Options) (destroy.Destroyer, error) {
+	if !opts.Filter.IsZero() {
+		return nil, errors.New("filters are not supported when destroying AWS clusters")
+	}
+	filters := make([]Filter, 0, len(metadata.ClusterPlatformMetadata.AWS.Identifier))
+	for _, filter := range metadata.ClusterPlatformMetadata.AWS.Identifier {
+		filters = append(filters, filter)
+	}
+
+	return &ClusterUninstaller{
+		Filters: filters,
+		Region:  metadata.ClusterPlatformMetadata.AWS.Region,
+		Logger:  logger,
+		Timeout: opts.Timeout,
+		Force:   opts.Force,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/destroy"
+)
+
+// List returns the resources which Run would delete: everything tagged
+// to match the filters, the matching IAM roles and users, and the record
+// sets in the cluster's hosted zones along with their copies in the shared
+// public zone.
+func (o *ClusterUninstaller) List() ([]destroy.Resource, error) {
+	err := o.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	awsSession, err := o.newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	tagClients := []*resourcegroupstaggingapi.ResourceGroupsTaggingAPI{
+		resourcegroupstaggingapi.New(awsSession),
+	}
+	if o.Region != "us-east-1" {
+		tagClients = append(tagClients, resourcegroupstaggingapi.New(
+			awsSession, aws.NewConfig().WithRegion("us-east-1"),
+		))
+	}
+
+	seen := map[string]struct{}{}
+	var arns []string
+	for _, tagClient := range tagClients {
+		for _, filter := range o.Filters {
+			tagFilters := make([]*resourcegroupstaggingapi.TagFilter, 0, len(filter))
+			for key, value := range filter {
+				tagFilters = append(tagFilters, &resourcegroupstaggingapi.TagFilter{
+					Key:    aws.String(key),
+					Values: []*string{aws.String(value)},
+				})
+			}
+			err = tagClient.GetResourcesPages(
+				&resourcegroupstaggingapi.GetResourcesInput{TagFilters: tagFilters},
+				func(results *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+					for _, resource := range results.ResourceTagMappingList {
+						if _, ok := seen[*resource.ResourceARN]; !ok {
+							seen[*resource.ResourceARN] = exists
+							arns = append(arns, *resource.ResourceARN)
+						}
+					}
+					return !lastPage
+				},
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "get tagged resources")
+			}
+		}
+	}
+
+	iamClient := iam.New(awsSession)
+	roleARNs, err := (&iamRoleSearch{client: iamClient, filters: o.Filters, logger: o.Logger}).arns()
+	if err != nil {
+		return nil, err
+	}
+	userARNs, err := (&iamUserSearch{client: iamClient, filters: o.Filters, logger: o.Logger}).arns()
+	if err != nil {
+		return nil, err
+	}
+	arns = append(arns, roleARNs...)
+	arns = append(arns, userARNs...)
+
+	resources := make([]destroy.Resource, 0, len(arns))
+	for _, arnString := range arns {
+		parsed, err := arn.Parse(arnString)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse ARN %q", arnString)
+		}
+		resources = append(resources, destroy.Resource{Type: arnType(parsed), Name: arnString})
+
+		if parsed.Service == "route53" {
+			recordSets, err := o.listRoute53RecordSets(awsSession, parsed)
+			if err != nil {
+				return nil, errors.Wrapf(err, "list record sets of %s", arnString)
+			}
+			resources = append(resources, recordSets...)
+		}
+	}
+
+	return resources, nil
+}
+
+// arnType returns the service and resource type of an ARN, e.g.
+// "ec2 instance".
+// arnResource returns the Resource for an ARN, falling back to a generic
+// type if it cannot be parsed.
+func arnResource(arnString string) destroy.Resource {
+	parsed, err := arn.Parse(arnString)
+	if err != nil {
+		return destroy.Resource{Type: "aws resource", Name: arnString}
+	}
+	return destroy.Resource{Type: arnType(parsed), Name: arnString}
+}
+
+func arnType(parsed arn.ARN) string {
+	if i := strings.IndexAny(parsed.Resource, "/:"); i >= 0 {
+		return fmt.Sprintf("%s %s", parsed.Service, parsed.Resource[:i])
+	}
+	return parsed.Service
+}
+
+// listRoute53RecordSets returns the record sets deleteRoute53 would delete
+// for a hosted zone.
+func (o *ClusterUninstaller) listRoute53RecordSets(session *session.Session, arn arn.ARN) ([]destroy.Resource, error) {
+	resourceType, id, err := splitSlash("resource", arn.Resource)
+	if err != nil {
+		return nil, err
+	}
+	if resourceType != "hostedzone" {
+		return nil, errors.Errorf("unrecognized Route 53 resource type %s", resourceType)
+	}
+
+	client := route53.New(session)
+	logger := o.Logger.WithField("id", id)
+	sharedZoneID, err := getSharedHostedZone(client, id, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	recordSetKey := func(recordSet *route53.ResourceRecordSet) string {
+		return fmt.Sprintf("%s %s", *recordSet.Type, *recordSet.Name)
+	}
+
+	sharedEntries := map[string]struct{}{}
+	if len(sharedZoneID) != 0 {
+		err = client.ListResourceRecordSetsPages(
+			&route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(sharedZoneID)},
+			func(results *route53.ListResourceRecordSetsOutput, lastPage bool) bool {
+				for _, recordSet := range results.ResourceRecordSets {
+					sharedEntries[recordSetKey(recordSet)] = exists
+				}
+				return !lastPage
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var resources []destroy.Resource
+	err = client.ListResourceRecordSetsPages(
+		&route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(id)},
+		func(results *route53.ListResourceRecordSetsOutput, lastPage bool) bool {
+			for _, recordSet := range results.ResourceRecordSets {
+				if *recordSet.Type == "SOA" || *recordSet.Type == "NS" {
+					continue
+				}
+				key := recordSetKey(recordSet)
+				if _, ok := sharedEntries[key]; ok {
+					resources = append(resources, destroy.Resource{Type: "route53 record set", Name: fmt.Sprintf("%s in public zone %s", key, sharedZoneID)})
+				}
+				resources = append(resources, destroy.Resource{Type: "route53 record set", Name: fmt.Sprintf("%s in zone %s", key, id)})
+			}
+			return !lastPage
+		},
+	)
+	return resources, err
+}
@@ -0,0 +1,10 @@
+// Package aws provides a cluster-destroyer for AWS clusters.
+package aws
+
+import (
+	"github.com/metalkube/kni-installer/pkg/destroy"
+)
+
+func init() {
+	destroy.Registry["aws"] = New
+}
@@ -1,39 +1,310 @@
+// +build libvirt
+
 package baremetal
 
 import (
+	"context"
+	"encoding/xml"
+	"time"
+
 	libvirt "github.com/libvirt/libvirt-go"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/metalkube/kni-installer/pkg/destroy"
+	"github.com/metalkube/kni-installer/pkg/destroy/leases"
 	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
 )
 
 // ClusterUninstaller holds the various options for the cluster we want to delete.
 type ClusterUninstaller struct {
 	LibvirtURI string
+	InfraID    string
+	Groups     destroy.Filter
+	Timeout    time.Duration
+	Force      bool
 	Logger     logrus.FieldLogger
 }
 
-// Run is the entrypoint to start the uninstall process.
+// Run is the entrypoint to start the uninstall process.  Only domains
+// tagged with the cluster's infra ID, and the volumes they reference, are
+// deleted, and the DHCP leases they hold on the (unmanaged) networks they
+// were attached to are released.
 func (o *ClusterUninstaller) Run() error {
 	o.Logger.Debug("Deleting bare metal resources")
 
-	// FIXME: close the connection
-	_, err := libvirt.NewConnect(o.LibvirtURI)
+	conn, err := o.connect()
+	if err != nil {
+		if o.Force {
+			o.Logger.Warnf("Skipping bare metal resources: %v", err)
+			return nil
+		}
+		return err
+	}
+	defer conn.Close()
+
+	progress := destroy.NewProgress(o.Logger, o.Timeout, o.Force)
+	defer progress.Stop()
+	resources, err := o.list(conn)
+	if err != nil {
+		return err
+	}
+	progress.Add(resources...)
+
+	held, err := o.leases(conn)
+	if err != nil {
+		return err
+	}
+
+	o.Logger.Debug("Deleting libvirt domains")
+	var domainXMLs []string
+	err = o.forEachDomain(conn, func(domain *libvirt.Domain, dName, dXML string) error {
+		domainXMLs = append(domainXMLs, dXML)
+		return progress.Delete(destroy.Resource{Type: "libvirt domain", Name: dName}, func(ctx context.Context) error {
+			return o.deleteDomain(ctx, domain, dName)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	o.Logger.Debug("Releasing DHCP leases")
+	for _, lease := range held {
+		lease := lease
+		err := progress.Delete(lease.Resource(), func(ctx context.Context) error {
+			return leases.Release(ctx, conn, o.LibvirtURI, lease, o.Logger)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	o.Logger.Debug("Deleting libvirt volumes")
+	return forEachVolume(conn, domainXMLs, func(vol *libvirt.StorageVol, vPath string) error {
+		return progress.Delete(destroy.Resource{Type: "libvirt volume", Name: vPath}, func(context.Context) error {
+			if err := vol.Delete(0); err != nil {
+				return errors.Wrapf(err, "delete volume %q", vPath)
+			}
+			o.Logger.WithField("volume", vPath).Info("Deleted volume")
+			return nil
+		})
+	})
+}
+
+// List returns the domains, DHCP leases and volumes which Run would delete.
+func (o *ClusterUninstaller) List() ([]destroy.Resource, error) {
+	conn, err := o.connect()
 	if err != nil {
-		return errors.Wrap(err, "failed to connect to Libvirt daemon")
+		return nil, err
 	}
+	defer conn.Close()
+	return o.list(conn)
+}
+
+// connect connects to the hypervisor, giving up after Timeout so an
+// unreachable host does not hang the destroy.
+func (o *ClusterUninstaller) connect() (*libvirt.Connect, error) {
+	var conn *libvirt.Connect
+	err := destroy.WithTimeout(o.Timeout, func(ctx context.Context) error {
+		c, err := libvirt.NewConnect(o.LibvirtURI)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			// The connection is no longer waited for.
+			c.Close()
+			return ctx.Err()
+		}
+		conn = c
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to Libvirt daemon")
+	}
+	return conn, nil
+}
+
+func (o *ClusterUninstaller) list(conn *libvirt.Connect) ([]destroy.Resource, error) {
+	var resources []destroy.Resource
+	var domainXMLs []string
+	err := o.forEachDomain(conn, func(domain *libvirt.Domain, dName, dXML string) error {
+		domainXMLs = append(domainXMLs, dXML)
+		resources = append(resources, destroy.Resource{Type: "libvirt domain", Name: dName})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := leases.Find(conn, domainXMLs)
+	if err != nil {
+		return nil, err
+	}
+	for _, lease := range held {
+		resources = append(resources, lease.Resource())
+	}
+
+	err = forEachVolume(conn, domainXMLs, func(vol *libvirt.StorageVol, vPath string) error {
+		resources = append(resources, destroy.Resource{Type: "libvirt volume", Name: vPath})
+		return nil
+	})
+	return resources, err
+}
 
-	o.Logger.Debug("FIXME: delete resources!")
+// leases returns the DHCP leases and static hosts which the cluster's
+// selected domains hold on libvirt networks.  Run releases them once the
+// domains are deleted.  Addresses from a DHCP server outside libvirt
+// cannot be released.
+func (o *ClusterUninstaller) leases(conn *libvirt.Connect) ([]leases.Lease, error) {
+	var domainXMLs []string
+	err := o.forEachDomain(conn, func(domain *libvirt.Domain, dName, dXML string) error {
+		domainXMLs = append(domainXMLs, dXML)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	held, err := leases.Find(conn, domainXMLs)
+	if err == nil && len(held) == 0 && len(domainXMLs) > 0 {
+		o.Logger.Debug("No libvirt network serves the domains' bridges; leaving their addresses to expire on the DHCP server")
+	}
+	return held, err
+}
+
+// forEachDomain calls fn with each domain tagged with our infra ID and
+// selected by Groups, along with its XML description, which is needed to
+// find the volumes it used.
+func (o *ClusterUninstaller) forEachDomain(conn *libvirt.Connect, fn func(domain *libvirt.Domain, dName, dXML string) error) error {
+	domains, err := conn.ListAllDomains(0)
+	if err != nil {
+		return errors.Wrap(err, "list domains")
+	}
+
+	for _, domain := range domains {
+		defer domain.Free()
+		dName, err := domain.GetName()
+		if err != nil {
+			return errors.Wrap(err, "get domain name")
+		}
+
+		owned, err := o.ownsDomain(&domain)
+		if err != nil {
+			return errors.Wrapf(err, "get metadata of domain %q", dName)
+		}
+		if !owned || !o.Groups.Selects(destroy.MachineGroup(o.InfraID, dName)) {
+			continue
+		}
+
+		dXML, err := domain.GetXMLDesc(0)
+		if err != nil {
+			return errors.Wrapf(err, "get XML of domain %q", dName)
+		}
+		if err := fn(&domain, dName, dXML); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *ClusterUninstaller) deleteDomain(ctx context.Context, domain *libvirt.Domain, dName string) error {
+	dState, _, err := domain.GetState()
+	if err != nil {
+		return errors.Wrapf(err, "get domain state %q", dName)
+	}
+	if dState != libvirt.DOMAIN_SHUTOFF && dState != libvirt.DOMAIN_SHUTDOWN {
+		if err := domain.Destroy(); err != nil {
+			return errors.Wrapf(err, "destroy domain %q", dName)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := domain.Undefine(); err != nil {
+		return errors.Wrapf(err, "undefine domain %q", dName)
+	}
+	o.Logger.WithField("domain", dName).Info("Deleted domain")
+	return nil
+}
+
+// ownsDomain returns true if the domain's metadata carries our infra ID.
+func (o *ClusterUninstaller) ownsDomain(domain *libvirt.Domain) (bool, error) {
+	raw, err := domain.GetMetadata(libvirt.DOMAIN_METADATA_ELEMENT, baremetal.DomainMetadataNamespace, libvirt.DOMAIN_AFFECT_CONFIG)
+	if err != nil {
+		if lerr, ok := err.(libvirt.Error); ok && lerr.Code == libvirt.ERR_NO_DOMAIN_METADATA {
+			return false, nil
+		}
+		return false, err
+	}
+
+	metadata := &baremetal.DomainMetadata{}
+	if err := xml.Unmarshal([]byte(raw), metadata); err != nil {
+		return false, err
+	}
+	return metadata.InfraID == o.InfraID, nil
+}
+
+// forEachVolume calls fn with each volume whose path is referenced by the
+// given domains, e.g. their disks and Ignition configs.
+func forEachVolume(conn *libvirt.Connect, domainXMLs []string, fn func(vol *libvirt.StorageVol, vPath string) error) error {
+	if len(domainXMLs) == 0 {
+		return nil
+	}
+
+	refs, err := referencedStorage(domainXMLs)
+	if err != nil {
+		return err
+	}
+
+	pools, err := conn.ListAllStoragePools(0)
+	if err != nil {
+		return errors.Wrap(err, "list storage pools")
+	}
+
+	for _, pool := range pools {
+		defer pool.Free()
+		poolName, err := pool.GetName()
+		if err != nil {
+			return errors.Wrap(err, "get pool name")
+		}
+		vols, err := pool.ListAllStorageVolumes(0)
+		if err != nil {
+			return errors.Wrap(err, "list volumes")
+		}
+		for _, vol := range vols {
+			defer vol.Free()
+			vName, err := vol.GetName()
+			if err != nil {
+				return errors.Wrap(err, "get volume name")
+			}
+			vPath, err := vol.GetPath()
+			if err != nil {
+				return errors.Wrap(err, "get volume path")
+			}
+			if !refs.referenced(poolName, vName, vPath) {
+				continue
+			}
+			if err := fn(&vol, vPath); err != nil {
+				return err
+			}
+		}
+	}
 
 	return nil
 }
 
 // New returns bare metal Uninstaller from ClusterMetadata.
-func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (destroy.Destroyer, error) {
+func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata, opts destroy.Options) (destroy.Destroyer, error) {
+	if metadata.InfraID == "" {
+		return nil, errors.New("no infra ID in metadata; refusing to select resources to delete")
+	}
 	return &ClusterUninstaller{
 		LibvirtURI: metadata.ClusterPlatformMetadata.BareMetal.URI,
+		InfraID:    metadata.InfraID,
+		Groups:     opts.Filter,
+		Timeout:    opts.Timeout,
+		Force:      opts.Force,
 		Logger:     logger,
 	}, nil
 }
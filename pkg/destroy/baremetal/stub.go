@@ -0,0 +1,18 @@
+// +build !libvirt
+
+package baremetal
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/destroy"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// New returns an error, because the bare metal destroyer needs to talk
+// to the local libvirt daemon and this binary was built without libvirt
+// support.
+func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata, opts destroy.Options) (destroy.Destroyer, error) {
+	return nil, errors.New("destroying bare metal clusters requires kni-install to be built with the libvirt tag (TAGS=libvirt hack/build.sh)")
+}
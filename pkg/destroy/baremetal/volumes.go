@@ -0,0 +1,76 @@
+package baremetal
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type domainXML struct {
+	Disks []struct {
+		Source struct {
+			File   string `xml:"file,attr"`
+			Dev    string `xml:"dev,attr"`
+			Pool   string `xml:"pool,attr"`
+			Volume string `xml:"volume,attr"`
+		} `xml:"source"`
+	} `xml:"devices>disk"`
+	QEMUArgs []struct {
+		Value string `xml:"value,attr"`
+	} `xml:"commandline>arg"`
+}
+
+// referencedVolumes holds the storage referenced by a set of domains.
+type referencedVolumes struct {
+	// paths are the files and block devices backing disks and the
+	// files passed to QEMU through fw_cfg (the Ignition configs).
+	paths map[string]bool
+
+	// volumes are the disks sourced from a pool, keyed by pool and
+	// then by volume name.
+	volumes map[string]map[string]bool
+}
+
+// referencedStorage parses domainXMLs and returns the storage the domains
+// reference.  Paths are compared exactly, so a volume whose path is a
+// prefix of another domain's disk is not picked up.
+func referencedStorage(domainXMLs []string) (*referencedVolumes, error) {
+	refs := &referencedVolumes{
+		paths:   map[string]bool{},
+		volumes: map[string]map[string]bool{},
+	}
+	for _, dXML := range domainXMLs {
+		domain := &domainXML{}
+		if err := xml.Unmarshal([]byte(dXML), domain); err != nil {
+			return nil, errors.Wrap(err, "failed to parse domain XML")
+		}
+		for _, disk := range domain.Disks {
+			for _, path := range []string{disk.Source.File, disk.Source.Dev} {
+				if path != "" {
+					refs.paths[path] = true
+				}
+			}
+			if disk.Source.Pool != "" && disk.Source.Volume != "" {
+				if refs.volumes[disk.Source.Pool] == nil {
+					refs.volumes[disk.Source.Pool] = map[string]bool{}
+				}
+				refs.volumes[disk.Source.Pool][disk.Source.Volume] = true
+			}
+		}
+		for _, arg := range domain.QEMUArgs {
+			for _, option := range strings.Split(arg.Value, ",") {
+				if strings.HasPrefix(option, "file=") {
+					refs.paths[strings.TrimPrefix(option, "file=")] = true
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// referenced returns true if the volume, given by its pool, name and
+// path, is referenced by one of the domains.
+func (r *referencedVolumes) referenced(pool, name, path string) bool {
+	return r.paths[path] || r.volumes[pool][name]
+}
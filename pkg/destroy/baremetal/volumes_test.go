@@ -0,0 +1,82 @@
+package baremetal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bootstrapXML = `<domain type='kvm' xmlns:qemu='http://libvirt.org/schemas/domain/qemu/1.0'>
+  <name>test-abcde-bootstrap</name>
+  <devices>
+    <disk type='file' device='disk'>
+      <source file='/var/lib/libvirt/images/test-abcde-bootstrap'/>
+    </disk>
+    <disk type='volume' device='disk'>
+      <source pool='default' volume='test-abcde-bootstrap-data'/>
+    </disk>
+  </devices>
+  <qemu:commandline>
+    <qemu:arg value='-fw_cfg'/>
+    <qemu:arg value='name=opt/com.coreos/config,file=/var/lib/libvirt/images/test-abcde-bootstrap.ign'/>
+  </qemu:commandline>
+</domain>`
+
+func TestReferencedStorage(t *testing.T) {
+	refs, err := referencedStorage([]string{bootstrapXML})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cases := []struct {
+		name       string
+		pool       string
+		volume     string
+		path       string
+		referenced bool
+	}{
+		{
+			name:       "disk",
+			pool:       "default",
+			volume:     "test-abcde-bootstrap",
+			path:       "/var/lib/libvirt/images/test-abcde-bootstrap",
+			referenced: true,
+		},
+		{
+			name:       "ignition",
+			pool:       "default",
+			volume:     "test-abcde-bootstrap.ign",
+			path:       "/var/lib/libvirt/images/test-abcde-bootstrap.ign",
+			referenced: true,
+		},
+		{
+			name:       "pool volume",
+			pool:       "default",
+			volume:     "test-abcde-bootstrap-data",
+			path:       "/var/lib/libvirt/images/test-abcde-bootstrap-data",
+			referenced: true,
+		},
+		{
+			name:   "path prefix",
+			pool:   "default",
+			volume: "test",
+			path:   "/var/lib/libvirt/images/test",
+		},
+		{
+			name:   "same volume name in another pool",
+			pool:   "other",
+			volume: "test-abcde-bootstrap-data",
+			path:   "/srv/images/test-abcde-bootstrap-data",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.referenced, refs.referenced(tc.pool, tc.volume, tc.path))
+		})
+	}
+}
+
+func TestReferencedStorageInvalid(t *testing.T) {
+	_, err := referencedStorage([]string{"<domain"})
+	assert.Regexp(t, "^failed to parse domain XML: ", err)
+}
@@ -2,6 +2,7 @@
 package bootstrap
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,13 +10,20 @@ import (
 	"strings"
 
 	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/destroy/leases"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 	"github.com/metalkube/kni-installer/pkg/terraform"
+	"github.com/metalkube/kni-installer/pkg/terraform/state"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
-// Destroy uses Terraform to remove bootstrap resources.
-func Destroy(dir string) (err error) {
+// Destroy uses Terraform to remove bootstrap resources, holding the lock
+// on the cluster's Terraform state.
+func Destroy(ctx context.Context, dir string) (err error) {
 	metadata, err := cluster.LoadMetadata(dir)
 	if err != nil {
 		return err
@@ -25,15 +33,30 @@ func Destroy(dir string) (err error) {
 	if platform == "" {
 		return errors.New("no platform configured in metadata")
 	}
+	if metadata.Simulated {
+		// The simulated cluster's resources are only in the state file,
+		// on the in-memory backend.
+		platform = fake.Name
+	}
 
+	store, err := state.ForCluster(dir, metadata.TerraformState)
+	if err != nil {
+		return err
+	}
+	return state.WithLock(ctx, store, "destroy bootstrap", func() error {
+		return destroy(ctx, dir, metadata, platform, store)
+	})
+}
+
+func destroy(ctx context.Context, dir string, metadata *types.ClusterMetadata, platform string, store state.Store) (err error) {
 	tfPlatformVarsFileName := fmt.Sprintf(cluster.TfPlatformVarsFileName, platform)
-	copyNames := []string{terraform.StateFileName, cluster.TfVarsFileName, tfPlatformVarsFileName}
+	copyNames := []string{cluster.TfVarsFileName, tfPlatformVarsFileName}
 
 	if platform == libvirt.Name {
-		err = ioutil.WriteFile(filepath.Join(dir, "disable-bootstrap.tfvars"), []byte(`{
+		err = fileaudit.WriteFile(filepath.Join(dir, "disable-bootstrap.tfvars"), []byte(`{
   "bootstrap_dns": false
 }
-`), 0666)
+`), 0666, "Terraform variables")
 		if err != nil {
 			return err
 		}
@@ -44,7 +67,22 @@ func Destroy(dir string) (err error) {
 	if err != nil {
 		return errors.Wrap(err, "failed to create temporary directory for Terraform execution")
 	}
-	defer os.RemoveAll(tempDir)
+	defer func() {
+		os.RemoveAll(tempDir)
+		fileaudit.Record(fileaudit.Remove, tempDir, "Terraform working directory", nil)
+	}()
+
+	data, err := store.Read(ctx)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return errors.Errorf("there is no Terraform state in %s", store)
+	}
+	err = fileaudit.WriteFile(filepath.Join(tempDir, terraform.StateFileName), data, 0600, "Terraform input")
+	if err != nil {
+		return err
+	}
 
 	extraArgs := []string{}
 	for _, filename := range copyNames {
@@ -63,31 +101,77 @@ func Destroy(dir string) (err error) {
 	}
 
 	if platform == libvirt.Name {
-		_, err = terraform.Apply(tempDir, platform, extraArgs...)
+		_, err = terraform.Apply(ctx, tempDir, platform, extraArgs...)
 		if err != nil {
-			return errors.Wrap(err, "Terraform apply")
+			return saveDebugArtifacts(ctx, dir, tempDir, "bootstrap-apply", err, "Terraform apply", extraArgs...)
 		}
 	}
 
+	releaseLeases := bootstrapLeases(metadata)
+
 	extraArgs = append(extraArgs, "-target=module.bootstrap")
-	err = terraform.Destroy(tempDir, platform, extraArgs...)
+	err = terraform.Destroy(ctx, tempDir, platform, extraArgs...)
 	if err != nil {
-		return errors.Wrap(err, "Terraform destroy")
+		return saveDebugArtifacts(ctx, dir, tempDir, "bootstrap-destroy", err, "Terraform destroy", append(extraArgs, "-destroy")...)
+	}
+	if err := releaseLeases(); err != nil {
+		logrus.Warnf("Failed to release the bootstrap machine's DHCP leases: %v", err)
 	}
 
-	tempStateFilePath := filepath.Join(dir, terraform.StateFileName+".new")
-	err = copy(filepath.Join(tempDir, terraform.StateFileName), tempStateFilePath)
+	data, err = fileaudit.ReadFile(filepath.Join(tempDir, terraform.StateFileName), "Terraform state")
 	if err != nil {
-		return errors.Wrapf(err, "failed to copy %s from the temporary directory", terraform.StateFileName)
+		return errors.Wrapf(err, "failed to read %s from the temporary directory", terraform.StateFileName)
+	}
+	return store.Write(ctx, data)
+}
+
+// bootstrapLeases finds the DHCP leases held by the bootstrap machines of
+// libvirt and bare metal clusters, which Terraform does not release, and
+// returns a function which releases them.  Failing to find them does not
+// stop the bootstrap machines being destroyed.  Simulated clusters hold no
+// leases.
+func bootstrapLeases(metadata *types.ClusterMetadata) func() error {
+	var uri string
+	switch {
+	case metadata.Libvirt != nil:
+		uri = metadata.Libvirt.URI
+	case metadata.BareMetal != nil:
+		uri = metadata.BareMetal.URI
+	}
+	noop := func() error { return nil }
+	if uri == "" || metadata.InfraID == "" || metadata.Simulated {
+		return noop
+	}
+	release, err := leases.Bootstrap(uri, metadata.InfraID, logrus.StandardLogger())
+	if err != nil {
+		logrus.Warnf("Failed to find the bootstrap machine's DHCP leases: %v", err)
+		return noop
+	}
+	return release
+}
+
+// saveDebugArtifacts writes the debug artifacts of a failed Terraform stage
+// run in tempDir to the stage's debug directory in the asset directory,
+// and returns the stage's error, wrapped with message and pointing at them.
+func saveDebugArtifacts(ctx context.Context, dir, tempDir, stage string, err error, message string, planArgs ...string) error {
+	debugDir := filepath.Join(terraform.DebugDir, stage)
+	if err2 := os.MkdirAll(filepath.Join(dir, debugDir), 0700); err2 != nil {
+		logrus.Errorf("Failed to create %s: %v", debugDir, err2)
+		return errors.Wrap(err, message)
+	}
+	for name, data := range terraform.DebugArtifacts(ctx, tempDir, planArgs...) {
+		if err2 := fileaudit.WriteFile(filepath.Join(dir, debugDir, name), data, 0600, "Terraform debug artifact"); err2 != nil {
+			logrus.Errorf("Failed to write %s: %v", filepath.Join(debugDir, name), err2)
+		}
 	}
-	return os.Rename(tempStateFilePath, filepath.Join(dir, terraform.StateFileName))
+	return errors.Wrapf(err, "%s (Terraform's plan, state and debug log are in %s)", message, debugDir)
 }
 
 func copy(from string, to string) error {
-	data, err := ioutil.ReadFile(from)
+	data, err := fileaudit.ReadFile(from, "Terraform input")
 	if err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(to, data, 0666)
+	return fileaudit.WriteFile(to, data, 0666, "Terraform input")
 }
@@ -1,11 +1,14 @@
 package destroy
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/metalkube/kni-installer/pkg/asset/cluster"
 	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 )
 
 // Destroyer allows multiple implementations of destroy
@@ -14,14 +17,44 @@ type Destroyer interface {
 	Run() error
 }
 
+// Resource is a cluster resource which a Destroyer removes.
+type Resource struct {
+	// Type is the kind of resource, e.g. "libvirt domain" or "ec2 instance".
+	Type string `json:"type"`
+
+	// Name identifies the resource, e.g. a domain name or an ARN.
+	Name string `json:"name"`
+}
+
+// Lister is implemented by Destroyers which can report the resources
+// they would remove without removing anything.
+type Lister interface {
+	List() ([]Resource, error)
+}
+
+// Options tune how a Destroyer removes the cluster.
+type Options struct {
+	// Filter selects which of the cluster's resources are destroyed.
+	Filter Filter
+
+	// Timeout bounds how long deleting a single resource, or connecting
+	// to the platform, may take.  Zero means no limit.
+	Timeout time.Duration
+
+	// Force skips resources which cannot be reached or deleted, logging
+	// warnings instead of failing.
+	Force bool
+}
+
 // NewFunc is an interface for creating platform-specific destroyers.
-type NewFunc func(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (Destroyer, error)
+type NewFunc func(logger logrus.FieldLogger, metadata *types.ClusterMetadata, opts Options) (Destroyer, error)
 
 // Registry maps ClusterMetadata.Platform() to per-platform Destroyer creators.
 var Registry = make(map[string]NewFunc)
 
-// New returns a Destroyer based on `metadata.json` in `rootDir`.
-func New(logger logrus.FieldLogger, rootDir string) (Destroyer, error) {
+// New returns a Destroyer based on `metadata.json` in `rootDir`.  Simulated
+// clusters are destroyed like fake ones, on Terraform's in-memory backend.
+func New(logger logrus.FieldLogger, rootDir string, opts Options) (Destroyer, error) {
 	metadata, err := cluster.LoadMetadata(rootDir)
 	if err != nil {
 		return nil, err
@@ -31,10 +64,13 @@ func New(logger logrus.FieldLogger, rootDir string) (Destroyer, error) {
 	if platform == "" {
 		return nil, errors.New("no platform configured in metadata")
 	}
+	if metadata.Simulated {
+		platform = fake.Name
+	}
 
 	creator, ok := Registry[platform]
 	if !ok {
 		return nil, errors.Errorf("no destroyers registered for %q", platform)
 	}
-	return creator(logger, metadata)
+	return creator(logger, metadata, opts)
 }
@@ -0,0 +1,4 @@
+// Package fake provides a cluster-destroyer for fake clusters, which
+// forgets them in Terraform's in-memory backend.  Simulated clusters are
+// destroyed with it too.
+package fake
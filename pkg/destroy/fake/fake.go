@@ -0,0 +1,51 @@
+package fake
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/destroy"
+	"github.com/metalkube/kni-installer/pkg/terraform"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// ClusterUninstaller holds the various options for the cluster we want to delete.
+type ClusterUninstaller struct {
+	ClusterID string
+	Logger    logrus.FieldLogger
+}
+
+// Run is the entrypoint to start the uninstall process.  Clusters
+// applied by another process are not live in this one, so there is
+// nothing to remove.
+func (o *ClusterUninstaller) Run() error {
+	if !terraform.Memory.Remove(o.ClusterID) {
+		o.Logger.Debugf("Cluster %s is not live in the in-memory backend", o.ClusterID)
+	}
+	return nil
+}
+
+// List returns the cluster's live resources in the in-memory backend.
+func (o *ClusterUninstaller) List() ([]destroy.Resource, error) {
+	cluster, ok := terraform.Memory.Cluster(o.ClusterID)
+	if !ok {
+		return nil, nil
+	}
+	resources := make([]destroy.Resource, 0, len(cluster.Resources))
+	for _, address := range cluster.Resources {
+		resources = append(resources, destroy.Resource{Type: "fake resource", Name: address})
+	}
+	return resources, nil
+}
+
+// New returns fake Uninstaller from ClusterMetadata.  Simulated clusters of
+// other platforms are recorded under their infra ID.
+func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata, opts destroy.Options) (destroy.Destroyer, error) {
+	clusterID := metadata.InfraID
+	if metadata.Fake != nil {
+		clusterID = metadata.Fake.ClusterID
+	}
+	return &ClusterUninstaller{
+		ClusterID: clusterID,
+		Logger:    logger,
+	}, nil
+}
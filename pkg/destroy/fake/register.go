@@ -0,0 +1,9 @@
+package fake
+
+import (
+	"github.com/metalkube/kni-installer/pkg/destroy"
+)
+
+func init() {
+	destroy.Registry["fake"] = New
+}
@@ -0,0 +1,102 @@
+package destroy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Resource groups which a Filter can keep or select.
+const (
+	// GroupBootstrap is the bootstrap machine and its volumes.
+	GroupBootstrap = "bootstrap"
+	// GroupMasters are the control-plane machines and their volumes.
+	GroupMasters = "masters"
+	// GroupWorkers are the compute machines and their volumes.
+	GroupWorkers = "workers"
+	// GroupNetwork is the cluster network.
+	GroupNetwork = "network"
+	// GroupStorage is shared storage, e.g. base images and storage pools.
+	GroupStorage = "storage"
+)
+
+var groups = []string{GroupBootstrap, GroupMasters, GroupNetwork, GroupStorage, GroupWorkers}
+
+// Filter selects which resource groups of a cluster are destroyed.  The
+// zero Filter selects everything.
+type Filter struct {
+	// Only, if set, limits destruction to these groups.  Resources which
+	// do not belong to a known group are kept.
+	Only []string
+
+	// Keep are groups which are not destroyed.
+	Keep []string
+}
+
+// ParseFilter parses filter expressions of the form only=<group> and
+// keep=<group>.
+func ParseFilter(exprs []string) (Filter, error) {
+	filter := Filter{}
+	for _, expr := range exprs {
+		parts := strings.SplitN(expr, "=", 2)
+		if len(parts) != 2 {
+			return filter, errors.Errorf("invalid filter %q: must be only=<group> or keep=<group>", expr)
+		}
+		group := parts[1]
+		if i := sort.SearchStrings(groups, group); i == len(groups) || groups[i] != group {
+			return filter, errors.Errorf("invalid filter %q: group must be one of %s", expr, strings.Join(groups, ", "))
+		}
+		switch parts[0] {
+		case "only":
+			filter.Only = append(filter.Only, group)
+		case "keep":
+			filter.Keep = append(filter.Keep, group)
+		default:
+			return filter, errors.Errorf("invalid filter %q: must be only=<group> or keep=<group>", expr)
+		}
+	}
+	return filter, nil
+}
+
+// IsZero returns true if the filter selects everything.
+func (f Filter) IsZero() bool {
+	return len(f.Only) == 0 && len(f.Keep) == 0
+}
+
+// Selects returns true if resources in the group should be destroyed.  An
+// empty group is used for resources which do not belong to a known group.
+func (f Filter) Selects(group string) bool {
+	for _, keep := range f.Keep {
+		if keep == group {
+			return false
+		}
+	}
+	if len(f.Only) == 0 {
+		return true
+	}
+	for _, only := range f.Only {
+		if only == group {
+			return true
+		}
+	}
+	return false
+}
+
+// MachineGroup returns the group of a machine or volume named after the
+// cluster's infra ID, e.g. <infraID>-worker-0-x7k2p, or an empty string
+// if the name does not match a machine role.
+func MachineGroup(infraID, name string) string {
+	role := strings.TrimPrefix(name, infraID+"-")
+	switch {
+	case strings.HasPrefix(role, "bootstrap"):
+		return GroupBootstrap
+	case strings.HasPrefix(role, "master"):
+		return GroupMasters
+	case strings.HasPrefix(role, "worker"):
+		return GroupWorkers
+	case strings.HasPrefix(role, "base"):
+		return GroupStorage
+	}
+	return ""
+}
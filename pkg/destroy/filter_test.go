@@ -0,0 +1,109 @@
+package destroy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	cases := []struct {
+		name          string
+		exprs         []string
+		expected      Filter
+		expectedError string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:     "only and keep",
+			exprs:    []string{"only=workers", "keep=network", "only=bootstrap"},
+			expected: Filter{Only: []string{GroupWorkers, GroupBootstrap}, Keep: []string{GroupNetwork}},
+		},
+		{
+			name:          "no operator",
+			exprs:         []string{"workers"},
+			expectedError: `invalid filter "workers": must be only=<group> or keep=<group>`,
+		},
+		{
+			name:          "unknown operator",
+			exprs:         []string{"drop=workers"},
+			expectedError: `invalid filter "drop=workers": must be only=<group> or keep=<group>`,
+		},
+		{
+			name:          "unknown group",
+			exprs:         []string{"only=computes"},
+			expectedError: `invalid filter "only=computes": group must be one of bootstrap, masters, network, storage, workers`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ParseFilter(tc.exprs)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, filter)
+		})
+	}
+}
+
+func TestFilterSelects(t *testing.T) {
+	cases := []struct {
+		name     string
+		filter   Filter
+		selected []string
+		kept     []string
+	}{
+		{
+			name:     "zero",
+			selected: []string{GroupBootstrap, GroupMasters, GroupNetwork, GroupStorage, GroupWorkers, ""},
+		},
+		{
+			name:     "only workers",
+			filter:   Filter{Only: []string{GroupWorkers}},
+			selected: []string{GroupWorkers},
+			kept:     []string{GroupBootstrap, GroupMasters, GroupNetwork, GroupStorage, ""},
+		},
+		{
+			name:     "keep network",
+			filter:   Filter{Keep: []string{GroupNetwork}},
+			selected: []string{GroupBootstrap, GroupMasters, GroupStorage, GroupWorkers, ""},
+			kept:     []string{GroupNetwork},
+		},
+		{
+			name:     "keep wins over only",
+			filter:   Filter{Only: []string{GroupWorkers, GroupStorage}, Keep: []string{GroupStorage}},
+			selected: []string{GroupWorkers},
+			kept:     []string{GroupStorage, GroupMasters},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, len(tc.filter.Only) == 0 && len(tc.filter.Keep) == 0, tc.filter.IsZero())
+			for _, group := range tc.selected {
+				assert.True(t, tc.filter.Selects(group), "group %q", group)
+			}
+			for _, group := range tc.kept {
+				assert.False(t, tc.filter.Selects(group), "group %q", group)
+			}
+		})
+	}
+}
+
+func TestMachineGroup(t *testing.T) {
+	for name, expected := range map[string]string{
+		"test-x7k2p-bootstrap":        GroupBootstrap,
+		"test-x7k2p-bootstrap.ign":    GroupBootstrap,
+		"test-x7k2p-master-0":         GroupMasters,
+		"test-x7k2p-worker-0-abcde":   GroupWorkers,
+		"test-x7k2p-base":             GroupStorage,
+		"test-x7k2p":                  "",
+		"other-x7k2p-worker-0-abcde":  "",
+		"test-x7k2p-master-etcd-data": GroupMasters,
+	} {
+		assert.Equal(t, expected, MachineGroup("test-x7k2p", name), name)
+	}
+}
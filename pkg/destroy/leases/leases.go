@@ -0,0 +1,135 @@
+// Package leases finds and releases the addresses a cluster's libvirt
+// domains hold on libvirt networks which outlive them, so long-lived
+// development hypervisors do not run out of addresses.
+//
+// Leases handed out from a network's DHCP range are released with
+// dnsmasq's dhcp_release, which only works on the hypervisor itself.
+// Static host entries, which the Terraform libvirt provider adds to a
+// network for each address it assigns but never removes, are deleted
+// through libvirt.
+package leases
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/destroy"
+)
+
+// Lease is an address a domain holds on a libvirt network.
+type Lease struct {
+	// Network is the name of the libvirt network.
+	Network string
+
+	// Bridge is the network's bridge, which dnsmasq serves.
+	Bridge string
+
+	// MAC is the hardware address of the domain's interface.
+	MAC string
+
+	// IP is the leased address.
+	IP string
+
+	// Static is true for a host entry in the network's DHCP
+	// configuration, and false for a lease from its DHCP range.
+	Static bool
+}
+
+// Resource returns the lease as a resource for destroy's progress and
+// listing.
+func (l Lease) Resource() destroy.Resource {
+	typ := "libvirt DHCP lease"
+	if l.Static {
+		typ = "libvirt DHCP host"
+	}
+	return destroy.Resource{Type: typ, Name: fmt.Sprintf("%s/%s/%s", l.Network, l.MAC, l.IP)}
+}
+
+// HostXML returns the network host entry of a static lease, as
+// virNetworkUpdate expects it.
+func (l Lease) HostXML() string {
+	return fmt.Sprintf("<host mac='%s' ip='%s'/>", l.MAC, l.IP)
+}
+
+type domainXML struct {
+	Interfaces []struct {
+		MAC struct {
+			Address string `xml:"address,attr"`
+		} `xml:"mac"`
+	} `xml:"devices>interface"`
+}
+
+// MACs returns the (lower-case) hardware addresses of the interfaces of
+// the domains described by domainXMLs.
+func MACs(domainXMLs []string) (map[string]bool, error) {
+	macs := map[string]bool{}
+	for _, dXML := range domainXMLs {
+		domain := &domainXML{}
+		if err := xml.Unmarshal([]byte(dXML), domain); err != nil {
+			return nil, errors.Wrap(err, "failed to parse domain XML")
+		}
+		for _, iface := range domain.Interfaces {
+			if iface.MAC.Address != "" {
+				macs[strings.ToLower(iface.MAC.Address)] = true
+			}
+		}
+	}
+	return macs, nil
+}
+
+type networkXML struct {
+	IPs []struct {
+		Hosts []struct {
+			MAC string `xml:"mac,attr"`
+			IP  string `xml:"ip,attr"`
+		} `xml:"dhcp>host"`
+	} `xml:"ip"`
+}
+
+// StaticLeases returns the host entries of the network described by
+// nXML whose hardware addresses are in macs.
+func StaticLeases(network, bridge, nXML string, macs map[string]bool) ([]Lease, error) {
+	parsed := &networkXML{}
+	if err := xml.Unmarshal([]byte(nXML), parsed); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse XML of network %q", network)
+	}
+	var leases []Lease
+	for _, ip := range parsed.IPs {
+		for _, host := range ip.Hosts {
+			if host.IP != "" && macs[strings.ToLower(host.MAC)] {
+				leases = append(leases, Lease{Network: network, Bridge: bridge, MAC: strings.ToLower(host.MAC), IP: host.IP, Static: true})
+			}
+		}
+	}
+	return leases, nil
+}
+
+// ErrRemote is returned by ReleaseCommand when the hypervisor is not the
+// local host, so its dnsmasq cannot be asked to release leases.
+var ErrRemote = errors.New("dhcp_release only runs on the hypervisor")
+
+// ReleaseCommand returns the dhcp_release command which releases a lease
+// from a network's range on the hypervisor at uri.
+func ReleaseCommand(ctx context.Context, uri string, lease Lease) (*exec.Cmd, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse libvirt URI %q", uri)
+	}
+	if u.Host != "" {
+		return nil, ErrRemote
+	}
+	if i := strings.Index(u.Scheme, "+"); i >= 0 && u.Scheme[i+1:] != "unix" {
+		return nil, ErrRemote
+	}
+	path, err := exec.LookPath("dhcp_release")
+	if err != nil {
+		return nil, errors.Wrap(err, "dhcp_release (from dnsmasq) is required to release leases")
+	}
+	return exec.CommandContext(ctx, path, lease.Bridge, lease.IP, lease.MAC), nil
+}
@@ -0,0 +1,84 @@
+package leases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/destroy"
+)
+
+const bootstrapXML = `<domain type='kvm'>
+  <name>test-abcde-bootstrap</name>
+  <devices>
+    <interface type='bridge'>
+      <mac address='52:54:00:AA:BB:01'/>
+      <source bridge='baremetal'/>
+    </interface>
+    <interface type='bridge'>
+      <mac address='52:54:00:aa:bb:02'/>
+      <source bridge='provisioning'/>
+    </interface>
+  </devices>
+</domain>`
+
+const baremetalNetworkXML = `<network>
+  <name>baremetal</name>
+  <bridge name='baremetal'/>
+  <ip address='192.168.111.1' netmask='255.255.255.0'>
+    <dhcp>
+      <range start='192.168.111.20' end='192.168.111.60'/>
+      <host mac='52:54:00:aa:bb:01' name='test-abcde-bootstrap' ip='192.168.111.10'/>
+      <host mac='52:54:00:cc:dd:01' name='master-0' ip='192.168.111.20'/>
+    </dhcp>
+  </ip>
+</network>`
+
+func TestStaticLeases(t *testing.T) {
+	macs, err := MACs([]string{bootstrapXML})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]bool{"52:54:00:aa:bb:01": true, "52:54:00:aa:bb:02": true}, macs)
+
+	leases, err := StaticLeases("baremetal", "baremetal", baremetalNetworkXML, macs)
+	if !assert.NoError(t, err) {
+		return
+	}
+	expected := Lease{Network: "baremetal", Bridge: "baremetal", MAC: "52:54:00:aa:bb:01", IP: "192.168.111.10", Static: true}
+	assert.Equal(t, []Lease{expected}, leases)
+	assert.Equal(t, destroy.Resource{Type: "libvirt DHCP host", Name: "baremetal/52:54:00:aa:bb:01/192.168.111.10"}, expected.Resource())
+	assert.Equal(t, "<host mac='52:54:00:aa:bb:01' ip='192.168.111.10'/>", expected.HostXML())
+
+	_, err = MACs([]string{"<domain>"})
+	assert.Error(t, err)
+}
+
+func TestReleaseCommand(t *testing.T) {
+	lease := Lease{Network: "baremetal", Bridge: "baremetal", MAC: "52:54:00:aa:bb:01", IP: "192.168.111.21"}
+	cases := []struct {
+		uri    string
+		remote bool
+	}{
+		{uri: "qemu:///system"},
+		{uri: "qemu+unix:///system"},
+		{uri: "qemu+ssh://root@hypervisor/system", remote: true},
+		{uri: "qemu+tcp://192.168.122.1/system", remote: true},
+		{uri: "qemu://hypervisor/system", remote: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.uri, func(t *testing.T) {
+			cmd, err := ReleaseCommand(context.Background(), tc.uri, lease)
+			if tc.remote {
+				assert.Equal(t, ErrRemote, err)
+				return
+			}
+			if err != nil {
+				assert.Contains(t, err.Error(), "dhcp_release")
+				return
+			}
+			assert.Equal(t, []string{"baremetal", "192.168.111.21", "52:54:00:aa:bb:01"}, cmd.Args[1:])
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// +build libvirt
+
+package leases
+
+import (
+	"context"
+	"strings"
+
+	libvirt "github.com/libvirt/libvirt-go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Find returns the leases and static host entries which the domains
+// described by domainXMLs hold on the hypervisor's active networks.  Call
+// it before the domains are deleted, as their interfaces are only known
+// from their XML.
+func Find(conn *libvirt.Connect, domainXMLs []string) ([]Lease, error) {
+	macs, err := MACs(domainXMLs)
+	if err != nil || len(macs) == 0 {
+		return nil, err
+	}
+
+	networks, err := conn.ListAllNetworks(libvirt.CONNECT_LIST_NETWORKS_ACTIVE)
+	if err != nil {
+		return nil, errors.Wrap(err, "list networks")
+	}
+
+	var leases []Lease
+	for _, network := range networks {
+		defer network.Free()
+		nName, err := network.GetName()
+		if err != nil {
+			return nil, errors.Wrap(err, "get network name")
+		}
+		bridge, err := network.GetBridgeName()
+		if err != nil {
+			return nil, errors.Wrapf(err, "get bridge of network %q", nName)
+		}
+		nXML, err := network.GetXMLDesc(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get XML of network %q", nName)
+		}
+
+		static, err := StaticLeases(nName, bridge, nXML, macs)
+		if err != nil {
+			return nil, err
+		}
+		leases = append(leases, static...)
+
+		dynamic, err := network.GetDHCPLeases()
+		if err != nil {
+			return nil, errors.Wrapf(err, "get DHCP leases of network %q", nName)
+		}
+		for _, lease := range dynamic {
+			mac := strings.ToLower(lease.Mac)
+			if !macs[mac] || lease.Type != libvirt.IP_ADDR_TYPE_IPV4 {
+				continue
+			}
+			leases = append(leases, Lease{Network: nName, Bridge: bridge, MAC: mac, IP: lease.IPaddr})
+		}
+	}
+	return leases, nil
+}
+
+// Release deletes a static host entry from its network, both live and
+// persistently, or asks the network's dnsmasq to release a lease.  A
+// lease on a remote hypervisor cannot be released; it is left to expire.
+func Release(ctx context.Context, conn *libvirt.Connect, uri string, lease Lease, logger logrus.FieldLogger) error {
+	if !lease.Static {
+		cmd, err := ReleaseCommand(ctx, uri, lease)
+		if err == ErrRemote {
+			logger.Warnf("Leaving the lease of %s on network %q to expire: %v", lease.IP, lease.Network, err)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errors.Wrapf(err, "release lease of %s on network %q: %s", lease.IP, lease.Network, strings.TrimSpace(string(out)))
+		}
+		logger.WithField("lease", lease.IP).Info("Released DHCP lease")
+		return nil
+	}
+
+	network, err := conn.LookupNetworkByName(lease.Network)
+	if err != nil {
+		return errors.Wrapf(err, "get network %q", lease.Network)
+	}
+	defer network.Free()
+	flags := libvirt.NETWORK_UPDATE_AFFECT_LIVE | libvirt.NETWORK_UPDATE_AFFECT_CONFIG
+	if err := network.Update(libvirt.NETWORK_UPDATE_COMMAND_DELETE, libvirt.NETWORK_SECTION_IP_DHCP_HOST, -1, lease.HostXML(), flags); err != nil {
+		return errors.Wrapf(err, "delete DHCP host %s from network %q", lease.IP, lease.Network)
+	}
+	logger.WithField("host", lease.IP).Info("Deleted DHCP host")
+	return nil
+}
+
+// Bootstrap finds the leases held by the bootstrap domains of the cluster
+// infraID, whose names start with <infraID>-bootstrap, and returns a
+// function which releases them once Terraform has deleted the domains.
+func Bootstrap(uri, infraID string, logger logrus.FieldLogger) (func() error, error) {
+	conn, err := libvirt.NewConnect(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to Libvirt daemon")
+	}
+
+	domains, err := conn.ListAllDomains(0)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "list domains")
+	}
+	var domainXMLs []string
+	for _, domain := range domains {
+		defer domain.Free()
+		dName, err := domain.GetName()
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "get domain name")
+		}
+		if !strings.HasPrefix(dName, infraID+"-bootstrap") {
+			continue
+		}
+		dXML, err := domain.GetXMLDesc(0)
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "get XML of domain %q", dName)
+		}
+		domainXMLs = append(domainXMLs, dXML)
+	}
+
+	leases, err := Find(conn, domainXMLs)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return func() error {
+		defer conn.Close()
+		for _, lease := range leases {
+			if err := Release(context.Background(), conn, uri, lease, logger); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,15 @@
+// +build !libvirt
+
+package leases
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Bootstrap returns a function which does nothing, because finding the
+// bootstrap domains' leases needs to talk to libvirt and this binary was
+// built without libvirt support.
+func Bootstrap(uri, infraID string, logger logrus.FieldLogger) (func() error, error) {
+	logger.Debug("Built without libvirt support; leaving the bootstrap machine's DHCP leases to expire")
+	return func() error { return nil }, nil
+}
@@ -3,13 +3,16 @@
 package libvirt
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	libvirt "github.com/libvirt/libvirt-go"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/metalkube/kni-installer/pkg/destroy"
+	"github.com/metalkube/kni-installer/pkg/destroy/leases"
 	"github.com/metalkube/kni-installer/pkg/types"
 )
 
@@ -37,35 +40,217 @@ var AlwaysTrueFilter = func() filterFunc {
 	}
 }
 
-// deleteFunc is the interface a function needs to implement to be delete resources.
-type deleteFunc func(conn *libvirt.Connect, filter filterFunc, logger logrus.FieldLogger) error
-
 // ClusterUninstaller holds the various options for the cluster we want to delete.
 type ClusterUninstaller struct {
 	LibvirtURI string
+	InfraID    string
 	Filter     filterFunc
+	Groups     destroy.Filter
+	Timeout    time.Duration
+	Force      bool
 	Logger     logrus.FieldLogger
 }
 
 // Run is the entrypoint to start the uninstall process.
 func (o *ClusterUninstaller) Run() error {
-	conn, err := libvirt.NewConnect(o.LibvirtURI)
+	conn, err := o.connect()
+	if err != nil {
+		if o.Force {
+			o.Logger.Warnf("Skipping libvirt resources: %v", err)
+			return nil
+		}
+		return err
+	}
+	defer conn.Close()
+
+	progress := destroy.NewProgress(o.Logger, o.Timeout, o.Force)
+	defer progress.Stop()
+	resources, err := o.list(conn)
 	if err != nil {
-		return errors.Wrap(err, "failed to connect to Libvirt daemon")
+		return err
 	}
+	progress.Add(resources...)
 
-	for _, del := range []deleteFunc{
-		deleteDomains,
-		deleteNetwork,
-		deleteVolumes,
-	} {
-		err = del(conn, o.Filter, o.Logger)
+	held, err := o.leases(conn)
+	if err != nil {
+		return err
+	}
+	if err := deleteDomains(conn, o.groupFilter(o.machineGroup), progress, o.Logger); err != nil {
+		return err
+	}
+	o.Logger.Debug("Releasing DHCP leases")
+	for _, lease := range held {
+		lease := lease
+		err := progress.Delete(lease.Resource(), func(ctx context.Context) error {
+			return leases.Release(ctx, conn, o.LibvirtURI, lease, o.Logger)
+		})
 		if err != nil {
 			return err
 		}
 	}
+	if err := deleteNetwork(conn, o.groupFilter(networkGroup), progress, o.Logger); err != nil {
+		return err
+	}
+	return deleteVolumes(conn, o.Filter, o.groupFilter(o.machineGroup), o.Groups.IsZero(), progress, o.Logger)
+}
 
-	return nil
+// connect connects to the hypervisor, giving up after Timeout so an
+// unreachable host does not hang the destroy.
+func (o *ClusterUninstaller) connect() (*libvirt.Connect, error) {
+	var conn *libvirt.Connect
+	err := destroy.WithTimeout(o.Timeout, func(ctx context.Context) error {
+		c, err := libvirt.NewConnect(o.LibvirtURI)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			// The connection is no longer waited for.
+			c.Close()
+			return ctx.Err()
+		}
+		conn = c
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to Libvirt daemon")
+	}
+	return conn, nil
+}
+
+// groupFilter narrows Filter to the names whose group is selected by
+// Groups.
+func (o *ClusterUninstaller) groupFilter(group func(name string) string) filterFunc {
+	return func(name string) bool {
+		return o.Filter(name) && o.Groups.Selects(group(name))
+	}
+}
+
+func (o *ClusterUninstaller) machineGroup(name string) string {
+	return destroy.MachineGroup(o.InfraID, name)
+}
+
+func networkGroup(string) string {
+	return destroy.GroupNetwork
+}
+
+// leases returns the DHCP leases and static hosts which the selected
+// domains hold on networks Run does not delete, e.g. the cluster's network
+// when it is kept.  Run releases them once the domains are deleted.
+func (o *ClusterUninstaller) leases(conn *libvirt.Connect) ([]leases.Lease, error) {
+	domainFilter := o.groupFilter(o.machineGroup)
+	domains, err := conn.ListAllDomains(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "list domains")
+	}
+	var domainXMLs []string
+	for _, domain := range domains {
+		defer domain.Free()
+		dName, err := domain.GetName()
+		if err != nil {
+			return nil, errors.Wrap(err, "get domain name")
+		}
+		if !domainFilter(dName) {
+			continue
+		}
+		dXML, err := domain.GetXMLDesc(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get XML of domain %q", dName)
+		}
+		domainXMLs = append(domainXMLs, dXML)
+	}
+
+	found, err := leases.Find(conn, domainXMLs)
+	if err != nil {
+		return nil, err
+	}
+	networkFilter := o.groupFilter(networkGroup)
+	var held []leases.Lease
+	for _, lease := range found {
+		if !networkFilter(lease.Network) {
+			held = append(held, lease)
+		}
+	}
+	return held, nil
+}
+
+// List returns the domains, DHCP leases, networks and volumes (or storage
+// pool) which Run would delete.
+func (o *ClusterUninstaller) List() ([]destroy.Resource, error) {
+	conn, err := o.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return o.list(conn)
+}
+
+func (o *ClusterUninstaller) list(conn *libvirt.Connect) ([]destroy.Resource, error) {
+	var resources []destroy.Resource
+	domainFilter := o.groupFilter(o.machineGroup)
+	domains, err := conn.ListAllDomains(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "list domains")
+	}
+	for _, domain := range domains {
+		defer domain.Free()
+		dName, err := domain.GetName()
+		if err != nil {
+			return nil, errors.Wrap(err, "get domain name")
+		}
+		if domainFilter(dName) {
+			resources = append(resources, destroy.Resource{Type: "libvirt domain", Name: dName})
+		}
+	}
+
+	held, err := o.leases(conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, lease := range held {
+		resources = append(resources, lease.Resource())
+	}
+
+	networkFilter := o.groupFilter(networkGroup)
+	networks, err := conn.ListNetworks()
+	if err != nil {
+		return nil, errors.Wrap(err, "list networks")
+	}
+	for _, nName := range networks {
+		if networkFilter(nName) {
+			resources = append(resources, destroy.Resource{Type: "libvirt network", Name: nName})
+		}
+	}
+
+	pool, wholePool, err := lookupPool(conn, o.Filter, o.Groups.IsZero())
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Free()
+	pName, err := pool.GetName()
+	if err != nil {
+		return nil, errors.Wrap(err, "get storage pool name")
+	}
+	if wholePool {
+		return append(resources, destroy.Resource{Type: "libvirt pool", Name: pName}), nil
+	}
+
+	volumeFilter := o.groupFilter(o.machineGroup)
+	vols, err := pool.ListAllStorageVolumes(0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list volumes in %q", pName)
+	}
+	for _, vol := range vols {
+		defer vol.Free()
+		vName, err := vol.GetName()
+		if err != nil {
+			return nil, errors.Wrapf(err, "get volume names in %q", pName)
+		}
+		if volumeFilter(vName) {
+			resources = append(resources, destroy.Resource{Type: "libvirt volume", Name: vName})
+		}
+	}
+
+	return resources, nil
 }
 
 // deleteDomains calls deleteDomainsSinglePass until it finds no
@@ -73,12 +258,17 @@ func (o *ClusterUninstaller) Run() error {
 // additional nodes after the initial list call.  We continue deleting
 // domains until we either hit an error or we have a list call with no
 // matching domains.
-func deleteDomains(conn *libvirt.Connect, filter filterFunc, logger logrus.FieldLogger) error {
+func deleteDomains(conn *libvirt.Connect, filter filterFunc, progress *destroy.Progress, logger logrus.FieldLogger) error {
 	logger.Debug("Deleting libvirt domains")
+	// Domains which were skipped will still be listed, so leave them out
+	// or we would never finish.
+	unskipped := func(name string) bool {
+		return filter(name) && !progress.Skipped(destroy.Resource{Type: "libvirt domain", Name: name})
+	}
 	var err error
 	nothingToDelete := false
 	for !nothingToDelete {
-		nothingToDelete, err = deleteDomainsSinglePass(conn, filter, logger)
+		nothingToDelete, err = deleteDomainsSinglePass(conn, unskipped, progress, logger)
 		if err != nil {
 			return err
 		}
@@ -86,7 +276,7 @@ func deleteDomains(conn *libvirt.Connect, filter filterFunc, logger logrus.Field
 	return nil
 }
 
-func deleteDomainsSinglePass(conn *libvirt.Connect, filter filterFunc, logger logrus.FieldLogger) (nothingToDelete bool, err error) {
+func deleteDomainsSinglePass(conn *libvirt.Connect, filter filterFunc, progress *destroy.Progress, logger logrus.FieldLogger) (nothingToDelete bool, err error) {
 	domains, err := conn.ListAllDomains(0)
 	if err != nil {
 		return false, errors.Wrap(err, "list domains")
@@ -104,84 +294,120 @@ func deleteDomainsSinglePass(conn *libvirt.Connect, filter filterFunc, logger lo
 		}
 
 		nothingToDelete = false
-		dState, _, err := domain.GetState()
-		if err != nil {
-			return false, errors.Wrapf(err, "get domain state %d", dName)
-		}
+		err = progress.Delete(destroy.Resource{Type: "libvirt domain", Name: dName}, func(ctx context.Context) error {
+			dState, _, err := domain.GetState()
+			if err != nil {
+				return errors.Wrapf(err, "get domain state %q", dName)
+			}
 
-		if dState != libvirt.DOMAIN_SHUTOFF && dState != libvirt.DOMAIN_SHUTDOWN {
-			if err := domain.Destroy(); err != nil {
-				return false, errors.Wrapf(err, "destroy domain %q", dName)
+			if dState != libvirt.DOMAIN_SHUTOFF && dState != libvirt.DOMAIN_SHUTDOWN {
+				if err := domain.Destroy(); err != nil {
+					return errors.Wrapf(err, "destroy domain %q", dName)
+				}
 			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := domain.Undefine(); err != nil {
+				return errors.Wrapf(err, "undefine domain %q", dName)
+			}
+			logger.WithField("domain", dName).Info("Deleted domain")
+			return nil
+		})
+		if err != nil {
+			return false, err
 		}
-		if err := domain.Undefine(); err != nil {
-			return false, errors.Wrapf(err, "undefine domain %q", dName)
-		}
-		logger.WithField("domain", dName).Info("Deleted domain")
 	}
 
 	return nothingToDelete, nil
 }
 
-func deleteVolumes(conn *libvirt.Connect, filter filterFunc, logger logrus.FieldLogger) error {
-	logger.Debug("Deleting libvirt volumes")
-
+// lookupPool returns the cluster's storage pool, which is the pool whose
+// name matches poolFilter, or the default pool.  wholePool is true if the
+// cluster's pool should be deleted outright rather than volume by volume,
+// which is only done when all of the cluster's resources are selected.
+func lookupPool(conn *libvirt.Connect, poolFilter filterFunc, selectsAll bool) (pool *libvirt.StoragePool, wholePool bool, err error) {
 	pools, err := conn.ListStoragePools()
 	if err != nil {
-		return errors.Wrap(err, "list storage pools")
+		return nil, false, errors.Wrap(err, "list storage pools")
 	}
 
 	tpool := "default"
 	for _, pname := range pools {
 		// pool name that returns true from filter, override default.
-		if filter(pname) {
+		if poolFilter(pname) {
 			tpool = pname
 		}
 	}
-	pool, err := conn.LookupStoragePoolByName(tpool)
+	pool, err = conn.LookupStoragePoolByName(tpool)
 	if err != nil {
-		return errors.Wrapf(err, "get storage pool %q", tpool)
+		return nil, false, errors.Wrapf(err, "get storage pool %q", tpool)
 	}
-	defer pool.Free()
+	return pool, tpool != "default" && selectsAll, nil
+}
 
-	switch tpool {
-	case "default":
-		// delete all vols that return true from filter.
-		vols, err := pool.ListAllStorageVolumes(0)
-		if err != nil {
-			return errors.Wrapf(err, "list volumes in %q", tpool)
-		}
+func deleteVolumes(conn *libvirt.Connect, poolFilter filterFunc, volumeFilter filterFunc, selectsAll bool, progress *destroy.Progress, logger logrus.FieldLogger) error {
+	logger.Debug("Deleting libvirt volumes")
 
-		for _, vol := range vols {
-			defer vol.Free()
-			vName, err := vol.GetName()
-			if err != nil {
-				return errors.Wrapf(err, "get volume names in %q", tpool)
+	pool, wholePool, err := lookupPool(conn, poolFilter, selectsAll)
+	if err != nil {
+		return err
+	}
+	defer pool.Free()
+	tpool, err := pool.GetName()
+	if err != nil {
+		return errors.Wrap(err, "get storage pool name")
+	}
+
+	if wholePool {
+		// blow away entire pool.
+		return progress.Delete(destroy.Resource{Type: "libvirt pool", Name: tpool}, func(ctx context.Context) error {
+			if err := pool.Destroy(); err != nil {
+				return errors.Wrapf(err, "destroy pool %q", tpool)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-			if !filter(vName) {
-				continue
+
+			if err := pool.Undefine(); err != nil {
+				return errors.Wrapf(err, "undefine pool %q", tpool)
 			}
+			logger.WithField("pool", tpool).Info("Deleted pool")
+			return nil
+		})
+	}
+
+	// delete all vols that return true from filter.
+	vols, err := pool.ListAllStorageVolumes(0)
+	if err != nil {
+		return errors.Wrapf(err, "list volumes in %q", tpool)
+	}
+
+	for _, vol := range vols {
+		defer vol.Free()
+		vName, err := vol.GetName()
+		if err != nil {
+			return errors.Wrapf(err, "get volume names in %q", tpool)
+		}
+		if !volumeFilter(vName) {
+			continue
+		}
+		err = progress.Delete(destroy.Resource{Type: "libvirt volume", Name: vName}, func(context.Context) error {
 			if err := vol.Delete(0); err != nil {
 				return errors.Wrapf(err, "delete volume %q from %q", vName, tpool)
 			}
 			logger.WithField("volume", vName).Info("Deleted volume")
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-	default:
-		// blow away entire pool.
-		if err := pool.Destroy(); err != nil {
-			return errors.Wrapf(err, "destroy pool %q", tpool)
-		}
-
-		if err := pool.Undefine(); err != nil {
-			return errors.Wrapf(err, "undefine pool %q", tpool)
-		}
-		logger.WithField("pool", tpool).Info("Deleted pool")
 	}
 
 	return nil
 }
 
-func deleteNetwork(conn *libvirt.Connect, filter filterFunc, logger logrus.FieldLogger) error {
+func deleteNetwork(conn *libvirt.Connect, filter filterFunc, progress *destroy.Progress, logger logrus.FieldLogger) error {
 	logger.Debug("Deleting libvirt network")
 
 	networks, err := conn.ListNetworks()
@@ -199,23 +425,36 @@ func deleteNetwork(conn *libvirt.Connect, filter filterFunc, logger logrus.Field
 		}
 		defer network.Free()
 
-		if err := network.Destroy(); err != nil {
-			return errors.Wrapf(err, "destroy network %q", nName)
-		}
+		err = progress.Delete(destroy.Resource{Type: "libvirt network", Name: nName}, func(ctx context.Context) error {
+			if err := network.Destroy(); err != nil {
+				return errors.Wrapf(err, "destroy network %q", nName)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 
-		if err := network.Undefine(); err != nil {
-			return errors.Wrapf(err, "undefine network %q", nName)
+			if err := network.Undefine(); err != nil {
+				return errors.Wrapf(err, "undefine network %q", nName)
+			}
+			logger.WithField("network", nName).Info("Deleted network")
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-		logger.WithField("network", nName).Info("Deleted network")
 	}
 	return nil
 }
 
 // New returns libvirt Uninstaller from ClusterMetadata.
-func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (destroy.Destroyer, error) {
+func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata, opts destroy.Options) (destroy.Destroyer, error) {
 	return &ClusterUninstaller{
 		LibvirtURI: metadata.ClusterPlatformMetadata.Libvirt.URI,
+		InfraID:    metadata.InfraID,
 		Filter:     ClusterIDPrefixFilter(metadata.InfraID),
+		Groups:     opts.Filter,
+		Timeout:    opts.Timeout,
+		Force:      opts.Force,
 		Logger:     logger,
 	}, nil
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/containers"
 	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/objects"
 	"github.com/gophercloud/utils/openstack/clientconfig"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -542,7 +543,10 @@ func deleteTrunks(opts *clientconfig.ClientOpts, filter Filter, logger logrus.Fi
 }
 
 // New returns an OpenStack destroyer from ClusterMetadata.
-func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (destroy.Destroyer, error) {
+func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata, opts destroy.Options) (destroy.Destroyer, error) {
+	if !opts.Filter.IsZero() {
+		return nil, errors.New("filters are not supported when destroying OpenStack clusters")
+	}
 	return &ClusterUninstaller{
 		Cloud:  metadata.ClusterPlatformMetadata.OpenStack.Cloud,
 		Filter: metadata.ClusterPlatformMetadata.OpenStack.Identifier,
@@ -0,0 +1,148 @@
+package destroy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ProgressInterval is how often Progress logs the remaining resources.
+var ProgressInterval = 30 * time.Second
+
+// WithTimeout runs fn, giving up once timeout has passed if it is
+// positive.  The platform libraries cannot be interrupted, so fn's context
+// is cancelled on the timeout, and fn must check it between calls and
+// return, releasing what it acquired, once it is done.
+func WithTimeout(timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return fn(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	result := make(chan error, 1)
+	go func() {
+		result <- fn(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return errors.Errorf("timed out after %s", timeout)
+	}
+}
+
+// Progress tracks the resources which remain to be destroyed and
+// periodically logs them, so users can see what a slow destroy is waiting
+// for.
+type Progress struct {
+	logger    logrus.FieldLogger
+	timeout   time.Duration
+	force     bool
+	lock      sync.Mutex
+	remaining map[Resource]struct{}
+	skipped   map[Resource]struct{}
+	stop      chan struct{}
+}
+
+// NewProgress returns a Progress which logs to logger every
+// ProgressInterval until it is stopped.  Deletes are bounded by timeout if
+// it is positive, and with force, failed deletes are skipped.
+func NewProgress(logger logrus.FieldLogger, timeout time.Duration, force bool) *Progress {
+	p := &Progress{
+		logger:    logger,
+		timeout:   timeout,
+		force:     force,
+		remaining: map[Resource]struct{}{},
+		skipped:   map[Resource]struct{}{},
+		stop:      make(chan struct{}),
+	}
+	go func() {
+		ticker := time.NewTicker(ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.log()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Add records resources which remain to be destroyed.
+func (p *Progress) Add(resources ...Resource) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, resource := range resources {
+		p.remaining[resource] = struct{}{}
+	}
+}
+
+// Delete runs fn to delete the resource, bounded by the timeout.  With
+// force, a failure is logged as a warning and the resource skipped.
+func (p *Progress) Delete(resource Resource, fn func(ctx context.Context) error) error {
+	p.Add(resource)
+	err := WithTimeout(p.timeout, fn)
+	if err != nil {
+		if !p.force {
+			return err
+		}
+		p.logger.Warnf("Skipping %s %s: %v", resource.Type, resource.Name, err)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.remaining, resource)
+	if err != nil {
+		p.skipped[resource] = struct{}{}
+	}
+	return nil
+}
+
+// Done records that the resource no longer needs to be destroyed.
+func (p *Progress) Done(resource Resource) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.remaining, resource)
+}
+
+// Skipped returns true if deleting the resource failed and was skipped.
+func (p *Progress) Skipped(resource Resource) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	_, ok := p.skipped[resource]
+	return ok
+}
+
+// Stop stops logging.
+func (p *Progress) Stop() {
+	close(p.stop)
+}
+
+func (p *Progress) log() {
+	p.lock.Lock()
+	names := make([]string, 0, len(p.remaining))
+	for resource := range p.remaining {
+		names = append(names, fmt.Sprintf("%s %s", resource.Type, resource.Name))
+	}
+	p.lock.Unlock()
+
+	count := len(names)
+	if count == 0 {
+		return
+	}
+	sort.Strings(names)
+	if count > 10 {
+		names = append(names[:10], fmt.Sprintf("and %d more", count-10))
+	}
+	p.logger.Infof("Waiting for %d resources to be destroyed: %s", count, strings.Join(names, ", "))
+}
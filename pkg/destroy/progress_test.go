@@ -0,0 +1,91 @@
+package destroy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeout(t *testing.T) {
+	err := WithTimeout(0, func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok, "a zero timeout set a deadline")
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+
+	assert.NoError(t, WithTimeout(time.Minute, func(context.Context) error { return nil }))
+
+	returned := make(chan struct{})
+	err = WithTimeout(10*time.Millisecond, func(ctx context.Context) error {
+		defer close(returned)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.EqualError(t, err, "timed out after 10ms")
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Error("fn did not see its context cancelled")
+	}
+}
+
+func TestProgressDelete(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &out
+	logger.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+	a := Resource{Type: "libvirt domain", Name: "a"}
+	b := Resource{Type: "libvirt domain", Name: "b"}
+
+	p := NewProgress(logger, 0, false)
+	defer p.Stop()
+	p.Add(a, b)
+	assert.NoError(t, p.Delete(a, func(context.Context) error { return nil }))
+	assert.EqualError(t, p.Delete(b, func(context.Context) error { return errors.New("failed") }), "failed")
+	assert.False(t, p.Skipped(a))
+	assert.False(t, p.Skipped(b))
+	p.log()
+	assert.Equal(t, "level=info msg=\"Waiting for 1 resources to be destroyed: libvirt domain b\"\n", out.String())
+
+	out.Reset()
+	forced := NewProgress(logger, 0, true)
+	defer forced.Stop()
+	assert.NoError(t, forced.Delete(b, func(context.Context) error { return errors.New("failed") }))
+	assert.True(t, forced.Skipped(b))
+	assert.Equal(t, "level=warning msg=\"Skipping libvirt domain b: failed\"\n", out.String())
+	out.Reset()
+	forced.log()
+	assert.Empty(t, out.String(), "skipped resources are not waited for")
+}
+
+func TestProgressLogConcurrently(t *testing.T) {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	p := NewProgress(logger, 0, false)
+	defer p.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				resource := Resource{Type: "volume", Name: string(rune('a'+i)) + string(rune('a'+j%26))}
+				p.Add(resource)
+				p.Done(resource)
+			}
+		}(i)
+	}
+	for i := 0; i < 100; i++ {
+		p.log()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,164 @@
+// Package download fetches installer artifacts, such as RHCOS metadata and
+// images, over HTTP.  Downloads can be rate limited, so installs at sites
+// with constrained links do not saturate them, and can fall back to
+// mirrors when the primary location is unavailable.
+package download
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// RateLimitEnvVar is the maximum download rate in bytes per second,
+	// as a quantity (e.g. "500Ki" or "10M").  The --download-rate-limit
+	// flag sets it.
+	RateLimitEnvVar = "OPENSHIFT_INSTALL_DOWNLOAD_RATE_LIMIT"
+
+	// MirrorsEnvVar is a comma-separated list of mirrors to try, in order,
+	// when the primary location fails.  The --rhcos-mirror flag sets it.
+	MirrorsEnvVar = "OPENSHIFT_INSTALL_RHCOS_MIRRORS"
+)
+
+// ParseRateLimit returns the rate in bytes per second for a quantity like
+// "10M".  An empty limit is zero, meaning unlimited.
+func ParseRateLimit(limit string) (int64, error) {
+	if limit == "" {
+		return 0, nil
+	}
+	quantity, err := resource.ParseQuantity(limit)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid download rate limit %q", limit)
+	}
+	rate := quantity.Value()
+	if rate <= 0 {
+		return 0, errors.Errorf("invalid download rate limit %q: must be positive", limit)
+	}
+	return rate, nil
+}
+
+// Mirrors returns the configured mirrors, in the order they should be
+// tried.
+func Mirrors() []string {
+	var mirrors []string
+	for _, mirror := range strings.Split(os.Getenv(MirrorsEnvVar), ",") {
+		if mirror = strings.TrimSpace(mirror); mirror != "" {
+			mirrors = append(mirrors, strings.TrimSuffix(mirror, "/"))
+		}
+	}
+	return mirrors
+}
+
+// URLs returns uri followed by its location on each mirror.  Mirrors
+// replace base, so they must have the same layout below it.  A uri which
+// is not below base has no mirrors.
+func URLs(uri, base string) []string {
+	urls := []string{uri}
+	if !strings.HasPrefix(uri, base) {
+		return urls
+	}
+	for _, mirror := range Mirrors() {
+		urls = append(urls, mirror+strings.TrimPrefix(uri, base))
+	}
+	return urls
+}
+
+// Get returns the response for the first of urls which returns 200 OK,
+// logging the failures of those before it.  The response body is rate
+// limited.
+func Get(ctx context.Context, client *http.Client, urls []string) (*http.Response, error) {
+	return GetConditional(ctx, client, urls, nil)
+}
+
+// GetConditional is Get with the request headers in header, e.g.
+// If-None-Match or If-Modified-Since, which also accepts a 304 Not
+// Modified response.
+func GetConditional(ctx context.Context, client *http.Client, urls []string, header http.Header) (*http.Response, error) {
+	rate, err := ParseRateLimit(os.Getenv(RateLimitEnvVar))
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	for _, url := range urls {
+		resp, err := get(ctx, client, url, header)
+		if err == nil {
+			if rate > 0 {
+				resp.Body = &limitedReadCloser{Reader: NewLimitedReader(resp.Body, rate), Closer: resp.Body}
+			}
+			return resp, nil
+		}
+		if len(urls) > 1 {
+			logrus.Warnf("Failed to fetch %s: %v", url, err)
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, errors.New(strings.Join(errs, "; "))
+}
+
+func get(ctx context.Context, client *http.Client, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request")
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && header != nil {
+		return resp, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("%s while getting %s", resp.Status, url)
+	}
+	return resp, nil
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type limitedReader struct {
+	reader io.Reader
+	rate   int64
+	start  time.Time
+	read   int64
+}
+
+// NewLimitedReader returns a reader which reads from r no faster than rate
+// bytes per second.
+func NewLimitedReader(r io.Reader, rate int64) io.Reader {
+	return &limitedReader{reader: r, rate: rate}
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+	// Read at most a second's worth at a time, so the rate holds for
+	// large buffers too.
+	if int64(len(p)) > r.rate {
+		p = p[:r.rate]
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	due := r.start.Add(time.Duration(float64(r.read) / float64(r.rate) * float64(time.Second)))
+	if wait := time.Until(due); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
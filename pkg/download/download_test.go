@@ -0,0 +1,116 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	cases := []struct {
+		limit         string
+		expected      int64
+		expectedError string
+	}{
+		{limit: "", expected: 0},
+		{limit: "500", expected: 500},
+		{limit: "10M", expected: 10000000},
+		{limit: "1Mi", expected: 1048576},
+		{limit: "fast", expectedError: `invalid download rate limit "fast": quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'`},
+		{limit: "0", expectedError: `invalid download rate limit "0": must be positive`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.limit, func(t *testing.T) {
+			rate, err := ParseRateLimit(tc.limit)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, rate)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestURLs(t *testing.T) {
+	defer os.Unsetenv(MirrorsEnvVar)
+
+	base := "https://example.com/releases"
+	uri := base + "/maipo/builds.json"
+
+	os.Unsetenv(MirrorsEnvVar)
+	assert.Equal(t, []string{uri}, URLs(uri, base))
+
+	os.Setenv(MirrorsEnvVar, "https://mirror-a.example.com/rhcos/, https://mirror-b.example.com")
+	assert.Equal(t, []string{
+		uri,
+		"https://mirror-a.example.com/rhcos/maipo/builds.json",
+		"https://mirror-b.example.com/maipo/builds.json",
+	}, URLs(uri, base))
+
+	assert.Equal(t, []string{"https://other.example.com/image"}, URLs("https://other.example.com/image", base))
+}
+
+func TestGetFallsBack(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("payload"))
+	}))
+	defer up.Close()
+
+	resp, err := Get(context.Background(), &http.Client{}, []string{down.URL, up.URL})
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "payload", string(body))
+	}
+
+	_, err = Get(context.Background(), &http.Client{}, []string{down.URL})
+	assert.EqualError(t, err, "503 Service Unavailable while getting "+down.URL)
+}
+
+func TestGetConditional(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"1"`)
+		w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("If-None-Match", `"1"`)
+	resp, err := GetConditional(context.Background(), &http.Client{}, []string{server.URL}, header)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	}
+
+	header.Set("If-None-Match", `"2"`)
+	resp, err = GetConditional(context.Background(), &http.Client{}, []string{server.URL}, header)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	start := time.Now()
+	body, err := ioutil.ReadAll(NewLimitedReader(bytes.NewReader(make([]byte, 200)), 1000))
+	assert.NoError(t, err)
+	assert.Len(t, body, 200)
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "read 200 bytes at 1000 bytes per second in %s", time.Since(start))
+}
@@ -0,0 +1,188 @@
+// Package encrypt encrypts the secret-bearing files the installer
+// generates, e.g. kubeconfigs, Ignition configs, private keys and Secret
+// manifests, so they can be committed to git.
+//
+// Files are encrypted with sops or age, which must be installed on the
+// installer host, for age recipients.  Either decrypts them again with any
+// of the recipients' private keys, e.g. "sops --decrypt" or
+// "age --decrypt --identity key.txt".
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// ageSuffix is appended to the names of files encrypted with age.
+const ageSuffix = ".age"
+
+var (
+	secretManifestRegexp = regexp.MustCompile(`(?m)(^kind:\s*Secret\s*$|"kind":\s*"Secret")`)
+	privateKeyRegexp     = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+	sopsMetadataRegexp   = regexp.MustCompile(`(?m)(^sops:\s*$|"sops":\s*{)`)
+
+	// skippedDirs are not encrypted: boot-media holds large images, which
+	// should not be committed at all, and gitops holds an export whose
+	// Secrets are already encrypted.
+	skippedDirs = map[string]bool{
+		"boot-media": true,
+		"gitops":     true,
+	}
+)
+
+// run runs an external command with data on its standard input, returning
+// its standard output.  Tests override it.
+var run = func(ctx context.Context, stdin []byte, name string, args ...string) ([]byte, error) {
+	logrus.Debugf("Running %s %s", name, strings.Join(args, " "))
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "%s failed: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// Encrypter encrypts files for the recipients of a SecretsEncryption.
+type Encrypter struct {
+	config *types.SecretsEncryption
+}
+
+// New returns an Encrypter for the given configuration.
+func New(config *types.SecretsEncryption) *Encrypter {
+	return &Encrypter{config: config}
+}
+
+// Encrypt returns the encrypted data of a file and the name to write it
+// under.  sops keeps the name and, for YAML and JSON, the structure of the
+// file, encrypting only the data of Secret manifests so their metadata
+// stays readable.  age encrypts the whole file and appends ".age" to its
+// name.
+func (e *Encrypter) Encrypt(ctx context.Context, filename string, data []byte) (string, []byte, error) {
+	var (
+		encrypted []byte
+		err       error
+	)
+	switch e.config.Tool {
+	case types.EncryptionToolAge:
+		args := []string{"--encrypt", "--armor"}
+		for _, r := range e.config.Recipients {
+			args = append(args, "--recipient", r)
+		}
+		encrypted, err = run(ctx, data, "age", args...)
+		filename += ageSuffix
+	case types.EncryptionToolSOPS:
+		format := sopsFormat(filename)
+		args := []string{"--encrypt", "--age", strings.Join(e.config.Recipients, ","), "--input-type", format, "--output-type", format}
+		if format == "yaml" && secretManifestRegexp.Match(data) {
+			args = append(args, "--encrypted-regex", "^(data|stringData)$")
+		}
+		encrypted, err = run(ctx, data, "sops", append(args, "/dev/stdin")...)
+	default:
+		return "", nil, errors.Errorf("unsupported encryption tool %q", e.config.Tool)
+	}
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to encrypt %s", filename)
+	}
+	return filename, encrypted, nil
+}
+
+// sopsFormat returns the sops input and output type of a file.
+func sopsFormat(filename string) string {
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json", ".ign":
+		return "json"
+	case "":
+		if filepath.Base(filename) == "kubeconfig" {
+			return "yaml"
+		}
+	}
+	return "binary"
+}
+
+// IsSecret returns true if a file in the asset directory holds secrets
+// and is not encrypted yet.
+func IsSecret(filename string, data []byte) bool {
+	if strings.HasSuffix(filename, ageSuffix) || sopsMetadataRegexp.Match(data) {
+		return false
+	}
+	base := filepath.Base(filename)
+	switch {
+	case base == ".openshift_install_state.json",
+		strings.HasPrefix(filepath.ToSlash(filename), "auth/"),
+		strings.HasPrefix(filepath.ToSlash(filename), "debug/terraform/"),
+		strings.HasSuffix(base, ".ign"),
+		strings.HasSuffix(base, ".key"),
+		strings.HasSuffix(base, ".tfstate"),
+		strings.HasSuffix(base, ".tfvars.json"),
+		privateKeyRegexp.Match(data):
+		return true
+	}
+	switch filepath.Ext(base) {
+	case ".yaml", ".yml", ".json":
+		return secretManifestRegexp.Match(data)
+	}
+	return false
+}
+
+// Dir encrypts the secret-bearing files of an asset directory in place,
+// returning their paths relative to the directory.
+func (e *Encrypter) Dir(ctx context.Context, dir string) ([]string, error) {
+	var encrypted []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if skippedDirs[rel] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		data, err := fileaudit.ReadFile(path, "secret scan")
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", rel)
+		}
+		if !IsSecret(rel, data) {
+			return nil
+		}
+		name, data, err := e.Encrypt(ctx, rel, data)
+		if err != nil {
+			return err
+		}
+		if err := fileaudit.WriteFile(filepath.Join(dir, name), data, 0600, "encrypted secret"); err != nil {
+			return errors.Wrapf(err, "failed to write %s", name)
+		}
+		if name != rel {
+			if err := os.Remove(path); err != nil {
+				return errors.Wrapf(err, "failed to remove the plain text %s", rel)
+			}
+			fileaudit.Record(fileaudit.Remove, path, "plain text secret", nil)
+		}
+		encrypted = append(encrypted, rel)
+		return nil
+	})
+	return encrypted, err
+}
@@ -0,0 +1,58 @@
+package expiry
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// bootstrapTokenType is the type of bootstrap token secrets, and
+	// bootstrapTokenExpirationKey the key of their RFC 3339 expiry.
+	bootstrapTokenType          corev1.SecretType = "bootstrap.kubernetes.io/token"
+	bootstrapTokenExpirationKey                   = "expiration"
+
+	// kubeadminExpiresAtAnnotation is when the kubeadmin secret of an
+	// ephemeral cluster expires.
+	kubeadminExpiresAtAnnotation = "kni.openshift.io/expires-at"
+)
+
+// ScanCluster returns a report of the TLS secrets, bootstrap tokens and
+// kubeadmin secret of the cluster behind client, against threshold.
+func ScanCluster(client kubernetes.Interface, threshold time.Duration) (*Report, error) {
+	secrets, err := client.CoreV1().Secrets(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list secrets")
+	}
+	return newReport(secretArtifacts(secrets.Items), threshold), nil
+}
+
+// secretArtifacts returns the expiring artifacts in secrets.
+func secretArtifacts(secrets []corev1.Secret) []Artifact {
+	c := newCollector()
+	for _, secret := range secrets {
+		source := secret.Namespace + "/" + secret.Name
+		switch {
+		case secret.Type == corev1.SecretTypeTLS:
+			c.addPEM(secret.Data[corev1.TLSCertKey], source)
+		case secret.Type == bootstrapTokenType:
+			a := Artifact{Kind: KindBootstrapToken, Name: secret.Name, Sources: []string{source}}
+			if expiration, ok := secret.Data[bootstrapTokenExpirationKey]; ok {
+				if t, err := time.Parse(time.RFC3339, string(expiration)); err == nil {
+					a.NotAfter = &t
+				}
+			}
+			c.add(a)
+		case secret.Namespace == metav1.NamespaceSystem && secret.Name == "kubeadmin":
+			a := Artifact{Kind: KindKubeadmin, Name: "kubeadmin", Sources: []string{source}}
+			if t, err := time.Parse(time.RFC3339, secret.Annotations[kubeadminExpiresAtAnnotation]); err == nil {
+				a.NotAfter = &t
+			}
+			c.add(a)
+		}
+	}
+	return c.artifacts
+}
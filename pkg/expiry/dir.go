@@ -0,0 +1,135 @@
+package expiry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/vincent-petithory/dataurl"
+	clientcmd "k8s.io/client-go/tools/clientcmd/api/v1"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	// maxFileSize skips files too large to hold credentials, such as
+	// boot media and Terraform providers.
+	maxFileSize = 16 << 20
+
+	// stateFileName is the installer's state, which holds the same
+	// certificates as the files it wrote.
+	stateFileName = ".openshift_install_state.json"
+
+	// kubeadminPasswordPath is the kubeadmin password in the asset
+	// directory.
+	kubeadminPasswordPath = "auth/kubeadmin-password"
+
+	// metadataFileName is the cluster metadata, which records when an
+	// ephemeral cluster expires.
+	metadataFileName = "metadata.json"
+)
+
+// ScanDir returns a report of the certificates in the asset directory dir,
+// in files of their own, in kubeconfigs and in Ignition configs, and of the
+// kubeadmin password, against threshold.
+func ScanDir(dir string, threshold time.Duration) (*Report, error) {
+	expiresAt, err := ephemeralExpiry(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := newCollector()
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel != "." && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Size() > maxFileSize || info.Name() == stateFileName {
+			return nil
+		}
+		data, err := fileaudit.ReadFile(path, "expiry scan")
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case rel == kubeadminPasswordPath:
+			c.add(Artifact{Kind: KindKubeadmin, Name: "kubeadmin", Sources: []string{rel}, NotAfter: expiresAt})
+		case filepath.Ext(rel) == ".ign":
+			addIgnition(c, data, rel)
+		case strings.HasPrefix(info.Name(), "kubeconfig"):
+			addKubeconfig(c, data, rel)
+		default:
+			c.addPEM(data, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to scan %s", dir)
+	}
+	return newReport(c.artifacts, threshold), nil
+}
+
+// ephemeralExpiry returns when the cluster of the asset directory dir
+// expires, or nil if it is not ephemeral or has no metadata yet.
+func ephemeralExpiry(dir string) (*time.Time, error) {
+	data, err := fileaudit.ReadFile(filepath.Join(dir, metadataFileName), "cluster metadata")
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	metadata := &types.ClusterMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", metadataFileName)
+	}
+	return metadata.ExpiresAt, nil
+}
+
+// addIgnition adds the certificates in the files and certificate
+// authorities of an Ignition config.  Configs which do not parse, e.g.
+// because they are encrypted, are skipped.
+func addIgnition(c *collector, data []byte, source string) {
+	config := &igntypes.Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return
+	}
+	for _, ca := range config.Ignition.Security.TLS.CertificateAuthorities {
+		if d, err := dataurl.DecodeString(ca.Source); err == nil {
+			c.addPEM(d.Data, source)
+		}
+	}
+	for _, file := range config.Storage.Files {
+		if d, err := dataurl.DecodeString(file.Contents.Source); err == nil {
+			c.addPEM(d.Data, source+":"+file.Path)
+		}
+	}
+}
+
+// addKubeconfig adds the certificates embedded in a kubeconfig.
+func addKubeconfig(c *collector, data []byte, source string) {
+	config := &clientcmd.Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return
+	}
+	for _, cluster := range config.Clusters {
+		c.addPEM(cluster.Cluster.CertificateAuthorityData, source)
+	}
+	for _, user := range config.AuthInfos {
+		c.addPEM(user.AuthInfo.ClientCertificateData, source)
+	}
+}
@@ -0,0 +1,4 @@
+// Package expiry finds the credentials of a cluster which expire, such as
+// certificates and bootstrap tokens, in its asset directory or in the
+// cluster itself, so that they can be audited before they lapse.
+package expiry
@@ -0,0 +1,191 @@
+package expiry
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Kind is the kind of an expiring artifact.
+type Kind string
+
+const (
+	// KindCertificate is an X.509 certificate.
+	KindCertificate Kind = "certificate"
+
+	// KindBootstrapToken is a Kubernetes bootstrap token.
+	KindBootstrapToken Kind = "bootstrap-token"
+
+	// KindKubeadmin is the kubeadmin password, which only expires on
+	// ephemeral clusters, but should be removed once other administrators
+	// are configured.
+	KindKubeadmin Kind = "kubeadmin"
+)
+
+// now returns the current time.  Tests override it.
+var now = time.Now
+
+// Artifact is a credential which may expire.
+type Artifact struct {
+	// Kind is the kind of the artifact.
+	Kind Kind `json:"kind"`
+
+	// Name identifies the artifact, e.g. a certificate's subject.
+	Name string `json:"name"`
+
+	// Sources are where the artifact was found, e.g. files of the asset
+	// directory or namespace/name of secrets.
+	Sources []string `json:"sources"`
+
+	// NotAfter is when the artifact expires, or nil if it does not.
+	NotAfter *time.Time `json:"notAfter,omitempty"`
+}
+
+// Remaining returns the time left before the artifact expires, which is
+// negative if it has expired.  It returns false if the artifact does not
+// expire.
+func (a *Artifact) Remaining() (time.Duration, bool) {
+	if a.NotAfter == nil {
+		return 0, false
+	}
+	return a.NotAfter.Sub(now()), true
+}
+
+// Report is the expiring artifacts found.
+type Report struct {
+	// Artifacts are sorted by expiry, soonest first, followed by those
+	// which do not expire.
+	Artifacts []Artifact `json:"artifacts"`
+
+	// Threshold is the time left below which an artifact is expiring.
+	Threshold time.Duration `json:"-"`
+
+	// Expiring is the number of artifacts which expire within the
+	// threshold, including those which have expired.
+	Expiring int `json:"expiring"`
+}
+
+// newReport returns a report of artifacts against threshold.
+func newReport(artifacts []Artifact, threshold time.Duration) *Report {
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		a, b := artifacts[i].NotAfter, artifacts[j].NotAfter
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Before(*b)
+	})
+	report := &Report{Artifacts: artifacts, Threshold: threshold}
+	for i := range artifacts {
+		if remaining, ok := artifacts[i].Remaining(); ok && remaining < threshold {
+			report.Expiring++
+		}
+	}
+	return report
+}
+
+// WriteTable writes the report as a table to w.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tEXPIRES\tREMAINING\tSOURCE")
+	for _, a := range r.Artifacts {
+		expires, remaining := "never", "-"
+		if d, ok := a.Remaining(); ok {
+			expires = a.NotAfter.UTC().Format(time.RFC3339)
+			remaining = formatRemaining(d)
+			if d < r.Threshold {
+				remaining += " !"
+			}
+		}
+		source := a.Sources[0]
+		if len(a.Sources) > 1 {
+			source = fmt.Sprintf("%s (+%d more)", source, len(a.Sources)-1)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", a.Kind, a.Name, expires, remaining, source)
+	}
+	return tw.Flush()
+}
+
+// formatRemaining formats d in days, or hours when less than a day is
+// left.
+func formatRemaining(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "expired"
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// collector gathers artifacts, merging the sources of certificates found
+// in several places.
+type collector struct {
+	artifacts    []Artifact
+	certificates map[[sha256.Size]byte]int
+}
+
+func newCollector() *collector {
+	return &collector{certificates: map[[sha256.Size]byte]int{}}
+}
+
+// addPEM adds the certificates in data, found at source.
+func (c *collector) addPEM(data []byte, source string) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		c.addCertificate(cert, block.Bytes, source)
+	}
+}
+
+func (c *collector) addCertificate(cert *x509.Certificate, raw []byte, source string) {
+	fingerprint := sha256.Sum256(raw)
+	if i, ok := c.certificates[fingerprint]; ok {
+		c.artifacts[i].Sources = append(c.artifacts[i].Sources, source)
+		return
+	}
+	notAfter := cert.NotAfter
+	c.certificates[fingerprint] = len(c.artifacts)
+	c.artifacts = append(c.artifacts, Artifact{
+		Kind:     KindCertificate,
+		Name:     certificateName(cert),
+		Sources:  []string{source},
+		NotAfter: &notAfter,
+	})
+}
+
+func (c *collector) add(a Artifact) {
+	c.artifacts = append(c.artifacts, a)
+}
+
+// certificateName returns the subject of cert, in the "CN=..., O=..."
+// form used by openssl.
+func certificateName(cert *x509.Certificate) string {
+	parts := []string{}
+	if cert.Subject.CommonName != "" {
+		parts = append(parts, "CN="+cert.Subject.CommonName)
+	}
+	for _, o := range cert.Subject.Organization {
+		parts = append(parts, "O="+o)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("serial %s", cert.SerialNumber)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,145 @@
+package expiry
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/vincent-petithory/dataurl"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmd "k8s.io/client-go/tools/clientcmd/api/v1"
+
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+)
+
+func certificate(t *testing.T, cn string, validity time.Duration) ([]byte, *x509.Certificate) {
+	_, cert, err := tls.GenerateSelfSignedCertificate(context.Background(), &tls.CertCfg{
+		Subject:   pkix.Name{CommonName: cn, OrganizationalUnit: []string{"test"}},
+		KeyUsages: x509.KeyUsageCertSign,
+		Validity:  validity,
+		IsCA:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.CertToPem(cert), cert
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+}
+
+func TestScanDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kni-install-expiry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rootCA, _ := certificate(t, "root-ca", tls.ValidityTenYears)
+	kubelet, _ := certificate(t, "kubelet-bootstrap", tls.ValidityOneDay)
+
+	writeFile(t, filepath.Join(dir, "tls", "root-ca.crt"), rootCA)
+	writeFile(t, filepath.Join(dir, "auth", "kubeadmin-password"), []byte("password"))
+
+	data, err := yaml.Marshal(clientcmd.Config{
+		Clusters:  []clientcmd.NamedCluster{{Name: "cluster", Cluster: clientcmd.Cluster{CertificateAuthorityData: rootCA}}},
+		AuthInfos: []clientcmd.NamedAuthInfo{{Name: "kubelet", AuthInfo: clientcmd.AuthInfo{ClientCertificateData: kubelet}}},
+	})
+	assert.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "auth", "kubeconfig-kubelet"), data)
+
+	ignition := igntypes.Config{}
+	ignition.Ignition.Security.TLS.CertificateAuthorities = []igntypes.CaReference{{Source: dataurl.EncodeBytes(rootCA)}}
+	ignition.Storage.Files = []igntypes.File{{
+		Node: igntypes.Node{Path: "/opt/openshift/tls/root-ca.crt"},
+		FileEmbedded1: igntypes.FileEmbedded1{
+			Contents: igntypes.FileContents{Source: dataurl.EncodeBytes(rootCA)},
+		},
+	}}
+	data, err = json.Marshal(ignition)
+	assert.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "bootstrap.ign"), data)
+
+	// The state and hidden directories duplicate what is written
+	// elsewhere, and are skipped.
+	writeFile(t, filepath.Join(dir, ".openshift_install_state.json"), kubelet)
+	writeFile(t, filepath.Join(dir, ".snapshots", "kubelet.crt"), kubelet)
+
+	report, err := ScanDir(dir, 30*24*time.Hour)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, report.Expiring)
+	if assert.Len(t, report.Artifacts, 3) {
+		assert.Equal(t, "CN=kubelet-bootstrap", report.Artifacts[0].Name)
+		assert.Equal(t, []string{"auth/kubeconfig-kubelet"}, report.Artifacts[0].Sources)
+		assert.Equal(t, "CN=root-ca", report.Artifacts[1].Name)
+		assert.ElementsMatch(t, []string{
+			"auth/kubeconfig-kubelet",
+			"bootstrap.ign",
+			"bootstrap.ign:/opt/openshift/tls/root-ca.crt",
+			"tls/root-ca.crt",
+		}, report.Artifacts[1].Sources)
+		assert.Equal(t, KindKubeadmin, report.Artifacts[2].Kind)
+		assert.Nil(t, report.Artifacts[2].NotAfter)
+	}
+}
+
+func TestSecretArtifacts(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	current := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+
+	serving, cert := certificate(t, "router", tls.ValidityOneYear)
+	secrets := []corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-certs-default"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: serving},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "bootstrap-token-abcdef"},
+			Type:       bootstrapTokenType,
+			Data:       map[string][]byte{bootstrapTokenExpirationKey: []byte("2019-04-02T00:00:00Z")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "kube-system",
+				Name:        "kubeadmin",
+				Annotations: map[string]string{kubeadminExpiresAtAnnotation: "2019-04-01T06:00:00Z"},
+			},
+			Type: corev1.SecretTypeOpaque,
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unrelated"},
+			Type:       corev1.SecretTypeOpaque,
+		},
+	}
+
+	report := newReport(secretArtifacts(secrets), 7*24*time.Hour)
+	assert.Equal(t, 2, report.Expiring)
+	if assert.Len(t, report.Artifacts, 3) {
+		assert.Equal(t, KindKubeadmin, report.Artifacts[0].Kind)
+		remaining, ok := report.Artifacts[0].Remaining()
+		assert.True(t, ok)
+		assert.Equal(t, 6*time.Hour, remaining)
+		assert.Equal(t, KindBootstrapToken, report.Artifacts[1].Kind)
+		remaining, ok = report.Artifacts[1].Remaining()
+		assert.True(t, ok)
+		assert.Equal(t, 24*time.Hour, remaining)
+		assert.Equal(t, "CN=router", report.Artifacts[2].Name)
+		assert.Equal(t, cert.NotAfter, *report.Artifacts[2].NotAfter)
+	}
+}
@@ -0,0 +1,147 @@
+// Package fileaudit records the files the installer reads, writes and
+// removes to a report, so security reviews can check that nothing
+// unexpected is touched and installs can be sandboxed to the paths they
+// need.
+package fileaudit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// EnvVar holds the path of the report.  The --audit-files flag sets it, so
+// child processes record to the same report.
+const EnvVar = "OPENSHIFT_INSTALL_AUDIT_FILES"
+
+// Operation is what was done to a file.
+type Operation string
+
+const (
+	// Read means the file was read.
+	Read Operation = "read"
+	// Write means the file was created or overwritten.
+	Write Operation = "write"
+	// Remove means the file, or directory, was removed.
+	Remove Operation = "remove"
+)
+
+// Entry is a line of the report.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	PID       int       `json:"pid"`
+	Operation Operation `json:"operation"`
+	Path      string    `json:"path"`
+	Purpose   string    `json:"purpose"`
+
+	// SHA256 is the digest of the data read or written.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+var lock sync.Mutex
+
+// Enabled returns true if file accesses are being recorded.
+func Enabled() bool {
+	return os.Getenv(EnvVar) != ""
+}
+
+// Check returns an error if the report cannot be appended to, so a bad
+// --audit-files fails before anything is touched.
+func Check(report string) error {
+	f, err := os.OpenFile(report, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open the file audit report")
+	}
+	return f.Close()
+}
+
+// Record records an operation on path, with the digest of data unless it
+// is nil.
+func Record(op Operation, path string, purpose string, data []byte) {
+	if !Enabled() {
+		return
+	}
+	digest := ""
+	if data != nil {
+		sum := sha256.Sum256(data)
+		digest = hex.EncodeToString(sum[:])
+	}
+	record(op, path, purpose, digest)
+}
+
+// RecordFile records an operation on path, with the digest of the file's
+// current contents.  Use it for files which were streamed rather than
+// read or written whole.
+func RecordFile(op Operation, path string, purpose string) {
+	if !Enabled() {
+		return
+	}
+	digest := ""
+	if f, err := os.Open(path); err == nil {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, f); err == nil {
+			digest = hex.EncodeToString(hash.Sum(nil))
+		}
+		f.Close()
+	}
+	record(op, path, purpose, digest)
+}
+
+// ReadFile is ioutil.ReadFile, recording the read.
+func ReadFile(path string, purpose string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		Record(Read, path, purpose, data)
+	}
+	return data, err
+}
+
+// WriteFile is ioutil.WriteFile, recording the write.
+func WriteFile(path string, data []byte, perm os.FileMode, purpose string) error {
+	err := ioutil.WriteFile(path, data, perm)
+	if err == nil {
+		Record(Write, path, purpose, data)
+	}
+	return err
+}
+
+func record(op Operation, path string, purpose string, digest string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	line, err := json.Marshal(&Entry{
+		Time:      time.Now().UTC(),
+		PID:       os.Getpid(),
+		Operation: op,
+		Path:      path,
+		Purpose:   purpose,
+		SHA256:    digest,
+	})
+	if err != nil {
+		logrus.Errorf("Failed to record the %s of %s: %v", op, path, err)
+		return
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	report := os.Getenv(EnvVar)
+	f, err := os.OpenFile(report, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err == nil {
+		_, err = f.Write(append(line, '\n'))
+		if err2 := f.Close(); err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		logrus.Errorf("Failed to record the %s of %s to %s: %v", op, path, report, err)
+	}
+}
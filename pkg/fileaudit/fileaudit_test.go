@@ -0,0 +1,79 @@
+package fileaudit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileaudit")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(EnvVar)
+
+	report := filepath.Join(dir, "report.jsonl")
+	path := filepath.Join(dir, "file")
+
+	os.Unsetenv(EnvVar)
+	assert.NoError(t, WriteFile(path, []byte("unrecorded"), 0600, "test"))
+	_, err = os.Stat(report)
+	assert.True(t, os.IsNotExist(err), "a report was written while disabled")
+
+	if !assert.NoError(t, Check(report)) {
+		return
+	}
+	os.Setenv(EnvVar, report)
+	assert.NoError(t, WriteFile(path, []byte("data"), 0600, "test write"))
+	data, err := ReadFile(path, "test read")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+	_, err = ReadFile(filepath.Join(dir, "missing"), "test missing")
+	assert.Error(t, err)
+	RecordFile(Read, path, "test stream")
+	Record(Remove, path, "test remove", nil)
+
+	f, err := os.Open(report)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry)) {
+			assert.Equal(t, os.Getpid(), entry.PID)
+			assert.False(t, entry.Time.IsZero())
+			entries = append(entries, entry)
+		}
+	}
+
+	digest := "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+	expected := []struct {
+		op      Operation
+		purpose string
+		digest  string
+	}{
+		{Write, "test write", digest},
+		{Read, "test read", digest},
+		{Read, "test stream", digest},
+		{Remove, "test remove", ""},
+	}
+	if !assert.Len(t, entries, len(expected)) {
+		return
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.op, entries[i].Operation)
+		assert.Equal(t, path, entries[i].Path)
+		assert.Equal(t, e.purpose, entries[i].Purpose)
+		assert.Equal(t, e.digest, entries[i].SHA256)
+	}
+}
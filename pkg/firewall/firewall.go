@@ -0,0 +1,288 @@
+// Package firewall derives the network flows a cluster requires from its
+// install config, so firewalls can be opened ahead of the install, and
+// renders nftables rules which only accept those flows on the nodes.
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// Endpoints, the sources and destinations of flows.
+const (
+	// External is any client outside the cluster, e.g. users of the API
+	// and of routes.
+	External = "external"
+	// Bootstrap is the bootstrap machine.
+	Bootstrap = "bootstrap"
+	// Master is the control plane machines.
+	Master = "master"
+	// Worker is the compute machines.
+	Worker = "worker"
+	// Provisioning is the bare metal hosts being provisioned, on the
+	// provisioning network.
+	Provisioning = "provisioning"
+	// DNS is the upstream resolvers the cluster's DNS forwards to.
+	DNS = "dns"
+	// RegistryMirror is the bare metal registry mirror VM.
+	RegistryMirror = "registry-mirror"
+)
+
+// nodes are the endpoints which are machines of the cluster.
+var nodes = map[string]bool{Bootstrap: true, Master: true, Worker: true}
+
+// Requirements are the networks and flows of a cluster.
+type Requirements struct {
+	// MachineNetworks are the address spaces of the cluster's machines.
+	MachineNetworks []string `json:"machineNetworks"`
+	// ClusterNetworks are the address spaces of the pods.
+	ClusterNetworks []string `json:"clusterNetworks"`
+	// ServiceNetworks are the address spaces of the services.
+	ServiceNetworks []string `json:"serviceNetworks"`
+	// Flows are the flows which must be allowed.
+	Flows []Flow `json:"flows"`
+}
+
+// Flow is traffic from any of its sources to any of its destinations.
+type Flow struct {
+	// Name identifies the flow.
+	Name string `json:"name"`
+	// Description is what the flow is for.
+	Description string `json:"description"`
+	// Sources are the endpoints the traffic comes from.
+	Sources []string `json:"sources"`
+	// Destinations are the endpoints the traffic goes to.
+	Destinations []string `json:"destinations"`
+	// Addresses, when known, are the destination addresses, e.g. a
+	// virtual IP the destinations share.
+	Addresses []string `json:"addresses,omitempty"`
+	// Protocol is tcp, udp or vrrp.
+	Protocol string `json:"protocol"`
+	// Port is the destination port, or the first of a range.
+	Port int `json:"port,omitempty"`
+	// EndPort, when set, is the last port of the range starting at Port.
+	EndPort int `json:"endPort,omitempty"`
+}
+
+// ports returns the flow's port or port range as nftables and the
+// requirements' readers write them.
+func (f *Flow) ports() string {
+	if f.EndPort > 0 {
+		return fmt.Sprintf("%d-%d", f.Port, f.EndPort)
+	}
+	return fmt.Sprint(f.Port)
+}
+
+// internal returns true if the flow only comes from the cluster's
+// machines.
+func (f *Flow) internal() bool {
+	for _, source := range f.Sources {
+		if !nodes[source] {
+			return false
+		}
+	}
+	return true
+}
+
+// ForConfig returns the requirements of the cluster the install config
+// describes.
+func ForConfig(config *types.InstallConfig) *Requirements {
+	req := &Requirements{}
+	machineNetworks := map[string]bool{}
+	addMachineNetwork := func(pool *types.MachinePool) {
+		if cidr := pool.MachineNetwork(config.Networking); cidr != nil && !machineNetworks[cidr.String()] {
+			machineNetworks[cidr.String()] = true
+			req.MachineNetworks = append(req.MachineNetworks, cidr.String())
+		}
+	}
+	addMachineNetwork(config.ControlPlane)
+	for i := range config.Compute {
+		addMachineNetwork(&config.Compute[i])
+	}
+	if config.Networking != nil {
+		for _, cn := range config.Networking.ClusterNetwork {
+			req.ClusterNetworks = append(req.ClusterNetworks, cn.CIDR.String())
+		}
+		for _, sn := range config.Networking.ServiceNetwork {
+			req.ServiceNetworks = append(req.ServiceNetworks, sn.String())
+		}
+	}
+
+	var apiAddresses, ingressAddresses []string
+	bm := config.Platform.BareMetal
+	if bm != nil && bm.APIVIP != "" {
+		apiAddresses = []string{bm.APIVIP}
+	}
+	if bm != nil && bm.IngressVIP != "" {
+		ingressAddresses = []string{bm.IngressVIP}
+	}
+
+	routers := Worker
+	if computeReplicas(config) == 0 {
+		routers = Master
+	}
+
+	add := func(flow Flow) {
+		req.Flows = append(req.Flows, flow)
+	}
+	add(Flow{Name: "kubernetes-api", Description: "Kubernetes API", Sources: []string{External, Bootstrap, Master, Worker}, Destinations: []string{Bootstrap, Master}, Addresses: apiAddresses, Protocol: "tcp", Port: 6443})
+	add(Flow{Name: "machine-config-server", Description: "Ignition configs for joining machines", Sources: []string{Master, Worker}, Destinations: []string{Bootstrap, Master}, Addresses: apiAddresses, Protocol: "tcp", Port: 22623})
+	add(Flow{Name: "ingress-http", Description: "Routes over HTTP", Sources: []string{External}, Destinations: []string{routers}, Addresses: ingressAddresses, Protocol: "tcp", Port: 80})
+	add(Flow{Name: "ingress-https", Description: "Routes over HTTPS", Sources: []string{External}, Destinations: []string{routers}, Addresses: ingressAddresses, Protocol: "tcp", Port: 443})
+	add(Flow{Name: "etcd-client", Description: "etcd clients, the API servers", Sources: []string{Bootstrap, Master}, Destinations: []string{Master}, Protocol: "tcp", Port: 2379})
+	add(Flow{Name: "etcd-peer", Description: "etcd members' replication", Sources: []string{Master}, Destinations: []string{Master}, Protocol: "tcp", Port: 2380})
+	add(Flow{Name: "kubelet", Description: "Kubelet API, for logs, exec and metrics", Sources: []string{Bootstrap, Master}, Destinations: []string{Master, Worker}, Protocol: "tcp", Port: 10250})
+	add(Flow{Name: "host-services", Description: "Host network services, e.g. the node exporter", Sources: []string{Master, Worker}, Destinations: []string{Master, Worker}, Protocol: "tcp", Port: 9000, EndPort: 9999})
+	if config.Networking != nil {
+		switch config.Networking.NetworkType {
+		case "OpenShiftSDN":
+			add(Flow{Name: "sdn-vxlan", Description: "Pod network overlay (VXLAN)", Sources: []string{Master, Worker}, Destinations: []string{Master, Worker}, Protocol: "udp", Port: 4789})
+		case "OVNKubernetes":
+			add(Flow{Name: "ovn-geneve", Description: "Pod network overlay (Geneve)", Sources: []string{Master, Worker}, Destinations: []string{Master, Worker}, Protocol: "udp", Port: 6081})
+			add(Flow{Name: "ovn-databases", Description: "OVN northbound and southbound databases", Sources: []string{Master, Worker}, Destinations: []string{Master}, Protocol: "tcp", Port: 6641, EndPort: 6642})
+		}
+	}
+	add(Flow{Name: "node-ports-tcp", Description: "Services of type NodePort", Sources: []string{External}, Destinations: []string{Master, Worker}, Protocol: "tcp", Port: 30000, EndPort: 32767})
+	add(Flow{Name: "node-ports-udp", Description: "Services of type NodePort", Sources: []string{External}, Destinations: []string{Master, Worker}, Protocol: "udp", Port: 30000, EndPort: 32767})
+	add(Flow{Name: "bootstrap-journal", Description: "Bootstrap logs, for gathering", Sources: []string{External}, Destinations: []string{Bootstrap}, Protocol: "tcp", Port: 19531})
+	if config.SSHKey != "" {
+		add(Flow{Name: "ssh", Description: "SSH as the core user", Sources: []string{External}, Destinations: []string{Bootstrap, Master, Worker}, Protocol: "tcp", Port: 22})
+	}
+
+	if bm != nil {
+		add(Flow{Name: "vrrp", Description: "Keepalived, holding the virtual IPs", Sources: []string{Bootstrap, Master}, Destinations: []string{Bootstrap, Master}, Protocol: "vrrp"})
+		provisioning := []string{Bootstrap, Master}
+		add(Flow{Name: "provisioning-dhcp", Description: "DHCP for PXE booting hosts", Sources: []string{Provisioning}, Destinations: provisioning, Protocol: "udp", Port: 67})
+		add(Flow{Name: "provisioning-tftp", Description: "TFTP for PXE booting hosts", Sources: []string{Provisioning}, Destinations: provisioning, Protocol: "udp", Port: 69})
+		add(Flow{Name: "provisioning-images", Description: "Images and iPXE scripts for hosts being provisioned", Sources: []string{Provisioning}, Destinations: provisioning, Protocol: "tcp", Port: 80})
+		add(Flow{Name: "ironic-api", Description: "Ironic API, for the ironic agent", Sources: []string{Provisioning}, Destinations: provisioning, Protocol: "tcp", Port: 6385})
+		add(Flow{Name: "ironic-inspector", Description: "Ironic inspector, for introspection data", Sources: []string{Provisioning}, Destinations: provisioning, Protocol: "tcp", Port: 5050})
+		if mirror := bm.RegistryMirror; mirror != nil {
+			if host, _, err := net.SplitHostPort(mirror.Endpoint()); err == nil {
+				add(Flow{Name: "registry-mirror", Description: "Release images from the registry mirror", Sources: []string{Bootstrap, Master, Worker}, Destinations: []string{RegistryMirror}, Addresses: []string{host}, Protocol: "tcp", Port: mirror.Port})
+			}
+		}
+	}
+
+	if dns := config.DNS; dns != nil {
+		byPort := map[int][]string{}
+		for _, upstream := range dns.Upstreams {
+			address, port, err := types.SplitDNSUpstream(upstream)
+			if err == nil && !contains(byPort[port], address) {
+				byPort[port] = append(byPort[port], address)
+			}
+		}
+		ports := make([]int, 0, len(byPort))
+		for port := range byPort {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+		for _, port := range ports {
+			for _, protocol := range []string{"udp", "tcp"} {
+				add(Flow{Name: fmt.Sprintf("dns-%s-%d", protocol, port), Description: "Upstream DNS resolvers", Sources: []string{Master, Worker}, Destinations: []string{DNS}, Addresses: byPort[port], Protocol: protocol, Port: port})
+			}
+		}
+	}
+
+	return req
+}
+
+// computeReplicas returns the number of compute machines.
+func computeReplicas(config *types.InstallConfig) int64 {
+	var replicas int64
+	for _, pool := range config.Compute {
+		if pool.Replicas != nil {
+			replicas += *pool.Replicas
+		}
+	}
+	return replicas
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NFTables returns nftables rules for the nodes of the destination
+// endpoint, which drop incoming traffic other than the flows to it.
+// Traffic from the pods and services and replies are always accepted, and
+// flows only from the cluster's machines are only accepted from the
+// machine networks.
+func (r *Requirements) NFTables(destination string) string {
+	var rules []string
+	rules = append(rules,
+		"ct state established,related accept",
+		`iifname "lo" accept`,
+		"meta l4proto { icmp, ipv6-icmp } accept",
+		"udp sport 67 udp dport 68 accept",
+	)
+	for _, match := range saddrMatches(append(append([]string{}, r.ClusterNetworks...), r.ServiceNetworks...)) {
+		rules = append(rules, match+" accept")
+	}
+	for _, f := range r.Flows {
+		if !contains(f.Destinations, destination) {
+			continue
+		}
+		var match string
+		switch f.Protocol {
+		case "vrrp":
+			match = "meta l4proto vrrp"
+		default:
+			match = fmt.Sprintf("%s dport %s", f.Protocol, f.ports())
+		}
+		comment := fmt.Sprintf(" comment %q", f.Name)
+		if !f.internal() || len(r.MachineNetworks) == 0 {
+			rules = append(rules, match+" accept"+comment)
+			continue
+		}
+		for _, saddr := range saddrMatches(r.MachineNetworks) {
+			rules = append(rules, saddr+" "+match+" accept"+comment)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("table inet openshift_firewall\n")
+	b.WriteString("flush table inet openshift_firewall\n")
+	b.WriteString("table inet openshift_firewall {\n")
+	b.WriteString("\tchain input {\n")
+	b.WriteString("\t\ttype filter hook input priority 0; policy drop;\n")
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "\t\t%s\n", rule)
+	}
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// saddrMatches returns nftables matches of the source addresses in cidrs,
+// one for each address family.
+func saddrMatches(cidrs []string) []string {
+	var v4, v6 []string
+	for _, cidr := range cidrs {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			v4 = append(v4, cidr)
+		} else {
+			v6 = append(v6, cidr)
+		}
+	}
+	var matches []string
+	if len(v4) > 0 {
+		matches = append(matches, fmt.Sprintf("ip saddr { %s }", strings.Join(v4, ", ")))
+	}
+	if len(v6) > 0 {
+		matches = append(matches, fmt.Sprintf("ip6 saddr { %s }", strings.Join(v6, ", ")))
+	}
+	return matches
+}
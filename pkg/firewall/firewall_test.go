@@ -0,0 +1,103 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func testConfig() *types.InstallConfig {
+	return &types.InstallConfig{
+		Networking: &types.Networking{
+			MachineCIDR:    ipnet.MustParseCIDR("192.168.111.0/24"),
+			NetworkType:    "OpenShiftSDN",
+			ClusterNetwork: []types.ClusterNetworkEntry{{CIDR: *ipnet.MustParseCIDR("10.128.0.0/14"), HostPrefix: 23}},
+			ServiceNetwork: []ipnet.IPNet{*ipnet.MustParseCIDR("172.30.0.0/16")},
+		},
+		ControlPlane: &types.MachinePool{Name: "master", Replicas: pointer.Int64Ptr(3)},
+		Compute:      []types.MachinePool{{Name: "worker", Replicas: pointer.Int64Ptr(2)}},
+		Platform: types.Platform{
+			BareMetal: &baremetal.Platform{APIVIP: "192.168.111.5", IngressVIP: "192.168.111.4"},
+		},
+	}
+}
+
+func flowsByName(req *Requirements) map[string]Flow {
+	flows := map[string]Flow{}
+	for _, f := range req.Flows {
+		flows[f.Name] = f
+	}
+	return flows
+}
+
+func TestForConfig(t *testing.T) {
+	config := testConfig()
+	config.DNS = &types.DNS{
+		Upstreams: []string{"10.0.0.1", "[fd00::1]:53"},
+	}
+	req := ForConfig(config)
+	assert.Equal(t, []string{"192.168.111.0/24"}, req.MachineNetworks)
+	assert.Equal(t, []string{"10.128.0.0/14"}, req.ClusterNetworks)
+	assert.Equal(t, []string{"172.30.0.0/16"}, req.ServiceNetworks)
+
+	flows := flowsByName(req)
+	assert.Equal(t, []string{"192.168.111.5"}, flows["kubernetes-api"].Addresses)
+	assert.Equal(t, []string{Worker}, flows["ingress-https"].Destinations)
+	assert.Equal(t, []string{"192.168.111.4"}, flows["ingress-https"].Addresses)
+	assert.Equal(t, []string{Master}, flows["etcd-peer"].Destinations)
+	assert.Contains(t, flows, "sdn-vxlan")
+	assert.Contains(t, flows, "ironic-api")
+	assert.NotContains(t, flows, "ssh")
+	assert.Equal(t, []string{"10.0.0.1", "fd00::1"}, flows["dns-udp-53"].Addresses)
+	assert.Equal(t, []string{"10.0.0.1", "fd00::1"}, flows["dns-tcp-53"].Addresses)
+
+	config = testConfig()
+	config.Platform = types.Platform{}
+	config.Compute[0].Replicas = pointer.Int64Ptr(0)
+	config.SSHKey = "ssh-ed25519 AAAA"
+	flows = flowsByName(ForConfig(config))
+	assert.Nil(t, flows["kubernetes-api"].Addresses)
+	assert.Equal(t, []string{Master}, flows["ingress-https"].Destinations)
+	assert.Equal(t, []string{Bootstrap, Master, Worker}, flows["ssh"].Destinations)
+	assert.NotContains(t, flows, "ironic-api")
+	assert.NotContains(t, flows, "vrrp")
+}
+
+func TestNFTables(t *testing.T) {
+	req := &Requirements{
+		MachineNetworks: []string{"192.168.111.0/24", "fd00:1::/64"},
+		ClusterNetworks: []string{"10.128.0.0/14"},
+		ServiceNetworks: []string{"172.30.0.0/16"},
+		Flows: []Flow{
+			{Name: "kubernetes-api", Sources: []string{External, Master}, Destinations: []string{Master}, Protocol: "tcp", Port: 6443},
+			{Name: "etcd-peer", Sources: []string{Master}, Destinations: []string{Master}, Protocol: "tcp", Port: 2380},
+			{Name: "node-ports-udp", Sources: []string{External}, Destinations: []string{Master, Worker}, Protocol: "udp", Port: 30000, EndPort: 32767},
+			{Name: "vrrp", Sources: []string{Master}, Destinations: []string{Master}, Protocol: "vrrp"},
+			{Name: "dns-udp-53", Sources: []string{Master, Worker}, Destinations: []string{DNS}, Protocol: "udp", Port: 53},
+		},
+	}
+	assert.Equal(t, `table inet openshift_firewall
+flush table inet openshift_firewall
+table inet openshift_firewall {
+	chain input {
+		type filter hook input priority 0; policy drop;
+		ct state established,related accept
+		iifname "lo" accept
+		meta l4proto { icmp, ipv6-icmp } accept
+		udp sport 67 udp dport 68 accept
+		ip saddr { 10.128.0.0/14, 172.30.0.0/16 } accept
+		tcp dport 6443 accept comment "kubernetes-api"
+		ip saddr { 192.168.111.0/24 } tcp dport 2380 accept comment "etcd-peer"
+		ip6 saddr { fd00:1::/64 } tcp dport 2380 accept comment "etcd-peer"
+		udp dport 30000-32767 accept comment "node-ports-udp"
+		ip saddr { 192.168.111.0/24 } meta l4proto vrrp accept comment "vrrp"
+		ip6 saddr { fd00:1::/64 } meta l4proto vrrp accept comment "vrrp"
+	}
+}
+`, req.NFTables(Master))
+}
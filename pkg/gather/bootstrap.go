@@ -0,0 +1,193 @@
+// Package gather harvests the bootstrap machine's artifacts into the
+// asset directory before the bootstrap is destroyed, so failures which
+// only show once the control plane is on its own can be investigated
+// after the machine is gone.
+//
+// The artifacts are fetched over SSH as the core user with the system's
+// ssh client, which must be able to authenticate with the key in the
+// install-config (for example through ssh-agent).
+package gather
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+const (
+	// ArtifactsDir is the directory in the asset directory the bootstrap
+	// artifacts are written to.
+	ArtifactsDir = "bootstrap-artifacts"
+
+	// archiveName is the bootstrap machine's archive in ArtifactsDir.
+	archiveName = "bootstrap.tar.gz"
+
+	// discoveryName is the etcd discovery records in ArtifactsDir.
+	discoveryName = "etcd-discovery.txt"
+)
+
+// script runs as root on the bootstrap machine and writes a gzipped
+// tarball of its journal, its rendered assets and its etcd environment to
+// stdout.
+const script = `set -u
+dir=$(mktemp -d)
+trap 'rm -rf "${dir}"' EXIT
+mkdir -p "${dir}/journal" "${dir}/etcd"
+journalctl --no-pager --boot > "${dir}/journal/journal.log" 2>&1
+for unit in bootkube openshift progress prepull kubelet crio keepalived; do
+	journalctl --no-pager --boot --unit="${unit}.service" > "${dir}/journal/${unit}.log" 2>&1
+done
+cp -r /opt/openshift "${dir}/rendered-assets" 2>/dev/null
+cp -r /run/etcd/. "${dir}/etcd/" 2>/dev/null
+crictl ps --all > "${dir}/containers.txt" 2>&1
+tar -czf - -C "${dir}" .
+`
+
+// run runs an external command with stdin, returning its output.  Tests
+// override it.
+var run = func(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	logrus.Debugf("Running %s %s", name, strings.Join(args, " "))
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "%s failed: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// lookupSRV resolves SRV records.  Tests override it.
+var lookupSRV = net.LookupSRV
+
+// Bootstrap harvests the artifacts of the bootstrap machine at host into
+// ArtifactsDir in the asset directory dir, and records the etcd discovery
+// records of clusterDomain, if set, alongside them.  It returns the
+// directory the artifacts were written to.
+func Bootstrap(ctx context.Context, dir, host, clusterDomain string) (string, error) {
+	artifactsDir := filepath.Join(dir, ArtifactsDir)
+	if err := os.MkdirAll(artifactsDir, 0700); err != nil {
+		return "", err
+	}
+
+	if clusterDomain != "" {
+		if err := fileaudit.WriteFile(filepath.Join(artifactsDir, discoveryName), etcdDiscovery(clusterDomain), 0600, "bootstrap artifact"); err != nil {
+			return "", err
+		}
+	}
+
+	archive, err := run(ctx, strings.NewReader(script), "ssh",
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=30",
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"core@"+host,
+		"sudo", "sh", "-s",
+	)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to gather the artifacts of the bootstrap machine %s", host)
+	}
+	if len(archive) == 0 {
+		return "", errors.Errorf("the bootstrap machine %s returned no artifacts", host)
+	}
+	if err := fileaudit.WriteFile(filepath.Join(artifactsDir, archiveName), archive, 0600, "bootstrap artifact"); err != nil {
+		return "", err
+	}
+	return artifactsDir, nil
+}
+
+// etcdDiscovery returns the etcd server SRV records of clusterDomain, as
+// the installer host resolves them, or the error resolving them.
+func etcdDiscovery(clusterDomain string) []byte {
+	name := fmt.Sprintf("_etcd-server-ssl._tcp.%s", clusterDomain)
+	_, records, err := lookupSRV("etcd-server-ssl", "tcp", clusterDomain)
+	if err != nil {
+		return []byte(fmt.Sprintf("%s: %v\n", name, err))
+	}
+	var buf bytes.Buffer
+	for _, record := range records {
+		fmt.Fprintf(&buf, "%s SRV %d %d %d %s\n", name, record.Priority, record.Weight, record.Port, record.Target)
+		addrs, err := net.LookupHost(strings.TrimSuffix(record.Target, "."))
+		if err != nil {
+			fmt.Fprintf(&buf, "%s: %v\n", record.Target, err)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s A %s\n", record.Target, strings.Join(addrs, " "))
+	}
+	return buf.Bytes()
+}
+
+// addressAttribute matches the addresses of a libvirt domain's interfaces
+// in the Terraform state.
+var addressAttribute = regexp.MustCompile(`^network_interface\.[0-9]+\.addresses\.[0-9]+$`)
+
+// BootstrapHost returns the address of the bootstrap machine from the
+// cluster's platform Terraform variables, tfvars, and Terraform state,
+// tfstate, either of which may be nil: the libvirt bootstrap address from
+// the variables or, for machines which get their address from DHCP, the
+// address Terraform recorded for the bootstrap domain.  It returns "" if
+// neither is known.
+func BootstrapHost(tfvars, tfstate []byte) (string, error) {
+	if tfvars != nil {
+		var vars struct {
+			BootstrapIP string `json:"libvirt_bootstrap_ip"`
+		}
+		if err := json.Unmarshal(tfvars, &vars); err != nil {
+			return "", errors.Wrap(err, "failed to parse the Terraform variables")
+		}
+		if vars.BootstrapIP != "" {
+			return vars.BootstrapIP, nil
+		}
+	}
+
+	if tfstate == nil {
+		return "", nil
+	}
+	var state struct {
+		Modules []struct {
+			Path      []string `json:"path"`
+			Resources map[string]struct {
+				Primary struct {
+					Attributes map[string]string `json:"attributes"`
+				} `json:"primary"`
+			} `json:"resources"`
+		} `json:"modules"`
+	}
+	if err := json.Unmarshal(tfstate, &state); err != nil {
+		return "", errors.Wrap(err, "failed to parse the Terraform state")
+	}
+	for _, module := range state.Modules {
+		if len(module.Path) == 0 || module.Path[len(module.Path)-1] != "bootstrap" {
+			continue
+		}
+		attributes := module.Resources["libvirt_domain.bootstrap"].Primary.Attributes
+		var keys []string
+		for key := range attributes {
+			if addressAttribute.MatchString(key) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if ip := net.ParseIP(attributes[key]); ip != nil && ip.To4() != nil {
+				return attributes[key], nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,126 @@
+package gather
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapHost(t *testing.T) {
+	cases := []struct {
+		name     string
+		tfvars   string
+		tfstate  string
+		expected string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:     "libvirt variables",
+			tfvars:   `{"libvirt_bootstrap_ip": "192.168.126.10"}`,
+			expected: "192.168.126.10",
+		},
+		{
+			name:   "bare metal state",
+			tfvars: `{"libvirt_uri": "qemu:///system"}`,
+			tfstate: `{"modules": [
+  {"path": ["root"], "resources": {}},
+  {"path": ["root", "bootstrap"], "resources": {"libvirt_domain.bootstrap": {"primary": {"attributes": {
+    "network_interface.#": "2",
+    "network_interface.0.addresses.#": "2",
+    "network_interface.0.addresses.0": "fd00::20",
+    "network_interface.0.addresses.1": "192.168.111.20",
+    "network_interface.1.addresses.0": "172.22.0.2"
+  }}}}}
+]}`,
+			expected: "192.168.111.20",
+		},
+		{
+			name:    "no bootstrap addresses",
+			tfstate: `{"modules": [{"path": ["root", "bootstrap"], "resources": {}}]}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var tfvars, tfstate []byte
+			if tc.tfvars != "" {
+				tfvars = []byte(tc.tfvars)
+			}
+			if tc.tfstate != "" {
+				tfstate = []byte(tc.tfstate)
+			}
+			host, err := BootstrapHost(tfvars, tfstate)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, host)
+		})
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	defer func(r func(context.Context, io.Reader, string, ...string) ([]byte, error), l func(string, string, string) (string, []*net.SRV, error)) {
+		run, lookupSRV = r, l
+	}(run, lookupSRV)
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		return "", nil, errors.Errorf("no such host")
+	}
+
+	cases := []struct {
+		name          string
+		output        []byte
+		err           error
+		expectedError string
+	}{
+		{
+			name:   "gathered",
+			output: []byte("archive"),
+		},
+		{
+			name:          "ssh fails",
+			err:           errors.New("ssh failed: Permission denied (publickey)"),
+			expectedError: `^failed to gather the artifacts of the bootstrap machine 192\.168\.126\.10: ssh failed: Permission denied \(publickey\)$`,
+		},
+		{
+			name:          "no output",
+			expectedError: `^the bootstrap machine 192\.168\.126\.10 returned no artifacts$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "gather-")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer os.RemoveAll(dir)
+
+			var args []string
+			run = func(ctx context.Context, stdin io.Reader, name string, a ...string) ([]byte, error) {
+				args = append([]string{name}, a...)
+				return tc.output, tc.err
+			}
+
+			artifactsDir, err := Bootstrap(context.Background(), dir, "192.168.126.10", "test-cluster.example.com")
+			assert.Equal(t, []string{"ssh", "core@192.168.126.10", "sudo", "sh", "-s"}, append(args[:1:1], args[len(args)-4:]...))
+			discovery, _ := ioutil.ReadFile(filepath.Join(dir, ArtifactsDir, "etcd-discovery.txt"))
+			assert.Equal(t, "_etcd-server-ssl._tcp.test-cluster.example.com: no such host\n", string(discovery))
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, filepath.Join(dir, ArtifactsDir), artifactsDir)
+			archive, err := ioutil.ReadFile(filepath.Join(artifactsDir, "bootstrap.tar.gz"))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.output, archive)
+		})
+	}
+}
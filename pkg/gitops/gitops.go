@@ -0,0 +1,217 @@
+// Package gitops arranges the generated manifests of a cluster for
+// management in git after the install, e.g. by Argo CD or Flux.
+package gitops
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+const (
+	// LayoutKustomize arranges the manifests as a kustomize base, with a
+	// directory for the cluster-scoped objects and one per namespace.
+	LayoutKustomize = "kustomize"
+
+	// clusterDir holds the cluster-scoped objects.
+	clusterDir = "cluster"
+
+	// namespacesDir holds a directory for each namespace.
+	namespacesDir = "namespaces"
+
+	// kustomizationFilename is the file kustomize reads in each directory.
+	kustomizationFilename = "kustomization.yaml"
+)
+
+// Layouts are the supported repository layouts.
+var Layouts = []string{LayoutKustomize}
+
+var (
+	documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+	// unsafeFilenameChars are replaced in the names of objects when they
+	// are used in file names.
+	unsafeFilenameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+)
+
+// object is a single Kubernetes object parsed from a manifest.
+type object struct {
+	kind      string
+	name      string
+	namespace string
+	data      []byte
+}
+
+// EncryptFunc encrypts the data of a file, returning the name to write the
+// encrypted data under.
+type EncryptFunc func(filename string, data []byte) (string, []byte, error)
+
+// Arrange returns the objects of the given manifests rearranged in the
+// layout, one object per file, with paths relative to the root of the
+// repository.  Multi-document files and Lists are split into their objects.
+// Secrets are encrypted with encrypt, or left out if it is nil, so the
+// repository holds no credentials in plain text.  Encrypted Secrets which
+// are no longer YAML, e.g. age-encrypted ones, are not listed in the
+// kustomizations, as they must be decrypted before they are applied.
+func Arrange(files []*asset.File, layout string, encrypt EncryptFunc) ([]*asset.File, error) {
+	if layout != LayoutKustomize {
+		return nil, errors.Errorf("unsupported repository layout %q; supported layouts: %s", layout, strings.Join(Layouts, ", "))
+	}
+
+	var objects []object
+	for _, file := range files {
+		switch filepath.Ext(file.Filename) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		for _, doc := range documentSeparator.Split(string(file.Data), -1) {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			parsed, err := parseObjects([]byte(doc))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse %s", file.Filename)
+			}
+			objects = append(objects, parsed...)
+		}
+	}
+
+	var skipped []string
+	seen := map[string]bool{}
+	dirs := map[string][]string{}
+	arranged := []*asset.File{}
+	for _, o := range objects {
+		if o.kind == "Secret" && encrypt == nil {
+			skipped = append(skipped, fmt.Sprintf("%s/%s", o.namespace, o.name))
+			continue
+		}
+		dir := clusterDir
+		if o.namespace != "" {
+			dir = path.Join(namespacesDir, o.namespace)
+		}
+		filename := fmt.Sprintf("%s-%s.yaml", strings.ToLower(o.kind), unsafeFilenameChars.ReplaceAllString(strings.ToLower(o.name), "-"))
+		if seen[path.Join(dir, filename)] {
+			return nil, errors.Errorf("%s %s is defined more than once", o.kind, o.name)
+		}
+		seen[path.Join(dir, filename)] = true
+		data := o.data
+		if o.kind == "Secret" {
+			var err error
+			filename, data, err = encrypt(filename, data)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if path.Ext(filename) == ".yaml" {
+			dirs[dir] = append(dirs[dir], filename)
+		}
+		arranged = append(arranged, &asset.File{
+			Filename: path.Join(dir, filename),
+			Data:     data,
+		})
+	}
+	if len(skipped) > 0 {
+		logrus.Warnf("Leaving out Secrets %s, which must not be committed to git in plain text; set secretsEncryption in the install config to export them encrypted", strings.Join(skipped, ", "))
+	}
+
+	var subdirs []string
+	for dir, filenames := range dirs {
+		sort.Strings(filenames)
+		data, err := kustomization(filenames)
+		if err != nil {
+			return nil, err
+		}
+		arranged = append(arranged, &asset.File{
+			Filename: path.Join(dir, kustomizationFilename),
+			Data:     data,
+		})
+		subdirs = append(subdirs, dir)
+	}
+	sort.Strings(subdirs)
+	data, err := kustomization(subdirs)
+	if err != nil {
+		return nil, err
+	}
+	arranged = append(arranged, &asset.File{
+		Filename: kustomizationFilename,
+		Data:     data,
+	})
+
+	asset.SortFiles(arranged)
+	return arranged, nil
+}
+
+// parseObjects returns the object in a single YAML or JSON document, or the
+// items of a List.
+func parseObjects(doc []byte) ([]object, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(doc, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if kind, _ := raw["kind"].(string); kind == "List" {
+		items, _ := raw["items"].([]interface{})
+		var objects []object
+		for _, item := range items {
+			data, err := yaml.Marshal(item)
+			if err != nil {
+				return nil, err
+			}
+			parsed, err := parseObjects(data)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, parsed...)
+		}
+		return objects, nil
+	}
+
+	var meta struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(doc, &meta); err != nil {
+		return nil, err
+	}
+	if meta.Kind == "" || meta.Metadata.Name == "" {
+		return nil, errors.New("kind and metadata.name are required")
+	}
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return []object{{
+		kind:      meta.Kind,
+		name:      meta.Metadata.Name,
+		namespace: meta.Metadata.Namespace,
+		data:      data,
+	}}, nil
+}
+
+// kustomization returns a kustomization.yaml listing the given resources.
+func kustomization(resources []string) ([]byte, error) {
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal kustomization")
+	}
+	return data, nil
+}
@@ -0,0 +1,104 @@
+package gitops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+func TestArrange(t *testing.T) {
+	files := []*asset.File{
+		{
+			Filename: "manifests/cluster-config.yaml",
+			Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster-config-v1
+  namespace: kube-system
+data:
+  install-config: ""
+---
+apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: cluster
+`),
+		},
+		{
+			Filename: "openshift/99_kubeadmin-password-secret.yaml",
+			Data: []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: kubeadmin
+  namespace: kube-system
+`),
+		},
+		{
+			Filename: "openshift/99_openshift-cluster-api_worker-user-data-secret.yaml",
+			Data: []byte(`apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: worker-user-data
+    namespace: openshift-machine-api
+`),
+		},
+		{
+			Filename: "manifests/README",
+			Data:     []byte("not a manifest"),
+		},
+	}
+
+	arranged, err := Arrange(files, LayoutKustomize, nil)
+	assert.NoError(t, err)
+
+	contents := map[string]string{}
+	var filenames []string
+	for _, f := range arranged {
+		filenames = append(filenames, f.Filename)
+		contents[f.Filename] = string(f.Data)
+	}
+	assert.Equal(t, []string{
+		"cluster/infrastructure-cluster.yaml",
+		"cluster/kustomization.yaml",
+		"kustomization.yaml",
+		"namespaces/kube-system/configmap-cluster-config-v1.yaml",
+		"namespaces/kube-system/kustomization.yaml",
+		"namespaces/openshift-machine-api/kustomization.yaml",
+		"namespaces/openshift-machine-api/serviceaccount-worker-user-data.yaml",
+	}, filenames)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- cluster
+- namespaces/kube-system
+- namespaces/openshift-machine-api
+`, contents["kustomization.yaml"])
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- configmap-cluster-config-v1.yaml
+`, contents["namespaces/kube-system/kustomization.yaml"])
+
+	arranged, err = Arrange(files, LayoutKustomize, func(filename string, data []byte) (string, []byte, error) {
+		return filename + ".age", []byte("encrypted"), nil
+	})
+	assert.NoError(t, err)
+	contents = map[string]string{}
+	for _, f := range arranged {
+		contents[f.Filename] = string(f.Data)
+	}
+	assert.Equal(t, "encrypted", contents["namespaces/kube-system/secret-kubeadmin.yaml.age"])
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- configmap-cluster-config-v1.yaml
+`, contents["namespaces/kube-system/kustomization.yaml"], "age-encrypted Secrets must not be listed")
+
+	_, err = Arrange(files, "helm", nil)
+	assert.EqualError(t, err, `unsupported repository layout "helm"; supported layouts: kustomize`)
+}
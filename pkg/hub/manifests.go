@@ -0,0 +1,158 @@
+// Package hub runs installs from a management ("hub") cluster.  Each
+// install of a spoke cluster runs in a Job on the hub, and the asset
+// directory, which is lost with the Job's pod, is kept in a Secret between
+// phases so a restarted pod picks up where the last one stopped.
+package hub
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// InstallConfigKey is the key of the install config in the state
+	// Secret, before the first phase has run.
+	InstallConfigKey = "install-config.yaml"
+
+	// assetDir is where the Job's pod keeps the asset directory.
+	assetDir = "/assets"
+)
+
+// Options configure the manifests for installing a spoke cluster.
+type Options struct {
+	// Name is the name of the spoke cluster, which prefixes the names of
+	// the generated resources.
+	Name string
+
+	// Namespace is the hub namespace to run the install in.
+	Namespace string
+
+	// Image is the installer image to run.
+	Image string
+
+	// InstallConfig is the spoke cluster's install-config.yaml.
+	InstallConfig []byte
+}
+
+// StateSecretName returns the name of the Secret holding the state of the
+// install of the named spoke cluster.
+func StateSecretName(name string) string {
+	return fmt.Sprintf("%s-install-state", name)
+}
+
+// Manifests returns the manifests for the hub resources which install a
+// spoke cluster, as a multi-document YAML stream: a ServiceAccount allowed
+// to update the state Secret, the state Secret seeded with the install
+// config, and the Job running the install.
+func Manifests(opts Options) ([]byte, error) {
+	if opts.Name == "" || opts.Namespace == "" || opts.Image == "" {
+		return nil, errors.New("the name, namespace and image are required")
+	}
+	if len(opts.InstallConfig) == 0 {
+		return nil, errors.New("an install config is required")
+	}
+
+	name := fmt.Sprintf("%s-install", opts.Name)
+	secret := StateSecretName(opts.Name)
+	meta := func() metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: opts.Namespace,
+			Labels:    map[string]string{"kni.openshift.io/spoke": opts.Name},
+		}
+	}
+
+	stateMeta := meta()
+	stateMeta.Name = secret
+
+	objects := []interface{}{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta(),
+		},
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+			ObjectMeta: meta(),
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets"},
+				ResourceNames: []string{secret},
+				Verbs:         []string{"get", "update"},
+			}},
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: meta(),
+			Subjects: []rbacv1.Subject{{
+				Kind:      "ServiceAccount",
+				Name:      name,
+				Namespace: opts.Namespace,
+			}},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     name,
+			},
+		},
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: stateMeta,
+			Data: map[string][]byte{
+				InstallConfigKey: opts.InstallConfig,
+			},
+		},
+		&batchv1.Job{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+			ObjectMeta: meta(),
+			Spec: batchv1.JobSpec{
+				BackoffLimit: pointer.Int32Ptr(3),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						ServiceAccountName: name,
+						RestartPolicy:      corev1.RestartPolicyNever,
+						Containers: []corev1.Container{{
+							Name:    "installer",
+							Image:   opts.Image,
+							Command: []string{"kni-install"},
+							Args: []string{
+								"--dir", assetDir,
+								"hub", "run",
+								"--namespace", opts.Namespace,
+								"--secret", secret,
+							},
+							VolumeMounts: []corev1.VolumeMount{{
+								Name:      "assets",
+								MountPath: assetDir,
+							}},
+						}},
+						Volumes: []corev1.Volume{{
+							Name: "assets",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	for _, object := range objects {
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal manifest")
+		}
+		buf.WriteString("---\n")
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
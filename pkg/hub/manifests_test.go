@@ -0,0 +1,47 @@
+package hub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestManifests(t *testing.T) {
+	_, err := Manifests(Options{Name: "spoke-1", Namespace: "spokes"})
+	assert.EqualError(t, err, "the name, namespace and image are required")
+
+	data, err := Manifests(Options{
+		Name:          "spoke-1",
+		Namespace:     "spokes",
+		Image:         "registry.example.com/kni/installer:latest",
+		InstallConfig: []byte("apiVersion: v1beta4\n"),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	documents := strings.Split(string(data), "---\n")[1:]
+	if !assert.Len(t, documents, 5) {
+		return
+	}
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, yaml.Unmarshal([]byte(documents[3]), secret))
+	assert.Equal(t, "spoke-1-install-state", secret.Name)
+	assert.Equal(t, "apiVersion: v1beta4\n", string(secret.Data[InstallConfigKey]))
+
+	job := &batchv1.Job{}
+	assert.NoError(t, yaml.Unmarshal([]byte(documents[4]), job))
+	assert.Equal(t, "spoke-1-install", job.Name)
+	assert.Equal(t, "spokes", job.Namespace)
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, "spoke-1-install", pod.ServiceAccountName)
+	if assert.Len(t, pod.Containers, 1) {
+		assert.Equal(t, "registry.example.com/kni/installer:latest", pod.Containers[0].Image)
+		assert.Equal(t, []string{"--dir", "/assets", "hub", "run", "--namespace", "spokes", "--secret", "spoke-1-install-state"}, pod.Containers[0].Args)
+	}
+}
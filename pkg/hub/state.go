@@ -0,0 +1,208 @@
+package hub
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+const (
+	// assetsKey is the key of the archived asset directory in the state
+	// Secret.
+	assetsKey = "assets.tar.gz"
+
+	// maxSecretSize is the most data a Secret can hold.
+	maxSecretSize = 1024 * 1024
+
+	// cacheDir is the user cache directory if $HOME is the asset
+	// directory.  It holds e.g. downloaded RHCOS images, which are too
+	// large for the Secret and are not part of the install's state.
+	cacheDir = ".cache"
+)
+
+// StateStore keeps an asset directory in a Secret.
+type StateStore struct {
+	Client    corev1client.SecretsGetter
+	Namespace string
+	Name      string
+
+	mu    sync.Mutex
+	saved [sha256.Size]byte
+}
+
+// Restore fills dir from the Secret.  Before the first Save, the Secret's
+// keys are written to dir as files, seeding it with e.g. the install
+// config.
+func (s *StateStore) Restore(dir string) error {
+	secret, err := s.Client.Secrets(s.Namespace).Get(s.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get secret %s/%s", s.Namespace, s.Name)
+	}
+
+	if archive, ok := secret.Data[assetsKey]; ok {
+		logrus.Infof("Restoring the asset directory from secret %s/%s", s.Namespace, s.Name)
+		return unarchive(archive, dir)
+	}
+
+	for key, data := range secret.Data {
+		if err := fileaudit.WriteFile(filepath.Join(dir, key), data, 0600, "asset directory restored from the hub"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save archives dir into the Secret, replacing the keys it was seeded with.
+// The Secret is not updated if dir is unchanged since the last Save.
+func (s *StateStore) Save(dir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archive, err := archive(dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to archive the asset directory")
+	}
+	if len(archive) > maxSecretSize {
+		return errors.Errorf("the archived asset directory is %d bytes, more than the %d a secret can hold", len(archive), maxSecretSize)
+	}
+	digest := sha256.Sum256(archive)
+	if digest == s.saved {
+		return nil
+	}
+
+	secrets := s.Client.Secrets(s.Namespace)
+	secret, err := secrets.Get(s.Name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get secret %s/%s", s.Namespace, s.Name)
+	}
+	secret.Data = map[string][]byte{assetsKey: archive}
+	if _, err := secrets.Update(secret); err != nil {
+		return errors.Wrapf(err, "failed to update secret %s/%s", s.Namespace, s.Name)
+	}
+	s.saved = digest
+	logrus.Debugf("Saved the asset directory to secret %s/%s", s.Namespace, s.Name)
+	return nil
+}
+
+// Checkpoint saves dir into the Secret every interval until ctx is done,
+// so a pod restarted in the middle of a long phase, e.g. while Terraform
+// provisions the hosts, resumes from the state written so far rather than
+// from the end of the last phase.
+func (s *StateStore) Checkpoint(ctx context.Context, dir string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Save(dir); err != nil {
+				logrus.Warn(err)
+			}
+		}
+	}
+}
+
+// archive returns a gzipped tarball of the regular files in dir, leaving
+// out the user cache directory.
+func archive(dir string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path == filepath.Join(dir, cacheDir) {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		if err == nil {
+			fileaudit.RecordFile(fileaudit.Read, path, "asset directory saved to the hub")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unarchive extracts a tarball written by archive into dir.
+func unarchive(data []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+			return errors.Errorf("refusing to extract %q outside of the asset directory", header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, tr)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		fileaudit.RecordFile(fileaudit.Write, path, "asset directory restored from the hub")
+	}
+}
@@ -0,0 +1,62 @@
+package hub
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive(t *testing.T) {
+	src, err := ioutil.TempDir("", "kni-install-hub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	dst, err := ioutil.TempDir("", "kni-install-hub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "auth"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, ".openshift_install_state.json"), []byte("{}"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "auth", "kubeconfig"), []byte("kubeconfig"), 0600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, ".cache", "kni-install"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, ".cache", "kni-install", "rhcos.qcow2"), []byte("image"), 0644))
+
+	data, err := archive(src)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, unarchive(data, dst))
+
+	state, err := ioutil.ReadFile(filepath.Join(dst, ".openshift_install_state.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(state))
+	info, err := os.Stat(filepath.Join(dst, "auth", "kubeconfig"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	_, err = os.Stat(filepath.Join(dst, ".cache"))
+	assert.True(t, os.IsNotExist(err), "the cache directory was archived")
+}
+
+func TestUnarchiveOutsideDirectory(t *testing.T) {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0600, Size: 1}))
+	_, err := tw.Write([]byte("x"))
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+
+	err = unarchive(buf.Bytes(), "/tmp/kni-install-hub-missing")
+	assert.EqualError(t, err, `refusing to extract "../escape" outside of the asset directory`)
+}
@@ -0,0 +1,67 @@
+package installer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/manifests"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+)
+
+// GenerateAssetsOptions configures GenerateAssets.
+type GenerateAssetsOptions struct {
+	// Dir is the asset directory.
+	Dir string
+
+	// Targets are the assets to generate, e.g. targets.IgnitionConfigs
+	// from pkg/asset/targets.
+	Targets []asset.WritableAsset
+
+	// Lint checks the generated files with manifests.Lint before any of
+	// them are written, e.g. for targets.Manifests.
+	Lint bool
+
+	// Outputs places copies of the generated files outside the asset
+	// directory.
+	Outputs OutputOptions
+}
+
+// GenerateAssets fetches the target assets (and everything they depend on)
+// and writes them to the asset directory.
+func GenerateAssets(ctx context.Context, opts GenerateAssetsOptions) error {
+	ctx, _, logTimings := timed(ctx)
+	defer logTimings()
+
+	assetStore, err := assetstore.NewStore(opts.Dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to create asset store")
+	}
+
+	var files []*asset.File
+	for _, a := range opts.Targets {
+		if err := assetStore.Fetch(ctx, a); err != nil {
+			err = errors.Wrapf(err, "failed to fetch %s", a.Name())
+			if err2 := asset.PersistToFile(a, opts.Dir); err2 != nil {
+				logrus.Error(errors.Wrapf(err2, "failed to write asset (%s) to disk", a.Name()))
+			}
+			return err
+		}
+		files = append(files, a.Files()...)
+	}
+
+	if opts.Lint {
+		if err := manifests.Lint(files); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range opts.Targets {
+		if err := asset.PersistToFile(a, opts.Dir); err != nil {
+			return errors.Wrapf(err, "failed to write asset (%s) to disk", a.Name())
+		}
+	}
+	return opts.Outputs.write(files)
+}
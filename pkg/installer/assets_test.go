@@ -0,0 +1,84 @@
+package installer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+// testManifest is a writable asset generating a single manifest.
+type testManifest struct {
+	data []byte
+	file *asset.File
+}
+
+func (a *testManifest) Dependencies() []asset.Asset {
+	return nil
+}
+
+func (a *testManifest) Generate(context.Context, asset.Parents) error {
+	a.file = &asset.File{Filename: "manifests/test.yaml", Data: a.data}
+	return nil
+}
+
+func (a *testManifest) Name() string {
+	return "Test Manifest"
+}
+
+func (a *testManifest) Files() []*asset.File {
+	if a.file != nil {
+		return []*asset.File{a.file}
+	}
+	return []*asset.File{}
+}
+
+func (a *testManifest) Load(asset.FileFetcher) (bool, error) {
+	return false, nil
+}
+
+func TestGenerateAssetsLint(t *testing.T) {
+	cases := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			data: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n",
+		},
+		{
+			name:          "invalid",
+			data:          "apiVersion: v1\nkind: ConfigMap\n",
+			expectedError: "invalid manifests:\nmanifests/test.yaml: metadata.name is required",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "assets")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer os.RemoveAll(dir)
+
+			err = GenerateAssets(context.Background(), GenerateAssetsOptions{
+				Dir:     dir,
+				Targets: []asset.WritableAsset{&testManifest{data: []byte(tc.data)}},
+				Lint:    true,
+			})
+			_, statErr := os.Stat(filepath.Join(dir, "manifests", "test.yaml"))
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, statErr)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.True(t, os.IsNotExist(statErr), "the invalid manifest was written")
+			}
+		})
+	}
+}
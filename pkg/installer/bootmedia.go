@@ -0,0 +1,182 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/machine"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/rhcos"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	rhcosbuilds "github.com/metalkube/kni-installer/pkg/rhcos"
+	"github.com/metalkube/kni-installer/pkg/rhcos/customize"
+	libvirttfvars "github.com/metalkube/kni-installer/pkg/tfvars/libvirt"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// BootMediaOptions configures CreateBootMedia.
+type BootMediaOptions struct {
+	// Dir is the asset directory.
+	Dir string
+
+	// Host is the name of the host in platform.baremetal.hosts.
+	Host string
+
+	// Image is the RHCOS live ISO or disk image to customize.  It
+	// defaults to the platform's RHCOS image for the architecture of the
+	// host's machine pool.
+	Image string
+}
+
+// CreateBootMedia writes a bootable image for a single host, with the
+// host's Ignition config and first-boot kernel arguments (including its
+// static network configuration) embedded, to boot-media/<host>.iso or
+// boot-media/<host>.qcow2 (.img for raw disk images) in the asset
+// directory, depending on the format of the image.  It returns the path of
+// the image.
+func CreateBootMedia(ctx context.Context, opts BootMediaOptions) (string, error) {
+	store, err := assetstore.NewStore(opts.Dir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	if err := store.Fetch(ctx, installConfig); err != nil {
+		return "", errors.Wrap(err, "failed to fetch install config")
+	}
+	if installConfig.Config.Platform.BareMetal == nil {
+		return "", errors.New("boot media can only be created for the bare metal platform")
+	}
+
+	var host *baremetal.Host
+	for i := range installConfig.Config.Platform.BareMetal.Hosts {
+		if h := &installConfig.Config.Platform.BareMetal.Hosts[i]; h.Name == opts.Host {
+			host = h
+		}
+	}
+	if host == nil {
+		return "", errors.Errorf("no host %q in platform.baremetal.hosts", opts.Host)
+	}
+	if host.Role == baremetal.MasterRole && installConfig.Config.Platform.BareMetal.VirtualControlPlane != nil {
+		return "", errors.Errorf("host %s is a virtual control plane machine, which the installer boots on the provisioning host with the master Ignition config", host.Name)
+	}
+	if host.Unprovisioned {
+		logrus.Infof("Host %s is unprovisioned; booting it from the media adds it to the cluster as a worker", host.Name)
+	}
+
+	if pool := hostPool(installConfig.Config, host); pool != nil && pool.OperatingSystem == types.OperatingSystemRHEL {
+		return "", errors.Errorf("host %s is in compute pool %s, which runs %s; install RHEL on it with rhel-worker-user-data.yaml as its cloud-init user data", host.Name, pool.Name, pool.OperatingSystem)
+	}
+
+	var ignition asset.WritableAsset
+	switch host.Role {
+	case baremetal.MasterRole:
+		ignition = &machine.Master{}
+	default:
+		ignition = &machine.Worker{}
+	}
+	if err := store.Fetch(ctx, ignition); err != nil {
+		return "", errors.Wrapf(err, "failed to fetch %s", ignition.Name())
+	}
+	ignitionData := ignition.Files()[0].Data
+	if host.Role != baremetal.MasterRole {
+		poolIgns := &machine.PoolWorkers{}
+		if err := store.Fetch(ctx, poolIgns); err != nil {
+			return "", errors.Wrapf(err, "failed to fetch %s", poolIgns.Name())
+		}
+		if file := poolIgns.File(baremetal.HostPool(host)); file != nil {
+			ignitionData = file.Data
+		}
+	}
+
+	kernelArgs, err := customize.HostKernelArgs(host)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := bootMediaSource(ctx, store, opts.Image, hostArchitecture(installConfig.Config, host))
+	if err != nil {
+		return "", err
+	}
+	ext, err := customize.Extension(src)
+	if err != nil {
+		return "", err
+	}
+	if ext == ".gz" || ext == ".xz" {
+		return "", errors.Errorf("%s is compressed; decompress it and pass it with --image", src)
+	}
+
+	outDir := filepath.Join(opts.Dir, "boot-media")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create boot-media directory")
+	}
+	dst := filepath.Join(outDir, host.Name+ext)
+
+	logrus.Infof("Creating boot media for %s from %s", host.Name, src)
+	err = customize.Image(ctx, src, dst, customize.Customization{
+		Ignition:   ignitionData,
+		KernelArgs: kernelArgs,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create boot media for %s", host.Name)
+	}
+	return dst, nil
+}
+
+// bootMediaSource returns the local path of the image to customize,
+// downloading the platform's RHCOS image for arch into the cache if no
+// image was given.
+func bootMediaSource(ctx context.Context, store asset.Store, image string, arch types.Architecture) (string, error) {
+	if image == "" {
+		if arch == types.ArchitectureAMD64 {
+			osImage := new(rhcos.Image)
+			if err := store.Fetch(ctx, osImage); err != nil {
+				return "", errors.Wrap(err, "failed to fetch the RHCOS image")
+			}
+			image = string(*osImage)
+		} else {
+			var err error
+			image, err = rhcosbuilds.QEMU(ctx, rhcosbuilds.ArchChannel(rhcosbuilds.DefaultChannel, arch))
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to find the %s RHCOS image", arch)
+			}
+		}
+		cached, err := libvirttfvars.CachedImage(ctx, image)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to download the RHCOS image")
+		}
+		image = cached
+	}
+	return strings.TrimPrefix(image, "file://"), nil
+}
+
+// hostPool returns the machine pool of host, or nil if the install config
+// has no such pool.
+func hostPool(installConfig *types.InstallConfig, host *baremetal.Host) *types.MachinePool {
+	switch name := baremetal.HostPool(host); name {
+	case baremetal.MasterRole:
+		return installConfig.ControlPlane
+	default:
+		for i := range installConfig.Compute {
+			if installConfig.Compute[i].Name == name {
+				return &installConfig.Compute[i]
+			}
+		}
+	}
+	return nil
+}
+
+// hostArchitecture returns the architecture of the machine pool of host.
+func hostArchitecture(installConfig *types.InstallConfig, host *baremetal.Host) types.Architecture {
+	pool := hostPool(installConfig, host)
+	if pool == nil || pool.Architecture == "" {
+		return types.ArchitectureAMD64
+	}
+	return pool.Architecture
+}
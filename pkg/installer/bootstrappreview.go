@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/preview"
+)
+
+// BootstrapPreviewOptions configures PreviewBootstrap.
+type BootstrapPreviewOptions struct {
+	// Dir is the asset directory.
+	Dir string
+
+	// SkipRender only checks the installer's own files, without running
+	// the release image's render steps.
+	SkipRender bool
+}
+
+// PreviewBootstrap lays out the bootstrap machine's /opt/openshift in
+// bootstrap-preview/ in the asset directory, checks the installer's
+// manifests there and, if podman is installed, renders and checks the
+// bootstrap static pod manifests as bootkube.sh does.  It returns the path of the preview.
+func PreviewBootstrap(ctx context.Context, opts BootstrapPreviewOptions) (string, error) {
+	store, err := assetstore.NewStore(opts.Dir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	bootstrapIgnition := &bootstrap.Bootstrap{}
+	if err := store.Fetch(ctx, installConfig); err != nil {
+		return "", errors.Wrap(err, "failed to fetch install config")
+	}
+	if err := store.Fetch(ctx, bootstrapIgnition); err != nil {
+		return "", errors.Wrapf(err, "failed to fetch %s", bootstrapIgnition.Name())
+	}
+
+	dir := filepath.Join(opts.Dir, "bootstrap-preview")
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	fileaudit.Record(fileaudit.Remove, dir, "previous bootstrap preview", nil)
+	if err := preview.Extract(bootstrapIgnition.Config, bootstrapIgnition.Contents, dir); err != nil {
+		return "", errors.Wrap(err, "failed to extract the bootstrap assets")
+	}
+	if err := preview.Verify(dir); err != nil {
+		return dir, err
+	}
+
+	render := !opts.SkipRender
+	if _, err := exec.LookPath(preview.Runtime); render && err != nil {
+		logrus.Warnf("%s is not installed; only checking the installer's manifests, not rendering the bootstrap static pods", preview.Runtime)
+		render = false
+	}
+	if render {
+		pullSecret, err := installConfig.Config.MergedPullSecret()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to merge pull secrets")
+		}
+		err = preview.Render(ctx, dir, preview.Options{
+			ReleaseImage: bootstrap.ReleaseImage(),
+			EtcdCluster:  bootstrap.EtcdCluster(installConfig.Config),
+			PullSecret:   pullSecret,
+		})
+		if err != nil {
+			return dir, err
+		}
+		if err := preview.VerifyRendered(dir); err != nil {
+			return dir, err
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,125 @@
+package installer
+
+import (
+	"encoding/json"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/credentials"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/version"
+)
+
+const (
+	configRecordNamespace = "kube-system"
+	configRecordName      = "kni-install-config"
+
+	redacted = "REDACTED"
+)
+
+// recordConfig stores a redacted copy of the install-config, with the
+// versions of the installer, release image and assets the cluster was
+// installed with, in the kube-system/kni-install-config ConfigMap, so
+// day-2 tooling can recover what the cluster was asked to be.
+func recordConfig(config *rest.Config, directory string) error {
+	data, err := configRecord(directory)
+	if err != nil {
+		return err
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a Kubernetes client")
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: configRecordNamespace,
+			Name:      configRecordName,
+		},
+		Data: data,
+	}
+	configMaps := client.CoreV1().ConfigMaps(configRecordNamespace)
+	_, err = configMaps.Create(configMap)
+	if apierrors.IsAlreadyExists(err) {
+		_, err = configMaps.Update(configMap)
+	}
+	return errors.Wrapf(err, "failed to store %s/%s", configRecordNamespace, configRecordName)
+}
+
+// configRecord returns the data of the kni-install-config ConfigMap for
+// the install in directory.
+func configRecord(directory string) (map[string]string, error) {
+	assetStore, err := assetstore.NewStore(directory)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	found, err := assetStore.LoadFromState(installConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the install-config")
+	}
+	if !found {
+		return nil, errors.New("the install-config is not in the installer state")
+	}
+	installConfigData, err := yaml.Marshal(redactInstallConfig(installConfig.Config))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the install-config")
+	}
+
+	digests, err := assetstore.StateDigests(directory)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to digest the installer state")
+	}
+	digestData, err := json.MarshalIndent(digests, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"install-config":    string(installConfigData),
+		"installer-version": version.Raw,
+		"release-image":     bootstrap.ReleaseImage(),
+		"assets":            string(digestData),
+	}, nil
+}
+
+// redactInstallConfig returns a copy of config without its credentials.
+// References to credentials providers are kept.
+func redactInstallConfig(config *types.InstallConfig) *types.InstallConfig {
+	c := *config
+	c.PullSecret = redact(config.PullSecret)
+	c.AdditionalPullSecrets = nil
+	for _, secret := range config.AdditionalPullSecrets {
+		c.AdditionalPullSecrets = append(c.AdditionalPullSecrets, redact(secret))
+	}
+	if config.Entitlements != nil {
+		entitlements := *config.Entitlements
+		entitlements.Certificate = redacted
+		entitlements.Key = redacted
+		c.Entitlements = &entitlements
+	}
+	if config.TLS != nil && config.TLS.RootCA != nil {
+		tlsConfig := *config.TLS
+		rootCA := *config.TLS.RootCA
+		rootCA.Key = redacted
+		tlsConfig.RootCA = &rootCA
+		c.TLS = &tlsConfig
+	}
+	return &c
+}
+
+func redact(secret string) string {
+	if credentials.IsReference(secret) {
+		return secret
+	}
+	return redacted
+}
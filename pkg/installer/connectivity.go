@@ -0,0 +1,48 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/rhcos"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/verify"
+)
+
+// CheckConnectivity checks that the installer host can reach everything
+// the install in dir needs, e.g. the release registry, the RHCOS image and
+// the libvirt daemon, before anything is provisioned.
+func CheckConnectivity(ctx context.Context, dir string) (*verify.Report, error) {
+	store, err := assetstore.NewStore(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	if err := store.Fetch(ctx, installConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch install config")
+	}
+
+	osImage := func() (string, error) {
+		image := new(rhcos.Image)
+		if err := store.Fetch(ctx, image); err != nil {
+			return "", err
+		}
+		return string(*image), nil
+	}
+	return verify.Run(verify.ConnectivityChecks(ctx, installConfig.Config, bootstrap.ReleaseImage(), osImage)), nil
+}
+
+// connectivityError describes the failed checks of a connectivity report.
+func connectivityError(report *verify.Report) error {
+	failed := report.Failed()
+	messages := make([]string, 0, len(failed))
+	for _, result := range failed {
+		messages = append(messages, fmt.Sprintf("%s: %s", result.Name, result.Message))
+	}
+	return errors.Errorf("the installer host cannot reach everything the install needs:\n%s", strings.Join(messages, "\n"))
+}
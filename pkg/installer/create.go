@@ -0,0 +1,595 @@
+package installer
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	clientwatch "k8s.io/client-go/tools/watch"
+
+	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	targetassets "github.com/metalkube/kni-installer/pkg/asset/targets"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	destroybootstrap "github.com/metalkube/kni-installer/pkg/destroy/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/rollout"
+	"github.com/metalkube/kni-installer/pkg/simulate"
+	"github.com/metalkube/kni-installer/pkg/timing"
+	"github.com/metalkube/kni-installer/pkg/types"
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	cov1helpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+)
+
+// FIXME: baremetal
+// stopAfterBootstrap makes CreateCluster return once the bootstrap cluster
+// has been created, for bare metal testing.
+var stopAfterBootstrap = true
+
+// ClusterPhases returns the names of the phases CreateCluster goes
+// through, in order, which are passed to CreateClusterOptions.OnPhase.
+// Phases may be skipped, e.g. waiting for the console when the install
+// config disables it.
+func ClusterPhases() []string {
+	if stopAfterBootstrap {
+		return []string{"Cluster"}
+	}
+	return []string{"Cluster", "Bootstrap", "Cluster initialization", "Console"}
+}
+
+// CreateClusterOptions configures CreateCluster.
+type CreateClusterOptions struct {
+	// Dir is the asset directory.
+	Dir string
+
+	// OnPhase, if set, is called as each phase of the install starts.
+	OnPhase PhaseFunc
+
+	// SkipConnectivityCheck skips checking that the installer host can
+	// reach everything the install needs before provisioning.
+	SkipConnectivityCheck bool
+
+	// SkipConfigRecord skips storing a redacted copy of the install-config
+	// in the cluster once it is installed.
+	SkipConfigRecord bool
+
+	// GatherBootstrap configures harvesting the bootstrap machine's
+	// artifacts before it is destroyed.
+	GatherBootstrap GatherBootstrapOptions
+
+	// Outputs places copies of the generated files outside the asset
+	// directory.
+	Outputs OutputOptions
+}
+
+// ClusterInfo describes a successfully installed cluster.
+type ClusterInfo struct {
+	// Kubeconfig is the path to the admin kubeconfig.
+	Kubeconfig string
+
+	// ConsoleURL is the URL of the OpenShift web-console.  It is empty
+	// if the install stopped after creating the bootstrap cluster.
+	ConsoleURL string
+}
+
+// CreateCluster generates the cluster assets, launching the cluster, and
+// waits for the cluster to finish installing.
+func CreateCluster(ctx context.Context, opts CreateClusterOptions) (*ClusterInfo, error) {
+	ctx, recorder, logTimings := timed(ctx)
+	defer logTimings()
+
+	if !opts.SkipConnectivityCheck && !simulate.FromContext(ctx) {
+		report, err := CheckConnectivity(ctx, opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if !report.Passed {
+			return nil, connectivityError(report)
+		}
+	}
+
+	scale, err := timeoutScale(ctx, opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	done := opts.OnPhase.start("Cluster")
+	if err := GenerateAssets(ctx, GenerateAssetsOptions{Dir: opts.Dir, Targets: targetassets.Cluster, Outputs: opts.Outputs}); err != nil {
+		return nil, err
+	}
+	done("")
+	defer func() {
+		if err := recordTimings(opts.Dir, recorder); err != nil {
+			logrus.Warnf("Failed to record the install's timings: %v", err)
+		}
+	}()
+
+	info := &ClusterInfo{Kubeconfig: filepath.Join(opts.Dir, "auth", "kubeconfig")}
+	metadata, err := cluster.LoadMetadata(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Fake != nil {
+		logrus.Info("The fake platform provisions nothing, so there is no cluster to wait for")
+		return info, nil
+	}
+	if metadata.Simulated {
+		logrus.Info("The simulated install provisions nothing, so there is no cluster to wait for")
+		return info, nil
+	}
+
+	config, err := installerRESTConfig(info.Kubeconfig, opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if stopAfterBootstrap {
+		logrus.Warn("FIXME! Exiting after bootstrap cluster create for baremetal testing")
+		if opts.GatherBootstrap.Enabled {
+			logrus.Warn("The bootstrap machine is kept, so its artifacts are gathered by kni-install destroy bootstrap --gather-bootstrap")
+		}
+		logrus.Info("Finish the install with kni-install destroy bootstrap and kni-install wait-for install-complete")
+		return info, nil
+	}
+
+	done = opts.OnPhase.start("Bootstrap")
+	stop := timing.Start(ctx, timing.Wait, "bootstrap")
+	err = destroyBootstrap(ctx, config, opts.Dir, scale, opts.GatherBootstrap)
+	stop()
+	if err != nil {
+		return nil, err
+	}
+	done("")
+
+	if err := completeInstall(ctx, config, opts.Dir, opts.OnPhase, scale, opts.SkipConfigRecord, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// WaitForInstallCompleteOptions configures WaitForInstallComplete.
+type WaitForInstallCompleteOptions struct {
+	// Dir is the asset directory.
+	Dir string
+
+	// OnPhase, if set, is called as each phase of the install starts.
+	OnPhase PhaseFunc
+
+	// SkipConfigRecord skips storing a redacted copy of the install-config
+	// in the cluster once it is installed.
+	SkipConfigRecord bool
+}
+
+// WaitForInstallComplete waits for a cluster whose bootstrap machine has
+// been destroyed to finish installing, going through the phases which
+// CreateCluster does not reach when it stops after the bootstrap cluster.
+func WaitForInstallComplete(ctx context.Context, opts WaitForInstallCompleteOptions) (*ClusterInfo, error) {
+	ctx, _, logTimings := timed(ctx)
+	defer logTimings()
+
+	scale, err := timeoutScale(ctx, opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ClusterInfo{Kubeconfig: filepath.Join(opts.Dir, "auth", "kubeconfig")}
+	metadata, err := cluster.LoadMetadata(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Fake != nil || metadata.Simulated {
+		logrus.Info("The install provisioned nothing, so there is no cluster to wait for")
+		return info, nil
+	}
+
+	config, err := installerRESTConfig(info.Kubeconfig, opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := completeInstall(ctx, config, opts.Dir, opts.OnPhase, scale, opts.SkipConfigRecord, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// completeInstall waits for the cluster to initialize once the bootstrap
+// machine is gone, rolls out the compute pools in waves, waits for the
+// console unless it is disabled, and records the install-config in the
+// cluster.  It sets the console URL of info.
+func completeInstall(ctx context.Context, config *rest.Config, dir string, onPhase PhaseFunc, scale time.Duration, skipConfigRecord bool, info *ClusterInfo) error {
+	done := onPhase.start("Cluster initialization")
+	stop := timing.Start(ctx, timing.Wait, "cluster initialization")
+	err := waitForInitializedCluster(ctx, config, scale)
+	stop()
+	if err != nil {
+		return err
+	}
+	done("")
+
+	if err := rolloutWorkers(ctx, config, onPhase, scale); err != nil {
+		return err
+	}
+
+	consoleEnabled, err := capabilityEnabled(ctx, dir, types.CapabilityConsole)
+	if err != nil {
+		return err
+	}
+	if consoleEnabled {
+		done = onPhase.start("Console")
+		stop = timing.Start(ctx, timing.Wait, "console")
+		info.ConsoleURL, err = waitForConsole(ctx, config, dir, scale)
+		stop()
+		if err != nil {
+			return err
+		}
+	} else {
+		logrus.Info("The console is disabled, so there is no console to wait for")
+	}
+	if err := addRouterCAToClusterCA(config, dir); err != nil {
+		return err
+	}
+	if !skipConfigRecord {
+		if err := recordConfig(config, dir); err != nil {
+			logrus.Warnf("Failed to record the install-config in the cluster: %v", err)
+		}
+	}
+	if consoleEnabled {
+		done(fmt.Sprintf("Install complete! Access the OpenShift web-console here: %s", info.ConsoleURL))
+	}
+	return nil
+}
+
+// capabilityEnabled returns true unless the install-config disables
+// capability.
+func capabilityEnabled(ctx context.Context, directory string, capability types.Capability) (bool, error) {
+	assetStore, err := assetstore.NewStore(directory)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	if err := assetStore.Fetch(ctx, installConfig); err != nil {
+		return false, errors.Wrap(err, "failed to fetch install config")
+	}
+	return installConfig.Config.CapabilityEnabled(capability), nil
+}
+
+// rolloutWorkers scales the compute pools which are rolled out in waves up
+// to their replicas.
+func rolloutWorkers(ctx context.Context, config *rest.Config, onPhase PhaseFunc, scale time.Duration) error {
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a dynamic client")
+	}
+	pools, err := rollout.List(client)
+	if err != nil {
+		return err
+	}
+	if len(pools) == 0 {
+		return nil
+	}
+
+	done := onPhase.start("Worker rollout")
+	stop := timing.Start(ctx, timing.Wait, "worker rollout")
+	defer stop()
+	waveTimeout := scale * 30 * time.Minute
+	for _, pool := range pools {
+		logrus.Infof("Rolling out compute pool %s, waiting up to %v for each wave...", pool.Name, waveTimeout)
+		if err := pool.Run(ctx, client, waveTimeout); err != nil {
+			return errors.Wrapf(err, "failed to roll out compute pool %s", pool.Name)
+		}
+	}
+	done("")
+	return nil
+}
+
+// installerRESTConfig loads the admin kubeconfig for the installer's own
+// use.  If the kubeconfig authenticates with an exec credential plugin,
+// which usually cannot work until the cluster has been integrated with the
+// identity provider, the admin client certificate from the installer's
+// state is used instead.
+func installerRESTConfig(kubeconfig string, directory string) (*rest.Config, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading kubeconfig")
+	}
+	fileaudit.RecordFile(fileaudit.Read, kubeconfig, "admin kubeconfig")
+	if config.ExecProvider == nil {
+		return config, nil
+	}
+
+	assetStore, err := assetstore.NewStore(directory)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	clientCertKey := &tls.AdminKubeConfigClientCertKey{}
+	found, err := assetStore.LoadFromState(clientCertKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the admin client certificate")
+	}
+	if !found {
+		return nil, errors.New("the admin kubeconfig uses a credential plugin, but the admin client certificate is not in the installer state")
+	}
+
+	logrus.Debugf("Using the admin client certificate instead of the %q credential plugin", config.ExecProvider.Command)
+	config.ExecProvider = nil
+	config.TLSClientConfig.CertData = clientCertKey.Cert()
+	config.TLSClientConfig.KeyData = clientCertKey.Key()
+	return config, nil
+}
+
+// addRouterCAToClusterCA adds router CA to cluster CA in kubeconfig
+func addRouterCAToClusterCA(config *rest.Config, directory string) (err error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a Kubernetes client")
+	}
+
+	// Configmap may not exist. log and accept not-found errors with configmap.
+	caConfigMap, err := client.CoreV1().ConfigMaps("openshift-config-managed").Get("router-ca", metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logrus.Infof("router-ca resource not found in cluster, perhaps you are not using default router CA")
+			return nil
+		}
+		return errors.Wrap(err, "fetching router-ca configmap from openshift-config-managed namespace")
+	}
+
+	routerCrtBytes := []byte(caConfigMap.Data["ca-bundle.crt"])
+	kubeconfig := filepath.Join(directory, "auth", "kubeconfig")
+	kconfig, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "loading kubeconfig")
+	}
+	fileaudit.RecordFile(fileaudit.Read, kubeconfig, "admin kubeconfig")
+
+	if kconfig == nil || len(kconfig.Clusters) == 0 {
+		return errors.New("kubeconfig is missing expected data")
+	}
+
+	for _, c := range kconfig.Clusters {
+		clusterCABytes := c.CertificateAuthorityData
+		if len(clusterCABytes) == 0 {
+			return errors.New("kubeconfig CertificateAuthorityData not found")
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(clusterCABytes) {
+			return errors.New("cluster CA found in kubeconfig not valid PEM format")
+		}
+		if !certPool.AppendCertsFromPEM(routerCrtBytes) {
+			return errors.New("ca-bundle.crt from router-ca configmap not valid PEM format")
+		}
+
+		newCA := append(routerCrtBytes, clusterCABytes...)
+		c.CertificateAuthorityData = newCA
+	}
+	if err := clientcmd.WriteToFile(*kconfig, kubeconfig); err != nil {
+		return errors.Wrap(err, "writing kubeconfig")
+	}
+	return nil
+}
+
+// FIXME: pulling the kubeconfig and metadata out of the root
+// directory is a bit cludgy when we already have them in memory.
+func destroyBootstrap(ctx context.Context, config *rest.Config, directory string, scale time.Duration, gather GatherBootstrapOptions) (err error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a Kubernetes client")
+	}
+
+	discovery := client.Discovery()
+
+	apiTimeout := scale * 30 * time.Minute
+	logrus.Infof("Waiting up to %v for the Kubernetes API...", apiTimeout)
+	apiContext, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	// Poll quickly so we notice changes, but only log when the response
+	// changes (because that's interesting) or when we've seen 15 of the
+	// same errors in a row (to show we're still alive).
+	logDownsample := 15
+	silenceRemaining := logDownsample
+	previousErrorSuffix := ""
+	wait.Until(func() {
+		version, err := discovery.ServerVersion()
+		if err == nil {
+			logrus.Infof("API %s up", version)
+			cancel()
+		} else {
+			silenceRemaining--
+			chunks := strings.Split(err.Error(), ":")
+			errorSuffix := chunks[len(chunks)-1]
+			if previousErrorSuffix != errorSuffix {
+				logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
+				previousErrorSuffix = errorSuffix
+				silenceRemaining = logDownsample
+			} else if silenceRemaining == 0 {
+				logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
+				silenceRemaining = logDownsample
+			}
+		}
+	}, 2*time.Second, apiContext.Done())
+	err = apiContext.Err()
+	if err != nil && err != context.Canceled {
+		return errors.Wrap(err, "waiting for Kubernetes API")
+	}
+
+	events := client.CoreV1().Events("kube-system")
+
+	eventTimeout := scale * 30 * time.Minute
+	logrus.Infof("Waiting up to %v for the bootstrap-complete event...", eventTimeout)
+	eventContext, cancel := context.WithTimeout(ctx, eventTimeout)
+	defer cancel()
+	_, err = Until(
+		eventContext,
+		"",
+		func(sinceResourceVersion string) (watch.Interface, error) {
+			for {
+				watcher, err := events.Watch(metav1.ListOptions{
+					ResourceVersion: sinceResourceVersion,
+				})
+				if err == nil {
+					return watcher, nil
+				}
+				select {
+				case <-eventContext.Done():
+					return watcher, err
+				default:
+					logrus.Warningf("Failed to connect events watcher: %s", err)
+					time.Sleep(2 * time.Second)
+				}
+			}
+		},
+		func(watchEvent watch.Event) (bool, error) {
+			event, ok := watchEvent.Object.(*corev1.Event)
+			if !ok {
+				return false, nil
+			}
+
+			if watchEvent.Type == watch.Error {
+				logrus.Debugf("error %s: %s", event.Name, event.Message)
+				return false, nil
+			}
+
+			if watchEvent.Type != watch.Added {
+				return false, nil
+			}
+
+			logrus.Debugf("added %s: %s", event.Name, event.Message)
+			return event.Name == "bootstrap-complete", nil
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "waiting for bootstrap-complete")
+	}
+
+	gatherBootstrap(ctx, directory, gather)
+
+	logrus.Info("Destroying the bootstrap resources...")
+	return destroybootstrap.Destroy(ctx, directory)
+}
+
+// waitForInitializedCluster watches the ClusterVersion waiting for confirmation
+// that the cluster has been initialized.
+func waitForInitializedCluster(ctx context.Context, config *rest.Config, scale time.Duration) error {
+	timeout := scale * 30 * time.Minute
+	logrus.Infof("Waiting up to %v for the cluster to initialize...", timeout)
+	cc, err := configclient.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a config client")
+	}
+	clusterVersionContext, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var clusterVersion *configv1.ClusterVersion
+
+	_, err = clientwatch.UntilWithSync(
+		clusterVersionContext,
+		cache.NewListWatchFromClient(cc.ConfigV1().RESTClient(), "clusterversions", "", fields.OneTermEqualSelector("metadata.name", "version")),
+		&configv1.ClusterVersion{},
+		nil,
+		func(event watch.Event) (bool, error) {
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				cv, ok := event.Object.(*configv1.ClusterVersion)
+				if !ok {
+					logrus.Warnf("Expected a ClusterVersion object but got a %q object instead", event.Object.GetObjectKind().GroupVersionKind())
+					return false, nil
+				}
+				clusterVersion = cv
+				if cov1helpers.IsStatusConditionTrue(cv.Status.Conditions, configv1.OperatorAvailable) {
+					logrus.Debug("Cluster is initialized")
+					return true, nil
+				}
+				if cov1helpers.IsStatusConditionTrue(cv.Status.Conditions, configv1.OperatorFailing) {
+					logrus.Debugf("Still waiting for the cluster to initialize: %v",
+						cov1helpers.FindStatusCondition(cv.Status.Conditions, configv1.OperatorFailing).Message)
+					return false, nil
+				}
+			}
+			logrus.Debug("Still waiting for the cluster to initialize...")
+			return false, nil
+		},
+	)
+
+	// If we timed out and the CVO failed, print out the failure message
+	if err != nil && clusterVersion != nil {
+		if cov1helpers.IsStatusConditionTrue(clusterVersion.Status.Conditions, configv1.OperatorFailing) {
+			err = errors.New(cov1helpers.FindStatusCondition(clusterVersion.Status.Conditions, configv1.OperatorFailing).Message)
+		}
+	}
+
+	return errors.Wrap(err, "failed to initialize the cluster")
+}
+
+// waitForConsole returns the console URL from the route 'console' in namespace openshift-console
+func waitForConsole(ctx context.Context, config *rest.Config, directory string, scale time.Duration) (string, error) {
+	url := ""
+	// Need to keep these updated if they change
+	consoleNamespace := "openshift-console"
+	consoleRouteName := "console"
+	rc, err := routeclient.NewForConfig(config)
+	if err != nil {
+		return "", errors.Wrap(err, "creating a route client")
+	}
+
+	consoleRouteTimeout := scale * 10 * time.Minute
+	logrus.Infof("Waiting up to %v for the openshift-console route to be created...", consoleRouteTimeout)
+	consoleRouteContext, cancel := context.WithTimeout(ctx, consoleRouteTimeout)
+	defer cancel()
+	// Poll quickly but only log when the response
+	// when we've seen 15 of the same errors or output of
+	// no route in a row (to show we're still alive).
+	logDownsample := 15
+	silenceRemaining := logDownsample
+	wait.Until(func() {
+		consoleRoutes, err := rc.RouteV1().Routes(consoleNamespace).List(metav1.ListOptions{})
+		if err == nil && len(consoleRoutes.Items) > 0 {
+			for _, route := range consoleRoutes.Items {
+				logrus.Debugf("Route found in openshift-console namespace: %s", route.Name)
+				if route.Name == consoleRouteName {
+					url = fmt.Sprintf("https://%s", route.Spec.Host)
+				}
+			}
+			logrus.Debug("OpenShift console route is created")
+			cancel()
+		} else if err != nil {
+			silenceRemaining--
+			if silenceRemaining == 0 {
+				logrus.Debugf("Still waiting for the console route: %v", err)
+				silenceRemaining = logDownsample
+			}
+		} else if len(consoleRoutes.Items) == 0 {
+			silenceRemaining--
+			if silenceRemaining == 0 {
+				logrus.Debug("Still waiting for the console route...")
+				silenceRemaining = logDownsample
+			}
+		}
+	}, 2*time.Second, consoleRouteContext.Done())
+	err = consoleRouteContext.Err()
+	if err != nil && err != context.Canceled {
+		return url, errors.Wrap(err, "waiting for openshift-console URL")
+	}
+	if url == "" {
+		return url, errors.New("could not get openshift-console URL")
+	}
+	return url, nil
+}
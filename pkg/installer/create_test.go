@@ -0,0 +1,158 @@
+package installer
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/data"
+	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/simulate"
+	"github.com/metalkube/kni-installer/pkg/terraform"
+)
+
+const fakeInstallConfig = `apiVersion: v1beta4
+metadata:
+  name: test-cluster
+baseDomain: test-domain
+platform:
+  fake: {}
+pullSecret: '{"auths":{"quay.io":{"auth":"c3VwZXItc2VjcmV0Cg=="}}}'
+`
+
+func TestCreateClusterFake(t *testing.T) {
+	data.Assets = http.Dir("../../data/data")
+
+	dir, err := ioutil.TempDir("", "kni-install-")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "install-config.yaml"), []byte(fakeInstallConfig), 0600)) {
+		return
+	}
+
+	ctx := context.Background()
+	info, err := CreateCluster(ctx, CreateClusterOptions{Dir: dir})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, filepath.Join(dir, "auth", "kubeconfig"), info.Kubeconfig)
+
+	for _, name := range []string{
+		"auth/kubeadmin-password",
+		"auth/kubeconfig",
+		"metadata.json",
+		"terraform.fake.auto.tfvars",
+		"terraform.tfstate",
+		"terraform.tfvars",
+		"tls/certificate-audit.jsonl",
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.NoError(t, err, name)
+	}
+
+	metadata, err := cluster.LoadMetadata(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NotNil(t, metadata.Fake) {
+		return
+	}
+	if assert.NotNil(t, metadata.Timings, "the install's timings were not recorded") {
+		stages := map[string]bool{}
+		for _, stage := range metadata.Timings.Stages {
+			stages[stage.Group+"/"+stage.Name] = true
+		}
+		assert.True(t, stages["assets/tls"], "no time was recorded for the tls assets")
+		assert.True(t, stages["assets/cluster"], "no time was recorded for the cluster assets")
+	}
+	live, ok := terraform.Memory.Cluster(metadata.Fake.ClusterID)
+	if !assert.True(t, ok, "the cluster was not applied") {
+		return
+	}
+	assert.Equal(t, "test-cluster.test-domain", live.Variables["cluster_domain"])
+	assert.Len(t, live.Variables["fake_master_names"], 3)
+	assert.Contains(t, live.Resources, "module.bootstrap.fake_instance.bootstrap")
+
+	record, err := configRecord(dir)
+	if assert.NoError(t, err) {
+		assert.Contains(t, record["install-config"], "pullSecret: REDACTED")
+		assert.NotContains(t, record["install-config"], "c3VwZXItc2VjcmV0Cg==")
+		assert.Contains(t, record["assets"], `"*installconfig.InstallConfig"`)
+	}
+
+	if !assert.NoError(t, DestroyBootstrap(ctx, dir, GatherBootstrapOptions{Enabled: true})) {
+		return
+	}
+	assert.Equal(t, []string{"fake_instance.master.0", "fake_instance.master.1", "fake_instance.master.2"}, live.Resources)
+
+	if !assert.NoError(t, DestroyCluster(ctx, DestroyClusterOptions{Dir: dir})) {
+		return
+	}
+	_, ok = terraform.Memory.Cluster(metadata.Fake.ClusterID)
+	assert.False(t, ok, "the cluster was not destroyed")
+}
+
+const baremetalInstallConfig = `apiVersion: v1beta4
+metadata:
+  name: test-cluster
+baseDomain: test-domain
+networking:
+  machineCIDR: 192.168.111.0/24
+platform:
+  baremetal:
+    URI: qemu+ssh://root@provisioner.example.com/system
+    apiVIP: 192.168.111.5
+    ingressVIP: 192.168.111.4
+pullSecret: '{"auths":{"quay.io":{"auth":"c3VwZXItc2VjcmV0Cg=="}}}'
+`
+
+func TestCreateClusterSimulated(t *testing.T) {
+	data.Assets = http.Dir("../../data/data")
+
+	dir, err := ioutil.TempDir("", "kni-install-")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	if !assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "install-config.yaml"), []byte(baremetalInstallConfig), 0600)) {
+		return
+	}
+
+	ctx := context.Background()
+	if _, err := CreateCluster(simulate.NewContext(ctx), CreateClusterOptions{Dir: dir}); !assert.NoError(t, err) {
+		return
+	}
+
+	metadata, err := cluster.LoadMetadata(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, metadata.Simulated)
+	if !assert.NotNil(t, metadata.BareMetal) {
+		return
+	}
+	live, ok := terraform.Memory.Cluster(metadata.InfraID)
+	if !assert.True(t, ok, "the cluster was not applied") {
+		return
+	}
+	assert.Equal(t, simulate.OSImage, live.Variables["os_image"])
+
+	// A simulated cluster is destroyed on the in-memory backend without
+	// --simulate.
+	if !assert.NoError(t, DestroyBootstrap(ctx, dir, GatherBootstrapOptions{Enabled: true})) {
+		return
+	}
+	assert.NotContains(t, live.Resources, "module.bootstrap.fake_instance.bootstrap")
+	if !assert.NoError(t, DestroyCluster(ctx, DestroyClusterOptions{Dir: dir})) {
+		return
+	}
+	_, ok = terraform.Memory.Cluster(metadata.InfraID)
+	assert.False(t, ok, "the cluster was not destroyed")
+}
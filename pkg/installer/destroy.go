@@ -0,0 +1,233 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	targetassets "github.com/metalkube/kni-installer/pkg/asset/targets"
+	"github.com/metalkube/kni-installer/pkg/destroy"
+	_ "github.com/metalkube/kni-installer/pkg/destroy/aws"
+	_ "github.com/metalkube/kni-installer/pkg/destroy/baremetal"
+	destroybootstrap "github.com/metalkube/kni-installer/pkg/destroy/bootstrap"
+	_ "github.com/metalkube/kni-installer/pkg/destroy/fake"
+	_ "github.com/metalkube/kni-installer/pkg/destroy/libvirt"
+	_ "github.com/metalkube/kni-installer/pkg/destroy/openstack"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/gather"
+	"github.com/metalkube/kni-installer/pkg/terraform/state"
+	"github.com/metalkube/kni-installer/pkg/timing"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// DestroyClusterOptions configures DestroyCluster.
+type DestroyClusterOptions struct {
+	// Dir is the asset directory of the cluster, containing its
+	// metadata.json.
+	Dir string
+
+	// Filter selects which of the cluster's resources are destroyed.
+	Filter destroy.Filter
+
+	// Timeout bounds how long deleting a single resource may take.  Zero
+	// means no limit.
+	Timeout time.Duration
+
+	// Force skips resources which cannot be reached or deleted, logging
+	// warnings instead of failing.
+	Force bool
+}
+
+func (opts DestroyClusterOptions) destroyOptions() destroy.Options {
+	return destroy.Options{
+		Filter:  opts.Filter,
+		Timeout: opts.Timeout,
+		Force:   opts.Force,
+	}
+}
+
+// DestroyCluster destroys the cluster and removes its assets and state
+// from the asset directory, and its Terraform state wherever it is kept,
+// holding that state's lock.  When a filter is set only the selected
+// resources are destroyed, and the assets and state are kept.
+func DestroyCluster(ctx context.Context, opts DestroyClusterOptions) error {
+	destroyer, err := destroy.New(logrus.StandardLogger(), opts.Dir, opts.destroyOptions())
+	if err != nil {
+		return errors.Wrap(err, "Failed while preparing to destroy cluster")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	metadata, err := cluster.LoadMetadata(opts.Dir)
+	if err != nil {
+		return err
+	}
+	tfstate, err := state.ForCluster(opts.Dir, metadata.TerraformState)
+	if err != nil {
+		return err
+	}
+	return state.WithLock(ctx, tfstate, "destroy cluster", func() error {
+		return destroyCluster(ctx, opts, destroyer, tfstate)
+	})
+}
+
+func destroyCluster(ctx context.Context, opts DestroyClusterOptions, destroyer destroy.Destroyer, tfstate state.Store) error {
+	ctx, _, logTimings := timed(ctx)
+	defer logTimings()
+	stop := timing.Start(ctx, timing.Destroy, "resources")
+	err := destroyer.Run()
+	stop()
+	if err != nil {
+		return errors.Wrap(err, "Failed to destroy cluster")
+	}
+	if !opts.Filter.IsZero() {
+		return nil
+	}
+
+	if err := tfstate.Delete(ctx); err != nil {
+		return errors.Wrap(err, "failed to remove the Terraform state")
+	}
+
+	store, err := assetstore.NewStore(opts.Dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to create asset store")
+	}
+	for _, asset := range targetassets.Cluster {
+		if err := store.Destroy(asset); err != nil {
+			return errors.Wrapf(err, "failed to destroy asset %q", asset.Name())
+		}
+	}
+	// delete the state file as well
+	err = store.DestroyState()
+	if err != nil {
+		return errors.Wrap(err, "failed to remove state file")
+	}
+
+	return nil
+}
+
+// ListClusterResources returns the resources DestroyCluster would remove,
+// based on the cluster's metadata and live queries of its platform,
+// without removing anything.
+func ListClusterResources(ctx context.Context, opts DestroyClusterOptions) ([]destroy.Resource, error) {
+	metadata, err := cluster.LoadMetadata(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	destroyer, err := destroy.New(logrus.StandardLogger(), opts.Dir, opts.destroyOptions())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed while preparing to destroy cluster")
+	}
+	lister, ok := destroyer.(destroy.Lister)
+	if !ok {
+		return nil, errors.Errorf("listing resources is not supported on %s", metadata.Platform())
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return lister.List()
+}
+
+// GatherBootstrapOptions configures harvesting the bootstrap machine's
+// artifacts before the bootstrap resources are destroyed.
+type GatherBootstrapOptions struct {
+	// Enabled harvests the bootstrap machine's journal, rendered assets
+	// and etcd discovery data into the asset directory.
+	Enabled bool
+
+	// Host is the address of the bootstrap machine.  If empty, it is
+	// taken from the Terraform variables or state.
+	Host string
+}
+
+// DestroyBootstrap destroys the bootstrap resources of the cluster in the
+// asset directory dir, first harvesting the bootstrap machine's artifacts
+// if gather is enabled.
+func DestroyBootstrap(ctx context.Context, dir string, gather GatherBootstrapOptions) error {
+	ctx, _, logTimings := timed(ctx)
+	defer logTimings()
+	gatherBootstrap(ctx, dir, gather)
+	return destroybootstrap.Destroy(ctx, dir)
+}
+
+// gatherBootstrap harvests the bootstrap machine's artifacts into the
+// asset directory dir if opts is enabled.  Failing to does not stop the
+// bootstrap resources being destroyed, so it only warns.
+func gatherBootstrap(ctx context.Context, dir string, opts GatherBootstrapOptions) {
+	if !opts.Enabled {
+		return
+	}
+	metadata, err := cluster.LoadMetadata(dir)
+	if err != nil {
+		logrus.Warnf("Failed to gather the bootstrap machine's artifacts: %v", err)
+		return
+	}
+	if metadata.Fake != nil {
+		logrus.Info("The fake platform has no bootstrap machine to gather artifacts from")
+		return
+	}
+	if metadata.Simulated {
+		logrus.Info("The simulated install has no bootstrap machine to gather artifacts from")
+		return
+	}
+
+	host := opts.Host
+	if host == "" {
+		host, err = bootstrapHost(ctx, dir, metadata)
+		if err != nil {
+			logrus.Warnf("Failed to find the bootstrap machine's address: %v", err)
+			return
+		}
+		if host == "" {
+			logrus.Warn("Not gathering the bootstrap machine's artifacts: its address is unknown, set it with --bootstrap-host")
+			return
+		}
+	}
+
+	var clusterDomain string
+	assetStore, err := assetstore.NewStore(dir)
+	if err == nil {
+		installConfig := &installconfig.InstallConfig{}
+		if err = assetStore.Fetch(ctx, installConfig); err == nil {
+			clusterDomain = installConfig.Config.ClusterDomain()
+		}
+	}
+	if err != nil {
+		logrus.Debugf("Not recording the etcd discovery records: %v", err)
+	}
+
+	logrus.Infof("Gathering the artifacts of the bootstrap machine %s...", host)
+	artifactsDir, err := gather.Bootstrap(ctx, dir, host, clusterDomain)
+	if err != nil {
+		logrus.Warnf("Failed to gather the bootstrap machine's artifacts: %v", err)
+		return
+	}
+	logrus.Infof("The bootstrap machine's artifacts are in %s", artifactsDir)
+}
+
+// bootstrapHost returns the address of the bootstrap machine from the
+// Terraform variables in the asset directory dir and the cluster's
+// Terraform state, or "" if it is not known.
+func bootstrapHost(ctx context.Context, dir string, metadata *types.ClusterMetadata) (string, error) {
+	tfvars, err := fileaudit.ReadFile(filepath.Join(dir, fmt.Sprintf(cluster.TfPlatformVarsFileName, metadata.Platform())), "Terraform input")
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	store, err := state.ForCluster(dir, metadata.TerraformState)
+	if err != nil {
+		return "", err
+	}
+	tfstate, err := store.Read(ctx)
+	if err != nil {
+		return "", err
+	}
+	return gather.BootstrapHost(tfvars, tfstate)
+}
@@ -0,0 +1,42 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/encrypt"
+)
+
+// stateFileName is the installer state in the asset directory.
+const stateFileName = ".openshift_install_state.json"
+
+// EncryptAssets encrypts the secret-bearing files of the asset directory in
+// place, for the recipients of the install config's secretsEncryption, and
+// returns their paths relative to the directory.  The installer cannot use
+// the directory again until the files are decrypted.
+func EncryptAssets(ctx context.Context, dir string) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(dir, stateFileName)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("no installer state in %s; it is already encrypted, or no assets were created", dir)
+		}
+		return nil, err
+	}
+
+	store, err := assetstore.NewStore(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	if err := store.Fetch(ctx, installConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch install config")
+	}
+	if installConfig.Config.SecretsEncryption == nil {
+		return nil, errors.New("the install config does not set secretsEncryption")
+	}
+	return encrypt.New(installConfig.Config.SecretsEncryption).Dir(ctx, dir)
+}
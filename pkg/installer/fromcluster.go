@@ -0,0 +1,320 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/aws"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
+	"github.com/metalkube/kni-installer/pkg/types/none"
+	"github.com/metalkube/kni-installer/pkg/types/openstack"
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+)
+
+const (
+	// clusterConfigNamespace and clusterConfigName locate the
+	// install-config which the cluster's operators read.
+	clusterConfigNamespace = "kube-system"
+	clusterConfigName      = "cluster-config-v1"
+
+	bareMetalHostNamespace = "openshift-machine-api"
+)
+
+var bareMetalHosts = schema.GroupVersionResource{Group: "metal3.io", Version: "v1alpha1", Resource: "baremetalhosts"}
+
+// InstallConfigFromClusterOptions configures InstallConfigFromCluster.
+type InstallConfigFromClusterOptions struct {
+	// Dir is the asset directory to write install-config.yaml to.
+	Dir string
+
+	// Kubeconfig is the path to an admin kubeconfig for the running
+	// cluster.
+	Kubeconfig string
+}
+
+// InstallConfigFromCluster reconstructs a best-effort install-config from
+// the running cluster, for rebuilds and disaster recovery, and writes it
+// to install-config.yaml in the asset directory.  It starts from the
+// install-config stored in the cluster, if there is one, and otherwise
+// from the cluster's infrastructure, DNS and network configuration.  The
+// pull secret is read from the cluster and bare metal hosts from its
+// BareMetalHosts.  Everything which could not be recovered is warned
+// about, and the install-config must be reviewed before it is used.
+func InstallConfigFromCluster(ctx context.Context, opts InstallConfigFromClusterOptions) (string, error) {
+	path := filepath.Join(opts.Dir, "install-config.yaml")
+	if _, err := os.Stat(path); err == nil {
+		return "", errors.Errorf("%s already exists", path)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", opts.Kubeconfig)
+	if err != nil {
+		return "", errors.Wrap(err, "loading kubeconfig")
+	}
+	fileaudit.RecordFile(fileaudit.Read, opts.Kubeconfig, "admin kubeconfig")
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", errors.Wrap(err, "creating a Kubernetes client")
+	}
+
+	installConfig, err := storedInstallConfig(client)
+	if err != nil {
+		return "", err
+	}
+	if installConfig == nil {
+		cc, err := configclient.NewForConfig(config)
+		if err != nil {
+			return "", errors.Wrap(err, "creating a config client")
+		}
+		infra, err := cc.ConfigV1().Infrastructures().Get("cluster", metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the cluster's infrastructure")
+		}
+		dns, err := cc.ConfigV1().DNSs().Get("cluster", metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the cluster's DNS configuration")
+		}
+		network, err := cc.ConfigV1().Networks().Get("cluster", metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get the cluster's network configuration")
+		}
+		installConfig, err = clusterInstallConfig(infra, dns, network)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if installConfig.PullSecret == "" || installConfig.PullSecret == redacted {
+		secret, err := client.CoreV1().Secrets("openshift-config").Get("pull-secret", metav1.GetOptions{})
+		if err == nil {
+			installConfig.PullSecret = string(secret.Data[".dockerconfigjson"])
+		} else {
+			logrus.Warnf("Failed to read the pull secret from the cluster, set pullSecret: %v", err)
+			installConfig.PullSecret = ""
+		}
+	}
+	var additional []string
+	for _, secret := range installConfig.AdditionalPullSecrets {
+		if secret == redacted {
+			logrus.Warn("An additional pull secret is redacted in the cluster, add it to additionalPullSecrets")
+			continue
+		}
+		additional = append(additional, secret)
+	}
+	installConfig.AdditionalPullSecrets = additional
+	if e := installConfig.Entitlements; e != nil && (e.Certificate == redacted || e.Key == redacted) {
+		logrus.Warn("The entitlement certificate and key are redacted in the cluster, set entitlements")
+	}
+	if t := installConfig.TLS; t != nil && t.RootCA != nil && (t.RootCA.Key == "" || t.RootCA.Key == redacted) {
+		logrus.Warn("The root CA key is not recorded in the cluster, set tls.rootCA.key")
+	}
+	if installConfig.SSHKey == "" {
+		logrus.Warn("The SSH key is not recorded in the cluster, set sshKey")
+	}
+
+	if installConfig.BareMetal != nil {
+		dc, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return "", errors.Wrap(err, "creating a dynamic client")
+		}
+		list, err := dc.Resource(bareMetalHosts).Namespace(bareMetalHostNamespace).List(metav1.ListOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			logrus.Warn("The cluster has no BareMetalHosts, review platform.baremetal.hosts")
+		case err != nil:
+			return "", errors.Wrap(err, "failed to list BareMetalHosts")
+		default:
+			installConfig.BareMetal.Hosts = hostsFromBareMetalHosts(list.Items, installConfig.BareMetal.Hosts)
+		}
+	}
+
+	data, err := yaml.Marshal(installConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the install-config")
+	}
+	return path, fileaudit.WriteFile(path, data, 0600, "install config")
+}
+
+// storedInstallConfig returns the install-config stored in the cluster,
+// by kni-install or by the cluster's original installer, or nil if there
+// is none.
+func storedInstallConfig(client kubernetes.Interface) (*types.InstallConfig, error) {
+	for _, name := range []string{configRecordName, clusterConfigName} {
+		configMap, err := client.CoreV1().ConfigMaps(clusterConfigNamespace).Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s/%s", clusterConfigNamespace, name)
+		}
+		data, ok := configMap.Data["install-config"]
+		if !ok {
+			continue
+		}
+		installConfig := &types.InstallConfig{}
+		if err := yaml.Unmarshal([]byte(data), installConfig); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the install-config in %s/%s", clusterConfigNamespace, name)
+		}
+		logrus.Infof("Using the install-config stored in %s/%s", clusterConfigNamespace, name)
+		return installConfig, nil
+	}
+	logrus.Warn("No install-config is stored in the cluster, reconstructing it from the cluster's configuration")
+	return nil, nil
+}
+
+// clusterInstallConfig returns an install-config reconstructed from the
+// cluster's infrastructure, DNS and network configuration.  The machine
+// network and the platform's settings are not recorded in them.
+func clusterInstallConfig(infra *configv1.Infrastructure, dns *configv1.DNS, network *configv1.Network) (*types.InstallConfig, error) {
+	clusterDomain := strings.TrimSuffix(dns.Spec.BaseDomain, ".")
+	i := strings.Index(clusterDomain, ".")
+	if i < 1 {
+		return nil, errors.Errorf("the cluster's domain %q is not <name>.<base domain>", dns.Spec.BaseDomain)
+	}
+
+	installConfig := &types.InstallConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: types.InstallConfigVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterDomain[:i],
+		},
+		BaseDomain: clusterDomain[i+1:],
+		Networking: &types.Networking{
+			NetworkType: network.Spec.NetworkType,
+		},
+	}
+	for _, entry := range network.Spec.ClusterNetwork {
+		cidr, err := ipnet.ParseCIDR(entry.CIDR)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cluster network %q", entry.CIDR)
+		}
+		installConfig.Networking.ClusterNetwork = append(installConfig.Networking.ClusterNetwork, types.ClusterNetworkEntry{
+			CIDR:       *cidr,
+			HostPrefix: int32(entry.HostPrefix),
+		})
+	}
+	for _, s := range network.Spec.ServiceNetwork {
+		cidr, err := ipnet.ParseCIDR(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid service network %q", s)
+		}
+		installConfig.Networking.ServiceNetwork = append(installConfig.Networking.ServiceNetwork, *cidr)
+	}
+	logrus.Warn("The machine network is not recorded in the cluster, set networking.machineCIDR")
+
+	switch infra.Status.Platform {
+	case configv1.AWSPlatform:
+		installConfig.AWS = &aws.Platform{}
+		logrus.Warn("The AWS region is not recorded in the cluster, set platform.aws.region")
+	case configv1.BareMetalPlatform:
+		installConfig.BareMetal = &baremetal.Platform{}
+		logrus.Warn("The libvirt URI and virtual IPs are not recorded in the cluster, set platform.baremetal")
+	case configv1.LibvirtPlatform:
+		installConfig.Libvirt = &libvirt.Platform{}
+		logrus.Warn("The libvirt URI is not recorded in the cluster, set platform.libvirt.URI")
+	case configv1.OpenStackPlatform:
+		installConfig.OpenStack = &openstack.Platform{}
+		logrus.Warn("The OpenStack cloud and networks are not recorded in the cluster, set platform.openstack")
+	case configv1.NonePlatform, "":
+		installConfig.None = &none.Platform{}
+	default:
+		return nil, errors.Errorf("the cluster's platform %q is not supported", infra.Status.Platform)
+	}
+	return installConfig, nil
+}
+
+// hostsFromBareMetalHosts returns the inventory of the cluster's
+// BareMetalHosts, by name.  The role of a host is taken from the Machine
+// which consumes it, and hosts which no Machine consumes are unprovisioned
+// workers.  The settings of known hosts which BareMetalHosts do not
+// record, e.g. their static network configuration, are kept.
+func hostsFromBareMetalHosts(items []unstructured.Unstructured, known []baremetal.Host) []baremetal.Host {
+	knownByName := map[string]baremetal.Host{}
+	for _, h := range known {
+		knownByName[h.Name] = h
+	}
+
+	hosts := make([]baremetal.Host, 0, len(items))
+	for _, item := range items {
+		host, ok := knownByName[item.GetName()]
+		if !ok {
+			host = baremetal.Host{Name: item.GetName(), Role: baremetal.WorkerRole}
+		}
+		if mac, _, _ := unstructured.NestedString(item.Object, "spec", "bootMACAddress"); mac != "" {
+			host.BootMACAddress = mac
+		}
+		consumer, _, _ := unstructured.NestedString(item.Object, "spec", "consumerRef", "name")
+		switch {
+		case consumer == "":
+			host.Role = baremetal.WorkerRole
+			host.Unprovisioned = true
+		case strings.Contains(consumer, "-"+baremetal.MasterRole+"-"):
+			host.Role = baremetal.MasterRole
+			host.Unprovisioned = false
+		default:
+			host.Unprovisioned = false
+		}
+		if hardware := bareMetalHostHardware(item.Object); hardware != nil {
+			host.Hardware = hardware
+		}
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts
+}
+
+// bareMetalHostHardware returns the introspected hardware recorded in a
+// BareMetalHost's status, or nil if it has not been introspected.
+func bareMetalHostHardware(obj map[string]interface{}) *baremetal.HostHardware {
+	status, ok, _ := unstructured.NestedMap(obj, "status", "hardware")
+	if !ok {
+		return nil
+	}
+
+	hardware := &baremetal.HostHardware{}
+	if count, ok, _ := unstructured.NestedInt64(status, "cpu", "count"); ok {
+		hardware.CPUs = int(count)
+	}
+	hardware.MemoryMiB, _, _ = unstructured.NestedInt64(status, "ramMebibytes")
+	if storage, _, _ := unstructured.NestedSlice(status, "storage"); len(storage) > 0 {
+		if disk, ok := storage[0].(map[string]interface{}); ok {
+			if size, ok, _ := unstructured.NestedInt64(disk, "sizeBytes"); ok {
+				hardware.RootDiskGiB = size >> 30
+			}
+			hardware.RootDiskRotational, _, _ = unstructured.NestedBool(disk, "rotational")
+		}
+	}
+
+	firmware := baremetal.HostFirmware{}
+	firmware.Manufacturer, _, _ = unstructured.NestedString(status, "systemVendor", "manufacturer")
+	firmware.ProductName, _, _ = unstructured.NestedString(status, "systemVendor", "productName")
+	firmware.SerialNumber, _, _ = unstructured.NestedString(status, "systemVendor", "serialNumber")
+	firmware.BIOSVendor, _, _ = unstructured.NestedString(status, "firmware", "bios", "vendor")
+	firmware.BIOSVersion, _, _ = unstructured.NestedString(status, "firmware", "bios", "version")
+	firmware.BIOSDate, _, _ = unstructured.NestedString(status, "firmware", "bios", "date")
+	if firmware != (baremetal.HostFirmware{}) {
+		hardware.Firmware = &firmware
+	}
+	return hardware
+}
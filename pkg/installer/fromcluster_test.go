@@ -0,0 +1,116 @@
+package installer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestClusterInstallConfig(t *testing.T) {
+	infra := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.BareMetalPlatform}}
+	dns := &configv1.DNS{Spec: configv1.DNSSpec{BaseDomain: "test-cluster.example.com"}}
+	network := &configv1.Network{Spec: configv1.NetworkSpec{
+		ClusterNetwork: []configv1.ClusterNetworkEntry{{CIDR: "10.128.0.0/14", HostPrefix: 23}},
+		ServiceNetwork: []string{"172.30.0.0/16"},
+		NetworkType:    "OpenShiftSDN",
+	}}
+
+	installConfig, err := clusterInstallConfig(infra, dns, network)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &types.InstallConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: types.InstallConfigVersion},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+		BaseDomain: "example.com",
+		Networking: &types.Networking{
+			NetworkType:    "OpenShiftSDN",
+			ClusterNetwork: []types.ClusterNetworkEntry{{CIDR: *ipnet.MustParseCIDR("10.128.0.0/14"), HostPrefix: 23}},
+			ServiceNetwork: []ipnet.IPNet{*ipnet.MustParseCIDR("172.30.0.0/16")},
+		},
+		Platform: types.Platform{BareMetal: &baremetal.Platform{}},
+	}, installConfig)
+
+	infra.Status.Platform = configv1.VSpherePlatform
+	_, err = clusterInstallConfig(infra, dns, network)
+	assert.EqualError(t, err, `the cluster's platform "VSphere" is not supported`)
+
+	dns.Spec.BaseDomain = "localdomain"
+	_, err = clusterInstallConfig(infra, dns, network)
+	assert.EqualError(t, err, `the cluster's domain "localdomain" is not <name>.<base domain>`)
+}
+
+func TestHostsFromBareMetalHosts(t *testing.T) {
+	items := []unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "worker-0"},
+			"spec": map[string]interface{}{
+				"bootMACAddress": "52:54:00:aa:bb:04",
+				"consumerRef":    map[string]interface{}{"name": "test-cluster-worker-0-abcde"},
+			},
+		}},
+		{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "spare-0"},
+			"spec":     map[string]interface{}{"bootMACAddress": "52:54:00:aa:bb:09"},
+		}},
+		{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "master-0"},
+			"spec": map[string]interface{}{
+				"bootMACAddress": "52:54:00:aa:bb:01",
+				"consumerRef":    map[string]interface{}{"name": "test-cluster-master-0"},
+			},
+			"status": map[string]interface{}{
+				"hardware": map[string]interface{}{
+					"cpu":          map[string]interface{}{"count": int64(32)},
+					"ramMebibytes": int64(196608),
+					"storage": []interface{}{
+						map[string]interface{}{"sizeBytes": int64(480 << 30), "rotational": false},
+					},
+					"systemVendor": map[string]interface{}{
+						"manufacturer": "Dell Inc.",
+						"productName":  "PowerEdge R640",
+						"serialNumber": "ABC1234",
+					},
+					"firmware": map[string]interface{}{
+						"bios": map[string]interface{}{"vendor": "Dell Inc.", "version": "2.1.8", "date": "04/30/2019"},
+					},
+				},
+			},
+		}},
+	}
+	known := []baremetal.Host{
+		{Name: "master-0", Role: baremetal.MasterRole, KernelArgs: []string{"console=ttyS0"}},
+		{Name: "decommissioned-0", Role: baremetal.WorkerRole},
+	}
+
+	assert.Equal(t, []baremetal.Host{
+		{
+			Name:           "master-0",
+			Role:           baremetal.MasterRole,
+			BootMACAddress: "52:54:00:aa:bb:01",
+			KernelArgs:     []string{"console=ttyS0"},
+			Hardware: &baremetal.HostHardware{
+				CPUs:        32,
+				MemoryMiB:   196608,
+				RootDiskGiB: 480,
+				Firmware: &baremetal.HostFirmware{
+					Manufacturer: "Dell Inc.",
+					ProductName:  "PowerEdge R640",
+					SerialNumber: "ABC1234",
+					BIOSVendor:   "Dell Inc.",
+					BIOSVersion:  "2.1.8",
+					BIOSDate:     "04/30/2019",
+				},
+			},
+		},
+		{Name: "spare-0", Role: baremetal.WorkerRole, BootMACAddress: "52:54:00:aa:bb:09", Unprovisioned: true},
+		{Name: "worker-0", Role: baremetal.WorkerRole, BootMACAddress: "52:54:00:aa:bb:04"},
+	}, hostsFromBareMetalHosts(items, known))
+}
@@ -0,0 +1,81 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/manifests"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/encrypt"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/gitops"
+)
+
+// GitOpsOptions configures ExportGitOps.
+type GitOpsOptions struct {
+	// Dir is the asset directory.
+	Dir string
+
+	// Output is the directory to write the repository layout to.  It
+	// defaults to gitops/ in the asset directory.
+	Output string
+
+	// Layout is the repository layout, e.g. gitops.LayoutKustomize.
+	Layout string
+}
+
+// ExportGitOps writes the generated manifests of the install in the asset
+// directory, arranged in a repository layout, so they can be committed to
+// git and managed from there after the install.  It works at any stage of
+// the install, as the manifests are kept in the asset state after they are
+// consumed.  It returns the output directory.
+func ExportGitOps(ctx context.Context, opts GitOpsOptions) (string, error) {
+	store, err := assetstore.NewStore(opts.Dir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	if err := store.Fetch(ctx, installConfig); err != nil {
+		return "", errors.Wrap(err, "failed to fetch install config")
+	}
+	var encryptSecret gitops.EncryptFunc
+	if config := installConfig.Config.SecretsEncryption; config != nil {
+		encrypter := encrypt.New(config)
+		encryptSecret = func(filename string, data []byte) (string, []byte, error) {
+			return encrypter.Encrypt(ctx, filename, data)
+		}
+	}
+
+	var files []*asset.File
+	for _, a := range []asset.WritableAsset{&manifests.Manifests{}, &manifests.Openshift{}} {
+		if err := store.Fetch(ctx, a); err != nil {
+			return "", errors.Wrapf(err, "failed to fetch %s", a.Name())
+		}
+		files = append(files, a.Files()...)
+	}
+
+	arranged, err := gitops.Arrange(files, opts.Layout, encryptSecret)
+	if err != nil {
+		return "", err
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = filepath.Join(opts.Dir, "gitops")
+	}
+	for _, file := range arranged {
+		path := filepath.Join(output, file.Filename)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", errors.Wrap(err, "failed to create directory")
+		}
+		if err := fileaudit.WriteFile(path, file.Data, 0644, "GitOps export"); err != nil {
+			return "", errors.Wrapf(err, "failed to write %s", file.Filename)
+		}
+	}
+	return output, nil
+}
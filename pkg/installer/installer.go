@@ -0,0 +1,18 @@
+// Package installer is the library API for creating and destroying
+// clusters, for Go programs (controllers, test harnesses) which embed the
+// installer instead of running kni-install.
+//
+// Progress is logged through logrus, so callers wanting to capture it
+// should configure the standard logger.
+package installer
+
+// PhaseFunc is called as each phase of a long-running operation starts,
+// and returns a function to call when the phase completes successfully.
+type PhaseFunc func(phase string) func(message string)
+
+func (f PhaseFunc) start(phase string) func(message string) {
+	if f == nil {
+		return func(string) {}
+	}
+	return f(phase)
+}
@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/asset/tls"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+// NewKubeconfigOptions configures NewKubeconfig.
+type NewKubeconfigOptions struct {
+	// Dir is the asset directory of an installed cluster.
+	Dir string
+
+	// TTL is how long the client certificate is valid for.
+	TTL time.Duration
+
+	// User is the user the certificate authenticates as.
+	User string
+
+	// Groups are the groups the user is a member of.
+	Groups []string
+}
+
+// NewKubeconfig issues a fresh client certificate from the admin kubeconfig
+// signer in the installer state and returns a copy of the admin kubeconfig
+// which authenticates with it, along with the certificate's expiry.
+func NewKubeconfig(ctx context.Context, opts NewKubeconfigOptions) ([]byte, time.Time, error) {
+	if opts.TTL <= 0 {
+		return nil, time.Time{}, errors.New("the certificate TTL must be positive")
+	}
+	if opts.User == "" {
+		return nil, time.Time{}, errors.New("a user is required")
+	}
+
+	assetStore, err := assetstore.NewStore(opts.Dir)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to create asset store")
+	}
+	signer := &tls.AdminKubeConfigSignerCertKey{}
+	found, err := assetStore.LoadFromState(signer)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to load the admin kubeconfig signer")
+	}
+	if !found {
+		return nil, time.Time{}, errors.Errorf("the admin kubeconfig signer is not in the installer state in %s", opts.Dir)
+	}
+	caKey, err := tls.PemToPrivateKey(signer.Key())
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to parse the admin kubeconfig signer key")
+	}
+	caCert, err := tls.PemToCertificate(signer.Cert())
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to parse the admin kubeconfig signer certificate")
+	}
+
+	cfg := &tls.CertCfg{
+		Subject:      pkix.Name{CommonName: opts.User, Organization: opts.Groups},
+		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Validity:     opts.TTL,
+	}
+	key, crt, err := tls.GenerateSignedCertificate(ctx, caKey, caCert, cfg)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to generate the client certificate")
+	}
+
+	kubeconfig := filepath.Join(opts.Dir, "auth", "kubeconfig")
+	config, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "loading kubeconfig")
+	}
+	fileaudit.RecordFile(fileaudit.Read, kubeconfig, "admin kubeconfig")
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, time.Time{}, errors.Errorf("kubeconfig is missing its current context %q", config.CurrentContext)
+	}
+	authInfo := clientcmdapi.NewAuthInfo()
+	authInfo.ClientCertificateData = tls.CertToPem(crt)
+	authInfo.ClientKeyData = tls.PrivateKeyToPem(key)
+	config.AuthInfos = map[string]*clientcmdapi.AuthInfo{opts.User: authInfo}
+	kubeContext.AuthInfo = opts.User
+	config.Contexts = map[string]*clientcmdapi.Context{opts.User: kubeContext}
+	config.CurrentContext = opts.User
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to marshal kubeconfig")
+	}
+	return data, crt.NotAfter, nil
+}
@@ -0,0 +1,45 @@
+package installer
+
+import (
+	"context"
+
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/simulate"
+	"github.com/metalkube/kni-installer/pkg/verify"
+)
+
+// timeoutScale assesses the libvirt host of the install in dir, warning
+// about anything which will slow the install down, and returns the factor
+// to extend the installer's wait timeouts by.  It is 1 for other
+// platforms, and for simulated installs, which have no libvirt host.
+func timeoutScale(ctx context.Context, dir string) (time.Duration, error) {
+	if simulate.FromContext(ctx) {
+		return 1, nil
+	}
+	store, err := assetstore.NewStore(dir)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create asset store")
+	}
+	installConfig := &installconfig.InstallConfig{}
+	if err := store.Fetch(ctx, installConfig); err != nil {
+		return 0, errors.Wrap(err, "failed to fetch install config")
+	}
+
+	assessment := verify.AssessLibvirtHost(installConfig.Config)
+	if assessment == nil {
+		return 1, nil
+	}
+	for _, warning := range assessment.Warnings {
+		logrus.Warn(warning)
+	}
+	if assessment.TimeoutScale > 1 {
+		logrus.Infof("Extending the install timeouts %d times to allow for the libvirt host", assessment.TimeoutScale)
+	}
+	return time.Duration(assessment.TimeoutScale), nil
+}
@@ -0,0 +1,120 @@
+package installer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+// OutputOptions places copies of the generated files outside the asset
+// directory, for tools which expect them in specific locations.  The
+// asset directory keeps its layout, as later commands read it.
+type OutputOptions struct {
+	// Dirs maps a directory of the asset directory, e.g. auth, to the
+	// directory its generated files are copied to.
+	Dirs map[string]string
+
+	// Tarball, if set, is the path of a gzipped tarball of all the
+	// generated files, laid out as in the asset directory.
+	Tarball string
+}
+
+// write copies files to the directories and tarball of the options.
+func (o OutputOptions) write(files []*asset.File) error {
+	sort.Slice(files, func(i, j int) bool { return files[i].Filename < files[j].Filename })
+
+	written := map[string]string{}
+	for _, file := range files {
+		name := filepath.ToSlash(file.Filename)
+		i := strings.Index(name, "/")
+		if i < 0 {
+			continue
+		}
+		dir, ok := o.Dirs[name[:i]]
+		if !ok || dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name[i+1:]))
+		if other, ok := written[path]; ok && other != name {
+			return errors.Errorf("%s and %s are both written to %s", other, name, path)
+		}
+		written[path] = name
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Wrap(err, "failed to create dir")
+		}
+		if err := asset.WriteFile(path, file.Data, file.FileMode(), "copy of "+name); err != nil {
+			return errors.Wrapf(err, "failed to write %s", path)
+		}
+		logrus.Debugf("Copied %s to %s", name, path)
+	}
+
+	if o.Tarball != "" {
+		if err := writeTarball(o.Tarball, files); err != nil {
+			return errors.Wrapf(err, "failed to write %s", o.Tarball)
+		}
+		logrus.Infof("Generated files written to %s", o.Tarball)
+	}
+	return nil
+}
+
+// writeTarball writes a gzipped tarball of files to path.  It holds the
+// cluster's credentials, so only the owner may read it.
+func writeTarball(path string, files []*asset.File) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tempPath := fmt.Sprintf("%s.tmp", path)
+	out, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(tempPath)
+		}
+	}()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    filepath.ToSlash(file.Filename),
+			Mode:    int64(file.FileMode()),
+			Size:    int64(len(file.Data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return err
+	}
+	fileaudit.RecordFile(fileaudit.Write, path, "tarball of the generated files")
+	return nil
+}
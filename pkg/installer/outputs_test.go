@@ -0,0 +1,108 @@
+package installer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+)
+
+func TestOutputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outputs")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	files := []*asset.File{
+		{Filename: "auth/kubeconfig", Data: []byte("kubeconfig")},
+		{Filename: "manifests/cvo-overrides.yaml", Data: []byte("overrides")},
+		{Filename: "openshift/99_openshift-cluster-api_master-machines-0.yaml", Data: []byte("master")},
+		{Filename: "tls/certificate-audit.jsonl", Data: []byte("audit")},
+		{Filename: "metadata.json", Data: []byte("metadata")},
+	}
+	outputs := OutputOptions{
+		Dirs: map[string]string{
+			"auth":      filepath.Join(dir, "credentials"),
+			"manifests": filepath.Join(dir, "manifests"),
+			"openshift": filepath.Join(dir, "manifests"),
+		},
+		Tarball: filepath.Join(dir, "assets.tar.gz"),
+	}
+	if !assert.NoError(t, outputs.write(files)) {
+		return
+	}
+
+	for path, expected := range map[string]string{
+		"credentials/kubeconfig":                                    "kubeconfig",
+		"manifests/cvo-overrides.yaml":                              "overrides",
+		"manifests/99_openshift-cluster-api_master-machines-0.yaml": "master",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, string(data))
+		}
+	}
+	for path, expected := range map[string]os.FileMode{
+		"credentials/kubeconfig":       asset.PrivateMode,
+		"manifests/cvo-overrides.yaml": asset.PublicMode,
+	} {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, info.Mode().Perm(), path)
+		}
+	}
+	_, err = os.Stat(filepath.Join(dir, "tls"))
+	assert.True(t, os.IsNotExist(err), "tls/ was copied without a directory for it")
+
+	info, err := os.Stat(outputs.Tarball)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	archived := map[string]string{}
+	tarball, err := os.Open(outputs.Tarball)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tarball.Close()
+	gz, err := gzip.NewReader(tarball)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		data, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		archived[header.Name] = string(data)
+	}
+	assert.Equal(t, map[string]string{
+		"auth/kubeconfig":              "kubeconfig",
+		"manifests/cvo-overrides.yaml": "overrides",
+		"openshift/99_openshift-cluster-api_master-machines-0.yaml": "master",
+		"tls/certificate-audit.jsonl":                               "audit",
+		"metadata.json":                                             "metadata",
+	}, archived)
+
+	conflicting := OutputOptions{Dirs: map[string]string{"manifests": dir, "openshift": dir}}
+	err = conflicting.write([]*asset.File{
+		{Filename: "manifests/a.yaml"},
+		{Filename: "openshift/a.yaml"},
+	})
+	assert.EqualError(t, err, "manifests/a.yaml and openshift/a.yaml are both written to "+filepath.Join(dir, "a.yaml"))
+}
@@ -0,0 +1,42 @@
+package installer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/snapshot"
+	_ "github.com/metalkube/kni-installer/pkg/snapshot/libvirt"
+)
+
+// CreateSnapshot snapshots all of the machines of the cluster in the asset
+// directory dir as name.
+func CreateSnapshot(ctx context.Context, dir string, name string) error {
+	snapshotter, err := newSnapshotter(ctx, dir, name)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(snapshotter.Create(name), "failed to create snapshot %q", name)
+}
+
+// RestoreSnapshot rolls all of the machines of the cluster in the asset
+// directory dir back to the snapshot name.
+func RestoreSnapshot(ctx context.Context, dir string, name string) error {
+	snapshotter, err := newSnapshotter(ctx, dir, name)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(snapshotter.Restore(name), "failed to restore snapshot %q", name)
+}
+
+func newSnapshotter(ctx context.Context, dir string, name string) (snapshot.Snapshotter, error) {
+	if err := snapshot.ValidateName(name); err != nil {
+		return nil, err
+	}
+	snapshotter, err := snapshot.New(logrus.StandardLogger(), dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed while preparing to snapshot cluster")
+	}
+	return snapshotter, ctx.Err()
+}
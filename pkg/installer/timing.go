@@ -0,0 +1,40 @@
+package installer
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/timing"
+)
+
+// timed returns ctx recording the time spent in each stage, and a function
+// which logs the summary.  If ctx already records them, the caller which
+// started recording logs the summary instead, and the function does
+// nothing.
+func timed(ctx context.Context) (context.Context, *timing.Recorder, func()) {
+	if recorder := timing.FromContext(ctx); recorder != nil {
+		return ctx, recorder, func() {}
+	}
+	recorder := timing.NewRecorder()
+	return timing.NewContext(ctx, recorder), recorder, func() {
+		timing.Log(logrus.StandardLogger(), recorder.Timings())
+	}
+}
+
+// recordTimings writes the time spent in each stage of the install to the
+// cluster's metadata.json, if it has been generated.
+func recordTimings(dir string, recorder *timing.Recorder) error {
+	metadata, err := cluster.LoadMetadata(dir)
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil
+		}
+		return err
+	}
+	metadata.Timings = recorder.Timings()
+	return cluster.SaveMetadata(dir, metadata)
+}
@@ -0,0 +1,130 @@
+// Package notify posts installer progress to a webhook, so operators can
+// walk away from long-running installs.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+)
+
+// Status is the state of a phase.
+type Status string
+
+const (
+	// Started is sent when a phase begins.
+	Started Status = "started"
+	// Succeeded is sent when a phase completes successfully.
+	Succeeded Status = "succeeded"
+	// Failed is sent when the installer exits with an error.
+	Failed Status = "failed"
+)
+
+// Event is the JSON document posted to the webhook.
+type Event struct {
+	Directory string        `json:"directory"`
+	Phase     string        `json:"phase"`
+	Status    Status        `json:"status"`
+	Message   string        `json:"message,omitempty"`
+	Time      time.Time     `json:"time"`
+	Elapsed   time.Duration `json:"elapsed"`
+
+	// Text summarizes the event in a single line.  It is what Slack
+	// incoming webhooks display, and ignored by other receivers.
+	Text string `json:"text"`
+}
+
+// Notifier posts events to a webhook.  A nil *Notifier is valid and
+// discards all events.
+type Notifier struct {
+	url       string
+	directory string
+	client    *http.Client
+	start     time.Time
+	offline   bool
+}
+
+// New returns a Notifier posting to url events about the install in
+// directory.  Nothing is posted if ctx is offline.
+func New(ctx context.Context, url, directory string) *Notifier {
+	return &Notifier{
+		url:       url,
+		directory: directory,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		start:     time.Now(),
+		offline:   offline.FromContext(ctx),
+	}
+}
+
+// Notify posts an event.  Delivery failures are logged, but never fail the
+// install.
+func (n *Notifier) Notify(phase string, status Status, message string) {
+	if n == nil {
+		return
+	}
+
+	now := time.Now()
+	event := &Event{
+		Directory: n.directory,
+		Phase:     phase,
+		Status:    status,
+		Message:   message,
+		Time:      now,
+		Elapsed:   now.Sub(n.start),
+	}
+	event.Text = fmt.Sprintf("kni-install (%s): %s %s after %s", event.Directory, event.Phase, event.Status, event.Elapsed.Round(time.Second))
+	if message != "" {
+		event.Text = fmt.Sprintf("%s: %s", event.Text, message)
+	}
+
+	if err := n.post(event); err != nil {
+		logrus.Warnf("Failed to send notification: %v", err)
+	}
+}
+
+func (n *Notifier) post(event *Event) error {
+	if n.offline {
+		return errors.New("refusing to post a notification in offline mode")
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("%s while posting to %s", resp.Status, n.url)
+	}
+	return nil
+}
+
+// FailureHook is a logrus hook which sends a Failed event for fatal log
+// entries, since those exit the installer without returning to the caller.
+type FailureHook struct {
+	Notifier *Notifier
+
+	// Phase is reported as the phase which failed.
+	Phase string
+}
+
+// Levels returns the levels the hook fires on.
+func (h *FailureHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel}
+}
+
+// Fire sends the failure notification.
+func (h *FailureHook) Fire(entry *logrus.Entry) error {
+	h.Notifier.Notify(h.Phase, Failed, entry.Message)
+	return nil
+}
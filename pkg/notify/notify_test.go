@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+)
+
+func TestNotify(t *testing.T) {
+	var events []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := Event{}
+		if assert.NoError(t, json.NewDecoder(r.Body).Decode(&event)) {
+			events = append(events, event)
+		}
+	}))
+	defer server.Close()
+
+	n := New(context.Background(), server.URL, "/tmp/cluster")
+	n.Notify("Cluster", Started, "")
+	hook := &FailureHook{Notifier: n, Phase: "Cluster"}
+	assert.NoError(t, hook.Fire(&logrus.Entry{Message: "terraform failed"}))
+
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "Cluster", events[0].Phase)
+		assert.Equal(t, Started, events[0].Status)
+		assert.Equal(t, "/tmp/cluster", events[0].Directory)
+		assert.Equal(t, Failed, events[1].Status)
+		assert.Equal(t, "terraform failed", events[1].Message)
+		assert.Contains(t, events[1].Text, "Cluster failed after")
+	}
+}
+
+func TestNilNotifier(t *testing.T) {
+	var n *Notifier
+	n.Notify("Cluster", Succeeded, "")
+}
+
+func TestNotifyOffline(t *testing.T) {
+	posted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = true
+	}))
+	defer server.Close()
+
+	n := New(offline.NewContext(context.Background()), server.URL, "/tmp/cluster")
+	assert.EqualError(t, n.post(&Event{}), "refusing to post a notification in offline mode")
+	assert.False(t, posted)
+}
@@ -0,0 +1,34 @@
+// Package offline lets the installer run without any outbound network
+// access, for disconnected sites.  Everything the install would otherwise
+// fetch must come from local paths or mirrors instead.
+package offline
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx in which the installer must not make
+// outbound network calls.  The --offline flag sets it.
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, true)
+}
+
+// FromContext returns true if the installer must not make outbound
+// network calls for ctx.
+func FromContext(ctx context.Context) bool {
+	offline, _ := ctx.Value(contextKey{}).(bool)
+	return offline
+}
+
+// Check returns an error if ctx is offline, naming the fetch that was
+// refused.  Call it before any outbound network call.
+func Check(ctx context.Context, what string) error {
+	if FromContext(ctx) {
+		return errors.Errorf("refusing to %s in offline mode", what)
+	}
+	return nil
+}
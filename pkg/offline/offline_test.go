@@ -0,0 +1,14 @@
+package offline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, Check(ctx, "fetch things"))
+	assert.EqualError(t, Check(NewContext(ctx), "fetch things"), "refusing to fetch things in offline mode")
+}
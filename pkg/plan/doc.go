@@ -0,0 +1,6 @@
+// Package plan reports the impact of changing the install config of an
+// asset directory: which generated files, and so which manifests,
+// Ignition configs and infrastructure, each changed field would alter, and
+// which changes cannot be applied any more.  Edits which alter nothing,
+// or come too late to be applied, are otherwise silently ignored.
+package plan
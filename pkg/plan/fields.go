@@ -0,0 +1,140 @@
+package plan
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// fieldDepth is how deep fields are compared, e.g. platform.baremetal.
+// Changes below it are reported against the enclosing field.
+const fieldDepth = 2
+
+// toMap returns the JSON form of v.
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, json.Unmarshal(data, &m)
+}
+
+// copyMap returns a deep copy of m.
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	c, err := toMap(m)
+	if err != nil {
+		// m was unmarshalled from JSON, so cannot fail to marshal.
+		panic(err)
+	}
+	return c
+}
+
+// changedFields returns the dot-separated paths of the fields which differ
+// between old and new, in order.
+func changedFields(old, new map[string]interface{}, prefix string, depth int) []string {
+	keys := map[string]bool{}
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+
+	var fields []string
+	for k := range keys {
+		o, n := old[k], new[k]
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		om, oIsMap := o.(map[string]interface{})
+		nm, nIsMap := n.(map[string]interface{})
+		if oIsMap && nIsMap && depth > 1 {
+			fields = append(fields, changedFields(om, nm, prefix+k+".", depth-1)...)
+			continue
+		}
+		fields = append(fields, prefix+k)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// unknownFields returns the dot-separated paths of the fields set in raw,
+// the install config as written, which are not in parsed, as the installer
+// does not know them.  Fields set to empty values are left out, as they
+// are omitted from parsed when known too.
+func unknownFields(raw, parsed map[string]interface{}, prefix string) []string {
+	var fields []string
+	for k, v := range raw {
+		p, ok := parsed[k]
+		if !ok {
+			if !isEmpty(v) {
+				fields = append(fields, prefix+k)
+			}
+			continue
+		}
+		rm, rIsMap := v.(map[string]interface{})
+		pm, pIsMap := p.(map[string]interface{})
+		if rIsMap && pIsMap {
+			fields = append(fields, unknownFields(rm, pm, prefix+k+".")...)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// isEmpty returns true if v, unmarshalled from JSON, is empty.
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// getField returns the value of the field at path, and whether it is set.
+func getField(m map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := m[k].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m = child
+	}
+	v, ok := m[keys[len(keys)-1]]
+	return v, ok
+}
+
+// setField sets the field at path to v, creating its parents, or removes
+// it if it is not set.
+func setField(m map[string]interface{}, path string, v interface{}, set bool) {
+	keys := strings.Split(path, ".")
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := m[k].(map[string]interface{})
+		if !ok {
+			if !set {
+				return
+			}
+			child = map[string]interface{}{}
+			m[k] = child
+		}
+		m = child
+	}
+	if set {
+		m[keys[len(keys)-1]] = v
+	} else {
+		delete(m, keys[len(keys)-1])
+	}
+}
@@ -0,0 +1,377 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	"github.com/metalkube/kni-installer/pkg/asset/manifests"
+	"github.com/metalkube/kni-installer/pkg/asset/render"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+	"github.com/metalkube/kni-installer/pkg/asset/targets"
+	"github.com/metalkube/kni-installer/pkg/simulate"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// Stage is how far an asset directory has been taken.
+type Stage string
+
+const (
+	// StageInstallConfig is an asset directory with only an install config.
+	StageInstallConfig Stage = "install-config"
+
+	// StageManifests is an asset directory with manifests, which are
+	// regenerated, discarding any edits, when the install config changes.
+	StageManifests Stage = "manifests"
+
+	// StageIgnitionConfigs is an asset directory with Ignition configs.
+	StageIgnitionConfigs Stage = "ignition-configs"
+
+	// StageCluster is an asset directory whose cluster has been created.
+	// Changing the install config no longer changes the cluster.
+	StageCluster Stage = "cluster"
+)
+
+// Category is the kind of a generated file.
+type Category string
+
+const (
+	// CategoryManifest is a manifest, applied to the cluster.
+	CategoryManifest Category = "manifest"
+
+	// CategoryIgnition is an Ignition config, or a file within one.
+	CategoryIgnition Category = "ignition"
+
+	// CategoryInfrastructure is a Terraform variables file, which changes
+	// the machines, networks and load balancers created.
+	CategoryInfrastructure Category = "infrastructure"
+
+	// CategoryOther is any other file, e.g. a kubeconfig.
+	CategoryOther Category = "other"
+)
+
+// Targets are the assets rendered to find the files which change.
+var Targets = [][]asset.WritableAsset{
+	targets.Manifests,
+	targets.IgnitionConfigs,
+	{&cluster.TerraformVariables{}},
+}
+
+// FileChange is a generated file which changes.
+type FileChange struct {
+	// Path is the path of the file in the asset directory.  Files in
+	// Ignition configs are under <config>.d, e.g.
+	// bootstrap.ign.d/opt/openshift/manifests/cvo-overrides.yaml.
+	Path string `json:"path"`
+
+	// Asset is the name of the target asset generating the file.
+	Asset string `json:"asset"`
+
+	// Category is the kind of file.
+	Category Category `json:"category"`
+
+	// Change is "added", "removed" or "modified".
+	Change string `json:"change"`
+}
+
+// Change is a changed field of the install config.
+type Change struct {
+	// Field is the dot-separated path of the field, e.g.
+	// platform.baremetal.
+	Field string `json:"field"`
+
+	// Files are the paths of the files which changing the field alone
+	// changes.
+	Files []string `json:"files,omitempty"`
+
+	// Infrastructure is true if the field changes the infrastructure.
+	Infrastructure bool `json:"infrastructure"`
+
+	// NoOp is true if the field changes no generated file, so changing it
+	// has no effect.
+	NoOp bool `json:"noOp,omitempty"`
+
+	// Unsupported is why the change cannot be applied, if it cannot.
+	Unsupported string `json:"unsupported,omitempty"`
+
+	// Error is why the field could not be rendered without the other
+	// changes, e.g. because they must change together.  Its files are
+	// then unknown.
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the impact of changing the install config.
+type Report struct {
+	// Stage is how far the asset directory has been taken.
+	Stage Stage `json:"stage"`
+
+	// Changes are the changed fields of the install config, in order.
+	Changes []Change `json:"changes"`
+
+	// Files are the generated files which all of the changes together
+	// change, in order.
+	Files []FileChange `json:"files"`
+
+	// Ignored are the fields of the new install config which the
+	// installer does not know, and ignores, e.g. because they are
+	// misspelt or belong to another version, in order.
+	Ignored []string `json:"ignored"`
+
+	// Unsupported is the number of changes which cannot be applied.
+	Unsupported int `json:"unsupported"`
+
+	// NoOps is the number of changes which have no effect.
+	NoOps int `json:"noOps"`
+}
+
+// Plan compares installConfig with the install config stored in the state
+// of the asset directory dir.  The assets are rendered from both, and from
+// the stored install config with each changed field in turn, replaying the
+// same random inputs, so that the files each field changes are found.  As
+// the keys are regenerated whenever the install config changes, every
+// certificate changes in practice, but is only reported when the change
+// affects it.
+//
+// Rendering is simulated, so neither the platform nor the RHCOS image is
+// contacted.
+func Plan(ctx context.Context, dir string, installConfig []byte) (*Report, error) {
+	store, err := assetstore.NewStore(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	stored := &installconfig.InstallConfig{}
+	found, err := store.LoadFromState(stored)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the stored install config")
+	}
+	if !found {
+		return nil, errors.Errorf("the install config is not in the installer state in %s", dir)
+	}
+	report := &Report{Changes: []Change{}, Files: []FileChange{}, Ignored: []string{}}
+	if report.Stage, err = stage(store); err != nil {
+		return nil, err
+	}
+
+	updated, err := parse(ctx, installConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := toMap(stored.Config)
+	if err != nil {
+		return nil, err
+	}
+	after, err := toMap(updated)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(installConfig, &raw); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the new install config")
+	}
+	report.Ignored = append(report.Ignored, unknownFields(raw, after, "")...)
+
+	fields := changedFields(before, after, "", fieldDepth)
+	if len(fields) == 0 {
+		return report, nil
+	}
+
+	replay := render.NewReplay()
+	oldFiles, err := renderMap(ctx, replay, before)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render the stored install config")
+	}
+	newFiles, err := renderMap(ctx, replay, after)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render the new install config")
+	}
+	changed := diffFiles(oldFiles, newFiles)
+
+	for _, field := range fields {
+		change := Change{Field: field}
+		var files map[string]FileChange
+		hybrid := copyMap(before)
+		v, set := getField(after, field)
+		setField(hybrid, field, v, set)
+		hybridFiles, err := renderMap(ctx, replay, hybrid)
+		if err != nil {
+			change.Error = err.Error()
+		} else {
+			files = diffFiles(oldFiles, hybridFiles)
+		}
+
+		for _, file := range files {
+			change.Files = append(change.Files, file.Path)
+			change.Infrastructure = change.Infrastructure || file.Category == CategoryInfrastructure
+		}
+		sort.Strings(change.Files)
+		if change.Error == "" && len(change.Files) == 0 {
+			change.NoOp = true
+			report.NoOps++
+		}
+		if report.Stage == StageCluster {
+			change.Unsupported = "the cluster has been created; make the change with day-2 operations"
+			report.Unsupported++
+		}
+		report.Changes = append(report.Changes, change)
+	}
+
+	for _, file := range changed {
+		report.Files = append(report.Files, file)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+	return report, nil
+}
+
+// stage returns how far the asset directory of store has been taken.
+func stage(store asset.Store) (Stage, error) {
+	for _, s := range []struct {
+		stage Stage
+		asset asset.Asset
+	}{
+		{stage: StageCluster, asset: &cluster.Cluster{}},
+		{stage: StageIgnitionConfigs, asset: &bootstrap.Bootstrap{}},
+		{stage: StageManifests, asset: &manifests.Manifests{}},
+	} {
+		found, err := store.LoadFromState(s.asset)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to load %s", s.asset.Name())
+		}
+		if found {
+			return s.stage, nil
+		}
+	}
+	return StageInstallConfig, nil
+}
+
+// parse returns the defaulted and validated install config.
+func parse(ctx context.Context, data []byte) (*types.InstallConfig, error) {
+	dir, err := ioutil.TempDir("", "plan")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "install-config.yaml"), data, 0600); err != nil {
+		return nil, err
+	}
+	store, err := assetstore.NewStore(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	config := &installconfig.InstallConfig{}
+	if err := store.Fetch(ctx, config); err != nil {
+		return nil, errors.Wrap(err, "failed to load the new install config")
+	}
+	return config.Config, nil
+}
+
+// renderMap renders the install config m, simulated, with the inputs
+// recorded by replay.
+func renderMap(ctx context.Context, replay *render.Replay, m map[string]interface{}) (map[string]render.File, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return render.Render(simulate.NewContext(ctx), data, Targets, replay.Sources)
+}
+
+// diffFiles returns the files which differ between old and new, keyed by
+// path.  The install config itself is left out.
+func diffFiles(old, new map[string]render.File) map[string]FileChange {
+	files := map[string]FileChange{}
+	for path, file := range new {
+		if oldFile, ok := old[path]; !ok {
+			files[path] = fileChange(path, file, "added")
+		} else if !bytes.Equal(oldFile.Data, file.Data) {
+			files[path] = fileChange(path, file, "modified")
+		}
+	}
+	for path, file := range old {
+		if _, ok := new[path]; !ok {
+			files[path] = fileChange(path, file, "removed")
+		}
+	}
+	delete(files, "install-config.yaml")
+	return files
+}
+
+func fileChange(path string, file render.File, change string) FileChange {
+	category := CategoryOther
+	switch {
+	case strings.HasPrefix(path, "manifests/"), strings.HasPrefix(path, "openshift/"):
+		category = CategoryManifest
+	case strings.Contains(path, ".ign"):
+		category = CategoryIgnition
+	case strings.HasPrefix(path, "terraform."):
+		category = CategoryInfrastructure
+	}
+	return FileChange{Path: path, Asset: file.Asset, Category: category, Change: change}
+}
+
+// WriteTable writes the report as tables of the changed fields and the
+// changed files to w.
+func (r *Report) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Stage: %s\n", r.Stage)
+	for _, field := range r.Ignored {
+		fmt.Fprintf(w, "Ignored: %s is not an install config field\n", field)
+	}
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "The install config is unchanged.")
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tFILES\tINFRASTRUCTURE\tNOTE")
+	for _, c := range r.Changes {
+		files := fmt.Sprintf("%d", len(c.Files))
+		infrastructure := "no"
+		if c.Infrastructure {
+			infrastructure = "yes"
+		}
+		var notes []string
+		if c.NoOp {
+			notes = append(notes, "no effect")
+		}
+		if c.Error != "" {
+			files = "?"
+			notes = append(notes, "only renders with other changes: "+c.Error)
+		}
+		if c.Unsupported != "" {
+			notes = append(notes, "unsupported: "+c.Unsupported)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Field, files, infrastructure, strings.Join(notes, "; "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Files) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tCATEGORY\tCHANGE\tASSET")
+	for _, f := range r.Files {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Path, f.Category, f.Change, f.Asset)
+	}
+	return tw.Flush()
+}
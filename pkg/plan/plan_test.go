@@ -0,0 +1,123 @@
+package plan
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/data"
+	"github.com/metalkube/kni-installer/pkg/asset/installconfig"
+	assetstore "github.com/metalkube/kni-installer/pkg/asset/store"
+)
+
+const storedInstallConfig = `apiVersion: v1beta4
+metadata:
+  name: test-cluster
+baseDomain: test-domain
+platform:
+  fake: {}
+pullSecret: '{"auths":{"quay.io":{"auth":"c3VwZXItc2VjcmV0Cg=="}}}'
+`
+
+func TestPlan(t *testing.T) {
+	data.Assets = http.Dir("../../data/data")
+
+	dir, err := ioutil.TempDir("", "kni-install-plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "install-config.yaml"), []byte(storedInstallConfig), 0600))
+	store, err := assetstore.NewStore(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, store.Fetch(context.Background(), &installconfig.InstallConfig{})) {
+		return
+	}
+
+	report, err := Plan(context.Background(), dir, []byte(storedInstallConfig))
+	if assert.NoError(t, err) {
+		assert.Equal(t, StageInstallConfig, report.Stage)
+		assert.Empty(t, report.Changes)
+		assert.Empty(t, report.Files)
+	}
+
+	report, err = Plan(context.Background(), dir, []byte(storedInstallConfig+`compute:
+- name: worker
+  replicas: 5
+networking:
+  serviceNetwork:
+  - 172.31.0.0/16
+fips: true
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"fips"}, report.Ignored)
+	if !assert.Len(t, report.Changes, 2) {
+		return
+	}
+	assert.Equal(t, "compute", report.Changes[0].Field)
+	serviceNetwork := report.Changes[1]
+	assert.Equal(t, "networking.serviceNetwork", serviceNetwork.Field)
+	assert.True(t, serviceNetwork.Infrastructure)
+	assert.False(t, serviceNetwork.NoOp)
+	assert.Contains(t, serviceNetwork.Files, "manifests/cluster-network-02-config.yml")
+	assert.Contains(t, serviceNetwork.Files, "bootstrap.ign.d/opt/openshift/tls/kube-apiserver-service-network-server.crt")
+	assert.NotContains(t, serviceNetwork.Files, "bootstrap.ign.d/opt/openshift/tls/kube-apiserver-lb-server.crt")
+	assert.Contains(t, report.Files, FileChange{
+		Path:     "terraform.tfvars",
+		Asset:    "Terraform Variables",
+		Category: CategoryInfrastructure,
+		Change:   "modified",
+	})
+	assert.Zero(t, report.Unsupported)
+
+	report, err = Plan(context.Background(), dir, []byte(storedInstallConfig+`tls:
+  keyAlgorithm: ECDSA-P256
+`))
+	if !assert.NoError(t, err) || !assert.Len(t, report.Changes, 1) {
+		return
+	}
+	keyAlgorithm := report.Changes[0]
+	assert.Equal(t, "tls", keyAlgorithm.Field)
+	assert.Contains(t, keyAlgorithm.Files, "bootstrap.ign.d/opt/openshift/tls/kube-apiserver-lb-server.crt")
+	assert.NotContains(t, keyAlgorithm.Files, "bootstrap.ign.d/opt/openshift/tls/service-account.pub")
+}
+
+func TestChangedFields(t *testing.T) {
+	old := map[string]interface{}{
+		"baseDomain": "example.com",
+		"platform":   map[string]interface{}{"baremetal": map[string]interface{}{"uri": "qemu:///system"}},
+		"compute":    []interface{}{map[string]interface{}{"replicas": 3.0}},
+		"sshKey":     "ssh-ed25519 AAAA",
+	}
+	new := map[string]interface{}{
+		"baseDomain": "example.com",
+		"platform":   map[string]interface{}{"baremetal": map[string]interface{}{"uri": "qemu+ssh://host/system"}},
+		"compute":    []interface{}{map[string]interface{}{"replicas": 2.0}},
+		"tls":        map[string]interface{}{"keyAlgorithm": "ECDSA-P256"},
+	}
+	assert.Equal(t, []string{"compute", "platform.baremetal", "sshKey", "tls"}, changedFields(old, new, "", fieldDepth))
+
+	setField(old, "tls.keyAlgorithm", "ECDSA-P256", true)
+	setField(old, "sshKey", nil, false)
+	v, set := getField(new, "platform.baremetal")
+	setField(old, "platform.baremetal", v, set)
+	assert.Equal(t, []string{"compute"}, changedFields(old, new, "", fieldDepth))
+
+	raw := map[string]interface{}{
+		"baseDomain": "example.com",
+		"fips":       true,
+		"platform":   map[string]interface{}{"baremetal": map[string]interface{}{"uri": "qemu:///system", "bootMode": "UEFI"}},
+		"proxy":      map[string]interface{}{},
+	}
+	assert.Equal(t, []string{"fips", "platform.baremetal.bootMode"}, unknownFields(raw, old, ""))
+}
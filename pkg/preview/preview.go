@@ -0,0 +1,402 @@
+// Package preview renders the bootstrap machine's static pod manifests
+// locally, as bootkube.sh does on the bootstrap machine, and checks them,
+// so mismatches between the release image and the installer's templates
+// are caught before any hardware is touched.
+//
+// The render steps run the release image's operators with podman, which
+// must be installed on the installer host and able to pull the release
+// image.  Without it, only the installer's own files are checked.
+package preview
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vincent-petithory/dataurl"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/manifests"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+const (
+	// assetDir is where the bootstrap Ignition config places the assets
+	// bootkube.sh renders from.
+	assetDir = "/opt/openshift"
+
+	// Runtime is the container runtime the render steps run with.
+	Runtime = "podman"
+)
+
+// run runs an external command, returning its output.  Tests override it.
+var run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	logrus.Debugf("Running %s %s", name, strings.Join(args, " "))
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return out, errors.Wrapf(err, "%s failed: %s", name, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// Extract writes the files the bootstrap Ignition config places below
+// /opt/openshift to dir, taking the contents the config references
+// instead of embedding from contents.
+func Extract(config *igntypes.Config, contents []*asset.File, dir string) error {
+	served := map[string][]byte{}
+	for _, file := range contents {
+		served[filepath.Base(file.Filename)] = file.Data
+	}
+
+	for _, file := range config.Storage.Files {
+		if !strings.HasPrefix(file.Path, assetDir+"/") {
+			continue
+		}
+		var data []byte
+		if decoded, err := dataurl.DecodeString(file.Contents.Source); err == nil {
+			data = decoded.Data
+		} else if d, ok := served[path.Base(file.Contents.Source)]; ok {
+			data = d
+		} else {
+			return errors.Errorf("the contents of %s are neither embedded nor served by the installer", file.Path)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(file.Path, assetDir+"/")))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := fileaudit.WriteFile(target, data, 0600, "bootstrap preview asset"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Options are the parameters of the render steps, which the installer
+// also renders into bootkube.sh.
+type Options struct {
+	// ReleaseImage is the release image the cluster is installed from.
+	ReleaseImage string
+
+	// EtcdCluster is the comma-separated etcd client URLs.
+	EtcdCluster string
+
+	// PullSecret is the pull secret the images are pulled with.
+	PullSecret string
+}
+
+// step is one of bootkube.sh's render steps.
+type step struct {
+	// name is the step's output directory.
+	name string
+	// image is the image the step runs, by its name in the release, or
+	// the release image itself if empty.
+	image string
+	// args are the arguments of the step, with /assets the asset
+	// directory and {{image-name}} replaced by the image of that name in
+	// the release.
+	args []string
+	// copies are the output directories of the step copied into the
+	// asset directory, and where to.
+	copies map[string]string
+}
+
+// steps returns bootkube.sh's render steps.  Keep them in sync with
+// data/data/bootstrap/files/usr/local/bin/bootkube.sh.template.
+func steps(opts Options) []step {
+	return []step{
+		{
+			name: "cvo-bootstrap",
+			args: []string{"render", "--output-dir=/assets/cvo-bootstrap", "--release-image=" + opts.ReleaseImage},
+			copies: map[string]string{
+				"bootstrap": "bootstrap-manifests",
+				"manifests": "manifests",
+			},
+		},
+		{
+			name:  "config-bootstrap",
+			image: "cluster-config-operator",
+			args: []string{
+				"/usr/bin/cluster-config-operator", "render",
+				"--config-output-file=/assets/config-bootstrap/config",
+				"--asset-input-dir=/assets/tls",
+				"--asset-output-dir=/assets/config-bootstrap",
+			},
+			copies: map[string]string{"manifests": "manifests"},
+		},
+		{
+			name:  "kube-apiserver-bootstrap",
+			image: "cluster-kube-apiserver-operator",
+			args: []string{
+				"/usr/bin/cluster-kube-apiserver-operator", "render",
+				"--manifest-etcd-serving-ca=etcd-client-ca.crt",
+				"--manifest-etcd-server-urls=" + opts.EtcdCluster,
+				"--manifest-image={{hypershift}}",
+				"--asset-input-dir=/assets/tls",
+				"--asset-output-dir=/assets/kube-apiserver-bootstrap",
+				"--config-output-file=/assets/kube-apiserver-bootstrap/config",
+				"--config-override-files=/assets/kube-apiserver-config-overrides.yaml",
+				"--cluster-config-file=/assets/openshift/99_openshift-cluster-api_cluster.yaml",
+			},
+			copies: map[string]string{
+				"bootstrap-manifests": "bootstrap-manifests",
+				"manifests":           "manifests",
+			},
+		},
+		{
+			name:  "kube-controller-manager-bootstrap",
+			image: "cluster-kube-controller-manager-operator",
+			args: []string{
+				"/usr/bin/cluster-kube-controller-manager-operator", "render",
+				"--manifest-image={{hyperkube}}",
+				"--asset-input-dir=/assets/tls",
+				"--asset-output-dir=/assets/kube-controller-manager-bootstrap",
+				"--config-output-file=/assets/kube-controller-manager-bootstrap/config",
+				"--cluster-config-file=/assets/openshift/99_openshift-cluster-api_cluster.yaml",
+			},
+			copies: map[string]string{
+				"bootstrap-manifests": "bootstrap-manifests",
+				"manifests":           "manifests",
+			},
+		},
+		{
+			name:  "kube-scheduler-bootstrap",
+			image: "cluster-kube-scheduler-operator",
+			args: []string{
+				"/usr/bin/cluster-kube-scheduler-operator", "render",
+				"--manifest-image={{hyperkube}}",
+				"--asset-input-dir=/assets/tls",
+				"--asset-output-dir=/assets/kube-scheduler-bootstrap",
+				"--config-output-file=/assets/kube-scheduler-bootstrap/config",
+			},
+			copies: map[string]string{
+				"bootstrap-manifests": "bootstrap-manifests",
+				"manifests":           "manifests",
+			},
+		},
+		{
+			name:  "mco-bootstrap",
+			image: "machine-config-operator",
+			args: []string{
+				"bootstrap",
+				"--etcd-ca=/assets/tls/etcd-client-ca.crt",
+				"--root-ca=/assets/tls/root-ca.crt",
+				"--kube-ca=/assets/tls/kube-ca.crt",
+				"--config-file=/assets/manifests/cluster-config.yaml",
+				"--dest-dir=/assets/mco-bootstrap",
+				"--pull-secret=/assets/manifests/pull.json",
+				"--etcd-image={{etcd}}",
+				"--setup-etcd-env-image={{setup-etcd-environment}}",
+				"--machine-config-controller-image={{machine-config-controller}}",
+				"--machine-config-server-image={{machine-config-server}}",
+				"--machine-config-daemon-image={{machine-config-daemon}}",
+				"--machine-config-oscontent-image={{machine-os-content}}",
+				"--infra-image={{pod}}",
+			},
+			copies: map[string]string{"manifests": "manifests"},
+		},
+	}
+}
+
+// Render runs bootkube.sh's render steps in dir, which holds the
+// extracted assets, copying their output into its bootstrap-manifests and
+// manifests directories as bootkube.sh does.
+func Render(ctx context.Context, dir string, opts Options) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	authFile := filepath.Join(dir, "preview-auth.json")
+	if err := fileaudit.WriteFile(authFile, []byte(opts.PullSecret), 0600, "bootstrap preview pull secret"); err != nil {
+		return err
+	}
+	defer func() {
+		os.Remove(authFile)
+		fileaudit.Record(fileaudit.Remove, authFile, "bootstrap preview pull secret", nil)
+	}()
+
+	logrus.Infof("Pulling release image %s...", opts.ReleaseImage)
+	if _, err := run(ctx, Runtime, "pull", "--quiet", "--authfile", authFile, opts.ReleaseImage); err != nil {
+		return errors.Wrap(err, "failed to pull the release image")
+	}
+	images := map[string]string{}
+	image := func(name string) (string, error) {
+		if name == "" {
+			return opts.ReleaseImage, nil
+		}
+		if pullSpec, ok := images[name]; ok {
+			return pullSpec, nil
+		}
+		out, err := run(ctx, Runtime, "run", "--quiet", "--rm", opts.ReleaseImage, "image", name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to find the %s image in the release", name)
+		}
+		pullSpec := strings.TrimSpace(string(out))
+		if pullSpec == "" {
+			return "", errors.Errorf("the release has no %s image", name)
+		}
+		images[name] = pullSpec
+		return pullSpec, nil
+	}
+
+	for _, s := range steps(opts) {
+		logrus.Infof("Rendering %s...", s.name)
+		stepImage, err := image(s.image)
+		if err != nil {
+			return err
+		}
+		// As root, so the output is readable with rootless podman
+		// whichever user the image runs as.
+		args := []string{"run", "--quiet", "--rm", "--user", "0", "--authfile", authFile, "--volume", dir + ":/assets:z", stepImage}
+		for _, arg := range s.args {
+			if i := strings.Index(arg, "{{"); i >= 0 && strings.HasSuffix(arg, "}}") {
+				pullSpec, err := image(arg[i+2 : len(arg)-2])
+				if err != nil {
+					return err
+				}
+				arg = arg[:i] + pullSpec
+			}
+			args = append(args, arg)
+		}
+		if _, err := run(ctx, Runtime, args...); err != nil {
+			return errors.Wrapf(err, "failed to render %s", s.name)
+		}
+		for from, to := range s.copies {
+			if err := copyDir(filepath.Join(dir, s.name, from), filepath.Join(dir, to)); err != nil {
+				return errors.Wrapf(err, "failed to copy the output of %s", s.name)
+			}
+		}
+	}
+	return nil
+}
+
+// copyDir copies the files in from into to.
+func copyDir(from, to string) error {
+	entries, err := ioutil.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(from, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := fileaudit.WriteFile(filepath.Join(to, entry.Name()), data, 0600, "bootstrap preview manifest"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requiredPods are the static pods cluster-bootstrap waits for, which the
+// render steps must produce.
+var requiredPods = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler", "cluster-version-operator"}
+
+// Verify checks that the installer's manifests extracted to dir, in its
+// manifests and openshift directories, are valid (see manifests.Lint).
+// Run it before Render, whose output need not be known to the installer.
+func Verify(dir string) error {
+	var files []*asset.File
+	for _, sub := range []string{"manifests", "openshift"} {
+		subFiles, err := readDir(filepath.Join(dir, sub), sub)
+		if err != nil {
+			return err
+		}
+		files = append(files, subFiles...)
+	}
+	return manifests.Lint(files)
+}
+
+// VerifyRendered checks the output of Render in dir: that the bootstrap
+// static pods cluster-bootstrap waits for are there, with their images
+// set.
+func VerifyRendered(dir string) error {
+	podFiles, err := readDir(filepath.Join(dir, "bootstrap-manifests"), "bootstrap-manifests")
+	if err != nil {
+		return err
+	}
+	var problems []string
+	found := map[string]bool{}
+	for _, file := range podFiles {
+		var pod struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Containers []struct {
+					Name  string `json:"name"`
+					Image string `json:"image"`
+				} `json:"containers"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal(file.Data, &pod); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", file.Filename, err))
+			continue
+		}
+		if pod.Kind != "Pod" {
+			continue
+		}
+		for _, name := range requiredPods {
+			if strings.Contains(pod.Metadata.Name, name) {
+				found[name] = true
+			}
+		}
+		if len(pod.Spec.Containers) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: pod %s has no containers", file.Filename, pod.Metadata.Name))
+		}
+		for _, c := range pod.Spec.Containers {
+			if c.Image == "" || strings.Contains(c.Image, "${") {
+				problems = append(problems, fmt.Sprintf("%s: container %s of pod %s has no image", file.Filename, c.Name, pod.Metadata.Name))
+			}
+		}
+	}
+	for _, name := range requiredPods {
+		if !found[name] {
+			problems = append(problems, fmt.Sprintf("bootstrap-manifests: no %s pod", name))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("invalid bootstrap manifests:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// readDir returns the files in dir, named below name.  A missing
+// directory has no files.
+func readDir(dir, name string) ([]*asset.File, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []*asset.File
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &asset.File{Filename: path.Join(name, entry.Name()), Data: data})
+	}
+	return files, nil
+}
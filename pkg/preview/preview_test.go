@@ -0,0 +1,171 @@
+package preview
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	igntypes "github.com/coreos/ignition/config/v2_2/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/asset"
+	"github.com/metalkube/kni-installer/pkg/asset/ignition"
+)
+
+const configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster-config-v1
+`
+
+func pod(name, image string) string {
+	return `apiVersion: v1
+kind: Pod
+metadata:
+  name: ` + name + `
+spec:
+  containers:
+  - name: ` + name + `
+    image: "` + image + `"
+`
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	served := ignition.FileFromString("/opt/openshift/openshift/99_openshift-cluster-api_master-user-data-secret.yaml", "root", 0600, "")
+	served.Contents.Source = "http://installer:8080/assets/master-user-data-secret.yaml"
+	config := &igntypes.Config{Storage: igntypes.Storage{Files: []igntypes.File{
+		ignition.FileFromString("/opt/openshift/manifests/cluster-config.yaml", "root", 0644, configMap),
+		served,
+		ignition.FileFromString("/usr/local/bin/bootkube.sh", "root", 0555, "#!/bin/sh\n"),
+	}}}
+	contents := []*asset.File{{Filename: "assets/master-user-data-secret.yaml", Data: []byte("served")}}
+
+	if !assert.NoError(t, Extract(config, contents, dir)) {
+		return
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "manifests", "cluster-config.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, configMap, string(data))
+	data, err = ioutil.ReadFile(filepath.Join(dir, "openshift", "99_openshift-cluster-api_master-user-data-secret.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "served", string(data))
+	_, err = os.Stat(filepath.Join(dir, "bootkube.sh"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Regexp(t, `^the contents of /opt/openshift/openshift/99_openshift-cluster-api_master-user-data-secret.yaml are neither embedded nor served by the installer$`, Extract(config, nil, dir))
+}
+
+func TestVerify(t *testing.T) {
+	cases := []struct {
+		name          string
+		files         map[string]string
+		expectedError string
+	}{
+		{
+			name:  "valid",
+			files: map[string]string{"manifests/cluster-config.yaml": configMap, "manifests/README": "not a manifest"},
+		},
+		{
+			name:          "invalid",
+			files:         map[string]string{"openshift/secret.yaml": "apiVersion: v1\nkind: Secret\n"},
+			expectedError: `^invalid manifests:\nopenshift/secret.yaml: metadata.name is required$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "preview")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			writeFiles(t, dir, tc.files)
+
+			err = Verify(dir)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// The render steps' output, as the stubbed podman would write it.
+	writeFiles(t, dir, map[string]string{
+		"cvo-bootstrap/bootstrap/cvo.yaml":                                     pod("bootstrap-cluster-version-operator", "quay.io/openshift/release"),
+		"cvo-bootstrap/manifests/0000_00_cluster-version-operator_00_ns.yaml":  configMap,
+		"config-bootstrap/manifests/config.yaml":                               configMap,
+		"kube-apiserver-bootstrap/bootstrap-manifests/kube-apiserver-pod.yaml": pod("bootstrap-kube-apiserver", "quay.io/openshift/hypershift"),
+		"kube-controller-manager-bootstrap/bootstrap-manifests/kcm-pod.yaml":   pod("bootstrap-kube-controller-manager", "quay.io/openshift/hyperkube"),
+		"kube-scheduler-bootstrap/bootstrap-manifests/scheduler-pod.yaml":      pod("bootstrap-kube-scheduler", ""),
+	})
+	for _, s := range steps(Options{}) {
+		for from := range s.copies {
+			if err := os.MkdirAll(filepath.Join(dir, s.name, from), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	defer func(r func(context.Context, string, ...string) ([]byte, error)) { run = r }(run)
+	var commands []string
+	run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		if len(args) == 6 && args[0] == "run" && args[3] == "quay.io/openshift/release" && args[4] == "image" {
+			return []byte("quay.io/openshift/" + args[5] + "\n"), nil
+		}
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return nil, nil
+	}
+
+	err = Render(context.Background(), dir, Options{
+		ReleaseImage: "quay.io/openshift/release",
+		EtcdCluster:  "https://etcd-0.example.com:2379",
+		PullSecret:   `{"auths":{}}`,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, commands, 7) {
+		assert.Equal(t, "podman pull --quiet --authfile "+filepath.Join(dir, "preview-auth.json")+" quay.io/openshift/release", commands[0])
+		assert.Contains(t, commands[3], " quay.io/openshift/cluster-kube-apiserver-operator /usr/bin/cluster-kube-apiserver-operator render ")
+		assert.Contains(t, commands[3], " --manifest-image=quay.io/openshift/hypershift ")
+		assert.Contains(t, commands[3], " --manifest-etcd-server-urls=https://etcd-0.example.com:2379 ")
+	}
+	_, err = os.Stat(filepath.Join(dir, "preview-auth.json"))
+	assert.True(t, os.IsNotExist(err), "the pull secret is removed")
+	_, err = os.Stat(filepath.Join(dir, "manifests", "config.yaml"))
+	assert.NoError(t, err)
+
+	assert.Regexp(t, `^invalid bootstrap manifests:\nbootstrap-manifests/scheduler-pod.yaml: container bootstrap-kube-scheduler of pod bootstrap-kube-scheduler has no image$`, VerifyRendered(dir))
+	writeFiles(t, dir, map[string]string{"bootstrap-manifests/scheduler-pod.yaml": pod("bootstrap-kube-scheduler", "quay.io/openshift/hyperkube")})
+	assert.NoError(t, VerifyRendered(dir))
+	os.Remove(filepath.Join(dir, "bootstrap-manifests", "kcm-pod.yaml"))
+	assert.Regexp(t, `^invalid bootstrap manifests:\nbootstrap-manifests: no kube-controller-manager pod$`, VerifyRendered(dir))
+}
@@ -0,0 +1,292 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+)
+
+const (
+	mediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCIIndex     = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest  = "application/vnd.oci.image.manifest.v1+json"
+)
+
+var (
+	// httpClient fetches from the registry.  Tests override it.
+	httpClient = http.DefaultClient
+)
+
+// reference is a parsed image pull spec.
+type reference struct {
+	registry   string
+	repository string
+	// tag is the tag or digest.
+	tag string
+}
+
+// manifest is the subset of a manifest list, OCI index or image manifest
+// which is needed to find the image's architectures and config.
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+		} `json:"platform"`
+	} `json:"manifests"`
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+// Architectures returns the CPU architectures the image can run on, sorted.
+// For a manifest list, they are those of its Linux manifests, and for a
+// single image, its own.  pullSecret holds the credentials for the image's
+// registry.
+func Architectures(ctx context.Context, image string, pullSecret string) ([]string, error) {
+	if err := offline.Check(ctx, "inspect the release image"); err != nil {
+		return nil, err
+	}
+	ref, err := parseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	client := &registryClient{ref: ref, auth: registryAuth(pullSecret, ref.registry)}
+
+	m, err := client.manifest(ctx, ref.tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch the manifest of %s", image)
+	}
+
+	switch m.MediaType {
+	case mediaTypeManifestList, mediaTypeOCIIndex:
+		seen := map[string]bool{}
+		var archs []string
+		for _, entry := range m.Manifests {
+			arch := entry.Platform.Architecture
+			if entry.Platform.OS != "linux" || seen[arch] {
+				continue
+			}
+			seen[arch] = true
+			archs = append(archs, arch)
+		}
+		sort.Strings(archs)
+		return archs, nil
+	case mediaTypeManifest, mediaTypeOCIManifest:
+		data, _, err := client.get(ctx, "blobs/"+m.Config.Digest, "")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch the image config of %s", image)
+		}
+		var config struct {
+			Architecture string `json:"architecture"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the image config of %s", image)
+		}
+		return []string{config.Architecture}, nil
+	default:
+		return nil, errors.Errorf("unsupported manifest type %q for %s", m.MediaType, image)
+	}
+}
+
+// parseReference parses an image pull spec, e.g.
+// quay.io/openshift-release-dev/ocp-release:4.1.0 or
+// registry.example.com:5000/ocp/release@sha256:...
+func parseReference(image string) (*reference, error) {
+	ref := &reference{registry: "registry-1.docker.io"}
+	name := image
+	if i := strings.Index(name, "/"); i >= 0 {
+		if first := name[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.registry = first
+			name = name[i+1:]
+		}
+	}
+	if ref.registry == "docker.io" {
+		ref.registry = "registry-1.docker.io"
+	}
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.repository, ref.tag = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i >= 0 {
+		ref.repository, ref.tag = name[:i], name[i+1:]
+	} else {
+		ref.repository, ref.tag = name, "latest"
+	}
+	if ref.repository == "" || ref.tag == "" {
+		return nil, errors.Errorf("invalid image %q", image)
+	}
+	if ref.registry == "registry-1.docker.io" && !strings.Contains(ref.repository, "/") {
+		ref.repository = "library/" + ref.repository
+	}
+	return ref, nil
+}
+
+// registryAuth returns the base64 user:password credentials for registry in
+// pullSecret, or "" if it has none.
+func registryAuth(pullSecret string, registry string) string {
+	var secret struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal([]byte(pullSecret), &secret); err != nil {
+		return ""
+	}
+	keys := []string{registry}
+	if registry == "registry-1.docker.io" {
+		keys = append(keys, "docker.io", "https://index.docker.io/v1/")
+	}
+	for _, key := range keys {
+		if auth, ok := secret.Auths[key]; ok {
+			return auth.Auth
+		}
+	}
+	return ""
+}
+
+// registryClient fetches from the v2 API of an image's registry,
+// authenticating with a bearer token or basic auth as the registry asks.
+type registryClient struct {
+	ref  *reference
+	auth string
+
+	// basic and bearer are set once the registry has challenged the
+	// client.
+	basic  bool
+	bearer string
+}
+
+// get fetches path below the image's repository, returning the body and its
+// content type.
+func (c *registryClient) get(ctx context.Context, path string, accept string) ([]byte, string, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/%s", c.ref.registry, c.ref.repository, path)
+	resp, err := c.do(ctx, u, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && !c.basic && c.bearer == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.authenticate(ctx, challenge); err != nil {
+			return nil, "", err
+		}
+		if resp, err = c.do(ctx, u, accept); err != nil {
+			return nil, "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", errors.Errorf("%s: %s", u, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to read HTTP response")
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (c *registryClient) do(ctx context.Context, u string, accept string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	switch {
+	case c.basic:
+		req.Header.Set("Authorization", "Basic "+c.auth)
+	case c.bearer != "":
+		req.Header.Set("Authorization", "Bearer "+c.bearer)
+	}
+	return httpClient.Do(req.WithContext(ctx))
+}
+
+// authenticate answers a WWW-Authenticate challenge.
+func (c *registryClient) authenticate(ctx context.Context, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.auth == "" {
+			return errors.Errorf("no credentials for %s in the pull secret", c.ref.registry)
+		}
+		c.basic = true
+		return nil
+	case "bearer":
+	default:
+		return errors.Errorf("unsupported authentication challenge %q from %s", challenge, c.ref.registry)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return errors.Errorf("invalid authentication realm %q from %s", params["realm"], c.ref.registry)
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", c.ref.repository))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.auth != "" {
+		req.Header.Set("Authorization", "Basic "+c.auth)
+	}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch a registry token")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to fetch a registry token from %s: %s", realm.Host, resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return errors.Wrap(err, "failed to parse the registry token")
+	}
+	c.bearer = token.Token
+	if c.bearer == "" {
+		c.bearer = token.AccessToken
+	}
+	if c.bearer == "" {
+		return errors.Errorf("no registry token from %s", realm.Host)
+	}
+	return nil
+}
+
+// parseChallenge parses a WWW-Authenticate header of the form
+// Bearer realm="...",service="...".
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+	for _, param := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return parts[0], params
+}
@@ -0,0 +1,134 @@
+package release
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected reference
+	}{
+		{
+			image:    "quay.io/openshift-release-dev/ocp-release:4.1.0",
+			expected: reference{registry: "quay.io", repository: "openshift-release-dev/ocp-release", tag: "4.1.0"},
+		},
+		{
+			image:    "registry.example.com:5000/ocp/release@sha256:0123",
+			expected: reference{registry: "registry.example.com:5000", repository: "ocp/release", tag: "sha256:0123"},
+		},
+		{
+			image:    "registry",
+			expected: reference{registry: "registry-1.docker.io", repository: "library/registry", tag: "latest"},
+		},
+		{
+			image:    "docker.io/openshift/origin-release:v4.0",
+			expected: reference{registry: "registry-1.docker.io", repository: "openshift/origin-release", tag: "v4.0"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			ref, err := parseReference(tc.image)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, *ref)
+			}
+		})
+	}
+}
+
+func TestArchitectures(t *testing.T) {
+	defer func(client *http.Client) { httpClient = client }(httpClient)
+
+	auth := base64.StdEncoding.EncodeToString([]byte("user:password"))
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.Header.Get("Authorization") != "Basic "+auth || r.URL.Query().Get("scope") != "repository:ocp/release:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token": "secret-token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/ocp/release/manifests/multi":
+			w.Header().Set("Content-Type", mediaTypeManifestList)
+			fmt.Fprint(w, `{
+  "mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+  "manifests": [
+    {"platform": {"architecture": "arm64", "os": "linux"}},
+    {"platform": {"architecture": "amd64", "os": "linux"}},
+    {"platform": {"architecture": "amd64", "os": "windows"}}
+  ]
+}`)
+		case "/v2/ocp/release/manifests/single":
+			w.Header().Set("Content-Type", mediaTypeManifest)
+			fmt.Fprint(w, `{"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "config": {"digest": "sha256:abcd"}}`)
+		case "/v2/ocp/release/blobs/sha256:abcd":
+			fmt.Fprint(w, `{"architecture": "amd64", "os": "linux"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	httpClient = server.Client()
+	registry := strings.TrimPrefix(server.URL, "https://")
+	pullSecret := fmt.Sprintf(`{"auths": {"%s": {"auth": "%s"}}}`, registry, auth)
+
+	cases := []struct {
+		name          string
+		tag           string
+		pullSecret    string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:       "manifest list",
+			tag:        "multi",
+			pullSecret: pullSecret,
+			expected:   []string{"amd64", "arm64"},
+		},
+		{
+			name:       "single image",
+			tag:        "single",
+			pullSecret: pullSecret,
+			expected:   []string{"amd64"},
+		},
+		{
+			name:          "missing",
+			tag:           "missing",
+			pullSecret:    pullSecret,
+			expectedError: `^failed to fetch the manifest of .*: 404 Not Found$`,
+		},
+		{
+			name:          "no credentials",
+			tag:           "multi",
+			pullSecret:    `{"auths": {}}`,
+			expectedError: `^failed to fetch the manifest of .*: failed to fetch a registry token from .*: 401 Unauthorized$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			archs, err := Architectures(context.Background(), fmt.Sprintf("%s/ocp/release:%s", registry, tc.tag), tc.pullSecret)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, archs)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package release picks the release image a cluster is installed from and
+// inspects release images in their registries.
+package release
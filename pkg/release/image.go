@@ -0,0 +1,28 @@
+package release
+
+import (
+	"os"
+
+	"github.com/metalkube/kni-installer/pkg/warnings"
+)
+
+// defaultImage is the release image the installer is built for.  It is
+// set with -ldflags by hack/build.sh's RELEASE_IMAGE.
+var defaultImage = "registry.svc.ci.openshift.org/openshift/origin-release:v4.0"
+
+// Image returns the release image the cluster is installed from: the
+// OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE override if it is set, or the
+// image the installer is built for.
+func Image() string {
+	return ImageFromEnv(os.LookupEnv)
+}
+
+// ImageFromEnv is Image with the environment looked up by lookupEnv, e.g.
+// the LookupEnv of asset sources.
+func ImageFromEnv(lookupEnv func(string) (string, bool)) string {
+	if ri, ok := lookupEnv("OPENSHIFT_INSTALL_RELEASE_IMAGE_OVERRIDE"); ok && ri != "" {
+		warnings.Warnf(warnings.Skew, "Found override for ReleaseImage. Please be warned, this is not advised")
+		return ri
+	}
+	return defaultImage
+}
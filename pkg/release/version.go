@@ -0,0 +1,120 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+)
+
+// versionLabel is the label of a release image carrying its version.
+const versionLabel = "io.openshift.release"
+
+// SupportedReleases are the minor releases, major.minor, whose manifests
+// the installer's templates are compatible with.
+var SupportedReleases = []string{"4.0", "4.1"}
+
+// Version returns the version of the release image, e.g. 4.1.0, from its
+// io.openshift.release label, or "" if it has none.  For a manifest list,
+// the label of the linux/amd64 image is used.  pullSecret holds the
+// credentials for the image's registry.
+func Version(ctx context.Context, image string, pullSecret string) (string, error) {
+	if err := offline.Check(ctx, "inspect the release image"); err != nil {
+		return "", err
+	}
+	ref, err := parseReference(image)
+	if err != nil {
+		return "", err
+	}
+	client := &registryClient{ref: ref, auth: registryAuth(pullSecret, ref.registry)}
+
+	m, err := client.manifest(ctx, ref.tag)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch the manifest of %s", image)
+	}
+	switch m.MediaType {
+	case mediaTypeManifestList, mediaTypeOCIIndex:
+		digest := ""
+		for _, entry := range m.Manifests {
+			if entry.Platform.OS == "linux" && entry.Platform.Architecture == "amd64" {
+				digest = entry.Digest
+				break
+			}
+		}
+		if digest == "" {
+			return "", errors.Errorf("%s has no linux/amd64 image", image)
+		}
+		if m, err = client.manifest(ctx, digest); err != nil {
+			return "", errors.Wrapf(err, "failed to fetch the linux/amd64 manifest of %s", image)
+		}
+	}
+
+	data, _, err := client.get(ctx, "blobs/"+m.Config.Digest, "")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to fetch the image config of %s", image)
+	}
+	var config struct {
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", errors.Wrapf(err, "failed to parse the image config of %s", image)
+	}
+	return config.Config.Labels[versionLabel], nil
+}
+
+// manifest fetches the manifest list, OCI index or image manifest with the
+// tag or digest.
+func (c *registryClient) manifest(ctx context.Context, tag string) (*manifest, error) {
+	data, mediaType, err := c.get(ctx, "manifests/"+tag, strings.Join([]string{mediaTypeManifestList, mediaTypeOCIIndex, mediaTypeManifest, mediaTypeOCIManifest}, ", "))
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the manifest")
+	}
+	if m.MediaType == "" {
+		m.MediaType = mediaType
+	}
+	return m, nil
+}
+
+// CheckSkew returns an error if version, a release image's version, is not
+// one of the SupportedReleases.  Pre-release and build suffixes are
+// ignored.
+func CheckSkew(version string) error {
+	minor, err := minorRelease(version)
+	if err != nil {
+		return err
+	}
+	for _, supported := range SupportedReleases {
+		if minor == supported {
+			return nil
+		}
+	}
+	return errors.Errorf("release %s is outside the releases this installer supports (%s); use an installer built for %s", version, strings.Join(SupportedReleases, ", "), minor)
+}
+
+// minorRelease returns major.minor of version, e.g. 4.1 for 4.1.0-rc.3.
+func minorRelease(version string) (string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return "", errors.Errorf("invalid release version %q", version)
+	}
+	minor := parts[1]
+	if i := strings.IndexAny(minor, "-+"); i >= 0 {
+		minor = minor[:i]
+	}
+	for _, part := range []string{parts[0], minor} {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return "", errors.Errorf("invalid release version %q", version)
+		}
+	}
+	return parts[0] + "." + minor, nil
+}
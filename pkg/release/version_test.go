@@ -0,0 +1,93 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	defer func(client *http.Client) { httpClient = client }(httpClient)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/ocp/release/manifests/multi":
+			w.Header().Set("Content-Type", mediaTypeManifestList)
+			fmt.Fprint(w, `{"manifests": [
+  {"digest": "sha256:arm64", "platform": {"architecture": "arm64", "os": "linux"}},
+  {"digest": "sha256:amd64", "platform": {"architecture": "amd64", "os": "linux"}}
+]}`)
+		case "/v2/ocp/release/manifests/arm64-only":
+			w.Header().Set("Content-Type", mediaTypeManifestList)
+			fmt.Fprint(w, `{"manifests": [{"digest": "sha256:arm64", "platform": {"architecture": "arm64", "os": "linux"}}]}`)
+		case "/v2/ocp/release/manifests/sha256:amd64", "/v2/ocp/release/manifests/single":
+			w.Header().Set("Content-Type", mediaTypeManifest)
+			fmt.Fprint(w, `{"config": {"digest": "sha256:config"}}`)
+		case "/v2/ocp/release/manifests/unlabeled":
+			w.Header().Set("Content-Type", mediaTypeManifest)
+			fmt.Fprint(w, `{"config": {"digest": "sha256:unlabeled"}}`)
+		case "/v2/ocp/release/blobs/sha256:config":
+			fmt.Fprint(w, `{"architecture": "amd64", "config": {"Labels": {"io.openshift.release": "4.1.0"}}}`)
+		case "/v2/ocp/release/blobs/sha256:unlabeled":
+			fmt.Fprint(w, `{"architecture": "amd64", "config": {}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	httpClient = server.Client()
+	registry := strings.TrimPrefix(server.URL, "https://")
+
+	cases := []struct {
+		tag           string
+		expected      string
+		expectedError string
+	}{
+		{tag: "multi", expected: "4.1.0"},
+		{tag: "single", expected: "4.1.0"},
+		{tag: "unlabeled", expected: ""},
+		{tag: "arm64-only", expectedError: `^.*/ocp/release:arm64-only has no linux/amd64 image$`},
+		{tag: "missing", expectedError: `^failed to fetch the manifest of .*: 404 Not Found$`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.tag, func(t *testing.T) {
+			version, err := Version(context.Background(), fmt.Sprintf("%s/ocp/release:%s", registry, tc.tag), "{}")
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, version)
+		})
+	}
+}
+
+func TestCheckSkew(t *testing.T) {
+	cases := []struct {
+		version       string
+		expectedError string
+	}{
+		{version: "4.1.0"},
+		{version: "4.0.0-0.ci-2019-04-17-133604"},
+		{version: "v4.1"},
+		{version: "4.1.0-rc.3"},
+		{version: "4.2.0", expectedError: `^release 4\.2\.0 is outside the releases this installer supports \(4\.0, 4\.1\); use an installer built for 4\.2$`},
+		{version: "3.11.0", expectedError: `^release 3\.11\.0 is outside the releases this installer supports`},
+		{version: "latest", expectedError: `^invalid release version "latest"$`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.version, func(t *testing.T) {
+			err := CheckSkew(tc.version)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
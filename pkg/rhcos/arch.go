@@ -0,0 +1,25 @@
+package rhcos
+
+import (
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// archNames are the names RHCOS gives the architectures it is built for,
+// where they differ from the Go names.
+var archNames = map[types.Architecture]string{
+	types.ArchitectureARM64: "aarch64",
+}
+
+// ArchChannel returns the channel of the RHCOS builds for arch.  The amd64
+// builds are published in channel itself, and those for other architectures
+// in channel suffixed with the architecture, e.g. maipo-aarch64.
+func ArchChannel(channel string, arch types.Architecture) string {
+	if arch == "" || arch == types.ArchitectureAMD64 {
+		return channel
+	}
+	name, ok := archNames[arch]
+	if !ok {
+		name = string(arch)
+	}
+	return channel + "-" + name
+}
@@ -9,6 +9,9 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/download"
+	"github.com/metalkube/kni-installer/pkg/offline"
 )
 
 var (
@@ -36,7 +39,16 @@ type metadata struct {
 	OSTreeVersion string `json:"ostree-version"`
 }
 
+// URLs returns uri followed by its location on each of the configured RHCOS
+// mirrors, which replace the release storage base URL.
+func URLs(uri string) []string {
+	return download.URLs(uri, baseURL)
+}
+
 func fetchLatestMetadata(ctx context.Context, channel string) (metadata, error) {
+	if err := offline.Check(ctx, "fetch RHCOS metadata"); err != nil {
+		return metadata{}, err
+	}
 	build := buildName
 	var err error
 	if build == "" {
@@ -48,22 +60,12 @@ func fetchLatestMetadata(ctx context.Context, channel string) (metadata, error)
 
 	url := fmt.Sprintf("%s/%s/%s/meta.json", baseURL, channel, build)
 	logrus.Debugf("Fetching RHCOS metadata from %q", url)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return metadata{}, errors.Wrap(err, "failed to build request")
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req.WithContext(ctx))
+	resp, err := download.Get(ctx, &http.Client{}, URLs(url))
 	if err != nil {
 		return metadata{}, errors.Wrapf(err, "failed to fetch metadata for build %s", build)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return metadata{}, errors.Errorf("incorrect HTTP response (%s)", resp.Status)
-	}
-
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return metadata{}, errors.Wrap(err, "failed to read HTTP response")
@@ -78,24 +80,17 @@ func fetchLatestMetadata(ctx context.Context, channel string) (metadata, error)
 }
 
 func fetchLatestBuild(ctx context.Context, channel string) (string, error) {
+	if err := offline.Check(ctx, "fetch the RHCOS build list"); err != nil {
+		return "", err
+	}
 	url := fmt.Sprintf("%s/%s/builds.json", baseURL, channel)
 	logrus.Debugf("Fetching RHCOS builds from %q", url)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to build request")
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req.WithContext(ctx))
+	resp, err := download.Get(ctx, &http.Client{}, URLs(url))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to fetch builds")
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", errors.Errorf("incorrect HTTP response (%s)", resp.Status)
-	}
-
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to read HTTP response")
@@ -0,0 +1,205 @@
+// Package customize embeds Ignition configs and first-boot kernel arguments
+// into RHCOS images, so hosts booted from virtual media or USB sticks need
+// neither PXE nor DHCP to find their configuration.
+//
+// ISO images are customized with coreos-installer and disk images (e.g.
+// qcow2) with guestfish, both of which must be installed on the installer
+// host.
+package customize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// Customization is what is embedded into an image.
+type Customization struct {
+	// Ignition is the config the host boots with, usually a pointer
+	// config referencing the machine config server.
+	Ignition []byte
+
+	// KernelArgs are appended to the kernel command line of the first
+	// boot, e.g. to configure the network before Ignition runs.
+	KernelArgs []string
+}
+
+// run runs an external command, returning its output.  Tests override it.
+var run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	logrus.Debugf("Running %s %s", name, strings.Join(args, " "))
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return out, errors.Wrapf(err, "%s failed: %s", name, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// Image writes a copy of the RHCOS image src, with the customization
+// embedded, to dst.  Images with an ISO 9660 file system are treated as
+// live ISOs and anything else as a disk image, whatever their names.
+func Image(ctx context.Context, src, dst string, c Customization) error {
+	tmpDir, err := ioutil.TempDir("", "kni-install-customize")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		os.RemoveAll(tmpDir)
+		fileaudit.Record(fileaudit.Remove, tmpDir, "image customization working directory", nil)
+	}()
+
+	ignition := filepath.Join(tmpDir, "config.ign")
+	if err := fileaudit.WriteFile(ignition, c.Ignition, 0600, "Ignition config to embed"); err != nil {
+		return errors.Wrap(err, "failed to write Ignition config")
+	}
+
+	ext, err := Extension(src)
+	if err != nil {
+		return err
+	}
+	if ext == ".iso" {
+		err = customizeISO(ctx, src, dst, ignition, c.KernelArgs)
+	} else {
+		err = customizeDisk(ctx, src, dst, ignition, tmpDir, c.KernelArgs)
+	}
+	if err == nil {
+		fileaudit.Record(fileaudit.Read, src, "RHCOS image to customize", nil)
+		fileaudit.RecordFile(fileaudit.Write, dst, "customized RHCOS image")
+	}
+	return err
+}
+
+// Extension returns the file name extension of the format of the image at
+// path, detected from its content: .iso for live ISOs, .qcow2 for qcow2
+// disk images, .gz or .xz for compressed images and .img for anything
+// else.  Cached images have no extension, so their names cannot be
+// trusted.
+func Extension(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open image")
+	}
+	defer f.Close()
+
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(f, header); err != nil && err != io.ErrUnexpectedEOF {
+		return "", errors.Wrapf(err, "failed to read %s", path)
+	}
+	switch {
+	case bytes.HasPrefix(header, []byte("QFI\xfb")):
+		return ".qcow2", nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return ".gz", nil
+	case bytes.HasPrefix(header, []byte("\xfd7zXZ\x00")):
+		return ".xz", nil
+	}
+
+	// The ISO 9660 volume descriptors start in the 17th 2048-byte sector.
+	magic := make([]byte, 5)
+	if _, err := f.ReadAt(magic, 0x8001); err != nil && err != io.EOF {
+		return "", errors.Wrapf(err, "failed to read %s", path)
+	}
+	if string(magic) == "CD001" {
+		return ".iso", nil
+	}
+	return ".img", nil
+}
+
+func customizeISO(ctx context.Context, src, dst, ignition string, kernelArgs []string) error {
+	if _, err := run(ctx, "coreos-installer", "iso", "ignition", "embed", "--force", "--ignition-file", ignition, "--output", dst, src); err != nil {
+		return errors.Wrap(err, "failed to embed Ignition config")
+	}
+	if len(kernelArgs) == 0 {
+		return nil
+	}
+	args := []string{"iso", "kargs", "modify"}
+	for _, arg := range kernelArgs {
+		args = append(args, "--append", arg)
+	}
+	if _, err := run(ctx, "coreos-installer", append(args, dst)...); err != nil {
+		return errors.Wrap(err, "failed to add kernel arguments")
+	}
+	return nil
+}
+
+// customizeDisk writes the Ignition config to /ignition/config.ign on the
+// image's boot partition, where the metal platform looks for it, and the
+// kernel arguments to /ignition.firstboot, which the boot loader only
+// reads on the first boot.
+func customizeDisk(ctx context.Context, src, dst, ignition, tmpDir string, kernelArgs []string) error {
+	if err := copyFile(src, dst); err != nil {
+		return errors.Wrap(err, "failed to copy image")
+	}
+
+	out, err := run(ctx, "guestfish", "--ro", "-a", dst, "run", ":", "findfs-label", "boot")
+	if err != nil {
+		return errors.Wrap(err, "failed to find the boot partition")
+	}
+	boot := strings.TrimSpace(string(out))
+
+	firstboot := filepath.Join(tmpDir, "ignition.firstboot")
+	content := fmt.Sprintf("set ignition_network_kcmdline='%s'\n", strings.Join(kernelArgs, " "))
+	if err := fileaudit.WriteFile(firstboot, []byte(content), 0644, "first-boot kernel arguments to embed"); err != nil {
+		return err
+	}
+
+	_, err = run(ctx, "guestfish", "--rw", "-a", dst,
+		"run",
+		":", "mount", boot, "/",
+		":", "mkdir-p", "/ignition",
+		":", "upload", ignition, "/ignition/config.ign",
+		":", "upload", firstboot, "/ignition.firstboot",
+	)
+	return errors.Wrap(err, "failed to write to the boot partition")
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// HostKernelArgs returns the first-boot kernel arguments for a host: its
+// static network configuration in dracut's syntax, if any, followed by its
+// own kernel arguments.
+func HostKernelArgs(host *baremetal.Host) ([]string, error) {
+	var args []string
+	if n := host.Network; n != nil {
+		ip, ipNet, err := net.ParseCIDR(n.Address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid address for host %s", host.Name)
+		}
+		args = append(args,
+			"rd.neednet=1",
+			fmt.Sprintf("ip=%s::%s:%s:%s:%s:none", ip, n.Gateway, net.IP(ipNet.Mask), host.Name, n.Interface),
+		)
+		for _, dns := range n.DNS {
+			args = append(args, fmt.Sprintf("nameserver=%s", dns))
+		}
+	}
+	return append(args, host.KernelArgs...), nil
+}
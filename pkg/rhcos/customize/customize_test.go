@@ -0,0 +1,140 @@
+package customize
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// fakeRun records the commands run instead of running them.
+func fakeRun(commands *[]string, output string) func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		*commands = append(*commands, name+" "+strings.Join(args, " "))
+		return []byte(output), nil
+	}
+}
+
+// isoImage returns the start of an ISO 9660 image, up to its primary volume
+// descriptor.
+func isoImage() []byte {
+	data := make([]byte, 0x8800)
+	data[0x8000] = 1
+	copy(data[0x8001:], "CD001")
+	return data
+}
+
+func TestImageISO(t *testing.T) {
+	defer func(r func(context.Context, string, ...string) ([]byte, error)) { run = r }(run)
+	var commands []string
+	run = fakeRun(&commands, "")
+
+	dir, err := ioutil.TempDir("", "kni-install-customize-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "rhcos")
+	if err := ioutil.WriteFile(src, isoImage(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Image(context.Background(), src, "out.iso", Customization{
+		Ignition:   []byte("{}"),
+		KernelArgs: []string{"console=ttyS0", "rd.neednet=1"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, commands, 2) {
+		assert.Regexp(t, `^coreos-installer iso ignition embed --force --ignition-file /.*/config\.ign --output out\.iso `+src+`$`, commands[0])
+		assert.Equal(t, "coreos-installer iso kargs modify --append console=ttyS0 --append rd.neednet=1 out.iso", commands[1])
+	}
+}
+
+func TestExtension(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kni-install-customize-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{name: "iso", data: isoImage(), expected: ".iso"},
+		{name: "qcow2", data: []byte("QFI\xfb\x00\x00\x00\x03"), expected: ".qcow2"},
+		{name: "gzip", data: []byte{0x1f, 0x8b, 0x08, 0x00}, expected: ".gz"},
+		{name: "xz", data: []byte("\xfd7zXZ\x00\x00"), expected: ".xz"},
+		{name: "raw", data: []byte("image"), expected: ".img"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			if err := ioutil.WriteFile(path, tc.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			ext, err := Extension(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, ext)
+		})
+	}
+}
+
+func TestImageDisk(t *testing.T) {
+	defer func(r func(context.Context, string, ...string) ([]byte, error)) { run = r }(run)
+	var commands []string
+	run = fakeRun(&commands, "/dev/sda1\n")
+
+	dir, err := ioutil.TempDir("", "kni-install-customize-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "rhcos.qcow2")
+	dst := filepath.Join(dir, "host.qcow2")
+	if err := ioutil.WriteFile(src, []byte("image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Image(context.Background(), src, dst, Customization{Ignition: []byte("{}")})
+	assert.NoError(t, err)
+	data, err := ioutil.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "image", string(data))
+	if assert.Len(t, commands, 2) {
+		assert.Equal(t, "guestfish --ro -a "+dst+" run : findfs-label boot", commands[0])
+		assert.Regexp(t, "^guestfish --rw -a "+dst+" run : mount /dev/sda1 / : mkdir-p /ignition : upload .*/config.ign /ignition/config.ign : upload .*/ignition.firstboot /ignition.firstboot$", commands[1])
+	}
+}
+
+func TestHostKernelArgs(t *testing.T) {
+	args, err := HostKernelArgs(&baremetal.Host{
+		Name: "master-0",
+		Network: &baremetal.HostNetwork{
+			Interface: "ens3",
+			Address:   "192.168.111.20/24",
+			Gateway:   "192.168.111.1",
+			DNS:       []string{"192.168.111.1", "8.8.8.8"},
+		},
+		KernelArgs: []string{"console=ttyS0"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"rd.neednet=1",
+		"ip=192.168.111.20::192.168.111.1:255.255.255.0:master-0:ens3:none",
+		"nameserver=192.168.111.1",
+		"nameserver=8.8.8.8",
+		"console=ttyS0",
+	}, args)
+
+	args, err = HostKernelArgs(&baremetal.Host{Name: "worker-0"})
+	assert.NoError(t, err)
+	assert.Empty(t, args)
+}
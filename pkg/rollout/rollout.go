@@ -0,0 +1,185 @@
+// Package rollout brings the MachineSets of compute pools up in waves
+// during the install, rather than all at once, to limit the load large
+// installs put on BMCs, PXE and image servers.
+//
+// The installer generates the MachineSets of a pool with a rollout policy
+// scaled to its first wave, and annotated with their target replicas.
+// Once the cluster is initialized, the Pools returned by List scale them
+// up a wave at a time, waiting for the machines of each wave to become
+// ready.
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// PoolAnnotation names the compute pool of a MachineSet rolled out in
+	// waves.
+	PoolAnnotation = "kni.openshift.io/rollout-pool"
+
+	// WaveSizeAnnotation is the wave size of the pool of a MachineSet
+	// rolled out in waves.
+	WaveSizeAnnotation = "kni.openshift.io/rollout-wave-size"
+
+	// TargetReplicasAnnotation is the number of replicas a MachineSet
+	// rolled out in waves is finally scaled to.
+	TargetReplicasAnnotation = "kni.openshift.io/rollout-target-replicas"
+
+	namespace = "openshift-machine-api"
+
+	// pollInterval is how often the readiness of a wave is checked.
+	pollInterval = 15 * time.Second
+)
+
+var machineSets = schema.GroupVersionResource{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machinesets"}
+
+// NextWave returns the replicas of a pool's MachineSets after adding a wave
+// of up to waveSize machines to current, spread round-robin across the
+// MachineSets which are below their targets.
+func NextWave(current, targets []int64, waveSize int64) []int64 {
+	next := append([]int64{}, current...)
+	for added := int64(0); added < waveSize; {
+		progress := false
+		for i := range next {
+			if added == waveSize {
+				break
+			}
+			if next[i] < targets[i] {
+				next[i]++
+				added++
+				progress = true
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+	return next
+}
+
+// Pool is the MachineSets of a compute pool rolled out in waves.
+type Pool struct {
+	// Name is the name of the compute pool.
+	Name string
+
+	waveSize int64
+	sets     []string
+	targets  []int64
+}
+
+// List returns the pools with MachineSets annotated for a rollout, by
+// name.
+func List(client dynamic.Interface) ([]*Pool, error) {
+	list, err := client.Resource(machineSets).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list MachineSets")
+	}
+	byName := map[string]*Pool{}
+	var names []string
+	for _, set := range list.Items {
+		annotations := set.GetAnnotations()
+		name, ok := annotations[PoolAnnotation]
+		if !ok {
+			continue
+		}
+		waveSize, err := strconv.ParseInt(annotations[WaveSizeAnnotation], 10, 64)
+		if err != nil || waveSize < 1 {
+			return nil, errors.Errorf("MachineSet %s has an invalid %s annotation %q", set.GetName(), WaveSizeAnnotation, annotations[WaveSizeAnnotation])
+		}
+		target, err := strconv.ParseInt(annotations[TargetReplicasAnnotation], 10, 64)
+		if err != nil || target < 0 {
+			return nil, errors.Errorf("MachineSet %s has an invalid %s annotation %q", set.GetName(), TargetReplicasAnnotation, annotations[TargetReplicasAnnotation])
+		}
+		p, ok := byName[name]
+		if !ok {
+			p = &Pool{Name: name, waveSize: waveSize}
+			byName[name] = p
+			names = append(names, name)
+		}
+		p.sets = append(p.sets, set.GetName())
+		p.targets = append(p.targets, target)
+	}
+	sort.Strings(names)
+	pools := make([]*Pool, 0, len(names))
+	for _, name := range names {
+		pools = append(pools, byName[name])
+	}
+	return pools, nil
+}
+
+// Run scales the pool's MachineSets up to their targets a wave at a time,
+// waiting up to waveTimeout for the machines of each wave to become ready.
+func (p *Pool) Run(ctx context.Context, client dynamic.Interface, waveTimeout time.Duration) error {
+	resource := client.Resource(machineSets).Namespace(namespace)
+	for {
+		current := make([]int64, len(p.sets))
+		err := waitFor(ctx, waveTimeout, func() (bool, error) {
+			ready := true
+			for i, name := range p.sets {
+				set, err := resource.Get(name, metav1.GetOptions{})
+				if err != nil {
+					logrus.Debugf("Failed to get MachineSet %s: %v", name, err)
+					return false, nil
+				}
+				replicas, readyReplicas := setReplicas(set)
+				current[i] = replicas
+				if readyReplicas < replicas {
+					ready = false
+				}
+			}
+			return ready, nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "the machines of compute pool %s did not become ready", p.Name)
+		}
+
+		next := NextWave(current, p.targets, p.waveSize)
+		total, added := int64(0), int64(0)
+		for i := range next {
+			total += next[i]
+			added += next[i] - current[i]
+		}
+		if added == 0 {
+			logrus.Infof("Compute pool %s is rolled out", p.Name)
+			return nil
+		}
+		logrus.Infof("Rolling out %d more machines of compute pool %s, %d in total...", added, p.Name, total)
+		for i, name := range p.sets {
+			if next[i] == current[i] {
+				continue
+			}
+			patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, next[i]))
+			if _, err := resource.Patch(name, types.MergePatchType, patch, metav1.UpdateOptions{}); err != nil {
+				return errors.Wrapf(err, "failed to scale MachineSet %s", name)
+			}
+		}
+	}
+}
+
+// setReplicas returns the desired and ready replicas of a MachineSet.
+func setReplicas(set *unstructured.Unstructured) (int64, int64) {
+	replicas, _, _ := unstructured.NestedInt64(set.Object, "spec", "replicas")
+	readyReplicas, _, _ := unstructured.NestedInt64(set.Object, "status", "readyReplicas")
+	return replicas, readyReplicas
+}
+
+// waitFor polls condition until it is true, timeout passes or ctx is done.
+func waitFor(ctx context.Context, timeout time.Duration, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(pollInterval, condition, ctx.Done())
+}
@@ -0,0 +1,60 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextWave(t *testing.T) {
+	cases := []struct {
+		name     string
+		current  []int64
+		targets  []int64
+		waveSize int64
+		expected []int64
+	}{
+		{
+			name:     "first wave of one set",
+			current:  []int64{0},
+			targets:  []int64{50},
+			waveSize: 10,
+			expected: []int64{10},
+		},
+		{
+			name:     "spread across sets",
+			current:  []int64{0, 0, 0},
+			targets:  []int64{20, 20, 20},
+			waveSize: 10,
+			expected: []int64{4, 3, 3},
+		},
+		{
+			name:     "sets at their targets",
+			current:  []int64{2, 5, 0},
+			targets:  []int64{2, 20, 20},
+			waveSize: 10,
+			expected: []int64{2, 10, 5},
+		},
+		{
+			name:     "last wave",
+			current:  []int64{18, 20},
+			targets:  []int64{20, 20},
+			waveSize: 10,
+			expected: []int64{20, 20},
+		},
+		{
+			name:     "rolled out",
+			current:  []int64{20, 20},
+			targets:  []int64{20, 20},
+			waveSize: 10,
+			expected: []int64{20, 20},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			current := append([]int64{}, tc.current...)
+			assert.Equal(t, tc.expected, NextWave(current, tc.targets, tc.waveSize))
+			assert.Equal(t, tc.current, current, "current is not modified")
+		})
+	}
+}
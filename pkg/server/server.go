@@ -0,0 +1,483 @@
+// Package server exposes the installer as a long-running REST service, so
+// provisioning portals can drive installs without shelling out to the CLI
+// and scraping its output.
+//
+// Each install runs in its own asset directory under the server's base
+// directory, in a child kni-install process, so a failing install cannot
+// take the service down with it.  The status of each cluster is kept in
+// its asset directory too, so a restarted server still knows, and can
+// destroy, the clusters it created.
+//
+// Clients authenticate with a bearer token.  Only the bootstrap content,
+// which the bootstrap node fetches by digest, is served without it.
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/metalkube/kni-installer/pkg/asset/ignition/bootstrap"
+	"github.com/metalkube/kni-installer/pkg/credentials"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const (
+	installConfigFilename = "install-config.yaml"
+	logFilename           = ".openshift_install.log"
+	pollInterval          = time.Second
+
+	// statusFilename is where the status of a cluster is kept in its
+	// asset directory, so a restarted server knows its clusters.
+	statusFilename = ".kni-install-server.json"
+
+	// maxInstallConfigSize is the largest install config accepted.
+	maxInstallConfigSize = 1024 * 1024
+
+	// outputLimit is how much of the end of an operation's output is kept
+	// for its error.  All of it is in the install log.
+	outputLimit = 64 * 1024
+)
+
+// artifacts are the files of an asset directory clients may fetch.  The
+// rest, such as keys, Ignition configs and Terraform state, stay on the
+// server.
+var artifacts = map[string]bool{
+	"metadata.json":           true,
+	"auth/kubeconfig":         true,
+	"auth/kubeadmin-password": true,
+}
+
+// State is the state of a cluster's most recent operation.
+type State string
+
+const (
+	// Running means the operation is still in progress.
+	Running State = "running"
+	// Succeeded means the operation completed successfully.
+	Succeeded State = "succeeded"
+	// Failed means the operation exited with an error.
+	Failed State = "failed"
+)
+
+// Cluster is the status of a cluster managed by the server.
+type Cluster struct {
+	ID        string     `json:"id"`
+	Operation string     `json:"operation"`
+	State     State      `json:"state"`
+	Error     string     `json:"error,omitempty"`
+	Started   time.Time  `json:"started"`
+	Finished  *time.Time `json:"finished,omitempty"`
+}
+
+// Server runs installer operations on behalf of REST clients.
+type Server struct {
+	// BaseDir holds one asset directory per cluster.
+	BaseDir string
+
+	// Command returns the command to run kni-install with args in dir.
+	Command func(dir string, args ...string) *exec.Cmd
+
+	// Token is the bearer token clients must send.
+	Token string
+
+	lock     sync.Mutex
+	clusters map[string]*Cluster
+}
+
+// New returns a Server which runs executable for each operation, for
+// clients which send token, with the clusters already in baseDir.
+func New(baseDir, executable, token string) (*Server, error) {
+	s := &Server{
+		BaseDir: baseDir,
+		Token:   token,
+		Command: func(dir string, args ...string) *exec.Cmd {
+			return exec.Command(executable, append([]string{"--dir", dir, "--log-level", "debug"}, args...)...)
+		},
+		clusters: map[string]*Cluster{},
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads the status of the clusters in the base directory.  An
+// operation which was running when the server stopped is recorded as
+// failed, as its process did not outlive the server.
+func (s *Server) load() error {
+	entries, err := ioutil.ReadDir(s.BaseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to read the base directory")
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(s.dir(entry.Name()), statusFilename))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to read the status of cluster %s", entry.Name())
+		}
+		cluster := &Cluster{}
+		if err := json.Unmarshal(data, cluster); err != nil {
+			return errors.Wrapf(err, "failed to parse the status of cluster %s", entry.Name())
+		}
+		cluster.ID = entry.Name()
+		if cluster.State == Running {
+			now := time.Now()
+			cluster.State = Failed
+			cluster.Error = "interrupted by a restart of the server"
+			cluster.Finished = &now
+			s.save(cluster)
+		}
+		s.clusters[cluster.ID] = cluster
+	}
+	logrus.Debugf("Loaded %d clusters from %s", len(s.clusters), s.BaseDir)
+	return nil
+}
+
+// save writes the status of cluster to its asset directory.  Failing to
+// only loses the status on a restart, so it is logged.
+func (s *Server) save(cluster *Cluster) {
+	data, err := json.Marshal(cluster)
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(s.dir(cluster.ID), statusFilename), data, 0644)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to save the status of cluster %s: %v", cluster.ID, err)
+	}
+}
+
+// ServeHTTP routes:
+//
+//	GET  /v1/clusters                        list clusters
+//	POST /v1/clusters                        create a cluster from an install-config body
+//	GET  /v1/clusters/{id}                   get the status of a cluster
+//	GET  /v1/clusters/{id}/log               stream the install log until the operation finishes
+//	GET  /v1/clusters/{id}/artifacts/{path}  fetch metadata.json, auth/kubeconfig or auth/kubeadmin-password
+//	GET  /v1/clusters/{id}/bootstrap-content/{sha512}
+//	                                         fetch bootstrap content for the bootstrap node
+//	POST /v1/clusters/{id}/destroy           destroy a cluster
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.Trim(r.URL.Path, "/"), "/", 5)
+	if len(parts) < 2 || parts[0] != "v1" || parts[1] != "clusters" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 2 {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("a valid bearer token is required"))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.list(w)
+		case http.MethodPost:
+			s.create(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	bootstrapContent := len(parts) == 5 && parts[3] == "bootstrap-content"
+	if !bootstrapContent && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("a valid bearer token is required"))
+		return
+	}
+
+	cluster, ok := s.get(parts[2])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, cluster)
+	case len(parts) == 4 && parts[3] == "log" && r.Method == http.MethodGet:
+		s.streamLog(w, r, cluster.ID)
+	case len(parts) == 5 && parts[3] == "artifacts" && r.Method == http.MethodGet:
+		if !artifacts[parts[4]] {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(s.dir(cluster.ID), filepath.FromSlash(parts[4])))
+	case bootstrapContent:
+		bootstrap.ContentHandler(s.dir(cluster.ID)).ServeHTTP(w, r)
+	case len(parts) == 4 && parts[3] == "destroy" && r.Method == http.MethodPost:
+		s.destroy(w, cluster.ID)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorized returns true if the request carries the server's token.
+func (s *Server) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if s.Token == "" || !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(header[len(prefix):]), []byte(s.Token)) == 1
+}
+
+func (s *Server) dir(id string) string {
+	return filepath.Join(s.BaseDir, id)
+}
+
+func (s *Server) get(id string) (Cluster, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cluster, ok := s.clusters[id]
+	if !ok {
+		return Cluster{}, false
+	}
+	return *cluster, true
+}
+
+func (s *Server) list(w http.ResponseWriter) {
+	s.lock.Lock()
+	clusters := make([]Cluster, 0, len(s.clusters))
+	for _, cluster := range s.clusters {
+		clusters = append(clusters, *cluster)
+	}
+	s.lock.Unlock()
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Started.Before(clusters[j].Started) })
+	writeJSON(w, http.StatusOK, clusters)
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	installConfig, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxInstallConfigSize))
+	if err != nil {
+		if len(installConfig) == maxInstallConfigSize {
+			writeError(w, http.StatusRequestEntityTooLarge, errors.Errorf("the install config must not exceed %d bytes", maxInstallConfigSize))
+			return
+		}
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "failed to read install config"))
+		return
+	}
+	if len(installConfig) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("the request body must be an install config"))
+		return
+	}
+	if err := checkCredentialReferences(installConfig); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id := utilrand.String(8)
+	dir := s.dir(id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		writeError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to create asset directory"))
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, installConfigFilename), installConfig, 0600); err != nil {
+		writeError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to write install config"))
+		return
+	}
+
+	cluster, err := s.start(id, "create", "create", "cluster")
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, cluster)
+}
+
+// checkCredentialReferences rejects install configs whose pull secrets
+// reference a credentials provider.  Clients may not make the server read
+// its files or environment, or run commands, on their behalf.
+func checkCredentialReferences(data []byte) error {
+	config := &types.InstallConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return errors.Wrap(err, "failed to parse install config")
+	}
+	secrets := append([]string{config.PullSecret}, config.AdditionalPullSecrets...)
+	for _, secret := range secrets {
+		if credentials.IsReference(secret) {
+			return errors.Errorf("%s: credentials provider references are not accepted by the server; send the pull secrets themselves", secret)
+		}
+	}
+	return nil
+}
+
+func (s *Server) destroy(w http.ResponseWriter, id string) {
+	cluster, err := s.start(id, "destroy", "destroy", "cluster")
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, cluster)
+}
+
+// start runs kni-install with args in the background, recording its
+// progress under id, unless an operation on id is already running.
+func (s *Server) start(id, operation string, args ...string) (Cluster, error) {
+	cmd := s.Command(s.dir(id), args...)
+	output := &tailBuffer{limit: outputLimit}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cluster := &Cluster{
+		ID:        id,
+		Operation: operation,
+		State:     Running,
+		Started:   time.Now(),
+	}
+
+	// Hold the lock from the check until the operation is recorded, so
+	// concurrent requests cannot both start one.
+	s.lock.Lock()
+	if existing, ok := s.clusters[id]; ok && existing.State == Running {
+		s.lock.Unlock()
+		return Cluster{}, errors.Errorf("cluster %s has an operation in progress", id)
+	}
+	s.clusters[id] = cluster
+	s.save(cluster)
+	snapshot := *cluster
+	s.lock.Unlock()
+
+	logrus.Infof("Starting %s of cluster %s", operation, id)
+	go func() {
+		err := cmd.Run()
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		defer s.save(cluster)
+		now := time.Now()
+		cluster.Finished = &now
+		if err != nil {
+			cluster.State = Failed
+			cluster.Error = fmt.Sprintf("%v: %s", err, lastLine(output.Bytes()))
+			logrus.Errorf("Failed to %s cluster %s: %s", operation, id, cluster.Error)
+			return
+		}
+		cluster.State = Succeeded
+		logrus.Infof("Finished %s of cluster %s", operation, id)
+	}()
+
+	return snapshot, nil
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	limit int
+	buf   bytes.Buffer
+	mu    sync.Mutex
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(p)
+	if len(p) > b.limit {
+		p = p[len(p)-b.limit:]
+	}
+	if excess := b.buf.Len() + len(p) - b.limit; excess > 0 {
+		b.buf.Next(excess)
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+// Bytes returns the bytes kept.
+func (b *tailBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+// streamLog copies the install log to the client, following it until the
+// operation finishes or the client goes away.
+func (s *Server) streamLog(w http.ResponseWriter, r *http.Request, id string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	flusher, _ := w.(http.Flusher)
+
+	var file *os.File
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		// Check whether the operation is finished before reading, so the
+		// final read is guaranteed to see everything it wrote.
+		cluster, _ := s.get(id)
+		finished := cluster.State != Running
+
+		if file == nil {
+			var err error
+			file, err = os.Open(filepath.Join(s.dir(id), logFilename))
+			if err != nil && !os.IsNotExist(err) {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		if file != nil {
+			if _, err := io.Copy(w, file); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		if finished {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return lines[len(lines)-1]
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.Warnf("Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeInstaller returns a Command which logs its arguments and exits with
+// the given status, after a moment, instead of running kni-install.
+func fakeInstaller(status string) func(dir string, args ...string) *exec.Cmd {
+	return func(dir string, args ...string) *exec.Cmd {
+		script := `echo "$@" >> "$0/` + logFilename + `"; echo "fatal: $@" >&2; sleep 0.2; exit ` + status
+		return exec.Command("sh", append([]string{"-c", script, dir}, args...)...)
+	}
+}
+
+func waitFor(t *testing.T, s *Server, id string) Cluster {
+	for i := 0; i < 100; i++ {
+		if cluster, _ := s.get(id); cluster.State != Running {
+			return cluster
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("cluster %s did not finish", id)
+	return Cluster{}
+}
+
+const token = "s3cr3t"
+
+func request(t *testing.T, s *Server, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+token)
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{limit: 8}
+	b.Write([]byte("0123"))
+	b.Write([]byte("456789"))
+	assert.Equal(t, "23456789", string(b.Bytes()))
+	b.Write([]byte("abcdefghijkl"))
+	assert.Equal(t, "efghijkl", string(b.Bytes()))
+}
+
+func TestServer(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "kni-install-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	s, err := New(baseDir, "kni-install", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Command = fakeInstaller("0")
+
+	for _, header := range []string{"", "Bearer wrong", token} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/clusters", nil)
+		r.Header.Set("Authorization", header)
+		s.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+	}
+
+	w := request(t, s, http.MethodPost, "/v1/clusters", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(t, s, http.MethodPost, "/v1/clusters", "apiVersion: v1beta4\npullSecret: exec:id\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "exec:id: credentials provider references are not accepted by the server")
+
+	w = request(t, s, http.MethodPost, "/v1/clusters", "apiVersion: v1beta4\n"+strings.Repeat("#", maxInstallConfigSize))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = request(t, s, http.MethodPost, "/v1/clusters", "apiVersion: v1beta4\n")
+	if !assert.Equal(t, http.StatusAccepted, w.Code) {
+		return
+	}
+	created := Cluster{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "create", created.Operation)
+
+	assert.Equal(t, Succeeded, waitFor(t, s, created.ID).State)
+
+	w = request(t, s, http.MethodGet, "/v1/clusters/"+created.ID+"/log", "")
+	assert.Equal(t, "create cluster\n", w.Body.String())
+
+	if err := ioutil.WriteFile(filepath.Join(baseDir, created.ID, "metadata.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w = request(t, s, http.MethodGet, "/v1/clusters/"+created.ID+"/artifacts/metadata.json", "")
+	assert.Equal(t, "{}", w.Body.String())
+
+	w = request(t, s, http.MethodGet, "/v1/clusters/"+created.ID+"/artifacts/install-config.yaml", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(t, s, http.MethodGet, "/v1/clusters/"+created.ID+"/artifacts/../../etc/passwd", "")
+	assert.NotEqual(t, http.StatusOK, w.Code)
+
+	w = request(t, s, http.MethodGet, "/v1/clusters/"+created.ID+"/bootstrap-content/install-config.yaml", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	s.Command = fakeInstaller("1")
+	w = request(t, s, http.MethodPost, "/v1/clusters/"+created.ID+"/destroy", "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	w = request(t, s, http.MethodPost, "/v1/clusters/"+created.ID+"/destroy", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	destroyed := waitFor(t, s, created.ID)
+	assert.Equal(t, Failed, destroyed.State)
+	assert.Equal(t, "exit status 1: fatal: destroy cluster", destroyed.Error)
+
+	w = request(t, s, http.MethodGet, "/v1/clusters", "")
+	clusters := []Cluster{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusters))
+	assert.Len(t, clusters, 1)
+
+	w = request(t, s, http.MethodGet, "/v1/clusters/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, err = os.Stat(filepath.Join(baseDir, created.ID, installConfigFilename))
+	assert.NoError(t, err)
+
+	restarted, err := New(baseDir, "kni-install", token)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cluster, ok := restarted.get(created.ID)
+	if assert.True(t, ok, "the restarted server lost the cluster") {
+		assert.Equal(t, destroyed.Operation, cluster.Operation)
+		assert.Equal(t, destroyed.State, cluster.State)
+		assert.Equal(t, destroyed.Error, cluster.Error)
+		assert.True(t, destroyed.Started.Equal(cluster.Started))
+	}
+}
+
+func TestLoadInterrupted(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "kni-install-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	if err := os.MkdirAll(filepath.Join(baseDir, "abc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	status := `{"id":"abc","operation":"create","state":"running","started":"2019-07-01T00:00:00Z"}`
+	if err := ioutil.WriteFile(filepath.Join(baseDir, "abc", statusFilename), []byte(status), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(baseDir, "kni-install", token)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cluster, ok := s.get("abc")
+	if assert.True(t, ok) {
+		assert.Equal(t, Failed, cluster.State)
+		assert.Equal(t, "interrupted by a restart of the server", cluster.Error)
+		assert.NotNil(t, cluster.Finished)
+	}
+
+	s.Command = fakeInstaller("0")
+	w := request(t, s, http.MethodPost, "/v1/clusters/abc/destroy", "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, Succeeded, waitFor(t, s, "abc").State)
+}
@@ -0,0 +1,28 @@
+// Package simulate lets operators rehearse an install end-to-end without
+// touching hardware.  The libvirt and bare metal clusters are applied on
+// Terraform's in-memory backend instead of a hypervisor, and nothing is
+// downloaded for them, but every asset is generated, validated and timed
+// as for a real install.
+package simulate
+
+import (
+	"context"
+)
+
+// OSImage is the placeholder RHCOS image of simulated libvirt and bare
+// metal clusters, which is never read.
+const OSImage = "file:///var/lib/kni-install/simulated/rhcos-qemu.qcow2"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx in which the install is simulated.  The
+// --simulate flag sets it.
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, true)
+}
+
+// FromContext returns true if the install of ctx is simulated.
+func FromContext(ctx context.Context) bool {
+	simulated, _ := ctx.Value(contextKey{}).(bool)
+	return simulated
+}
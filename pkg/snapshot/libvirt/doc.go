@@ -0,0 +1,2 @@
+// Package libvirt provides a cluster snapshotter for libvirt clusters.
+package libvirt
@@ -0,0 +1,184 @@
+// +build libvirt
+
+package libvirt
+
+import (
+	"fmt"
+	"strings"
+
+	libvirt "github.com/libvirt/libvirt-go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/snapshot"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// ClusterSnapshotter snapshots the domains of a libvirt cluster.
+//
+// All of the domains are paused while they are snapshotted, and resumed
+// together once they have been restored, so that the etcd members are
+// checkpointed at the same moment and come back without one of them
+// having moved on.
+type ClusterSnapshotter struct {
+	LibvirtURI string
+	InfraID    string
+	Logger     logrus.FieldLogger
+}
+
+// Create snapshots the disks and memory of all of the cluster's domains.
+func (o *ClusterSnapshotter) Create(name string) error {
+	conn, err := libvirt.NewConnect(o.LibvirtURI)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Libvirt daemon")
+	}
+	defer conn.Close()
+
+	domains, err := o.domains(conn)
+	if err != nil {
+		return err
+	}
+	defer freeDomains(domains)
+	for dName, domain := range domains {
+		if s, err := domain.SnapshotLookupByName(name, 0); err == nil {
+			s.Free()
+			return errors.Errorf("domain %q already has a snapshot named %q", dName, name)
+		}
+	}
+
+	paused, err := o.pause(domains)
+	defer o.resume(paused)
+	if err != nil {
+		return err
+	}
+
+	xml := fmt.Sprintf("<domainsnapshot><name>%s</name><description>kni-install snapshot of cluster %s</description></domainsnapshot>", name, o.InfraID)
+	for dName, domain := range domains {
+		s, err := domain.CreateSnapshotXML(xml, libvirt.DOMAIN_SNAPSHOT_CREATE_ATOMIC)
+		if err != nil {
+			return errors.Wrapf(err, "snapshot domain %q", dName)
+		}
+		s.Free()
+		o.Logger.WithField("domain", dName).Infof("Created snapshot %s", name)
+	}
+	return nil
+}
+
+// Restore reverts all of the cluster's domains to a snapshot, leaving them
+// running.
+func (o *ClusterSnapshotter) Restore(name string) error {
+	conn, err := libvirt.NewConnect(o.LibvirtURI)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Libvirt daemon")
+	}
+	defer conn.Close()
+
+	domains, err := o.domains(conn)
+	if err != nil {
+		return err
+	}
+	defer freeDomains(domains)
+
+	snapshots := make(map[string]*libvirt.DomainSnapshot, len(domains))
+	defer func() {
+		for _, s := range snapshots {
+			s.Free()
+		}
+	}()
+	var missing []string
+	for dName, domain := range domains {
+		s, err := domain.SnapshotLookupByName(name, 0)
+		if err != nil {
+			missing = append(missing, dName)
+			continue
+		}
+		snapshots[dName] = s
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("no snapshot named %q for domains %s; domains created since the snapshot must be deleted first", name, strings.Join(missing, ", "))
+	}
+
+	reverted := make(map[string]*libvirt.Domain, len(domains))
+	defer o.resume(reverted)
+	for dName, s := range snapshots {
+		if err := s.RevertToSnapshot(libvirt.DOMAIN_SNAPSHOT_REVERT_PAUSED); err != nil {
+			return errors.Wrapf(err, "revert domain %q to snapshot %q", dName, name)
+		}
+		reverted[dName] = domains[dName]
+		o.Logger.WithField("domain", dName).Infof("Restored snapshot %s", name)
+	}
+	return nil
+}
+
+// domains returns the cluster's domains by name.
+func (o *ClusterSnapshotter) domains(conn *libvirt.Connect) (map[string]*libvirt.Domain, error) {
+	all, err := conn.ListAllDomains(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "list domains")
+	}
+	domains := make(map[string]*libvirt.Domain)
+	for i := range all {
+		domain := &all[i]
+		dName, err := domain.GetName()
+		if err != nil {
+			domain.Free()
+			return nil, errors.Wrap(err, "get domain name")
+		}
+		if !strings.HasPrefix(dName, o.InfraID) {
+			domain.Free()
+			continue
+		}
+		domains[dName] = domain
+	}
+	if len(domains) == 0 {
+		return nil, errors.Errorf("no domains found for cluster %s", o.InfraID)
+	}
+	return domains, nil
+}
+
+// pause suspends the running domains, returning those it suspended.
+func (o *ClusterSnapshotter) pause(domains map[string]*libvirt.Domain) (map[string]*libvirt.Domain, error) {
+	paused := make(map[string]*libvirt.Domain, len(domains))
+	for dName, domain := range domains {
+		state, _, err := domain.GetState()
+		if err != nil {
+			return paused, errors.Wrapf(err, "get domain state %q", dName)
+		}
+		if state != libvirt.DOMAIN_RUNNING {
+			continue
+		}
+		if err := domain.Suspend(); err != nil {
+			return paused, errors.Wrapf(err, "pause domain %q", dName)
+		}
+		paused[dName] = domain
+		o.Logger.WithField("domain", dName).Debug("Paused domain")
+	}
+	return paused, nil
+}
+
+// resume resumes domains, logging rather than returning errors so that it
+// can be deferred.
+func (o *ClusterSnapshotter) resume(domains map[string]*libvirt.Domain) {
+	for dName, domain := range domains {
+		if err := domain.Resume(); err != nil {
+			o.Logger.WithField("domain", dName).Warnf("Failed to resume domain: %v", err)
+			continue
+		}
+		o.Logger.WithField("domain", dName).Debug("Resumed domain")
+	}
+}
+
+func freeDomains(domains map[string]*libvirt.Domain) {
+	for _, domain := range domains {
+		domain.Free()
+	}
+}
+
+// New returns a libvirt Snapshotter from ClusterMetadata.
+func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (snapshot.Snapshotter, error) {
+	return &ClusterSnapshotter{
+		LibvirtURI: metadata.ClusterPlatformMetadata.Libvirt.URI,
+		InfraID:    metadata.InfraID,
+		Logger:     logger,
+	}, nil
+}
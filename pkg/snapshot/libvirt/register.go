@@ -0,0 +1,11 @@
+// +build libvirt
+
+package libvirt
+
+import (
+	"github.com/metalkube/kni-installer/pkg/snapshot"
+)
+
+func init() {
+	snapshot.Registry["libvirt"] = New
+}
@@ -0,0 +1,61 @@
+// Package snapshot checkpoints the machines of development clusters, so
+// that they can be rolled back to a known state instead of reinstalled.
+package snapshot
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/asset/cluster"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// Snapshotter allows multiple implementations of snapshots for different
+// platforms.
+type Snapshotter interface {
+	// Create snapshots all of the cluster's machines as name.
+	Create(name string) error
+
+	// Restore rolls all of the cluster's machines back to the snapshot
+	// name.
+	Restore(name string) error
+}
+
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ValidateName checks that name can be used to name a snapshot on every
+// platform.
+func ValidateName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return errors.Errorf("invalid snapshot name %q: it must start with a letter or digit and contain only letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// NewFunc is an interface for creating platform-specific snapshotters.
+type NewFunc func(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (Snapshotter, error)
+
+// Registry maps ClusterMetadata.Platform() to per-platform Snapshotter
+// creators.
+var Registry = make(map[string]NewFunc)
+
+// New returns a Snapshotter based on `metadata.json` in `rootDir`.
+func New(logger logrus.FieldLogger, rootDir string) (Snapshotter, error) {
+	metadata, err := cluster.LoadMetadata(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	platform := metadata.Platform()
+	if platform == "" {
+		return nil, errors.New("no platform configured in metadata")
+	}
+
+	creator, ok := Registry[platform]
+	if !ok {
+		return nil, errors.Errorf("snapshots are not supported on %q", platform)
+	}
+	return creator(logger, metadata)
+}
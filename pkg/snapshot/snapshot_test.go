@@ -0,0 +1,31 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateName(t *testing.T) {
+	cases := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "before-upgrade", valid: true},
+		{name: "4.1.0_rc.1", valid: true},
+		{name: "", valid: false},
+		{name: "-flag", valid: false},
+		{name: "a/b", valid: false},
+		{name: "<name>", valid: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateName(tc.name)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
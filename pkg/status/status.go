@@ -0,0 +1,273 @@
+// Package status tracks the progress of a long-running installer command
+// and serves it over HTTP, so orchestration wrappers can poll the
+// installer instead of parsing its output.
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// State is the state of a phase, or of the whole command.
+type State string
+
+const (
+	// Pending means the phase has not started yet.
+	Pending State = "pending"
+	// Running means the phase is in progress.
+	Running State = "running"
+	// Succeeded means the phase completed successfully.
+	Succeeded State = "succeeded"
+	// Failed means an error was logged during the phase.
+	Failed State = "failed"
+)
+
+// ExpectedPhase is a phase which a command is expected to go through, with
+// its typical duration for estimating when the command will finish.
+type ExpectedPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// clusterPhaseDurations are the typical durations of the phases of
+// `create cluster` on bare metal.
+var clusterPhaseDurations = map[string]time.Duration{
+	"Cluster":                15 * time.Minute,
+	"Bootstrap":              20 * time.Minute,
+	"Cluster initialization": 20 * time.Minute,
+	"Console":                5 * time.Minute,
+}
+
+// ClusterPhases returns the phases of `create cluster` with the given
+// names, as the installer will run them, with their typical durations.
+func ClusterPhases(names []string) []ExpectedPhase {
+	phases := make([]ExpectedPhase, 0, len(names))
+	for _, name := range names {
+		phases = append(phases, ExpectedPhase{Name: name, Duration: clusterPhaseDurations[name]})
+	}
+	return phases
+}
+
+// Phase is the status of one phase.
+type Phase struct {
+	Name     string     `json:"name"`
+	State    State      `json:"state"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Message  string     `json:"message,omitempty"`
+}
+
+// Status is the JSON document served by the tracker.
+type Status struct {
+	State     State     `json:"state"`
+	Phase     string    `json:"phase,omitempty"`
+	Phases    []Phase   `json:"phases"`
+	LastError string    `json:"lastError,omitempty"`
+	Started   time.Time `json:"started"`
+
+	// ETA estimates when the command will finish, from the typical
+	// duration of each phase which has not finished yet.
+	ETA *time.Time `json:"eta,omitempty"`
+}
+
+// Tracker records the progress of a command.
+type Tracker struct {
+	lock     sync.Mutex
+	started  time.Time
+	phases   []Phase
+	expected map[string]time.Duration
+	current  int
+	failed   bool
+	finished bool
+	lastErr  string
+}
+
+// New returns a Tracker expecting the given phases.  Phases which are not
+// expected are tracked too, but are not included in the ETA.
+func New(expected []ExpectedPhase) *Tracker {
+	t := &Tracker{
+		started:  time.Now(),
+		expected: map[string]time.Duration{},
+		current:  -1,
+	}
+	for _, phase := range expected {
+		t.phases = append(t.phases, Phase{Name: phase.Name, State: Pending})
+		t.expected[phase.Name] = phase.Duration
+	}
+	return t
+}
+
+// Start records the start of phase and returns a function recording its
+// success.  It is an installer.PhaseFunc.
+func (t *Tracker) Start(phase string) func(message string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now()
+	index := t.find(phase)
+	if index < 0 {
+		t.phases = append(t.phases, Phase{Name: phase})
+		index = len(t.phases) - 1
+	}
+	t.phases[index].State = Running
+	t.phases[index].Started = &now
+	t.current = index
+
+	return func(message string) {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		now := time.Now()
+		t.phases[index].State = Succeeded
+		t.phases[index].Finished = &now
+		t.phases[index].Message = message
+	}
+}
+
+func (t *Tracker) find(phase string) int {
+	for i := range t.phases {
+		if t.phases[i].Name == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// Status returns the current status.
+func (t *Tracker) Status() *Status {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	status := &Status{
+		State:     Running,
+		Phases:    make([]Phase, len(t.phases)),
+		LastError: t.lastErr,
+		Started:   t.started,
+	}
+	copy(status.Phases, t.phases)
+	if t.current >= 0 {
+		status.Phase = t.phases[t.current].Name
+	}
+
+	switch {
+	case t.failed:
+		status.State = Failed
+	case t.finished && t.allSucceeded():
+		status.State = Succeeded
+	default:
+		eta := time.Now().Add(t.remaining())
+		status.ETA = &eta
+	}
+	return status
+}
+
+func (t *Tracker) allSucceeded() bool {
+	for _, phase := range t.phases {
+		if phase.State != Succeeded {
+			return false
+		}
+	}
+	return true
+}
+
+// Finish records the end of the command: its failure with err, or else its
+// success, dropping the expected phases which did not run, e.g. because
+// the install config disables the console.
+func (t *Tracker) Finish(err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if err != nil {
+		t.fail(err.Error())
+		return
+	}
+
+	var current string
+	if t.current >= 0 {
+		current = t.phases[t.current].Name
+	}
+	phases := t.phases[:0]
+	for _, phase := range t.phases {
+		if phase.State != Pending {
+			phases = append(phases, phase)
+		}
+	}
+	t.phases = phases
+	t.current = t.find(current)
+	t.finished = true
+}
+
+// fail records message as the last error and fails the command, along
+// with the phase in progress.
+func (t *Tracker) fail(message string) {
+	t.lastErr = message
+	t.failed = true
+	if t.current >= 0 && t.phases[t.current].State == Running {
+		t.phases[t.current].State = Failed
+	}
+}
+
+// remaining estimates the time until the last phase finishes.
+func (t *Tracker) remaining() time.Duration {
+	var remaining time.Duration
+	for _, phase := range t.phases {
+		expected := t.expected[phase.Name]
+		switch phase.State {
+		case Pending:
+			remaining += expected
+		case Running:
+			if left := expected - time.Since(*phase.Started); left > 0 {
+				remaining += left
+			}
+		}
+	}
+	return remaining
+}
+
+// Levels returns the levels which the tracker records as errors.
+func (t *Tracker) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+// Fire records entry as the last error.  Fatal entries, which exit the
+// installer, also fail the current phase.
+func (t *Tracker) Fire(entry *logrus.Entry) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if entry.Level <= logrus.FatalLevel {
+		t.fail(entry.Message)
+	} else {
+		t.lastErr = entry.Message
+	}
+	return nil
+}
+
+// ServeHTTP serves:
+//
+//   GET /status   the Status as JSON
+//   GET /healthz  200 OK unless the command has failed
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := t.Status()
+	switch r.URL.Path {
+	case "/status":
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			logrus.Warnf("Failed to write status: %v", err)
+		}
+	case "/healthz":
+		if status.State == Failed {
+			http.Error(w, status.LastError, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,115 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *Tracker, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	t.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestTracker(t *testing.T) {
+	tracker := New([]ExpectedPhase{
+		{Name: "first", Duration: time.Hour},
+		{Name: "second", Duration: 2 * time.Hour},
+	})
+
+	status := tracker.Status()
+	assert.Equal(t, Running, status.State)
+	assert.Equal(t, "", status.Phase)
+	if assert.NotNil(t, status.ETA) {
+		assert.WithinDuration(t, time.Now().Add(3*time.Hour), *status.ETA, time.Minute)
+	}
+
+	done := tracker.Start("first")
+	done("")
+	tracker.Start("second")
+	assert.NoError(t, tracker.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "retrying"}))
+
+	w := get(tracker, "/status")
+	assert.Equal(t, http.StatusOK, w.Code)
+	status = &Status{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), status))
+	assert.Equal(t, Running, status.State)
+	assert.Equal(t, "second", status.Phase)
+	assert.Equal(t, "retrying", status.LastError)
+	if assert.Len(t, status.Phases, 2) {
+		assert.Equal(t, Succeeded, status.Phases[0].State)
+		assert.Equal(t, Running, status.Phases[1].State)
+	}
+	if assert.NotNil(t, status.ETA) {
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), *status.ETA, time.Minute)
+	}
+	assert.Equal(t, http.StatusOK, get(tracker, "/healthz").Code)
+
+	assert.NoError(t, tracker.Fire(&logrus.Entry{Level: logrus.FatalLevel, Message: "timed out"}))
+	status = tracker.Status()
+	assert.Equal(t, Failed, status.State)
+	assert.Equal(t, Failed, status.Phases[1].State)
+	assert.Nil(t, status.ETA)
+	w = get(tracker, "/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "timed out\n", w.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, get(tracker, "/missing").Code)
+}
+
+func TestTrackerSucceeded(t *testing.T) {
+	tracker := New([]ExpectedPhase{{Name: "only", Duration: time.Hour}})
+	tracker.Start("only")("all done")
+	tracker.Start("unexpected")("")
+
+	// The phases have all succeeded, but the command has not finished.
+	assert.Equal(t, Running, tracker.Status().State)
+
+	tracker.Finish(nil)
+	status := tracker.Status()
+	assert.Equal(t, Succeeded, status.State)
+	assert.Equal(t, "unexpected", status.Phase)
+	assert.Equal(t, "all done", status.Phases[0].Message)
+	assert.Nil(t, status.ETA)
+}
+
+func TestTrackerFinish(t *testing.T) {
+	tracker := New([]ExpectedPhase{
+		{Name: "first", Duration: time.Hour},
+		{Name: "skipped", Duration: time.Hour},
+	})
+	tracker.Start("first")("")
+	tracker.Finish(nil)
+
+	status := tracker.Status()
+	assert.Equal(t, Succeeded, status.State)
+	assert.Equal(t, "first", status.Phase)
+	assert.Len(t, status.Phases, 1)
+
+	tracker = New([]ExpectedPhase{
+		{Name: "first", Duration: time.Hour},
+		{Name: "second", Duration: time.Hour},
+	})
+	tracker.Start("first")
+	tracker.Finish(errors.New("failed to create the cluster"))
+
+	status = tracker.Status()
+	assert.Equal(t, Failed, status.State)
+	assert.Equal(t, "failed to create the cluster", status.LastError)
+	if assert.Len(t, status.Phases, 2) {
+		assert.Equal(t, Failed, status.Phases[0].State)
+		assert.Equal(t, Pending, status.Phases[1].State)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, get(tracker, "/healthz").Code)
+}
+
+func TestClusterPhases(t *testing.T) {
+	assert.Equal(t, []ExpectedPhase{{Name: "Cluster", Duration: 15 * time.Minute}}, ClusterPhases([]string{"Cluster"}))
+}
@@ -3,12 +3,12 @@ package exec
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
-	"os/signal"
 
 	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/logutils"
@@ -29,16 +29,44 @@ var commands = map[string]cmdFunc{
 	"init": func(meta command.Meta) cli.Command {
 		return &command.InitCommand{Meta: meta}
 	},
+	"plan": func(meta command.Meta) cli.Command {
+		return &command.PlanCommand{Meta: meta}
+	},
 }
 
-func runner(cmd string, dir string, args []string, stdout, stderr io.Writer) int {
-	lf := ioutil.Discard
-	if level := logging.LogLevel(); level != "" {
-		lf = &logutils.LevelFilter{
+// runner runs a Terraform command.  Terraform's log, including that of the
+// providers, is always written to debug at the DEBUG level (or TRACE, if
+// TF_LOG asks for it), so it is at hand when the command fails, and to
+// stdout as well if TF_LOG is set.
+func runner(ctx context.Context, cmd string, dir string, args []string, stdout, stderr, debug io.Writer) int {
+	writers := []io.Writer{}
+	level := logging.LogLevel()
+	if level != "" {
+		writers = append(writers, &logutils.LevelFilter{
 			Levels:   logging.ValidLevels,
 			MinLevel: logutils.LogLevel(level),
 			Writer:   stdout,
+		})
+	} else {
+		// The providers run as plugins, which only log if TF_LOG is set
+		// in their environment.
+		os.Setenv(logging.EnvLog, "DEBUG")
+		defer os.Unsetenv(logging.EnvLog)
+	}
+	if debug != nil {
+		debugLevel := logutils.LogLevel("DEBUG")
+		if level == "TRACE" {
+			debugLevel = "TRACE"
 		}
+		writers = append(writers, &logutils.LevelFilter{
+			Levels:   logging.ValidLevels,
+			MinLevel: debugLevel,
+			Writer:   debug,
+		})
+	}
+	lf := ioutil.Discard
+	if len(writers) > 0 {
+		lf = io.MultiWriter(writers...)
 	}
 	log.SetOutput(lf)
 	defer log.SetOutput(os.Stderr)
@@ -46,7 +74,7 @@ func runner(cmd string, dir string, args []string, stdout, stderr io.Writer) int
 	// Make sure we clean up any managed plugins at the end of this
 	defer plugin.CleanupClients()
 
-	sdCh, cancel := makeShutdownCh()
+	sdCh, cancel := makeShutdownCh(ctx)
 	defer cancel()
 
 	pluginDirs, err := globalPluginDirs(dir)
@@ -90,37 +118,42 @@ func runner(cmd string, dir string, args []string, stdout, stderr io.Writer) int
 }
 
 // Apply is wrapper around `terraform apply` subcommand.
-func Apply(datadir string, args []string, stdout, stderr io.Writer) int {
-	return runner("apply", datadir, args, stdout, stderr)
+func Apply(ctx context.Context, datadir string, args []string, stdout, stderr, debug io.Writer) int {
+	return runner(ctx, "apply", datadir, args, stdout, stderr, debug)
 }
 
 // Destroy is wrapper around `terraform destroy` subcommand.
-func Destroy(datadir string, args []string, stdout, stderr io.Writer) int {
-	return runner("destroy", datadir, args, stdout, stderr)
+func Destroy(ctx context.Context, datadir string, args []string, stdout, stderr, debug io.Writer) int {
+	return runner(ctx, "destroy", datadir, args, stdout, stderr, debug)
 }
 
 // Init is wrapper around `terraform init` subcommand.
-func Init(datadir string, args []string, stdout, stderr io.Writer) int {
-	return runner("init", datadir, args, stdout, stderr)
+func Init(ctx context.Context, datadir string, args []string, stdout, stderr, debug io.Writer) int {
+	return runner(ctx, "init", datadir, args, stdout, stderr, debug)
 }
 
-// makeShutdownCh creates an interrupt listener and returns a channel.
-// A message will be sent on the channel for every interrupt received.
-func makeShutdownCh() (<-chan struct{}, func()) {
-	resultCh := make(chan struct{})
-	signalCh := make(chan os.Signal, 4)
-
-	handle := []os.Signal{}
-	handle = append(handle, ignoreSignals...)
-	handle = append(handle, forwardSignals...)
+// Plan is wrapper around `terraform plan` subcommand.
+func Plan(ctx context.Context, datadir string, args []string, stdout, stderr, debug io.Writer) int {
+	return runner(ctx, "plan", datadir, args, stdout, stderr, debug)
+}
 
-	signal.Notify(signalCh, handle...)
+// makeShutdownCh returns a channel which receives a message once the
+// context is done, asking Terraform to stop gracefully.  Interrupts are the
+// caller's business; kni-install cancels the context on the first one.
+func makeShutdownCh(ctx context.Context) (<-chan struct{}, func()) {
+	resultCh := make(chan struct{})
+	done := make(chan struct{})
 	go func() {
-		for {
-			<-signalCh
-			resultCh <- struct{}{}
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		select {
+		case resultCh <- struct{}{}:
+		case <-done:
 		}
 	}()
 
-	return resultCh, func() { signal.Reset(handle...) }
+	return resultCh, func() { close(done) }
 }
@@ -1,10 +0,0 @@
-// +build windows
-
-package exec
-
-import (
-	"os"
-)
-
-var ignoreSignals = []os.Signal{os.Interrupt}
-var forwardSignals []os.Signal
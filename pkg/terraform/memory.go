@@ -0,0 +1,199 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Memory is the in-memory backend which stands in for Terraform on the
+// fake platform and for simulated installs.  It renders nothing, but checks and records the
+// variables each cluster is applied with, so tests can inspect what
+// would have been provisioned.
+var Memory = &MemoryBackend{clusters: map[string]*MemoryCluster{}}
+
+// MemoryBackend records the clusters applied on the fake platform,
+// keyed by cluster ID.
+type MemoryBackend struct {
+	mu       sync.Mutex
+	clusters map[string]*MemoryCluster
+}
+
+// MemoryCluster is a cluster applied on the in-memory backend.
+type MemoryCluster struct {
+	// Variables are the Terraform variables the cluster was applied
+	// with, merged from every variable file.
+	Variables map[string]interface{}
+
+	// Resources are the addresses of the cluster's live resources,
+	// e.g. "module.bootstrap.fake_instance.bootstrap".
+	Resources []string
+}
+
+// memoryState is the Terraform state file written for the fake
+// platform, which lists the cluster's live resources.
+type memoryState struct {
+	Version          int      `json:"version"`
+	TerraformVersion string   `json:"terraform_version"`
+	Resources        []string `json:"resources"`
+}
+
+// requiredVariables are the variables every cluster must be applied
+// with, as the platform modules require them.
+var requiredVariables = []string{
+	"cluster_id",
+	"cluster_domain",
+	"base_domain",
+	"machine_cidr",
+	"master_count",
+	"ignition_bootstrap",
+	"ignition_master",
+}
+
+// Cluster returns the cluster with the given ID, if it is live.
+func (b *MemoryBackend) Cluster(clusterID string) (*MemoryCluster, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cluster, ok := b.clusters[clusterID]
+	return cluster, ok
+}
+
+// Remove forgets the cluster with the given ID, returning whether it
+// was live.
+func (b *MemoryBackend) Remove(clusterID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.clusters[clusterID]
+	delete(b.clusters, clusterID)
+	return ok
+}
+
+// apply records the cluster described by the -var-file arguments and
+// writes its state file to dir.
+func (b *MemoryBackend) apply(dir string, args []string) (string, error) {
+	variables, err := memoryVariables(args)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	for _, name := range requiredVariables {
+		if _, ok := variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", errors.Errorf("missing Terraform variables: %s", strings.Join(missing, ", "))
+	}
+
+	resources := []string{"module.bootstrap.fake_instance.bootstrap"}
+	masters, ok := variables["master_count"].(float64)
+	if !ok || masters < 1 {
+		return "", errors.Errorf("invalid master_count %v", variables["master_count"])
+	}
+	for i := 0; i < int(masters); i++ {
+		resources = append(resources, fmt.Sprintf("fake_instance.master.%d", i))
+	}
+
+	cluster := &MemoryCluster{Variables: variables, Resources: resources}
+	b.mu.Lock()
+	b.clusters[fmt.Sprint(variables["cluster_id"])] = cluster
+	b.mu.Unlock()
+
+	sf := filepath.Join(dir, StateFileName)
+	return sf, writeMemoryState(sf, resources)
+}
+
+// destroy removes the resources selected by the -target arguments (or
+// every resource, without any) from the state file in dir, which may
+// have been written by another process, and from the cluster described
+// by the -var-file arguments, if it is live.
+func (b *MemoryBackend) destroy(dir string, args []string) error {
+	variables, err := memoryVariables(args)
+	if err != nil {
+		return err
+	}
+
+	sf := filepath.Join(dir, StateFileName)
+	data, err := ioutil.ReadFile(sf)
+	if err != nil {
+		return err
+	}
+	state := &memoryState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", StateFileName)
+	}
+
+	var targets []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-target=") {
+			targets = append(targets, strings.TrimPrefix(arg, "-target="))
+		}
+	}
+
+	var remaining []string
+	for _, resource := range state.Resources {
+		targeted := len(targets) == 0
+		for _, target := range targets {
+			if resource == target || strings.HasPrefix(resource, target+".") {
+				targeted = true
+				break
+			}
+		}
+		if !targeted {
+			remaining = append(remaining, resource)
+		}
+	}
+
+	b.mu.Lock()
+	if cluster, ok := b.clusters[fmt.Sprint(variables["cluster_id"])]; ok {
+		cluster.Resources = remaining
+	}
+	b.mu.Unlock()
+
+	return writeMemoryState(sf, remaining)
+}
+
+// memoryVariables merges the JSON variable files given as -var-file
+// arguments, later files taking precedence.
+func memoryVariables(args []string) (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-var-file=") {
+			continue
+		}
+		path := strings.TrimPrefix(arg, "-var-file=")
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileVariables := map[string]interface{}{}
+		if err := json.Unmarshal(data, &fileVariables); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", filepath.Base(path))
+		}
+		for name, value := range fileVariables {
+			variables[name] = value
+		}
+	}
+	return variables, nil
+}
+
+func writeMemoryState(path string, resources []string) error {
+	sorted := append([]string{}, resources...)
+	sort.Strings(sorted)
+	data, err := json.MarshalIndent(&memoryState{
+		Version:          3,
+		TerraformVersion: "fake",
+		Resources:        sorted,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,121 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+// Local keeps the state in terraform.tfstate in an asset directory, and
+// locks it with terraform.tfstate.lock beside it, which holds the
+// LockInfo of its holder.
+type Local struct {
+	Dir string
+}
+
+var _ Store = (*Local)(nil)
+
+func (s *Local) path() string {
+	return filepath.Join(s.Dir, stateFileName)
+}
+
+func (s *Local) lockPath() string {
+	return s.path() + ".lock"
+}
+
+// Read returns the state, or nil if there is none.
+func (s *Local) Read(ctx context.Context) ([]byte, error) {
+	data, err := fileaudit.ReadFile(s.path(), "Terraform state")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Write replaces the state, through a temporary file so a failed write
+// does not leave it truncated.
+func (s *Local) Write(ctx context.Context, data []byte) error {
+	tempPath := s.path() + ".new"
+	if err := fileaudit.WriteFile(tempPath, data, 0600, "Terraform state"); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.path())
+}
+
+// Delete removes the state.
+func (s *Local) Delete(ctx context.Context) error {
+	err := os.Remove(s.path())
+	if err == nil {
+		fileaudit.Record(fileaudit.Remove, s.path(), "Terraform state", nil)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	return err
+}
+
+// Lock creates the lock file, which fails if it already exists.
+func (s *Local) Lock(ctx context.Context, operation string) (func() error, error) {
+	info, err := newLockInfo(operation)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	path := s.lockPath()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		held, err := s.readLock()
+		if err != nil {
+			return nil, errors.Wrapf(err, "the Terraform state in %s is locked", s)
+		}
+		return nil, &LockedError{Location: s.String(), Info: *held}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to lock the Terraform state")
+	}
+	_, err = f.Write(data)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, errors.Wrap(err, "failed to lock the Terraform state")
+	}
+
+	return func() error {
+		held, err := s.readLock()
+		if err != nil {
+			return err
+		}
+		if held.ID != info.ID {
+			return errors.Errorf("the lock was taken over by %s to %s (lock ID %s)", held.Who, held.Operation, held.ID)
+		}
+		return os.Remove(path)
+	}, nil
+}
+
+func (s *Local) readLock() (*LockInfo, error) {
+	f, err := os.Open(s.lockPath())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info := &LockInfo{}
+	if err := json.NewDecoder(f).Decode(info); err != nil {
+		return nil, errors.Wrapf(err, "failed to read the lock %s", s.lockPath())
+	}
+	return info, nil
+}
+
+// String returns the path of the state.
+func (s *Local) String() string {
+	return s.path()
+}
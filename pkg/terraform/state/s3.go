@@ -0,0 +1,175 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+)
+
+// lockSettle is how long S3 waits after writing its lock object before
+// checking it still holds the lock.
+var lockSettle = 5 * time.Second
+
+// S3 keeps the state in an object in an S3 bucket, encrypted at rest.
+//
+// S3 has no conditional writes, so the lock is advisory: the lock object,
+// <key>.lock, is written if it is missing, and the lock is held if the
+// object still holds it a few seconds later, when a racing writer's
+// object would have replaced it.  Enable versioning on the bucket to
+// recover from a lost race.
+type S3 struct {
+	Client s3iface.S3API
+	Bucket string
+	Key    string
+}
+
+var _ Store = (*S3)(nil)
+
+// newS3 returns the store of the object key in bucket, with the
+// credentials and region of the environment or the shared AWS config.
+func newS3(bucket, key string) (*S3, error) {
+	ssn, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create an AWS session")
+	}
+	return &S3{Client: s3.New(ssn), Bucket: bucket, Key: key}, nil
+}
+
+// Read returns the state, or nil if there is none.
+func (s *S3) Read(ctx context.Context) ([]byte, error) {
+	return s.get(ctx, s.Key)
+}
+
+// Write replaces the state.
+func (s *S3) Write(ctx context.Context, data []byte) error {
+	return s.put(ctx, s.Key, data)
+}
+
+// Delete removes the state.
+func (s *S3) Delete(ctx context.Context) error {
+	if err := offline.Check(ctx, "connect to S3"); err != nil {
+		return err
+	}
+	_, err := s.Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.Key),
+	})
+	return errors.Wrapf(err, "failed to delete %s", s)
+}
+
+// Lock writes the lock object, and checks it still holds it once racing
+// writers have settled.
+func (s *S3) Lock(ctx context.Context, operation string) (func() error, error) {
+	lockKey := s.Key + ".lock"
+	if held, err := s.readLock(ctx, lockKey); err != nil {
+		return nil, err
+	} else if held != nil {
+		return nil, &LockedError{Location: s.String(), Info: *held}
+	}
+
+	info, err := newLockInfo(operation)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.put(ctx, lockKey, data); err != nil {
+		return nil, errors.Wrap(err, "failed to lock the Terraform state")
+	}
+
+	select {
+	case <-time.After(lockSettle):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	held, err := s.readLock(ctx, lockKey)
+	if err != nil {
+		return nil, err
+	}
+	if held == nil {
+		return nil, errors.Errorf("the lock on the Terraform state in %s was removed while it was being taken", s)
+	}
+	if held.ID != info.ID {
+		return nil, &LockedError{Location: s.String(), Info: *held}
+	}
+
+	return func() error {
+		held, err := s.readLock(context.Background(), lockKey)
+		if err != nil {
+			return err
+		}
+		if held == nil || held.ID != info.ID {
+			return errors.New("the lock was taken over")
+		}
+		_, err = s.Client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket: aws.String(s.Bucket),
+			Key:    aws.String(lockKey),
+		})
+		return err
+	}, nil
+}
+
+func (s *S3) readLock(ctx context.Context, lockKey string) (*LockInfo, error) {
+	data, err := s.get(ctx, lockKey)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	info := &LockInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, errors.Wrapf(err, "failed to read the lock s3://%s/%s", s.Bucket, lockKey)
+	}
+	return info, nil
+}
+
+// get returns the object key, or nil if it is missing.
+func (s *S3) get(ctx context.Context, key string) ([]byte, error) {
+	if err := offline.Check(ctx, "connect to S3"); err != nil {
+		return nil, err
+	}
+	out, err := s.Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get s3://%s/%s", s.Bucket, key)
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+func (s *S3) put(ctx context.Context, key string, data []byte) error {
+	if err := offline.Check(ctx, "connect to S3"); err != nil {
+		return err
+	}
+	_, err := s.Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(s.Bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(data),
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAes256),
+	})
+	return errors.Wrapf(err, "failed to put s3://%s/%s", s.Bucket, key)
+}
+
+// String returns the URL of the state.
+func (s *S3) String() string {
+	return fmt.Sprintf("s3://%s/%s", s.Bucket, s.Key)
+}
@@ -0,0 +1,177 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// lockAnnotation holds the LockInfo of the holder of a Secret's lock.
+const lockAnnotation = "kni-install.metalkube.io/terraform-state-lock"
+
+// Secret keeps the state under the terraform.tfstate key of a Secret,
+// which is created if it is missing.  The lock is an annotation on the
+// Secret, taken with an update that fails if the Secret changed since it
+// was read.  Keep the state to a megabyte, the most a Secret holds.
+type Secret struct {
+	Client    corev1client.SecretsGetter
+	Namespace string
+	Name      string
+}
+
+var _ Store = (*Secret)(nil)
+
+// newSecret returns the store of the Secret namespace/name, in the cluster
+// of the current kubeconfig, or of the pod the installer runs in.
+func newSecret(namespace, name string) (*Secret, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the kubeconfig")
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a client")
+	}
+	return &Secret{Client: client.CoreV1(), Namespace: namespace, Name: name}, nil
+}
+
+// Read returns the state, or nil if there is none.
+func (s *Secret) Read(ctx context.Context) ([]byte, error) {
+	secret, err := s.get()
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	return secret.Data[stateFileName], nil
+}
+
+// Write replaces the state.
+func (s *Secret) Write(ctx context.Context, data []byte) error {
+	return s.update(func(secret *corev1.Secret) error {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[stateFileName] = data
+		return nil
+	})
+}
+
+// Delete removes the state, leaving the Secret.
+func (s *Secret) Delete(ctx context.Context) error {
+	secret, err := s.get()
+	if err != nil || secret == nil {
+		return err
+	}
+	if _, ok := secret.Data[stateFileName]; !ok {
+		return nil
+	}
+	delete(secret.Data, stateFileName)
+	_, err = s.Client.Secrets(s.Namespace).Update(secret)
+	return errors.Wrapf(err, "failed to update secret %s/%s", s.Namespace, s.Name)
+}
+
+// Lock sets the lock annotation, if it is unset.
+func (s *Secret) Lock(ctx context.Context, operation string) (func() error, error) {
+	info, err := newLockInfo(operation)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.update(func(secret *corev1.Secret) error {
+		if held := secret.Annotations[lockAnnotation]; held != "" {
+			return s.lockedError(held)
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[lockAnnotation] = string(data)
+		return nil
+	})
+	if apierrors.IsConflict(errors.Cause(err)) || apierrors.IsAlreadyExists(errors.Cause(err)) {
+		// Another took the lock between our read and update.
+		secret, err2 := s.get()
+		if err2 == nil && secret != nil && secret.Annotations[lockAnnotation] != "" {
+			return nil, s.lockedError(secret.Annotations[lockAnnotation])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return s.update(func(secret *corev1.Secret) error {
+			held := &LockInfo{}
+			if err := json.Unmarshal([]byte(secret.Annotations[lockAnnotation]), held); err != nil || held.ID != info.ID {
+				return errors.New("the lock was taken over")
+			}
+			delete(secret.Annotations, lockAnnotation)
+			return nil
+		})
+	}, nil
+}
+
+func (s *Secret) lockedError(held string) error {
+	info := LockInfo{}
+	if err := json.Unmarshal([]byte(held), &info); err != nil {
+		return errors.Wrapf(err, "the Terraform state in %s is locked", s)
+	}
+	return &LockedError{Location: s.String(), Info: info}
+}
+
+// get returns the Secret, or nil if it is missing.
+func (s *Secret) get() (*corev1.Secret, error) {
+	secret, err := s.Client.Secrets(s.Namespace).Get(s.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return secret, errors.Wrapf(err, "failed to get secret %s/%s", s.Namespace, s.Name)
+}
+
+// update applies mutate to the Secret, creating it if it is missing.  The
+// update fails with a conflict if the Secret changed since it was read.
+func (s *Secret) update(mutate func(*corev1.Secret) error) error {
+	secret, err := s.get()
+	if err != nil {
+		return err
+	}
+
+	secrets := s.Client.Secrets(s.Namespace)
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.Namespace,
+				Name:      s.Name,
+			},
+		}
+		if err := mutate(secret); err != nil {
+			return err
+		}
+		_, err = secrets.Create(secret)
+		return errors.Wrapf(err, "failed to create secret %s/%s", s.Namespace, s.Name)
+	}
+
+	if err := mutate(secret); err != nil {
+		return err
+	}
+	_, err = secrets.Update(secret)
+	return errors.Wrapf(err, "failed to update secret %s/%s", s.Namespace, s.Name)
+}
+
+// String returns the location of the state.
+func (s *Secret) String() string {
+	return fmt.Sprintf("secret://%s/%s", s.Namespace, s.Name)
+}
@@ -0,0 +1,159 @@
+// Package state keeps a cluster's Terraform state, in the asset directory
+// or in a remote backend, so the cluster can be destroyed, and its
+// bootstrap resources removed, from machines other than the one that
+// created it.
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/terraform"
+)
+
+// EnvVar is the location of the Terraform state of clusters being
+// created, and overrides that recorded in the metadata of clusters being
+// destroyed.  The --tfstate flag sets it, so child processes inherit it.
+// See New for the locations supported.
+const EnvVar = "OPENSHIFT_INSTALL_TFSTATE"
+
+// Store keeps the Terraform state of one cluster.
+type Store interface {
+	// Read returns the state, or nil if there is none.
+	Read(ctx context.Context) ([]byte, error)
+
+	// Write replaces the state.
+	Write(ctx context.Context, data []byte) error
+
+	// Delete removes the state.  Deleting a missing state is not an
+	// error.
+	Delete(ctx context.Context) error
+
+	// Lock takes the state's lock for the named operation, returning a
+	// function which releases it, or a *LockedError if another holds
+	// it.
+	Lock(ctx context.Context, operation string) (unlock func() error, err error)
+
+	// String returns the location of the state.
+	String() string
+}
+
+// LockInfo identifies the holder of a state's lock.
+type LockInfo struct {
+	// ID is unique to each time the lock is taken.
+	ID string `json:"id"`
+	// Operation is what the lock was taken for, e.g. "destroy cluster".
+	Operation string `json:"operation"`
+	// Who is the user and host which took the lock.
+	Who string `json:"who"`
+	// Created is when the lock was taken.
+	Created time.Time `json:"created"`
+}
+
+// LockedError is returned when a state is locked by another.
+type LockedError struct {
+	Location string
+	Info     LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("the Terraform state in %s is locked by %s to %s since %s (lock ID %s)", e.Location, e.Info.Who, e.Info.Operation, e.Info.Created.Format(time.RFC3339), e.Info.ID)
+}
+
+// Location returns the location set by EnvVar, or "" if there is none.
+func Location() string {
+	return os.Getenv(EnvVar)
+}
+
+// New returns the store at location: terraform.tfstate in the asset
+// directory dir if location is "", an object in an S3 bucket if it is
+// s3://<bucket>/<key>, or a Secret in the cluster of the current
+// kubeconfig, or of the pod the installer runs in, if it is
+// secret://<namespace>/<name>.  A bucket may hold the state of several
+// clusters, under different keys.
+func New(dir, location string) (Store, error) {
+	if location == "" {
+		return &Local{Dir: dir}, nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Terraform state location %q", location)
+	}
+	path := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "s3":
+		if u.Host == "" || path == "" {
+			return nil, errors.Errorf("invalid Terraform state location %q: want s3://<bucket>/<key>", location)
+		}
+		return newS3(u.Host, path)
+	case "secret":
+		if u.Host == "" || path == "" || strings.Contains(path, "/") {
+			return nil, errors.Errorf("invalid Terraform state location %q: want secret://<namespace>/<name>", location)
+		}
+		return newSecret(u.Host, path)
+	default:
+		return nil, errors.Errorf("unsupported Terraform state location %q: want s3://<bucket>/<key> or secret://<namespace>/<name>", location)
+	}
+}
+
+// ForCluster returns the store of the cluster in the asset directory dir
+// whose metadata recorded the location recorded: EnvVar's location if it
+// is set, else the recorded one.
+func ForCluster(dir, recorded string) (Store, error) {
+	location := Location()
+	if location == "" {
+		location = recorded
+	}
+	return New(dir, location)
+}
+
+// WithLock runs fn holding the store's lock for the named operation.
+func WithLock(ctx context.Context, store Store, operation string, fn func() error) (err error) {
+	unlock, err := store.Lock(ctx, operation)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err2 := unlock(); err2 != nil && err == nil {
+			err = errors.Wrapf(err2, "failed to unlock the Terraform state in %s", store)
+		}
+	}()
+	return fn()
+}
+
+// newLockInfo returns a fresh lock for the named operation.
+func newLockInfo(operation string) (LockInfo, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return LockInfo{}, errors.Wrap(err, "failed to generate a lock ID")
+	}
+
+	who := "unknown"
+	if u, err := user.Current(); err == nil {
+		who = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		who = fmt.Sprintf("%s@%s", who, host)
+	}
+
+	return LockInfo{
+		ID:        hex.EncodeToString(id),
+		Operation: operation,
+		Who:       who,
+		Created:   time.Now().UTC(),
+	}, nil
+}
+
+// stateFileName is the name of the state in the asset directory, and its
+// key in a Secret.
+const stateFileName = terraform.StateFileName
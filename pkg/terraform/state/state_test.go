@@ -0,0 +1,124 @@
+package state
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		location string
+		expected string
+		err      string
+	}{
+		{location: "", expected: "dir/terraform.tfstate"},
+		{location: "s3://bucket", err: `^invalid Terraform state location "s3://bucket": want s3://<bucket>/<key>$`},
+		{location: "secret://ns", err: `^invalid Terraform state location "secret://ns": want secret://<namespace>/<name>$`},
+		{location: "secret://ns/a/b", err: `^invalid Terraform state location "secret://ns/a/b": want secret://<namespace>/<name>$`},
+		{location: "gs://bucket/key", err: `^unsupported Terraform state location "gs://bucket/key"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.location, func(t *testing.T) {
+			store, err := New("dir", tc.location)
+			if tc.err != "" {
+				assert.Regexp(t, tc.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, store.String())
+		})
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kni-install-tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testStore(t, &Local{Dir: dir})
+}
+
+func TestSecret(t *testing.T) {
+	testStore(t, &Secret{Client: &fakeSecrets{}, Namespace: "ns", Name: "tfstate"})
+}
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	data, err := store.Read(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+
+	unlock, err := store.Lock(ctx, "create cluster")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = store.Lock(ctx, "destroy cluster")
+	if assert.IsType(t, &LockedError{}, err) {
+		assert.Equal(t, "create cluster", err.(*LockedError).Info.Operation)
+	}
+
+	assert.NoError(t, store.Write(ctx, []byte("state")))
+	data, err = store.Read(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "state", string(data))
+
+	assert.NoError(t, unlock())
+	err = WithLock(ctx, store, "destroy cluster", func() error {
+		return store.Delete(ctx)
+	})
+	assert.NoError(t, err)
+
+	data, err = store.Read(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+	assert.NoError(t, store.Delete(ctx))
+}
+
+// fakeSecrets holds one Secret, and rejects updates of stale copies of it.
+type fakeSecrets struct {
+	corev1client.SecretInterface
+	secret *corev1.Secret
+}
+
+func (f *fakeSecrets) Secrets(namespace string) corev1client.SecretInterface {
+	return f
+}
+
+func (f *fakeSecrets) Get(name string, options metav1.GetOptions) (*corev1.Secret, error) {
+	if f.secret == nil {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	return f.secret.DeepCopy(), nil
+}
+
+func (f *fakeSecrets) Create(secret *corev1.Secret) (*corev1.Secret, error) {
+	if f.secret != nil {
+		return nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "secrets"}, secret.Name)
+	}
+	f.secret = secret.DeepCopy()
+	f.secret.ResourceVersion = "1"
+	return f.secret.DeepCopy(), nil
+}
+
+func (f *fakeSecrets) Update(secret *corev1.Secret) (*corev1.Secret, error) {
+	if f.secret == nil || secret.ResourceVersion != f.secret.ResourceVersion {
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, secret.Name, nil)
+	}
+	version, _ := strconv.Atoi(f.secret.ResourceVersion)
+	f.secret = secret.DeepCopy()
+	f.secret.ResourceVersion = strconv.Itoa(version + 1)
+	return f.secret.DeepCopy(), nil
+}
@@ -1,7 +1,11 @@
 package terraform
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -10,9 +14,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
 	"github.com/metalkube/kni-installer/pkg/lineprinter"
+	"github.com/metalkube/kni-installer/pkg/simulate"
 	texec "github.com/metalkube/kni-installer/pkg/terraform/exec"
 	"github.com/metalkube/kni-installer/pkg/terraform/exec/plugins"
+	"github.com/metalkube/kni-installer/pkg/timing"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 )
 
 const (
@@ -21,14 +31,42 @@ const (
 
 	// VarFileName is the default name for Terraform var file.
 	VarFileName string = "terraform.tfvars"
+
+	// DebugDir is where the debug artifacts of a failed Terraform stage
+	// are kept, relative to the asset directory, in a subdirectory per
+	// stage.
+	DebugDir string = "debug/terraform"
+
+	// debugLogFileName is the Terraform debug log, including that of the
+	// providers, in the Terraform directory.
+	debugLogFileName string = "terraform-debug.log"
+
+	// planFileName is the plan of what is left to do after a failure.
+	planFileName string = "plan.txt"
 )
 
+// platformPlugins lists the embedded plugins which are only compiled in
+// with build tags, keyed by the platforms whose modules need them.
+var platformPlugins = map[string][]string{
+	baremetal.Name: {"terraform-provider-libvirt"},
+	libvirt.Name:   {"terraform-provider-libvirt"},
+}
+
 // Apply unpacks the platform-specific Terraform modules into the
 // given directory and then runs 'terraform init' and 'terraform
 // apply'.  It returns the absolute path of the tfstate file, rooted
 // in the specified directory, along with any errors from Terraform.
-func Apply(dir string, platform string, extraArgs ...string) (path string, err error) {
-	err = unpackAndInit(dir, platform)
+// Cancelling the context asks Terraform to stop gracefully.  The fake
+// platform, and simulated installs, are applied on the in-memory backend
+// instead.
+func Apply(ctx context.Context, dir string, platform string, extraArgs ...string) (path string, err error) {
+	if platform == fake.Name || simulate.FromContext(ctx) {
+		return Memory.apply(dir, extraArgs)
+	}
+
+	stop := timing.Start(ctx, timing.Terraform, "init")
+	err = unpackAndInit(ctx, dir, platform)
+	stop()
 	if err != nil {
 		return "", err
 	}
@@ -50,7 +88,15 @@ func Apply(dir string, platform string, extraArgs ...string) (path string, err e
 	defer lpDebug.Close()
 	defer lpError.Close()
 
-	if exitCode := texec.Apply(dir, args, lpDebug, lpError); exitCode != 0 {
+	debug, closeDebug := openDebugLog(dir)
+	defer closeDebug()
+
+	stop = timing.Start(ctx, timing.Terraform, "apply")
+	defer stop()
+	if exitCode := texec.Apply(ctx, dir, args, lpDebug, lpError, debug); exitCode != 0 {
+		if ctx.Err() != nil {
+			return sf, errors.Wrap(ctx.Err(), "Terraform apply interrupted")
+		}
 		return sf, errors.New("failed to apply using Terraform")
 	}
 	return sf, nil
@@ -58,9 +104,17 @@ func Apply(dir string, platform string, extraArgs ...string) (path string, err e
 
 // Destroy unpacks the platform-specific Terraform modules into the
 // given directory and then runs 'terraform init' and 'terraform
-// destroy'.
-func Destroy(dir string, platform string, extraArgs ...string) (err error) {
-	err = unpackAndInit(dir, platform)
+// destroy'.  Cancelling the context asks Terraform to stop gracefully.
+// The fake platform, and simulated installs, are destroyed on the
+// in-memory backend instead.
+func Destroy(ctx context.Context, dir string, platform string, extraArgs ...string) (err error) {
+	if platform == fake.Name || simulate.FromContext(ctx) {
+		return Memory.destroy(dir, extraArgs)
+	}
+
+	stop := timing.Start(ctx, timing.Terraform, "init")
+	err = unpackAndInit(ctx, dir, platform)
+	stop()
 	if err != nil {
 		return err
 	}
@@ -81,12 +135,63 @@ func Destroy(dir string, platform string, extraArgs ...string) (err error) {
 	defer lpDebug.Close()
 	defer lpError.Close()
 
-	if exitCode := texec.Destroy(dir, args, lpDebug, lpError); exitCode != 0 {
+	debug, closeDebug := openDebugLog(dir)
+	defer closeDebug()
+
+	stop = timing.Start(ctx, timing.Terraform, "destroy")
+	defer stop()
+	if exitCode := texec.Destroy(ctx, dir, args, lpDebug, lpError, debug); exitCode != 0 {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "Terraform destroy interrupted")
+		}
 		return errors.New("failed to destroy using Terraform")
 	}
 	return nil
 }
 
+// DebugArtifacts returns the artifacts for debugging a failed Terraform
+// stage in the given directory, keyed by file name: a snapshot of the
+// state, the debug log of Terraform and its providers, and the plan of
+// what is left to do, made with planArgs (e.g. the -var-file and -target
+// arguments of the failed command, and -destroy for a destroy).  Artifacts
+// which cannot be collected are logged and left out.
+func DebugArtifacts(ctx context.Context, dir string, planArgs ...string) map[string][]byte {
+	artifacts := map[string][]byte{}
+	for _, name := range []string{StateFileName, debugLogFileName} {
+		data, err := fileaudit.ReadFile(filepath.Join(dir, name), "Terraform debug artifact")
+		if err != nil {
+			logrus.Debugf("Failed to collect %s: %v", name, err)
+			continue
+		}
+		artifacts[name] = data
+	}
+
+	var plan, planErrors bytes.Buffer
+	args := append([]string{
+		"-input=false",
+		fmt.Sprintf("-state=%s", filepath.Join(dir, StateFileName)),
+	}, planArgs...)
+	args = append(args, dir)
+	if exitCode := texec.Plan(ctx, dir, args, &plan, &planErrors, ioutil.Discard); exitCode != 0 {
+		plan.WriteString("\nPlanning failed:\n")
+		plan.Write(planErrors.Bytes())
+	}
+	artifacts[planFileName] = plan.Bytes()
+	return artifacts
+}
+
+// openDebugLog opens the debug log in the given directory for appending.
+// If it cannot be opened, Terraform runs without it.
+func openDebugLog(dir string) (io.Writer, func()) {
+	f, err := os.OpenFile(filepath.Join(dir, debugLogFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		logrus.Debugf("Failed to open the Terraform debug log: %v", err)
+		return nil, func() {}
+	}
+	fileaudit.Record(fileaudit.Write, f.Name(), "Terraform debug log", nil)
+	return f, func() { f.Close() }
+}
+
 // unpack unpacks the platform-specific Terraform modules into the
 // given directory.
 func unpack(dir string, platform string) (err error) {
@@ -105,7 +210,13 @@ func unpack(dir string, platform string) (err error) {
 
 // unpackAndInit unpacks the platform-specific Terraform modules into
 // the given directory and then runs 'terraform init'.
-func unpackAndInit(dir string, platform string) (err error) {
+func unpackAndInit(ctx context.Context, dir string, platform string) (err error) {
+	for _, name := range platformPlugins[platform] {
+		if _, ok := plugins.KnownPlugins[name]; !ok {
+			return errors.Errorf("the %s platform requires %s, which is not available in this build of kni-install (%s/%s); rebuild with TAGS=libvirt on Linux", platform, name, runtime.GOOS, runtime.GOARCH)
+		}
+	}
+
 	err = unpack(dir, platform)
 	if err != nil {
 		return errors.Wrap(err, "failed to unpack Terraform modules")
@@ -126,7 +237,10 @@ func unpackAndInit(dir string, platform string) (err error) {
 		"-get-plugins=false",
 	}
 	args = append(args, dir)
-	if exitCode := texec.Init(dir, args, lpDebug, lpError); exitCode != 0 {
+	debug, closeDebug := openDebugLog(dir)
+	defer closeDebug()
+
+	if exitCode := texec.Init(ctx, dir, args, lpDebug, lpError, debug); exitCode != 0 {
 		return errors.New("failed to initialize Terraform")
 	}
 	return nil
@@ -24,6 +24,9 @@ type config struct {
 
 // TFVars generates AWS-specific Terraform variables launching the cluster.
 func TFVars(masterConfigs []*v1beta1.AWSMachineProviderConfig) ([]byte, error) {
+	if len(masterConfigs) == 0 {
+		return nil, errors.New("master slice cannot be empty")
+	}
 	masterConfig := masterConfigs[0]
 
 	tags := make(map[string]string, len(masterConfig.Tags))
@@ -57,6 +60,10 @@ func TFVars(masterConfigs []*v1beta1.AWSMachineProviderConfig) ([]byte, error) {
 		return nil, errors.New("EBS IOPS must be configured for the io1 root volume")
 	}
 
+	if masterConfig.AMI.ID == nil {
+		return nil, errors.New("AMI ID must be configured")
+	}
+
 	instanceClass := defaults.InstanceClass(masterConfig.Placement.Region)
 
 	cfg := &config{
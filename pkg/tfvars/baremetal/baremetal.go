@@ -2,32 +2,96 @@
 package baremetal
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
+	"net"
 
 	libvirttfvars "github.com/metalkube/kni-installer/pkg/tfvars/libvirt"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
 	"github.com/pkg/errors"
 )
 
 type config struct {
-	URI             string `json:"libvirt_uri,omitempty"`
-	Image           string `json:"os_image,omitempty"`
-	BareMetalBridge string `json:"baremetal_bridge,omitempty"`
-	OverCloudBridge string `json:"overcloud_bridge,omitempty"`
+	URI                    string `json:"libvirt_uri,omitempty"`
+	Image                  string `json:"os_image,omitempty"`
+	BareMetalBridge        string `json:"baremetal_bridge,omitempty"`
+	OverCloudBridge        string `json:"overcloud_bridge,omitempty"`
+	DomainXSLT             string `json:"domain_xslt,omitempty"`
+	IgnitionRegistryMirror string `json:"ignition_registry_mirror,omitempty"`
+
+	VirtualMasterCount    int      `json:"virtual_master_count,omitempty"`
+	VirtualMasterMemory   int      `json:"virtual_master_memory,omitempty"`
+	VirtualMasterVCPU     int      `json:"virtual_master_vcpu,omitempty"`
+	VirtualMasterDiskSize int64    `json:"virtual_master_disk_size,omitempty"`
+	VirtualMasterNames    []string `json:"virtual_master_names,omitempty"`
+	VirtualMasterMACs     []string `json:"virtual_master_macs,omitempty"`
 }
 
 // TFVars generates bare metal specific Terraform variables.
-func TFVars(libvirtURI, osImage, baremetalBridge, overcloudBridge string) ([]byte, error) {
-	osImage, err := libvirttfvars.CachedImage(osImage)
+// registryMirrorIgn is the ignition config of the registry mirror VM, or
+// empty for no registry mirror.  virtualControlPlane, when not nil, runs
+// masterCount control plane VMs, named and addressed by the master hosts
+// of hosts if there are any.
+func TFVars(ctx context.Context, infraID, libvirtURI, osImage, baremetalBridge, overcloudBridge string, userTags map[string]string, registryMirrorIgn string, virtualControlPlane *baremetal.VirtualControlPlane, hosts []baremetal.Host, masterCount int) ([]byte, error) {
+	osImage, err := libvirttfvars.CachedImage(ctx, osImage)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to use cached libvirt image")
 	}
 
+	xslt, err := DomainXSLT(infraID, userTags)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &config{
-		URI:             libvirtURI,
-		Image:           osImage,
-		BareMetalBridge: baremetalBridge,
-		OverCloudBridge: overcloudBridge,
+		URI:                    libvirtURI,
+		Image:                  osImage,
+		BareMetalBridge:        baremetalBridge,
+		OverCloudBridge:        overcloudBridge,
+		DomainXSLT:             xslt,
+		IgnitionRegistryMirror: registryMirrorIgn,
+	}
+
+	if v := virtualControlPlane; v != nil {
+		cfg.VirtualMasterCount = masterCount
+		cfg.VirtualMasterMemory = v.MemoryMiB
+		cfg.VirtualMasterVCPU = v.CPUs
+		cfg.VirtualMasterDiskSize = int64(v.DiskGiB) * 1024 * 1024 * 1024
+		cfg.VirtualMasterNames, cfg.VirtualMasterMACs = VirtualMasters(infraID, baremetal.HostsWithRole(hosts, baremetal.MasterRole), masterCount)
 	}
 
 	return json.MarshalIndent(cfg, "", "  ")
 }
+
+// VirtualMasters returns the hostnames and MAC addresses of the count
+// control plane VMs: those of the master hosts, in inventory order, or
+// <infraID>-master-<index> and a MAC address derived from it.  The
+// derived addresses are stable, so DHCP reservations can be made for them
+// before the install.
+func VirtualMasters(infraID string, masterHosts []baremetal.Host, count int) (names []string, macs []string) {
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("%s-master-%d", infraID, i)
+		var mac string
+		if i < len(masterHosts) {
+			name = masterHosts[i].Name
+			if hw, err := net.ParseMAC(masterHosts[i].BootMACAddress); err == nil {
+				mac = hw.String()
+			}
+		}
+		if mac == "" {
+			mac = virtualMAC(infraID, i)
+		}
+		names = append(names, name)
+		macs = append(macs, mac)
+	}
+	return names, macs
+}
+
+// virtualMAC returns the MAC address of the index'th control plane VM of
+// the cluster, under QEMU's 52:54:00 prefix.
+func virtualMAC(infraID string, index int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s-master-%d", infraID, index)))
+	return net.HardwareAddr{0x52, 0x54, 0x00, sum[0], sum[1], sum[2]}.String()
+}
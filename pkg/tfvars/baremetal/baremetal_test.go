@@ -0,0 +1,26 @@
+package baremetal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestVirtualMasters(t *testing.T) {
+	names, macs := VirtualMasters("test-cluster-x7k2p", []baremetal.Host{
+		{Name: "cp-0", Role: baremetal.MasterRole, BootMACAddress: "52:54:00:AA:BB:CC"},
+		{Name: "cp-1", Role: baremetal.MasterRole},
+	}, 3)
+	assert.Equal(t, []string{"cp-0", "cp-1", "test-cluster-x7k2p-master-2"}, names)
+	if assert.Len(t, macs, 3) {
+		assert.Equal(t, "52:54:00:aa:bb:cc", macs[0])
+		assert.Regexp(t, "^52:54:00(:[0-9a-f]{2}){3}$", macs[1])
+		assert.NotEqual(t, macs[1], macs[2])
+	}
+
+	// The derived addresses are stable.
+	_, again := VirtualMasters("test-cluster-x7k2p", nil, 3)
+	assert.Equal(t, macs[1:], again[1:])
+}
@@ -0,0 +1,62 @@
+package baremetal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// domainXSLT appends the cluster's DomainMetadata to the libvirt domain XML
+// generated by the Terraform provider, which has no native support for
+// domain metadata.  libvirt drops metadata elements without a namespace
+// prefix, so the element is written out by hand rather than marshalled.
+var domainXSLT = template.Must(template.New("domain-xslt").Funcs(template.FuncMap{
+	"xml": func(s string) (string, error) {
+		buf := &bytes.Buffer{}
+		err := xml.EscapeText(buf, []byte(s))
+		return buf.String(), err
+	},
+}).Parse(`<?xml version="1.0"?>
+<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:output omit-xml-declaration="yes" indent="yes"/>
+  <xsl:template match="node()|@*">
+    <xsl:copy>
+      <xsl:apply-templates select="node()|@*"/>
+    </xsl:copy>
+  </xsl:template>
+  <xsl:template match="/domain">
+    <xsl:copy>
+      <xsl:apply-templates select="node()|@*"/>
+      <metadata>
+        <kni:cluster xmlns:kni="{{.Namespace}}">
+          <kni:infraID>{{xml .Metadata.InfraID}}</kni:infraID>
+{{- range .Metadata.Tags}}
+          <kni:tag key="{{xml .Key}}" value="{{xml .Value}}"/>
+{{- end}}
+        </kni:cluster>
+      </metadata>
+    </xsl:copy>
+  </xsl:template>
+</xsl:stylesheet>
+`))
+
+// DomainXSLT returns an XSLT stylesheet which tags libvirt domains with the
+// infrastructure ID and user tags.
+func DomainXSLT(infraID string, userTags map[string]string) (string, error) {
+	buf := &bytes.Buffer{}
+	err := domainXSLT.Execute(buf, struct {
+		Namespace string
+		Metadata  *baremetal.DomainMetadata
+	}{
+		Namespace: baremetal.DomainMetadataNamespace,
+		Metadata:  baremetal.NewDomainMetadata(infraID, userTags),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to execute domain XSLT template")
+	}
+	return buf.String(), nil
+}
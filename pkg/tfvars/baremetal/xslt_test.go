@@ -0,0 +1,35 @@
+package baremetal
+
+import (
+	"encoding/xml"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestDomainXSLT(t *testing.T) {
+	xslt, err := DomainXSLT("test-cluster-x7k2p", map[string]string{
+		"owner":           "a&b",
+		"example.com/env": "ci",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The stylesheet must itself be well-formed XML.
+	assert.NoError(t, xml.Unmarshal([]byte(xslt), new(interface{})))
+
+	// What libvirt hands back from virDomainGetMetadata round-trips.
+	element := regexp.MustCompile(`(?s)<kni:cluster .*</kni:cluster>`).FindString(xslt)
+	metadata := &baremetal.DomainMetadata{}
+	if assert.NoError(t, xml.Unmarshal([]byte(element), metadata)) {
+		assert.Equal(t, "test-cluster-x7k2p", metadata.InfraID)
+		assert.Equal(t, []baremetal.DomainTag{
+			{Key: "example.com/env", Value: "ci"},
+			{Key: "owner", Value: "a&b"},
+		}, metadata.Tags)
+	}
+}
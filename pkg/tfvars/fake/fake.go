@@ -0,0 +1,21 @@
+// Package fake contains fake-platform Terraform-variable logic.
+package fake
+
+import (
+	"encoding/json"
+
+	machineapi "github.com/openshift/cluster-api/pkg/apis/machine/v1beta1"
+)
+
+type config struct {
+	MasterNames []string `json:"fake_master_names"`
+}
+
+// TFVars generates fake-platform Terraform variables.
+func TFVars(masters []machineapi.Machine) ([]byte, error) {
+	cfg := &config{}
+	for _, master := range masters {
+		cfg.MasterNames = append(cfg.MasterNames, master.Name)
+	}
+	return json.MarshalIndent(cfg, "", "  ")
+}
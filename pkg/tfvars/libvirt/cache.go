@@ -1,79 +1,105 @@
 package libvirt
 
 import (
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/gregjones/httpcache"
-	"github.com/gregjones/httpcache/diskcache"
-	"github.com/peterbourgon/diskv"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sys/unix"
+
+	"github.com/metalkube/kni-installer/pkg/cache"
+	"github.com/metalkube/kni-installer/pkg/download"
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+	"github.com/metalkube/kni-installer/pkg/offline"
+	"github.com/metalkube/kni-installer/pkg/rhcos"
 )
 
 // FIXME: baremetal
 // Export cachedImage for reuse by baremetal
-func CachedImage(uri string) (string, error) {
-	return cachedImage(uri)
+func CachedImage(ctx context.Context, uri string) (string, error) {
+	return cachedImage(ctx, uri)
 }
 
 // cachedImage leaves non-file:// image URIs unalterered.
-// Other URIs are retrieved with a local cache at
-// $XDG_CACHE_HOME/kni-install/libvirt [1].  This allows you to
+// Other URIs are retrieved with a local cache at libvirt/image in the
+// installer's cache directory (see pkg/cache).  This allows you to
 // use the same remote image URI multiple times without needing to
 // worry about redundant downloads, although you will want to
-// periodically blow away your cache.
-//
-// [1]: https://standards.freedesktop.org/basedir-spec/basedir-spec-0.7.html
-func cachedImage(uri string) (string, error) {
+// periodically clean your cache with kni-install cache clean.  RHCOS
+// images fall back to the configured mirrors, and downloads are rate
+// limited as configured.  Images are streamed to disk, never held in
+// memory, and keyed by the ETag of the response.  Once an image is cached,
+// it is fetched again with If-None-Match and If-Modified-Since, so an
+// unchanged image is not sent again.  The download is cancelled with ctx.
+func cachedImage(ctx context.Context, uri string) (string, error) {
 	if strings.HasPrefix(uri, "file://") {
 		return uri, nil
 	}
+	if err := offline.Check(ctx, "download the OS image"); err != nil {
+		return uri, err
+	}
 
 	logrus.Infof("Fetching OS image: %s", filepath.Base(uri))
 
-	// FIXME: Use os.UserCacheDir() once we bump to Go 1.11
-	// baseCacheDir, err := os.UserCacheDir()
-	// if err != nil {
-	// 	return uri, err
-	// }
-	baseCacheDir := filepath.Join(os.Getenv("HOME"), ".cache")
+	baseCacheDir, err := cache.Dir()
+	if err != nil {
+		return uri, err
+	}
+	cacheDir := filepath.Join(baseCacheDir, "libvirt")
 
-	cacheDir := filepath.Join(baseCacheDir, "kni-install", "libvirt")
+	// Earlier installers also kept a copy of every response in an HTTP
+	// cache, which was buffered in memory as it was written.
 	httpCacheDir := filepath.Join(cacheDir, "http")
-	err := os.MkdirAll(httpCacheDir, 0777)
+	if _, err := os.Stat(httpCacheDir); err == nil {
+		logrus.Debugf("Removing the obsolete HTTP cache %q", httpCacheDir)
+		if err := os.RemoveAll(httpCacheDir); err != nil {
+			logrus.Warnf("Failed to remove the obsolete HTTP cache %q: %v", httpCacheDir, err)
+		} else {
+			fileaudit.Record(fileaudit.Remove, httpCacheDir, "obsolete HTTP cache", nil)
+		}
+	}
+
+	imageCacheDir := filepath.Join(cacheDir, "image")
+	err = os.MkdirAll(imageCacheDir, 0777)
 	if err != nil {
 		return uri, err
 	}
 
-	cache := diskcache.NewWithDiskv(diskv.New(diskv.Options{
-		BasePath:     httpCacheDir,
-		CacheSizeMax: 0, // This stops the diskcache from caching the resp in memory.
-	}))
-	transport := httpcache.NewTransport(cache)
-	resp, err := transport.Client().Get(uri)
+	source := findSource(imageCacheDir, uri)
+	var header http.Header
+	if source != nil {
+		header = http.Header{}
+		header.Set("If-None-Match", source.ETag)
+		if source.LastModified != "" {
+			header.Set("If-Modified-Since", source.LastModified)
+		}
+	}
+
+	resp, err := download.GetConditional(ctx, http.DefaultClient, rhcos.URLs(uri), header)
 	if err != nil {
 		return uri, err
 	}
-	if resp.StatusCode != 200 {
-		return uri, fmt.Errorf("%s while getting %s", resp.Status, uri)
-	}
 	defer resp.Body.Close()
 
-	key, err := cacheKey(resp.Header.Get("ETag"))
-	if err != nil {
-		return uri, fmt.Errorf("invalid ETag for %s: %v", uri, err)
+	if resp.StatusCode == http.StatusNotModified {
+		imagePath := filepath.Join(imageCacheDir, source.key)
+		logrus.Debugf("Using cached OS image %q, which is not modified", imagePath)
+		return fmt.Sprintf("file://%s", filepath.ToSlash(imagePath)), nil
 	}
 
-	imageCacheDir := filepath.Join(cacheDir, "image")
-	err = os.MkdirAll(imageCacheDir, 0777)
+	etag := resp.Header.Get("ETag")
+	key, err := cacheKey(etag)
 	if err != nil {
-		return uri, err
+		return uri, fmt.Errorf("invalid ETag for %s: %v", uri, err)
 	}
 
 	imagePath := filepath.Join(imageCacheDir, key)
@@ -91,9 +117,69 @@ func cachedImage(uri string) (string, error) {
 		}
 	}
 
+	if source != nil && source.key != key {
+		// The image changed, so the source of the previous one is stale.
+		stale := filepath.Join(imageCacheDir, source.key+".source")
+		if err := os.Remove(stale); err == nil {
+			fileaudit.Record(fileaudit.Remove, stale, "stale OS image source", nil)
+		} else if !os.IsNotExist(err) {
+			logrus.Warnf("Failed to remove the stale OS image source %q: %v", stale, err)
+		}
+	}
+
+	source = &imageSource{URI: uri, ETag: etag, LastModified: resp.Header.Get("Last-Modified")}
+	if err := writeSource(imagePath, source); err != nil {
+		logrus.Warnf("Failed to record where the cached OS image %q came from: %v", imagePath, err)
+	}
+
 	return fmt.Sprintf("file://%s", filepath.ToSlash(imagePath)), nil
 }
 
+// imageSource is where a cached image was last fetched from, with the
+// validators of the response, so the next fetch can be conditional.  It is
+// kept next to the image, in a .source file.
+type imageSource struct {
+	URI          string `json:"uri"`
+	ETag         string `json:"etag"`
+	LastModified string `json:"lastModified,omitempty"`
+
+	// key is the name of the cached image.
+	key string
+}
+
+// findSource returns the source of the image cached in dir for uri, or nil
+// if there is none.
+func findSource(dir, uri string) *imageSource {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.source"))
+	if err != nil {
+		return nil
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		source := &imageSource{}
+		if err := json.Unmarshal(data, source); err != nil || source.URI != uri || source.ETag == "" {
+			continue
+		}
+		source.key = strings.TrimSuffix(filepath.Base(path), ".source")
+		if _, err := os.Stat(filepath.Join(dir, source.key)); err != nil {
+			continue
+		}
+		return source
+	}
+	return nil
+}
+
+func writeSource(imagePath string, source *imageSource) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return fileaudit.WriteFile(imagePath+".source", data, 0644, "OS image source")
+}
+
 func cacheKey(etag string) (key string, err error) {
 	if etag == "" {
 		return "", fmt.Errorf("caching is not supported when ETag is unset")
@@ -129,12 +215,12 @@ func cacheImage(reader io.Reader, imagePath string) (err error) {
 		}
 	}()
 
-	err = unix.Flock(int(flock.Fd()), unix.LOCK_EX)
+	err = lockFile(flock)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		err2 := unix.Flock(int(flock.Fd()), unix.LOCK_UN)
+		err2 := unlockFile(flock)
 		if err == nil {
 			err = err2
 		}
@@ -157,7 +243,8 @@ func cacheImage(reader io.Reader, imagePath string) (err error) {
 		}
 	}()
 
-	_, err = io.Copy(file, reader)
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), reader)
 	if err != nil {
 		return err
 	}
@@ -168,5 +255,18 @@ func cacheImage(reader io.Reader, imagePath string) (err error) {
 	}
 	closed = true
 
-	return os.Rename(tempPath, imagePath)
+	// Record the digest in the format of sha256sum, so the cached image
+	// can be checked without the installer.
+	digest := hex.EncodeToString(hash.Sum(nil))
+	logrus.Debugf("Cached OS image %q has SHA-256 %s", imagePath, digest)
+	err = fileaudit.WriteFile(imagePath+".sha256", []byte(fmt.Sprintf("%s  %s\n", digest, filepath.Base(imagePath))), 0644, "OS image digest")
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tempPath, imagePath)
+	if err == nil {
+		fileaudit.Record(fileaudit.Write, imagePath, "cached OS image", nil)
+	}
+	return err
 }
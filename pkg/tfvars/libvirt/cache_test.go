@@ -0,0 +1,106 @@
+package libvirt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/cache"
+)
+
+func TestCachedImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(cache.EnvVar)
+	os.Setenv(cache.EnvVar, dir)
+
+	httpCacheDir := filepath.Join(dir, "libvirt", "http")
+	if !assert.NoError(t, os.MkdirAll(httpCacheDir, 0777)) {
+		return
+	}
+
+	image := strings.Repeat("image", 1024)
+	etag := `"1"`
+	var served, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 01 Jul 2019 00:00:00 GMT")
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == "Mon, 01 Jul 2019 00:00:00 GMT" {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		served++
+		w.Write([]byte(image))
+	}))
+	defer server.Close()
+
+	uri, err := cachedImage(context.Background(), server.URL+"/image.qcow2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.True(t, strings.HasPrefix(uri, "file://"), uri) {
+		return
+	}
+	imagePath := filepath.FromSlash(strings.TrimPrefix(uri, "file://"))
+
+	data, err := ioutil.ReadFile(imagePath)
+	assert.NoError(t, err)
+	assert.Equal(t, image, string(data))
+
+	digest := sha256.Sum256([]byte(image))
+	data, err = ioutil.ReadFile(imagePath + ".sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(digest[:])+"  "+filepath.Base(imagePath)+"\n", string(data))
+
+	_, err = os.Stat(httpCacheDir)
+	assert.True(t, os.IsNotExist(err), "the obsolete HTTP cache was not removed")
+
+	cached, err := cachedImage(context.Background(), server.URL+"/image.qcow2")
+	assert.NoError(t, err)
+	assert.Equal(t, uri, cached)
+	assert.Equal(t, 1, served, "the cached image was sent again")
+	assert.Equal(t, 1, notModified)
+
+	// An image whose source was lost is fetched unconditionally.
+	assert.NoError(t, os.Remove(imagePath+".source"))
+	cached, err = cachedImage(context.Background(), server.URL+"/image.qcow2")
+	assert.NoError(t, err)
+	assert.Equal(t, uri, cached)
+	assert.Equal(t, 2, served)
+	_, err = os.Stat(imagePath + ".source")
+	assert.NoError(t, err)
+
+	// A changed image replaces the source of the previous one.
+	etag = `"2"`
+	cached, err = cachedImage(context.Background(), server.URL+"/image.qcow2")
+	assert.NoError(t, err)
+	assert.NotEqual(t, uri, cached)
+	assert.Equal(t, 3, served)
+	_, err = os.Stat(imagePath + ".source")
+	assert.True(t, os.IsNotExist(err), "the stale source was not removed")
+	_, err = os.Stat(filepath.FromSlash(strings.TrimPrefix(cached, "file://")) + ".source")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cachedImage(ctx, server.URL+"/image.qcow2")
+	assert.Error(t, err, "the download was not cancelled")
+
+	uri = "file:///path/to/image.qcow2"
+	cached, err = cachedImage(context.Background(), uri)
+	assert.NoError(t, err)
+	assert.Equal(t, uri, cached)
+}
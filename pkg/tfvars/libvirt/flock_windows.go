@@ -0,0 +1,18 @@
+package libvirt
+
+import (
+	"os"
+)
+
+// lockFile is a no-op on Windows.  The image cache is only shared
+// between concurrent installers on libvirt hypervisor hosts, which are
+// never Windows machines, so the temp-file-and-rename in cacheImage is
+// sufficient here.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on Windows.
+func unlockFile(f *os.File) error {
+	return nil
+}
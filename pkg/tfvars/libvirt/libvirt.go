@@ -2,6 +2,7 @@
 package libvirt
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -9,18 +10,27 @@ import (
 	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/openshift/cluster-api-provider-libvirt/pkg/apis/libvirtproviderconfig/v1alpha1"
 	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 )
 
 type config struct {
-	URI         string   `json:"libvirt_uri,omitempty"`
-	Image       string   `json:"os_image,omitempty"`
-	IfName      string   `json:"libvirt_network_if"`
-	MasterIPs   []string `json:"libvirt_master_ips,omitempty"`
-	BootstrapIP string   `json:"libvirt_bootstrap_ip,omitempty"`
+	URI           string   `json:"libvirt_uri,omitempty"`
+	Image         string   `json:"os_image,omitempty"`
+	IfName        string   `json:"libvirt_network_if"`
+	MasterIPs     []string `json:"libvirt_master_ips,omitempty"`
+	BootstrapIP   string   `json:"libvirt_bootstrap_ip,omitempty"`
+	Firmware      string   `json:"libvirt_firmware,omitempty"`
+	NVRAMTemplate string   `json:"libvirt_nvram_template,omitempty"`
+	SecureBoot    bool     `json:"libvirt_secure_boot,omitempty"`
 }
 
 // TFVars generates libvirt-specific Terraform variables.
-func TFVars(masterConfig *v1alpha1.LibvirtMachineProviderConfig, osImage string, machineCIDR *net.IPNet, bridge string, masterCount int) ([]byte, error) {
+func TFVars(ctx context.Context, masterConfig *v1alpha1.LibvirtMachineProviderConfig, osImage string, machineCIDR *net.IPNet, bridge string, masterCount int, firmware *libvirt.Firmware) ([]byte, error) {
+	if machineCIDR == nil {
+		return nil, errors.New("machine CIDR is required")
+	}
+
 	bootstrapIP, err := cidr.Host(machineCIDR, 10)
 	if err != nil {
 		return nil, errors.Errorf("failed to generate bootstrap IP: %v", err)
@@ -31,7 +41,7 @@ func TFVars(masterConfig *v1alpha1.LibvirtMachineProviderConfig, osImage string,
 		return nil, err
 	}
 
-	osImage, err = cachedImage(osImage)
+	osImage, err = cachedImage(ctx, osImage)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to use cached libvirt image")
 	}
@@ -44,6 +54,12 @@ func TFVars(masterConfig *v1alpha1.LibvirtMachineProviderConfig, osImage string,
 		MasterIPs:   masterIPs,
 	}
 
+	if firmware != nil && firmware.Type == libvirt.FirmwareUEFI {
+		cfg.Firmware = firmware.Loader
+		cfg.NVRAMTemplate = firmware.NVRAMTemplate
+		cfg.SecureBoot = firmware.SecureBoot
+	}
+
 	return json.MarshalIndent(cfg, "", "  ")
 }
 
@@ -4,6 +4,8 @@ package tfvars
 import (
 	"encoding/json"
 	"net"
+
+	"github.com/pkg/errors"
 )
 
 type config struct {
@@ -19,6 +21,10 @@ type config struct {
 
 // TFVars generates terraform.tfvar JSON for launching the cluster.
 func TFVars(clusterID string, clusterDomain string, baseDomain string, machineCIDR *net.IPNet, bootstrapIgn string, masterIgn string, masterCount int) ([]byte, error) {
+	if machineCIDR == nil {
+		return nil, errors.New("machine CIDR is required")
+	}
+
 	config := &config{
 		ClusterID:         clusterID,
 		ClusterDomain:     clusterDomain,
@@ -0,0 +1,203 @@
+// Package timing records how long each stage of an install or destroy
+// takes, so the slowest parts can be reported when it completes.
+//
+// Stages are recorded on a Recorder carried by the context.  They nest,
+// e.g. Terraform's apply runs while the Cluster asset is generated, and
+// each stage is charged only for the time not spent in the stages nested
+// within it, so the stages add up to the time elapsed.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+// Stage groups.
+const (
+	// Assets are the generation of assets, named by their package below
+	// pkg/asset, e.g. tls or ignition/bootstrap.
+	Assets = "assets"
+	// Terraform are Terraform's init, apply and destroy.
+	Terraform = "terraform"
+	// Wait are the waits for the cluster, e.g. for bootstrapping to
+	// complete.
+	Wait = "wait"
+	// Destroy is the deletion of the cluster's resources.
+	Destroy = "destroy"
+)
+
+type key struct {
+	group string
+	name  string
+}
+
+type open struct {
+	key    key
+	start  time.Time
+	nested time.Duration
+}
+
+// Recorder records the time spent in each stage.
+type Recorder struct {
+	now   func() time.Time
+	start time.Time
+
+	lock   sync.Mutex
+	open   []*open
+	stages map[key]time.Duration
+	order  []key
+}
+
+// NewRecorder returns a Recorder whose total starts now.
+func NewRecorder() *Recorder {
+	return newRecorder(time.Now)
+}
+
+func newRecorder(now func() time.Time) *Recorder {
+	return &Recorder{
+		now:    now,
+		start:  now(),
+		stages: map[key]time.Duration{},
+	}
+}
+
+// Start starts timing the stage name of group, and returns a function
+// which stops it.  Stages started while it runs are nested within it.
+func (r *Recorder) Start(group, name string) func() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	stage := &open{key: key{group: group, name: name}, start: r.now()}
+	r.open = append(r.open, stage)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { r.stop(stage) })
+	}
+}
+
+func (r *Recorder) stop(stage *open) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := len(r.open) - 1
+	for ; i >= 0 && r.open[i] != stage; i-- {
+	}
+	if i < 0 {
+		return
+	}
+	r.open = append(r.open[:i], r.open[i+1:]...)
+
+	elapsed := r.now().Sub(stage.start)
+	if i > 0 {
+		r.open[i-1].nested += elapsed
+	}
+	if _, ok := r.stages[stage.key]; !ok {
+		r.order = append(r.order, stage.key)
+	}
+	r.stages[stage.key] += elapsed - stage.nested
+}
+
+// Timings returns the time elapsed since the Recorder was created, and
+// spent in each stage so far, in the order the stages were first
+// recorded.
+func (r *Recorder) Timings() *types.TimingMetadata {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	timings := &types.TimingMetadata{
+		TotalSeconds: seconds(r.now().Sub(r.start)),
+		Stages:       make([]types.StageTiming, 0, len(r.order)),
+	}
+	for _, k := range r.order {
+		timings.Stages = append(timings.Stages, types.StageTiming{
+			Group:   k.group,
+			Name:    k.name,
+			Seconds: seconds(r.stages[k]),
+		})
+	}
+	return timings
+}
+
+func seconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx which records stages on r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the Recorder of ctx, or nil if it has none.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(contextKey{}).(*Recorder)
+	return r
+}
+
+// Start starts timing the stage name of group on the Recorder of ctx, and
+// returns a function which stops it.  Without a Recorder, nothing is
+// recorded.
+func Start(ctx context.Context, group, name string) func() {
+	r := FromContext(ctx)
+	if r == nil {
+		return func() {}
+	}
+	return r.Start(group, name)
+}
+
+// maxStages is the number of stages Log lists for each group, slowest
+// first.
+const maxStages = 5
+
+// Log logs the time spent in each group of stages, slowest first, with
+// its slowest stages.
+func Log(logger logrus.FieldLogger, timings *types.TimingMetadata) {
+	type group struct {
+		name    string
+		seconds float64
+		stages  []types.StageTiming
+	}
+	var groups []*group
+	byName := map[string]*group{}
+	for _, stage := range timings.Stages {
+		g, ok := byName[stage.Group]
+		if !ok {
+			g = &group{name: stage.Group}
+			byName[stage.Group] = g
+			groups = append(groups, g)
+		}
+		g.seconds += stage.Seconds
+		g.stages = append(g.stages, stage)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].seconds > groups[j].seconds })
+
+	logger.Infof("Time elapsed: %s", duration(timings.TotalSeconds))
+	for _, g := range groups {
+		sort.SliceStable(g.stages, func(i, j int) bool { return g.stages[i].Seconds > g.stages[j].Seconds })
+		var parts []string
+		for i, stage := range g.stages {
+			if i == maxStages {
+				parts = append(parts, fmt.Sprintf("and %d more", len(g.stages)-maxStages))
+				break
+			}
+			parts = append(parts, fmt.Sprintf("%s %s", stage.Name, duration(stage.Seconds)))
+		}
+		logger.Infof("  %s: %s (%s)", g.name, duration(g.seconds), strings.Join(parts, ", "))
+	}
+}
+
+func duration(seconds float64) time.Duration {
+	d := time.Duration(seconds * float64(time.Second))
+	if d >= time.Minute {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,71 @@
+package timing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestRecorder(t *testing.T) {
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	advance := func(d time.Duration) { now = now.Add(d) }
+
+	r := newRecorder(clock)
+	ctx := NewContext(context.Background(), r)
+
+	stop := Start(ctx, Assets, "tls")
+	advance(30 * time.Second)
+	stop()
+	stop()
+
+	stopCluster := Start(ctx, Assets, "cluster")
+	advance(time.Second)
+	stopInit := Start(ctx, Terraform, "init")
+	advance(10 * time.Second)
+	stopInit()
+	stopApply := Start(ctx, Terraform, "apply")
+	advance(5 * time.Minute)
+	stopApply()
+	advance(2 * time.Second)
+	stopCluster()
+
+	stop = Start(ctx, Assets, "tls")
+	advance(5 * time.Second)
+	stop()
+
+	stop = Start(ctx, Wait, "bootstrap")
+	advance(20 * time.Minute)
+	stop()
+	advance(time.Second)
+
+	Start(context.Background(), Wait, "unrecorded")()
+
+	assert.Equal(t, &types.TimingMetadata{
+		TotalSeconds: 1549,
+		Stages: []types.StageTiming{
+			{Group: Assets, Name: "tls", Seconds: 35},
+			{Group: Terraform, Name: "init", Seconds: 10},
+			{Group: Terraform, Name: "apply", Seconds: 300},
+			{Group: Assets, Name: "cluster", Seconds: 3},
+			{Group: Wait, Name: "bootstrap", Seconds: 1200},
+		},
+	}, r.Timings())
+
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &out
+	logger.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+	Log(logger, r.Timings())
+	assert.Equal(t, `level=info msg="Time elapsed: 25m49s"
+level=info msg="  wait: 20m0s (bootstrap 20m0s)"
+level=info msg="  terraform: 5m10s (apply 5m0s, init 10s)"
+level=info msg="  assets: 38s (tls 35s, cluster 3s)"
+`, out.String())
+}
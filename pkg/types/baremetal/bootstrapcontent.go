@@ -0,0 +1,17 @@
+package baremetal
+
+// BootstrapContent moves the contents of the bootstrap Ignition config's
+// large files out of the config, to be served over HTTP, for boot paths
+// which limit the size of the config, such as some BMCs' virtual media.
+type BootstrapContent struct {
+	// URL is the base URL the contents are served from.  The installer
+	// writes them to the bootstrap-content directory of the asset
+	// directory, named by their SHA-512, which must be served at URL.
+	URL string `json:"url"`
+
+	// MinSize is the size in bytes from which a file's contents are
+	// served rather than embedded in the config.
+	// +optional
+	// Default is 4096.
+	MinSize int `json:"minSize,omitempty"`
+}
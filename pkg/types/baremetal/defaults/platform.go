@@ -7,6 +7,33 @@ import (
 const (
 	// DefaultURI is the default URI of the libvirtd connection.
 	DefaultURI = "qemu:///system"
+
+	// DefaultRegistryMirrorPort is the default port of the registry mirror.
+	DefaultRegistryMirrorPort = 5000
+
+	// DefaultRegistryMirrorImage is the default registry container image
+	// of the registry mirror.
+	DefaultRegistryMirrorImage = "docker.io/library/registry:2"
+
+	// DefaultRegistryMirrorInterface is the NIC of the registry mirror VM
+	// on the baremetal bridge.
+	DefaultRegistryMirrorInterface = "ens3"
+
+	// DefaultBootstrapContentMinSize is the default size in bytes from
+	// which the bootstrap Ignition config's file contents are served.
+	DefaultBootstrapContentMinSize = 4096
+
+	// DefaultVirtualMasterMemoryMiB is the default memory of the virtual
+	// control plane's VMs.
+	DefaultVirtualMasterMemoryMiB = 16384
+
+	// DefaultVirtualMasterCPUs is the default number of virtual CPUs of
+	// the virtual control plane's VMs.
+	DefaultVirtualMasterCPUs = 4
+
+	// DefaultVirtualMasterDiskGiB is the default disk size of the virtual
+	// control plane's VMs.
+	DefaultVirtualMasterDiskGiB = 120
 )
 
 // SetPlatformDefaults sets the defaults for the platform.
@@ -14,4 +41,29 @@ func SetPlatformDefaults(p *baremetal.Platform) {
 	if p.URI == "" {
 		p.URI = DefaultURI
 	}
+	if m := p.RegistryMirror; m != nil {
+		if m.Network.Interface == "" {
+			m.Network.Interface = DefaultRegistryMirrorInterface
+		}
+		if m.Port == 0 {
+			m.Port = DefaultRegistryMirrorPort
+		}
+		if m.Image == "" {
+			m.Image = DefaultRegistryMirrorImage
+		}
+	}
+	if c := p.BootstrapContent; c != nil && c.MinSize == 0 {
+		c.MinSize = DefaultBootstrapContentMinSize
+	}
+	if v := p.VirtualControlPlane; v != nil {
+		if v.MemoryMiB == 0 {
+			v.MemoryMiB = DefaultVirtualMasterMemoryMiB
+		}
+		if v.CPUs == 0 {
+			v.CPUs = DefaultVirtualMasterCPUs
+		}
+		if v.DiskGiB == 0 {
+			v.DiskGiB = DefaultVirtualMasterDiskGiB
+		}
+	}
 }
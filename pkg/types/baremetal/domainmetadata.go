@@ -0,0 +1,36 @@
+package baremetal
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// DomainMetadataNamespace is the XML namespace of the DomainMetadata element
+// recorded in libvirt domains created for a cluster.
+const DomainMetadataNamespace = "https://github.com/metalkube/kni-installer/domain/v1"
+
+// DomainMetadata identifies the cluster that owns a libvirt domain.  The
+// destroyer only removes domains whose metadata carries the cluster's
+// infrastructure ID, so clusters sharing a hypervisor are never touched.
+type DomainMetadata struct {
+	XMLName xml.Name    `xml:"https://github.com/metalkube/kni-installer/domain/v1 cluster"`
+	InfraID string      `xml:"infraID"`
+	Tags    []DomainTag `xml:"tag"`
+}
+
+// DomainTag is a single user tag.
+type DomainTag struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// NewDomainMetadata returns the metadata for the given infrastructure ID and
+// user tags, with the tags sorted by key.
+func NewDomainMetadata(infraID string, userTags map[string]string) *DomainMetadata {
+	m := &DomainMetadata{InfraID: infraID}
+	for key, value := range userTags {
+		m.Tags = append(m.Tags, DomainTag{Key: key, Value: value})
+	}
+	sort.Slice(m.Tags, func(i, j int) bool { return m.Tags[i].Key < m.Tags[j].Key })
+	return m
+}
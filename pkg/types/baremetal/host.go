@@ -0,0 +1,161 @@
+package baremetal
+
+// Host roles.
+const (
+	// MasterRole is the role of control plane hosts.
+	MasterRole = "master"
+	// WorkerRole is the role of compute hosts.
+	WorkerRole = "worker"
+)
+
+// Host is a physical host in the cluster's inventory.
+type Host struct {
+	// Name identifies the host, and is used as its hostname.
+	Name string `json:"name"`
+
+	// Role is the role the host is installed with, master, worker or
+	// etcd.
+	Role string `json:"role"`
+
+	// Pool is the compute pool a worker host belongs to, which decides
+	// e.g. the architecture of its boot media.
+	// +optional
+	// Default is worker.
+	Pool string `json:"pool,omitempty"`
+
+	// BootMACAddress is the MAC address of the NIC the host boots from.
+	// +optional
+	BootMACAddress string `json:"bootMACAddress,omitempty"`
+
+	// Network configures the host's network statically on first boot,
+	// for sites without DHCP.
+	// +optional
+	Network *HostNetwork `json:"network,omitempty"`
+
+	// KernelArgs are additional kernel arguments for the host's first
+	// boot, e.g. to select a console.
+	// +optional
+	KernelArgs []string `json:"kernelArgs,omitempty"`
+
+	// Hardware is the host's hardware, as declared by the user, e.g. from
+	// an earlier introspection.  The installer does not inspect hosts, so
+	// it is taken as given.  It is used to check that the control plane
+	// hosts are alike, and is reported in the hardware inventory.
+	// +optional
+	Hardware *HostHardware `json:"hardware,omitempty"`
+
+	// Unprovisioned marks a worker host which is racked, or planned, but
+	// not installed with the cluster, so a partially populated rack can be
+	// described up front and its hosts added later.  Its name and
+	// addresses stay reserved.
+	// +optional
+	Unprovisioned bool `json:"unprovisioned,omitempty"`
+}
+
+// HostHardware is the user-declared hardware of a host.  Unknown values
+// are left zero.
+type HostHardware struct {
+	// CPUs is the number of logical CPUs.
+	// +optional
+	CPUs int `json:"cpus,omitempty"`
+
+	// MemoryMiB is the physical memory in MiB.
+	// +optional
+	MemoryMiB int64 `json:"memoryMiB,omitempty"`
+
+	// RootDiskGiB is the size of the install disk in GiB.
+	// +optional
+	RootDiskGiB int64 `json:"rootDiskGiB,omitempty"`
+
+	// RootDiskRotational is true if the install disk is a spinning disk.
+	// +optional
+	RootDiskRotational bool `json:"rootDiskRotational,omitempty"`
+
+	// Firmware identifies the host's system and firmware.
+	// +optional
+	Firmware *HostFirmware `json:"firmware,omitempty"`
+}
+
+// HostFirmware is the user-declared system and firmware identification of
+// a host, as the site's support cases and lifecycle tracking refer to it.
+// Unknown values are left empty.
+type HostFirmware struct {
+	// Manufacturer is the system's manufacturer, e.g. Dell Inc.
+	// +optional
+	Manufacturer string `json:"manufacturer,omitempty"`
+
+	// ProductName is the system's model, e.g. PowerEdge R640.
+	// +optional
+	ProductName string `json:"productName,omitempty"`
+
+	// SerialNumber is the system's serial number, or service tag.
+	// +optional
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// BIOSVendor is the vendor of the system's BIOS or UEFI firmware.
+	// +optional
+	BIOSVendor string `json:"biosVendor,omitempty"`
+
+	// BIOSVersion is the version of the system's BIOS or UEFI firmware.
+	// +optional
+	BIOSVersion string `json:"biosVersion,omitempty"`
+
+	// BIOSDate is the release date of the system's BIOS or UEFI
+	// firmware, as the firmware reports it.
+	// +optional
+	BIOSDate string `json:"biosDate,omitempty"`
+
+	// BMCVersion is the firmware version of the host's BMC.
+	// +optional
+	BMCVersion string `json:"bmcVersion,omitempty"`
+}
+
+// HostPool returns the name of the machine pool of host: its role, or for
+// worker hosts, their pool.
+func HostPool(host *Host) string {
+	if host.Role == WorkerRole && host.Pool != "" {
+		return host.Pool
+	}
+	return host.Role
+}
+
+// HostsWithRole returns the hosts with the given role, in inventory order.
+func HostsWithRole(hosts []Host, role string) []Host {
+	var matching []Host
+	for _, h := range hosts {
+		if h.Role == role {
+			matching = append(matching, h)
+		}
+	}
+	return matching
+}
+
+// ProvisionedHosts returns the hosts which are installed with the
+// cluster, i.e. are not unprovisioned, in inventory order.
+func ProvisionedHosts(hosts []Host) []Host {
+	var provisioned []Host
+	for _, h := range hosts {
+		if !h.Unprovisioned {
+			provisioned = append(provisioned, h)
+		}
+	}
+	return provisioned
+}
+
+// HostNetwork is the static network configuration of a host.
+type HostNetwork struct {
+	// Interface is the name of the NIC to configure, e.g. ens3.
+	Interface string `json:"interface"`
+
+	// Address is the host's IP address and prefix length, e.g.
+	// 192.168.111.20/24.
+	Address string `json:"address"`
+
+	// Gateway is the IP address of the default gateway.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// DNS is the list of nameserver IP addresses.
+	// +optional
+	DNS []string `json:"dns,omitempty"`
+}
@@ -0,0 +1,45 @@
+package baremetal
+
+// InventoryHost is a host of the cluster's inventory, with its hardware
+// and firmware, as it was at install time.
+type InventoryHost struct {
+	// Name is the host's name.
+	Name string `json:"name"`
+
+	// Role is the role the host was installed with.
+	Role string `json:"role"`
+
+	// Pool is the machine pool of the host (see HostPool).
+	Pool string `json:"pool"`
+
+	// BootMACAddress is the MAC address of the NIC the host boots from.
+	// +optional
+	BootMACAddress string `json:"bootMACAddress,omitempty"`
+
+	// Unprovisioned is true for a host which was not installed with the
+	// cluster.
+	// +optional
+	Unprovisioned bool `json:"unprovisioned,omitempty"`
+
+	// Hardware is the host's hardware and firmware as declared in the
+	// install-config, if known.
+	// +optional
+	Hardware *HostHardware `json:"hardware,omitempty"`
+}
+
+// Inventory returns the inventory of hosts, in inventory order.
+func Inventory(hosts []Host) []InventoryHost {
+	var inventory []InventoryHost
+	for i := range hosts {
+		h := &hosts[i]
+		inventory = append(inventory, InventoryHost{
+			Name:           h.Name,
+			Role:           h.Role,
+			Pool:           HostPool(h),
+			BootMACAddress: h.BootMACAddress,
+			Unprovisioned:  h.Unprovisioned,
+			Hardware:       h.Hardware,
+		})
+	}
+	return inventory
+}
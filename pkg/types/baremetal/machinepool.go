@@ -1,8 +1,34 @@
 package baremetal
 
+// RackLabel is the node label naming the rack of a failure domain.
+const RackLabel = "kni.openshift.io/rack"
+
 // MachinePool stores the configuration for a machine pool installed
 // on bare metal.
 type MachinePool struct {
+	// FailureDomains are the racks, or other groups of hosts which can
+	// fail together, across which the pool's machines are spread
+	// round-robin.  Nodes are labelled with the rack and zone of their
+	// failure domain.
+	// +optional
+	FailureDomains []FailureDomain `json:"failureDomains,omitempty"`
+
+	// AllowSharedFailureDomains allows more than one control plane
+	// machine in a failure domain, which risks losing etcd quorum when
+	// the domain fails.
+	// +optional
+	AllowSharedFailureDomains bool `json:"allowSharedFailureDomains,omitempty"`
+}
+
+// FailureDomain is a group of hosts which can fail together.
+type FailureDomain struct {
+	// Rack is the name of the failure domain, used for the rack node
+	// label.
+	Rack string `json:"rack"`
+
+	// Zone is the zone the rack is in, used for the zone node label.
+	// +optional
+	Zone string `json:"zone,omitempty"`
 }
 
 // Set sets the values from `required` to `a`.
@@ -10,4 +36,11 @@ func (l *MachinePool) Set(required *MachinePool) {
 	if required == nil || l == nil {
 		return
 	}
+
+	if len(required.FailureDomains) > 0 {
+		l.FailureDomains = required.FailureDomains
+	}
+	if required.AllowSharedFailureDomains {
+		l.AllowSharedFailureDomains = true
+	}
 }
@@ -3,4 +3,8 @@ package baremetal
 // Metadata contains baremetal metadata (e.g. for uninstalling the cluster).
 type Metadata struct {
 	URI string `json:"uri"`
+
+	// Inventory is the cluster's hosts, with their hardware and
+	// firmware, as they were at install time.
+	Inventory []InventoryHost `json:"inventory,omitempty"`
 }
@@ -9,6 +9,47 @@ type Platform struct {
 	// Default is qemu:///system
 	URI string `json:"URI,omitempty"`
 
+	// UserTags specifies additional tags recorded on the libvirt resources
+	// created for the cluster, alongside the cluster's infrastructure ID.
+	// +optional
+	UserTags map[string]string `json:"userTags,omitempty"`
+
+	// APIVIP is the virtual IP address on the machine network which serves
+	// the Kubernetes API.
+	// +optional
+	APIVIP string `json:"apiVIP,omitempty"`
+
+	// IngressVIP is the virtual IP address on the machine network which
+	// serves the default ingress controller.
+	// +optional
+	IngressVIP string `json:"ingressVIP,omitempty"`
+
+	// DHCPRange is the range of addresses handed out by DHCP on the machine
+	// network, as the first and last address separated by a comma, e.g.
+	// 192.168.111.100,192.168.111.200.
+	// +optional
+	DHCPRange string `json:"dhcpRange,omitempty"`
+
+	// Hosts is the inventory of the hosts the cluster is installed on.
+	// +optional
+	Hosts []Host `json:"hosts,omitempty"`
+
+	// RegistryMirror, when set, creates a registry mirror VM alongside the
+	// bootstrap VM, so the release payload is only pulled over the WAN
+	// once.
+	// +optional
+	RegistryMirror *RegistryMirror `json:"registryMirror,omitempty"`
+
+	// BootstrapContent, when set, serves the contents of the bootstrap
+	// Ignition config's large files over HTTP instead of embedding them.
+	// +optional
+	BootstrapContent *BootstrapContent `json:"bootstrapContent,omitempty"`
+
+	// VirtualControlPlane, when set, runs the control plane as VMs on the
+	// provisioning host instead of on physical hosts.
+	// +optional
+	VirtualControlPlane *VirtualControlPlane `json:"virtualControlPlane,omitempty"`
+
 	// DefaultMachinePlatform is the default configuration used when
 	// installing on bare metal for machine pools which do not define their own
 	// platform configuration.
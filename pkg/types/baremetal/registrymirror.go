@@ -0,0 +1,44 @@
+package baremetal
+
+import (
+	"fmt"
+	"net"
+)
+
+// RegistryMirrorRepository is the repository on the registry mirror the
+// release payload is mirrored to.
+const RegistryMirrorRepository = "ocp/release"
+
+// RegistryMirror is a temporary registry VM, created alongside the bootstrap
+// VM and torn down with it, which mirrors the release payload once over the
+// WAN and serves it to the cluster's nodes.
+type RegistryMirror struct {
+	// Network is the static network configuration of the mirror VM on the
+	// baremetal bridge.  Its gateway and nameservers must let it reach the
+	// release registry.
+	Network HostNetwork `json:"network"`
+
+	// Sources are the repositories the mirror serves in place of their
+	// registries, e.g. the release repository and the repository of the
+	// payload's components (see oc adm release info --pullspecs).
+	Sources []string `json:"sources"`
+
+	// Port is the port the registry listens on.
+	// +optional
+	// Default is 5000.
+	Port int `json:"port,omitempty"`
+
+	// Image is the registry container image.
+	// +optional
+	// Default is docker.io/library/registry:2.
+	Image string `json:"image,omitempty"`
+}
+
+// Endpoint returns the host:port nodes pull from the mirror at.
+func (m *RegistryMirror) Endpoint() string {
+	ip, _, err := net.ParseCIDR(m.Network.Address)
+	if err != nil {
+		return ""
+	}
+	return net.JoinHostPort(ip.String(), fmt.Sprint(m.Port))
+}
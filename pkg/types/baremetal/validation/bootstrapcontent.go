@@ -0,0 +1,27 @@
+package validation
+
+import (
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/validate"
+)
+
+// ValidateBootstrapContent checks that the bootstrap content is served
+// from an HTTP URL Ignition can fetch.
+func ValidateBootstrapContent(c *baremetal.BootstrapContent, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if err := validate.URI(c.URL); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), c.URL, err.Error()))
+	} else if u, _ := url.Parse(c.URL); u.Scheme != "http" && u.Scheme != "https" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), c.URL, "must be an http or https URL"))
+	} else if u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), c.URL, "must have a host"))
+	}
+	if c.MinSize < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minSize"), c.MinSize, "must be positive"))
+	}
+	return allErrs
+}
@@ -0,0 +1,53 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestValidateBootstrapContent(t *testing.T) {
+	cases := []struct {
+		name          string
+		content       baremetal.BootstrapContent
+		expectedError string
+	}{
+		{
+			name:    "valid",
+			content: baremetal.BootstrapContent{URL: "http://192.168.111.1:8080/bootstrap-content", MinSize: 4096},
+		},
+		{
+			name:          "no URL",
+			content:       baremetal.BootstrapContent{MinSize: 4096},
+			expectedError: `^test-path\.url: Invalid value: "": invalid URI "" \(no scheme\)$`,
+		},
+		{
+			name:          "unsupported scheme",
+			content:       baremetal.BootstrapContent{URL: "file:///srv/bootstrap-content", MinSize: 4096},
+			expectedError: `^test-path\.url: Invalid value: "file:///srv/bootstrap-content": must be an http or https URL$`,
+		},
+		{
+			name:          "no host",
+			content:       baremetal.BootstrapContent{URL: "http:///bootstrap-content", MinSize: 4096},
+			expectedError: `^test-path\.url: Invalid value: "http:///bootstrap-content": must have a host$`,
+		},
+		{
+			name:          "invalid minimum size",
+			content:       baremetal.BootstrapContent{URL: "https://example.com", MinSize: -1},
+			expectedError: `^test-path\.minSize: Invalid value: -1: must be positive$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBootstrapContent(&tc.content, field.NewPath("test-path")).ToAggregate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+//go:build gofuzz
+// +build gofuzz
+
+package validation
+
+import (
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/ipnet"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// fuzzMachineCIDR is the machine CIDR the fuzzed addresses are checked
+// against.
+var fuzzMachineCIDR = ipnet.MustParseCIDR("192.168.111.0/24")
+
+// Fuzz is a fuzz testing function designed to be used with go-fuzz:
+//
+//	https://github.com/dvyukov/go-fuzz
+//
+// It parses the data as the baremetal section of an install config and
+// validates it, along with the addresses of its VIPs, DHCP range, hosts
+// and registry mirror.  It's not included in a normal build due to the
+// gofuzz build tag above.
+func Fuzz(data []byte) int {
+	p := &baremetal.Platform{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return 0
+	}
+	fldPath := field.NewPath("platform", "baremetal")
+	allErrs := ValidatePlatform(p, fldPath)
+	allErrs = append(allErrs, ValidateNetwork(p, &fuzzMachineCIDR.IPNet, nil, fldPath)...)
+	if len(allErrs) > 0 {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// HardwareTolerance is how much smaller than the largest control plane host
+// another may be, as a fraction, before the difference is reported.
+const HardwareTolerance = 0.1
+
+// HardwareAsymmetry returns a message for each way in which the declared
+// hardware of the control plane hosts differs by more than the tolerance.
+// etcd commits at the pace of its slower members, so mixed hardware on the
+// control plane shows up as etcd latency.  Hosts without hardware are
+// skipped.
+func HardwareAsymmetry(hosts []baremetal.Host) []string {
+	var masters []baremetal.Host
+	for _, h := range baremetal.HostsWithRole(hosts, baremetal.MasterRole) {
+		if h.Hardware != nil {
+			masters = append(masters, h)
+		}
+	}
+	if len(masters) < 2 {
+		return nil
+	}
+
+	var messages []string
+	for _, spec := range []struct {
+		name  string
+		unit  string
+		value func(*baremetal.HostHardware) int64
+	}{
+		{name: "CPUs", unit: "CPUs", value: func(h *baremetal.HostHardware) int64 { return int64(h.CPUs) }},
+		{name: "memory", unit: "MiB", value: func(h *baremetal.HostHardware) int64 { return h.MemoryMiB }},
+		{name: "install disk size", unit: "GiB", value: func(h *baremetal.HostHardware) int64 { return h.RootDiskGiB }},
+	} {
+		var largest baremetal.Host
+		var smaller []string
+		for _, h := range masters {
+			if spec.value(h.Hardware) > 0 && (largest.Hardware == nil || spec.value(h.Hardware) > spec.value(largest.Hardware)) {
+				largest = h
+			}
+		}
+		if largest.Hardware == nil {
+			continue
+		}
+		limit := float64(spec.value(largest.Hardware)) * (1 - HardwareTolerance)
+		for _, h := range masters {
+			if v := spec.value(h.Hardware); v > 0 && float64(v) < limit {
+				smaller = append(smaller, fmt.Sprintf("%s has %d", h.Name, v))
+			}
+		}
+		if len(smaller) > 0 {
+			messages = append(messages, fmt.Sprintf("The control plane hosts differ in %s: %s has %d %s, but %s.", spec.name, largest.Name, spec.value(largest.Hardware), spec.unit, strings.Join(smaller, ", ")))
+		}
+	}
+
+	var rotational []string
+	for _, h := range masters {
+		if h.Hardware.RootDiskRotational {
+			rotational = append(rotational, h.Name)
+		}
+	}
+	if len(rotational) > 0 && len(rotational) < len(masters) {
+		sort.Strings(rotational)
+		messages = append(messages, fmt.Sprintf("The control plane hosts differ in install disk type: spinning disks on %s, solid state disks on the others.", strings.Join(rotational, ", ")))
+	}
+	return messages
+}
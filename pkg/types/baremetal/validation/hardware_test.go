@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestHardwareAsymmetry(t *testing.T) {
+	master := func(name string, cpus int, memoryMiB int64, rotational bool) baremetal.Host {
+		return baremetal.Host{
+			Name: name,
+			Role: baremetal.MasterRole,
+			Hardware: &baremetal.HostHardware{
+				CPUs:               cpus,
+				MemoryMiB:          memoryMiB,
+				RootDiskGiB:        480,
+				RootDiskRotational: rotational,
+			},
+		}
+	}
+
+	cases := []struct {
+		name     string
+		hosts    []baremetal.Host
+		expected []string
+	}{
+		{
+			name: "alike",
+			hosts: []baremetal.Host{
+				master("master-0", 32, 65536, false),
+				master("master-1", 32, 63488, false),
+				master("master-2", 32, 65536, false),
+			},
+		},
+		{
+			name: "workers are ignored",
+			hosts: []baremetal.Host{
+				master("master-0", 32, 65536, false),
+				{Name: "worker-0", Role: baremetal.WorkerRole, Hardware: &baremetal.HostHardware{CPUs: 4}},
+				{Name: "master-1", Role: baremetal.MasterRole},
+			},
+		},
+		{
+			name: "mixed",
+			hosts: []baremetal.Host{
+				master("master-0", 32, 65536, false),
+				master("master-1", 16, 32768, true),
+				master("master-2", 32, 49152, false),
+			},
+			expected: []string{
+				"The control plane hosts differ in CPUs: master-0 has 32 CPUs, but master-1 has 16.",
+				"The control plane hosts differ in memory: master-0 has 65536 MiB, but master-1 has 32768, master-2 has 49152.",
+				"The control plane hosts differ in install disk type: spinning disks on master-1, solid state disks on the others.",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, HardwareAsymmetry(tc.hosts))
+		})
+	}
+}
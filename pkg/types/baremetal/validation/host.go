@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+var validRoles = []string{baremetal.MasterRole, baremetal.WorkerRole}
+
+// ValidateHosts checks that the host inventory is valid.
+func ValidateHosts(hosts []baremetal.Host, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := map[string]bool{}
+	macs := map[string]bool{}
+	for i, host := range hosts {
+		hostPath := fldPath.Index(i)
+		for _, msg := range k8svalidation.IsDNS1123Label(host.Name) {
+			allErrs = append(allErrs, field.Invalid(hostPath.Child("name"), host.Name, msg))
+		}
+		if names[host.Name] {
+			allErrs = append(allErrs, field.Duplicate(hostPath.Child("name"), host.Name))
+		}
+		names[host.Name] = true
+
+		if !isValidRole(host.Role) {
+			allErrs = append(allErrs, field.NotSupported(hostPath.Child("role"), host.Role, validRoles))
+		} else if host.Unprovisioned && host.Role != baremetal.WorkerRole {
+			allErrs = append(allErrs, field.Forbidden(hostPath.Child("unprovisioned"), fmt.Sprintf("only %s hosts can be added after the install", baremetal.WorkerRole)))
+		}
+
+		if host.BootMACAddress != "" {
+			mac, err := net.ParseMAC(host.BootMACAddress)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(hostPath.Child("bootMACAddress"), host.BootMACAddress, err.Error()))
+			} else if macs[mac.String()] {
+				allErrs = append(allErrs, field.Duplicate(hostPath.Child("bootMACAddress"), host.BootMACAddress))
+			} else {
+				macs[mac.String()] = true
+			}
+		}
+
+		if host.Network != nil {
+			allErrs = append(allErrs, validateHostNetwork(host.Network, hostPath.Child("network"))...)
+		}
+
+		if hw := host.Hardware; hw != nil {
+			hardwarePath := hostPath.Child("hardware")
+			if hw.CPUs < 0 {
+				allErrs = append(allErrs, field.Invalid(hardwarePath.Child("cpus"), hw.CPUs, "must not be negative"))
+			}
+			if hw.MemoryMiB < 0 {
+				allErrs = append(allErrs, field.Invalid(hardwarePath.Child("memoryMiB"), hw.MemoryMiB, "must not be negative"))
+			}
+			if hw.RootDiskGiB < 0 {
+				allErrs = append(allErrs, field.Invalid(hardwarePath.Child("rootDiskGiB"), hw.RootDiskGiB, "must not be negative"))
+			}
+		}
+	}
+	return allErrs
+}
+
+func isValidRole(role string) bool {
+	for _, r := range validRoles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+func validateHostNetwork(n *baremetal.HostNetwork, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if n.Interface == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("interface"), "interface is required"))
+	}
+	if _, _, err := net.ParseCIDR(n.Address); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("address"), n.Address, "must be an IP address with a prefix length, e.g. 192.168.111.20/24"))
+	}
+	if n.Gateway != "" && net.ParseIP(n.Gateway) == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("gateway"), n.Gateway, "must be an IP address"))
+	}
+	for i, dns := range n.DNS {
+		if net.ParseIP(dns) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dns").Index(i), dns, "must be an IP address"))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func validHost() baremetal.Host {
+	return baremetal.Host{
+		Name:           "master-0",
+		Role:           baremetal.MasterRole,
+		BootMACAddress: "52:54:00:aa:bb:01",
+		Network: &baremetal.HostNetwork{
+			Interface: "ens3",
+			Address:   "192.168.111.20/24",
+			Gateway:   "192.168.111.1",
+			DNS:       []string{"192.168.111.1"},
+		},
+	}
+}
+
+func TestValidateHosts(t *testing.T) {
+	cases := []struct {
+		name          string
+		hosts         func() []baremetal.Host
+		expectedError string
+	}{
+		{
+			name:  "valid",
+			hosts: func() []baremetal.Host { return []baremetal.Host{validHost()} },
+		},
+		{
+			name: "invalid name and role",
+			hosts: func() []baremetal.Host {
+				h := validHost()
+				h.Name = "Master_0"
+				h.Role = "infra"
+				return []baremetal.Host{h}
+			},
+			expectedError: `^\[test-path\[0\]\.name: Invalid value: "Master_0": .*, test-path\[0\]\.role: Unsupported value: "infra": supported values: "master", "worker"\]$`,
+		},
+		{
+			name: "unprovisioned worker",
+			hosts: func() []baremetal.Host {
+				h := validHost()
+				h.Name = "worker-0"
+				h.Role = baremetal.WorkerRole
+				h.Unprovisioned = true
+				return []baremetal.Host{h}
+			},
+		},
+		{
+			name: "unprovisioned master",
+			hosts: func() []baremetal.Host {
+				h := validHost()
+				h.Unprovisioned = true
+				return []baremetal.Host{h}
+			},
+			expectedError: `^test-path\[0\]\.unprovisioned: Forbidden: only worker hosts can be added after the install$`,
+		},
+		{
+			name: "duplicate name and MAC",
+			hosts: func() []baremetal.Host {
+				h := validHost()
+				h.Network = nil
+				dup := h
+				dup.BootMACAddress = "52-54-00-AA-BB-01"
+				return []baremetal.Host{h, dup}
+			},
+			expectedError: `^\[test-path\[1\]\.name: Duplicate value: "master-0", test-path\[1\]\.bootMACAddress: Duplicate value: "52-54-00-AA-BB-01"\]$`,
+		},
+		{
+			name: "invalid network",
+			hosts: func() []baremetal.Host {
+				h := validHost()
+				h.Network = &baremetal.HostNetwork{Address: "192.168.111.20", Gateway: "gw", DNS: []string{"dns"}}
+				return []baremetal.Host{h}
+			},
+			expectedError: `^\[test-path\[0\]\.network\.interface: Required value: interface is required, test-path\[0\]\.network\.address: Invalid value: "192\.168\.111\.20": .*, test-path\[0\]\.network\.gateway: Invalid value: "gw": must be an IP address, test-path\[0\]\.network\.dns\[0\]: Invalid value: "dns": must be an IP address\]$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateHosts(tc.hosts(), field.NewPath("test-path")).ToAggregate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
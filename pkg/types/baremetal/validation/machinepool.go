@@ -1,6 +1,7 @@
 package validation
 
 import (
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
@@ -8,5 +9,37 @@ import (
 
 // ValidateMachinePool checks that the specified machine pool is valid.
 func ValidateMachinePool(p *baremetal.MachinePool, fldPath *field.Path) field.ErrorList {
-	return field.ErrorList{}
+	allErrs := field.ErrorList{}
+	racks := map[string]bool{}
+	for i, domain := range p.FailureDomains {
+		domainPath := fldPath.Child("failureDomains").Index(i)
+		if domain.Rack == "" {
+			allErrs = append(allErrs, field.Required(domainPath.Child("rack"), "rack is required"))
+		}
+		for _, msg := range k8svalidation.IsValidLabelValue(domain.Rack) {
+			allErrs = append(allErrs, field.Invalid(domainPath.Child("rack"), domain.Rack, msg))
+		}
+		for _, msg := range k8svalidation.IsValidLabelValue(domain.Zone) {
+			allErrs = append(allErrs, field.Invalid(domainPath.Child("zone"), domain.Zone, msg))
+		}
+		if racks[domain.Rack] {
+			allErrs = append(allErrs, field.Duplicate(domainPath.Child("rack"), domain.Rack))
+		}
+		racks[domain.Rack] = true
+	}
+	return allErrs
+}
+
+// ValidateControlPlanePlacement checks that no two of the control plane's
+// replicas share a failure domain, so losing one cannot cost etcd its
+// quorum, unless the pool allows it.
+func ValidateControlPlanePlacement(p *baremetal.MachinePool, replicas int64, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(p.FailureDomains) == 0 || p.AllowSharedFailureDomains {
+		return allErrs
+	}
+	if replicas > int64(len(p.FailureDomains)) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("failureDomains"), len(p.FailureDomains), "there must be a failure domain for each control plane replica unless allowSharedFailureDomains is set"))
+	}
+	return allErrs
 }
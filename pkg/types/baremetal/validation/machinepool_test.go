@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestValidateMachinePool(t *testing.T) {
+	cases := []struct {
+		name  string
+		pool  *baremetal.MachinePool
+		valid bool
+	}{
+		{
+			name:  "empty",
+			pool:  &baremetal.MachinePool{},
+			valid: true,
+		},
+		{
+			name: "valid failure domains",
+			pool: &baremetal.MachinePool{
+				FailureDomains: []baremetal.FailureDomain{
+					{Rack: "r1", Zone: "east"},
+					{Rack: "r2"},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "missing rack",
+			pool: &baremetal.MachinePool{
+				FailureDomains: []baremetal.FailureDomain{{Zone: "east"}},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid zone",
+			pool: &baremetal.MachinePool{
+				FailureDomains: []baremetal.FailureDomain{{Rack: "r1", Zone: "east zone"}},
+			},
+			valid: false,
+		},
+		{
+			name: "duplicate rack",
+			pool: &baremetal.MachinePool{
+				FailureDomains: []baremetal.FailureDomain{{Rack: "r1"}, {Rack: "r1"}},
+			},
+			valid: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateMachinePool(tc.pool, field.NewPath("test-path")).ToAggregate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateControlPlanePlacement(t *testing.T) {
+	racks := []baremetal.FailureDomain{{Rack: "r1"}, {Rack: "r2"}, {Rack: "r3"}}
+	cases := []struct {
+		name     string
+		pool     *baremetal.MachinePool
+		replicas int64
+		valid    bool
+	}{
+		{
+			name:     "no failure domains",
+			pool:     &baremetal.MachinePool{},
+			replicas: 3,
+			valid:    true,
+		},
+		{
+			name:     "one replica per rack",
+			pool:     &baremetal.MachinePool{FailureDomains: racks},
+			replicas: 3,
+			valid:    true,
+		},
+		{
+			name:     "shared rack",
+			pool:     &baremetal.MachinePool{FailureDomains: racks[:2]},
+			replicas: 3,
+			valid:    false,
+		},
+		{
+			name:     "shared rack allowed",
+			pool:     &baremetal.MachinePool{FailureDomains: racks[:2], AllowSharedFailureDomains: true},
+			replicas: 3,
+			valid:    true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateControlPlanePlacement(tc.pool, tc.replicas, field.NewPath("test-path")).ToAggregate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package validation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// ValidateNetwork checks that the VIPs, the DHCP range and the registry
+// mirror are valid addresses on the machine network, and that no two users of the network
+// were given the same address.  machineCIDR may be nil, in which case the
+// addresses are not checked against it.
+//
+// poolCIDRs are the machine CIDRs of the machine pools on routed subnets
+// of their own, by pool name.  The API VIP must then be on the control
+// plane's subnet and the ingress VIP on the worker pool's, as VRRP only
+// fails over within a subnet, and the static addresses of hosts must be on
+// the subnet of their pool.
+func ValidateNetwork(p *baremetal.Platform, machineCIDR *net.IPNet, poolCIDRs map[string]*net.IPNet, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	cidrFor := func(pool string) *net.IPNet {
+		if cidr, ok := poolCIDRs[pool]; ok {
+			return cidr
+		}
+		return machineCIDR
+	}
+
+	var dhcpStart, dhcpEnd net.IP
+	if p.DHCPRange != "" {
+		var err error
+		dhcpStart, dhcpEnd, err = ParseDHCPRange(p.DHCPRange)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dhcpRange"), p.DHCPRange, err.Error()))
+		} else if machineCIDR != nil && !withinAny(dhcpStart, dhcpEnd, machineCIDR, poolCIDRs) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("dhcpRange"), p.DHCPRange, fmt.Sprintf("must be within the machine CIDR %s", machineCIDR)))
+		}
+	}
+
+	for i, h := range p.Hosts {
+		pool := baremetal.HostPool(&h)
+		cidr, ok := poolCIDRs[pool]
+		if !ok || h.Network == nil {
+			continue
+		}
+		if ip, _, err := net.ParseCIDR(h.Network.Address); err == nil && !cidr.Contains(ip) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hosts").Index(i).Child("network", "address"), h.Network.Address, fmt.Sprintf("must be within the %s machine CIDR %s", pool, cidr)))
+		}
+	}
+
+	// users maps the addresses seen so far to their field, so collisions
+	// can name both sides.
+	users := map[string]string{}
+	for _, h := range p.Hosts {
+		if h.Network == nil {
+			continue
+		}
+		if ip, _, err := net.ParseCIDR(h.Network.Address); err == nil {
+			users[ip.String()] = fmt.Sprintf("host %s", h.Name)
+		}
+	}
+
+	for _, vip := range []struct {
+		field string
+		value string
+		cidr  *net.IPNet
+	}{
+		{field: "apiVIP", value: p.APIVIP, cidr: cidrFor(baremetal.MasterRole)},
+		{field: "ingressVIP", value: p.IngressVIP, cidr: cidrFor(baremetal.WorkerRole)},
+	} {
+		if vip.value == "" {
+			continue
+		}
+		vipPath := fldPath.Child(vip.field)
+		ip := net.ParseIP(vip.value)
+		if ip == nil {
+			allErrs = append(allErrs, field.Invalid(vipPath, vip.value, "must be an IP address"))
+			continue
+		}
+		if vip.cidr != nil && !vip.cidr.Contains(ip) {
+			allErrs = append(allErrs, field.Invalid(vipPath, vip.value, fmt.Sprintf("must be within the machine CIDR %s", vip.cidr)))
+		}
+		if dhcpStart != nil && inRange(ip, dhcpStart, dhcpEnd) {
+			allErrs = append(allErrs, field.Invalid(vipPath, vip.value, "must not be within the DHCP range"))
+		}
+		if user, ok := users[ip.String()]; ok {
+			allErrs = append(allErrs, field.Invalid(vipPath, vip.value, fmt.Sprintf("already used by %s", user)))
+			continue
+		}
+		users[ip.String()] = vip.field
+	}
+
+	if m := p.RegistryMirror; m != nil {
+		mirrorPath := fldPath.Child("registryMirror", "network", "address")
+		if ip, _, err := net.ParseCIDR(m.Network.Address); err == nil {
+			if machineCIDR != nil && !machineCIDR.Contains(ip) {
+				allErrs = append(allErrs, field.Invalid(mirrorPath, m.Network.Address, fmt.Sprintf("must be within the machine CIDR %s", machineCIDR)))
+			}
+			if dhcpStart != nil && inRange(ip, dhcpStart, dhcpEnd) {
+				allErrs = append(allErrs, field.Invalid(mirrorPath, m.Network.Address, "must not be within the DHCP range"))
+			}
+			if user, ok := users[ip.String()]; ok {
+				allErrs = append(allErrs, field.Invalid(mirrorPath, m.Network.Address, fmt.Sprintf("already used by %s", user)))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ParseDHCPRange parses a DHCP range of the form first,last.
+func ParseDHCPRange(dhcpRange string) (start, end net.IP, err error) {
+	parts := strings.Split(dhcpRange, ",")
+	if len(parts) != 2 {
+		return nil, nil, errors.New("must be the first and last address separated by a comma")
+	}
+	start = net.ParseIP(strings.TrimSpace(parts[0]))
+	end = net.ParseIP(strings.TrimSpace(parts[1]))
+	if start == nil || end == nil {
+		return nil, nil, errors.New("must be the first and last address separated by a comma")
+	}
+	if bytes.Compare(start.To16(), end.To16()) > 0 {
+		return nil, nil, errors.New("first address must not be after the last")
+	}
+	return start, end, nil
+}
+
+// withinAny returns true if the range from start to end is within the
+// machine CIDR or the machine CIDR of any pool, as the provisioning network
+// may be any of the routed subnets.
+func withinAny(start, end net.IP, machineCIDR *net.IPNet, poolCIDRs map[string]*net.IPNet) bool {
+	if machineCIDR.Contains(start) && machineCIDR.Contains(end) {
+		return true
+	}
+	for _, cidr := range poolCIDRs {
+		if cidr.Contains(start) && cidr.Contains(end) {
+			return true
+		}
+	}
+	return false
+}
+
+func inRange(ip, start, end net.IP) bool {
+	ip = ip.To16()
+	return bytes.Compare(ip, start.To16()) >= 0 && bytes.Compare(ip, end.To16()) <= 0
+}
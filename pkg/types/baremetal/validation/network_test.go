@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func validNetworkPlatform() *baremetal.Platform {
+	return &baremetal.Platform{
+		APIVIP:     "192.168.111.5",
+		IngressVIP: "192.168.111.4",
+		DHCPRange:  "192.168.111.100,192.168.111.200",
+		Hosts:      []baremetal.Host{validHost()},
+	}
+}
+
+func TestValidateNetwork(t *testing.T) {
+	_, machineCIDR, _ := net.ParseCIDR("192.168.111.0/24")
+	_, leafCIDR, _ := net.ParseCIDR("192.168.112.0/24")
+	cases := []struct {
+		name          string
+		platform      func() *baremetal.Platform
+		poolCIDRs     map[string]*net.IPNet
+		expectedError string
+	}{
+		{
+			name:     "valid",
+			platform: validNetworkPlatform,
+		},
+		{
+			name: "invalid VIP and DHCP range",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.APIVIP = "api"
+				p.DHCPRange = "192.168.111.200,192.168.111.100"
+				return p
+			},
+			expectedError: `^\[test-path\.dhcpRange: Invalid value: "192\.168\.111\.200,192\.168\.111\.100": first address must not be after the last, test-path\.apiVIP: Invalid value: "api": must be an IP address\]$`,
+		},
+		{
+			name: "outside machine CIDR",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.IngressVIP = "10.0.0.4"
+				p.DHCPRange = "192.168.111.100,192.168.112.200"
+				return p
+			},
+			expectedError: `^\[test-path\.dhcpRange: Invalid value: "192\.168\.111\.100,192\.168\.112\.200": must be within the machine CIDR 192\.168\.111\.0/24, test-path\.ingressVIP: Invalid value: "10\.0\.0\.4": must be within the machine CIDR 192\.168\.111\.0/24\]$`,
+		},
+		{
+			name: "collisions",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.APIVIP = "192.168.111.20"
+				p.IngressVIP = "192.168.111.150"
+				return p
+			},
+			expectedError: `^\[test-path\.apiVIP: Invalid value: "192\.168\.111\.20": already used by host master-0, test-path\.ingressVIP: Invalid value: "192\.168\.111\.150": must not be within the DHCP range\]$`,
+		},
+		{
+			name: "routed worker subnet",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.IngressVIP = "192.168.112.4"
+				p.DHCPRange = "192.168.112.100,192.168.112.200"
+				return p
+			},
+			poolCIDRs: map[string]*net.IPNet{baremetal.WorkerRole: leafCIDR},
+		},
+		{
+			name:          "outside routed control plane subnet",
+			platform:      validNetworkPlatform,
+			poolCIDRs:     map[string]*net.IPNet{baremetal.MasterRole: leafCIDR},
+			expectedError: `^\[test-path\.hosts\[0\]\.network\.address: Invalid value: "192\.168\.111\.20/24": must be within the master machine CIDR 192\.168\.112\.0/24, test-path\.apiVIP: Invalid value: "192\.168\.111\.5": must be within the machine CIDR 192\.168\.112\.0/24\]$`,
+		},
+		{
+			name: "outside routed compute pool subnet",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				worker := validHost()
+				worker.Name = "gpu-0"
+				worker.Role = baremetal.WorkerRole
+				worker.Pool = "gpu"
+				worker.Network.Address = "192.168.111.30/24"
+				p.Hosts = append(p.Hosts, worker)
+				return p
+			},
+			poolCIDRs:     map[string]*net.IPNet{"gpu": leafCIDR},
+			expectedError: `^test-path\.hosts\[1\]\.network\.address: Invalid value: "192\.168\.111\.30/24": must be within the gpu machine CIDR 192\.168\.112\.0/24$`,
+		},
+		{
+			name: "same VIPs",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.IngressVIP = p.APIVIP
+				return p
+			},
+			expectedError: `^test-path\.ingressVIP: Invalid value: "192\.168\.111\.5": already used by apiVIP$`,
+		},
+		{
+			name: "registry mirror",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.RegistryMirror = validRegistryMirror()
+				return p
+			},
+		},
+		{
+			name: "registry mirror collisions",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.RegistryMirror = validRegistryMirror()
+				p.RegistryMirror.Network.Address = "192.168.111.5/24"
+				return p
+			},
+			expectedError: `^test-path\.registryMirror\.network\.address: Invalid value: "192\.168\.111\.5/24": already used by apiVIP$`,
+		},
+		{
+			name: "registry mirror outside machine CIDR",
+			platform: func() *baremetal.Platform {
+				p := validNetworkPlatform()
+				p.RegistryMirror = validRegistryMirror()
+				p.RegistryMirror.Network.Address = "10.0.0.3/24"
+				return p
+			},
+			expectedError: `^test-path\.registryMirror\.network\.address: Invalid value: "10\.0\.0\.3/24": must be within the machine CIDR 192\.168\.111\.0/24$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateNetwork(tc.platform(), machineCIDR, tc.poolCIDRs, field.NewPath("test-path")).ToAggregate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package validation
 
 import (
+	"sort"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
@@ -13,6 +16,26 @@ func ValidatePlatform(p *baremetal.Platform, fldPath *field.Path) field.ErrorLis
 	if err := validate.URI(p.URI); err != nil {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("uri"), p.URI, err.Error()))
 	}
+	keys := make([]string, 0, len(p.UserTags))
+	for key := range p.UserTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, msg := range k8svalidation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("userTags"), key, msg))
+		}
+	}
+	allErrs = append(allErrs, ValidateHosts(p.Hosts, fldPath.Child("hosts"))...)
+	if p.RegistryMirror != nil {
+		allErrs = append(allErrs, ValidateRegistryMirror(p.RegistryMirror, fldPath.Child("registryMirror"))...)
+	}
+	if p.BootstrapContent != nil {
+		allErrs = append(allErrs, ValidateBootstrapContent(p.BootstrapContent, fldPath.Child("bootstrapContent"))...)
+	}
+	if p.VirtualControlPlane != nil {
+		allErrs = append(allErrs, ValidateVirtualControlPlane(p.VirtualControlPlane, p.Hosts, fldPath.Child("virtualControlPlane"), fldPath.Child("hosts"))...)
+	}
 	if p.DefaultMachinePlatform != nil {
 		allErrs = append(allErrs, ValidateMachinePool(p.DefaultMachinePlatform, fldPath.Child("defaultMachinePlatform"))...)
 	}
@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func validPlatform() *baremetal.Platform {
+	return &baremetal.Platform{
+		URI: "qemu+tcp://192.168.122.1/system",
+	}
+}
+
+func TestValidatePlatform(t *testing.T) {
+	cases := []struct {
+		name     string
+		platform *baremetal.Platform
+		valid    bool
+	}{
+		{
+			name:     "minimal",
+			platform: validPlatform(),
+			valid:    true,
+		},
+		{
+			name: "invalid uri",
+			platform: func() *baremetal.Platform {
+				p := validPlatform()
+				p.URI = "bad-uri"
+				return p
+			}(),
+			valid: false,
+		},
+		{
+			name: "valid user tags",
+			platform: func() *baremetal.Platform {
+				p := validPlatform()
+				p.UserTags = map[string]string{"example.com/owner": "team a"}
+				return p
+			}(),
+			valid: true,
+		},
+		{
+			name: "invalid user tag key",
+			platform: func() *baremetal.Platform {
+				p := validPlatform()
+				p.UserTags = map[string]string{"bad key": "value"}
+				return p
+			}(),
+			valid: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePlatform(tc.platform, field.NewPath("test-path")).ToAggregate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
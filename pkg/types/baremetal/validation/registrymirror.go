@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// ValidateRegistryMirror checks that the registry mirror VM has a complete
+// static network configuration and that its sources are repositories.
+func ValidateRegistryMirror(m *baremetal.RegistryMirror, fldPath *field.Path) field.ErrorList {
+	allErrs := validateHostNetwork(&m.Network, fldPath.Child("network"))
+	if m.Network.Gateway == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("network", "gateway"), "the registry mirror must be able to reach the release registry"))
+	}
+	if len(m.Network.DNS) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("network", "dns"), "the registry mirror must be able to resolve the release registry"))
+	}
+	if len(m.Sources) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("sources"), "at least the release repository is required"))
+	}
+	seen := map[string]bool{}
+	for i, source := range m.Sources {
+		if err := validateRepository(source); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sources").Index(i), source, err.Error()))
+		} else if seen[source] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("sources").Index(i), source))
+		}
+		seen[source] = true
+	}
+	if m.Port < 1 || m.Port > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("port"), m.Port, "must be between 1 and 65535"))
+	}
+	if m.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), "image is required"))
+	}
+	return allErrs
+}
+
+// validateRepository checks that repository is of the form
+// registry/path, with no tag or digest.
+func validateRepository(repository string) error {
+	i := strings.Index(repository, "/")
+	if i <= 0 || i == len(repository)-1 {
+		return errors.New("must be of the form registry/path, e.g. quay.io/openshift-release-dev/ocp-release")
+	}
+	if strings.ContainsAny(repository[i:], ":@") {
+		return errors.New("must not have a tag or digest")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func validRegistryMirror() *baremetal.RegistryMirror {
+	return &baremetal.RegistryMirror{
+		Network: baremetal.HostNetwork{
+			Interface: "ens3",
+			Address:   "192.168.111.3/24",
+			Gateway:   "192.168.111.1",
+			DNS:       []string{"192.168.111.1"},
+		},
+		Sources: []string{
+			"quay.io/openshift-release-dev/ocp-release",
+			"quay.io/openshift-release-dev/ocp-v4.0-art-dev",
+		},
+		Port:  5000,
+		Image: "docker.io/library/registry:2",
+	}
+}
+
+func TestValidateRegistryMirror(t *testing.T) {
+	cases := []struct {
+		name          string
+		mirror        func() *baremetal.RegistryMirror
+		expectedError string
+	}{
+		{
+			name:   "valid",
+			mirror: validRegistryMirror,
+		},
+		{
+			name: "no gateway or nameservers",
+			mirror: func() *baremetal.RegistryMirror {
+				m := validRegistryMirror()
+				m.Network.Gateway = ""
+				m.Network.DNS = nil
+				return m
+			},
+			expectedError: `^\[test-path\.network\.gateway: Required value: the registry mirror must be able to reach the release registry, test-path\.network\.dns: Required value: the registry mirror must be able to resolve the release registry\]$`,
+		},
+		{
+			name: "no sources",
+			mirror: func() *baremetal.RegistryMirror {
+				m := validRegistryMirror()
+				m.Sources = nil
+				return m
+			},
+			expectedError: `^test-path\.sources: Required value: at least the release repository is required$`,
+		},
+		{
+			name: "invalid sources",
+			mirror: func() *baremetal.RegistryMirror {
+				m := validRegistryMirror()
+				m.Sources = []string{"quay.io", "quay.io/openshift-release-dev/ocp-release:4.1.0", "quay.io/a/b", "quay.io/a/b"}
+				return m
+			},
+			expectedError: `^\[test-path\.sources\[0\]: Invalid value: "quay\.io": must be of the form registry/path, e\.g\. quay\.io/openshift-release-dev/ocp-release, test-path\.sources\[1\]: Invalid value: "quay\.io/openshift-release-dev/ocp-release:4\.1\.0": must not have a tag or digest, test-path\.sources\[3\]: Duplicate value: "quay\.io/a/b"\]$`,
+		},
+		{
+			name: "invalid port",
+			mirror: func() *baremetal.RegistryMirror {
+				m := validRegistryMirror()
+				m.Port = 70000
+				return m
+			},
+			expectedError: `^test-path\.port: Invalid value: 70000: must be between 1 and 65535$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRegistryMirror(tc.mirror(), field.NewPath("test-path")).ToAggregate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
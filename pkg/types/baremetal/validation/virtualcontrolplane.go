@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// ValidateVirtualControlPlane checks that the control plane VMs have
+// resources, and that the master hosts of the inventory, which name the
+// VMs, set nothing only a physical host has.  The VMs are booted by
+// Terraform with the master Ignition config, so they take no first-boot
+// network configuration or kernel arguments.
+func ValidateVirtualControlPlane(v *baremetal.VirtualControlPlane, hosts []baremetal.Host, fldPath, hostsPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if v.MemoryMiB < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("memoryMiB"), v.MemoryMiB, "must be positive"))
+	}
+	if v.CPUs < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cpus"), v.CPUs, "must be positive"))
+	}
+	if v.DiskGiB < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("diskGiB"), v.DiskGiB, "must be positive"))
+	}
+	for i, h := range hosts {
+		if h.Role != baremetal.MasterRole {
+			continue
+		}
+		hostPath := hostsPath.Index(i)
+		if h.Network != nil {
+			allErrs = append(allErrs, field.Forbidden(hostPath.Child("network"), "virtual control plane machines are configured by DHCP"))
+		}
+		if len(h.KernelArgs) > 0 {
+			allErrs = append(allErrs, field.Forbidden(hostPath.Child("kernelArgs"), "virtual control plane machines boot without first-boot kernel arguments"))
+		}
+		if h.Hardware != nil {
+			allErrs = append(allErrs, field.Forbidden(hostPath.Child("hardware"), "virtual control plane machines are sized by virtualControlPlane"))
+		}
+	}
+	return allErrs
+}
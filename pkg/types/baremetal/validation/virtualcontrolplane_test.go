@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestValidateVirtualControlPlane(t *testing.T) {
+	valid := baremetal.VirtualControlPlane{MemoryMiB: 16384, CPUs: 4, DiskGiB: 120}
+	cases := []struct {
+		name          string
+		controlPlane  baremetal.VirtualControlPlane
+		hosts         []baremetal.Host
+		expectedError string
+	}{
+		{
+			name:         "valid",
+			controlPlane: valid,
+			hosts: []baremetal.Host{
+				{Name: "master-0", Role: baremetal.MasterRole, BootMACAddress: "52:54:00:00:00:01"},
+				{Name: "worker-0", Role: baremetal.WorkerRole, Network: &baremetal.HostNetwork{Interface: "eno1", Address: "192.168.111.30/24"}},
+			},
+		},
+		{
+			name:          "no memory",
+			controlPlane:  baremetal.VirtualControlPlane{CPUs: 4, DiskGiB: 120},
+			expectedError: `^test-path\.memoryMiB: Invalid value: 0: must be positive$`,
+		},
+		{
+			name:         "master with static network",
+			controlPlane: valid,
+			hosts: []baremetal.Host{
+				{Name: "master-0", Role: baremetal.MasterRole, Network: &baremetal.HostNetwork{Interface: "ens3", Address: "192.168.111.20/24"}},
+			},
+			expectedError: `^hosts\[0\]\.network: Forbidden: virtual control plane machines are configured by DHCP$`,
+		},
+		{
+			name:         "master with hardware",
+			controlPlane: valid,
+			hosts: []baremetal.Host{
+				{Name: "master-0", Role: baremetal.MasterRole, Hardware: &baremetal.HostHardware{CPUs: 8}},
+			},
+			expectedError: `^hosts\[0\]\.hardware: Forbidden: virtual control plane machines are sized by virtualControlPlane$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateVirtualControlPlane(&tc.controlPlane, tc.hosts, field.NewPath("test-path"), field.NewPath("hosts")).ToAggregate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
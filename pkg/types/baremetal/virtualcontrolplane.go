@@ -0,0 +1,22 @@
+package baremetal
+
+// VirtualControlPlane runs the control plane as libvirt VMs on the
+// provisioning host, alongside the bootstrap VM, on the baremetal bridge.
+// Only the workers, and any etcd hosts, are physical.  The master hosts
+// of the inventory, if any, name the VMs and fix their MAC addresses.
+type VirtualControlPlane struct {
+	// MemoryMiB is the memory of each VM in MiB.
+	// +optional
+	// Default is 16384.
+	MemoryMiB int `json:"memoryMiB,omitempty"`
+
+	// CPUs is the number of virtual CPUs of each VM.
+	// +optional
+	// Default is 4.
+	CPUs int `json:"cpus,omitempty"`
+
+	// DiskGiB is the size of each VM's disk in GiB.
+	// +optional
+	// Default is 120.
+	DiskGiB int `json:"diskGiB,omitempty"`
+}
@@ -0,0 +1,38 @@
+package types
+
+// Capability is an optional cluster component.
+type Capability string
+
+const (
+	// CapabilityConsole is the web console and its operator.
+	CapabilityConsole Capability = "console"
+
+	// CapabilityMarketplace is the operator marketplace, serving the
+	// catalogs of optional operators.
+	CapabilityMarketplace Capability = "marketplace"
+
+	// CapabilitySamples is the samples operator, importing the sample
+	// image streams and templates.
+	CapabilitySamples Capability = "samples"
+)
+
+// Capabilities selects the optional cluster components installed.
+type Capabilities struct {
+	// Disabled are the optional components which are not installed, e.g.
+	// to shrink an edge cluster.
+	// +optional
+	Disabled []Capability `json:"disabled,omitempty"`
+}
+
+// CapabilityEnabled returns true unless capability is disabled.
+func (c *InstallConfig) CapabilityEnabled(capability Capability) bool {
+	if c.Capabilities == nil {
+		return true
+	}
+	for _, disabled := range c.Capabilities.Disabled {
+		if disabled == capability {
+			return false
+		}
+	}
+	return true
+}
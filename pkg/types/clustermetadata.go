@@ -1,8 +1,11 @@
 package types
 
 import (
+	"time"
+
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
 )
@@ -15,16 +18,60 @@ type ClusterMetadata struct {
 	// clusterID is a globally unique ID that is used to identify an Openshift cluster.
 	ClusterID string `json:"clusterID"`
 	// infraID is an ID that is used to identify cloud resources created by the installer.
-	InfraID                 string `json:"infraID"`
+	InfraID string `json:"infraID"`
+	// expiresAt is when an ephemeral cluster's certificates and
+	// credentials expire, and the cluster should be destroyed.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// certificateAudit identifies the certificate audit log written
+	// alongside the metadata.
+	CertificateAudit *CertificateAuditMetadata `json:"certificateAudit,omitempty"`
+	// timings records how long the install took, by stage.
+	Timings *TimingMetadata `json:"timings,omitempty"`
+	// simulated marks clusters created with --simulate, whose resources
+	// only exist in Terraform's in-memory backend.
+	Simulated bool `json:"simulated,omitempty"`
+	// terraformState is the location of the cluster's Terraform state,
+	// if it is not in the asset directory, e.g. s3://bucket/key.
+	TerraformState          string `json:"terraformState,omitempty"`
 	ClusterPlatformMetadata `json:",inline"`
 }
 
+// CertificateAuditMetadata records the extent of the certificate audit
+// log, so a truncated or rewritten log can be detected.
+type CertificateAuditMetadata struct {
+	// entries is the number of certificates recorded in the log.
+	Entries int `json:"entries"`
+	// head is the hex-encoded SHA-256 digest of the last line of the
+	// log.
+	Head string `json:"head"`
+}
+
+// TimingMetadata records the time spent in each stage of an install.
+type TimingMetadata struct {
+	// totalSeconds is the time the install took.
+	TotalSeconds float64 `json:"totalSeconds"`
+	// stages are the stages of the install, in the order they started.
+	Stages []StageTiming `json:"stages"`
+}
+
+// StageTiming is the time spent in a stage, excluding the stages nested
+// within it.
+type StageTiming struct {
+	// group is the kind of stage, e.g. assets, terraform or wait.
+	Group string `json:"group"`
+	// name identifies the stage within its group, e.g. tls or apply.
+	Name string `json:"name"`
+	// seconds is the time spent in the stage.
+	Seconds float64 `json:"seconds"`
+}
+
 // ClusterPlatformMetadata contains metadata for platfrom.
 type ClusterPlatformMetadata struct {
 	AWS       *aws.Metadata       `json:"aws,omitempty"`
 	OpenStack *openstack.Metadata `json:"openstack,omitempty"`
 	Libvirt   *libvirt.Metadata   `json:"libvirt,omitempty"`
 	BareMetal *baremetal.Metadata `json:"baremetal,omitempty"`
+	Fake      *fake.Metadata      `json:"fake,omitempty"`
 }
 
 // Platform returns a string representation of the platform
@@ -46,5 +93,8 @@ func (cpm *ClusterPlatformMetadata) Platform() string {
 	if cpm.BareMetal != nil {
 		return "baremetal"
 	}
+	if cpm.Fake != nil {
+		return "fake"
+	}
 	return ""
 }
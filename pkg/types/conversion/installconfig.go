@@ -3,6 +3,7 @@ package conversion
 import (
 	"github.com/metalkube/kni-installer/pkg/ipnet"
 	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/warnings"
 	"github.com/pkg/errors"
 )
 
@@ -13,8 +14,10 @@ import (
 func ConvertInstallConfig(config *types.InstallConfig) error {
 	// check that the version is convertible
 	switch config.APIVersion {
-	case types.InstallConfigVersion, "v1beta3":
+	case types.InstallConfigVersion:
 		// works
+	case "v1beta3":
+		warnings.Warnf(warnings.Deprecated, "apiVersion %s is deprecated, use %s", config.APIVersion, types.InstallConfigVersion)
 	default:
 		return errors.Errorf("cannot upconvert from version %s", config.APIVersion)
 	}
@@ -32,22 +35,26 @@ func ConvertNetworking(config *types.InstallConfig) {
 
 	netconf := config.Networking
 
-	if len(netconf.ClusterNetwork) == 0 {
+	if len(netconf.ClusterNetwork) == 0 && len(netconf.DeprecatedClusterNetworks) > 0 {
+		warnings.Warnf(warnings.Deprecated, "networking.clusterNetworks is deprecated, use networking.clusterNetwork")
 		netconf.ClusterNetwork = netconf.DeprecatedClusterNetworks
 	}
 
 	if len(netconf.ServiceNetwork) == 0 && netconf.DeprecatedServiceCIDR != nil {
+		warnings.Warnf(warnings.Deprecated, "networking.serviceCIDR is deprecated, use networking.serviceNetwork")
 		netconf.ServiceNetwork = []ipnet.IPNet{*netconf.DeprecatedServiceCIDR}
 	}
 
 	// Convert type to networkType if the latter is missing
-	if netconf.NetworkType == "" {
+	if netconf.NetworkType == "" && netconf.DeprecatedType != "" {
+		warnings.Warnf(warnings.Deprecated, "networking.type is deprecated, use networking.networkType")
 		netconf.NetworkType = netconf.DeprecatedType
 	}
 
 	// Convert hostSubnetLength to hostPrefix
 	for i, entry := range netconf.ClusterNetwork {
 		if entry.HostPrefix == 0 && entry.DeprecatedHostSubnetLength != 0 {
+			warnings.Warnf(warnings.Deprecated, "networking.clusterNetwork[%d].hostSubnetLength is deprecated, use hostPrefix", i)
 			_, size := entry.CIDR.Mask.Size()
 			netconf.ClusterNetwork[i].HostPrefix = int32(size) - entry.DeprecatedHostSubnetLength
 		}
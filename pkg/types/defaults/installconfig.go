@@ -1,6 +1,8 @@
 package defaults
 
 import (
+	"fmt"
+
 	"github.com/metalkube/kni-installer/pkg/ipnet"
 	"github.com/metalkube/kni-installer/pkg/types"
 	awsdefaults "github.com/metalkube/kni-installer/pkg/types/aws/defaults"
@@ -16,6 +18,15 @@ var (
 	defaultClusterNetwork = ipnet.MustParseCIDR("10.128.0.0/14")
 	defaultHostPrefix     = 23
 	defaultNetworkType    = "OpenShiftSDN"
+
+	defaultEtcdBackupSchedule  = "0 */6 * * *"
+	defaultEtcdBackupRetention = 14
+	defaultEtcdBackupImage     = "quay.io/coreos/etcd:v3.3.10"
+	defaultEtcdBackupHostPath  = "/var/lib/etcd-backup"
+	defaultEtcdBackupSize      = "10Gi"
+
+	defaultWorkloadIssuerName      = "workload-issuing-ca"
+	defaultWorkloadIssuerNamespace = "cert-manager"
 )
 
 // SetInstallConfigDefaults sets the defaults for the install config.
@@ -43,13 +54,25 @@ func SetInstallConfigDefaults(c *types.InstallConfig) {
 			},
 		}
 	}
+	if c.Profile == "" {
+		c.Profile = types.ProfileStandard
+	}
 	defaultReplicaCount := int64(3)
 	if c.Platform.Libvirt != nil {
 		defaultReplicaCount = 1
 	}
+	controlPlaneReplicas := defaultReplicaCount
+	computeReplicas := defaultReplicaCount
+	switch c.Profile {
+	case types.ProfileCompact:
+		controlPlaneReplicas = 3
+		computeReplicas = 0
+	case types.ProfileHAStretch:
+		controlPlaneReplicas = 5
+	}
 	if c.ControlPlane == nil {
 		c.ControlPlane = &types.MachinePool{
-			Replicas: &defaultReplicaCount,
+			Replicas: &controlPlaneReplicas,
 		}
 	}
 	c.ControlPlane.Name = "master"
@@ -57,13 +80,71 @@ func SetInstallConfigDefaults(c *types.InstallConfig) {
 		c.Compute = []types.MachinePool{
 			{
 				Name:     "worker",
-				Replicas: &defaultReplicaCount,
+				Replicas: &computeReplicas,
 			},
 		}
 	}
+	if c.ControlPlane.Architecture == "" {
+		c.ControlPlane.Architecture = types.ArchitectureAMD64
+	}
 	for i, p := range c.Compute {
 		if p.Replicas == nil {
-			c.Compute[i].Replicas = &defaultReplicaCount
+			c.Compute[i].Replicas = &computeReplicas
+		}
+		if p.Architecture == "" {
+			c.Compute[i].Architecture = types.ArchitectureAMD64
+		}
+	}
+	for i, p := range c.MachineConfigPools {
+		if len(p.NodeSelector) == 0 {
+			c.MachineConfigPools[i].NodeSelector = map[string]string{
+				fmt.Sprintf("node-role.kubernetes.io/%s", p.Name): "",
+			}
+		}
+		for j, f := range p.Files {
+			if f.Mode == nil {
+				mode := 0644
+				c.MachineConfigPools[i].Files[j].Mode = &mode
+			}
+		}
+	}
+	if c.SecretsEncryption != nil && c.SecretsEncryption.Tool == "" {
+		c.SecretsEncryption.Tool = types.EncryptionToolSOPS
+	}
+	if p := c.CredentialsPolicy; p != nil {
+		if p.Length == 0 {
+			p.Length = 23
+		}
+		if len(p.CharacterClasses) == 0 {
+			p.CharacterClasses = []types.CharacterClass{types.CharacterClassLower, types.CharacterClassUpper, types.CharacterClassDigit}
+		}
+		if p.BcryptCost == 0 {
+			p.BcryptCost = 10
+		}
+	}
+	if b := c.EtcdBackup; b != nil {
+		if b.Schedule == "" {
+			b.Schedule = defaultEtcdBackupSchedule
+		}
+		if b.Retention == 0 {
+			b.Retention = defaultEtcdBackupRetention
+		}
+		if b.Image == "" {
+			b.Image = defaultEtcdBackupImage
+		}
+		if b.PersistentVolumeClaim == nil && b.HostPath == "" {
+			b.HostPath = defaultEtcdBackupHostPath
+		}
+		if b.PersistentVolumeClaim != nil && b.PersistentVolumeClaim.Size == "" {
+			b.PersistentVolumeClaim.Size = defaultEtcdBackupSize
+		}
+	}
+	if c.TLS != nil && c.TLS.WorkloadIssuer != nil {
+		if c.TLS.WorkloadIssuer.Name == "" {
+			c.TLS.WorkloadIssuer.Name = defaultWorkloadIssuerName
+		}
+		if c.TLS.WorkloadIssuer.Namespace == "" {
+			c.TLS.WorkloadIssuer.Namespace = defaultWorkloadIssuerNamespace
 		}
 	}
 	switch {
@@ -32,15 +32,18 @@ func defaultInstallConfig() *types.InstallConfig {
 			},
 		},
 		ControlPlane: &types.MachinePool{
-			Name:     "master",
-			Replicas: pointer.Int64Ptr(3),
+			Name:         "master",
+			Replicas:     pointer.Int64Ptr(3),
+			Architecture: types.ArchitectureAMD64,
 		},
 		Compute: []types.MachinePool{
 			{
-				Name:     "worker",
-				Replicas: pointer.Int64Ptr(3),
+				Name:         "worker",
+				Replicas:     pointer.Int64Ptr(3),
+				Architecture: types.ArchitectureAMD64,
 			},
 		},
+		Profile: types.ProfileStandard,
 	}
 }
 
@@ -178,8 +181,146 @@ func TestSetInstallConfigDefaults(t *testing.T) {
 				c := defaultInstallConfig()
 				c.Compute = []types.MachinePool{
 					{
-						Name:     "test-compute",
-						Replicas: pointer.Int64Ptr(3),
+						Name:         "test-compute",
+						Replicas:     pointer.Int64Ptr(3),
+						Architecture: types.ArchitectureAMD64,
+					},
+				}
+				return c
+			}(),
+		},
+		{
+			name: "MachineConfigPools present",
+			config: &types.InstallConfig{
+				MachineConfigPools: []types.MachineConfigPool{{
+					Name:  "infra",
+					Files: []types.MachineConfigFile{{Path: "/etc/infra"}},
+				}},
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				mode := 0644
+				c.MachineConfigPools = []types.MachineConfigPool{{
+					Name:         "infra",
+					NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+					Files:        []types.MachineConfigFile{{Path: "/etc/infra", Mode: &mode}},
+				}}
+				return c
+			}(),
+		},
+		{
+			name: "CredentialsPolicy present",
+			config: &types.InstallConfig{
+				CredentialsPolicy: &types.CredentialsPolicy{Length: 32},
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				c.CredentialsPolicy = &types.CredentialsPolicy{
+					Length:           32,
+					CharacterClasses: []types.CharacterClass{types.CharacterClassLower, types.CharacterClassUpper, types.CharacterClassDigit},
+					BcryptCost:       10,
+				}
+				return c
+			}(),
+		},
+		{
+			name: "empty EtcdBackup",
+			config: &types.InstallConfig{
+				EtcdBackup: &types.EtcdBackup{},
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				c.EtcdBackup = &types.EtcdBackup{
+					Schedule:  "0 */6 * * *",
+					Retention: 14,
+					Image:     "quay.io/coreos/etcd:v3.3.10",
+					HostPath:  "/var/lib/etcd-backup",
+				}
+				return c
+			}(),
+		},
+		{
+			name: "empty WorkloadIssuer",
+			config: &types.InstallConfig{
+				TLS: &types.TLS{WorkloadIssuer: &types.WorkloadIssuer{}},
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				c.TLS = &types.TLS{WorkloadIssuer: &types.WorkloadIssuer{
+					Name:      "workload-issuing-ca",
+					Namespace: "cert-manager",
+				}}
+				return c
+			}(),
+		},
+		{
+			name: "EtcdBackup to a volume claim",
+			config: &types.InstallConfig{
+				EtcdBackup: &types.EtcdBackup{
+					Retention:             3,
+					PersistentVolumeClaim: &types.EtcdBackupVolumeClaim{StorageClassName: "nfs"},
+				},
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				c.EtcdBackup = &types.EtcdBackup{
+					Schedule:              "0 */6 * * *",
+					Retention:             3,
+					Image:                 "quay.io/coreos/etcd:v3.3.10",
+					PersistentVolumeClaim: &types.EtcdBackupVolumeClaim{StorageClassName: "nfs", Size: "10Gi"},
+				}
+				return c
+			}(),
+		},
+		{
+			name: "Architecture present",
+			config: &types.InstallConfig{
+				Compute: []types.MachinePool{{Name: "arm", Architecture: types.ArchitectureARM64}},
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				c.Compute = []types.MachinePool{
+					{
+						Name:         "arm",
+						Replicas:     pointer.Int64Ptr(3),
+						Architecture: types.ArchitectureARM64,
+					},
+				}
+				return c
+			}(),
+		},
+		{
+			name: "Compact profile",
+			config: &types.InstallConfig{
+				Profile: types.ProfileCompact,
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				c.Profile = types.ProfileCompact
+				c.Compute[0].Replicas = pointer.Int64Ptr(0)
+				return c
+			}(),
+		},
+		{
+			name: "HA stretch profile",
+			config: &types.InstallConfig{
+				Profile: types.ProfileHAStretch,
+				Compute: []types.MachinePool{{Name: "zone-a"}, {Name: "zone-b", Replicas: pointer.Int64Ptr(2)}},
+			},
+			expected: func() *types.InstallConfig {
+				c := defaultInstallConfig()
+				c.Profile = types.ProfileHAStretch
+				c.ControlPlane.Replicas = pointer.Int64Ptr(5)
+				c.Compute = []types.MachinePool{
+					{
+						Name:         "zone-a",
+						Replicas:     pointer.Int64Ptr(3),
+						Architecture: types.ArchitectureAMD64,
+					},
+					{
+						Name:         "zone-b",
+						Replicas:     pointer.Int64Ptr(2),
+						Architecture: types.ArchitectureAMD64,
 					},
 				}
 				return c
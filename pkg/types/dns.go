@@ -0,0 +1,79 @@
+package types
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DNS configures the nodes' resolvers, which the cluster's DNS forwards
+// the queries it cannot answer itself to, i.e. for names outside the
+// cluster, and how the datacenter's DNS resolves the cluster's API names.
+type DNS struct {
+	// Upstreams are the resolvers of the nodes' /etc/resolv.conf, as IP
+	// or IP:53, instead of those from DHCP.
+	// +optional
+	Upstreams []string `json:"upstreams,omitempty"`
+
+	// Forwarders forward the queries for particular zones, e.g. internal
+	// zones which only the site's own resolvers serve.  They are not
+	// supported, as the DNS operator of the supported releases cannot
+	// forward zones.
+	// +optional
+	Forwarders []DNSForwarder `json:"forwarders,omitempty"`
+
+	// Views resolves the API names differently for clients outside the
+	// datacenter and for the cluster's own machines (split-horizon DNS).
+	// +optional
+	Views *DNSViews `json:"views,omitempty"`
+}
+
+// DNSViews are the targets of the API names in the views of a
+// split-horizon DNS.  Targets are IP addresses, or names the API names are
+// aliases (CNAMEs) of, e.g. a load balancer's.
+type DNSViews struct {
+	// External are the targets of api.<cluster domain> in the view of
+	// clients outside the datacenter.
+	External []string `json:"external"`
+
+	// Internal are the targets of api-int.<cluster domain>, and of
+	// api.<cluster domain>, in the view of the cluster's machines.
+	Internal []string `json:"internal"`
+}
+
+// DNSForwarder forwards the queries for some zones to their resolvers.
+type DNSForwarder struct {
+	// Name identifies the forwarder in the DNS operator's config.
+	Name string `json:"name"`
+
+	// Zones are the domains whose names are resolved by Upstreams, e.g.
+	// corp.example.com.
+	Zones []string `json:"zones"`
+
+	// Upstreams are the resolvers of the zones, as IP or IP:port.
+	Upstreams []string `json:"upstreams"`
+}
+
+// DefaultDNSPort is the port of an upstream resolver given without one.
+const DefaultDNSPort = 53
+
+// SplitDNSUpstream splits an upstream resolver, given as IP or IP:port
+// (with an IPv6 address in brackets), into its address and port.
+func SplitDNSUpstream(upstream string) (string, int, error) {
+	host, port := upstream, DefaultDNSPort
+	if h, p, err := net.SplitHostPort(upstream); err == nil {
+		host = h
+		port, err = strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			return "", 0, errors.Errorf("invalid port %q", p)
+		}
+	} else if strings.HasPrefix(upstream, "[") && strings.HasSuffix(upstream, "]") {
+		host = strings.TrimSuffix(strings.TrimPrefix(upstream, "["), "]")
+	}
+	if net.ParseIP(host) == nil {
+		return "", 0, errors.Errorf("%q is not an IP address", host)
+	}
+	return host, port, nil
+}
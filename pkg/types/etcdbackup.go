@@ -0,0 +1,48 @@
+package types
+
+// EtcdBackup schedules snapshots of etcd from the day the cluster is
+// installed.  The snapshots are taken by a CronJob and kept either on the
+// control plane hosts or on a persistent volume.
+type EtcdBackup struct {
+	// Schedule is when snapshots are taken, in cron format.
+	// Default is "0 */6 * * *", every six hours.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention is the number of snapshots kept; older ones are deleted.
+	// Default is 14.
+	// +optional
+	Retention int `json:"retention,omitempty"`
+
+	// Image is the image the snapshots are taken with, which must provide
+	// etcdctl and a POSIX shell.
+	// Default is quay.io/coreos/etcd:v3.3.10.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// HostPath is the directory on the control plane host running the job
+	// which the snapshots are written to.  It cannot be combined with
+	// PersistentVolumeClaim.
+	// Default is /var/lib/etcd-backup, unless PersistentVolumeClaim is set.
+	// +optional
+	HostPath string `json:"hostPath,omitempty"`
+
+	// PersistentVolumeClaim, when set, is a claim created for the
+	// snapshots, so they are kept off the control plane hosts.
+	// +optional
+	PersistentVolumeClaim *EtcdBackupVolumeClaim `json:"persistentVolumeClaim,omitempty"`
+}
+
+// EtcdBackupVolumeClaim is the persistent volume claim the etcd snapshots
+// are written to.
+type EtcdBackupVolumeClaim struct {
+	// StorageClassName is the storage class of the claim.
+	// Default is the cluster's default storage class.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Size is the requested size of the volume, e.g. 10Gi.
+	// Default is 10Gi.
+	// +optional
+	Size string `json:"size,omitempty"`
+}
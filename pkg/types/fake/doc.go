@@ -0,0 +1,8 @@
+// Package fake contains the configuration of the fake platform, which
+// generates every asset and stands in for Terraform with an in-memory
+// backend, so the whole create pipeline can run without infrastructure,
+// e.g. in tests and CI.
+package fake
+
+// Name is name for the fake platform.
+const Name string = "fake"
@@ -0,0 +1,8 @@
+package fake
+
+// Metadata contains fake metadata (e.g. for uninstalling the cluster).
+type Metadata struct {
+	// ClusterID is the ID under which the in-memory backend recorded
+	// the cluster.
+	ClusterID string `json:"clusterID"`
+}
@@ -0,0 +1,5 @@
+package fake
+
+// Platform stores the global configuration of the fake platform, which
+// has none.
+type Platform struct{}
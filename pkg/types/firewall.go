@@ -0,0 +1,10 @@
+package types
+
+// Firewall configures the nodes' host firewall.
+type Firewall struct {
+	// NFTables, when true, applies nftables rules on the control plane and
+	// compute nodes which drop incoming traffic other than the flows the
+	// cluster requires, as listed in firewall-requirements.json.
+	// +optional
+	NFTables bool `json:"nftables,omitempty"`
+}
@@ -6,10 +6,12 @@ import (
 	"github.com/metalkube/kni-installer/pkg/ipnet"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	"github.com/metalkube/kni-installer/pkg/types/none"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmd "k8s.io/client-go/tools/clientcmd/api/v1"
 )
 
 const (
@@ -17,6 +19,11 @@ const (
 	// If you bump this, you must also update the list of convertable values in
 	// pkg/conversion/installconfig.go
 	InstallConfigVersion = "v1beta4"
+
+	// NetworkTypeCustom is the network type of a cluster whose CNI is
+	// deployed from manifests the user provides, instead of by the
+	// cluster network operator.
+	NetworkTypeCustom = "Custom"
 )
 
 var (
@@ -31,6 +38,7 @@ var (
 	// to the user in the interactive wizard.
 	HiddenPlatformNames = []string{
 		aws.Name,
+		fake.Name,
 		none.Name,
 		openstack.Name,
 	}
@@ -62,12 +70,224 @@ type InstallConfig struct {
 	// +optional
 	Compute []MachinePool `json:"compute,omitempty"`
 
+	// MachineConfigPools are additional pools of the machine-config
+	// operator, e.g. for infra nodes, along with the files their nodes get.
+	// +optional
+	MachineConfigPools []MachineConfigPool `json:"machineConfigPools,omitempty"`
+
 	// Platform is the configuration for the specific platform upon which to
 	// perform the installation.
 	Platform `json:"platform"`
 
 	// PullSecret is the secret to use when pulling images.
 	PullSecret string `json:"pullSecret"`
+
+	// AdditionalPullSecrets are more registry credentials, e.g. for mirrors
+	// or private catalogs, in the same format as PullSecret.  They are
+	// merged with PullSecret into the cluster's global pull secret.
+	// +optional
+	AdditionalPullSecrets []string `json:"additionalPullSecrets,omitempty"`
+
+	// InfraID customizes the infrastructure ID used to name the resources
+	// created for the cluster.  By default it is the cluster name followed
+	// by a random suffix.
+	// +optional
+	InfraID *InfraID `json:"infraID,omitempty"`
+
+	// AdminKubeconfig customizes the admin kubeconfig written to
+	// auth/kubeconfig.
+	// +optional
+	AdminKubeconfig *AdminKubeconfig `json:"adminKubeconfig,omitempty"`
+
+	// SecretsEncryption, when set, lets the secret-bearing files the
+	// installer generates be encrypted, so the asset directory and exported
+	// manifests can be committed to git.
+	// +optional
+	SecretsEncryption *SecretsEncryption `json:"secretsEncryption,omitempty"`
+
+	// CredentialsPolicy constrains the passwords the installer generates,
+	// such as the kubeadmin password, to satisfy site password policies.
+	// +optional
+	CredentialsPolicy *CredentialsPolicy `json:"credentialsPolicy,omitempty"`
+
+	// Entitlements, when set, are the Red Hat subscription entitlement the
+	// cluster's compute hosts are registered with, as RHEL compute pools
+	// need.
+	// +optional
+	Entitlements *Entitlements `json:"entitlements,omitempty"`
+
+	// EtcdBackup, when set, schedules snapshots of etcd from install time.
+	// +optional
+	EtcdBackup *EtcdBackup `json:"etcdBackup,omitempty"`
+
+	// TLSSecurityProfile, when set, is the TLS profile of the API servers,
+	// the default ingress controller and the nodes' system crypto policy.
+	// +optional
+	// Default is the cluster's default, Intermediate.
+	TLSSecurityProfile *TLSSecurityProfile `json:"tlsSecurityProfile,omitempty"`
+
+	// TLS, when set, configures the certificates the installer generates.
+	// +optional
+	TLS *TLS `json:"tls,omitempty"`
+
+	// DNS, when set, configures the resolvers the cluster's DNS forwards
+	// to, so nodes and pods resolve the site's internal zones from the
+	// start.
+	// +optional
+	DNS *DNS `json:"dns,omitempty"`
+
+	// Firewall, when set, configures the nodes' host firewall.
+	// +optional
+	Firewall *Firewall `json:"firewall,omitempty"`
+
+	// Capabilities, when set, disables optional cluster components.
+	// +optional
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+
+	// Profile selects a cluster topology, which sets defaults for and
+	// constrains the machine pools.
+	// +optional
+	// Default is standard.
+	Profile Profile `json:"profile,omitempty"`
+}
+
+// Profile is a named cluster topology.
+type Profile string
+
+const (
+	// ProfileCompact is a three node cluster with no compute machines.
+	// Workloads must run on the control plane.
+	ProfileCompact Profile = "compact"
+
+	// ProfileStandard is a cluster with three control plane machines and
+	// at least one compute machine.
+	ProfileStandard Profile = "standard"
+
+	// ProfileHAStretch is a cluster stretched across failure domains, with
+	// five control plane machines and compute pools in at least two zones.
+	ProfileHAStretch Profile = "ha-stretch"
+)
+
+// ZoneLabel is the node label naming the zone of a machine pool's nodes.
+const ZoneLabel = "failure-domain.beta.kubernetes.io/zone"
+
+// InfraIDMaxLength is the maximum length of an infrastructure ID.  Resources
+// using it usually have suffixes like `[-/_][a-z]{3,4}`, e.g. `_int`, `-ext`
+// or `-ctlp`.
+const InfraIDMaxLength = 32 - 5
+
+// InfraIDDefaultRandomLength is the length of the random suffix of a
+// generated infrastructure ID when InfraID.RandomLength is unset.
+const InfraIDDefaultRandomLength = 5
+
+// InfraID customizes the generated infrastructure ID.
+type InfraID struct {
+	// Value pins the infrastructure ID.  It cannot be combined with Prefix
+	// or RandomLength.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Prefix replaces the cluster name as the base of the generated ID.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// RandomLength is the number of random characters appended to the
+	// base.  Zero disables the random suffix.
+	// +optional
+	// Default is 5.
+	RandomLength *int32 `json:"randomLength,omitempty"`
+}
+
+// AdminKubeconfig customizes the admin kubeconfig.
+type AdminKubeconfig struct {
+	// Exec authenticates the admin user with an exec credential plugin,
+	// e.g. for OIDC, instead of embedding the long-lived admin client
+	// certificate in the kubeconfig.  The installer still uses the client
+	// certificate from the asset directory while it waits for the cluster.
+	// +optional
+	Exec *clientcmd.ExecConfig `json:"exec,omitempty"`
+}
+
+// EncryptionTool is a tool encrypting secret-bearing files.
+type EncryptionTool string
+
+const (
+	// EncryptionToolSOPS encrypts with sops, which keeps the structure of
+	// YAML and JSON files and only encrypts their values, so GitOps tools
+	// with sops support can apply encrypted Secrets.
+	EncryptionToolSOPS EncryptionTool = "sops"
+
+	// EncryptionToolAge encrypts whole files with age.
+	EncryptionToolAge EncryptionTool = "age"
+)
+
+// SecretsEncryption configures the encryption of secret-bearing files.
+type SecretsEncryption struct {
+	// Tool is the encryption tool, which must be installed on the
+	// installer host.
+	// +optional
+	// Default is sops.
+	Tool EncryptionTool `json:"tool,omitempty"`
+
+	// Recipients are the age public keys (age1...) the files are
+	// encrypted for.  Any of their private keys decrypts the files.
+	Recipients []string `json:"recipients"`
+}
+
+// CharacterClass is a class of characters generated passwords are made of.
+type CharacterClass string
+
+const (
+	// CharacterClassLower is the lower case letters.
+	CharacterClassLower CharacterClass = "lower"
+
+	// CharacterClassUpper is the upper case letters.
+	CharacterClassUpper CharacterClass = "upper"
+
+	// CharacterClassDigit is the decimal digits.
+	CharacterClassDigit CharacterClass = "digit"
+
+	// CharacterClassSymbol is the ASCII punctuation characters.
+	CharacterClassSymbol CharacterClass = "symbol"
+)
+
+// CredentialsPolicy constrains generated passwords and their hashes.
+type CredentialsPolicy struct {
+	// Length is the number of characters in each password.
+	// +optional
+	// Default is 23.
+	Length int `json:"length,omitempty"`
+
+	// CharacterClasses are the classes of characters passwords are made
+	// of.  Every password contains at least one character of each class.
+	// Passwords without symbols are split into groups of five characters
+	// with dashes, which count towards the length.
+	// +optional
+	// Default is lower, upper and digit.
+	CharacterClasses []CharacterClass `json:"characterClasses,omitempty"`
+
+	// BcryptCost is the cost of the bcrypt hashes of the passwords, e.g.
+	// for the htpasswd-style kubeadmin secret.
+	// +optional
+	// Default is 10.
+	BcryptCost int `json:"bcryptCost,omitempty"`
+}
+
+// Entitlements are a Red Hat subscription entitlement, as found in
+// /etc/pki/entitlement on a registered RHEL host.
+type Entitlements struct {
+	// Certificate is the PEM entitlement certificate, e.g. the contents of
+	// /etc/pki/entitlement/<serial>.pem.
+	Certificate string `json:"certificate"`
+
+	// Key is the PEM private key of the entitlement certificate, e.g. the
+	// contents of /etc/pki/entitlement/<serial>-key.pem.
+	Key string `json:"key"`
+
+	// RHSMConfig is the subscription-manager configuration, rhsm.conf,
+	// e.g. to register with a Satellite server.
+	// +optional
+	RHSMConfig string `json:"rhsmConfig,omitempty"`
 }
 
 // ClusterDomain returns the DNS domain that all records for a cluster must belong to.
@@ -82,6 +302,11 @@ type Platform struct {
 	// +optional
 	AWS *aws.Platform `json:"aws,omitempty"`
 
+	// Fake is the configuration used when generating every asset
+	// without provisioning anything, e.g. in tests and CI.
+	// +optional
+	Fake *fake.Platform `json:"fake,omitempty"`
+
 	// Libvirt is the configuration used when installing on libvirt.
 	// +optional
 	Libvirt *libvirt.Platform `json:"libvirt,omitempty"`
@@ -109,6 +334,9 @@ func (p *Platform) Name() string {
 	if p.AWS != nil {
 		return aws.Name
 	}
+	if p.Fake != nil {
+		return fake.Name
+	}
 	if p.Libvirt != nil {
 		return libvirt.Name
 	}
@@ -132,7 +360,8 @@ type Networking struct {
 	// For Libvirt, the default is 192.168.126.0/24.
 	MachineCIDR *ipnet.IPNet `json:"machineCIDR,omitempty"`
 
-	// NetworkType is the type of network to install.
+	// NetworkType is the type of network to install.  Custom deploys the
+	// CNI manifests in the asset directory's cni/ instead.
 	// +optional
 	// Default is OpenShiftSDN.
 	NetworkType string `json:"networkType,omitempty"`
@@ -13,3 +13,24 @@ func TestPlatformNamesSorted(t *testing.T) {
 	sort.Strings(sorted)
 	assert.Equal(t, sorted, PlatformNames)
 }
+
+func TestMergedPullSecret(t *testing.T) {
+	c := &InstallConfig{
+		PullSecret: `{"auths": {"example.com": {"auth": "a"}}}`,
+	}
+	merged, err := c.MergedPullSecret()
+	assert.NoError(t, err)
+	assert.Equal(t, c.PullSecret, merged)
+
+	c.AdditionalPullSecrets = []string{
+		`{"auths": {"mirror.example.com": {"auth": "b"}}}`,
+		`{"auths": {"example.com": {"auth": "a"}, "catalog.example.com": {"auth": "c"}}}`,
+	}
+	merged, err = c.MergedPullSecret()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"auths":{"catalog.example.com":{"auth":"c"},"example.com":{"auth":"a"},"mirror.example.com":{"auth":"b"}}}`, merged)
+
+	c.AdditionalPullSecrets = append(c.AdditionalPullSecrets, `{"auths": {"mirror.example.com": {"auth": "d"}}}`)
+	_, err = c.MergedPullSecret()
+	assert.EqualError(t, err, `additionalPullSecrets[2]: conflicting credentials for registry "mirror.example.com"`)
+}
@@ -0,0 +1,25 @@
+package defaults
+
+import (
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
+)
+
+// SetFirmwareDefaults sets the defaults for the firmware.
+func SetFirmwareDefaults(f *libvirt.Firmware) {
+	if f.Type == "" {
+		f.Type = libvirt.FirmwareBIOS
+	}
+	if f.Type != libvirt.FirmwareUEFI {
+		return
+	}
+	suffix := ""
+	if f.SecureBoot {
+		suffix = ".secboot"
+	}
+	if f.Loader == "" {
+		f.Loader = "/usr/share/OVMF/OVMF_CODE" + suffix + ".fd"
+	}
+	if f.NVRAMTemplate == "" {
+		f.NVRAMTemplate = "/usr/share/OVMF/OVMF_VARS" + suffix + ".fd"
+	}
+}
@@ -18,4 +18,7 @@ func SetPlatformDefaults(p *libvirt.Platform) {
 		p.Network = &libvirt.Network{}
 	}
 	SetNetworkDefaults(p.Network)
+	if p.Firmware != nil {
+		SetFirmwareDefaults(p.Firmware)
+	}
 }
@@ -39,6 +39,25 @@ func TestSetPlatformDefaults(t *testing.T) {
 				return p
 			}(),
 		},
+		{
+			name: "UEFI with secure boot",
+			platform: &libvirt.Platform{
+				Firmware: &libvirt.Firmware{
+					Type:       libvirt.FirmwareUEFI,
+					SecureBoot: true,
+				},
+			},
+			expected: func() *libvirt.Platform {
+				p := defaultPlatform()
+				p.Firmware = &libvirt.Firmware{
+					Type:          libvirt.FirmwareUEFI,
+					SecureBoot:    true,
+					Loader:        "/usr/share/OVMF/OVMF_CODE.secboot.fd",
+					NVRAMTemplate: "/usr/share/OVMF/OVMF_VARS.secboot.fd",
+				}
+				return p
+			}(),
+		},
 		{
 			name: "Network present",
 			platform: &libvirt.Platform{
@@ -0,0 +1,40 @@
+package libvirt
+
+// FirmwareType is the firmware libvirt domains boot with.
+type FirmwareType string
+
+const (
+	// FirmwareBIOS boots domains with SeaBIOS.
+	FirmwareBIOS FirmwareType = "bios"
+
+	// FirmwareUEFI boots domains with OVMF, like UEFI-only bare metal.
+	FirmwareUEFI FirmwareType = "uefi"
+)
+
+// Firmware is the configuration of the firmware of the libvirt domains
+// created by the installer, i.e. the bootstrap and control plane domains.
+// Compute domains are created by the machine API, which always uses BIOS.
+type Firmware struct {
+	// Type is the firmware the domains boot with.
+	// +optional
+	// Default is bios.
+	Type FirmwareType `json:"type,omitempty"`
+
+	// SecureBoot enables UEFI secure boot, using the q35 machine type with
+	// SMM as OVMF requires.  It is only supported with UEFI firmware.
+	// +optional
+	SecureBoot bool `json:"secureBoot,omitempty"`
+
+	// Loader is the path of the OVMF code on the libvirt host.
+	// +optional
+	// Default is /usr/share/OVMF/OVMF_CODE.fd, or
+	// /usr/share/OVMF/OVMF_CODE.secboot.fd with secure boot.
+	Loader string `json:"loader,omitempty"`
+
+	// NVRAMTemplate is the path of the OVMF variable store on the libvirt
+	// host, which is copied for each domain.
+	// +optional
+	// Default is /usr/share/OVMF/OVMF_VARS.fd, or
+	// /usr/share/OVMF/OVMF_VARS.secboot.fd with secure boot.
+	NVRAMTemplate string `json:"nvramTemplate,omitempty"`
+}
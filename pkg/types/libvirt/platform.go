@@ -20,4 +20,9 @@ type Platform struct {
 	// Network
 	// +optional
 	Network *Network `json:"network,omitempty"`
+
+	// Firmware selects the firmware the bootstrap and control plane
+	// domains boot with, e.g. UEFI to reproduce UEFI-only bare metal.
+	// +optional
+	Firmware *Firmware `json:"firmware,omitempty"`
 }
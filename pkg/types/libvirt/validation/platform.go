@@ -1,6 +1,8 @@
 package validation
 
 import (
+	"path"
+
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
@@ -23,5 +25,28 @@ func ValidatePlatform(p *libvirt.Platform, fldPath *field.Path) field.ErrorList
 	} else {
 		allErrs = append(allErrs, field.Required(fldPath.Child("network"), "network is required"))
 	}
+	if p.Firmware != nil {
+		allErrs = append(allErrs, validateFirmware(p.Firmware, fldPath.Child("firmware"))...)
+	}
+	return allErrs
+}
+
+func validateFirmware(f *libvirt.Firmware, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch f.Type {
+	case libvirt.FirmwareBIOS:
+		if f.SecureBoot {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("secureBoot"), f.SecureBoot, "secure boot requires UEFI firmware"))
+		}
+	case libvirt.FirmwareUEFI:
+		if !path.IsAbs(f.Loader) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("loader"), f.Loader, "must be an absolute path"))
+		}
+		if !path.IsAbs(f.NVRAMTemplate) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nvramTemplate"), f.NVRAMTemplate, "must be an absolute path"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), f.Type, []string{string(libvirt.FirmwareBIOS), string(libvirt.FirmwareUEFI)}))
+	}
 	return allErrs
 }
@@ -56,6 +56,29 @@ func TestValidatePlatform(t *testing.T) {
 			}(),
 			valid: false,
 		},
+		{
+			name: "valid UEFI firmware",
+			platform: func() *libvirt.Platform {
+				p := validPlatform()
+				p.Firmware = &libvirt.Firmware{
+					Type:          libvirt.FirmwareUEFI,
+					SecureBoot:    true,
+					Loader:        "/usr/share/OVMF/OVMF_CODE.secboot.fd",
+					NVRAMTemplate: "/usr/share/OVMF/OVMF_VARS.secboot.fd",
+				}
+				return p
+			}(),
+			valid: true,
+		},
+		{
+			name: "secure boot with BIOS",
+			platform: func() *libvirt.Platform {
+				p := validPlatform()
+				p.Firmware = &libvirt.Firmware{Type: libvirt.FirmwareBIOS, SecureBoot: true}
+				return p
+			}(),
+			valid: false,
+		},
 		{
 			name: "valid machine pool",
 			platform: func() *libvirt.Platform {
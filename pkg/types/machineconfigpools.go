@@ -0,0 +1,36 @@
+package types
+
+// MachineConfigPool is an additional pool of the machine-config operator,
+// e.g. "infra", created at install time.  Its nodes get the MachineConfigs
+// of the worker pool as well as those of its own role.
+type MachineConfigPool struct {
+	// Name is the name of the pool, which is also the role its
+	// MachineConfigs are labeled with.  It must be a unique DNS label and
+	// cannot be "master" or "worker".
+	Name string `json:"name"`
+
+	// NodeSelector selects the nodes in the pool.
+	// Default is the node-role.kubernetes.io/<name> label, which the
+	// nodes of the compute pool with the same name get.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Files are written to the nodes in the pool by a MachineConfig
+	// generated for it.
+	// +optional
+	Files []MachineConfigFile `json:"files,omitempty"`
+}
+
+// MachineConfigFile is a file written by a MachineConfig.
+type MachineConfigFile struct {
+	// Path is the absolute path of the file.
+	Path string `json:"path"`
+
+	// Contents are the contents of the file.
+	Contents string `json:"contents"`
+
+	// Mode is the permission mode of the file.
+	// Default is 0644.
+	// +optional
+	Mode *int `json:"mode,omitempty"`
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"github.com/metalkube/kni-installer/pkg/ipnet"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
@@ -11,16 +12,169 @@ import (
 type MachinePool struct {
 	// Name is the name of the machine pool.
 	// For the control plane machine pool, the name will always be "master".
-	// For the compute machine pools, the only valid name is "worker".
+	// For the compute machine pools, the name must be a unique DNS label,
+	// e.g. "worker" or "gpu".
 	Name string `json:"name"`
 
 	// Replicas is the count of machines for this machine pool.
 	Replicas *int64 `json:"replicas,omitempty"`
 
+	// Labels are additional labels applied to the nodes created for this
+	// machine pool. Compute pools other than "worker" additionally get a
+	// node-role.kubernetes.io/<name> label.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Autoscaling, when set, generates a MachineAutoscaler for each of the
+	// pool's MachineSets along with a cluster-wide ClusterAutoscaler.
+	// It is only supported for compute pools.
+	// +optional
+	Autoscaling *MachinePoolAutoscaling `json:"autoscaling,omitempty"`
+
+	// Rollout, when set, brings the pool's machines up in waves during the
+	// install rather than all at once, to limit the load on the BMCs, PXE
+	// and image servers of large installs.  It is only supported for
+	// compute pools.
+	// +optional
+	Rollout *MachinePoolRollout `json:"rollout,omitempty"`
+
+	// MachineCIDR is the IP address space of the pool's machines, for pools
+	// on a routed subnet of their own, e.g. a different leaf of a
+	// spine-leaf network than the control plane.  It is only supported on
+	// bare metal.
+	// Default is the cluster's networking.machineCIDR.
+	// +optional
+	MachineCIDR *ipnet.IPNet `json:"machineCIDR,omitempty"`
+
+	// DiskLayout, when set, adds partitions to the pool's install disk for
+	// separate filesystems, e.g. /var or /var/lib/containers.  The compute
+	// pools all boot from the same Ignition config, so they must share
+	// one layout.
+	// +optional
+	DiskLayout *DiskLayout `json:"diskLayout,omitempty"`
+
+	// Sysctls are kernel parameters set on the pool's nodes, e.g.
+	// net.ipv4.conf.all.rp_filter or net.netfilter.nf_conntrack_max.
+	// Compute pools other than "worker" need a MachineConfigPool of the
+	// same name in machineConfigPools.
+	// +optional
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+
+	// SELinuxBooleans are SELinux booleans set persistently on the pool's
+	// nodes, e.g. container_manage_cgroup.
+	// Compute pools other than "worker" need a MachineConfigPool of the
+	// same name in machineConfigPools.
+	// +optional
+	SELinuxBooleans map[string]bool `json:"selinuxBooleans,omitempty"`
+
+	// Architecture is the CPU architecture of the pool's machines.  Pools
+	// other than amd64 need a multi-arch release image, and are only
+	// supported on bare metal.
+	// +optional
+	// Default is amd64.
+	Architecture Architecture `json:"architecture,omitempty"`
+
+	// OperatingSystem is the operating system of the pool's hosts.  The
+	// installer does not provision RHEL hosts, which join the cluster
+	// after the install, so RHEL is only supported for compute pools and
+	// needs the install config's entitlements.
+	// +optional
+	// Default is rhcos.
+	OperatingSystem OperatingSystem `json:"operatingSystem,omitempty"`
+
 	// Platform is configuration for machine pool specific to the platfrom.
 	Platform MachinePoolPlatform `json:"platform"`
 }
 
+// Architecture is the CPU architecture of a machine pool, as named by Go and
+// by the architecture of container images.
+type Architecture string
+
+const (
+	// ArchitectureAMD64 is x86-64.
+	ArchitectureAMD64 Architecture = "amd64"
+
+	// ArchitectureARM64 is 64-bit ARM.
+	ArchitectureARM64 Architecture = "arm64"
+)
+
+// ArchitectureLabel is the node label naming the CPU architecture of a
+// node.  The kubelet sets it too, but setting it on the machines lets it be
+// known before the nodes exist, e.g. when the autoscaler scales from zero.
+const ArchitectureLabel = "beta.kubernetes.io/arch"
+
+// OperatingSystem is the operating system of a machine pool's hosts.
+type OperatingSystem string
+
+const (
+	// OperatingSystemRHCOS is Red Hat Enterprise Linux CoreOS.
+	OperatingSystemRHCOS OperatingSystem = "rhcos"
+
+	// OperatingSystemRHEL is Red Hat Enterprise Linux.
+	OperatingSystemRHEL OperatingSystem = "rhel"
+)
+
+// MachineNetwork returns the IP address space of the pool's machines:
+// the pool's own machine CIDR if it has one, otherwise the cluster's.
+func (p *MachinePool) MachineNetwork(n *Networking) *ipnet.IPNet {
+	if p != nil && p.MachineCIDR != nil {
+		return p.MachineCIDR
+	}
+	if n == nil {
+		return nil
+	}
+	return n.MachineCIDR
+}
+
+// MachinePoolAutoscaling is the range within which the autoscaler may scale
+// a machine pool. The bounds are spread across the pool's MachineSets in the
+// same way as the replicas.
+type MachinePoolAutoscaling struct {
+	// MinReplicas is the minimum number of machines in the pool.
+	MinReplicas int64 `json:"minReplicas"`
+
+	// MaxReplicas is the maximum number of machines in the pool.
+	MaxReplicas int64 `json:"maxReplicas"`
+}
+
+// MachinePoolRollout is the pace at which a machine pool is brought up.
+type MachinePoolRollout struct {
+	// WaveSize is the number of machines brought up at a time.  The
+	// next wave starts once the machines of the previous one are ready.
+	WaveSize int64 `json:"waveSize"`
+}
+
+// DiskLayout is the partitioning of a machine's install disk.
+type DiskLayout struct {
+	// Device is the install disk.
+	// Default is /dev/sda.
+	// +optional
+	Device string `json:"device,omitempty"`
+
+	// Partitions are appended to the install disk, after the partitions
+	// of the operating system, in order.
+	Partitions []DiskPartition `json:"partitions"`
+}
+
+// DiskPartition is a partition holding a filesystem mounted on the machine.
+type DiskPartition struct {
+	// Label is the label of the partition.
+	Label string `json:"label"`
+
+	// MountPath is where the filesystem is mounted, e.g. /var.
+	MountPath string `json:"mountPath"`
+
+	// SizeMiB is the size of the partition.  Only the last partition may
+	// leave it unset, to fill the rest of the disk.
+	// +optional
+	SizeMiB int `json:"sizeMiB,omitempty"`
+
+	// Format is the type of the filesystem, xfs or ext4.
+	// Default is xfs.
+	// +optional
+	Format string `json:"format,omitempty"`
+}
+
 // MachinePoolPlatform is the platform-specific configuration for a machine
 // pool. Only one of the platforms should be set.
 type MachinePoolPlatform struct {
@@ -34,7 +188,7 @@ type MachinePoolPlatform struct {
 	OpenStack *openstack.MachinePool `json:"openstack,omitempty"`
 
 	// BareMetal is the configuration used when installing on bare metal.
-	BareMetal *baremetal.MachinePool `json:"openstack,omitempty"`
+	BareMetal *baremetal.MachinePool `json:"baremetal,omitempty"`
 }
 
 // Name returns a string representation of the platform (e.g. "aws" if
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/credentials"
+)
+
+type pullSecret struct {
+	Auths map[string]interface{} `json:"auths"`
+}
+
+// MergePullSecrets returns a pull secret with the registry credentials of
+// both base and additional.  It is an error for them to have different
+// credentials for the same registry.
+func MergePullSecrets(base, additional string) (string, error) {
+	var merged, extra pullSecret
+	if err := json.Unmarshal([]byte(base), &merged); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal([]byte(additional), &extra); err != nil {
+		return "", err
+	}
+	if merged.Auths == nil {
+		merged.Auths = map[string]interface{}{}
+	}
+
+	for registry, auth := range extra.Auths {
+		if existing, ok := merged.Auths[registry]; ok {
+			if !reflect.DeepEqual(existing, auth) {
+				return "", errors.Errorf("conflicting credentials for registry %q", registry)
+			}
+			continue
+		}
+		merged.Auths[registry] = auth
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// MergedPullSecret returns PullSecret merged with AdditionalPullSecrets,
+// after reading any of them which reference a credentials provider.
+// Without additional pull secrets, it is PullSecret unchanged.
+func (c *InstallConfig) MergedPullSecret() (string, error) {
+	merged, err := credentials.Resolve(c.PullSecret)
+	if err != nil {
+		return "", errors.Wrap(err, "pullSecret")
+	}
+	for i, secret := range c.AdditionalPullSecrets {
+		secret, err := credentials.Resolve(secret)
+		if err == nil {
+			merged, err = MergePullSecrets(merged, secret)
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "additionalPullSecrets[%d]", i)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,97 @@
+package types
+
+// TLS configures the certificates the installer generates.
+type TLS struct {
+	// RootCA, when set, is a CA of the user's PKI which signs every
+	// signer the installer generates, so all of the cluster's certificates
+	// chain to it.
+	// +optional
+	// Default is self-signed signers.
+	RootCA *RootCA `json:"rootCA,omitempty"`
+
+	// KeyAlgorithm is the algorithm of the keys of the installer's
+	// certificates.  The service account signing key is always RSA.
+	// +optional
+	// Default is RSA.
+	KeyAlgorithm KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+
+	// KeySize is the size in bits of the RSA keys of the installer's
+	// certificates, and of the service account signing key: 2048, 3072 or
+	// 4096.
+	// +optional
+	// Default is 2048.
+	KeySize int `json:"keySize,omitempty"`
+
+	// LoadBalancer, when set, configures the trust of an external load
+	// balancer in front of the API servers which terminates TLS and
+	// re-encrypts to them.
+	// +optional
+	LoadBalancer *LoadBalancerTLS `json:"loadBalancer,omitempty"`
+
+	// AdditionalSANs are extra DNS names and IP addresses the API servers
+	// are reached on, e.g. of external load balancers, corporate aliases
+	// or floating VIPs, added to the serving certificates of the load
+	// balancer, localhost and service network endpoints.
+	// +optional
+	AdditionalSANs []string `json:"additionalSANs,omitempty"`
+
+	// WorkloadIssuer, when set, generates a workload issuing CA under the
+	// cluster's root CA, and a cert-manager ClusterIssuer of it, so
+	// applications' certificates chain to the cluster's trust.
+	// +optional
+	WorkloadIssuer *WorkloadIssuer `json:"workloadIssuer,omitempty"`
+}
+
+// RootCA is a certificate authority of the user's PKI.
+type RootCA struct {
+	// Certificate is the PEM certificate of the CA.
+	Certificate string `json:"certificate"`
+
+	// Key is the PEM RSA or ECDSA private key of the CA.
+	Key string `json:"key"`
+}
+
+// KeyAlgorithm is the algorithm of a private key.
+type KeyAlgorithm string
+
+const (
+	// KeyAlgorithmRSA is an RSA key.
+	KeyAlgorithmRSA KeyAlgorithm = "RSA"
+
+	// KeyAlgorithmECDSAP256 is an ECDSA key on the NIST P-256 curve.
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA-P256"
+
+	// KeyAlgorithmECDSAP384 is an ECDSA key on the NIST P-384 curve.
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ECDSA-P384"
+)
+
+// LoadBalancerTLS configures the trust of a load balancer which
+// re-encrypts to the API servers.
+type LoadBalancerTLS struct {
+	// ClientCA is the PEM bundle of the CAs which clients verify the load
+	// balancer with.  It is appended to the kube-apiserver-lb-ca-bundle,
+	// so the kubeconfigs trust the load balancer.
+	// +optional
+	ClientCA string `json:"clientCA,omitempty"`
+
+	// FrontendName, when set, is the load balancer's front-side DNS name,
+	// for which a serving certificate is issued from the
+	// kube-apiserver-lb-signer.
+	// +optional
+	FrontendName string `json:"frontendName,omitempty"`
+}
+
+// WorkloadIssuer is a cert-manager ClusterIssuer of a CA signed by the
+// cluster's root CA.
+type WorkloadIssuer struct {
+	// Name is the name of the ClusterIssuer and of the Secret of its CA.
+	// +optional
+	// Default is workload-issuing-ca.
+	Name string `json:"name,omitempty"`
+
+	// Namespace is cert-manager's cluster resource namespace, which the
+	// Secret of the CA is created in.
+	// +optional
+	// Default is cert-manager.
+	Namespace string `json:"namespace,omitempty"`
+}
@@ -0,0 +1,63 @@
+package types
+
+// TLSSecurityProfile selects the TLS ciphers and minimum version of the
+// cluster's TLS servers.  It follows the tlsSecurityProfile of OpenShift's
+// APIServer and IngressController resources.
+type TLSSecurityProfile struct {
+	// Type is the profile: one of the Mozilla server side TLS
+	// configurations, Old, Intermediate or Modern, or Custom.
+	Type TLSProfileType `json:"type"`
+
+	// Custom is the ciphers and minimum version of a Custom profile.
+	// +optional
+	Custom *CustomTLSProfile `json:"custom,omitempty"`
+}
+
+// TLSProfileType is a TLS security profile.
+type TLSProfileType string
+
+const (
+	// TLSProfileOld is the Mozilla "old" configuration, for legacy
+	// clients.
+	TLSProfileOld TLSProfileType = "Old"
+
+	// TLSProfileIntermediate is the Mozilla "intermediate"
+	// configuration, which is the cluster's default.
+	TLSProfileIntermediate TLSProfileType = "Intermediate"
+
+	// TLSProfileModern is the Mozilla "modern" configuration, which
+	// only allows TLS 1.3.
+	TLSProfileModern TLSProfileType = "Modern"
+
+	// TLSProfileCustom is a profile of the user's ciphers and minimum
+	// version.
+	TLSProfileCustom TLSProfileType = "Custom"
+)
+
+// CustomTLSProfile is the ciphers and minimum version of a Custom TLS
+// security profile.
+type CustomTLSProfile struct {
+	// Ciphers are the allowed ciphers, by their OpenSSL names, e.g.
+	// ECDHE-RSA-AES128-GCM-SHA256.
+	Ciphers []string `json:"ciphers"`
+
+	// MinTLSVersion is the minimum TLS version, e.g. VersionTLS12.
+	MinTLSVersion TLSProtocolVersion `json:"minTLSVersion"`
+}
+
+// TLSProtocolVersion is a version of the TLS protocol.
+type TLSProtocolVersion string
+
+const (
+	// VersionTLS10 is TLS 1.0.
+	VersionTLS10 TLSProtocolVersion = "VersionTLS10"
+
+	// VersionTLS11 is TLS 1.1.
+	VersionTLS11 TLSProtocolVersion = "VersionTLS11"
+
+	// VersionTLS12 is TLS 1.2.
+	VersionTLS12 TLSProtocolVersion = "VersionTLS12"
+
+	// VersionTLS13 is TLS 1.3.
+	VersionTLS13 TLSProtocolVersion = "VersionTLS13"
+)
@@ -1,23 +1,38 @@
 package validation
 
 import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
+	"path"
+	"reflect"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
-	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/apimachinery/pkg/api/resource"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
+	"github.com/metalkube/kni-installer/pkg/credentials"
 	"github.com/metalkube/kni-installer/pkg/types"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	awsvalidation "github.com/metalkube/kni-installer/pkg/types/aws/validation"
 	"github.com/metalkube/kni-installer/pkg/types/baremetal"
 	baremetalvalidation "github.com/metalkube/kni-installer/pkg/types/baremetal/validation"
+	"github.com/metalkube/kni-installer/pkg/types/fake"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
 	libvirtvalidation "github.com/metalkube/kni-installer/pkg/types/libvirt/validation"
+	"github.com/metalkube/kni-installer/pkg/types/none"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
 	openstackvalidation "github.com/metalkube/kni-installer/pkg/types/openstack/validation"
 	"github.com/metalkube/kni-installer/pkg/validate"
+	"github.com/metalkube/kni-installer/pkg/warnings"
 )
 
 const (
@@ -68,13 +83,628 @@ func ValidateInstallConfig(c *types.InstallConfig, openStackValidValuesFetcher o
 	}
 	if c.ControlPlane != nil {
 		allErrs = append(allErrs, validateControlPlane(c.ControlPlane, field.NewPath("controlPlane"), c.Platform.Name())...)
+		if c.Platform.BareMetal != nil && c.ControlPlane.Replicas != nil {
+			mpool := baremetal.MachinePool{}
+			mpool.Set(c.Platform.BareMetal.DefaultMachinePlatform)
+			mpool.Set(c.ControlPlane.Platform.BareMetal)
+			allErrs = append(allErrs, baremetalvalidation.ValidateControlPlanePlacement(&mpool, *c.ControlPlane.Replicas, field.NewPath("controlPlane", "platform", "baremetal"))...)
+		}
 	} else {
 		allErrs = append(allErrs, field.Required(field.NewPath("controlPlane"), "controlPlane is required"))
 	}
 	allErrs = append(allErrs, validateCompute(c.Compute, field.NewPath("compute"), c.Platform.Name())...)
+	if c.Networking != nil {
+		allErrs = append(allErrs, validateClusterNetworkCapacity(c, field.NewPath("networking", "clusterNetwork"))...)
+	}
+	allErrs = append(allErrs, validateMachineConfigPools(c, field.NewPath("machineConfigPools"))...)
+	allErrs = append(allErrs, validateProfile(c, field.NewPath("profile"))...)
 	allErrs = append(allErrs, validatePlatform(&c.Platform, field.NewPath("platform"), openStackValidValuesFetcher)...)
-	if err := validate.ImagePullSecret(c.PullSecret); err != nil {
+	if c.Networking != nil {
+		allErrs = append(allErrs, validatePoolMachineCIDRs(c)...)
+	}
+	if c.Platform.BareMetal != nil {
+		var machineCIDR *net.IPNet
+		if c.Networking != nil && c.Networking.MachineCIDR != nil {
+			machineCIDR = &c.Networking.MachineCIDR.IPNet
+		}
+		poolCIDRs := map[string]*net.IPNet{}
+		if c.ControlPlane != nil && c.ControlPlane.MachineCIDR != nil {
+			poolCIDRs[c.ControlPlane.Name] = &c.ControlPlane.MachineCIDR.IPNet
+		}
+		for _, p := range c.Compute {
+			if p.MachineCIDR != nil {
+				poolCIDRs[p.Name] = &p.MachineCIDR.IPNet
+			}
+		}
+		allErrs = append(allErrs, baremetalvalidation.ValidateNetwork(c.Platform.BareMetal, machineCIDR, poolCIDRs, field.NewPath("platform", "baremetal"))...)
+		allErrs = append(allErrs, validateHostPools(c, field.NewPath("platform", "baremetal", "hosts"))...)
+		allErrs = append(allErrs, validateUnprovisionedHosts(c, field.NewPath("compute"))...)
+		allErrs = append(allErrs, validateVirtualMasterHosts(c, field.NewPath("platform", "baremetal", "hosts"))...)
+	}
+	if pullSecret, err := validatePullSecret(c.PullSecret); err != nil {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("pullSecret"), c.PullSecret, err.Error()))
+	} else {
+		allErrs = append(allErrs, validateAdditionalPullSecrets(pullSecret, c.AdditionalPullSecrets, field.NewPath("additionalPullSecrets"))...)
+	}
+	if c.InfraID != nil {
+		allErrs = append(allErrs, validateInfraID(c.InfraID, field.NewPath("infraID"))...)
+	}
+	if c.AdminKubeconfig != nil {
+		allErrs = append(allErrs, validateAdminKubeconfig(c.AdminKubeconfig, field.NewPath("adminKubeconfig"))...)
+	}
+	if c.SecretsEncryption != nil {
+		allErrs = append(allErrs, validateSecretsEncryption(c.SecretsEncryption, field.NewPath("secretsEncryption"))...)
+	}
+	if c.CredentialsPolicy != nil {
+		allErrs = append(allErrs, validateCredentialsPolicy(c.CredentialsPolicy, field.NewPath("credentialsPolicy"))...)
+	}
+	if c.EtcdBackup != nil {
+		allErrs = append(allErrs, validateEtcdBackup(c.EtcdBackup, field.NewPath("etcdBackup"))...)
+	}
+	if c.TLSSecurityProfile != nil {
+		allErrs = append(allErrs, validateTLSSecurityProfile(c.TLSSecurityProfile, field.NewPath("tlsSecurityProfile"))...)
+	}
+	if c.TLS != nil {
+		allErrs = append(allErrs, validateTLS(c.TLS, field.NewPath("tls"))...)
+	}
+	if c.DNS != nil {
+		allErrs = append(allErrs, validateDNS(c.DNS, field.NewPath("dns"), c.Platform.Name())...)
+	}
+	if c.Firewall != nil && c.Firewall.NFTables && c.Networking != nil && c.Networking.NetworkType == types.NetworkTypeCustom {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("firewall", "nftables"), fmt.Sprintf("the flows of networkType %s are unknown, so the nodes' firewall would drop its overlay traffic", types.NetworkTypeCustom)))
+	}
+	if c.Capabilities != nil {
+		allErrs = append(allErrs, validateCapabilities(c.Capabilities, field.NewPath("capabilities"))...)
+	}
+	if c.Entitlements != nil {
+		allErrs = append(allErrs, validateEntitlements(c.Entitlements, field.NewPath("entitlements"))...)
+	} else {
+		for _, p := range c.Compute {
+			if p.OperatingSystem == types.OperatingSystemRHEL {
+				allErrs = append(allErrs, field.Required(field.NewPath("entitlements"), fmt.Sprintf("compute pool %s runs %s, whose hosts must be registered", p.Name, p.OperatingSystem)))
+				break
+			}
+		}
+	}
+	return allErrs
+}
+
+// InstallConfigWarnings returns the settings of a valid install config which
+// are allowed, but risky, for the caller to report.
+func InstallConfigWarnings(c *types.InstallConfig) []warnings.Warning {
+	var warns []warnings.Warning
+	if c.ControlPlane != nil && c.ControlPlane.Replicas != nil {
+		warns = append(warns, etcdQuorumWarnings(*c.ControlPlane.Replicas)...)
+	}
+	warns = append(warns, machineConfigPoolWarnings(c)...)
+	if c.Platform.BareMetal != nil {
+		for _, msg := range baremetalvalidation.HardwareAsymmetry(c.Platform.BareMetal.Hosts) {
+			warns = append(warns, warnings.Warning{
+				Category: warnings.Weak,
+				Message:  msg + " etcd runs at the pace of its slowest member, so mixed control plane hardware causes etcd latency.",
+			})
+		}
+	}
+	return warns
+}
+
+// validatePoolMachineCIDRs checks that the machine CIDRs of machine pools on
+// their own routed subnets overlap neither the service and cluster networks
+// nor each other, although pools may share a subnet.  They may be carved
+// out of the cluster's machine CIDR, e.g. one leaf subnet each from the
+// address space of a spine-leaf network.
+func validatePoolMachineCIDRs(c *types.InstallConfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+	type poolCIDR struct {
+		name string
+		cidr *net.IPNet
+		path *field.Path
+	}
+	var pools []poolCIDR
+	if c.ControlPlane != nil && c.ControlPlane.MachineCIDR != nil {
+		pools = append(pools, poolCIDR{name: c.ControlPlane.Name, cidr: &c.ControlPlane.MachineCIDR.IPNet, path: field.NewPath("controlPlane", "machineCIDR")})
+	}
+	for i, p := range c.Compute {
+		if p.MachineCIDR != nil {
+			pools = append(pools, poolCIDR{name: p.Name, cidr: &p.MachineCIDR.IPNet, path: field.NewPath("compute").Index(i).Child("machineCIDR")})
+		}
+	}
+
+	for i, p := range pools {
+		for j, sn := range c.Networking.ServiceNetwork {
+			if validate.DoCIDRsOverlap(p.cidr, &sn.IPNet) {
+				allErrs = append(allErrs, field.Invalid(p.path, p.cidr.String(), fmt.Sprintf("machine CIDR must not overlap with service network %d", j)))
+			}
+		}
+		for j, cn := range c.Networking.ClusterNetwork {
+			if validate.DoCIDRsOverlap(p.cidr, &cn.CIDR.IPNet) {
+				allErrs = append(allErrs, field.Invalid(p.path, p.cidr.String(), fmt.Sprintf("machine CIDR must not overlap with cluster network %d", j)))
+			}
+		}
+		if m := c.Networking.MachineCIDR; m != nil && validate.DoCIDRsOverlap(p.cidr, &m.IPNet) && !contains(&m.IPNet, p.cidr) {
+			allErrs = append(allErrs, field.Invalid(p.path, p.cidr.String(), fmt.Sprintf("machine CIDR must be within or separate from networking.machineCIDR %s", m)))
+		}
+		for _, o := range pools[:i] {
+			if validate.DoCIDRsOverlap(p.cidr, o.cidr) && p.cidr.String() != o.cidr.String() {
+				allErrs = append(allErrs, field.Invalid(p.path, p.cidr.String(), fmt.Sprintf("machine CIDR must not overlap with the machine CIDR %s of pool %s unless they are the same", o.cidr, o.name)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// contains returns true if inner is entirely within outer.
+func contains(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outer.Contains(inner.IP)
+}
+
+// validatePullSecret reads the pull secret, if it references a
+// credentials provider, and checks that it is valid.
+func validatePullSecret(value string) (string, error) {
+	secret, err := credentials.Resolve(value)
+	if err != nil {
+		return "", err
+	}
+	return secret, validate.ImagePullSecret(secret)
+}
+
+// validateAdditionalPullSecrets checks that each additional pull secret is
+// valid, and that none has different credentials for a registry than the
+// pull secrets before it.
+func validateAdditionalPullSecrets(pullSecret string, additional []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	merged := pullSecret
+	for i, value := range additional {
+		secret, err := validatePullSecret(value)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), value, err.Error()))
+			continue
+		}
+		next, err := types.MergePullSecrets(merged, secret)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), value, err.Error()))
+			continue
+		}
+		merged = next
+	}
+	return allErrs
+}
+
+// execAPIVersions are the exec credential plugin API versions supported by
+// the vendored client-go.
+var execAPIVersions = map[string]bool{
+	"client.authentication.k8s.io/v1alpha1": true,
+	"client.authentication.k8s.io/v1beta1":  true,
+}
+
+func validateAdminKubeconfig(k *types.AdminKubeconfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if k.Exec == nil {
+		return allErrs
+	}
+	execPath := fldPath.Child("exec")
+	if k.Exec.Command == "" {
+		allErrs = append(allErrs, field.Required(execPath.Child("command"), "the credential plugin command is required"))
+	}
+	if !execAPIVersions[k.Exec.APIVersion] {
+		allErrs = append(allErrs, field.NotSupported(execPath.Child("apiVersion"), k.Exec.APIVersion, []string{"client.authentication.k8s.io/v1alpha1", "client.authentication.k8s.io/v1beta1"}))
+	}
+	for i, env := range k.Exec.Env {
+		if env.Name == "" {
+			allErrs = append(allErrs, field.Required(execPath.Child("env").Index(i).Child("name"), "environment variable name is required"))
+		}
+	}
+	return allErrs
+}
+
+// ageRecipientRegexp matches age X25519 public keys.
+var ageRecipientRegexp = regexp.MustCompile(`^age1[02-9ac-hj-np-z]{58}$`)
+
+func validateSecretsEncryption(e *types.SecretsEncryption, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch e.Tool {
+	case types.EncryptionToolSOPS, types.EncryptionToolAge:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("tool"), e.Tool, []string{string(types.EncryptionToolAge), string(types.EncryptionToolSOPS)}))
+	}
+	if len(e.Recipients) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("recipients"), "at least one recipient is required"))
+	}
+	for i, r := range e.Recipients {
+		if !ageRecipientRegexp.MatchString(r) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("recipients").Index(i), r, "must be an age public key (age1...)"))
+		}
+	}
+	return allErrs
+}
+
+// maxPasswordLength is the longest password bcrypt hashes in full.
+const maxPasswordLength = 72
+
+func validateCredentialsPolicy(p *types.CredentialsPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if p.Length < 8 || p.Length > maxPasswordLength {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("length"), p.Length, fmt.Sprintf("must be between 8 and %d, the longest password bcrypt hashes in full", maxPasswordLength)))
+	} else if p.Length < len(p.CharacterClasses) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("length"), p.Length, "must be at least the number of character classes"))
+	}
+	validClasses := []string{
+		string(types.CharacterClassDigit),
+		string(types.CharacterClassLower),
+		string(types.CharacterClassSymbol),
+		string(types.CharacterClassUpper),
+	}
+	seen := map[types.CharacterClass]bool{}
+	for i, class := range p.CharacterClasses {
+		switch class {
+		case types.CharacterClassLower, types.CharacterClassUpper, types.CharacterClassDigit, types.CharacterClassSymbol:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("characterClasses").Index(i), class, validClasses))
+		}
+		if seen[class] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("characterClasses").Index(i), class))
+		}
+		seen[class] = true
+	}
+	if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("bcryptCost"), p.BcryptCost, fmt.Sprintf("must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)))
+	}
+	return allErrs
+}
+
+func validateEntitlements(e *types.Entitlements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if e.Certificate == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("certificate"), "the entitlement certificate is required"))
+	}
+	if e.Key == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("key"), "the entitlement key is required"))
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	if _, err := tls.X509KeyPair([]byte(e.Certificate), []byte(e.Key)); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, "", fmt.Sprintf("the certificate and key must be a PEM key pair: %v", err)))
+	}
+	return allErrs
+}
+
+// cronFieldRegexp matches a field of a cron schedule, e.g. "*", "*/6",
+// "1-5" or "0,30".
+var cronFieldRegexp = regexp.MustCompile(`^(\*|[0-9]+(-[0-9]+)?)(/[0-9]+)?(,(\*|[0-9]+(-[0-9]+)?)(/[0-9]+)?)*$`)
+
+func validateEtcdBackup(b *types.EtcdBackup, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if fields := strings.Fields(b.Schedule); len(fields) != 5 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), b.Schedule, "must be a cron schedule of five fields, e.g. \"0 */6 * * *\""))
+	} else {
+		for _, f := range fields {
+			if !cronFieldRegexp.MatchString(f) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), b.Schedule, fmt.Sprintf("invalid field %q", f)))
+				break
+			}
+		}
+	}
+	if b.Retention < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("retention"), b.Retention, "must keep at least one snapshot"))
+	}
+	if b.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), "the image to take snapshots with is required"))
+	}
+	switch {
+	case b.HostPath != "" && b.PersistentVolumeClaim != nil:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostPath"), "hostPath cannot be combined with persistentVolumeClaim"))
+	case b.HostPath == "" && b.PersistentVolumeClaim == nil:
+		allErrs = append(allErrs, field.Required(fldPath, "one of hostPath or persistentVolumeClaim is required"))
+	case b.HostPath != "" && !path.IsAbs(b.HostPath):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hostPath"), b.HostPath, "must be an absolute path"))
+	case b.PersistentVolumeClaim != nil:
+		claimPath := fldPath.Child("persistentVolumeClaim")
+		if b.PersistentVolumeClaim.StorageClassName != "" {
+			for _, msg := range k8svalidation.IsDNS1123Subdomain(b.PersistentVolumeClaim.StorageClassName) {
+				allErrs = append(allErrs, field.Invalid(claimPath.Child("storageClassName"), b.PersistentVolumeClaim.StorageClassName, msg))
+			}
+		}
+		if size, err := resource.ParseQuantity(b.PersistentVolumeClaim.Size); err != nil {
+			allErrs = append(allErrs, field.Invalid(claimPath.Child("size"), b.PersistentVolumeClaim.Size, err.Error()))
+		} else if size.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(claimPath.Child("size"), b.PersistentVolumeClaim.Size, "must be positive"))
+		}
+	}
+	return allErrs
+}
+
+// supportedTLSCiphers are the ciphers of the Old profile, which allows the
+// most, by their OpenSSL names.
+var supportedTLSCiphers = []string{
+	"TLS_AES_128_GCM_SHA256",
+	"TLS_AES_256_GCM_SHA384",
+	"TLS_CHACHA20_POLY1305_SHA256",
+	"ECDHE-ECDSA-AES128-GCM-SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256",
+	"ECDHE-ECDSA-AES256-GCM-SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384",
+	"ECDHE-ECDSA-CHACHA20-POLY1305",
+	"ECDHE-RSA-CHACHA20-POLY1305",
+	"DHE-RSA-AES128-GCM-SHA256",
+	"DHE-RSA-AES256-GCM-SHA384",
+	"DHE-RSA-CHACHA20-POLY1305",
+	"ECDHE-ECDSA-AES128-SHA256",
+	"ECDHE-RSA-AES128-SHA256",
+	"ECDHE-ECDSA-AES128-SHA",
+	"ECDHE-RSA-AES128-SHA",
+	"ECDHE-ECDSA-AES256-SHA384",
+	"ECDHE-RSA-AES256-SHA384",
+	"ECDHE-ECDSA-AES256-SHA",
+	"ECDHE-RSA-AES256-SHA",
+	"DHE-RSA-AES128-SHA256",
+	"DHE-RSA-AES256-SHA256",
+	"AES128-GCM-SHA256",
+	"AES256-GCM-SHA384",
+	"AES128-SHA256",
+	"AES256-SHA256",
+	"AES128-SHA",
+	"AES256-SHA",
+	"DES-CBC3-SHA",
+}
+
+// capabilities are the optional components which can be disabled.
+var capabilities = []string{
+	string(types.CapabilityConsole),
+	string(types.CapabilityMarketplace),
+	string(types.CapabilitySamples),
+}
+
+func validateCapabilities(c *types.Capabilities, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	seen := map[types.Capability]bool{}
+	for i, capability := range c.Disabled {
+		switch capability {
+		case types.CapabilityConsole, types.CapabilityMarketplace, types.CapabilitySamples:
+			if seen[capability] {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Child("disabled").Index(i), capability))
+			}
+			seen[capability] = true
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("disabled").Index(i), capability, capabilities))
+		}
+	}
+	return allErrs
+}
+
+func validateTLS(t *types.TLS, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if t.RootCA != nil {
+		allErrs = append(allErrs, validateRootCA(t.RootCA, fldPath.Child("rootCA"))...)
+	}
+	switch t.KeyAlgorithm {
+	case "", types.KeyAlgorithmRSA, types.KeyAlgorithmECDSAP256, types.KeyAlgorithmECDSAP384:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("keyAlgorithm"), t.KeyAlgorithm, []string{string(types.KeyAlgorithmRSA), string(types.KeyAlgorithmECDSAP256), string(types.KeyAlgorithmECDSAP384)}))
+	}
+	switch t.KeySize {
+	case 0, 2048, 3072, 4096:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("keySize"), t.KeySize, []string{"2048", "3072", "4096"}))
+	}
+	if t.KeySize != 0 && t.RootCA != nil {
+		if pair, err := tls.X509KeyPair([]byte(t.RootCA.Certificate), []byte(t.RootCA.Key)); err == nil {
+			// The signers are no stronger than the root CA which signs them.
+			if key, ok := pair.PrivateKey.(*rsa.PrivateKey); ok && key.N.BitLen() < t.KeySize {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("keySize"), t.KeySize, fmt.Sprintf("must not exceed the %d bits of the root CA's key", key.N.BitLen())))
+			}
+		}
+	}
+	if t.LoadBalancer != nil {
+		allErrs = append(allErrs, validateLoadBalancerTLS(t.LoadBalancer, fldPath.Child("loadBalancer"))...)
+	}
+	for i, san := range t.AdditionalSANs {
+		if net.ParseIP(san) != nil {
+			continue
+		}
+		if err := validate.DomainName(san, false); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("additionalSANs").Index(i), san, "must be a DNS name or an IP address"))
+		}
+	}
+	if t.WorkloadIssuer != nil {
+		for _, msg := range k8svalidation.IsDNS1123Subdomain(t.WorkloadIssuer.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("workloadIssuer", "name"), t.WorkloadIssuer.Name, msg))
+		}
+		for _, msg := range k8svalidation.IsDNS1123Label(t.WorkloadIssuer.Namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("workloadIssuer", "namespace"), t.WorkloadIssuer.Namespace, msg))
+		}
+	}
+	return allErrs
+}
+
+func validateLoadBalancerTLS(lb *types.LoadBalancerTLS, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if lb.ClientCA == "" && lb.FrontendName == "" {
+		allErrs = append(allErrs, field.Required(fldPath, "at least one of clientCA and frontendName is required"))
+	}
+	if lb.ClientCA != "" {
+		if pool := x509.NewCertPool(); !pool.AppendCertsFromPEM([]byte(lb.ClientCA)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("clientCA"), "", "must be a bundle of PEM certificates"))
+		}
+	}
+	if lb.FrontendName != "" {
+		if err := validate.DomainName(lb.FrontendName, false); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("frontendName"), lb.FrontendName, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+func validateRootCA(ca *types.RootCA, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ca.Certificate == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("certificate"), "the CA certificate is required"))
+	}
+	if ca.Key == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("key"), "the CA key is required"))
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	pair, err := tls.X509KeyPair([]byte(ca.Certificate), []byte(ca.Key))
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, "", fmt.Sprintf("the certificate and key must be a PEM key pair: %v", err)))
+	}
+	switch pair.PrivateKey.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), "", "must be an RSA or ECDSA key"))
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath.Child("certificate"), "", err.Error()))
+	}
+	if !cert.IsCA || (cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageCertSign == 0) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("certificate"), "", "must be a CA certificate which may sign certificates"))
+	}
+	if time.Now().After(cert.NotAfter) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("certificate"), "", fmt.Sprintf("the CA expired at %s", cert.NotAfter.Format(time.RFC3339))))
+	}
+	return allErrs
+}
+
+func validateTLSSecurityProfile(p *types.TLSSecurityProfile, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch p.Type {
+	case types.TLSProfileOld, types.TLSProfileIntermediate, types.TLSProfileModern:
+		if p.Custom != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("custom"), fmt.Sprintf("custom cannot be set for the %s profile", p.Type)))
+		}
+	case types.TLSProfileCustom:
+		if p.Custom == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("custom"), "the ciphers and minimum version of the Custom profile are required"))
+			break
+		}
+		customPath := fldPath.Child("custom")
+		if len(p.Custom.Ciphers) == 0 {
+			allErrs = append(allErrs, field.Required(customPath.Child("ciphers"), "at least one cipher is required"))
+		}
+		supported := map[string]bool{}
+		for _, cipher := range supportedTLSCiphers {
+			supported[cipher] = true
+		}
+		seen := map[string]bool{}
+		for i, cipher := range p.Custom.Ciphers {
+			if !supported[cipher] {
+				allErrs = append(allErrs, field.NotSupported(customPath.Child("ciphers").Index(i), cipher, supportedTLSCiphers))
+			} else if seen[cipher] {
+				allErrs = append(allErrs, field.Duplicate(customPath.Child("ciphers").Index(i), cipher))
+			}
+			seen[cipher] = true
+		}
+		switch p.Custom.MinTLSVersion {
+		case types.VersionTLS10, types.VersionTLS11, types.VersionTLS12, types.VersionTLS13:
+		default:
+			allErrs = append(allErrs, field.NotSupported(customPath.Child("minTLSVersion"), p.Custom.MinTLSVersion, []string{string(types.VersionTLS10), string(types.VersionTLS11), string(types.VersionTLS12), string(types.VersionTLS13)}))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), p.Type, []string{string(types.TLSProfileOld), string(types.TLSProfileIntermediate), string(types.TLSProfileModern), string(types.TLSProfileCustom)}))
+	}
+	return allErrs
+}
+
+// maxDNSUpstreams is the most resolvers resolv.conf takes.
+const maxDNSUpstreams = 3
+
+func validateDNS(d *types.DNS, fldPath *field.Path, platform string) field.ErrorList {
+	allErrs := validateDNSUpstreams(d.Upstreams, fldPath.Child("upstreams"))
+	if len(d.Forwarders) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("forwarders"), "forwarding zones to their own resolvers needs the DNS operator's forwardPlugin, which the supported releases lack; add the zones to the upstream resolvers instead"))
+	}
+	if d.Views != nil {
+		allErrs = append(allErrs, validateDNSViews(d.Views, fldPath.Child("views"), platform)...)
+	}
+	return allErrs
+}
+
+// validateDNSViews checks the split-horizon views of the API names, which
+// the installer cannot honour on platforms where it creates the DNS
+// records itself.
+func validateDNSViews(v *types.DNSViews, fldPath *field.Path, platform string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch platform {
+	case baremetal.Name, none.Name:
+	default:
+		return append(allErrs, field.Invalid(fldPath, platform, fmt.Sprintf("split-horizon DNS views are not supported on %q, where the installer creates the DNS records", platform)))
+	}
+	for _, view := range []struct {
+		name    string
+		targets []string
+	}{{"external", v.External}, {"internal", v.Internal}} {
+		vPath := fldPath.Child(view.name)
+		if len(view.targets) == 0 {
+			allErrs = append(allErrs, field.Required(vPath, "at least one target is required"))
+		}
+		for i, target := range view.targets {
+			switch {
+			case net.ParseIP(target) != nil:
+			case validate.DomainName(target, true) != nil:
+				allErrs = append(allErrs, field.Invalid(vPath.Index(i), target, "must be an IP address or a domain name"))
+			case len(view.targets) > 1:
+				allErrs = append(allErrs, field.Invalid(vPath.Index(i), target, "a domain name must be the only target, as a CNAME cannot have other records"))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateDNSUpstreams(upstreams []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(upstreams) > maxDNSUpstreams {
+		allErrs = append(allErrs, field.Invalid(fldPath, len(upstreams), fmt.Sprintf("at most %d upstream resolvers are supported", maxDNSUpstreams)))
+	}
+	for i, upstream := range upstreams {
+		_, port, err := types.SplitDNSUpstream(upstream)
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), upstream, err.Error()))
+		case port != types.DefaultDNSPort:
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), upstream, fmt.Sprintf("the nodes' resolvers must listen on port %d", types.DefaultDNSPort)))
+		}
+	}
+	return allErrs
+}
+
+func validateInfraID(i *types.InfraID, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if i.Value != "" {
+		if i.Prefix != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("prefix"), "prefix cannot be combined with value"))
+		}
+		if i.RandomLength != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("randomLength"), "randomLength cannot be combined with value"))
+		}
+		for _, msg := range k8svalidation.IsDNS1123Label(i.Value) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), i.Value, msg))
+		}
+		if len(i.Value) > types.InfraIDMaxLength {
+			allErrs = append(allErrs, field.TooLong(fldPath.Child("value"), i.Value, types.InfraIDMaxLength))
+		}
+		return allErrs
+	}
+	suffixLen := types.InfraIDDefaultRandomLength + 1
+	if i.RandomLength != nil {
+		suffixLen = 0
+		if *i.RandomLength < 0 || *i.RandomLength > int32(types.InfraIDMaxLength-2) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("randomLength"), *i.RandomLength, fmt.Sprintf("must be between 0 and %d", types.InfraIDMaxLength-2)))
+		} else if *i.RandomLength > 0 {
+			suffixLen = int(*i.RandomLength) + 1
+		}
+	}
+	if i.Prefix != "" {
+		for _, msg := range k8svalidation.IsDNS1123Label(i.Prefix) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("prefix"), i.Prefix, msg))
+		}
+		if len(i.Prefix)+suffixLen > types.InfraIDMaxLength {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("prefix"), i.Prefix, fmt.Sprintf("prefix and random suffix must not be longer than %d characters", types.InfraIDMaxLength)))
+		}
 	}
 	return allErrs
 }
@@ -149,12 +779,53 @@ func validateClusterNetwork(n *types.Networking, cn *types.ClusterNetworkEntry,
 	if cn.HostPrefix < 0 {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("hostPrefix"), cn.HostPrefix, "hostPrefix must be positive"))
 	}
-	if ones, _ := cn.CIDR.Mask.Size(); cn.HostPrefix < int32(ones) {
+	ones, bits := cn.CIDR.Mask.Size()
+	if cn.HostPrefix < int32(ones) {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("hostPrefix"), cn.HostPrefix, "cluster network host subnetwork prefix must not be larger size than CIDR "+cn.CIDR.String()))
 	}
+	if cn.HostPrefix > int32(bits) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hostPrefix"), cn.HostPrefix, fmt.Sprintf("hostPrefix must not be longer than the %d bits of the CIDR's addresses", bits)))
+	}
 	return allErrs
 }
 
+// validateClusterNetworkCapacity checks that the cluster networks have a
+// host subnetwork for every node the install config asks for, counting
+// autoscaled pools at their maximum size.
+func validateClusterNetworkCapacity(c *types.InstallConfig, fldPath *field.Path) field.ErrorList {
+	var nodes int64
+	if c.ControlPlane != nil && c.ControlPlane.Replicas != nil {
+		nodes += *c.ControlPlane.Replicas
+	}
+	for _, pool := range c.Compute {
+		switch {
+		case pool.Autoscaling != nil:
+			nodes += pool.Autoscaling.MaxReplicas
+		case pool.Replicas != nil:
+			nodes += *pool.Replicas
+		}
+	}
+
+	var subnets int64
+	for _, cn := range c.Networking.ClusterNetwork {
+		ones, bits := cn.CIDR.Mask.Size()
+		size := cn.HostPrefix - int32(ones)
+		if size < 0 || cn.HostPrefix > int32(bits) {
+			// Reported by validateClusterNetwork.
+			return nil
+		}
+		if size >= 32 {
+			return nil
+		}
+		subnets += 1 << uint(size)
+	}
+
+	if len(c.Networking.ClusterNetwork) > 0 && subnets < nodes {
+		return field.ErrorList{field.Invalid(fldPath, subnets, fmt.Sprintf("the cluster networks only have host subnetworks for %d nodes, but up to %d nodes are requested", subnets, nodes))}
+	}
+	return nil
+}
+
 func validateControlPlane(pool *types.MachinePool, fldPath *field.Path, platform string) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if pool.Name != masterPoolName {
@@ -163,30 +834,224 @@ func validateControlPlane(pool *types.MachinePool, fldPath *field.Path, platform
 	if pool.Replicas != nil && *pool.Replicas == 0 {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), pool.Replicas, "number of control plane replicas must be positive"))
 	}
+	if pool.Autoscaling != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("autoscaling"), "the control plane cannot be autoscaled"))
+	}
+	if pool.Rollout != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("rollout"), "the control plane cannot be rolled out in waves"))
+	}
+	allErrs = append(allErrs, validateBootstrapArchitecture(pool, fldPath)...)
 	allErrs = append(allErrs, ValidateMachinePool(pool, fldPath, platform)...)
 	return allErrs
 }
 
+// validateBootstrapArchitecture checks that a pool the bootstrap machine
+// hands over to is of the bootstrap machine's architecture, amd64, and runs
+// RHCOS, which the installer provisions.
+func validateBootstrapArchitecture(pool *types.MachinePool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if pool.Architecture != "" && pool.Architecture != types.ArchitectureAMD64 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("architecture"), pool.Architecture, fmt.Sprintf("must be %s, the architecture of the bootstrap machine", types.ArchitectureAMD64)))
+	}
+	if pool.OperatingSystem == types.OperatingSystemRHEL {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("operatingSystem"), pool.OperatingSystem, fmt.Sprintf("only compute pools may run %s", pool.OperatingSystem)))
+	}
+	return allErrs
+}
+
+// etcdQuorumWarnings returns warnings about control plane replica counts
+// which are valid, but which are a poor fit for the etcd members running on
+// the control plane.  etcd needs a majority of its members to keep quorum.
+func etcdQuorumWarnings(replicas int64) []warnings.Warning {
+	var message string
+	switch {
+	case replicas == 1:
+		message = "There is a single control plane replica. Losing it loses etcd, and with it the cluster."
+	case replicas%2 == 0:
+		message = fmt.Sprintf("There are %d control plane replicas. An even number of etcd members tolerates no more failures than %d members.", replicas, replicas-1)
+	case replicas > 5:
+		message = fmt.Sprintf("There are %d control plane replicas. More than 5 etcd members slow writes, which must reach a majority of the members.", replicas)
+	default:
+		return nil
+	}
+	return []warnings.Warning{{Category: warnings.Weak, Message: message}}
+}
+
+// validateHostPools checks that the pool of each worker host is a compute
+// pool, and that only worker hosts name one.
+func validateHostPools(c *types.InstallConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	pools := map[string]bool{}
+	for _, p := range c.Compute {
+		pools[p.Name] = true
+	}
+	for i, h := range c.Platform.BareMetal.Hosts {
+		if h.Pool == "" {
+			continue
+		}
+		poolPath := fldPath.Index(i).Child("pool")
+		if h.Role != baremetal.WorkerRole {
+			allErrs = append(allErrs, field.Forbidden(poolPath, fmt.Sprintf("only %s hosts belong to a compute pool", baremetal.WorkerRole)))
+		} else if !pools[h.Pool] {
+			allErrs = append(allErrs, field.Invalid(poolPath, h.Pool, "must be the name of a compute pool"))
+		}
+	}
+	return allErrs
+}
+
+// validateUnprovisionedHosts checks that the replicas of each compute pool
+// with unprovisioned hosts match its provisioned hosts, as the pool's
+// unprovisioned hosts are only added after the install.
+func validateUnprovisionedHosts(c *types.InstallConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, p := range c.Compute {
+		if p.Replicas == nil {
+			continue
+		}
+		provisioned, unprovisioned := 0, 0
+		for _, h := range baremetal.HostsWithRole(c.Platform.BareMetal.Hosts, baremetal.WorkerRole) {
+			switch {
+			case baremetal.HostPool(&h) != p.Name:
+			case h.Unprovisioned:
+				unprovisioned++
+			default:
+				provisioned++
+			}
+		}
+		if unprovisioned > 0 && int64(provisioned) != *p.Replicas {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("replicas"), *p.Replicas, fmt.Sprintf("must match the %d provisioned hosts of the pool in platform.baremetal.hosts, which has %d unprovisioned hosts", provisioned, unprovisioned)))
+		}
+	}
+	return allErrs
+}
+
+// validateVirtualMasterHosts checks that the master hosts of a virtual
+// control plane, if any are listed, name every control plane VM.
+func validateVirtualMasterHosts(c *types.InstallConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if c.Platform.BareMetal.VirtualControlPlane == nil || c.ControlPlane == nil || c.ControlPlane.Replicas == nil {
+		return allErrs
+	}
+	masters := baremetal.HostsWithRole(c.Platform.BareMetal.Hosts, baremetal.MasterRole)
+	if len(masters) > 0 && int64(len(masters)) != *c.ControlPlane.Replicas {
+		allErrs = append(allErrs, field.Invalid(fldPath, len(masters), fmt.Sprintf("the %d hosts with the %q role must match the %d control plane replicas, as they name the virtual control plane machines", len(masters), baremetal.MasterRole, *c.ControlPlane.Replicas)))
+	}
+	return allErrs
+}
+
 func validateCompute(pools []types.MachinePool, fldPath *field.Path, platform string) field.ErrorList {
 	allErrs := field.ErrorList{}
 	poolNames := map[string]bool{}
-	foundPositiveReplicas := false
 	for i, p := range pools {
 		poolFldPath := fldPath.Index(i)
-		if p.Name != "worker" {
-			allErrs = append(allErrs, field.NotSupported(poolFldPath.Child("name"), p.Name, []string{"worker"}))
+		switch {
+		case p.Name == masterPoolName:
+			allErrs = append(allErrs, field.Invalid(poolFldPath.Child("name"), p.Name, fmt.Sprintf("%q is reserved for the control plane", masterPoolName)))
+		default:
+			for _, msg := range k8svalidation.IsDNS1123Label(p.Name) {
+				allErrs = append(allErrs, field.Invalid(poolFldPath.Child("name"), p.Name, msg))
+			}
 		}
 		if poolNames[p.Name] {
 			allErrs = append(allErrs, field.Duplicate(poolFldPath.Child("name"), p.Name))
 		}
 		poolNames[p.Name] = true
-		if p.Replicas != nil && *p.Replicas > 0 {
-			foundPositiveReplicas = true
-		}
 		allErrs = append(allErrs, ValidateMachinePool(&p, poolFldPath, platform)...)
 	}
-	if !foundPositiveReplicas {
-		logrus.Warnf("There are no compute nodes specified. The cluster will not fully initialize without compute nodes.")
+	var diskLayoutPool *types.MachinePool
+	for i := range pools {
+		p := &pools[i]
+		if p.DiskLayout == nil {
+			continue
+		}
+		if diskLayoutPool == nil {
+			diskLayoutPool = p
+		} else if !reflect.DeepEqual(p.DiskLayout, diskLayoutPool.DiskLayout) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("diskLayout"), fmt.Sprintf("compute pools boot from the same worker.ign, so the disk layout must match that of pool %s", diskLayoutPool.Name)))
+		}
+	}
+	if diskLayoutPool != nil {
+		for i, p := range pools {
+			if p.DiskLayout == nil {
+				allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("diskLayout"), fmt.Sprintf("compute pools boot from the same worker.ign, so the disk layout of pool %s applies to every pool", diskLayoutPool.Name)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// machineConfigPoolWarnings warns about additional MachineConfigPools
+// selecting nodes by the default role label when no compute pool creates
+// nodes with it.
+func machineConfigPoolWarnings(c *types.InstallConfig) []warnings.Warning {
+	computePools := map[string]bool{}
+	for _, p := range c.Compute {
+		computePools[p.Name] = true
+	}
+	var warns []warnings.Warning
+	for _, p := range c.MachineConfigPools {
+		roleLabel := fmt.Sprintf("node-role.kubernetes.io/%s", p.Name)
+		if _, ok := p.NodeSelector[roleLabel]; ok && len(p.NodeSelector) == 1 && !computePools[p.Name] {
+			warns = append(warns, warnings.Warning{
+				Category: warnings.Other,
+				Message:  fmt.Sprintf("There is no compute pool named %q, so no nodes will join machine config pool %q until they are labeled %s.", p.Name, p.Name, roleLabel),
+			})
+		}
+	}
+	return warns
+}
+
+// validateMachineConfigPools checks the additional MachineConfigPools.  It
+// also checks that compute pools with tuning have a pool for their
+// MachineConfigs.
+func validateMachineConfigPools(c *types.InstallConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	computePools := map[string]bool{}
+	for _, p := range c.Compute {
+		computePools[p.Name] = true
+	}
+	poolNames := map[string]bool{}
+	for i, p := range c.MachineConfigPools {
+		poolFldPath := fldPath.Index(i)
+		switch p.Name {
+		case masterPoolName, "worker":
+			allErrs = append(allErrs, field.Invalid(poolFldPath.Child("name"), p.Name, fmt.Sprintf("%q is created by the machine-config operator", p.Name)))
+		default:
+			for _, msg := range k8svalidation.IsDNS1123Label(p.Name) {
+				allErrs = append(allErrs, field.Invalid(poolFldPath.Child("name"), p.Name, msg))
+			}
+		}
+		if poolNames[p.Name] {
+			allErrs = append(allErrs, field.Duplicate(poolFldPath.Child("name"), p.Name))
+		}
+		poolNames[p.Name] = true
+		for key, value := range p.NodeSelector {
+			for _, msg := range k8svalidation.IsQualifiedName(key) {
+				allErrs = append(allErrs, field.Invalid(poolFldPath.Child("nodeSelector"), key, msg))
+			}
+			for _, msg := range k8svalidation.IsValidLabelValue(value) {
+				allErrs = append(allErrs, field.Invalid(poolFldPath.Child("nodeSelector").Key(key), value, msg))
+			}
+		}
+		paths := map[string]bool{}
+		for j, f := range p.Files {
+			fileFldPath := poolFldPath.Child("files").Index(j)
+			if !path.IsAbs(f.Path) {
+				allErrs = append(allErrs, field.Invalid(fileFldPath.Child("path"), f.Path, "must be an absolute path"))
+			}
+			if paths[f.Path] {
+				allErrs = append(allErrs, field.Duplicate(fileFldPath.Child("path"), f.Path))
+			}
+			paths[f.Path] = true
+			if f.Mode != nil && (*f.Mode < 0 || *f.Mode > 07777) {
+				allErrs = append(allErrs, field.Invalid(fileFldPath.Child("mode"), *f.Mode, "must be a permission mode between 0 and 07777"))
+			}
+		}
+	}
+	for _, p := range c.Compute {
+		if p.Name != "worker" && (len(p.Sysctls) > 0 || len(p.SELinuxBooleans) > 0) && !poolNames[p.Name] {
+			allErrs = append(allErrs, field.Required(fldPath, fmt.Sprintf("compute pool %s sets sysctls or SELinux booleans, which need a machine config pool of the same name", p.Name)))
+		}
 	}
 	return allErrs
 }
@@ -211,6 +1076,9 @@ func validatePlatform(platform *types.Platform, fldPath *field.Path, openStackVa
 	if platform.AWS != nil {
 		validate(aws.Name, platform.AWS, func(f *field.Path) field.ErrorList { return awsvalidation.ValidatePlatform(platform.AWS, f) })
 	}
+	if platform.Fake != nil {
+		validate(fake.Name, platform.Fake, func(*field.Path) field.ErrorList { return nil })
+	}
 	if platform.Libvirt != nil {
 		validate(libvirt.Name, platform.Libvirt, func(f *field.Path) field.ErrorList { return libvirtvalidation.ValidatePlatform(platform.Libvirt, f) })
 	}
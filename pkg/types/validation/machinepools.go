@@ -2,7 +2,11 @@ package validation
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"strings"
 
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/metalkube/kni-installer/pkg/types"
@@ -14,6 +18,7 @@ import (
 	libvirtvalidation "github.com/metalkube/kni-installer/pkg/types/libvirt/validation"
 	"github.com/metalkube/kni-installer/pkg/types/openstack"
 	openstackvalidation "github.com/metalkube/kni-installer/pkg/types/openstack/validation"
+	"github.com/metalkube/kni-installer/pkg/validate"
 )
 
 // ValidateMachinePool checks that the specified machine pool is valid.
@@ -26,10 +31,162 @@ func ValidateMachinePool(p *types.MachinePool, fldPath *field.Path, platform str
 	} else {
 		allErrs = append(allErrs, field.Required(fldPath.Child("replicas"), "replicas is required"))
 	}
+	if p.Autoscaling != nil {
+		allErrs = append(allErrs, validateMachinePoolAutoscaling(p, fldPath)...)
+	}
+	if p.Rollout != nil {
+		if p.Rollout.WaveSize < 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rollout", "waveSize"), p.Rollout.WaveSize, "wave size must be positive"))
+		}
+		if p.Autoscaling != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("rollout"), "an autoscaled pool cannot be rolled out in waves, as the autoscaler scales it to its minimum at once"))
+		}
+	}
+	if p.MachineCIDR != nil {
+		if platform != baremetal.Name {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("machineCIDR"), p.MachineCIDR.String(), fmt.Sprintf("a machine pool's own machine CIDR is not supported on %q", platform)))
+		} else if err := validate.SubnetCIDR(&p.MachineCIDR.IPNet); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("machineCIDR"), p.MachineCIDR.String(), err.Error()))
+		}
+	}
+	if p.DiskLayout != nil {
+		allErrs = append(allErrs, validateDiskLayout(p.DiskLayout, fldPath.Child("diskLayout"))...)
+	}
+	for key, value := range p.Sysctls {
+		if !sysctlKeyRegexp.MatchString(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sysctls"), key, "must be a kernel parameter name, e.g. net.ipv4.conf.all.rp_filter"))
+		}
+		if value == "" || strings.ContainsAny(value, "\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sysctls").Key(key), value, "must be a single line value"))
+		}
+	}
+	for name := range p.SELinuxBooleans {
+		if !selinuxBooleanRegexp.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("selinuxBooleans"), name, "must be an SELinux boolean name, e.g. container_manage_cgroup"))
+		}
+	}
+	switch p.Architecture {
+	case "", types.ArchitectureAMD64:
+	case types.ArchitectureARM64:
+		if platform != baremetal.Name {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("architecture"), p.Architecture, fmt.Sprintf("the %s architecture is not supported on %q", p.Architecture, platform)))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("architecture"), p.Architecture, []string{string(types.ArchitectureAMD64), string(types.ArchitectureARM64)}))
+	}
+	switch p.OperatingSystem {
+	case "", types.OperatingSystemRHCOS, types.OperatingSystemRHEL:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("operatingSystem"), p.OperatingSystem, []string{string(types.OperatingSystemRHCOS), string(types.OperatingSystemRHEL)}))
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabels(p.Labels, fldPath.Child("labels"))...)
 	allErrs = append(allErrs, validateMachinePoolPlatform(&p.Platform, fldPath.Child("platform"), platform)...)
 	return allErrs
 }
 
+func validateMachinePoolAutoscaling(p *types.MachinePool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	a := p.Autoscaling
+	autoscalingPath := fldPath.Child("autoscaling")
+	if a.MinReplicas < 0 {
+		allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("minReplicas"), a.MinReplicas, "minimum number of replicas must not be negative"))
+	}
+	if a.MaxReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("maxReplicas"), a.MaxReplicas, "maximum number of replicas must be positive"))
+	}
+	if a.MaxReplicas < a.MinReplicas {
+		allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("maxReplicas"), a.MaxReplicas, "maximum number of replicas must not be less than the minimum"))
+	}
+	if p.Replicas != nil && (*p.Replicas < a.MinReplicas || *p.Replicas > a.MaxReplicas) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *p.Replicas, fmt.Sprintf("number of replicas must be between %d and %d when autoscaling", a.MinReplicas, a.MaxReplicas)))
+	}
+	return allErrs
+}
+
+var (
+	// sysctlKeyRegexp matches kernel parameter names, in either the dotted
+	// or the slashed form sysctl accepts.
+	sysctlKeyRegexp = regexp.MustCompile(`^[a-z0-9_]+([./][a-zA-Z0-9_-]+)+$`)
+
+	// selinuxBooleanRegexp matches SELinux boolean names.
+	selinuxBooleanRegexp = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// gptLabelMaxLength is the longest partition label a GPT can hold.
+const gptLabelMaxLength = 36
+
+// validDiskFormats are the filesystems a partition can be formatted with.
+var validDiskFormats = map[string]bool{
+	"xfs":  true,
+	"ext4": true,
+}
+
+// ignitionPaths are the directories Ignition writes files to on the root
+// filesystem, from the config the machine config server serves and for the
+// core user's SSH keys.  The filesystems of a disk layout are mounted by
+// systemd after Ignition has run, so one mounted over these would hide
+// the files.
+var ignitionPaths = []string{"/etc", "/home", "/opt", "/root", "/var/home", "/var/lib/kubelet", "/var/opt", "/var/roothome", "/var/usrlocal"}
+
+// hiddenIgnitionPath returns the directory Ignition writes to which a
+// filesystem mounted at mountPath would hide, or "" if there is none.
+func hiddenIgnitionPath(mountPath string) string {
+	for _, p := range ignitionPaths {
+		if p == mountPath || strings.HasPrefix(p, mountPath+"/") || strings.HasPrefix(mountPath, p+"/") {
+			return p
+		}
+	}
+	return ""
+}
+
+func validateDiskLayout(l *types.DiskLayout, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if l.Device != "" && !path.IsAbs(l.Device) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("device"), l.Device, "must be an absolute path"))
+	}
+	if len(l.Partitions) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("partitions"), "at least one partition is required"))
+	}
+	labels := map[string]bool{}
+	mountPaths := map[string]bool{}
+	for i, p := range l.Partitions {
+		partitionPath := fldPath.Child("partitions").Index(i)
+		if p.Label == "" {
+			allErrs = append(allErrs, field.Required(partitionPath.Child("label"), "label is required"))
+		} else if len(p.Label) > gptLabelMaxLength {
+			allErrs = append(allErrs, field.TooLong(partitionPath.Child("label"), p.Label, gptLabelMaxLength))
+		}
+		if labels[p.Label] {
+			allErrs = append(allErrs, field.Duplicate(partitionPath.Child("label"), p.Label))
+		}
+		labels[p.Label] = true
+		mountPath := path.Clean(p.MountPath)
+		switch {
+		case !path.IsAbs(p.MountPath):
+			allErrs = append(allErrs, field.Invalid(partitionPath.Child("mountPath"), p.MountPath, "must be an absolute path"))
+		case mountPath == "/" || mountPath == "/boot" || strings.HasPrefix(mountPath, "/boot/") || mountPath == "/usr" || strings.HasPrefix(mountPath, "/usr/"):
+			allErrs = append(allErrs, field.Invalid(partitionPath.Child("mountPath"), p.MountPath, "cannot be a filesystem of the operating system"))
+		default:
+			if hidden := hiddenIgnitionPath(mountPath); hidden != "" {
+				allErrs = append(allErrs, field.Invalid(partitionPath.Child("mountPath"), p.MountPath, fmt.Sprintf("would hide the files Ignition writes to %s", hidden)))
+			}
+		}
+		if mountPaths[mountPath] {
+			allErrs = append(allErrs, field.Duplicate(partitionPath.Child("mountPath"), p.MountPath))
+		}
+		mountPaths[mountPath] = true
+		if p.SizeMiB < 0 {
+			allErrs = append(allErrs, field.Invalid(partitionPath.Child("sizeMiB"), p.SizeMiB, "must not be negative"))
+		} else if p.SizeMiB == 0 && i != len(l.Partitions)-1 {
+			allErrs = append(allErrs, field.Required(partitionPath.Child("sizeMiB"), "only the last partition may fill the rest of the disk"))
+		}
+		if p.Format != "" && !validDiskFormats[p.Format] {
+			allErrs = append(allErrs, field.NotSupported(partitionPath.Child("format"), p.Format, []string{"ext4", "xfs"}))
+		}
+	}
+	return allErrs
+}
+
 func validateMachinePoolPlatform(p *types.MachinePoolPlatform, fldPath *field.Path, platform string) field.ErrorList {
 	allErrs := field.ErrorList{}
 	validate := func(n string, value interface{}, validation func(*field.Path) field.ErrorList) {
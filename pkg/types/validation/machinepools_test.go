@@ -7,6 +7,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
 
+	"github.com/metalkube/kni-installer/pkg/ipnet"
 	"github.com/metalkube/kni-installer/pkg/types"
 	"github.com/metalkube/kni-installer/pkg/types/aws"
 	"github.com/metalkube/kni-installer/pkg/types/libvirt"
@@ -53,6 +54,67 @@ func TestValidateMachinePool(t *testing.T) {
 			platform: "aws",
 			valid:    false,
 		},
+		{
+			name: "valid autoscaling",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Autoscaling = &types.MachinePoolAutoscaling{MinReplicas: 1, MaxReplicas: 5}
+				return p
+			}(),
+			platform: "aws",
+			valid:    true,
+		},
+		{
+			name: "autoscaling max less than min",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Autoscaling = &types.MachinePoolAutoscaling{MinReplicas: 3, MaxReplicas: 2}
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
+		{
+			name: "replicas outside autoscaling range",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Autoscaling = &types.MachinePoolAutoscaling{MinReplicas: 2, MaxReplicas: 5}
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
+		{
+			name: "valid rollout",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Rollout = &types.MachinePoolRollout{WaveSize: 10}
+				return p
+			}(),
+			platform: "aws",
+			valid:    true,
+		},
+		{
+			name: "invalid rollout wave size",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Rollout = &types.MachinePoolRollout{}
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
+		{
+			name: "rollout with autoscaling",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Autoscaling = &types.MachinePoolAutoscaling{MinReplicas: 1, MaxReplicas: 5}
+				p.Rollout = &types.MachinePoolRollout{WaveSize: 1}
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
 		{
 			name: "valid aws",
 			pool: func() *types.MachinePool {
@@ -105,6 +167,151 @@ func TestValidateMachinePool(t *testing.T) {
 			platform: "openstack",
 			valid:    true,
 		},
+		{
+			name: "valid machine CIDR",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.MachineCIDR = ipnet.MustParseCIDR("192.168.112.0/24")
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    true,
+		},
+		{
+			name: "invalid machine CIDR",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.MachineCIDR = ipnet.MustParseCIDR("192.168.112.1/24")
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    false,
+		},
+		{
+			name: "unsupported machine CIDR",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.MachineCIDR = ipnet.MustParseCIDR("10.1.0.0/16")
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
+		{
+			name: "valid architecture",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Architecture = types.ArchitectureARM64
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    true,
+		},
+		{
+			name: "unsupported architecture",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Architecture = types.ArchitectureARM64
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
+		{
+			name: "invalid architecture",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Architecture = "x86_64"
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    false,
+		},
+		{
+			name: "valid disk layout",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.DiskLayout = &types.DiskLayout{
+					Partitions: []types.DiskPartition{
+						{Label: "log", MountPath: "/var/log", SizeMiB: 20480},
+						{Label: "containers", MountPath: "/var/lib/containers", Format: "ext4"},
+					},
+				}
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    true,
+		},
+		{
+			name: "disk layout with unsized partition before the last",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.DiskLayout = &types.DiskLayout{
+					Partitions: []types.DiskPartition{
+						{Label: "log", MountPath: "/var/log"},
+						{Label: "containers", MountPath: "/var/lib/containers"},
+					},
+				}
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    false,
+		},
+		{
+			name: "disk layout mounting over the operating system",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.DiskLayout = &types.DiskLayout{
+					Partitions: []types.DiskPartition{{Label: "usr", MountPath: "/usr/local"}},
+				}
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    false,
+		},
+		{
+			name: "disk layout mounting over files Ignition writes",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.DiskLayout = &types.DiskLayout{
+					Partitions: []types.DiskPartition{{Label: "var", MountPath: "/var"}},
+				}
+				return p
+			}(),
+			platform: "baremetal",
+			valid:    false,
+		},
+		{
+			name: "valid tuning",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Sysctls = map[string]string{"net.ipv4.conf.all.rp_filter": "2", "net/netfilter/nf_conntrack_max": "1048576"}
+				p.SELinuxBooleans = map[string]bool{"container_manage_cgroup": true}
+				return p
+			}(),
+			platform: "aws",
+			valid:    true,
+		},
+		{
+			name: "invalid sysctl",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.Sysctls = map[string]string{"rp_filter": "2"}
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
+		{
+			name: "invalid SELinux boolean",
+			pool: func() *types.MachinePool {
+				p := validMachinePool()
+				p.SELinuxBooleans = map[string]bool{"container manage cgroup": true}
+				return p
+			}(),
+			platform: "aws",
+			valid:    false,
+		},
 		{
 			name: "mis-matched platform",
 			pool: func() *types.MachinePool {
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+var validProfiles = []string{
+	string(types.ProfileCompact),
+	string(types.ProfileHAStretch),
+	string(types.ProfileStandard),
+}
+
+// validateProfile checks the machine pools against the constraints of the
+// selected profile.  An empty profile is standard.
+func validateProfile(c *types.InstallConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	controlPlanePath := field.NewPath("controlPlane")
+	computePath := field.NewPath("compute")
+	switch c.Profile {
+	case "", types.ProfileStandard:
+	case types.ProfileCompact:
+		if c.ControlPlane != nil && c.ControlPlane.Replicas != nil && *c.ControlPlane.Replicas != 3 {
+			allErrs = append(allErrs, field.Invalid(controlPlanePath.Child("replicas"), *c.ControlPlane.Replicas, "compact clusters must have 3 control plane replicas"))
+		}
+		for i, p := range c.Compute {
+			if p.Replicas != nil && *p.Replicas != 0 {
+				allErrs = append(allErrs, field.Invalid(computePath.Index(i).Child("replicas"), *p.Replicas, "compact clusters cannot have compute replicas"))
+			}
+			if p.Autoscaling != nil {
+				allErrs = append(allErrs, field.Forbidden(computePath.Index(i).Child("autoscaling"), "compact clusters cannot autoscale compute pools"))
+			}
+		}
+	case types.ProfileHAStretch:
+		if c.ControlPlane != nil && c.ControlPlane.Replicas != nil && (*c.ControlPlane.Replicas < 5 || *c.ControlPlane.Replicas%2 == 0) {
+			allErrs = append(allErrs, field.Invalid(controlPlanePath.Child("replicas"), *c.ControlPlane.Replicas, "ha-stretch clusters must have an odd number of control plane replicas, at least 5"))
+		}
+		zones := map[string]bool{}
+		for i, p := range c.Compute {
+			zone, ok := p.Labels[types.ZoneLabel]
+			if !ok {
+				allErrs = append(allErrs, field.Required(computePath.Index(i).Child("labels"), fmt.Sprintf("ha-stretch compute pools must set the %s label", types.ZoneLabel)))
+				continue
+			}
+			zones[zone] = true
+		}
+		if len(zones) < 2 {
+			allErrs = append(allErrs, field.Invalid(computePath, len(zones), "ha-stretch clusters must have compute pools in at least 2 zones"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath, c.Profile, validProfiles))
+	}
+	return allErrs
+}
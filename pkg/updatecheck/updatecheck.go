@@ -0,0 +1,237 @@
+// Package updatecheck finds newer installer builds for the targeted
+// OpenShift release on a release endpoint.  It only reports them: nothing
+// authenticates the builds beyond the index's transport, so replacing the
+// installer is left to the user's own verified download.
+//
+// A release endpoint is an index, served over HTTPS or kept in a local
+// mirror directory, listing the available builds:
+//
+//	{
+//	  "builds": [
+//	    {
+//	      "version": "v0.17.0",
+//	      "release": "4.1",
+//	      "channels": ["stable"],
+//	      "os": "linux",
+//	      "arch": "amd64",
+//	      "url": "v0.17.0/kni-install-linux-amd64",
+//	      "sha256": "..."
+//	    }
+//	  ]
+//	}
+//
+// Relative URLs are resolved against the index's location.
+package updatecheck
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/download"
+	"github.com/metalkube/kni-installer/pkg/offline"
+)
+
+// EndpointEnvVar is the release endpoint, the URL or local path of the
+// index of installer builds.  The --endpoint flags override it.
+const EndpointEnvVar = "OPENSHIFT_INSTALL_UPDATE_ENDPOINT"
+
+// Index is the list of installer builds served by a release endpoint.
+type Index struct {
+	Builds []Build `json:"builds"`
+}
+
+// Build is an installer build.
+type Build struct {
+	// Version is the installer's version, e.g. v0.17.0.
+	Version string `json:"version"`
+
+	// Release is the OpenShift release the build installs, as
+	// major.minor, e.g. 4.1.
+	Release string `json:"release"`
+
+	// Channels are the channels the build is published to, e.g. stable
+	// or candidate.  A build without channels is in every channel.
+	Channels []string `json:"channels,omitempty"`
+
+	// OS and Arch are the platform of the build, as named by Go.
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+
+	// URL is the location of the installer binary, relative to the
+	// index.
+	URL string `json:"url"`
+
+	// SHA256 is the hex-encoded SHA-256 digest of the binary.
+	SHA256 string `json:"sha256"`
+}
+
+// FetchIndex reads the index at endpoint, an HTTPS or file URL or a local
+// path, and resolves the URLs of its builds against it.
+func FetchIndex(ctx context.Context, endpoint string) (*Index, error) {
+	if endpoint == "" {
+		return nil, errors.Errorf("no release endpoint; set --endpoint or %s", EndpointEnvVar)
+	}
+	base, err := endpointURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	body, err := open(ctx, base)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the release index")
+	}
+	defer body.Close()
+
+	index := &Index{}
+	if err := json.NewDecoder(body).Decode(index); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the release index")
+	}
+	for i := range index.Builds {
+		ref, err := url.Parse(index.Builds[i].URL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid URL of build %s", index.Builds[i].Version)
+		}
+		index.Builds[i].URL = base.ResolveReference(ref).String()
+	}
+	return index, nil
+}
+
+// endpointURL returns endpoint as a URL, turning local paths into file
+// URLs.  Plain HTTP is refused, as anyone on the path could list builds of
+// their choosing.
+func endpointURL(endpoint string) (*url.URL, error) {
+	if u, err := url.Parse(endpoint); err == nil && (u.Scheme == "https" || u.Scheme == "file") {
+		return u, nil
+	} else if err == nil && u.Scheme == "http" {
+		return nil, errors.Errorf("the release endpoint %s must use https", endpoint)
+	}
+	path, err := filepath.Abs(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(path)}, nil
+}
+
+// open opens an HTTPS or file URL.
+func open(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	if u.Scheme == "file" {
+		return os.Open(filepath.FromSlash(u.Path))
+	}
+	if err := offline.Check(ctx, "check for installer updates"); err != nil {
+		return nil, err
+	}
+	resp, err := download.Get(ctx, http.DefaultClient, []string{u.String()})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Latest returns the newest build in index for this platform which installs
+// release, if it is not empty, and is in channel, or nil if there is none.
+// Builds with invalid versions are skipped.
+func Latest(index *Index, release, channel string) *Build {
+	var latest *Build
+	for i := range index.Builds {
+		b := &index.Builds[i]
+		if b.OS != runtime.GOOS || b.Arch != runtime.GOARCH {
+			continue
+		}
+		if release != "" && b.Release != release {
+			continue
+		}
+		if !inChannel(b, channel) {
+			continue
+		}
+		if _, err := parseVersion(b.Version); err != nil {
+			continue
+		}
+		if latest == nil {
+			latest = b
+		} else if newer, _ := Newer(b.Version, latest.Version); newer {
+			latest = b
+		}
+	}
+	return latest
+}
+
+func inChannel(b *Build, channel string) bool {
+	if len(b.Channels) == 0 {
+		return true
+	}
+	for _, c := range b.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Newer returns true if version a is newer than b.  Versions are
+// v-prefixed or bare dot-separated numbers, with an optional suffix after
+// a dash, e.g. the commits since the tag from git describe, which is
+// ignored.
+func Newer(a, b string) (bool, error) {
+	av, err := parseVersion(a)
+	if err != nil {
+		return false, err
+	}
+	bv, err := parseVersion(b)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var x, y int
+		if i < len(av) {
+			x = av[i]
+		}
+		if i < len(bv) {
+			y = bv[i]
+		}
+		if x != y {
+			return x > y, nil
+		}
+	}
+	return false, nil
+}
+
+func parseVersion(version string) ([]int, error) {
+	v := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, errors.Errorf("invalid version %q", version)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+// ReleaseFromImage returns the OpenShift release, as major.minor, of a
+// release image tagged with its version, e.g. 4.1 for
+// quay.io/openshift-release-dev/ocp-release:4.1.0, or "" if the tag is not
+// a version.
+func ReleaseFromImage(image string) string {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") || strings.Contains(image, "@") {
+		return ""
+	}
+	parts, err := parseVersion(image[i+1:])
+	if err != nil || len(parts) < 2 {
+		return ""
+	}
+	return strconv.Itoa(parts[0]) + "." + strconv.Itoa(parts[1])
+}
@@ -0,0 +1,115 @@
+package updatecheck
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewer(t *testing.T) {
+	cases := []struct {
+		a, b          string
+		expected      bool
+		expectedError string
+	}{
+		{a: "v0.17.0", b: "v0.16.1", expected: true},
+		{a: "v0.16.1", b: "v0.17.0"},
+		{a: "v0.17.0", b: "v0.17.0"},
+		{a: "0.17.1", b: "v0.17", expected: true},
+		{a: "v0.17.0", b: "v0.16.1-12-gabcdef0", expected: true},
+		{a: "v0.17.0", b: "v0.17.0-12-gabcdef0"},
+		{a: "v0.17.0", b: "unreleased-master-1234", expectedError: `^invalid version "unreleased-master-1234"$`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			newer, err := Newer(tc.a, tc.b)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, newer)
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	build := func(version, release string, channels ...string) Build {
+		return Build{Version: version, Release: release, Channels: channels, OS: runtime.GOOS, Arch: runtime.GOARCH}
+	}
+	other := build("v0.20.0", "4.1", "stable")
+	other.Arch = "other"
+	index := &Index{Builds: []Build{
+		build("v0.16.0", "4.1", "stable", "candidate"),
+		build("v0.17.0", "4.1", "stable"),
+		build("v0.18.0", "4.1", "candidate"),
+		build("v0.19.0", "4.2"),
+		build("latest", "4.1", "stable"),
+		other,
+	}}
+
+	cases := []struct {
+		release  string
+		channel  string
+		expected string
+	}{
+		{release: "4.1", channel: "stable", expected: "v0.17.0"},
+		{release: "4.1", channel: "candidate", expected: "v0.18.0"},
+		{release: "4.2", channel: "stable", expected: "v0.19.0"},
+		{release: "", channel: "stable", expected: "v0.19.0"},
+		{release: "4.3", channel: "stable"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.release+" "+tc.channel, func(t *testing.T) {
+			latest := Latest(index, tc.release, tc.channel)
+			if tc.expected == "" {
+				assert.Nil(t, latest)
+				return
+			}
+			if assert.NotNil(t, latest) {
+				assert.Equal(t, tc.expected, latest.Version)
+			}
+		})
+	}
+}
+
+func TestReleaseFromImage(t *testing.T) {
+	cases := map[string]string{
+		"quay.io/openshift-release-dev/ocp-release:4.1.0":             "4.1",
+		"registry.svc.ci.openshift.org/openshift/origin-release:v4.0": "4.0",
+		"localhost:5000/ocp-release":                                  "",
+		"quay.io/openshift-release-dev/ocp-release:latest":            "",
+		"quay.io/openshift-release-dev/ocp-release@sha256:0123":       "",
+	}
+	for image, expected := range cases {
+		assert.Equal(t, expected, ReleaseFromImage(image), image)
+	}
+}
+
+func TestFetchIndexInsecure(t *testing.T) {
+	_, err := FetchIndex(context.Background(), "http://releases.example.com/index.json")
+	assert.EqualError(t, err, "the release endpoint http://releases.example.com/index.json must use https")
+}
+
+func TestFetchIndexMirror(t *testing.T) {
+	dir, err := ioutil.TempDir("", "updatecheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "index.json")
+	if err := ioutil.WriteFile(path, []byte(`{"builds": [{"version": "v0.17.0", "url": "kni-install"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := FetchIndex(context.Background(), path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "file://"+filepath.ToSlash(filepath.Join(dir, "kni-install")), index.Builds[0].URL)
+}
@@ -0,0 +1,69 @@
+package verify
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/release"
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+const releaseTimeout = 30 * time.Second
+
+var (
+	// releaseArchitectures returns the architectures of a release image.
+	// Tests override it.
+	releaseArchitectures = release.Architectures
+)
+
+// poolArchitectures returns the architectures of the machine pools of
+// installConfig, sorted.
+func poolArchitectures(installConfig *types.InstallConfig) []string {
+	pools := append([]types.MachinePool{}, installConfig.Compute...)
+	if installConfig.ControlPlane != nil {
+		pools = append(pools, *installConfig.ControlPlane)
+	}
+	seen := map[string]bool{}
+	var archs []string
+	for _, pool := range pools {
+		arch := string(pool.Architecture)
+		if arch == "" {
+			arch = string(types.ArchitectureAMD64)
+		}
+		if !seen[arch] {
+			seen[arch] = true
+			archs = append(archs, arch)
+		}
+	}
+	sort.Strings(archs)
+	return archs
+}
+
+// releaseCoversArchitectures checks that releaseImage is available for each
+// of archs, giving up when ctx is done or after releaseTimeout.
+func releaseCoversArchitectures(ctx context.Context, releaseImage, pullSecret string, archs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, releaseTimeout)
+	defer cancel()
+	available, err := releaseArchitectures(ctx, releaseImage, pullSecret)
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, arch := range available {
+		found[arch] = true
+	}
+	var missing []string
+	for _, arch := range archs {
+		if !found[arch] {
+			missing = append(missing, arch)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("the release image %s is not available for %s, which machine pools need; use a multi-arch release image", releaseImage, strings.Join(missing, ", "))
+	}
+	return nil
+}
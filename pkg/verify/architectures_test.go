@@ -0,0 +1,36 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func TestPoolArchitectures(t *testing.T) {
+	installConfig := &types.InstallConfig{
+		ControlPlane: &types.MachinePool{Name: "master"},
+		Compute: []types.MachinePool{
+			{Name: "worker", Architecture: types.ArchitectureAMD64},
+			{Name: "arm", Architecture: types.ArchitectureARM64},
+		},
+	}
+	assert.Equal(t, []string{"amd64", "arm64"}, poolArchitectures(installConfig))
+}
+
+func TestReleaseCoversArchitectures(t *testing.T) {
+	defer func(fn func(context.Context, string, string) ([]string, error)) { releaseArchitectures = fn }(releaseArchitectures)
+	releaseArchitectures = func(ctx context.Context, image, pullSecret string) ([]string, error) {
+		if image == "example.com/ocp/release:multi" {
+			return []string{"amd64", "arm64", "ppc64le"}, nil
+		}
+		return []string{"amd64"}, nil
+	}
+
+	assert.NoError(t, releaseCoversArchitectures(context.Background(), "example.com/ocp/release:multi", "{}", []string{"amd64", "arm64"}))
+	assert.EqualError(t,
+		releaseCoversArchitectures(context.Background(), "example.com/ocp/release:single", "{}", []string{"amd64", "arm64"}),
+		"the release image example.com/ocp/release:single is not available for arm64, which machine pools need; use a multi-arch release image")
+}
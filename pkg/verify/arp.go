@@ -0,0 +1,183 @@
+package verify
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+var (
+	// arpTable is where Linux exposes its IPv4 neighbour cache.
+	arpTable = "/proc/net/arp"
+
+	// arpWait is how long to wait for replies to the probes.
+	arpWait = 2 * time.Second
+
+	// sendProbe makes the kernel resolve ip's MAC address, if ip is on a
+	// directly attached network, by sending it a UDP datagram.  Tests
+	// override it.
+	sendProbe = func(ip string) error {
+		conn, err := net.Dial("udp", net.JoinHostPort(ip, "9"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = conn.Write([]byte{0})
+		return err
+	}
+)
+
+// plannedAddress is an address the install will assign.
+type plannedAddress struct {
+	// IP is the address.
+	IP string
+
+	// User is what the address is for, e.g. "apiVIP" or "host master-0".
+	User string
+
+	// MAC, if set, is the MAC address which may already answer for the
+	// address, e.g. that of a host which is up with its final address.
+	MAC string
+}
+
+// arpEntry is a resolved entry in the kernel's neighbour cache.
+type arpEntry struct {
+	MAC    string
+	Device string
+}
+
+// plannedAddresses returns the addresses a bare metal install will assign,
+// and the names of the hosts which own each boot MAC address.
+func plannedAddresses(p *baremetal.Platform) ([]plannedAddress, map[string]string) {
+	var planned []plannedAddress
+	if p.APIVIP != "" {
+		planned = append(planned, plannedAddress{IP: p.APIVIP, User: "apiVIP"})
+	}
+	if p.IngressVIP != "" {
+		planned = append(planned, plannedAddress{IP: p.IngressVIP, User: "ingressVIP"})
+	}
+
+	owners := map[string]string{}
+	for _, host := range p.Hosts {
+		mac, err := net.ParseMAC(host.BootMACAddress)
+		if err == nil {
+			owners[mac.String()] = host.Name
+		}
+		if host.Network == nil {
+			continue
+		}
+		if ip, _, err := net.ParseCIDR(host.Network.Address); err == nil {
+			address := plannedAddress{IP: ip.String(), User: "host " + host.Name}
+			if mac != nil {
+				address.MAC = mac.String()
+			}
+			planned = append(planned, address)
+		}
+	}
+	return planned, owners
+}
+
+// addressConflicts returns a message for each planned address which
+// something on the installer host's directly attached networks, e.g. the
+// baremetal and provisioning bridges, appears to use.  owners names the
+// declared hosts by their boot MAC addresses, so a conflict with one of
+// them is reported as such.
+//
+// The addresses are looked up in the kernel's neighbour cache after
+// probing them, and the cache may still hold entries of machines which
+// have since gone away, so the conflicts are only likely, not certain.
+func addressConflicts(planned []plannedAddress, owners map[string]string) ([]string, error) {
+	ips := make([]string, 0, len(planned))
+	for _, address := range planned {
+		ips = append(ips, address.IP)
+	}
+	users, err := probeAddresses(ips)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for _, address := range planned {
+		entry, ok := users[address.IP]
+		if !ok {
+			continue
+		}
+		mac := entry.MAC
+		if hw, err := net.ParseMAC(mac); err == nil {
+			mac = hw.String()
+		}
+		if mac == address.MAC {
+			continue
+		}
+		user := "MAC " + mac
+		if owner, ok := owners[mac]; ok {
+			user = fmt.Sprintf("host %s (MAC %s)", owner, mac)
+		}
+		conflicts = append(conflicts, fmt.Sprintf("%s (%s) appears to be used by %s on %s", address.IP, address.User, user, entry.Device))
+	}
+	return conflicts, nil
+}
+
+// probeAddresses probes each of the addresses and returns the neighbour
+// cache entry of each one which answered.
+func probeAddresses(ips []string) (map[string]arpEntry, error) {
+	if _, err := os.Stat(arpTable); os.IsNotExist(err) {
+		logrus.Debugf("Skipping ARP probes: %s does not exist", arpTable)
+		return nil, nil
+	}
+
+	for _, ip := range ips {
+		if err := sendProbe(ip); err != nil {
+			logrus.Debugf("Failed to probe %s: %v", ip, err)
+		}
+	}
+	time.Sleep(arpWait)
+
+	table, err := readARPTable(arpTable)
+	if err != nil {
+		return nil, err
+	}
+	users := map[string]arpEntry{}
+	for _, ip := range ips {
+		if entry, ok := table[ip]; ok {
+			users[ip] = entry
+		}
+	}
+	return users, nil
+}
+
+// readARPTable returns the resolved entries of a /proc/net/arp style
+// table.
+func readARPTable(path string) (map[string]arpEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the ARP table")
+	}
+	defer file.Close()
+
+	table := map[string]arpEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // skip the header
+	for scanner.Scan() {
+		// IP address, HW type, Flags, HW address, Mask, Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[2], 0, 32)
+		if err != nil || flags&0x2 == 0 {
+			continue
+		}
+		table[fields[0]] = arpEntry{MAC: fields[3], Device: fields[5]}
+	}
+	return table, errors.Wrap(scanner.Err(), "failed to read the ARP table")
+}
@@ -0,0 +1,71 @@
+package verify
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+const testARPTable = `IP address       HW type     Flags       HW address            Mask     Device
+192.168.111.1    0x1         0x2         52:54:00:00:00:01     *        baremetal
+192.168.111.5    0x1         0x2         52:54:00:00:00:05     *        baremetal
+192.168.111.4    0x1         0x0         00:00:00:00:00:00     *        baremetal
+192.168.111.20   0x1         0x2         52:54:00:aa:bb:01     *        baremetal
+192.168.111.21   0x1         0x2         52:54:00:aa:bb:01     *        baremetal
+`
+
+func TestAddressConflicts(t *testing.T) {
+	file, err := ioutil.TempFile("", "arp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(testARPTable); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	defer func(table string, wait time.Duration, probe func(string) error) {
+		arpTable, arpWait, sendProbe = table, wait, probe
+	}(arpTable, arpWait, sendProbe)
+	var probed []string
+	arpTable, arpWait = file.Name(), 0
+	sendProbe = func(ip string) error {
+		probed = append(probed, ip)
+		return nil
+	}
+
+	platform := &baremetal.Platform{
+		IngressVIP: "192.168.111.4",
+		Hosts: []baremetal.Host{
+			{
+				Name:           "master-0",
+				BootMACAddress: "52-54-00-AA-BB-01",
+				Network:        &baremetal.HostNetwork{Address: "192.168.111.20/24"},
+			},
+		},
+	}
+	planned, owners := plannedAddresses(platform)
+	conflicts, err := addressConflicts(planned, owners)
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, []string{"192.168.111.4", "192.168.111.20"}, probed)
+
+	platform.APIVIP = "192.168.111.5"
+	platform.Hosts = append(platform.Hosts, baremetal.Host{
+		Name:    "master-1",
+		Network: &baremetal.HostNetwork{Address: "192.168.111.21/24"},
+	})
+	planned, owners = plannedAddresses(platform)
+	conflicts, err = addressConflicts(planned, owners)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"192.168.111.5 (apiVIP) appears to be used by MAC 52:54:00:00:00:05 on baremetal",
+		"192.168.111.21 (host master-1) appears to be used by host master-0 (MAC 52:54:00:aa:bb:01) on baremetal",
+	}, conflicts)
+}
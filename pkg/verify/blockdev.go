@@ -0,0 +1,18 @@
+// +build linux
+
+package verify
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// blockDevice returns the major:minor number of the device holding path.
+func blockDevice(path string) (string, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%d", unix.Major(st.Dev), unix.Minor(st.Dev)), nil
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package verify
+
+import (
+	"github.com/pkg/errors"
+)
+
+// blockDevice returns the major:minor number of the device holding path.
+func blockDevice(path string) (string, error) {
+	return "", errors.New("block devices can only be inspected on Linux")
+}
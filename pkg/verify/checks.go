@@ -0,0 +1,198 @@
+package verify
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	cov1helpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/aws"
+	"github.com/metalkube/kni-installer/pkg/types/openstack"
+)
+
+const (
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	imageRegistryOperator         = "image-registry"
+	dialTimeout                   = 10 * time.Second
+)
+
+// ClusterChecks returns the curated set of checks for the cluster described
+// by installConfig.
+func ClusterChecks(installConfig *types.InstallConfig, client kubernetes.Interface, cc configclient.Interface) []Check {
+	clusterDomain := installConfig.ClusterDomain()
+	apiHost := fmt.Sprintf("api.%s", clusterDomain)
+	ingressHost := fmt.Sprintf("verify.apps.%s", clusterDomain)
+
+	checks := []Check{
+		{
+			Name: "cluster-operators-available",
+			Run: func() error {
+				operators, err := cc.ConfigV1().ClusterOperators().List(metav1.ListOptions{})
+				if err != nil {
+					return errors.Wrap(err, "failed to list cluster operators")
+				}
+				return clusterOperatorsAvailable(operators.Items)
+			},
+		},
+		{
+			Name: "image-registry-available",
+			Run: func() error {
+				operator, err := cc.ConfigV1().ClusterOperators().Get(imageRegistryOperator, metav1.GetOptions{})
+				if err != nil {
+					return errors.Wrap(err, "failed to get the image registry operator")
+				}
+				return clusterOperatorsAvailable([]configv1.ClusterOperator{*operator})
+			},
+		},
+		{
+			Name: "nodes-ready",
+			Run: func() error {
+				nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+				if err != nil {
+					return errors.Wrap(err, "failed to list nodes")
+				}
+				return nodesReady(nodes.Items, expectedNodes(installConfig))
+			},
+		},
+	}
+
+	if hasDefaultStorage(installConfig.Platform.Name()) {
+		checks = append(checks, Check{
+			Name: "default-storage-class",
+			Run: func() error {
+				classes, err := client.StorageV1().StorageClasses().List(metav1.ListOptions{})
+				if err != nil {
+					return errors.Wrap(err, "failed to list storage classes")
+				}
+				return defaultStorageClass(classes.Items)
+			},
+		})
+	}
+
+	checks = append(checks,
+		Check{
+			Name: "api-dns",
+			Run:  func() error { return resolves(apiHost) },
+		},
+		Check{
+			Name: "ingress-dns",
+			Run:  func() error { return resolves(ingressHost) },
+		},
+		Check{
+			Name: "api-vip-reachable",
+			Run:  func() error { return reachable(net.JoinHostPort(apiHost, "6443")) },
+		},
+		Check{
+			Name: "ingress-vip-reachable",
+			Run:  func() error { return reachable(net.JoinHostPort(ingressHost, "443")) },
+		},
+	)
+
+	return checks
+}
+
+// hasDefaultStorage returns true for platforms on which the cluster is
+// expected to come up with a default StorageClass.
+func hasDefaultStorage(platform string) bool {
+	switch platform {
+	case aws.Name, openstack.Name:
+		return true
+	default:
+		return false
+	}
+}
+
+// expectedNodes returns the number of nodes declared in the install config.
+func expectedNodes(installConfig *types.InstallConfig) int64 {
+	var total int64
+	if installConfig.ControlPlane != nil && installConfig.ControlPlane.Replicas != nil {
+		total += *installConfig.ControlPlane.Replicas
+	}
+	for _, pool := range installConfig.Compute {
+		if pool.Replicas != nil {
+			total += *pool.Replicas
+		}
+	}
+	return total
+}
+
+func clusterOperatorsAvailable(operators []configv1.ClusterOperator) error {
+	if len(operators) == 0 {
+		return errors.New("no cluster operators found")
+	}
+	var unavailable []string
+	for _, operator := range operators {
+		if !cov1helpers.IsStatusConditionTrue(operator.Status.Conditions, configv1.OperatorAvailable) ||
+			cov1helpers.IsStatusConditionTrue(operator.Status.Conditions, configv1.OperatorFailing) {
+			unavailable = append(unavailable, operator.Name)
+		}
+	}
+	if len(unavailable) > 0 {
+		sort.Strings(unavailable)
+		return errors.Errorf("cluster operators not available: %s", strings.Join(unavailable, ", "))
+	}
+	return nil
+}
+
+func nodesReady(nodes []corev1.Node, expected int64) error {
+	var notReady []string
+	for _, node := range nodes {
+		ready := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		if !ready {
+			notReady = append(notReady, node.Name)
+		}
+	}
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return errors.Errorf("nodes not ready: %s", strings.Join(notReady, ", "))
+	}
+	if int64(len(nodes)) < expected {
+		return errors.Errorf("found %d ready nodes, but the install config declares %d", len(nodes), expected)
+	}
+	return nil
+}
+
+func defaultStorageClass(classes []storagev1.StorageClass) error {
+	for _, class := range classes {
+		if class.Annotations[defaultStorageClassAnnotation] == "true" {
+			return nil
+		}
+	}
+	return errors.New("no default storage class found")
+}
+
+func resolves(host string) error {
+	addrs, err := net.LookupHost(host)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return errors.Errorf("%s did not resolve to any addresses", host)
+	}
+	return nil
+}
+
+func reachable(address string) error {
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
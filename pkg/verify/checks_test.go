@@ -0,0 +1,143 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+func clusterOperator(name string, conditions ...configv1.ClusterOperatorStatusCondition) configv1.ClusterOperator {
+	return configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     configv1.ClusterOperatorStatus{Conditions: conditions},
+	}
+}
+
+func condition(conditionType configv1.ClusterStatusConditionType, status configv1.ConditionStatus) configv1.ClusterOperatorStatusCondition {
+	return configv1.ClusterOperatorStatusCondition{Type: conditionType, Status: status}
+}
+
+func node(name string, status corev1.ConditionStatus) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestClusterOperatorsAvailable(t *testing.T) {
+	cases := []struct {
+		name          string
+		operators     []configv1.ClusterOperator
+		expectedError string
+	}{
+		{
+			name:          "none",
+			expectedError: "no cluster operators found",
+		},
+		{
+			name: "available",
+			operators: []configv1.ClusterOperator{
+				clusterOperator("dns", condition(configv1.OperatorAvailable, configv1.ConditionTrue)),
+			},
+		},
+		{
+			name: "unavailable and failing",
+			operators: []configv1.ClusterOperator{
+				clusterOperator("network", condition(configv1.OperatorAvailable, configv1.ConditionTrue), condition(configv1.OperatorFailing, configv1.ConditionTrue)),
+				clusterOperator("dns", condition(configv1.OperatorAvailable, configv1.ConditionFalse)),
+				clusterOperator("ingress", condition(configv1.OperatorAvailable, configv1.ConditionTrue)),
+			},
+			expectedError: "cluster operators not available: dns, network",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := clusterOperatorsAvailable(tc.operators)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestNodesReady(t *testing.T) {
+	cases := []struct {
+		name          string
+		nodes         []corev1.Node
+		expected      int64
+		expectedError string
+	}{
+		{
+			name:     "all ready",
+			nodes:    []corev1.Node{node("master-0", corev1.ConditionTrue), node("worker-0", corev1.ConditionTrue)},
+			expected: 2,
+		},
+		{
+			name:          "not ready",
+			nodes:         []corev1.Node{node("master-0", corev1.ConditionTrue), node("worker-0", corev1.ConditionUnknown)},
+			expected:      2,
+			expectedError: "nodes not ready: worker-0",
+		},
+		{
+			name:          "missing nodes",
+			nodes:         []corev1.Node{node("master-0", corev1.ConditionTrue)},
+			expected:      3,
+			expectedError: "found 1 ready nodes, but the install config declares 3",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := nodesReady(tc.nodes, tc.expected)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestDefaultStorageClass(t *testing.T) {
+	classes := []storagev1.StorageClass{{ObjectMeta: metav1.ObjectMeta{Name: "slow"}}}
+	assert.EqualError(t, defaultStorageClass(classes), "no default storage class found")
+
+	classes = append(classes, storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+		Name:        "gp2",
+		Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+	}})
+	assert.NoError(t, defaultStorageClass(classes))
+}
+
+func TestExpectedNodes(t *testing.T) {
+	installConfig := &types.InstallConfig{
+		ControlPlane: &types.MachinePool{Name: "master", Replicas: pointer.Int64Ptr(3)},
+		Compute: []types.MachinePool{
+			{Name: "worker", Replicas: pointer.Int64Ptr(2)},
+			{Name: "gpu", Replicas: pointer.Int64Ptr(1)},
+		},
+	}
+	assert.Equal(t, int64(6), expectedNodes(installConfig))
+}
+
+func TestRun(t *testing.T) {
+	report := Run([]Check{
+		{Name: "pass", Run: func() error { return nil }},
+		{Name: "fail", Run: func() error { return errors.New("broken") }},
+	})
+	assert.False(t, report.Passed)
+	assert.Len(t, report.Checks, 2)
+	assert.True(t, report.Checks[0].Passed)
+	assert.Equal(t, "broken", report.Checks[1].Message)
+}
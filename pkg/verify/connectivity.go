@@ -0,0 +1,213 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/offline"
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
+	"github.com/metalkube/kni-installer/pkg/types/none"
+)
+
+const libvirtSocket = "/var/run/libvirt/libvirt-sock"
+
+// ConnectivityChecks returns the checks that the installer host can reach
+// everything an install of installConfig needs before anything is
+// provisioned.  osImage returns the RHCOS image location, which may itself
+// need the network to resolve.  The checks give up when ctx is done.
+func ConnectivityChecks(ctx context.Context, installConfig *types.InstallConfig, releaseImage string, osImage func() (string, error)) []Check {
+	// The fake platform provisions nothing, so there is nothing to reach.
+	if installConfig.Platform.Fake != nil {
+		return nil
+	}
+
+	checks := []Check{
+		{
+			Name: "release-registry",
+			Run: func() error {
+				return reachable(registryAddress(releaseImage))
+			},
+		},
+		{
+			Name: "rhcos-image-source",
+			Run: func() error {
+				image, err := osImage()
+				if err != nil {
+					return errors.Wrap(err, "failed to resolve the RHCOS image")
+				}
+				return imageAvailable(image)
+			},
+		},
+	}
+
+	// The release image is not inspected offline, so its version and
+	// architectures are left to the user.
+	if !offline.FromContext(ctx) {
+		checks = append(checks, Check{
+			Name: "release-version",
+			Run: func() error {
+				pullSecret, err := installConfig.MergedPullSecret()
+				if err != nil {
+					return err
+				}
+				return releaseSkew(ctx, releaseImage, pullSecret)
+			},
+		})
+
+		// A single-arch release image runs on amd64, so the release only has to
+		// be inspected for clusters with other architectures.
+		if archs := poolArchitectures(installConfig); len(archs) > 1 || archs[0] != string(types.ArchitectureAMD64) {
+			checks = append(checks, Check{
+				Name: "release-architectures",
+				Run: func() error {
+					pullSecret, err := installConfig.MergedPullSecret()
+					if err != nil {
+						return err
+					}
+					return releaseCoversArchitectures(ctx, releaseImage, pullSecret, archs)
+				},
+			})
+		}
+	}
+
+	var libvirtURI string
+	switch {
+	case installConfig.Platform.BareMetal != nil:
+		libvirtURI = installConfig.Platform.BareMetal.URI
+	case installConfig.Platform.Libvirt != nil:
+		libvirtURI = installConfig.Platform.Libvirt.URI
+	}
+	if libvirtURI != "" {
+		checks = append(checks, Check{
+			Name: "libvirt-uri",
+			Run:  func() error { return libvirtReachable(libvirtURI) },
+		})
+	}
+
+	if p := installConfig.Platform.BareMetal; p != nil {
+		if planned, owners := plannedAddresses(p); len(planned) > 0 {
+			checks = append(checks, Check{
+				Name: "address-conflicts",
+				Run: func() error {
+					conflicts, err := addressConflicts(planned, owners)
+					if err != nil {
+						return err
+					}
+					// The neighbour cache may be stale, so conflicts do
+					// not fail the check.
+					for _, conflict := range conflicts {
+						logrus.Warnf("%s, unless its ARP cache entry is stale", conflict)
+					}
+					return nil
+				},
+			})
+		}
+		// Unprovisioned hosts are not installed now, so they are only
+		// checked when they are added.
+		if hosts := baremetal.ProvisionedHosts(p.Hosts); len(hosts) > 0 {
+			checks = append(checks, Check{
+				Name: "host-reuse",
+				Run:  func() error { return reusedHosts(hosts) },
+			})
+		}
+	}
+
+	// The installer creates DNS records itself on the cloud platforms, so
+	// they can only be checked up front where the user provides them.
+	switch installConfig.Platform.Name() {
+	case baremetal.Name, none.Name, libvirt.Name:
+		clusterDomain := installConfig.ClusterDomain()
+		for _, name := range []string{"api", "api-int"} {
+			host := fmt.Sprintf("%s.%s", name, clusterDomain)
+			checks = append(checks, Check{
+				Name: fmt.Sprintf("%s-dns", name),
+				Run:  func() error { return resolves(host) },
+			})
+		}
+	}
+
+	return checks
+}
+
+// registryAddress returns the host:port of the registry serving image.
+func registryAddress(image string) string {
+	host := "registry-1.docker.io"
+	if i := strings.Index(image, "/"); i >= 0 {
+		if first := image[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			host = first
+		}
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+	return host
+}
+
+// imageAvailable checks that an image URL responds, or that a local image
+// exists.
+func imageAvailable(image string) error {
+	u, err := url.Parse(image)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		resp, err := http.Head(image)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return errors.Errorf("%s while fetching %s", resp.Status, image)
+		}
+		return nil
+	case "file":
+		_, err := os.Stat(u.Path)
+		return err
+	case "":
+		if strings.HasPrefix(u.Path, "/") {
+			_, err := os.Stat(u.Path)
+			return err
+		}
+		return nil
+	default:
+		// e.g. an AMI ID or Glance image name, which is checked by the
+		// platform.
+		return nil
+	}
+}
+
+// libvirtReachable checks that the libvirt daemon behind uri accepts
+// connections, without needing the libvirt client libraries.
+func libvirtReachable(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		_, err := os.Stat(libvirtSocket)
+		return err
+	}
+
+	if u.Port() != "" {
+		return reachable(u.Host)
+	}
+	port := "16514"
+	switch {
+	case strings.HasSuffix(u.Scheme, "+ssh"):
+		port = "22"
+	case strings.HasSuffix(u.Scheme, "+tcp"):
+		port = "16509"
+	}
+	return reachable(net.JoinHostPort(u.Hostname(), port))
+}
@@ -0,0 +1,42 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryAddress(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected string
+	}{
+		{
+			image:    "registry.svc.ci.openshift.org/openshift/origin-release:v4.0",
+			expected: "registry.svc.ci.openshift.org:443",
+		},
+		{
+			image:    "mirror.example.com:5000/ocp/release@sha256:abc",
+			expected: "mirror.example.com:5000",
+		},
+		{
+			image:    "localhost/release",
+			expected: "localhost:443",
+		},
+		{
+			image:    "openshift/origin-release",
+			expected: "registry-1.docker.io:443",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.expected, registryAddress(tc.image))
+		})
+	}
+}
+
+func TestImageAvailable(t *testing.T) {
+	assert.NoError(t, imageAvailable("ami-0123456789"))
+	assert.Error(t, imageAvailable("file:///does/not/exist.qcow2"))
+	assert.Error(t, imageAvailable("/does/not/exist.qcow2"))
+}
@@ -0,0 +1,223 @@
+package verify
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+)
+
+var (
+	// procDir and sysDir are where Linux exposes the host's CPU, memory
+	// and block devices.  Tests override them.
+	procDir = "/proc"
+	sysDir  = "/sys"
+
+	// kvmDevice exists when the host can run hardware-accelerated guests.
+	kvmDevice = "/dev/kvm"
+
+	// libvirtImagesDir backs the default storage pool, which holds the
+	// domains' volumes.
+	libvirtImagesDir = "/var/lib/libvirt/images"
+
+	// interfaceAddrs lists the addresses of the installer host.  Tests
+	// override it.
+	interfaceAddrs = net.InterfaceAddrs
+)
+
+const (
+	// bootstrapMemoryMiB and domainMemoryMiB are the memory of the
+	// bootstrap domain in data/data/libvirt and of the other domains in
+	// pkg/asset/machines/libvirt.
+	bootstrapMemoryMiB = 2048
+	domainMemoryMiB    = 4096
+)
+
+// HostAssessment describes how well the libvirt host can run the domains
+// of an install.
+type HostAssessment struct {
+	// Warnings describe what will slow the install down and how to avoid
+	// it.
+	Warnings []string
+
+	// TimeoutScale is the factor the installer's wait timeouts should be
+	// multiplied by to allow for the host's performance.  It is 1 for a
+	// host with no warnings.
+	TimeoutScale int
+}
+
+// AssessLibvirtHost checks the libvirt host of installConfig for nested
+// virtualization, volumes on spinning disks and too little memory for the
+// domains, which make installs slow enough to hit the installer's
+// timeouts.  It returns nil if installConfig is not for libvirt, or if the
+// libvirt host is not the installer host and so cannot be inspected.
+func AssessLibvirtHost(installConfig *types.InstallConfig) *HostAssessment {
+	p := installConfig.Platform.Libvirt
+	if p == nil {
+		return nil
+	}
+	if !localURI(p.URI) {
+		logrus.Debugf("Not assessing the libvirt host behind %s, which is not the installer host", p.URI)
+		return nil
+	}
+
+	assessment := &HostAssessment{TimeoutScale: 1}
+	warn := func(scale int, format string, args ...interface{}) {
+		assessment.Warnings = append(assessment.Warnings, fmt.Sprintf(format, args...))
+		assessment.TimeoutScale += scale
+	}
+
+	if _, err := os.Stat(kvmDevice); os.IsNotExist(err) {
+		warn(3, "%s does not exist, so the domains will be emulated in software, which is very slow. Enable virtualization in the host's firmware and load the kvm module", kvmDevice)
+	} else if nested, err := nestedVirtualization(); err != nil {
+		logrus.Debugf("Failed to check for nested virtualization: %v", err)
+	} else if nested {
+		warn(1, "The libvirt host is itself a virtual machine, so the domains run under nested virtualization, which is much slower than on bare metal")
+	}
+
+	if device, err := blockDevice(libvirtImagesDir); err != nil {
+		logrus.Debugf("Failed to find the device holding %s: %v", libvirtImagesDir, err)
+	} else if spinning, err := rotational(device); err != nil {
+		logrus.Debugf("Failed to check whether %s is on a spinning disk: %v", libvirtImagesDir, err)
+	} else if spinning {
+		warn(1, "%s, which holds the domains' volumes, is on a spinning disk, which is too slow for etcd. Move the default storage pool to a solid state disk", libvirtImagesDir)
+	}
+
+	needed := memoryNeeded(installConfig)
+	if available, err := memAvailable(); err != nil {
+		logrus.Debugf("Failed to check the available memory: %v", err)
+	} else if available < needed {
+		warn(1, "The domains need %d MiB of memory but the libvirt host only has %d MiB available, so they will swap. Free some memory or reduce the number of compute replicas", needed, available)
+	}
+
+	return assessment
+}
+
+// localURI returns true if the libvirt daemon behind uri runs on the
+// installer host.
+func localURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedVirtualization returns true if the host's CPU reports that it is
+// running under a hypervisor.
+func nestedVirtualization() (bool, error) {
+	f, err := os.Open(filepath.Join(procDir, "cpuinfo"))
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value := splitProcLine(scanner.Text())
+		if key != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			if flag == "hypervisor" {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, scanner.Err()
+}
+
+// rotational returns true if the block device major:minor is a spinning
+// disk.  Partitions report the disk they are on.
+func rotational(device string) (bool, error) {
+	dir, err := filepath.EvalSymlinks(filepath.Join(sysDir, "dev", "block", device))
+	if err != nil {
+		return false, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "queue", "rotational"))
+	if os.IsNotExist(err) {
+		data, err = ioutil.ReadFile(filepath.Join(dir, "..", "queue", "rotational"))
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(data)) == "1", nil
+}
+
+// memAvailable returns the memory available on the host in MiB.
+func memAvailable() (int64, error) {
+	f, err := os.Open(filepath.Join(procDir, "meminfo"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value := splitProcLine(scanner.Text())
+		if key != "MemAvailable" {
+			continue
+		}
+		kib, err := strconv.ParseInt(strings.TrimSuffix(value, " kB"), 10, 64)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid MemAvailable")
+		}
+		return kib / 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("no MemAvailable in meminfo")
+}
+
+// memoryNeeded returns the memory in MiB of all of the domains of the
+// install, including the bootstrap domain.
+func memoryNeeded(installConfig *types.InstallConfig) int64 {
+	domains := int64(0)
+	if installConfig.ControlPlane != nil && installConfig.ControlPlane.Replicas != nil {
+		domains += *installConfig.ControlPlane.Replicas
+	}
+	for _, pool := range installConfig.Compute {
+		if pool.Replicas != nil {
+			domains += *pool.Replicas
+		}
+	}
+	return bootstrapMemoryMiB + domains*domainMemoryMiB
+}
+
+// splitProcLine splits a "key : value" line of a /proc file.
+func splitProcLine(line string) (string, string) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return strings.TrimSpace(line), ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,168 @@
+package verify
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	"github.com/metalkube/kni-installer/pkg/types"
+	"github.com/metalkube/kni-installer/pkg/types/libvirt"
+)
+
+func writeFile(t *testing.T, path string, contents string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+}
+
+func TestAssessLibvirtHost(t *testing.T) {
+	defer func(proc, kvm, images string) {
+		procDir, kvmDevice, libvirtImagesDir = proc, kvm, images
+	}(procDir, kvmDevice, libvirtImagesDir)
+
+	installConfig := &types.InstallConfig{
+		ControlPlane: &types.MachinePool{Name: "master", Replicas: pointer.Int64Ptr(3)},
+		Compute:      []types.MachinePool{{Name: "worker", Replicas: pointer.Int64Ptr(2)}},
+		Platform: types.Platform{
+			Libvirt: &libvirt.Platform{URI: "qemu:///system"},
+		},
+	}
+
+	cases := []struct {
+		name             string
+		flags            string
+		noKVM            bool
+		memAvailableKiB  string
+		expectedWarnings []string
+		expectedScale    int
+	}{
+		{
+			name:            "bare metal",
+			flags:           "fpu vmx sse2",
+			memAvailableKiB: "33554432",
+			expectedScale:   1,
+		},
+		{
+			name:            "nested",
+			flags:           "fpu vmx sse2 hypervisor",
+			memAvailableKiB: "33554432",
+			expectedWarnings: []string{
+				"^The libvirt host is itself a virtual machine",
+			},
+			expectedScale: 2,
+		},
+		{
+			name:            "no kvm",
+			flags:           "fpu sse2 hypervisor",
+			noKVM:           true,
+			memAvailableKiB: "33554432",
+			expectedWarnings: []string{
+				"does not exist, so the domains will be emulated in software",
+			},
+			expectedScale: 4,
+		},
+		{
+			name:            "short of memory",
+			flags:           "fpu vmx sse2",
+			memAvailableKiB: "16777216",
+			expectedWarnings: []string{
+				"^The domains need 22528 MiB of memory but the libvirt host only has 16384 MiB available",
+			},
+			expectedScale: 2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kni-install-libvirthost")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			procDir = filepath.Join(dir, "proc")
+			writeFile(t, filepath.Join(procDir, "cpuinfo"), "processor\t: 0\nflags\t\t: "+tc.flags+"\n")
+			writeFile(t, filepath.Join(procDir, "meminfo"), "MemTotal:       67108864 kB\nMemAvailable:   "+tc.memAvailableKiB+" kB\n")
+			kvmDevice = filepath.Join(dir, "kvm")
+			if !tc.noKVM {
+				writeFile(t, kvmDevice, "")
+			}
+			libvirtImagesDir = filepath.Join(dir, "missing")
+
+			assessment := AssessLibvirtHost(installConfig)
+			if !assert.NotNil(t, assessment) {
+				return
+			}
+			assert.Equal(t, tc.expectedScale, assessment.TimeoutScale)
+			if assert.Len(t, assessment.Warnings, len(tc.expectedWarnings)) {
+				for i, expected := range tc.expectedWarnings {
+					assert.Regexp(t, expected, assessment.Warnings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRotational(t *testing.T) {
+	defer func(sys string) { sysDir = sys }(sysDir)
+
+	dir, err := ioutil.TempDir("", "kni-install-rotational")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	sysDir = dir
+
+	writeFile(t, filepath.Join(dir, "devices", "sda", "queue", "rotational"), "1\n")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "devices", "sda", "sda2"), 0755))
+	writeFile(t, filepath.Join(dir, "devices", "nvme0n1", "queue", "rotational"), "0\n")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "dev", "block"), 0755))
+	for device, target := range map[string]string{
+		"8:0":   "sda",
+		"8:2":   "sda/sda2",
+		"259:0": "nvme0n1",
+	} {
+		assert.NoError(t, os.Symlink(filepath.Join(dir, "devices", target), filepath.Join(dir, "dev", "block", device)))
+	}
+
+	cases := []struct {
+		device   string
+		expected bool
+	}{
+		{device: "8:0", expected: true},
+		{device: "8:2", expected: true},
+		{device: "259:0", expected: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.device, func(t *testing.T) {
+			spinning, err := rotational(tc.device)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, spinning)
+		})
+	}
+}
+
+func TestLocalURI(t *testing.T) {
+	defer func(addrs func() ([]net.Addr, error)) { interfaceAddrs = addrs }(interfaceAddrs)
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("192.168.122.1"), Mask: net.CIDRMask(24, 32)},
+		}, nil
+	}
+
+	cases := []struct {
+		uri      string
+		expected bool
+	}{
+		{uri: "qemu:///system", expected: true},
+		{uri: "qemu+tcp://192.168.122.1/system", expected: true},
+		{uri: "qemu+ssh://root@localhost/system", expected: true},
+		{uri: "qemu+tcp://192.168.122.2/system", expected: false},
+		{uri: "qemu+ssh://root@hypervisor.example.com/system", expected: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.uri, func(t *testing.T) {
+			assert.Equal(t, tc.expected, localURI(tc.uri))
+		})
+	}
+}
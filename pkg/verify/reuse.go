@@ -0,0 +1,93 @@
+package verify
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+// deploymentPorts are ports which only a node of an installed cluster
+// listens on, by the service listening on them.
+var deploymentPorts = []struct {
+	Port    string
+	Service string
+}{
+	{Port: "10250", Service: "kubelet"},
+	{Port: "22623", Service: "machine config server"},
+	{Port: "2379", Service: "etcd"},
+	{Port: "6443", Service: "Kubernetes API"},
+}
+
+var (
+	// reuseDialTimeout is how long to wait for each deployment port.
+	// Powered-off hosts never answer, so it is kept short.
+	reuseDialTimeout = 2 * time.Second
+
+	// dialDeploymentPort returns nil if something accepts connections on
+	// address.  Tests override it.
+	dialDeploymentPort = func(address string) error {
+		conn, err := net.DialTimeout("tcp", address, reuseDialTimeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+)
+
+// reusedHosts returns an error naming each host with a static address
+// which is still running a previous deployment, e.g. because it booted an
+// old RHCOS install from its disk.  Such a host would rejoin the old
+// cluster, or serve stale Ignition configs, instead of being provisioned.
+func reusedHosts(hosts []baremetal.Host) error {
+	var (
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		services = map[string][]string{}
+		address  = map[string]string{}
+	)
+	for _, host := range hosts {
+		if host.Network == nil {
+			continue
+		}
+		ip, _, err := net.ParseCIDR(host.Network.Address)
+		if err != nil {
+			continue
+		}
+		address[host.Name] = ip.String()
+		for _, port := range deploymentPorts {
+			wg.Add(1)
+			go func(name, service, hostPort string) {
+				defer wg.Done()
+				if dialDeploymentPort(hostPort) == nil {
+					lock.Lock()
+					services[name] = append(services[name], service)
+					lock.Unlock()
+				}
+			}(host.Name, fmt.Sprintf("%s on port %s", port.Service, port.Port), net.JoinHostPort(ip.String(), port.Port))
+		}
+	}
+	wg.Wait()
+
+	if len(services) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		sort.Strings(services[name])
+		messages = append(messages, fmt.Sprintf("host %s (%s) is still running a previous deployment (%s answered)", name, address[name], strings.Join(services[name], ", ")))
+	}
+	return errors.Errorf("%s; power the hosts off and wipe their install disks (e.g. \"wipefs --all\" from a rescue image) so they boot from the network", strings.Join(messages, "; "))
+}
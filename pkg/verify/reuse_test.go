@@ -0,0 +1,54 @@
+package verify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metalkube/kni-installer/pkg/types/baremetal"
+)
+
+func TestReusedHosts(t *testing.T) {
+	defer func(dial func(string) error) { dialDeploymentPort = dial }(dialDeploymentPort)
+
+	hosts := []baremetal.Host{
+		{Name: "master-0", Network: &baremetal.HostNetwork{Address: "192.168.111.20/24"}},
+		{Name: "master-1", Network: &baremetal.HostNetwork{Address: "192.168.111.21/24"}},
+		{Name: "worker-0"},
+	}
+
+	cases := []struct {
+		name          string
+		listening     map[string]bool
+		expectedError string
+	}{
+		{
+			name: "fresh hosts",
+		},
+		{
+			name: "previous deployment",
+			listening: map[string]bool{
+				"192.168.111.21:10250": true,
+				"192.168.111.21:22623": true,
+			},
+			expectedError: `host master-1 \(192\.168\.111\.21\) is still running a previous deployment \(kubelet on port 10250, machine config server on port 22623 answered\); power the hosts off`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dialDeploymentPort = func(address string) error {
+				if tc.listening[address] {
+					return nil
+				}
+				return errors.New("connection refused")
+			}
+			err := reusedHosts(hosts)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package verify
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/metalkube/kni-installer/pkg/release"
+	"github.com/metalkube/kni-installer/pkg/warnings"
+)
+
+var (
+	// releaseVersion returns the version of a release image.  Tests
+	// override it.
+	releaseVersion = release.Version
+)
+
+// releaseSkew checks that the version of releaseImage is one the
+// installer's manifest templates are compatible with.  A release image
+// without a version only gets a warning, since it cannot be checked.  It
+// gives up when ctx is done or after releaseTimeout.
+func releaseSkew(ctx context.Context, releaseImage, pullSecret string) error {
+	ctx, cancel := context.WithTimeout(ctx, releaseTimeout)
+	defer cancel()
+	version, err := releaseVersion(ctx, releaseImage, pullSecret)
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		warnings.Warnf(warnings.Skew, "The release image %s has no version label, so it cannot be checked against the releases this installer supports", releaseImage)
+		return nil
+	}
+	return errors.Wrapf(release.CheckSkew(version), "the release image %s", releaseImage)
+}
@@ -0,0 +1,25 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseSkew(t *testing.T) {
+	defer func(fn func(context.Context, string, string) (string, error)) { releaseVersion = fn }(releaseVersion)
+	releaseVersion = func(ctx context.Context, image, pullSecret string) (string, error) {
+		return map[string]string{
+			"example.com/ocp/release:4.1":       "4.1.0",
+			"example.com/ocp/release:4.2":       "4.2.0-rc.1",
+			"example.com/ocp/release:unlabeled": "",
+		}[image], nil
+	}
+
+	assert.NoError(t, releaseSkew(context.Background(), "example.com/ocp/release:4.1", "{}"))
+	assert.NoError(t, releaseSkew(context.Background(), "example.com/ocp/release:unlabeled", "{}"))
+	assert.EqualError(t,
+		releaseSkew(context.Background(), "example.com/ocp/release:4.2", "{}"),
+		"the release image example.com/ocp/release:4.2: release 4.2.0-rc.1 is outside the releases this installer supports (4.0, 4.1); use an installer built for 4.2")
+}
@@ -0,0 +1,83 @@
+// Package verify runs post-install smoke tests against a cluster.
+package verify
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Check is a single named verification.
+type Check struct {
+	// Name identifies the check in the report.
+	Name string
+
+	// Run performs the check, returning an error describing why it failed.
+	Run func() error
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Message  string        `json:"message,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of a verification run.  It is passed only if every
+// check passed.
+type Report struct {
+	Passed bool     `json:"passed"`
+	Checks []Result `json:"checks"`
+}
+
+// Run runs all of the checks in order and collects the results.  Failing
+// checks do not stop later checks from running.
+func Run(checks []Check) *Report {
+	report := &Report{Passed: true}
+	for _, check := range checks {
+		logrus.Debugf("Running check %q...", check.Name)
+		start := time.Now()
+		err := check.Run()
+		result := Result{
+			Name:     check.Name,
+			Passed:   err == nil,
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			result.Message = err.Error()
+			report.Passed = false
+			logrus.Debugf("Check %q failed: %v", check.Name, err)
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// WriteTable writes the report as a table of checks and their outcomes.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tMESSAGE")
+	for _, result := range r.Checks {
+		status := "ok"
+		if !result.Passed {
+			status = "FAILED"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, status, result.Message)
+	}
+	return tw.Flush()
+}
+
+// Failed returns the results of the checks which failed.
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Checks {
+		if !result.Passed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,123 @@
+// Package warnings collects the non-fatal issues found while validating
+// and generating assets, such as deprecated fields, weak settings and
+// version skew risks, so they can be summarized together at the end of a
+// run instead of scrolling past between the progress messages.
+package warnings
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/metalkube/kni-installer/pkg/fileaudit"
+)
+
+// Category is the kind of issue a warning is about.
+type Category string
+
+const (
+	// Deprecated is a deprecated field or value, which a later version
+	// may stop accepting.
+	Deprecated Category = "deprecated"
+
+	// Weak is a valid setting which makes the cluster less resilient or
+	// less secure than the defaults.
+	Weak Category = "weak"
+
+	// Skew is a risk of the installer, release image and RHCOS image not
+	// matching, e.g. when one of them is overridden.
+	Skew Category = "skew"
+
+	// Other is every warning logged without a category.
+	Other Category = "other"
+)
+
+// CategoryField is the logrus field carrying a warning's category.
+const CategoryField = "category"
+
+// Warning is a non-fatal issue.
+type Warning struct {
+	Category Category `json:"category"`
+	Message  string   `json:"message"`
+}
+
+// Warnf logs a warning in category.
+func Warnf(category Category, format string, args ...interface{}) {
+	logrus.WithField(CategoryField, category).Warnf(format, args...)
+}
+
+// Collector is a logrus hook collecting the warnings logged, once each.
+type Collector struct {
+	mu       sync.Mutex
+	seen     map[Warning]bool
+	warnings []Warning
+}
+
+var _ logrus.Hook = (*Collector)(nil)
+
+// NewCollector returns an empty collector.
+func NewCollector() *Collector {
+	return &Collector{seen: map[Warning]bool{}}
+}
+
+// Levels returns the warning level, the only one collected.
+func (c *Collector) Levels() []logrus.Level {
+	return []logrus.Level{logrus.WarnLevel}
+}
+
+// Fire collects the warning, unless it was already collected.
+func (c *Collector) Fire(entry *logrus.Entry) error {
+	category, ok := entry.Data[CategoryField].(Category)
+	if !ok {
+		category = Other
+	}
+	w := Warning{Category: category, Message: entry.Message}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.seen[w] {
+		c.seen[w] = true
+		c.warnings = append(c.warnings, w)
+	}
+	return nil
+}
+
+// Warnings returns the warnings collected, in the order they were first
+// logged.
+func (c *Collector) Warnings() []Warning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Warning(nil), c.warnings...)
+}
+
+// categories are the categories in the order they are summarized.
+var categories = []Category{Deprecated, Weak, Skew, Other}
+
+// Log logs a summary of warnings, grouped by category, at the info level
+// so it is not collected again.
+func Log(logger logrus.FieldLogger, warnings []Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+	logger.Infof("%d warnings:", len(warnings))
+	for _, category := range categories {
+		for _, w := range warnings {
+			if w.Category == category {
+				logger.Infof("  %s: %s", category, w.Message)
+			}
+		}
+	}
+}
+
+// Write writes warnings to path as a JSON array.
+func Write(path string, warnings []Warning) error {
+	if warnings == nil {
+		warnings = []Warning{}
+	}
+	data, err := json.MarshalIndent(warnings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileaudit.WriteFile(path, append(data, '\n'), 0644, "warnings")
+}
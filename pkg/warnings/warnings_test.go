@@ -0,0 +1,61 @@
+package warnings
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &out
+	logger.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+	collector := NewCollector()
+	logger.AddHook(collector)
+
+	logger.Warn("There are no compute nodes specified.")
+	logger.WithField(CategoryField, Weak).Warn("There is a single control plane replica.")
+	logger.WithField(CategoryField, Deprecated).Warn("networking.type is deprecated, use networking.networkType")
+	logger.WithField(CategoryField, Weak).Warn("There is a single control plane replica.")
+	logger.Info("Not a warning")
+	logger.Error("Not a warning either")
+
+	expected := []Warning{
+		{Category: Other, Message: "There are no compute nodes specified."},
+		{Category: Weak, Message: "There is a single control plane replica."},
+		{Category: Deprecated, Message: "networking.type is deprecated, use networking.networkType"},
+	}
+	assert.Equal(t, expected, collector.Warnings())
+
+	out.Reset()
+	Log(logger, collector.Warnings())
+	assert.Equal(t, `level=info msg="3 warnings:"
+level=info msg="  deprecated: networking.type is deprecated, use networking.networkType"
+level=info msg="  weak: There is a single control plane replica."
+level=info msg="  other: There are no compute nodes specified."
+`, out.String())
+	assert.Len(t, collector.Warnings(), 3)
+
+	dir, err := ioutil.TempDir("", "warnings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "warnings.json")
+	if !assert.NoError(t, Write(path, collector.Warnings())) {
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"category": "deprecated"`)
+
+	out.Reset()
+	Log(logger, nil)
+	assert.Empty(t, out.String())
+}